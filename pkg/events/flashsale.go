@@ -0,0 +1,21 @@
+package events
+
+// 秒杀相关事件的路由键
+const (
+	FlashSaleAdmitted = "flashsale.admitted" // 用户抢到秒杀名额（已扣减活动库存，等待异步建单）
+)
+
+// FlashSaleAdmittedEvent 秒杀排队事件
+//
+// 只有抢到名额的请求才会产生该事件，订单服务按队列速度消费并创建订单，
+// MySQL只承受"成功者"的写入；TicketID同时作为消息ID，重复投递时据此去重
+type FlashSaleAdmittedEvent struct {
+	Type       string `json:"type"` // 固定为FlashSaleAdmitted
+	TicketID   string `json:"ticket_id"`
+	SaleID     uint64 `json:"sale_id"`
+	UserID     uint64 `json:"user_id"`
+	BookID     uint64 `json:"book_id"`
+	Quantity   int    `json:"quantity"`
+	Price      int64  `json:"price"` // 秒杀单价（分）
+	AdmittedAt int64  `json:"admitted_at"`
+}
@@ -0,0 +1,41 @@
+package events
+
+// 库存相关事件的路由键
+const (
+	InventoryLowStock    = "inventory.low_stock"     // 可用库存降到低库存阈值以下（仍有货）
+	InventoryOutOfStock  = "inventory.out_of_stock"  // 可用库存降为0（缺货）
+	InventoryBackInStock = "inventory.back_in_stock" // 补货使可用库存从0变为有货（到货）
+)
+
+// InventoryLowStockEvent 低库存事件
+//
+// 只在库存"跨过"阈值时发布一次（变更前高于阈值，变更后不高于阈值），不会每次扣减都重复发布
+type InventoryLowStockEvent struct {
+	Type        string `json:"type"` // 固定为InventoryLowStock
+	BookID      uint64 `json:"book_id"`
+	Stock       int    `json:"stock"`        // 变更后的可用库存
+	BeforeStock int    `json:"before_stock"` // 变更前的可用库存
+	Threshold   int    `json:"threshold"`    // 生效的低库存阈值（图书单独设置或默认值）
+	OrderID     uint64 `json:"order_id"`     // 触发变更的订单（可选）
+	OccurredAt  int64  `json:"occurred_at"`
+}
+
+// InventoryOutOfStockEvent 缺货事件
+type InventoryOutOfStockEvent struct {
+	Type        string `json:"type"` // 固定为InventoryOutOfStock
+	BookID      uint64 `json:"book_id"`
+	BeforeStock int    `json:"before_stock"`
+	OrderID     uint64 `json:"order_id"`
+	OccurredAt  int64  `json:"occurred_at"`
+}
+
+// InventoryBackInStockEvent 到货事件
+//
+// 订阅了到货提醒的用户由inventory-service消费该事件后分批通知
+type InventoryBackInStockEvent struct {
+	Type        string `json:"type"` // 固定为InventoryBackInStock
+	BookID      uint64 `json:"book_id"`
+	Stock       int    `json:"stock"`        // 补货后的可用库存
+	BeforeStock int    `json:"before_stock"` // 补货前的可用库存
+	OccurredAt  int64  `json:"occurred_at"`
+}
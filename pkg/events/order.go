@@ -0,0 +1,38 @@
+package events
+
+// 订单相关事件的路由键
+const (
+	OrderCreated       = "order.created"        // 订单创建（库存已扣减，待支付）
+	OrderStatusChanged = "order.status_changed" // 订单状态变更（已支付/已取消/已发货等）
+)
+
+// OrderCreatedEvent 订单创建事件
+type OrderCreatedEvent struct {
+	Type      string           `json:"type"` // 固定为OrderCreated
+	OrderID   uint64           `json:"order_id"`
+	OrderNo   string           `json:"order_no"`
+	UserID    uint64           `json:"user_id"`
+	Total     int64            `json:"total"` // 订单总金额（分）
+	Items     []OrderItemEvent `json:"items"`
+	CreatedAt int64            `json:"created_at"`
+}
+
+// OrderItemEvent 订单明细
+type OrderItemEvent struct {
+	BookID   uint64 `json:"book_id"`
+	Quantity int    `json:"quantity"`
+	Price    int64  `json:"price"` // 下单时单价（分）
+}
+
+// OrderStatusChangedEvent 订单状态变更事件
+//
+// From/To为订单状态值：1待支付 2已支付 3已发货 4已完成 5已取消
+type OrderStatusChangedEvent struct {
+	Type      string `json:"type"` // 固定为OrderStatusChanged
+	OrderID   uint64 `json:"order_id"`
+	OrderNo   string `json:"order_no"`
+	UserID    uint64 `json:"user_id"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	ChangedAt int64  `json:"changed_at"`
+}
@@ -0,0 +1,55 @@
+// Package events 定义跨服务共享的领域事件（路由键 + 消息体）
+//
+// 教学要点：
+// 1. 事件是服务间的契约：发布方（payment-service）和订阅方（order-service）共用同一份定义
+// 2. 路由键采用"聚合.动作"格式，订阅方可以用通配符（如 payment.*）批量订阅
+// 3. 消息体只携带订阅方需要的数据，不暴露发布方的内部模型（如gorm实体）
+// 4. 每个事件都带Type字段：一个队列绑定多个路由键时，消费者据此分发
+package events
+
+// Exchange 业务事件交换机（Topic类型）
+const (
+	Exchange     = "bookstore.events"
+	ExchangeType = "topic"
+)
+
+// 支付相关事件的路由键
+const (
+	PaymentSucceeded = "payment.succeeded" // 支付成功
+	PaymentFailed    = "payment.failed"    // 支付失败（用户可在订单超时前重新支付）
+	RefundSucceeded  = "refund.succeeded"  // 退款成功（部分或全额）
+)
+
+// PaymentSucceededEvent 支付成功事件
+type PaymentSucceededEvent struct {
+	Type         string `json:"type"` // 固定为PaymentSucceeded
+	OrderID      uint64 `json:"order_id"`
+	PaymentNo    string `json:"payment_no"`
+	ThirdPartyNo string `json:"third_party_no"`
+	Amount       int64  `json:"amount"` // 实付金额（分）
+	PaidAt       int64  `json:"paid_at"`
+}
+
+// PaymentFailedEvent 支付失败事件
+type PaymentFailedEvent struct {
+	Type      string `json:"type"` // 固定为PaymentFailed
+	OrderID   uint64 `json:"order_id"`
+	PaymentNo string `json:"payment_no"`
+	Amount    int64  `json:"amount"`
+	Reason    string `json:"reason"`
+	FailedAt  int64  `json:"failed_at"`
+}
+
+// RefundSucceededEvent 退款成功事件
+//
+// FullyRefunded为true表示该笔支付已全部退还，订单据此关闭；部分退款订单状态不变
+type RefundSucceededEvent struct {
+	Type           string `json:"type"` // 固定为RefundSucceeded
+	OrderID        uint64 `json:"order_id"`
+	PaymentNo      string `json:"payment_no"`
+	RefundNo       string `json:"refund_no"`
+	Amount         int64  `json:"amount"`          // 本次退款金额（分）
+	RefundedAmount int64  `json:"refunded_amount"` // 累计已退金额（分）
+	FullyRefunded  bool   `json:"fully_refunded"`
+	RefundedAt     int64  `json:"refunded_at"`
+}
@@ -0,0 +1,241 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// 以下测试不依赖RabbitMQ：用fakeAcknowledger记录确认结果，用fake publishF记录重新投递
+
+type fakeAcknowledger struct {
+	acked   bool
+	nacked  bool
+	requeue bool
+}
+
+func (a *fakeAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.acked = true
+	return nil
+}
+
+func (a *fakeAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.nacked = true
+	a.requeue = requeue
+	return nil
+}
+
+func (a *fakeAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+type republished struct {
+	exchange string
+	key      string
+	msg      amqp.Publishing
+}
+
+func newTestConsumer(opts ...ConsumerOption) (*Consumer, *[]republished) {
+	options := defaultConsumerOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+	var sent []republished
+	c := &Consumer{
+		queue:   "test.queue",
+		dlx:     "test.events.dlx",
+		retryQs: retryQueues("test.queue", options),
+		opts:    options,
+	}
+	c.publishF = func(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+		sent = append(sent, republished{exchange: exchange, key: key, msg: msg})
+		return nil
+	}
+	return c, &sent
+}
+
+func newDelivery(id string, retries int) (amqp.Delivery, *fakeAcknowledger) {
+	ack := &fakeAcknowledger{}
+	d := amqp.Delivery{
+		Acknowledger: ack,
+		MessageId:    id,
+		RoutingKey:   "payment.succeeded",
+		Body:         []byte(`{"order_id":1}`),
+	}
+	if retries > 0 {
+		d.Headers = amqp.Table{HeaderRetryCount: int32(retries)}
+	}
+	return d, ack
+}
+
+// TestConsumer_DeduplicatesByMessageID 测试同一MessageId只处理一次
+func TestConsumer_DeduplicatesByMessageID(t *testing.T) {
+	c, _ := newTestConsumer(WithProcessedStore(NewMemoryProcessedStore(), time.Minute, time.Hour))
+
+	calls := 0
+	handler := func([]byte) error { calls++; return nil }
+
+	for i := 0; i < 2; i++ {
+		d, ack := newDelivery("msg-1", 0)
+		c.handleDelivery(context.Background(), d, handler)
+		if !ack.acked {
+			t.Fatalf("第%d次投递期望被确认", i+1)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("期望handler只调用1次，实际%d次", calls)
+	}
+}
+
+// TestConsumer_RetryThenDeadLetter 测试失败消息进入重试队列，超过次数后进入死信交换机
+func TestConsumer_RetryThenDeadLetter(t *testing.T) {
+	store := NewMemoryProcessedStore()
+	c, sent := newTestConsumer(
+		WithMaxRetries(2),
+		WithRetryBackoff(100*time.Millisecond, time.Second),
+		WithProcessedStore(store, time.Minute, time.Hour),
+	)
+	handler := func([]byte) error { return errors.New("db down") }
+
+	// 第一次失败：进入重试队列，x-retry-count=1
+	d, ack := newDelivery("msg-2", 0)
+	c.handleDelivery(context.Background(), d, handler)
+	if !ack.acked || len(*sent) != 1 {
+		t.Fatalf("期望确认原消息并投递重试队列，实际acked=%v sent=%d", ack.acked, len(*sent))
+	}
+	r := (*sent)[0]
+	if r.exchange != "" || r.key != "test.queue.retry.100ms" {
+		t.Errorf("期望投递到100ms重试队列，实际exchange=%q key=%q", r.exchange, r.key)
+	}
+	if r.msg.Headers[HeaderRetryCount] != int32(1) {
+		t.Errorf("期望重试次数1，实际%v", r.msg.Headers[HeaderRetryCount])
+	}
+	if r.msg.MessageId != "msg-2" || r.msg.Headers[HeaderOriginalRoutingKey] != "payment.succeeded" {
+		t.Errorf("重新投递应保留MessageId和原始路由键: %+v", r.msg)
+	}
+
+	// 失败后释放了处理权：第二次重试仍会调用handler，延迟翻倍
+	d, _ = newDelivery("msg-2", 1)
+	c.handleDelivery(context.Background(), d, handler)
+	if got := (*sent)[1].key; got != "test.queue.retry.200ms" {
+		t.Errorf("期望第二次重试进入200ms重试队列，实际%s", got)
+	}
+
+	// 重试次数用尽：进入死信交换机
+	d, ack = newDelivery("msg-2", 2)
+	c.handleDelivery(context.Background(), d, handler)
+	last := (*sent)[len(*sent)-1]
+	if !ack.acked || last.exchange != "test.events.dlx" || last.key != "test.queue" {
+		t.Fatalf("期望投递到死信交换机，实际exchange=%q key=%q", last.exchange, last.key)
+	}
+	if last.msg.Headers[HeaderLastError] != "db down" {
+		t.Errorf("死信消息应带上最后一次错误: %v", last.msg.Headers[HeaderLastError])
+	}
+}
+
+// TestConsumer_InProgressIsDelayed 测试消息正被其他消费者处理时延迟重投，并计入重试次数
+func TestConsumer_InProgressIsDelayed(t *testing.T) {
+	store := NewMemoryProcessedStore()
+	c, sent := newTestConsumer(WithMaxRetries(1), WithProcessedStore(store, time.Minute, time.Hour))
+
+	if _, err := store.Claim(context.Background(), "test.queue:msg-3", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	called := false
+	handler := func([]byte) error { called = true; return nil }
+
+	d, ack := newDelivery("msg-3", 0)
+	c.handleDelivery(context.Background(), d, handler)
+	if called {
+		t.Error("处理中的消息不应再次调用handler")
+	}
+	if !ack.acked || len(*sent) != 1 || (*sent)[0].msg.Headers[HeaderRetryCount] != int32(1) {
+		t.Fatalf("期望延迟重投且重试次数+1，实际sent=%v", *sent)
+	}
+
+	// 重试次数用尽：仍被占用也进入死信，不会在重试队列里无限循环
+	d, ack = newDelivery("msg-3", 1)
+	c.handleDelivery(context.Background(), d, handler)
+	last := (*sent)[len(*sent)-1]
+	if !ack.acked || last.exchange != "test.events.dlx" {
+		t.Errorf("期望投递到死信交换机，实际exchange=%q key=%q", last.exchange, last.key)
+	}
+}
+
+// failingStore 去重存储不可用
+type failingStore struct{ ProcessedStore }
+
+func (failingStore) Claim(ctx context.Context, key string, ttl time.Duration) (ClaimResult, error) {
+	return 0, errors.New("redis down")
+}
+
+// TestConsumer_StoreErrorCountsAsRetry 测试去重存储不可用时的重投计入重试次数
+func TestConsumer_StoreErrorCountsAsRetry(t *testing.T) {
+	c, sent := newTestConsumer(WithMaxRetries(2), WithProcessedStore(failingStore{}, time.Minute, time.Hour))
+	handler := func([]byte) error { t.Fatal("去重存储不可用时不应调用handler"); return nil }
+
+	tests := []struct {
+		retries   int
+		wantKey   string
+		wantCount int32
+	}{
+		{retries: 0, wantKey: "test.queue.retry.1000ms", wantCount: 1},
+		{retries: 1, wantKey: "test.queue.retry.2000ms", wantCount: 2},
+		{retries: 2, wantKey: "test.queue", wantCount: 2}, // 死信交换机，路由键为主队列名
+	}
+	for _, tt := range tests {
+		d, ack := newDelivery("msg-4", tt.retries)
+		c.handleDelivery(context.Background(), d, handler)
+		last := (*sent)[len(*sent)-1]
+		if !ack.acked || last.key != tt.wantKey || last.msg.Headers[HeaderRetryCount] != tt.wantCount {
+			t.Errorf("retries=%d: 期望投递到%s且重试次数%d，实际key=%s count=%v",
+				tt.retries, tt.wantKey, tt.wantCount, last.key, last.msg.Headers[HeaderRetryCount])
+		}
+	}
+}
+
+// TestRetryQueues 测试每个退避延迟一个重试队列，达到上限后共用同一队列
+func TestRetryQueues(t *testing.T) {
+	opts := defaultConsumerOptions()
+	WithMaxRetries(5)(&opts)
+	WithRetryBackoff(time.Second, 4*time.Second)(&opts)
+
+	got := retryQueues("q", opts)
+	want := map[time.Duration]string{
+		time.Second:     "q.retry.1000ms",
+		2 * time.Second: "q.retry.2000ms",
+		4 * time.Second: "q.retry.4000ms",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("期望%d个重试队列，实际%v", len(want), got)
+	}
+	for d, name := range want {
+		if got[d] != name {
+			t.Errorf("延迟%v: 期望%s，实际%s", d, name, got[d])
+		}
+	}
+}
+
+// TestMemoryProcessedStore_ClaimExpires 测试处理权超时后可以被重新占用（处理者崩溃）
+func TestMemoryProcessedStore_ClaimExpires(t *testing.T) {
+	store := NewMemoryProcessedStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	if r, _ := store.Claim(ctx, "k", time.Second); r != ClaimAcquired {
+		t.Fatalf("期望获得处理权，实际%v", r)
+	}
+	if r, _ := store.Claim(ctx, "k", time.Second); r != ClaimInProgress {
+		t.Fatalf("期望处理中，实际%v", r)
+	}
+
+	now = now.Add(2 * time.Second)
+	if r, _ := store.Claim(ctx, "k", time.Second); r != ClaimAcquired {
+		t.Fatalf("占用过期后期望重新获得处理权，实际%v", r)
+	}
+}
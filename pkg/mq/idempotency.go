@@ -0,0 +1,91 @@
+package mq
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ClaimResult 抢占消息处理权的结果
+type ClaimResult int
+
+const (
+	ClaimAcquired   ClaimResult = iota // 获得处理权（首次处理，或上次处理失败已释放）
+	ClaimProcessed                     // 已处理完成（重复投递，直接确认）
+	ClaimInProgress                    // 其他消费者正在处理（稍后重试）
+)
+
+// ProcessedStore 已处理消息存储（消费幂等）
+//
+// 教学要点：
+// 1. RabbitMQ只保证"至少一次"投递：Ack丢失、消费者重启、发件箱重复发布都会导致重复消息
+// 2. 按消息ID记录处理状态，重复投递直接确认，实现"副作用只生效一次"
+// 3. 两阶段：Claim占用（短TTL，消费者崩溃后自动释放）→ MarkProcessed标记完成（长期保留）
+// 4. pkg/mq只定义接口，服务用自己的Redis/MySQL实现；内置MemoryProcessedStore用于测试
+type ProcessedStore interface {
+	// Claim 占用消息处理权，ttl为占用的最长时间（超时视为处理者已崩溃）
+	Claim(ctx context.Context, key string, ttl time.Duration) (ClaimResult, error)
+
+	// MarkProcessed 标记处理完成，retention为去重记录保留时长
+	MarkProcessed(ctx context.Context, key string, retention time.Duration) error
+
+	// Release 处理失败时释放占用，重新投递后可以再次处理
+	Release(ctx context.Context, key string) error
+}
+
+// ==================== MemoryProcessedStore ====================
+
+// MemoryProcessedStore 内存版已处理消息存储
+//
+// ⚠️ 进程重启后记录丢失，多实例之间不共享，仅用于测试
+type MemoryProcessedStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+}
+
+type memoryEntry struct {
+	processed bool
+	expireAt  time.Time
+}
+
+// NewMemoryProcessedStore 创建内存存储
+func NewMemoryProcessedStore() *MemoryProcessedStore {
+	return &MemoryProcessedStore{entries: make(map[string]memoryEntry), now: time.Now}
+}
+
+// Claim 占用消息处理权
+func (m *MemoryProcessedStore) Claim(ctx context.Context, key string, ttl time.Duration) (ClaimResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if e, ok := m.entries[key]; ok && now.Before(e.expireAt) {
+		if e.processed {
+			return ClaimProcessed, nil
+		}
+		return ClaimInProgress, nil
+	}
+	m.entries[key] = memoryEntry{expireAt: now.Add(ttl)}
+	return ClaimAcquired, nil
+}
+
+// MarkProcessed 标记处理完成
+func (m *MemoryProcessedStore) MarkProcessed(ctx context.Context, key string, retention time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[key] = memoryEntry{processed: true, expireAt: m.now().Add(retention)}
+	return nil
+}
+
+// Release 释放占用
+func (m *MemoryProcessedStore) Release(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if e, ok := m.entries[key]; ok && !e.processed {
+		delete(m.entries, key)
+	}
+	return nil
+}
@@ -20,6 +20,8 @@ package mq
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -113,7 +115,17 @@ func NewPublisher(url, exchange, exchangeType string) (*Publisher, error) {
 // - 消息持久化：DeliveryMode=2（确保RabbitMQ重启后消息不丢失）
 // - ContentType：application/json（便于跨语言）
 // - Timestamp：记录消息发送时间（便于调试）
+// - MessageId：每条消息随机生成，消费者据此去重
 func (p *Publisher) Publish(routingKey string, message interface{}) error {
+	return p.PublishWithID(NewMessageID(), routingKey, message)
+}
+
+// PublishWithID 使用指定的消息ID发布消息
+//
+// 教学要点：
+// 同一事件重复发布时（如发件箱中继重试）必须使用相同的消息ID，
+// 消费者才能识别为重复消息；由业务数据派生ID（如outbox表主键）即可保证
+func (p *Publisher) PublishWithID(messageID, routingKey string, message interface{}) error {
 	// 1. 序列化消息为JSON
 	body, err := json.Marshal(message)
 	if err != nil {
@@ -132,6 +144,7 @@ func (p *Publisher) Publish(routingKey string, message interface{}) error {
 			Body:         body,
 			DeliveryMode: amqp.Persistent, // 消息持久化
 			Timestamp:    time.Now(),
+			MessageId:    messageID,
 		},
 	)
 
@@ -139,10 +152,19 @@ func (p *Publisher) Publish(routingKey string, message interface{}) error {
 		return fmt.Errorf("发布消息失败: %w", err)
 	}
 
-	log.Printf("📤 消息已发布: RoutingKey=%s, Body=%s", routingKey, string(body))
+	log.Printf("📤 消息已发布: RoutingKey=%s, MessageId=%s, Body=%s", routingKey, messageID, string(body))
 	return nil
 }
 
+// NewMessageID 生成随机消息ID（32位十六进制）
+func NewMessageID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
 // Close 关闭发布者
 func (p *Publisher) Close() error {
 	if p.channel != nil {
@@ -154,11 +176,89 @@ func (p *Publisher) Close() error {
 	return nil
 }
 
+// 消费失败处理相关的消息头
+const (
+	HeaderRetryCount         = "x-retry-count"          // 已重试次数
+	HeaderOriginalRoutingKey = "x-original-routing-key" // 原始路由键（经重试队列后路由键变为队列名）
+	HeaderLastError          = "x-last-error"           // 进入死信队列前的最后一次错误
+)
+
 // Consumer 消息消费者
+//
+// 教学要点（可靠消费）：
+// 1. 去重：配置ProcessedStore后按MessageId去重，重复投递直接确认
+// 2. 有限重试：失败消息带上x-retry-count头投递到对应延迟的重试队列（<queue>.retry.<延迟>ms），过期后回到主队列
+// 3. 不再Nack重新入队：毒消息不会无限循环
+// 4. 死信：重投maxRetries次后仍未处理成功，投递到死信交换机（<exchange>.dlx）→ 死信队列（<queue>.dlq）
+//
+// 每次重投都计入重试次数（包括处理权被占用、去重存储不可用），避免消息在重试队列里无限循环
 type Consumer struct {
-	conn    *amqp.Connection
-	channel *amqp.Channel
-	queue   string // Queue名称
+	conn     *amqp.Connection
+	channel  *amqp.Channel
+	queue    string                   // Queue名称
+	dlx      string                   // 死信交换机
+	retryQs  map[time.Duration]string // 重试延迟 → 重试队列
+	opts     consumerOptions
+	publishF func(ctx context.Context, exchange, key string, msg amqp.Publishing) error
+}
+
+// consumerOptions 消费者配置
+type consumerOptions struct {
+	maxRetries   int            // 最多重试次数（不含第一次），超过后进入死信队列
+	retryBackoff time.Duration  // 首次重试延迟，之后每次翻倍
+	maxBackoff   time.Duration  // 单次重试延迟上限
+	store        ProcessedStore // 为nil时不去重
+	claimTTL     time.Duration  // 处理权占用时长
+	retention    time.Duration  // 去重记录保留时长
+}
+
+// ConsumerOption 消费者配置项
+type ConsumerOption func(*consumerOptions)
+
+// WithMaxRetries 设置最多重试次数（默认3次）
+func WithMaxRetries(n int) ConsumerOption {
+	return func(o *consumerOptions) {
+		if n >= 0 {
+			o.maxRetries = n
+		}
+	}
+}
+
+// WithRetryBackoff 设置首次重试延迟和上限（默认1秒，上限1分钟）
+func WithRetryBackoff(initial, max time.Duration) ConsumerOption {
+	return func(o *consumerOptions) {
+		if initial > 0 {
+			o.retryBackoff = initial
+		}
+		if max > 0 {
+			o.maxBackoff = max
+		}
+	}
+}
+
+// WithProcessedStore 启用按MessageId去重
+//
+// claimTTL应大于单条消息的最长处理时间；retention应覆盖重复投递可能出现的时间窗口（如发件箱重试周期）
+func WithProcessedStore(store ProcessedStore, claimTTL, retention time.Duration) ConsumerOption {
+	return func(o *consumerOptions) {
+		o.store = store
+		if claimTTL > 0 {
+			o.claimTTL = claimTTL
+		}
+		if retention > 0 {
+			o.retention = retention
+		}
+	}
+}
+
+func defaultConsumerOptions() consumerOptions {
+	return consumerOptions{
+		maxRetries:   3,
+		retryBackoff: time.Second,
+		maxBackoff:   time.Minute,
+		claimTTL:     time.Minute,
+		retention:    7 * 24 * time.Hour,
+	}
 }
 
 // NewConsumer 创建消息消费者
@@ -179,8 +279,18 @@ type Consumer struct {
 //	    "topic",
 //	    "order.notification",         // Queue名称
 //	    []string{"order.*"},          // 订阅所有order.开头的事件
+//	    WithMaxRetries(5),            // 可选：失败重试5次后进入死信队列
 //	)
-func NewConsumer(url, exchange, exchangeType, queue string, routingKeys []string) (*Consumer, error) {
+//
+// ⚠️ 主队列声明了死信参数（x-dead-letter-exchange），
+// 已存在的同名队列如果没有这些参数，RabbitMQ会拒绝声明（PRECONDITION_FAILED），需要先删除旧队列
+// 重试队列按延迟命名（<queue>.retry.<延迟>ms），旧的单一重试队列<queue>.retry不再使用，消息回流到主队列后即可删除
+func NewConsumer(url, exchange, exchangeType, queue string, routingKeys []string, opts ...ConsumerOption) (*Consumer, error) {
+	options := defaultConsumerOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	// 1. 连接RabbitMQ
 	conn, err := amqp.Dial(url)
 	if err != nil {
@@ -210,19 +320,34 @@ func NewConsumer(url, exchange, exchangeType, queue string, routingKeys []string
 		return nil, fmt.Errorf("声明Exchange失败: %w", err)
 	}
 
-	// 4. 声明Queue
+	// 4. 声明死信交换机和死信队列
+	//
+	// 死信交换机使用direct类型，路由键为主队列名，每个主队列对应一个死信队列
+	dlx := exchange + ".dlx"
+	dlq := queue + ".dlq"
+	if err := declareDeadLetter(channel, dlx, dlq, queue); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, err
+	}
+
+	// 5. 声明Queue
 	//
 	// 参数说明：
 	// - Durable: true表示持久化
 	// - AutoDelete: false表示没有消费者时不自动删除
 	// - Exclusive: false表示允许多个消费者
+	// - x-dead-letter-*: 被拒绝（Nack且不重新入队）的消息转到死信交换机
 	q, err := channel.QueueDeclare(
 		queue, // Queue名称
 		true,  // Durable
 		false, // AutoDelete
 		false, // Exclusive
 		false, // NoWait
-		nil,   // Arguments
+		amqp.Table{
+			"x-dead-letter-exchange":    dlx,
+			"x-dead-letter-routing-key": queue,
+		},
 	)
 	if err != nil {
 		channel.Close()
@@ -230,7 +355,33 @@ func NewConsumer(url, exchange, exchangeType, queue string, routingKeys []string
 		return nil, fmt.Errorf("声明Queue失败: %w", err)
 	}
 
-	// 5. 绑定Queue到Exchange
+	// 6. 声明重试队列（每个退避延迟一个队列）
+	//
+	// 重试队列没有消费者：消息按队列的x-message-ttl过期后，经默认交换机（""）死信回主队列，实现延迟重试。
+	// ⚠️ 不能把不同延迟的消息放进同一个队列（靠每条消息的Expiration）：RabbitMQ只在队头过期消息，
+	// 排在1分钟消息后面的1秒消息要等1分钟。同一队列内TTL相同，先入队的一定先过期
+	retryQs := retryQueues(queue, options)
+	for delay, retryQ := range retryQs {
+		_, err = channel.QueueDeclare(
+			retryQ,
+			true,  // Durable
+			false, // AutoDelete
+			false, // Exclusive
+			false, // NoWait
+			amqp.Table{
+				"x-message-ttl":             delay.Milliseconds(),
+				"x-dead-letter-exchange":    "",
+				"x-dead-letter-routing-key": queue,
+			},
+		)
+		if err != nil {
+			channel.Close()
+			conn.Close()
+			return nil, fmt.Errorf("声明重试队列失败: %w", err)
+		}
+	}
+
+	// 7. 绑定Queue到Exchange
 	//
 	// Topic Exchange支持通配符：
 	// - * 匹配一个单词（如 order.* 匹配 order.created, order.paid）
@@ -250,13 +401,47 @@ func NewConsumer(url, exchange, exchangeType, queue string, routingKeys []string
 		}
 	}
 
-	log.Printf("✅ 消息消费者已创建: Queue=%s, RoutingKeys=%v", queue, routingKeys)
+	log.Printf("✅ 消息消费者已创建: Queue=%s, RoutingKeys=%v, 最多重试%d次, 死信队列=%s",
+		queue, routingKeys, options.maxRetries, dlq)
 
-	return &Consumer{
+	c := &Consumer{
 		conn:    conn,
 		channel: channel,
 		queue:   q.Name,
-	}, nil
+		dlx:     dlx,
+		retryQs: retryQs,
+		opts:    options,
+	}
+	c.publishF = func(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+		return channel.PublishWithContext(ctx, exchange, key, false, false, msg)
+	}
+	return c, nil
+}
+
+// retryQueues 计算各次重试用到的重试队列（相同延迟共用一个队列）
+//
+// 延迟达到maxBackoff后不再变化，队列数量不超过maxRetries
+func retryQueues(queue string, opts consumerOptions) map[time.Duration]string {
+	queues := make(map[time.Duration]string)
+	for i := 1; i <= opts.maxRetries; i++ {
+		d := opts.backoff(i)
+		queues[d] = fmt.Sprintf("%s.retry.%dms", queue, d.Milliseconds())
+	}
+	return queues
+}
+
+// declareDeadLetter 声明死信交换机、死信队列并绑定
+func declareDeadLetter(channel *amqp.Channel, dlx, dlq, routingKey string) error {
+	if err := channel.ExchangeDeclare(dlx, "direct", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("声明死信Exchange失败: %w", err)
+	}
+	if _, err := channel.QueueDeclare(dlq, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("声明死信Queue失败: %w", err)
+	}
+	if err := channel.QueueBind(dlq, routingKey, dlx, false, nil); err != nil {
+		return fmt.Errorf("绑定死信Queue失败: %w", err)
+	}
+	return nil
 }
 
 // Consume 开始消费消息
@@ -279,7 +464,8 @@ func NewConsumer(url, exchange, exchangeType, queue string, routingKeys []string
 //
 // 教学要点：
 // - AutoAck: false（手动确认，确保消息处理成功后才从队列删除）
-// - 失败重试：handler返回错误时，消息会被Nack（重新入队）
+// - 失败重试：handler返回错误时，消息进入重试队列延迟重投，超过次数进入死信队列
+// - 去重：配置ProcessedStore时，已处理过的MessageId直接确认，不再调用handler
 // - 优雅退出：监听ctx.Done()，收到信号时停止消费
 func (c *Consumer) Consume(ctx context.Context, handler func([]byte) error) error {
 	// 1. 设置Qos（Quality of Service）
@@ -325,20 +511,152 @@ func (c *Consumer) Consume(ctx context.Context, handler func([]byte) error) erro
 				return fmt.Errorf("消息Channel已关闭")
 			}
 
-			log.Printf("📬 收到消息: RoutingKey=%s, Body=%s", msg.RoutingKey, string(msg.Body))
-
-			// 处理消息
-			err := handler(msg.Body)
-			if err != nil {
-				// 处理失败，Nack（重新入队）
-				log.Printf("❌ 消息处理失败: %v, 消息将重新入队", err)
-				msg.Nack(false, true) // Requeue=true
-			} else {
-				// 处理成功，Ack（确认）
-				msg.Ack(false)
-				log.Printf("✅ 消息处理成功")
+			log.Printf("📬 收到消息: RoutingKey=%s, MessageId=%s, Body=%s", msg.RoutingKey, msg.MessageId, string(msg.Body))
+			c.handleDelivery(ctx, msg, handler)
+		}
+	}
+}
+
+// handleDelivery 处理单条消息：去重 → 调用handler → 确认/重试/死信
+func (c *Consumer) handleDelivery(ctx context.Context, msg amqp.Delivery, handler func([]byte) error) {
+	// 1. 去重：抢占处理权
+	key := ""
+	if c.opts.store != nil && msg.MessageId != "" {
+		key = c.queue + ":" + msg.MessageId
+		result, err := c.opts.store.Claim(ctx, key, c.opts.claimTTL)
+		if err != nil {
+			// 去重存储不可用：不冒险重复处理，延迟重投
+			log.Printf("⚠️ 去重存储不可用: %v", err)
+			c.requeue(ctx, msg, "去重存储不可用: "+err.Error())
+			return
+		}
+		switch result {
+		case ClaimProcessed:
+			log.Printf("🔁 重复消息，已处理过: MessageId=%s", msg.MessageId)
+			msg.Ack(false)
+			return
+		case ClaimInProgress:
+			log.Printf("⏳ 消息正在被其他消费者处理: MessageId=%s", msg.MessageId)
+			c.requeue(ctx, msg, "消息正在被其他消费者处理")
+			return
+		}
+	}
+
+	// 2. 调用业务处理
+	err := handler(msg.Body)
+	if err == nil {
+		if key != "" {
+			if err := c.opts.store.MarkProcessed(ctx, key, c.opts.retention); err != nil {
+				// 副作用已生效：仍然确认，占用记录过期后重复投递需靠handler自身幂等兜底
+				log.Printf("⚠️ 记录已处理消息失败: %v", err)
 			}
 		}
+		msg.Ack(false)
+		log.Printf("✅ 消息处理成功")
+		return
+	}
+
+	if key != "" {
+		if err := c.opts.store.Release(ctx, key); err != nil {
+			log.Printf("⚠️ 释放消息处理权失败: %v", err)
+		}
+	}
+
+	// 3. 失败：未超过重试次数则延迟重投，否则进入死信队列
+	log.Printf("❌ 消息处理失败: %v", err)
+	c.requeue(ctx, msg, err.Error())
+}
+
+// requeue 未超过重试次数则延迟重投（重试次数+1），否则进入死信队列
+func (c *Consumer) requeue(ctx context.Context, msg amqp.Delivery, reason string) {
+	retries := retryCount(msg)
+	if retries >= c.opts.maxRetries {
+		log.Printf("☠️ 消息已重投%d次，进入死信队列: %s", retries, reason)
+		c.deadLetter(ctx, msg, reason)
+		return
+	}
+	log.Printf("🔁 消息延迟重投，第%d次重试: MessageId=%s", retries+1, msg.MessageId)
+	c.retry(ctx, msg, retries+1, reason)
+}
+
+// retry 投递到第retries次重试对应延迟的重试队列，成功后确认原消息
+//
+// 投递失败时Nack重新入队，保证消息不丢
+func (c *Consumer) retry(ctx context.Context, msg amqp.Delivery, retries int, lastErr string) {
+	retryQ, ok := c.retryQs[c.opts.backoff(retries)]
+	if !ok {
+		// 只有retries超出maxRetries时才会找不到队列，requeue已拦截，这里兜底进入死信
+		c.deadLetter(ctx, msg, lastErr)
+		return
+	}
+
+	if err := c.publishF(ctx, "", retryQ, republishing(msg, retries, lastErr)); err != nil {
+		log.Printf("⚠️ 投递重试队列失败: %v, 消息重新入队", err)
+		msg.Nack(false, true)
+		return
+	}
+	msg.Ack(false)
+}
+
+// deadLetter 投递到死信交换机（带上最后一次错误），成功后确认原消息
+//
+// 投递失败时Nack且不重新入队，由主队列的x-dead-letter-exchange兜底转入死信队列
+func (c *Consumer) deadLetter(ctx context.Context, msg amqp.Delivery, lastErr string) {
+	if err := c.publishF(ctx, c.dlx, c.queue, republishing(msg, retryCount(msg), lastErr)); err != nil {
+		log.Printf("⚠️ 投递死信交换机失败: %v", err)
+		msg.Nack(false, false)
+		return
+	}
+	msg.Ack(false)
+}
+
+// backoff 第retries次重试的延迟：retryBackoff * 2^(retries-1)，不超过maxBackoff
+func (o consumerOptions) backoff(retries int) time.Duration {
+	d := o.retryBackoff
+	for i := 1; i < retries && d < o.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > o.maxBackoff {
+		d = o.maxBackoff
+	}
+	return d
+}
+
+// republishing 复制原消息用于重新投递（保留MessageId，更新重试头）
+func republishing(msg amqp.Delivery, retries int, lastErr string) amqp.Publishing {
+	headers := amqp.Table{}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[HeaderRetryCount] = int32(retries)
+	if _, ok := headers[HeaderOriginalRoutingKey]; !ok {
+		headers[HeaderOriginalRoutingKey] = msg.RoutingKey
+	}
+	if lastErr != "" {
+		headers[HeaderLastError] = lastErr
+	}
+
+	return amqp.Publishing{
+		Headers:      headers,
+		ContentType:  msg.ContentType,
+		Body:         msg.Body,
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    msg.Timestamp,
+		MessageId:    msg.MessageId,
+	}
+}
+
+// retryCount 读取消息已重试次数（头不存在时为0）
+func retryCount(msg amqp.Delivery) int {
+	switch v := msg.Headers[HeaderRetryCount].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
 	}
 }
 
@@ -408,6 +726,9 @@ func (c *Consumer) Close() error {
 // 2. 解耦（邮件服务故障不影响订单）
 // 3. 可扩展（启动多个消费者进程）
 // 4. 削峰填谷（邮件慢慢发，不影响用户）
+//
+// ⚠️ 注意：db.Create和Publish不在同一事务，两步之间崩溃会丢事件。
+// 订单、支付等关键事件应使用pkg/outbox：事件与业务数据同事务写入outbox表，由Relay异步发布
 
 // ==================== 教学总结 ====================
 //
@@ -0,0 +1,142 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MessageModel 发件箱表（GORM模型）
+//
+// 教学要点：
+// 1. 与业务表在同一个库中，服务的仓储在写业务数据的事务里用Write同时写入事件
+// 2. (status, id)联合索引：中继按"待发布 + ID升序"扫描
+// 3. 各服务使用同一张表结构，启动时AutoMigrate(&outbox.MessageModel{})
+type MessageModel struct {
+	ID         uint64     `gorm:"primaryKey"`
+	RoutingKey string     `gorm:"type:varchar(64);not null"`
+	Payload    string     `gorm:"type:text;not null"` // 事件JSON
+	Status     string     `gorm:"type:varchar(20);not null;index:idx_status_id,priority:1"`
+	Attempts   int        `gorm:"not null;default:0"`
+	LastError  string     `gorm:"type:varchar(1000)"`
+	CreatedAt  time.Time  `gorm:"not null"`
+	SentAt     *time.Time `gorm:"comment:发布时间"`
+}
+
+// TableName 指定表名
+func (MessageModel) TableName() string {
+	return "outbox_messages"
+}
+
+// Write 在调用方的事务中写入一条待发布事件
+//
+// 教学要点：
+// tx必须是业务数据所在的事务，事务回滚时事件一起回滚，事务提交后中继才能看到
+func Write(tx *gorm.DB, routingKey string, event interface{}) error {
+	msg, err := NewMessage(routingKey, event)
+	if err != nil {
+		return err
+	}
+	model := MessageModel{
+		RoutingKey: msg.RoutingKey,
+		Payload:    string(msg.Payload),
+		Status:     string(StatusPending),
+		CreatedAt:  time.Now(),
+	}
+	if err := tx.Create(&model).Error; err != nil {
+		return fmt.Errorf("写入发件箱失败: %w", err)
+	}
+	return nil
+}
+
+// gormStore 发件箱存储GORM实现（供Relay使用）
+type gormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore 创建基于GORM的发件箱存储
+func NewGormStore(db *gorm.DB) Store {
+	return &gormStore{db: db}
+}
+
+// FetchPending 按ID升序查询待发布的消息
+func (s *gormStore) FetchPending(ctx context.Context, limit int) ([]*Message, error) {
+	var models []MessageModel
+	err := s.db.WithContext(ctx).
+		Where("status = ?", StatusPending).
+		Order("id ASC").
+		Limit(limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询待发布消息失败: %w", err)
+	}
+
+	msgs := make([]*Message, 0, len(models))
+	for _, m := range models {
+		msgs = append(msgs, &Message{
+			ID:         m.ID,
+			RoutingKey: m.RoutingKey,
+			Payload:    []byte(m.Payload),
+			Status:     Status(m.Status),
+			Attempts:   m.Attempts,
+			LastError:  m.LastError,
+			CreatedAt:  m.CreatedAt,
+			SentAt:     m.SentAt,
+		})
+	}
+	return msgs, nil
+}
+
+// MarkSent 标记已发布
+func (s *gormStore) MarkSent(ctx context.Context, id uint64) error {
+	now := time.Now()
+	err := s.db.WithContext(ctx).
+		Model(&MessageModel{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":  string(StatusSent),
+			"sent_at": &now,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("标记消息已发布失败: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed 记录发布失败，尝试次数达到maxAttempts时转为FAILED
+//
+// 先累加次数再按累加后的次数判断：多个中继实例并发失败时也不会漏判
+func (s *gormStore) MarkFailed(ctx context.Context, id uint64, errMsg string, maxAttempts int) error {
+	db := s.db.WithContext(ctx)
+	err := db.Model(&MessageModel{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": truncate(errMsg, 1000),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("记录消息发布失败出错: %w", err)
+	}
+
+	if maxAttempts <= 0 {
+		return nil
+	}
+	err = db.Model(&MessageModel{}).
+		Where("id = ? AND status = ? AND attempts >= ?", id, StatusPending, maxAttempts).
+		Update("status", string(StatusFailed)).Error
+	if err != nil {
+		return fmt.Errorf("标记消息发布失败出错: %w", err)
+	}
+	return nil
+}
+
+// truncate 按字符截断（避免超出varchar长度）
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max])
+}
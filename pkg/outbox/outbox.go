@@ -0,0 +1,174 @@
+// Package outbox 实现事务性发件箱（Transactional Outbox）
+//
+// 问题：先写数据库再调用Publisher.Publish，两步之间进程崩溃（或RabbitMQ不可用）事件就丢了；
+// 反过来先发消息再写库，写库失败又会发出"不存在的事件"。
+//
+// 解决方案：
+// 1. 业务数据和事件写在同一个数据库事务里（事件写入outbox表），要么都成功要么都失败
+// 2. 独立的Relay定期扫描outbox表，把PENDING的事件发布到消息队列，成功后标记SENT
+// 3. Relay可能在"发布成功、标记失败"之间崩溃，事件会被重复发布：消费者必须幂等
+// 4. 发布失败次数达到上限的消息标记FAILED，不再阻塞后续事件，等待人工排查后改回PENDING重新发布
+//
+// 教学要点：
+// - pkg/outbox定义消息模型、存储接口和Relay，写入由服务的仓储在自己的事务中调用Write完成
+// - 内置GORM实现（NewGormStore + MessageModel），各服务共用同一张outbox_messages表结构
+// - 框架内置MemoryStore，用于单元测试
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status 发件箱消息状态
+type Status string
+
+const (
+	StatusPending Status = "PENDING" // 待发布（含发布失败等待重试）
+	StatusSent    Status = "SENT"    // 已发布
+	StatusFailed  Status = "FAILED"  // 发布失败次数达到上限，不再自动重试
+)
+
+// Message 发件箱消息
+type Message struct {
+	ID         uint64
+	RoutingKey string // 路由键（如payment.succeeded）
+	Payload    []byte // 事件JSON
+	Status     Status
+	Attempts   int    // 发布尝试次数
+	LastError  string // 最近一次发布错误
+	CreatedAt  time.Time
+	SentAt     *time.Time
+}
+
+// NewMessage 创建待发布的消息（event序列化为JSON）
+func NewMessage(routingKey string, event interface{}) (*Message, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("事件序列化失败: %w", err)
+	}
+	return &Message{
+		RoutingKey: routingKey,
+		Payload:    payload,
+		Status:     StatusPending,
+	}, nil
+}
+
+// Store 发件箱存储接口（Relay使用）
+//
+// 写入不在接口中：消息必须与业务数据在同一事务中写入，由服务的仓储实现负责
+type Store interface {
+	// FetchPending 按ID升序查询待发布的消息（保证同一聚合的事件按写入顺序发布）
+	FetchPending(ctx context.Context, limit int) ([]*Message, error)
+
+	// MarkSent 标记消息已发布
+	MarkSent(ctx context.Context, id uint64) error
+
+	// MarkFailed 记录发布失败（Attempts加1）
+	//
+	// 累加后达到maxAttempts（>0）时转为FAILED，否则保持PENDING等待下次重试
+	MarkFailed(ctx context.Context, id uint64, errMsg string, maxAttempts int) error
+}
+
+// ErrMessageNotFound 消息不存在
+var ErrMessageNotFound = errors.New("发件箱消息不存在")
+
+// ==================== MemoryStore ====================
+
+// MemoryStore 内存版发件箱存储
+//
+// ⚠️ 无法与业务数据共享事务，仅用于测试
+type MemoryStore struct {
+	mu       sync.Mutex
+	messages map[uint64]*Message
+	nextID   uint64
+}
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{messages: make(map[uint64]*Message)}
+}
+
+// Add 写入消息（测试中模拟业务事务的写入）
+func (m *MemoryStore) Add(ctx context.Context, msg *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	cp := *msg
+	cp.ID = m.nextID
+	cp.Status = StatusPending
+	cp.CreatedAt = time.Now()
+	m.messages[cp.ID] = &cp
+	msg.ID = cp.ID
+	return nil
+}
+
+// Get 按ID查询消息（测试断言用）
+func (m *MemoryStore) Get(id uint64) (*Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	msg, ok := m.messages[id]
+	if !ok {
+		return nil, false
+	}
+	cp := *msg
+	return &cp, true
+}
+
+// FetchPending 查询待发布的消息
+func (m *MemoryStore) FetchPending(ctx context.Context, limit int) ([]*Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []*Message
+	for _, msg := range m.messages {
+		if msg.Status == StatusPending {
+			cp := *msg
+			result = append(result, &cp)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// MarkSent 标记已发布
+func (m *MemoryStore) MarkSent(ctx context.Context, id uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	msg, ok := m.messages[id]
+	if !ok {
+		return ErrMessageNotFound
+	}
+	now := time.Now()
+	msg.Status = StatusSent
+	msg.SentAt = &now
+	return nil
+}
+
+// MarkFailed 记录发布失败
+func (m *MemoryStore) MarkFailed(ctx context.Context, id uint64, errMsg string, maxAttempts int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	msg, ok := m.messages[id]
+	if !ok {
+		return ErrMessageNotFound
+	}
+	msg.Attempts++
+	msg.LastError = errMsg
+	if maxAttempts > 0 && msg.Attempts >= maxAttempts && msg.Status == StatusPending {
+		msg.Status = StatusFailed
+	}
+	return nil
+}
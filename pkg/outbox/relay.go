@@ -0,0 +1,151 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Publisher 消息发布接口（由pkg/mq.Publisher实现）
+//
+// 消息ID由"中继名称-outbox主键"派生，同一条消息重复发布时ID不变，消费者据此去重
+type Publisher interface {
+	PublishWithID(messageID, routingKey string, message interface{}) error
+}
+
+// Relay 发件箱中继：把outbox表中待发布的消息投递到消息队列
+//
+// 教学要点：
+// 1. 按ID升序发布，遇到发布失败立即结束本轮：
+//   - 失败通常是RabbitMQ不可用，继续发后面的消息也会失败
+//   - 保证同一订单的事件不会乱序（如order.cancelled先于order.created到达）
+//
+// 2. 发布成功但MarkSent失败时，下一轮会重复发布，消费者必须幂等
+// 3. 多实例部署时各实例的Relay可能发布同一条消息，同样依赖消费者幂等
+// 4. 同一条消息发布失败maxAttempts次后标记FAILED，毒消息（如消息过大被拒）不会永远卡住后续事件：
+//   - RabbitMQ长时间不可用时队头消息也会达到上限，上限应覆盖可接受的故障时长（默认100次，按1秒间隔约100秒）
+//   - FAILED消息需人工排查后改回PENDING重新发布
+//
+// 使用示例（服务启动时）：
+//
+//	relay := outbox.NewRelay("order-service", store, publisher)
+//	go relay.Run(ctx)
+type Relay struct {
+	name        string // 中继名称（通常为服务名），作为消息ID前缀避免不同服务的outbox主键冲突
+	store       Store
+	publisher   Publisher
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int // 单条消息最多发布尝试次数
+}
+
+// RelayOption Relay配置项
+type RelayOption func(*Relay)
+
+// WithInterval 设置扫描间隔（默认1秒）
+func WithInterval(d time.Duration) RelayOption {
+	return func(r *Relay) {
+		if d > 0 {
+			r.interval = d
+		}
+	}
+}
+
+// WithBatchSize 设置每轮最多发布的消息数（默认100）
+func WithBatchSize(n int) RelayOption {
+	return func(r *Relay) {
+		if n > 0 {
+			r.batchSize = n
+		}
+	}
+}
+
+// WithMaxAttempts 设置单条消息最多发布尝试次数（默认100次）
+func WithMaxAttempts(n int) RelayOption {
+	return func(r *Relay) {
+		if n > 0 {
+			r.maxAttempts = n
+		}
+	}
+}
+
+// NewRelay 创建发件箱中继
+func NewRelay(name string, store Store, publisher Publisher, opts ...RelayOption) *Relay {
+	r := &Relay{
+		name:        name,
+		store:       store,
+		publisher:   publisher,
+		interval:    time.Second,
+		batchSize:   100,
+		maxAttempts: 100,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run 循环发布待发布消息，直到ctx取消
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	log.Printf("📮 发件箱中继已启动（间隔:%s 批量:%d）", r.interval, r.batchSize)
+	for {
+		if _, err := r.RelayOnce(ctx); err != nil {
+			log.Printf("⚠️ 发件箱中继本轮未完成: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("发件箱中继已停止")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce 发布一批待发布消息，返回成功发布的数量
+//
+// 本批消息全部发布完且数量达到batchSize时继续取下一批，积压的消息尽快清空
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	sent := 0
+	for {
+		msgs, err := r.store.FetchPending(ctx, r.batchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, msg := range msgs {
+			if err := ctx.Err(); err != nil {
+				return sent, err
+			}
+
+			// Payload已是JSON，用RawMessage避免Publisher二次序列化成字符串
+			if err := r.publisher.PublishWithID(r.MessageID(msg), msg.RoutingKey, json.RawMessage(msg.Payload)); err != nil {
+				if markErr := r.store.MarkFailed(ctx, msg.ID, err.Error(), r.maxAttempts); markErr != nil {
+					log.Printf("⚠️ 记录发件箱发布失败出错[%d]: %v", msg.ID, markErr)
+				} else if msg.Attempts+1 >= r.maxAttempts {
+					log.Printf("☠️ 发件箱消息发布失败%d次，已标记FAILED[%d %s]: %v", msg.Attempts+1, msg.ID, msg.RoutingKey, err)
+				}
+				return sent, err
+			}
+			if err := r.store.MarkSent(ctx, msg.ID); err != nil {
+				// 已发布但未标记：下一轮会重复发布（消费者幂等）
+				return sent, err
+			}
+			sent++
+		}
+
+		if len(msgs) < r.batchSize {
+			return sent, nil
+		}
+	}
+}
+
+// MessageID 发件箱消息对应的MQ消息ID
+func (r *Relay) MessageID(msg *Message) string {
+	return fmt.Sprintf("%s-%d", r.name, msg.ID)
+}
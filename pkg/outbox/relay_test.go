@@ -0,0 +1,170 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// fakePublisher 记录发布的消息，failAt>0时第failAt次发布返回错误
+type fakePublisher struct {
+	published []string
+	ids       []string
+	calls     int
+	failAt    int
+}
+
+func (p *fakePublisher) PublishWithID(messageID, routingKey string, message interface{}) error {
+	p.calls++
+	if p.calls == p.failAt {
+		return errors.New("broker unavailable")
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	p.published = append(p.published, routingKey+" "+string(body))
+	p.ids = append(p.ids, messageID)
+	return nil
+}
+
+func addMessage(t *testing.T, store *MemoryStore, routingKey string, event interface{}) *Message {
+	t.Helper()
+	msg, err := NewMessage(routingKey, event)
+	if err != nil {
+		t.Fatalf("创建消息失败: %v", err)
+	}
+	if err := store.Add(context.Background(), msg); err != nil {
+		t.Fatalf("写入消息失败: %v", err)
+	}
+	return msg
+}
+
+// TestRelay_PublishesInOrder 测试按写入顺序发布并标记已发布
+func TestRelay_PublishesInOrder(t *testing.T) {
+	store := NewMemoryStore()
+	first := addMessage(t, store, "order.created", map[string]int{"order_id": 1})
+	second := addMessage(t, store, "order.paid", map[string]int{"order_id": 1})
+
+	pub := &fakePublisher{}
+	n, err := NewRelay("order-service", store, pub).RelayOnce(context.Background())
+	if err != nil {
+		t.Fatalf("发布失败: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("期望发布2条，实际%d条", n)
+	}
+
+	want := []string{`order.created {"order_id":1}`, `order.paid {"order_id":1}`}
+	for i, w := range want {
+		if pub.published[i] != w {
+			t.Errorf("第%d条期望%s，实际%s", i+1, w, pub.published[i])
+		}
+	}
+
+	if pub.ids[0] != "order-service-1" || pub.ids[1] != "order-service-2" {
+		t.Errorf("消息ID应由中继名称和outbox主键派生，实际%v", pub.ids)
+	}
+
+	for _, id := range []uint64{first.ID, second.ID} {
+		msg, _ := store.Get(id)
+		if msg.Status != StatusSent || msg.SentAt == nil {
+			t.Errorf("消息%d期望已发布，实际%s", id, msg.Status)
+		}
+	}
+}
+
+// TestRelay_StopsOnFailure 测试发布失败时本轮停止，失败消息保持待发布并在下一轮重试
+func TestRelay_StopsOnFailure(t *testing.T) {
+	store := NewMemoryStore()
+	first := addMessage(t, store, "payment.succeeded", map[string]int{"order_id": 1})
+	second := addMessage(t, store, "refund.succeeded", map[string]int{"order_id": 1})
+
+	pub := &fakePublisher{failAt: 1}
+	relay := NewRelay("order-service", store, pub)
+
+	n, err := relay.RelayOnce(context.Background())
+	if err == nil {
+		t.Fatal("期望返回发布错误")
+	}
+	if n != 0 || len(pub.published) != 0 {
+		t.Fatalf("失败后不应继续发布后续消息，实际发布%d条", len(pub.published))
+	}
+
+	msg, _ := store.Get(first.ID)
+	if msg.Status != StatusPending || msg.Attempts != 1 || msg.LastError == "" {
+		t.Errorf("失败消息期望PENDING且记录错误，实际%+v", msg)
+	}
+
+	n, err = relay.RelayOnce(context.Background())
+	if err != nil || n != 2 {
+		t.Fatalf("重试期望发布2条，实际%d条（err=%v）", n, err)
+	}
+	if msg, _ := store.Get(second.ID); msg.Status != StatusSent {
+		t.Errorf("第二条消息期望已发布，实际%s", msg.Status)
+	}
+}
+
+// TestRelay_DrainsBacklog 测试积压超过一批时同一轮内连续发布
+func TestRelay_DrainsBacklog(t *testing.T) {
+	store := NewMemoryStore()
+	for i := 0; i < 5; i++ {
+		addMessage(t, store, "order.created", map[string]int{"order_id": i})
+	}
+
+	pub := &fakePublisher{}
+	n, err := NewRelay("order-service", store, pub, WithBatchSize(2)).RelayOnce(context.Background())
+	if err != nil {
+		t.Fatalf("发布失败: %v", err)
+	}
+	if n != 5 {
+		t.Errorf("期望发布5条，实际%d条", n)
+	}
+
+	pending, _ := store.FetchPending(context.Background(), 0)
+	if len(pending) != 0 {
+		t.Errorf("期望无待发布消息，实际%d条", len(pending))
+	}
+}
+
+// TestRelay_MarksFailedAfterMaxAttempts 测试发布失败达到上限的消息标记FAILED，不再阻塞后续消息
+func TestRelay_MarksFailedAfterMaxAttempts(t *testing.T) {
+	store := NewMemoryStore()
+	poison := addMessage(t, store, "order.created", map[string]int{"order_id": 1})
+	next := addMessage(t, store, "order.created", map[string]int{"order_id": 2})
+
+	tests := []struct {
+		round      int
+		wantStatus Status
+	}{
+		{round: 1, wantStatus: StatusPending},
+		{round: 2, wantStatus: StatusPending},
+		{round: 3, wantStatus: StatusFailed},
+	}
+	relay := NewRelay("order-service", store, nil, WithMaxAttempts(3))
+	for _, tt := range tests {
+		// 每轮新建failAt=1的发布者：队头消息的发布总是失败
+		relay.publisher = &fakePublisher{failAt: 1}
+		if _, err := relay.RelayOnce(context.Background()); err == nil {
+			t.Fatalf("第%d轮期望返回发布错误", tt.round)
+		}
+		msg, _ := store.Get(poison.ID)
+		if msg.Attempts != tt.round || msg.Status != tt.wantStatus {
+			t.Errorf("第%d轮期望attempts=%d status=%s，实际%d/%s", tt.round, tt.round, tt.wantStatus, msg.Attempts, msg.Status)
+		}
+	}
+
+	pub := &fakePublisher{}
+	relay.publisher = pub
+	n, err := relay.RelayOnce(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("FAILED消息之后的消息期望正常发布1条，实际%d条（err=%v）", n, err)
+	}
+	if msg, _ := store.Get(next.ID); msg.Status != StatusSent {
+		t.Errorf("后续消息期望已发布，实际%s", msg.Status)
+	}
+	if msg, _ := store.Get(poison.ID); msg.Status != StatusFailed {
+		t.Errorf("FAILED消息不应再被发布，实际%s", msg.Status)
+	}
+}
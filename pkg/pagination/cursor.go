@@ -0,0 +1,59 @@
+// Package pagination 游标分页（Keyset Pagination）的游标编码
+//
+// 为什么需要游标分页？
+// - OFFSET分页：LIMIT 20 OFFSET 10000 要先扫过前10000行再丢弃，越往后翻越慢
+// - 翻页期间有新数据插入：OFFSET整体后移，下一页会重复上一页的最后几条；有数据删除则会漏掉
+// - 游标分页：记住上一页最后一条的排序键，下一页用 WHERE (排序键, id) < (上次的值) 直接定位
+//   - 借助索引直接跳到起点，任何深度的翻页代价都一样
+//   - 新插入的数据不影响已经翻过的位置
+//
+// 教学要点：
+// - 排序键可能重复（同一时刻发布的图书、同价图书），必须加上唯一的ID作为第二排序键
+// - 游标对客户端是不透明的字符串（base64），客户端只负责原样传回，服务端可以随时调整内部格式
+// - 游标记录了生成它的排序方式，换了排序方式的请求不能沿用旧游标
+// - 代价：只能"下一页"，不能直接跳到第N页（无限滚动、App列表的典型场景正好不需要）
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidCursor 游标格式错误或与排序方式不匹配
+var ErrInvalidCursor = errors.New("分页游标无效")
+
+// Cursor 游标：上一页最后一条记录的排序键和ID
+type Cursor struct {
+	Sort  string `json:"s"` // 排序方式（见SortKey），解码时校验
+	Value int64  `json:"v"` // 排序字段的值（时间用UnixNano，按ID排序时与ID相同）
+	ID    uint64 `json:"i"` // 记录ID（排序键相同时的第二排序键）
+}
+
+// SortKey 排序方式的标识，如"created_at:desc"
+func SortKey(sortBy, order string) string {
+	return sortBy + ":" + order
+}
+
+// Encode 编码为不透明的字符串（URL安全，可以直接放在Query参数中）
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c) // 字段都是基本类型，不会失败
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode 解码游标，并校验它是按sort排序生成的
+func Decode(token, sort string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if c.Sort != sort || c.ID == 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
@@ -0,0 +1,51 @@
+package pagination
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestCursor_RoundTrip 测试编码后解码得到原游标
+func TestCursor_RoundTrip(t *testing.T) {
+	sort := SortKey("created_at", "desc")
+	c := Cursor{Sort: sort, Value: 1700000000123000000, ID: 42}
+
+	token := c.Encode()
+	if strings.ContainsAny(token, "+/=") {
+		t.Errorf("游标应为URL安全的字符串，实际%q", token)
+	}
+
+	got, err := Decode(token, sort)
+	if err != nil {
+		t.Fatalf("解码失败: %v", err)
+	}
+	if got != c {
+		t.Errorf("期望%+v，实际%+v", c, got)
+	}
+}
+
+// TestDecode_SortMismatch 测试换了排序方式后旧游标失效
+func TestDecode_SortMismatch(t *testing.T) {
+	token := Cursor{Sort: SortKey("price", "asc"), Value: 5000, ID: 7}.Encode()
+
+	if _, err := Decode(token, SortKey("price", "desc")); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("期望ErrInvalidCursor，实际%v", err)
+	}
+}
+
+// TestDecode_Invalid 测试格式错误的游标
+func TestDecode_Invalid(t *testing.T) {
+	sort := SortKey("created_at", "desc")
+	tokens := []string{
+		"not base64!",
+		"bm90IGpzb24",                         // "not json"
+		Cursor{Sort: sort, Value: 1}.Encode(), // 缺少ID
+	}
+
+	for _, token := range tokens {
+		if _, err := Decode(token, sort); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("游标%q：期望ErrInvalidCursor，实际%v", token, err)
+		}
+	}
+}
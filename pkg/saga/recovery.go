@@ -0,0 +1,249 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CompensateFunc 基于持久化数据的补偿函数
+//
+// data为Action中SetStepData保存的JSON（未保存时为nil），
+// 实现需自行反序列化，并且必须幂等（同一步骤可能被补偿多次）
+type CompensateFunc func(ctx context.Context, data []byte) error
+
+// Recoverer Saga故障恢复器
+//
+// 教学要点：
+// 1. 进程崩溃后内存中的闭包全部丢失，无法直接调用Step.Compensate
+// 2. 解决方案：按"Saga类型 + 步骤名"注册补偿函数，用日志中的Data重建补偿
+// 3. 恢复策略：未完成的Saga向后恢复（补偿），不尝试继续正向执行
+//   - 正向执行可能依赖已过期的上下文（如用户请求早已超时返回）
+//   - 补偿是更安全的终态
+//   - 例外：全部步骤都已SUCCEEDED（只差COMPLETED没写进去），业务已经生效，补记为COMPLETED（向前恢复）
+//
+// 使用示例（服务启动时）：
+//
+//	r := saga.NewRecoverer(store)
+//	r.Register("create_order", "扣减库存", releaseStockFromLog)
+//	r.Register("create_order", "创建订单", cancelOrderFromLog)
+//	n, err := r.Recover(ctx, 30*time.Second)
+type Recoverer struct {
+	store        Store
+	compensators map[string]map[string]registeredCompensator // sagaName → stepName → fn
+	batchSize    int
+}
+
+// registeredCompensator 已注册的补偿函数及其重试策略
+type registeredCompensator struct {
+	fn    CompensateFunc
+	retry RetryPolicy
+}
+
+// NewRecoverer 创建恢复器
+func NewRecoverer(store Store) *Recoverer {
+	return &Recoverer{
+		store:        store,
+		compensators: make(map[string]map[string]registeredCompensator),
+		batchSize:    100,
+	}
+}
+
+// Register 注册某类Saga某个步骤的补偿函数（使用DefaultRetryPolicy）
+func (r *Recoverer) Register(sagaName, stepName string, fn CompensateFunc) {
+	r.RegisterWithRetry(sagaName, stepName, fn, DefaultRetryPolicy)
+}
+
+// RegisterWithRetry 注册补偿函数，并指定恢复和重新驱动时的重试策略
+func (r *Recoverer) RegisterWithRetry(sagaName, stepName string, fn CompensateFunc, retry RetryPolicy) {
+	if r.compensators[sagaName] == nil {
+		r.compensators[sagaName] = make(map[string]registeredCompensator)
+	}
+	r.compensators[sagaName][stepName] = registeredCompensator{fn: fn, retry: retry}
+}
+
+// Recover 恢复未完成的Saga
+//
+// 参数：
+//
+//	staleAfter: 最后更新时间超过该时长的Saga才视为"崩溃遗留"。
+//	            应不小于Saga的超时时间，避免与正在执行的Saga并发补偿
+//
+// 返回：本次处理的Saga数量。单个Saga恢复失败不影响其他Saga，
+// 重试耗尽的步骤写入卡住的补偿记录、Saga标记为FAILED，所有错误聚合后返回。
+func (r *Recoverer) Recover(ctx context.Context, staleAfter time.Duration) (int, error) {
+	logs, err := r.store.ListUnfinished(ctx, time.Now().Add(-staleAfter), r.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("查询未完成saga失败: %w", err)
+	}
+
+	var errs []error
+	for _, l := range logs {
+		if err := r.recoverOne(ctx, l); err != nil {
+			errs = append(errs, fmt.Errorf("saga[%s:%s]: %w", l.Name, l.ID, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return len(logs), fmt.Errorf("%d个saga恢复失败: %v", len(errs), errs)
+	}
+	return len(logs), nil
+}
+
+// recoverOne 恢复单个Saga：全部步骤已成功时补记COMPLETED，否则逆序补偿
+func (r *Recoverer) recoverOne(ctx context.Context, l *Log) error {
+	if l.allSucceeded() {
+		if err := r.store.UpdateStatus(ctx, l.ID, StatusCompleted); err != nil {
+			return fmt.Errorf("更新saga状态失败: %w", err)
+		}
+		return nil
+	}
+
+	if err := r.store.UpdateStatus(ctx, l.ID, StatusCompensating); err != nil {
+		return fmt.Errorf("更新saga状态失败: %w", err)
+	}
+
+	var firstErr error
+	for i := len(l.Steps) - 1; i >= 0; i-- {
+		step := l.Steps[i]
+		if !step.Compensable || !step.Status.needsCompensation() {
+			continue
+		}
+
+		attempts, err := r.compensateStep(ctx, l.Name, &step)
+		if err != nil {
+			step.Status = StepCompensateFailed
+			step.Error = err.Error()
+			if firstErr == nil {
+				firstErr = fmt.Errorf("步骤[%d:%s]补偿失败: %w", step.Index, step.Name, err)
+			}
+			r.recordStuck(ctx, l, &step, attempts, err)
+		} else {
+			step.Status = StepCompensated
+			step.Error = ""
+		}
+
+		r.saveStep(ctx, l.ID, &step)
+	}
+
+	final := StatusCompensated
+	if firstErr != nil {
+		final = StatusFailed
+	}
+	if err := r.store.UpdateStatus(ctx, l.ID, final); err != nil && firstErr == nil {
+		return fmt.Errorf("更新saga状态失败: %w", err)
+	}
+
+	return firstErr
+}
+
+// allSucceeded 正向执行中的Saga是否全部步骤都已SUCCEEDED
+//
+// 已进入COMPENSATING的Saga必须继续补偿；StepCount为0（旧日志）时无法确认步骤是否齐全，按未完成处理
+func (l *Log) allSucceeded() bool {
+	if l.Status != StatusRunning || l.StepCount == 0 || len(l.Steps) != l.StepCount {
+		return false
+	}
+	for _, step := range l.Steps {
+		if step.Status != StepSucceeded {
+			return false
+		}
+	}
+	return true
+}
+
+// compensateStep 查找并按重试策略执行已注册的补偿函数，返回尝试次数
+func (r *Recoverer) compensateStep(ctx context.Context, sagaName string, step *StepLog) (int, error) {
+	c, ok := r.compensators[sagaName][step.Name]
+	if !ok {
+		return 0, fmt.Errorf("未注册补偿函数")
+	}
+	return c.retry.run(ctx, func(ctx context.Context) error {
+		return c.fn(ctx, step.Data)
+	})
+}
+
+// recordStuck 写入卡住的补偿记录，失败只打印告警
+func (r *Recoverer) recordStuck(ctx context.Context, l *Log, step *StepLog, attempts int, cause error) {
+	err := r.store.SaveStuck(ctx, &StuckCompensation{
+		SagaID:    l.ID,
+		SagaName:  l.Name,
+		StepIndex: step.Index,
+		StepName:  step.Name,
+		Data:      step.Data,
+		Attempts:  attempts,
+		LastError: cause.Error(),
+	})
+	if err != nil {
+		fmt.Printf("⚠️ 卡住的补偿记录写入失败[%s 步骤:%s]: %v\n", l.ID, step.Name, err)
+	}
+}
+
+// Redrive 重新驱动一条卡住的补偿（管理接口调用）
+//
+// 流程：
+// 1. 按ID加载记录，已解决的直接返回成功（重复点击安全）
+// 2. 用注册的补偿函数 + 记录中的Data按重试策略执行
+// 3. 成功：记录标记RESOLVED，步骤日志记为COMPENSATED；该Saga没有其他待处理记录时，Saga转为COMPENSATED
+// 4. 失败：累加尝试次数、更新最后错误，返回错误
+//
+// ⚠️ 补偿函数必须幂等：运维可能在下游已恢复、补偿其实已生效后再次重新驱动
+func (r *Recoverer) Redrive(ctx context.Context, stuckID uint64) (*StuckCompensation, error) {
+	stuck, err := r.store.GetStuck(ctx, stuckID)
+	if err != nil {
+		return nil, err
+	}
+	if stuck.Status == StuckResolved {
+		return stuck, nil
+	}
+
+	step := &StepLog{
+		Index:       stuck.StepIndex,
+		Name:        stuck.StepName,
+		Compensable: true,
+		Data:        stuck.Data,
+	}
+	l := &Log{ID: stuck.SagaID, Name: stuck.SagaName}
+
+	attempts, err := r.compensateStep(ctx, stuck.SagaName, step)
+	if err != nil {
+		r.recordStuck(ctx, l, step, attempts, err)
+		step.Status = StepCompensateFailed
+		step.Error = err.Error()
+		r.saveStep(ctx, l.ID, step)
+
+		stuck.Attempts += attempts
+		stuck.LastError = err.Error()
+		return stuck, fmt.Errorf("步骤[%d:%s]补偿失败: %w", step.Index, step.Name, err)
+	}
+
+	if err := r.store.ResolveStuck(ctx, stuck.ID); err != nil {
+		return nil, fmt.Errorf("更新补偿记录失败: %w", err)
+	}
+	step.Status = StepCompensated
+	r.saveStep(ctx, l.ID, step)
+
+	// 该Saga的所有卡住步骤都已解决 → 补偿完成
+	_, pending, err := r.store.ListStuck(ctx, StuckFilter{SagaID: stuck.SagaID, Status: StuckPending, Limit: 1})
+	if err != nil {
+		return nil, fmt.Errorf("查询补偿记录失败: %w", err)
+	}
+	if pending == 0 {
+		if err := r.store.UpdateStatus(ctx, stuck.SagaID, StatusCompensated); err != nil && !errors.Is(err, ErrLogNotFound) {
+			return nil, fmt.Errorf("更新saga状态失败: %w", err)
+		}
+	}
+
+	stuck.Attempts += attempts
+	stuck.LastError = ""
+	stuck.Status = StuckResolved
+	return stuck, nil
+}
+
+// saveStep 写入步骤日志，失败只打印告警
+func (r *Recoverer) saveStep(ctx context.Context, sagaID string, step *StepLog) {
+	if err := r.store.SaveStep(ctx, sagaID, step); err != nil {
+		fmt.Printf("⚠️ saga日志写入失败[%s 步骤:%s]: %v\n", sagaID, step.Name, err)
+	}
+}
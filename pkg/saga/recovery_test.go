@@ -0,0 +1,289 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// runUntilCrash 执行Saga，并把Action中的panic当作"进程崩溃"吞掉
+func runUntilCrash(s *Saga) (crashed bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			crashed = true
+		}
+	}()
+	_ = s.Execute(context.Background())
+	return false
+}
+
+// TestPersistentSaga_Success 测试成功时日志状态为COMPLETED
+func TestPersistentSaga_Success(t *testing.T) {
+	store := NewMemoryStore()
+	s := NewPersistentSaga("", "create_order", 5*time.Second, store)
+
+	s.AddStep("扣减库存",
+		func(ctx context.Context) error {
+			return SetStepData(ctx, map[string]int{"book_id": 1, "quantity": 2})
+		},
+		func(ctx context.Context) error { return nil },
+	)
+	s.AddStep("创建订单", func(ctx context.Context) error { return nil }, nil)
+
+	if err := s.Execute(context.Background()); err != nil {
+		t.Fatalf("Saga执行失败: %v", err)
+	}
+
+	l, ok := store.Get(s.ID())
+	if !ok {
+		t.Fatal("Saga日志不存在")
+	}
+	if l.Status != StatusCompleted {
+		t.Errorf("期望状态%s，实际%s", StatusCompleted, l.Status)
+	}
+	if len(l.Steps) != 2 {
+		t.Fatalf("期望2条步骤日志，实际%d条", len(l.Steps))
+	}
+	for _, step := range l.Steps {
+		if step.Status != StepSucceeded {
+			t.Errorf("步骤[%s]期望%s，实际%s", step.Name, StepSucceeded, step.Status)
+		}
+	}
+	if string(l.Steps[0].Data) != `{"book_id":1,"quantity":2}` {
+		t.Errorf("步骤数据未保存: %s", l.Steps[0].Data)
+	}
+}
+
+// TestPersistentSaga_FailureAndCompensate 测试失败补偿后日志状态为COMPENSATED
+func TestPersistentSaga_FailureAndCompensate(t *testing.T) {
+	store := NewMemoryStore()
+	s := NewPersistentSaga("saga-1", "create_order", 5*time.Second, store)
+
+	s.AddStep("扣减库存",
+		func(ctx context.Context) error { return nil },
+		func(ctx context.Context) error { return nil },
+	)
+	s.AddStep("创建订单",
+		func(ctx context.Context) error { return errors.New("数据库错误") },
+		func(ctx context.Context) error { return nil },
+	)
+
+	if err := s.Execute(context.Background()); err == nil {
+		t.Fatal("Saga应该失败但返回成功")
+	}
+
+	l, _ := store.Get("saga-1")
+	if l.Status != StatusCompensated {
+		t.Errorf("期望状态%s，实际%s", StatusCompensated, l.Status)
+	}
+	if l.Steps[0].Status != StepCompensated {
+		t.Errorf("步骤0期望%s，实际%s", StepCompensated, l.Steps[0].Status)
+	}
+	if l.Steps[1].Status != StepFailed || l.Steps[1].Error != "数据库错误" {
+		t.Errorf("步骤1期望%s(数据库错误)，实际%s(%s)", StepFailed, l.Steps[1].Status, l.Steps[1].Error)
+	}
+}
+
+// TestPersistentSaga_CompensateFailed 测试补偿失败时日志状态为FAILED
+func TestPersistentSaga_CompensateFailed(t *testing.T) {
+	store := NewMemoryStore()
+	s := NewPersistentSaga("saga-1", "create_order", 5*time.Second, store)
+
+	s.AddStep("扣减库存",
+		func(ctx context.Context) error { return nil },
+		func(ctx context.Context) error { return errors.New("inventory-service不可用") },
+	)
+	s.AddStep("创建订单",
+		func(ctx context.Context) error { return errors.New("数据库错误") },
+		nil,
+	)
+
+	_ = s.Execute(context.Background())
+
+	l, _ := store.Get("saga-1")
+	if l.Status != StatusFailed {
+		t.Errorf("期望状态%s，实际%s", StatusFailed, l.Status)
+	}
+	if l.Steps[0].Status != StepCompensateFailed {
+		t.Errorf("步骤0期望%s，实际%s", StepCompensateFailed, l.Steps[0].Status)
+	}
+}
+
+// TestRecoverer_ResumesCrashedSaga 测试进程崩溃后恢复补偿
+//
+// 场景：扣减库存成功 → 创建订单执行中进程崩溃
+// 期望：恢复时逆序补偿"创建订单"（STARTED）和"扣减库存"（SUCCEEDED）
+func TestRecoverer_ResumesCrashedSaga(t *testing.T) {
+	store := NewMemoryStore()
+	s := NewPersistentSaga("saga-1", "create_order", 5*time.Second, store)
+
+	type stockData struct {
+		BookID   uint `json:"book_id"`
+		Quantity int  `json:"quantity"`
+	}
+
+	s.AddStep("查询图书信息", func(ctx context.Context) error { return nil }, nil)
+	s.AddStep("扣减库存",
+		func(ctx context.Context) error {
+			return SetStepData(ctx, stockData{BookID: 7, Quantity: 3})
+		},
+		func(ctx context.Context) error { return nil },
+	)
+	s.AddStep("创建订单",
+		func(ctx context.Context) error {
+			if err := SetStepData(ctx, map[string]uint{"order_id": 99}); err != nil {
+				return err
+			}
+			panic("模拟进程崩溃")
+		},
+		func(ctx context.Context) error { return nil },
+	)
+
+	if !runUntilCrash(s) {
+		t.Fatal("期望模拟崩溃")
+	}
+
+	l, _ := store.Get("saga-1")
+	if l.Status != StatusRunning {
+		t.Fatalf("崩溃后期望状态%s，实际%s", StatusRunning, l.Status)
+	}
+
+	// 服务重启：注册补偿函数并恢复
+	compensated := make([]string, 0)
+	r := NewRecoverer(store)
+	r.Register("create_order", "扣减库存", func(ctx context.Context, data []byte) error {
+		var d stockData
+		if err := json.Unmarshal(data, &d); err != nil {
+			return err
+		}
+		if d.BookID != 7 || d.Quantity != 3 {
+			t.Errorf("补偿数据错误: %+v", d)
+		}
+		compensated = append(compensated, "释放库存")
+		return nil
+	})
+	r.Register("create_order", "创建订单", func(ctx context.Context, data []byte) error {
+		compensated = append(compensated, "取消订单")
+		return nil
+	})
+
+	n, err := r.Recover(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("期望恢复1个saga，实际%d个", n)
+	}
+
+	expected := []string{"取消订单", "释放库存"}
+	if len(compensated) != len(expected) {
+		t.Fatalf("期望补偿%v，实际%v", expected, compensated)
+	}
+	for i := range expected {
+		if compensated[i] != expected[i] {
+			t.Errorf("补偿%d期望'%s'，实际'%s'", i, expected[i], compensated[i])
+		}
+	}
+
+	l, _ = store.Get("saga-1")
+	if l.Status != StatusCompensated {
+		t.Errorf("恢复后期望状态%s，实际%s", StatusCompensated, l.Status)
+	}
+
+	// 再次恢复：已是终态，不应重复补偿
+	n, _ = r.Recover(context.Background(), 0)
+	if n != 0 || len(compensated) != 2 {
+		t.Errorf("终态saga不应被重复恢复: n=%d, compensated=%v", n, compensated)
+	}
+}
+
+// TestRecoverer_SkipsFreshSaga 测试不处理最近仍在更新的Saga
+func TestRecoverer_SkipsFreshSaga(t *testing.T) {
+	store := NewMemoryStore()
+	_ = store.CreateLog(context.Background(), &Log{ID: "saga-1", Name: "create_order", Status: StatusRunning})
+
+	n, err := NewRecoverer(store).Recover(context.Background(), time.Minute)
+	if err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("执行中的saga不应被恢复，实际恢复%d个", n)
+	}
+}
+
+// TestRecoverer_MissingCompensator 测试未注册补偿函数时标记为FAILED
+func TestRecoverer_MissingCompensator(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	_ = store.CreateLog(ctx, &Log{ID: "saga-1", Name: "create_order", Status: StatusRunning})
+	_ = store.SaveStep(ctx, "saga-1", &StepLog{Index: 0, Name: "扣减库存", Status: StepSucceeded, Compensable: true})
+
+	if _, err := NewRecoverer(store).Recover(ctx, 0); err == nil {
+		t.Fatal("未注册补偿函数应返回错误")
+	}
+
+	l, _ := store.Get("saga-1")
+	if l.Status != StatusFailed {
+		t.Errorf("期望状态%s，实际%s", StatusFailed, l.Status)
+	}
+	if l.Steps[0].Status != StepCompensateFailed {
+		t.Errorf("步骤期望%s，实际%s", StepCompensateFailed, l.Steps[0].Status)
+	}
+}
+
+// completionFailingStore COMPLETED状态写入失败的存储（模拟最后一次写库时数据库抖动）
+type completionFailingStore struct {
+	*MemoryStore
+}
+
+func (s completionFailingStore) UpdateStatus(ctx context.Context, sagaID string, status Status) error {
+	if status == StatusCompleted {
+		return errors.New("数据库连接中断")
+	}
+	return s.MemoryStore.UpdateStatus(ctx, sagaID, status)
+}
+
+// TestRecoverer_RollsForwardCompletedSaga 测试全部步骤成功但COMPLETED未写入时，恢复补记COMPLETED而不是补偿
+func TestRecoverer_RollsForwardCompletedSaga(t *testing.T) {
+	store := NewMemoryStore()
+	s := NewPersistentSaga("saga-1", "create_order", 5*time.Second, completionFailingStore{store})
+
+	compensated := 0
+	s.AddStep("扣减库存",
+		func(ctx context.Context) error { return nil },
+		func(ctx context.Context) error { compensated++; return nil },
+	)
+	s.AddStep("创建订单", func(ctx context.Context) error { return nil }, nil)
+
+	err := s.Execute(context.Background())
+	if !errors.Is(err, ErrCompletionNotLogged) {
+		t.Fatalf("期望ErrCompletionNotLogged，实际%v", err)
+	}
+	if compensated != 0 {
+		t.Errorf("业务已生效，不应补偿，实际补偿%d次", compensated)
+	}
+
+	l, _ := store.Get("saga-1")
+	if l.Status != StatusRunning {
+		t.Fatalf("期望状态%s，实际%s", StatusRunning, l.Status)
+	}
+
+	r := NewRecoverer(store)
+	r.Register("create_order", "扣减库存", func(ctx context.Context, data []byte) error {
+		compensated++
+		return nil
+	})
+	if _, err := r.Recover(context.Background(), 0); err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	if compensated != 0 {
+		t.Errorf("恢复时不应补偿，实际补偿%d次", compensated)
+	}
+
+	l, _ = store.Get("saga-1")
+	if l.Status != StatusCompleted {
+		t.Errorf("恢复后期望状态%s，实际%s", StatusCompleted, l.Status)
+	}
+}
@@ -0,0 +1,81 @@
+package saga
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RetryPolicy 补偿重试策略
+//
+// 教学要点：
+// 1. 补偿失败多数是瞬时故障（网络抖动、下游重启），重试几次通常能成功
+// 2. 指数退避：每次等待时间翻倍，避免重试风暴压垮刚恢复的下游
+// 3. 重试耗尽后写入"卡住的补偿"记录（StuckCompensation），交给人工重新驱动
+//
+// 示例（最多3次，间隔100ms → 200ms）：
+//
+//	RetryPolicy{MaxAttempts: 3, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+type RetryPolicy struct {
+	MaxAttempts    int           // 最大尝试次数（含第一次），<=1表示不重试
+	InitialBackoff time.Duration // 第一次重试前的等待时间
+	MaxBackoff     time.Duration // 单次等待上限（0表示不设上限）
+	Multiplier     float64       // 退避倍数（<1按1处理，即固定间隔）
+}
+
+// DefaultRetryPolicy 默认补偿重试策略（AddStep和Recoverer.Register使用）
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	Multiplier:     2,
+}
+
+// NoRetry 不重试（失败一次即记为卡住）
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// backoff 第attempt次失败后的等待时间（attempt从1开始）
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	for i := 1; i < attempt; i++ {
+		d = time.Duration(float64(d) * multiplier)
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return d
+}
+
+// run 按策略执行fn，返回实际尝试次数和最后一次错误
+//
+// ctx取消时立即停止重试（服务关闭时不阻塞）
+func (p RetryPolicy) run(ctx context.Context, fn func(ctx context.Context) error) (int, error) {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err = fn(ctx); err == nil {
+			return attempt, nil
+		}
+		if attempt == maxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return attempt, fmt.Errorf("%w（重试被取消: %v）", err, ctx.Err())
+		case <-time.After(p.backoff(attempt)):
+		}
+	}
+	return maxAttempts, err
+}
@@ -0,0 +1,212 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fastRetry 测试用重试策略（避免测试变慢）
+var fastRetry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, Multiplier: 2}
+
+// TestRetryPolicy_Backoff 测试指数退避与上限
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond, Multiplier: 2}
+
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, want := range expected {
+		if got := p.backoff(i + 1); got != want {
+			t.Errorf("第%d次退避期望%v，实际%v", i+1, want, got)
+		}
+	}
+}
+
+// TestRetryPolicy_StopsOnCancel 测试ctx取消时停止重试
+func TestRetryPolicy_StopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Hour}
+
+	calls := 0
+	attempts, err := p.run(ctx, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return errors.New("下游不可用")
+	})
+	if err == nil {
+		t.Fatal("期望返回错误")
+	}
+	if attempts != 1 || calls != 1 {
+		t.Errorf("取消后不应继续重试: attempts=%d, calls=%d", attempts, calls)
+	}
+}
+
+// TestPersistentSaga_CompensateRetrySucceeds 测试补偿重试成功后不产生卡住记录
+func TestPersistentSaga_CompensateRetrySucceeds(t *testing.T) {
+	store := NewMemoryStore()
+	s := NewPersistentSaga("saga-1", "create_order", 5*time.Second, store)
+
+	calls := 0
+	s.AddStepWithRetry("扣减库存",
+		func(ctx context.Context) error { return nil },
+		func(ctx context.Context) error {
+			calls++
+			if calls < 3 {
+				return errors.New("inventory-service不可用")
+			}
+			return nil
+		},
+		fastRetry,
+	)
+	s.AddStep("创建订单", func(ctx context.Context) error { return errors.New("数据库错误") }, nil)
+
+	_ = s.Execute(context.Background())
+
+	if calls != 3 {
+		t.Errorf("期望补偿调用3次，实际%d次", calls)
+	}
+	l, _ := store.Get("saga-1")
+	if l.Status != StatusCompensated {
+		t.Errorf("期望状态%s，实际%s", StatusCompensated, l.Status)
+	}
+	if _, total, _ := store.ListStuck(context.Background(), StuckFilter{}); total != 0 {
+		t.Errorf("重试成功不应产生卡住记录，实际%d条", total)
+	}
+}
+
+// TestPersistentSaga_CompensateRetryExhausted 测试重试耗尽后写入卡住的补偿记录
+func TestPersistentSaga_CompensateRetryExhausted(t *testing.T) {
+	store := NewMemoryStore()
+	s := NewPersistentSaga("saga-1", "create_order", 5*time.Second, store)
+
+	calls := 0
+	s.AddStepWithRetry("扣减库存",
+		func(ctx context.Context) error {
+			return SetStepData(ctx, map[string]int{"book_id": 7})
+		},
+		func(ctx context.Context) error {
+			calls++
+			return errors.New("inventory-service不可用")
+		},
+		fastRetry,
+	)
+	s.AddStep("创建订单", func(ctx context.Context) error { return errors.New("数据库错误") }, nil)
+
+	_ = s.Execute(context.Background())
+
+	if calls != 3 {
+		t.Errorf("期望补偿调用3次，实际%d次", calls)
+	}
+
+	stuck, total, err := store.ListStuck(context.Background(), StuckFilter{Status: StuckPending})
+	if err != nil {
+		t.Fatalf("查询卡住记录失败: %v", err)
+	}
+	if total != 1 {
+		t.Fatalf("期望1条卡住记录，实际%d条", total)
+	}
+	st := stuck[0]
+	if st.SagaID != "saga-1" || st.SagaName != "create_order" || st.StepName != "扣减库存" || st.StepIndex != 0 {
+		t.Errorf("卡住记录字段错误: %+v", st)
+	}
+	if st.Attempts != 3 || st.LastError != "inventory-service不可用" {
+		t.Errorf("期望尝试3次(inventory-service不可用)，实际%d次(%s)", st.Attempts, st.LastError)
+	}
+	if string(st.Data) != `{"book_id":7}` {
+		t.Errorf("补偿数据未保存: %s", st.Data)
+	}
+}
+
+// TestRecoverer_RecordsStuck 测试恢复时重试耗尽写入卡住记录
+func TestRecoverer_RecordsStuck(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	_ = store.CreateLog(ctx, &Log{ID: "saga-1", Name: "create_order", Status: StatusRunning})
+	_ = store.SaveStep(ctx, "saga-1", &StepLog{Index: 0, Name: "扣减库存", Status: StepSucceeded, Compensable: true})
+
+	calls := 0
+	r := NewRecoverer(store)
+	r.RegisterWithRetry("create_order", "扣减库存", func(ctx context.Context, data []byte) error {
+		calls++
+		return errors.New("inventory-service不可用")
+	}, fastRetry)
+
+	if _, err := r.Recover(ctx, 0); err == nil {
+		t.Fatal("补偿失败应返回错误")
+	}
+	if calls != 3 {
+		t.Errorf("期望补偿调用3次，实际%d次", calls)
+	}
+	if _, total, _ := store.ListStuck(ctx, StuckFilter{SagaID: "saga-1", Status: StuckPending}); total != 1 {
+		t.Errorf("期望1条卡住记录，实际%d条", total)
+	}
+}
+
+// TestRecoverer_Redrive 测试重新驱动卡住的补偿
+//
+// 场景：两个步骤补偿都卡住 → 第一次重新驱动失败（累加次数）→ 逐个驱动成功 → Saga转为COMPENSATED
+func TestRecoverer_Redrive(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	_ = store.CreateLog(ctx, &Log{ID: "saga-1", Name: "create_order", Status: StatusFailed})
+	for i, name := range []string{"扣减库存", "创建订单"} {
+		_ = store.SaveStep(ctx, "saga-1", &StepLog{Index: i, Name: name, Status: StepCompensateFailed, Compensable: true})
+		_ = store.SaveStuck(ctx, &StuckCompensation{
+			SagaID: "saga-1", SagaName: "create_order", StepIndex: i, StepName: name,
+			Data: []byte(`{}`), Attempts: 3, LastError: "下游不可用",
+		})
+	}
+
+	downstreamUp := false
+	r := NewRecoverer(store)
+	r.RegisterWithRetry("create_order", "扣减库存", func(ctx context.Context, data []byte) error {
+		if !downstreamUp {
+			return errors.New("仍不可用")
+		}
+		return nil
+	}, NoRetry)
+	r.RegisterWithRetry("create_order", "创建订单", func(ctx context.Context, data []byte) error {
+		return nil
+	}, NoRetry)
+
+	// 下游仍不可用：重新驱动失败，尝试次数累加
+	if _, err := r.Redrive(ctx, 1); err == nil {
+		t.Fatal("下游不可用时重新驱动应失败")
+	}
+	st, _ := store.GetStuck(ctx, 1)
+	if st.Status != StuckPending || st.Attempts != 4 || st.LastError != "仍不可用" {
+		t.Errorf("期望PENDING/4次/仍不可用，实际%s/%d次/%s", st.Status, st.Attempts, st.LastError)
+	}
+
+	// 下游恢复：驱动第一条，Saga仍有待处理记录
+	downstreamUp = true
+	if _, err := r.Redrive(ctx, 1); err != nil {
+		t.Fatalf("重新驱动失败: %v", err)
+	}
+	l, _ := store.Get("saga-1")
+	if l.Status != StatusFailed {
+		t.Errorf("仍有卡住记录时Saga应保持%s，实际%s", StatusFailed, l.Status)
+	}
+	if l.Steps[0].Status != StepCompensated {
+		t.Errorf("步骤0期望%s，实际%s", StepCompensated, l.Steps[0].Status)
+	}
+
+	// 驱动第二条：全部解决，Saga转为COMPENSATED
+	if _, err := r.Redrive(ctx, 2); err != nil {
+		t.Fatalf("重新驱动失败: %v", err)
+	}
+	l, _ = store.Get("saga-1")
+	if l.Status != StatusCompensated {
+		t.Errorf("期望状态%s，实际%s", StatusCompensated, l.Status)
+	}
+
+	// 重复驱动已解决的记录：直接成功
+	st, err := r.Redrive(ctx, 1)
+	if err != nil || st.Status != StuckResolved {
+		t.Errorf("重复驱动应直接成功: %v, %+v", err, st)
+	}
+
+	if _, err := r.Redrive(ctx, 99); !errors.Is(err, ErrStuckNotFound) {
+		t.Errorf("期望ErrStuckNotFound，实际%v", err)
+	}
+}
@@ -8,11 +8,13 @@
 // 教学要点：
 // - Saga vs 2PC（两阶段提交）的区别
 // - 补偿操作的幂等性设计
-// - 超时控制与故障恢复
+// - 超时控制与故障恢复（Saga日志 + 启动恢复，见store.go、recovery.go）
 package saga
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 )
@@ -23,10 +25,14 @@ import (
 // 1. Action是正向操作（如扣减库存、创建订单）
 // 2. Compensate是补偿操作（如释放库存、取消订单）
 // 3. 每个操作都必须支持幂等（允许重试）
+// 4. Retry只作用于补偿：正向失败直接触发补偿，补偿失败则按策略重试
 type Step struct {
 	Name       string                          // 步骤名称（用于日志和调试）
 	Action     func(ctx context.Context) error // 正向操作
 	Compensate func(ctx context.Context) error // 补偿操作
+	Retry      RetryPolicy                     // 补偿重试策略
+
+	index int // 步骤序号（写日志用）
 }
 
 // Saga 表示一个Saga事务
@@ -34,6 +40,12 @@ type Saga struct {
 	steps    []Step        // 所有步骤
 	executed []Step        // 已执行的步骤（用于补偿）
 	timeout  time.Duration // 整体超时时间
+
+	// 持久化相关（store为nil时退化为纯内存Saga）
+	id       string    // Saga实例ID
+	name     string    // Saga类型，恢复时据此查找补偿函数
+	store    Store     // Saga日志存储
+	stepLogs []StepLog // 步骤日志的内存副本（每次写入整条覆盖）
 }
 
 // NewSaga 创建一个新的Saga事务
@@ -55,6 +67,47 @@ func NewSaga(timeout time.Duration) *Saga {
 	}
 }
 
+// NewPersistentSaga 创建一个带执行日志的Saga事务
+//
+// 与NewSaga的区别：
+// 1. 执行前写入Saga日志，每个步骤开始/成功/失败/补偿都会落库
+// 2. 进程崩溃后，Recoverer根据日志找到未完成的Saga并继续补偿
+//
+// 参数：
+//
+//	id:    Saga实例ID（为空时自动生成）
+//	name:  Saga类型（如"create_order"），必须与Recoverer.Register的名称一致
+//	store: 日志存储（生产环境用MySQL实现）
+//
+// 要让崩溃后的补偿可执行，Action需要调用SetStepData保存补偿所需的数据：
+//
+//	saga.AddStep("扣减库存",
+//	    func(ctx context.Context) error {
+//	        if err := saga.SetStepData(ctx, items); err != nil { // 先落库，再调用下游
+//	            return err
+//	        }
+//	        return deduct(ctx, items)
+//	    },
+//	    func(ctx context.Context) error { return release(ctx, items) },
+//	)
+func NewPersistentSaga(id, name string, timeout time.Duration, store Store) *Saga {
+	if id == "" {
+		id = GenerateID()
+	}
+	return &Saga{
+		steps:   make([]Step, 0),
+		timeout: timeout,
+		id:      id,
+		name:    name,
+		store:   store,
+	}
+}
+
+// ID 返回Saga实例ID（纯内存Saga为空字符串）
+func (s *Saga) ID() string {
+	return s.id
+}
+
 // AddStep 添加一个Saga步骤
 //
 // 设计原则：
@@ -68,13 +121,30 @@ func NewSaga(timeout time.Duration) *Saga {
 // ✅ DO: 补偿操作完全独立
 // 正确示例：每个步骤的补偿只依赖自己的Action结果
 func (s *Saga) AddStep(name string, action, compensate func(ctx context.Context) error) {
+	s.AddStepWithRetry(name, action, compensate, DefaultRetryPolicy)
+}
+
+// AddStepWithRetry 添加一个Saga步骤，并指定补偿重试策略
+//
+// 不同步骤的补偿代价不同：
+// - 释放库存：下游可能短暂不可用，值得多重试几次（丢失即库存泄漏）
+// - 删除缓存：失败影响小，NoRetry即可
+func (s *Saga) AddStepWithRetry(name string, action, compensate func(ctx context.Context) error, retry RetryPolicy) {
 	s.steps = append(s.steps, Step{
 		Name:       name,
 		Action:     action,
 		Compensate: compensate,
+		Retry:      retry,
+		index:      len(s.steps),
 	})
 }
 
+// ErrCompletionNotLogged 全部步骤都已成功，但Saga的COMPLETED状态写入失败
+//
+// 业务已经生效（不会补偿），调用方应按成功处理并记录告警；
+// 日志停留在RUNNING，恢复任务看到全部步骤SUCCEEDED后补记为COMPLETED（见Recoverer）
+var ErrCompletionNotLogged = errors.New("saga已完成但状态写入失败")
+
 // Execute 执行Saga事务
 //
 // 执行流程：
@@ -90,6 +160,11 @@ func (s *Saga) AddStep(name string, action, compensate func(ctx context.Context)
 // - Action和Compensate都必须支持幂等
 // - 原因：网络故障可能导致重试
 //
+// 持久化Saga（NewPersistentSaga）额外保证：
+// - 日志创建失败时不执行任何步骤（没有日志就无法恢复）
+// - 步骤STARTED日志写入失败时按步骤失败处理（先写日志，后执行）
+// - 全部步骤成功但COMPLETED写入失败时返回ErrCompletionNotLogged（不补偿，由恢复任务补记为COMPLETED）
+//
 // ⚠️ 注意事项：
 // 1. 补偿操作可能失败（需要人工介入或重试机制）
 // 2. Saga保证"最终一致性"，而非"强一致性"
@@ -102,6 +177,13 @@ func (s *Saga) Execute(ctx context.Context) error {
 		defer cancel()
 	}
 
+	// 写入Saga日志
+	if err := s.begin(ctx); err != nil {
+		return fmt.Errorf("创建saga日志失败: %w", err)
+	}
+
+	unlogged := false // 是否有步骤的SUCCEEDED日志写入失败
+
 	// 按顺序执行每个步骤的Action
 	for i, step := range s.steps {
 		select {
@@ -112,19 +194,40 @@ func (s *Saga) Execute(ctx context.Context) error {
 		default:
 		}
 
+		// 先写STARTED日志，再执行Action
+		if err := s.saveStep(ctx, i, StepStarted, ""); err != nil {
+			s.compensate(context.Background())
+			return fmt.Errorf("步骤[%d:%s]日志写入失败: %w", i, step.Name, err)
+		}
+
 		// 执行正向操作
 		if step.Action != nil {
-			if err := step.Action(ctx); err != nil {
+			if err := step.Action(withStepRecorder(ctx, s, i)); err != nil {
 				// 执行失败，触发补偿
+				s.logStep(context.Background(), i, StepFailed, err.Error())
 				s.compensate(context.Background())
 				return fmt.Errorf("步骤[%d:%s]执行失败: %w", i, step.Name, err)
 			}
 		}
 
+		// 成功日志写入失败不影响流程：崩溃恢复时STARTED同样会被补偿
+		if err := s.saveStep(ctx, i, StepSucceeded, ""); err != nil {
+			fmt.Printf("⚠️ saga日志写入失败[%s 步骤:%s 状态:%s]: %v\n", s.id, step.Name, StepSucceeded, err)
+			unlogged = true
+		}
+
 		// 记录已执行的步骤（用于补偿）
 		s.executed = append(s.executed, step)
 	}
 
+	if err := s.saveStatus(ctx, StatusCompleted); err != nil {
+		// 有步骤停留在STARTED：恢复任务会补偿这个Saga，与其让调用方按成功处理，不如现在就补偿
+		if unlogged {
+			s.compensate(context.Background())
+			return fmt.Errorf("saga状态写入失败: %w", err)
+		}
+		return fmt.Errorf("%w: %v", ErrCompletionNotLogged, err)
+	}
 	return nil
 }
 
@@ -132,8 +235,8 @@ func (s *Saga) Execute(ctx context.Context) error {
 //
 // 补偿原则：
 // 1. 按逆序执行已完成步骤的Compensate
-// 2. 即使某个Compensate失败，也继续执行后续补偿（尽最大努力）
-// 3. 记录所有补偿错误，返回聚合错误
+// 2. 单个Compensate失败时按Step.Retry重试（指数退避）
+// 3. 重试耗尽后写入卡住的补偿记录，继续执行后续补偿（尽最大努力）
 //
 // 为什么逆序？
 //   - 依赖关系：后执行的步骤可能依赖先执行的步骤
@@ -141,30 +244,157 @@ func (s *Saga) Execute(ctx context.Context) error {
 //     补偿时应先"释放库存"，再"取消订单"
 //
 // 补偿失败的处理：
-// - 记录日志（需要人工介入）
-// - 可选：发送告警通知
-// - 可选：写入死信队列，稍后重试
+// - 重试耗尽：步骤日志记为COMPENSATE_FAILED，Saga记为FAILED
+// - 持久化Saga额外写入StuckCompensation，管理接口可列出并重新驱动
+// - 纯内存Saga没有存储，只能打印日志
 func (s *Saga) compensate(ctx context.Context) {
+	s.logStatus(ctx, StatusCompensating)
+
+	failed := false
+
 	// 逆序执行补偿操作
 	for i := len(s.executed) - 1; i >= 0; i-- {
 		step := s.executed[i]
 
 		if step.Compensate != nil {
-			if err := step.Compensate(ctx); err != nil {
-				// ⚠️ 补偿失败：记录日志，继续执行后续补偿
-				// 生产环境应该：
-				// 1. 记录到专门的补偿失败表
-				// 2. 发送告警通知运维人员
-				// 3. 提供重试机制或人工介入接口
-				fmt.Printf("⚠️ 补偿失败[步骤:%s]: %v\n", step.Name, err)
+			attempts, err := step.Retry.run(ctx, step.Compensate)
+			if err != nil {
+				// ⚠️ 补偿重试耗尽：记录卡住的补偿，继续执行后续补偿
+				fmt.Printf("⚠️ 补偿失败[步骤:%s 尝试:%d次]: %v\n", step.Name, attempts, err)
+				s.logStep(ctx, step.index, StepCompensateFailed, err.Error())
+				s.recordStuck(ctx, step.index, attempts, err)
+				failed = true
+				continue
 			}
+			s.logStep(ctx, step.index, StepCompensated, "")
 		}
 	}
 
+	if failed {
+		s.logStatus(ctx, StatusFailed)
+	} else {
+		s.logStatus(ctx, StatusCompensated)
+	}
+
 	// 清空已执行列表
 	s.executed = nil
 }
 
+// ==================== Saga日志 ====================
+
+// stepRecorderKey Context中保存当前步骤记录器的Key
+type stepRecorderKey struct{}
+
+// stepRecorder 当前正在执行的步骤（供SetStepData定位）
+type stepRecorder struct {
+	saga  *Saga
+	index int
+}
+
+func withStepRecorder(ctx context.Context, s *Saga, index int) context.Context {
+	if s.store == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, stepRecorderKey{}, &stepRecorder{saga: s, index: index})
+}
+
+// SetStepData 保存当前步骤的补偿数据
+//
+// 教学要点：
+// 1. 只能在Action中调用（ctx由Execute注入当前步骤信息）
+// 2. 立即落库：应在调用下游服务之前保存，否则"下游已生效、数据未落库"时崩溃，恢复任务就不知道该补偿什么
+// 3. 非持久化Saga中调用是空操作，业务代码无需区分
+func SetStepData(ctx context.Context, v interface{}) error {
+	rec, ok := ctx.Value(stepRecorderKey{}).(*stepRecorder)
+	if !ok {
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("序列化步骤数据失败: %w", err)
+	}
+
+	s := rec.saga
+	s.stepLogs[rec.index].Data = data
+	return s.store.SaveStep(ctx, s.id, &s.stepLogs[rec.index])
+}
+
+// begin 创建Saga日志
+func (s *Saga) begin(ctx context.Context) error {
+	if s.store == nil {
+		return nil
+	}
+
+	s.stepLogs = make([]StepLog, len(s.steps))
+	for i, step := range s.steps {
+		s.stepLogs[i] = StepLog{
+			Index:       i,
+			Name:        step.Name,
+			Compensable: step.Compensate != nil,
+		}
+	}
+
+	return s.store.CreateLog(ctx, &Log{
+		ID:        s.id,
+		Name:      s.name,
+		Status:    StatusRunning,
+		StepCount: len(s.steps),
+	})
+}
+
+// saveStep 写入步骤日志，返回错误
+func (s *Saga) saveStep(ctx context.Context, index int, status StepStatus, errMsg string) error {
+	if s.store == nil {
+		return nil
+	}
+
+	s.stepLogs[index].Status = status
+	s.stepLogs[index].Error = errMsg
+	return s.store.SaveStep(ctx, s.id, &s.stepLogs[index])
+}
+
+// logStep 写入步骤日志，失败只打印告警
+func (s *Saga) logStep(ctx context.Context, index int, status StepStatus, errMsg string) {
+	if err := s.saveStep(ctx, index, status, errMsg); err != nil {
+		fmt.Printf("⚠️ saga日志写入失败[%s 步骤:%s 状态:%s]: %v\n", s.id, s.steps[index].Name, status, err)
+	}
+}
+
+// recordStuck 写入卡住的补偿记录，失败只打印告警
+func (s *Saga) recordStuck(ctx context.Context, index, attempts int, cause error) {
+	if s.store == nil {
+		return
+	}
+	err := s.store.SaveStuck(ctx, &StuckCompensation{
+		SagaID:    s.id,
+		SagaName:  s.name,
+		StepIndex: index,
+		StepName:  s.steps[index].Name,
+		Data:      s.stepLogs[index].Data,
+		Attempts:  attempts,
+		LastError: cause.Error(),
+	})
+	if err != nil {
+		fmt.Printf("⚠️ 卡住的补偿记录写入失败[%s 步骤:%s]: %v\n", s.id, s.steps[index].Name, err)
+	}
+}
+
+// saveStatus 更新Saga整体状态，返回错误
+func (s *Saga) saveStatus(ctx context.Context, status Status) error {
+	if s.store == nil {
+		return nil
+	}
+	return s.store.UpdateStatus(ctx, s.id, status)
+}
+
+// logStatus 更新Saga整体状态，失败只打印告警
+func (s *Saga) logStatus(ctx context.Context, status Status) {
+	if err := s.saveStatus(ctx, status); err != nil {
+		fmt.Printf("⚠️ saga状态更新失败[%s 状态:%s]: %v\n", s.id, status, err)
+	}
+}
+
 // ==================== DO/DON'T 对比示例 ====================
 
 // ❌ DON'T: 补偿操作不幂等
@@ -288,6 +518,7 @@ func (s *Saga) compensate(ctx context.Context) {
 //
 // 关键学习点：
 // 1. 补偿操作必须幂等（使用idempotency_key）
-// 2. 补偿失败需要人工介入（记录日志、告警）
+// 2. 补偿失败先按策略重试，重试耗尽再落"卡住的补偿"记录，由管理接口重新驱动
 // 3. Saga期间数据可能不一致（业务需容忍）
-// 4. 生产环境建议使用DTM等成熟框架（支持故障恢复）
+// 4. 进程崩溃会丢失内存中的executed列表：持久化Saga日志 + 启动恢复才能保证补偿最终执行
+// 5. 生产环境建议使用DTM等成熟框架（支持故障恢复）
@@ -0,0 +1,370 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status Saga整体状态
+//
+// 状态流转：
+//
+//	RUNNING ──成功──> COMPLETED
+//	   │
+//	   └──失败/超时/崩溃──> COMPENSATING ──> COMPENSATED
+//	                              │
+//	                              └──补偿重试耗尽──> FAILED（写入卡住的补偿记录，人工重新驱动）
+//
+// 恢复任务只处理RUNNING和COMPENSATING状态的Saga（"未完成"）
+type Status string
+
+const (
+	StatusRunning      Status = "RUNNING"      // 正向执行中
+	StatusCompleted    Status = "COMPLETED"    // 全部步骤成功
+	StatusCompensating Status = "COMPENSATING" // 补偿中
+	StatusCompensated  Status = "COMPENSATED"  // 补偿完成
+	StatusFailed       Status = "FAILED"       // 补偿失败（重新驱动成功后转为COMPENSATED）
+)
+
+// StepStatus 单个步骤状态
+//
+// 教学要点：
+// STARTED是关键状态——写入STARTED后才执行Action（先写日志，后执行，即WAL思想）。
+// 如果进程在Action执行中崩溃，日志停留在STARTED，
+// 恢复时无法确定Action是否已生效，只能按"已生效"处理并执行补偿（补偿必须幂等）。
+type StepStatus string
+
+const (
+	StepStarted          StepStatus = "STARTED"           // 已开始（Action可能已生效）
+	StepSucceeded        StepStatus = "SUCCEEDED"         // Action成功
+	StepFailed           StepStatus = "FAILED"            // Action失败（不需要补偿）
+	StepCompensated      StepStatus = "COMPENSATED"       // 补偿成功
+	StepCompensateFailed StepStatus = "COMPENSATE_FAILED" // 补偿失败
+)
+
+// needsCompensation 该步骤在恢复时是否需要补偿
+func (s StepStatus) needsCompensation() bool {
+	return s == StepStarted || s == StepSucceeded || s == StepCompensateFailed
+}
+
+// Log Saga执行日志（一次Saga执行对应一条）
+type Log struct {
+	ID        string    // Saga实例ID（全局唯一）
+	Name      string    // Saga类型（如create_order），恢复时据此查找补偿函数
+	Status    Status    // 整体状态
+	StepCount int       // 步骤总数（恢复时据此判断是否全部步骤都已成功，为0表示未知）
+	Steps     []StepLog // 步骤日志（按Index升序）
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// StepLog 步骤执行日志
+//
+// 设计要点：
+// Data保存补偿所需的数据（JSON），由Action通过SetStepData写入。
+// 进程重启后闭包已丢失，恢复任务只能依赖Data重建补偿操作。
+type StepLog struct {
+	Index       int        // 步骤序号（从0开始）
+	Name        string     // 步骤名称
+	Status      StepStatus // 步骤状态
+	Compensable bool       // 是否定义了补偿操作
+	Data        []byte     // 补偿数据（JSON）
+	Error       string     // 最近一次错误信息
+	UpdatedAt   time.Time
+}
+
+// Store Saga日志存储接口
+//
+// 教学要点：
+// 1. pkg/saga只定义接口，具体存储由服务实现（MySQL/Redis等）
+// 2. 框架内置MemoryStore，用于单元测试和本地开发
+// 3. SaveStep语义为"按(sagaID, index)覆盖写入"，实现需保证幂等
+type Store interface {
+	// CreateLog 创建Saga日志（Execute开始时调用）
+	CreateLog(ctx context.Context, log *Log) error
+
+	// UpdateStatus 更新Saga整体状态
+	UpdateStatus(ctx context.Context, sagaID string, status Status) error
+
+	// SaveStep 写入步骤日志（不存在则插入，存在则覆盖）
+	SaveStep(ctx context.Context, sagaID string, step *StepLog) error
+
+	// ListUnfinished 查询未完成的Saga（RUNNING/COMPENSATING），含步骤日志
+	//
+	// updatedBefore：只返回最后更新时间早于该时间的Saga，
+	// 避免把其他实例正在执行中的Saga当成"崩溃遗留"
+	ListUnfinished(ctx context.Context, updatedBefore time.Time, limit int) ([]*Log, error)
+
+	// SaveStuck 写入卡住的补偿记录（按(sagaID, stepIndex)合并，Attempts累加，状态重置为PENDING）
+	SaveStuck(ctx context.Context, stuck *StuckCompensation) error
+
+	// GetStuck 按ID查询卡住的补偿记录，不存在返回ErrStuckNotFound
+	GetStuck(ctx context.Context, id uint64) (*StuckCompensation, error)
+
+	// ListStuck 分页查询卡住的补偿记录（按创建时间升序），返回记录和总数
+	ListStuck(ctx context.Context, filter StuckFilter) ([]*StuckCompensation, int64, error)
+
+	// ResolveStuck 标记卡住的补偿已解决
+	ResolveStuck(ctx context.Context, id uint64) error
+}
+
+// StuckStatus 卡住的补偿记录状态
+type StuckStatus string
+
+const (
+	StuckPending  StuckStatus = "PENDING"  // 待处理（重试耗尽，等待人工重新驱动）
+	StuckResolved StuckStatus = "RESOLVED" // 已解决（重新驱动成功）
+)
+
+// StuckCompensation 卡住的补偿记录（补偿的"死信"）
+//
+// 教学要点：
+// 1. 补偿重试耗尽后，Saga日志只剩一个COMPENSATE_FAILED状态，不便于运维检索
+// 2. 单独落一条记录，带上补偿数据、尝试次数和最后错误，管理接口据此列出和重新驱动
+// 3. 重新驱动使用Recoverer注册的补偿函数 + Data，与崩溃恢复走同一套逻辑
+type StuckCompensation struct {
+	ID        uint64
+	SagaID    string
+	SagaName  string
+	StepIndex int
+	StepName  string
+	Data      []byte // 补偿数据（JSON，来自SetStepData）
+	Attempts  int    // 累计尝试次数
+	LastError string // 最后一次错误
+	Status    StuckStatus
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// StuckFilter 卡住的补偿记录查询条件
+type StuckFilter struct {
+	SagaID string      // 为空表示不限
+	Status StuckStatus // 为空表示不限
+	Offset int
+	Limit  int // <=0表示不限
+}
+
+// ErrLogNotFound Saga日志不存在
+var ErrLogNotFound = errors.New("saga日志不存在")
+
+// ErrStuckNotFound 卡住的补偿记录不存在
+var ErrStuckNotFound = errors.New("补偿记录不存在")
+
+// GenerateID 生成Saga实例ID
+//
+// 格式：SAGA + YYYYMMDDHHMMSS + 6位随机数（与订单号风格一致，便于排查）
+func GenerateID() string {
+	return fmt.Sprintf("SAGA%s%d", time.Now().Format("20060102150405"), rand.Intn(900000)+100000)
+}
+
+// ==================== MemoryStore ====================
+
+// MemoryStore 内存版Saga日志存储
+//
+// ⚠️ 进程重启后数据丢失，不具备故障恢复能力，仅用于测试和演示
+type MemoryStore struct {
+	mu      sync.Mutex
+	logs    map[string]*Log
+	stuck   []*StuckCompensation
+	stuckID uint64
+}
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{logs: make(map[string]*Log)}
+}
+
+// CreateLog 创建Saga日志
+func (m *MemoryStore) CreateLog(ctx context.Context, log *Log) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.logs[log.ID]; exists {
+		return fmt.Errorf("saga日志已存在: %s", log.ID)
+	}
+
+	now := time.Now()
+	cp := *log
+	cp.Steps = append([]StepLog(nil), log.Steps...)
+	if cp.CreatedAt.IsZero() {
+		cp.CreatedAt = now
+	}
+	cp.UpdatedAt = now
+	m.logs[log.ID] = &cp
+	return nil
+}
+
+// UpdateStatus 更新Saga状态
+func (m *MemoryStore) UpdateStatus(ctx context.Context, sagaID string, status Status) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.logs[sagaID]
+	if !ok {
+		return ErrLogNotFound
+	}
+	l.Status = status
+	l.UpdatedAt = time.Now()
+	return nil
+}
+
+// SaveStep 写入步骤日志
+func (m *MemoryStore) SaveStep(ctx context.Context, sagaID string, step *StepLog) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.logs[sagaID]
+	if !ok {
+		return ErrLogNotFound
+	}
+
+	now := time.Now()
+	cp := *step
+	cp.Data = append([]byte(nil), step.Data...)
+	cp.UpdatedAt = now
+
+	replaced := false
+	for i := range l.Steps {
+		if l.Steps[i].Index == step.Index {
+			l.Steps[i] = cp
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		l.Steps = append(l.Steps, cp)
+		sort.Slice(l.Steps, func(i, j int) bool { return l.Steps[i].Index < l.Steps[j].Index })
+	}
+	l.UpdatedAt = now
+	return nil
+}
+
+// ListUnfinished 查询未完成的Saga
+func (m *MemoryStore) ListUnfinished(ctx context.Context, updatedBefore time.Time, limit int) ([]*Log, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]*Log, 0)
+	for _, l := range m.logs {
+		if l.Status != StatusRunning && l.Status != StatusCompensating {
+			continue
+		}
+		if !l.UpdatedAt.Before(updatedBefore) {
+			continue
+		}
+		cp := *l
+		cp.Steps = append([]StepLog(nil), l.Steps...)
+		result = append(result, &cp)
+	}
+
+	// 按创建时间升序，先恢复最早的Saga
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// SaveStuck 写入卡住的补偿记录
+func (m *MemoryStore) SaveStuck(ctx context.Context, stuck *StuckCompensation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, existing := range m.stuck {
+		if existing.SagaID == stuck.SagaID && existing.StepIndex == stuck.StepIndex {
+			existing.Data = append([]byte(nil), stuck.Data...)
+			existing.Attempts += stuck.Attempts
+			existing.LastError = stuck.LastError
+			existing.Status = StuckPending
+			existing.UpdatedAt = now
+			return nil
+		}
+	}
+
+	m.stuckID++
+	cp := *stuck
+	cp.ID = m.stuckID
+	cp.Data = append([]byte(nil), stuck.Data...)
+	cp.Status = StuckPending
+	cp.CreatedAt = now
+	cp.UpdatedAt = now
+	m.stuck = append(m.stuck, &cp)
+	return nil
+}
+
+// GetStuck 查询卡住的补偿记录
+func (m *MemoryStore) GetStuck(ctx context.Context, id uint64) (*StuckCompensation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, st := range m.stuck {
+		if st.ID == id {
+			cp := *st
+			return &cp, nil
+		}
+	}
+	return nil, ErrStuckNotFound
+}
+
+// ListStuck 查询卡住的补偿记录
+func (m *MemoryStore) ListStuck(ctx context.Context, filter StuckFilter) ([]*StuckCompensation, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	matched := make([]*StuckCompensation, 0)
+	for _, st := range m.stuck {
+		if filter.SagaID != "" && st.SagaID != filter.SagaID {
+			continue
+		}
+		if filter.Status != "" && st.Status != filter.Status {
+			continue
+		}
+		cp := *st
+		matched = append(matched, &cp)
+	}
+
+	total := int64(len(matched))
+	if filter.Offset >= len(matched) {
+		return []*StuckCompensation{}, total, nil
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit > 0 && len(matched) > filter.Limit {
+		matched = matched[:filter.Limit]
+	}
+	return matched, total, nil
+}
+
+// ResolveStuck 标记卡住的补偿已解决
+func (m *MemoryStore) ResolveStuck(ctx context.Context, id uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, st := range m.stuck {
+		if st.ID == id {
+			st.Status = StuckResolved
+			st.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return ErrStuckNotFound
+}
+
+// Get 查询Saga日志（测试辅助）
+func (m *MemoryStore) Get(sagaID string) (*Log, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.logs[sagaID]
+	if !ok {
+		return nil, false
+	}
+	cp := *l
+	cp.Steps = append([]StepLog(nil), l.Steps...)
+	return &cp, true
+}
@@ -133,6 +133,8 @@ type ListBooksRequest struct {
 	PageSize      uint32                 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"` // 每页数量（默认10，最大100）
 	SortBy        string                 `protobuf:"bytes,3,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`        // 排序字段：created_at（默认）, price
 	Order         string                 `protobuf:"bytes,4,opt,name=order,proto3" json:"order,omitempty"`                        // 排序方向：desc（默认）, asc
+	Filter        *BookFilter            `protobuf:"bytes,5,opt,name=filter,proto3" json:"filter,omitempty"`                      // 筛选条件（可选）
+	Cursor        string                 `protobuf:"bytes,6,opt,name=cursor,proto3" json:"cursor,omitempty"`                      // 游标（上一页响应的next_cursor），非空时忽略page
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -195,33 +197,1401 @@ func (x *ListBooksRequest) GetOrder() string {
 	return ""
 }
 
+func (x *ListBooksRequest) GetFilter() *BookFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *ListBooksRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
 type ListBooksResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Books         []*Book                `protobuf:"bytes,3,rep,name=books,proto3" json:"books,omitempty"`
-	Total         uint32                 `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"` // 总数
-	Page          uint32                 `protobuf:"varint,5,opt,name=page,proto3" json:"page,omitempty"`
-	PageSize      uint32                 `protobuf:"varint,6,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Books         []*Book                `protobuf:"bytes,3,rep,name=books,proto3" json:"books,omitempty"`
+	Total         uint32                 `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"` // 满足筛选条件的总数
+	Page          uint32                 `protobuf:"varint,5,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      uint32                 `protobuf:"varint,6,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Facets        *BookFacets            `protobuf:"bytes,7,opt,name=facets,proto3" json:"facets,omitempty"`                           // 分面统计
+	NextCursor    string                 `protobuf:"bytes,8,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // 下一页游标（没有下一页时为空）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBooksResponse) Reset() {
+	*x = ListBooksResponse{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBooksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBooksResponse) ProtoMessage() {}
+
+func (x *ListBooksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBooksResponse.ProtoReflect.Descriptor instead.
+func (*ListBooksResponse) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListBooksResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ListBooksResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ListBooksResponse) GetBooks() []*Book {
+	if x != nil {
+		return x.Books
+	}
+	return nil
+}
+
+func (x *ListBooksResponse) GetTotal() uint32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListBooksResponse) GetPage() uint32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListBooksResponse) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListBooksResponse) GetFacets() *BookFacets {
+	if x != nil {
+		return x.Facets
+	}
+	return nil
+}
+
+func (x *ListBooksResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+// 图书筛选条件（零值表示不限）
+type BookFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinPrice      int64                  `protobuf:"varint,1,opt,name=min_price,json=minPrice,proto3" json:"min_price,omitempty"`                // 最低价格（分，含）
+	MaxPrice      int64                  `protobuf:"varint,2,opt,name=max_price,json=maxPrice,proto3" json:"max_price,omitempty"`                // 最高价格（分，含）
+	Author        string                 `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`                                     // 作者（精确匹配）
+	Publisher     string                 `protobuf:"bytes,4,opt,name=publisher,proto3" json:"publisher,omitempty"`                               // 出版社（精确匹配）
+	PublishedFrom int64                  `protobuf:"varint,5,opt,name=published_from,json=publishedFrom,proto3" json:"published_from,omitempty"` // 发布时间下限（Unix时间戳，秒，含）
+	PublishedTo   int64                  `protobuf:"varint,6,opt,name=published_to,json=publishedTo,proto3" json:"published_to,omitempty"`       // 发布时间上限（Unix时间戳，秒，不含）
+	InStockOnly   bool                   `protobuf:"varint,7,opt,name=in_stock_only,json=inStockOnly,proto3" json:"in_stock_only,omitempty"`     // 只看有货
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BookFilter) Reset() {
+	*x = BookFilter{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BookFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BookFilter) ProtoMessage() {}
+
+func (x *BookFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BookFilter.ProtoReflect.Descriptor instead.
+func (*BookFilter) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BookFilter) GetMinPrice() int64 {
+	if x != nil {
+		return x.MinPrice
+	}
+	return 0
+}
+
+func (x *BookFilter) GetMaxPrice() int64 {
+	if x != nil {
+		return x.MaxPrice
+	}
+	return 0
+}
+
+func (x *BookFilter) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *BookFilter) GetPublisher() string {
+	if x != nil {
+		return x.Publisher
+	}
+	return ""
+}
+
+func (x *BookFilter) GetPublishedFrom() int64 {
+	if x != nil {
+		return x.PublishedFrom
+	}
+	return 0
+}
+
+func (x *BookFilter) GetPublishedTo() int64 {
+	if x != nil {
+		return x.PublishedTo
+	}
+	return 0
+}
+
+func (x *BookFilter) GetInStockOnly() bool {
+	if x != nil {
+		return x.InStockOnly
+	}
+	return false
+}
+
+// 分面统计
+// 每个分面忽略自身的筛选条件：选中某出版社后，出版社分面仍返回其他出版社的数量
+type BookFacets struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Publishers    []*FacetCount          `protobuf:"bytes,1,rep,name=publishers,proto3" json:"publishers,omitempty"`                   // 出版社（按图书数降序，最多20项）
+	PriceBands    []*PriceBandCount      `protobuf:"bytes,2,rep,name=price_bands,json=priceBands,proto3" json:"price_bands,omitempty"` // 价格区间（按价格升序）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BookFacets) Reset() {
+	*x = BookFacets{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BookFacets) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BookFacets) ProtoMessage() {}
+
+func (x *BookFacets) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BookFacets.ProtoReflect.Descriptor instead.
+func (*BookFacets) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BookFacets) GetPublishers() []*FacetCount {
+	if x != nil {
+		return x.Publishers
+	}
+	return nil
+}
+
+func (x *BookFacets) GetPriceBands() []*PriceBandCount {
+	if x != nil {
+		return x.PriceBands
+	}
+	return nil
+}
+
+type FacetCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Count         uint32                 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FacetCount) Reset() {
+	*x = FacetCount{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FacetCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FacetCount) ProtoMessage() {}
+
+func (x *FacetCount) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FacetCount.ProtoReflect.Descriptor instead.
+func (*FacetCount) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *FacetCount) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *FacetCount) GetCount() uint32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// 价格区间 [min_price, max_price)
+type PriceBandCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Label         string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	MinPrice      int64                  `protobuf:"varint,2,opt,name=min_price,json=minPrice,proto3" json:"min_price,omitempty"` // 分
+	MaxPrice      int64                  `protobuf:"varint,3,opt,name=max_price,json=maxPrice,proto3" json:"max_price,omitempty"` // 分，0表示无上限
+	Count         uint32                 `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PriceBandCount) Reset() {
+	*x = PriceBandCount{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PriceBandCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceBandCount) ProtoMessage() {}
+
+func (x *PriceBandCount) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriceBandCount.ProtoReflect.Descriptor instead.
+func (*PriceBandCount) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PriceBandCount) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *PriceBandCount) GetMinPrice() int64 {
+	if x != nil {
+		return x.MinPrice
+	}
+	return 0
+}
+
+func (x *PriceBandCount) GetMaxPrice() int64 {
+	if x != nil {
+		return x.MaxPrice
+	}
+	return 0
+}
+
+func (x *PriceBandCount) GetCount() uint32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// 搜索图书
+type SearchBooksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keyword       string                 `protobuf:"bytes,1,opt,name=keyword,proto3" json:"keyword,omitempty"` // 搜索关键词（匹配title、author、publisher、description）
+	Page          uint32                 `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      uint32                 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Filter        *BookFilter            `protobuf:"bytes,4,opt,name=filter,proto3" json:"filter,omitempty"` // 筛选条件（可选）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchBooksRequest) Reset() {
+	*x = SearchBooksRequest{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchBooksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchBooksRequest) ProtoMessage() {}
+
+func (x *SearchBooksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchBooksRequest.ProtoReflect.Descriptor instead.
+func (*SearchBooksRequest) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SearchBooksRequest) GetKeyword() string {
+	if x != nil {
+		return x.Keyword
+	}
+	return ""
+}
+
+func (x *SearchBooksRequest) GetPage() uint32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *SearchBooksRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *SearchBooksRequest) GetFilter() *BookFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type SearchBooksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Books         []*Book                `protobuf:"bytes,3,rep,name=books,proto3" json:"books,omitempty"` // 按相关性降序
+	Total         uint32                 `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	Highlights    []*BookHighlight       `protobuf:"bytes,5,rep,name=highlights,proto3" json:"highlights,omitempty"` // 与books一一对应
+	Facets        *BookFacets            `protobuf:"bytes,6,opt,name=facets,proto3" json:"facets,omitempty"`         // 命中结果的分面统计
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchBooksResponse) Reset() {
+	*x = SearchBooksResponse{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchBooksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchBooksResponse) ProtoMessage() {}
+
+func (x *SearchBooksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchBooksResponse.ProtoReflect.Descriptor instead.
+func (*SearchBooksResponse) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SearchBooksResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *SearchBooksResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SearchBooksResponse) GetBooks() []*Book {
+	if x != nil {
+		return x.Books
+	}
+	return nil
+}
+
+func (x *SearchBooksResponse) GetTotal() uint32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *SearchBooksResponse) GetHighlights() []*BookHighlight {
+	if x != nil {
+		return x.Highlights
+	}
+	return nil
+}
+
+func (x *SearchBooksResponse) GetFacets() *BookFacets {
+	if x != nil {
+		return x.Facets
+	}
+	return nil
+}
+
+// 搜索高亮
+// 命中的词用<em></em>包裹，其余文本已做HTML转义；未命中的字段为空
+type BookHighlight struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookId        uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Author        string                 `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	Publisher     string                 `protobuf:"bytes,4,opt,name=publisher,proto3" json:"publisher,omitempty"`
+	Description   string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"` // 命中位置附近的摘要
+	Score         float64                `protobuf:"fixed64,6,opt,name=score,proto3" json:"score,omitempty"`           // 相关性得分
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BookHighlight) Reset() {
+	*x = BookHighlight{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BookHighlight) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BookHighlight) ProtoMessage() {}
+
+func (x *BookHighlight) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BookHighlight.ProtoReflect.Descriptor instead.
+func (*BookHighlight) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BookHighlight) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *BookHighlight) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *BookHighlight) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *BookHighlight) GetPublisher() string {
+	if x != nil {
+		return x.Publisher
+	}
+	return ""
+}
+
+func (x *BookHighlight) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *BookHighlight) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+// 同义词组
+type SynonymGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Words         []string               `protobuf:"bytes,2,rep,name=words,proto3" json:"words,omitempty"`                           // 互为同义词的词
+	UpdatedAt     int64                  `protobuf:"varint,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"` // Unix时间戳（秒）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SynonymGroup) Reset() {
+	*x = SynonymGroup{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SynonymGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SynonymGroup) ProtoMessage() {}
+
+func (x *SynonymGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SynonymGroup.ProtoReflect.Descriptor instead.
+func (*SynonymGroup) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SynonymGroup) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *SynonymGroup) GetWords() []string {
+	if x != nil {
+		return x.Words
+	}
+	return nil
+}
+
+func (x *SynonymGroup) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type ListSynonymGroupsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSynonymGroupsRequest) Reset() {
+	*x = ListSynonymGroupsRequest{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSynonymGroupsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSynonymGroupsRequest) ProtoMessage() {}
+
+func (x *ListSynonymGroupsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSynonymGroupsRequest.ProtoReflect.Descriptor instead.
+func (*ListSynonymGroupsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{12}
+}
+
+type ListSynonymGroupsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Groups        []*SynonymGroup        `protobuf:"bytes,3,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSynonymGroupsResponse) Reset() {
+	*x = ListSynonymGroupsResponse{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSynonymGroupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSynonymGroupsResponse) ProtoMessage() {}
+
+func (x *ListSynonymGroupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSynonymGroupsResponse.ProtoReflect.Descriptor instead.
+func (*ListSynonymGroupsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListSynonymGroupsResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ListSynonymGroupsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ListSynonymGroupsResponse) GetGroups() []*SynonymGroup {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+// 新建（id为0）或更新同义词组
+type SaveSynonymGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Words         []string               `protobuf:"bytes,2,rep,name=words,proto3" json:"words,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveSynonymGroupRequest) Reset() {
+	*x = SaveSynonymGroupRequest{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveSynonymGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveSynonymGroupRequest) ProtoMessage() {}
+
+func (x *SaveSynonymGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveSynonymGroupRequest.ProtoReflect.Descriptor instead.
+func (*SaveSynonymGroupRequest) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SaveSynonymGroupRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *SaveSynonymGroupRequest) GetWords() []string {
+	if x != nil {
+		return x.Words
+	}
+	return nil
+}
+
+type SaveSynonymGroupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Group         *SynonymGroup          `protobuf:"bytes,3,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveSynonymGroupResponse) Reset() {
+	*x = SaveSynonymGroupResponse{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveSynonymGroupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveSynonymGroupResponse) ProtoMessage() {}
+
+func (x *SaveSynonymGroupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveSynonymGroupResponse.ProtoReflect.Descriptor instead.
+func (*SaveSynonymGroupResponse) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SaveSynonymGroupResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *SaveSynonymGroupResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SaveSynonymGroupResponse) GetGroup() *SynonymGroup {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+type DeleteSynonymGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSynonymGroupRequest) Reset() {
+	*x = DeleteSynonymGroupRequest{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSynonymGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSynonymGroupRequest) ProtoMessage() {}
+
+func (x *DeleteSynonymGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSynonymGroupRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSynonymGroupRequest) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *DeleteSynonymGroupRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteSynonymGroupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSynonymGroupResponse) Reset() {
+	*x = DeleteSynonymGroupResponse{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSynonymGroupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSynonymGroupResponse) ProtoMessage() {}
+
+func (x *DeleteSynonymGroupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSynonymGroupResponse.ProtoReflect.Descriptor instead.
+func (*DeleteSynonymGroupResponse) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *DeleteSynonymGroupResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *DeleteSynonymGroupResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// 发布图书
+type PublishBookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Isbn          string                 `protobuf:"bytes,1,opt,name=isbn,proto3" json:"isbn,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Author        string                 `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	Publisher     string                 `protobuf:"bytes,4,opt,name=publisher,proto3" json:"publisher,omitempty"`
+	Price         int64                  `protobuf:"varint,5,opt,name=price,proto3" json:"price,omitempty"`                                // 价格（分）
+	CoverUrl      string                 `protobuf:"bytes,6,opt,name=cover_url,json=coverUrl,proto3" json:"cover_url,omitempty"`           // 封面URL（可选）
+	Description   string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`                     // 描述（可选）
+	PublisherId   uint64                 `protobuf:"varint,8,opt,name=publisher_id,json=publisherId,proto3" json:"publisher_id,omitempty"` // 发布者用户ID
+	Draft         bool                   `protobuf:"varint,9,opt,name=draft,proto3" json:"draft,omitempty"`                                // 是否保存为草稿（默认直接上架）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishBookRequest) Reset() {
+	*x = PublishBookRequest{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishBookRequest) ProtoMessage() {}
+
+func (x *PublishBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishBookRequest.ProtoReflect.Descriptor instead.
+func (*PublishBookRequest) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *PublishBookRequest) GetIsbn() string {
+	if x != nil {
+		return x.Isbn
+	}
+	return ""
+}
+
+func (x *PublishBookRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *PublishBookRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *PublishBookRequest) GetPublisher() string {
+	if x != nil {
+		return x.Publisher
+	}
+	return ""
+}
+
+func (x *PublishBookRequest) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *PublishBookRequest) GetCoverUrl() string {
+	if x != nil {
+		return x.CoverUrl
+	}
+	return ""
+}
+
+func (x *PublishBookRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *PublishBookRequest) GetPublisherId() uint64 {
+	if x != nil {
+		return x.PublisherId
+	}
+	return 0
+}
+
+func (x *PublishBookRequest) GetDraft() bool {
+	if x != nil {
+		return x.Draft
+	}
+	return false
+}
+
+type PublishBookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	BookId        uint64                 `protobuf:"varint,3,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishBookResponse) Reset() {
+	*x = PublishBookResponse{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishBookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishBookResponse) ProtoMessage() {}
+
+func (x *PublishBookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishBookResponse.ProtoReflect.Descriptor instead.
+func (*PublishBookResponse) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *PublishBookResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *PublishBookResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *PublishBookResponse) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+// 修改图书信息（ISBN不可修改；字段全部必填，整体覆盖）
+type UpdateBookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookId        uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	OperatorId    uint64                 `protobuf:"varint,2,opt,name=operator_id,json=operatorId,proto3" json:"operator_id,omitempty"` // 操作者用户ID（必须是发布者）
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Author        string                 `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`
+	Publisher     string                 `protobuf:"bytes,5,opt,name=publisher,proto3" json:"publisher,omitempty"`
+	CoverUrl      string                 `protobuf:"bytes,6,opt,name=cover_url,json=coverUrl,proto3" json:"cover_url,omitempty"`
+	Description   string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateBookRequest) Reset() {
+	*x = UpdateBookRequest{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBookRequest) ProtoMessage() {}
+
+func (x *UpdateBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBookRequest.ProtoReflect.Descriptor instead.
+func (*UpdateBookRequest) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *UpdateBookRequest) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *UpdateBookRequest) GetOperatorId() uint64 {
+	if x != nil {
+		return x.OperatorId
+	}
+	return 0
+}
+
+func (x *UpdateBookRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *UpdateBookRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *UpdateBookRequest) GetPublisher() string {
+	if x != nil {
+		return x.Publisher
+	}
+	return ""
+}
+
+func (x *UpdateBookRequest) GetCoverUrl() string {
+	if x != nil {
+		return x.CoverUrl
+	}
+	return ""
+}
+
+func (x *UpdateBookRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type UpdateBookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Book          *Book                  `protobuf:"bytes,3,opt,name=book,proto3" json:"book,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateBookResponse) Reset() {
+	*x = UpdateBookResponse{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateBookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBookResponse) ProtoMessage() {}
+
+func (x *UpdateBookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBookResponse.ProtoReflect.Descriptor instead.
+func (*UpdateBookResponse) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *UpdateBookResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *UpdateBookResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *UpdateBookResponse) GetBook() *Book {
+	if x != nil {
+		return x.Book
+	}
+	return nil
+}
+
+// 修改价格
+type UpdateBookPriceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookId        uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	OperatorId    uint64                 `protobuf:"varint,2,opt,name=operator_id,json=operatorId,proto3" json:"operator_id,omitempty"`
+	Price         int64                  `protobuf:"varint,3,opt,name=price,proto3" json:"price,omitempty"` // 新价格（分）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateBookPriceRequest) Reset() {
+	*x = UpdateBookPriceRequest{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateBookPriceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBookPriceRequest) ProtoMessage() {}
+
+func (x *UpdateBookPriceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBookPriceRequest.ProtoReflect.Descriptor instead.
+func (*UpdateBookPriceRequest) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *UpdateBookPriceRequest) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *UpdateBookPriceRequest) GetOperatorId() uint64 {
+	if x != nil {
+		return x.OperatorId
+	}
+	return 0
+}
+
+func (x *UpdateBookPriceRequest) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+type UpdateBookPriceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	OldPrice      int64                  `protobuf:"varint,3,opt,name=old_price,json=oldPrice,proto3" json:"old_price,omitempty"`
+	NewPrice      int64                  `protobuf:"varint,4,opt,name=new_price,json=newPrice,proto3" json:"new_price,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListBooksResponse) Reset() {
-	*x = ListBooksResponse{}
-	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[3]
+func (x *UpdateBookPriceResponse) Reset() {
+	*x = UpdateBookPriceResponse{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListBooksResponse) String() string {
+func (x *UpdateBookPriceResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListBooksResponse) ProtoMessage() {}
+func (*UpdateBookPriceResponse) ProtoMessage() {}
 
-func (x *ListBooksResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[3]
+func (x *UpdateBookPriceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -232,78 +1602,64 @@ func (x *ListBooksResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListBooksResponse.ProtoReflect.Descriptor instead.
-func (*ListBooksResponse) Descriptor() ([]byte, []int) {
-	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use UpdateBookPriceResponse.ProtoReflect.Descriptor instead.
+func (*UpdateBookPriceResponse) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *ListBooksResponse) GetCode() uint32 {
+func (x *UpdateBookPriceResponse) GetCode() uint32 {
 	if x != nil {
 		return x.Code
 	}
 	return 0
 }
 
-func (x *ListBooksResponse) GetMessage() string {
+func (x *UpdateBookPriceResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *ListBooksResponse) GetBooks() []*Book {
-	if x != nil {
-		return x.Books
-	}
-	return nil
-}
-
-func (x *ListBooksResponse) GetTotal() uint32 {
-	if x != nil {
-		return x.Total
-	}
-	return 0
-}
-
-func (x *ListBooksResponse) GetPage() uint32 {
+func (x *UpdateBookPriceResponse) GetOldPrice() int64 {
 	if x != nil {
-		return x.Page
+		return x.OldPrice
 	}
 	return 0
 }
 
-func (x *ListBooksResponse) GetPageSize() uint32 {
+func (x *UpdateBookPriceResponse) GetNewPrice() int64 {
 	if x != nil {
-		return x.PageSize
+		return x.NewPrice
 	}
 	return 0
 }
 
-// 搜索图书
-type SearchBooksRequest struct {
+// 修改状态（上架/下架）
+type ChangeBookStatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Keyword       string                 `protobuf:"bytes,1,opt,name=keyword,proto3" json:"keyword,omitempty"` // 搜索关键词（匹配title、author、publisher）
-	Page          uint32                 `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
-	PageSize      uint32                 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	BookId        uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	OperatorId    uint64                 `protobuf:"varint,2,opt,name=operator_id,json=operatorId,proto3" json:"operator_id,omitempty"`
+	Status        int32                  `protobuf:"varint,3,opt,name=status,proto3" json:"status,omitempty"` // 目标状态：2在售 3已下架
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SearchBooksRequest) Reset() {
-	*x = SearchBooksRequest{}
-	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[4]
+func (x *ChangeBookStatusRequest) Reset() {
+	*x = ChangeBookStatusRequest{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SearchBooksRequest) String() string {
+func (x *ChangeBookStatusRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SearchBooksRequest) ProtoMessage() {}
+func (*ChangeBookStatusRequest) ProtoMessage() {}
 
-func (x *SearchBooksRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[4]
+func (x *ChangeBookStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -314,57 +1670,56 @@ func (x *SearchBooksRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SearchBooksRequest.ProtoReflect.Descriptor instead.
-func (*SearchBooksRequest) Descriptor() ([]byte, []int) {
-	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use ChangeBookStatusRequest.ProtoReflect.Descriptor instead.
+func (*ChangeBookStatusRequest) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *SearchBooksRequest) GetKeyword() string {
+func (x *ChangeBookStatusRequest) GetBookId() uint64 {
 	if x != nil {
-		return x.Keyword
+		return x.BookId
 	}
-	return ""
+	return 0
 }
 
-func (x *SearchBooksRequest) GetPage() uint32 {
+func (x *ChangeBookStatusRequest) GetOperatorId() uint64 {
 	if x != nil {
-		return x.Page
+		return x.OperatorId
 	}
 	return 0
 }
 
-func (x *SearchBooksRequest) GetPageSize() uint32 {
+func (x *ChangeBookStatusRequest) GetStatus() int32 {
 	if x != nil {
-		return x.PageSize
+		return x.Status
 	}
 	return 0
 }
 
-type SearchBooksResponse struct {
+type ChangeBookStatusResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Books         []*Book                `protobuf:"bytes,3,rep,name=books,proto3" json:"books,omitempty"`
-	Total         uint32                 `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	Status        int32                  `protobuf:"varint,3,opt,name=status,proto3" json:"status,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SearchBooksResponse) Reset() {
-	*x = SearchBooksResponse{}
-	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[5]
+func (x *ChangeBookStatusResponse) Reset() {
+	*x = ChangeBookStatusResponse{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SearchBooksResponse) String() string {
+func (x *ChangeBookStatusResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SearchBooksResponse) ProtoMessage() {}
+func (*ChangeBookStatusResponse) ProtoMessage() {}
 
-func (x *SearchBooksResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[5]
+func (x *ChangeBookStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -375,69 +1730,56 @@ func (x *SearchBooksResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SearchBooksResponse.ProtoReflect.Descriptor instead.
-func (*SearchBooksResponse) Descriptor() ([]byte, []int) {
-	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use ChangeBookStatusResponse.ProtoReflect.Descriptor instead.
+func (*ChangeBookStatusResponse) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *SearchBooksResponse) GetCode() uint32 {
+func (x *ChangeBookStatusResponse) GetCode() uint32 {
 	if x != nil {
 		return x.Code
 	}
 	return 0
 }
 
-func (x *SearchBooksResponse) GetMessage() string {
+func (x *ChangeBookStatusResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *SearchBooksResponse) GetBooks() []*Book {
-	if x != nil {
-		return x.Books
-	}
-	return nil
-}
-
-func (x *SearchBooksResponse) GetTotal() uint32 {
+func (x *ChangeBookStatusResponse) GetStatus() int32 {
 	if x != nil {
-		return x.Total
+		return x.Status
 	}
 	return 0
 }
 
-// 发布图书
-type PublishBookRequest struct {
+// 删除图书（软删除）
+type DeleteBookRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Isbn          string                 `protobuf:"bytes,1,opt,name=isbn,proto3" json:"isbn,omitempty"`
-	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Author        string                 `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
-	Publisher     string                 `protobuf:"bytes,4,opt,name=publisher,proto3" json:"publisher,omitempty"`
-	Price         int64                  `protobuf:"varint,5,opt,name=price,proto3" json:"price,omitempty"`                                // 价格（分）
-	CoverUrl      string                 `protobuf:"bytes,6,opt,name=cover_url,json=coverUrl,proto3" json:"cover_url,omitempty"`           // 封面URL（可选）
-	Description   string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`                     // 描述（可选）
-	PublisherId   uint64                 `protobuf:"varint,8,opt,name=publisher_id,json=publisherId,proto3" json:"publisher_id,omitempty"` // 发布者用户ID
+	BookId        uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	OperatorId    uint64                 `protobuf:"varint,2,opt,name=operator_id,json=operatorId,proto3" json:"operator_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PublishBookRequest) Reset() {
-	*x = PublishBookRequest{}
-	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[6]
+func (x *DeleteBookRequest) Reset() {
+	*x = DeleteBookRequest{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PublishBookRequest) String() string {
+func (x *DeleteBookRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PublishBookRequest) ProtoMessage() {}
+func (*DeleteBookRequest) ProtoMessage() {}
 
-func (x *PublishBookRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[6]
+func (x *DeleteBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -448,91 +1790,154 @@ func (x *PublishBookRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PublishBookRequest.ProtoReflect.Descriptor instead.
-func (*PublishBookRequest) Descriptor() ([]byte, []int) {
-	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use DeleteBookRequest.ProtoReflect.Descriptor instead.
+func (*DeleteBookRequest) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *PublishBookRequest) GetIsbn() string {
+func (x *DeleteBookRequest) GetBookId() uint64 {
 	if x != nil {
-		return x.Isbn
+		return x.BookId
 	}
-	return ""
+	return 0
 }
 
-func (x *PublishBookRequest) GetTitle() string {
+func (x *DeleteBookRequest) GetOperatorId() uint64 {
 	if x != nil {
-		return x.Title
+		return x.OperatorId
 	}
-	return ""
+	return 0
 }
 
-func (x *PublishBookRequest) GetAuthor() string {
-	if x != nil {
-		return x.Author
-	}
-	return ""
+type DeleteBookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PublishBookRequest) GetPublisher() string {
+func (x *DeleteBookResponse) Reset() {
+	*x = DeleteBookResponse{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBookResponse) ProtoMessage() {}
+
+func (x *DeleteBookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[27]
 	if x != nil {
-		return x.Publisher
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *PublishBookRequest) GetPrice() int64 {
+// Deprecated: Use DeleteBookResponse.ProtoReflect.Descriptor instead.
+func (*DeleteBookResponse) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *DeleteBookResponse) GetCode() uint32 {
 	if x != nil {
-		return x.Price
+		return x.Code
 	}
 	return 0
 }
 
-func (x *PublishBookRequest) GetCoverUrl() string {
+func (x *DeleteBookResponse) GetMessage() string {
 	if x != nil {
-		return x.CoverUrl
+		return x.Message
 	}
 	return ""
 }
 
-func (x *PublishBookRequest) GetDescription() string {
+// 恢复已删除的图书（恢复后保持删除前的状态）
+type RestoreBookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookId        uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	OperatorId    uint64                 `protobuf:"varint,2,opt,name=operator_id,json=operatorId,proto3" json:"operator_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreBookRequest) Reset() {
+	*x = RestoreBookRequest{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreBookRequest) ProtoMessage() {}
+
+func (x *RestoreBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[28]
 	if x != nil {
-		return x.Description
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *PublishBookRequest) GetPublisherId() uint64 {
+// Deprecated: Use RestoreBookRequest.ProtoReflect.Descriptor instead.
+func (*RestoreBookRequest) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *RestoreBookRequest) GetBookId() uint64 {
 	if x != nil {
-		return x.PublisherId
+		return x.BookId
 	}
 	return 0
 }
 
-type PublishBookResponse struct {
+func (x *RestoreBookRequest) GetOperatorId() uint64 {
+	if x != nil {
+		return x.OperatorId
+	}
+	return 0
+}
+
+type RestoreBookResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	BookId        uint64                 `protobuf:"varint,3,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	Book          *Book                  `protobuf:"bytes,3,opt,name=book,proto3" json:"book,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PublishBookResponse) Reset() {
-	*x = PublishBookResponse{}
-	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[7]
+func (x *RestoreBookResponse) Reset() {
+	*x = RestoreBookResponse{}
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PublishBookResponse) String() string {
+func (x *RestoreBookResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PublishBookResponse) ProtoMessage() {}
+func (*RestoreBookResponse) ProtoMessage() {}
 
-func (x *PublishBookResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[7]
+func (x *RestoreBookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -543,30 +1948,30 @@ func (x *PublishBookResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PublishBookResponse.ProtoReflect.Descriptor instead.
-func (*PublishBookResponse) Descriptor() ([]byte, []int) {
-	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use RestoreBookResponse.ProtoReflect.Descriptor instead.
+func (*RestoreBookResponse) Descriptor() ([]byte, []int) {
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *PublishBookResponse) GetCode() uint32 {
+func (x *RestoreBookResponse) GetCode() uint32 {
 	if x != nil {
 		return x.Code
 	}
 	return 0
 }
 
-func (x *PublishBookResponse) GetMessage() string {
+func (x *RestoreBookResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *PublishBookResponse) GetBookId() uint64 {
+func (x *RestoreBookResponse) GetBook() *Book {
 	if x != nil {
-		return x.BookId
+		return x.Book
 	}
-	return 0
+	return nil
 }
 
 // 批量获取图书
@@ -579,7 +1984,7 @@ type BatchGetBooksRequest struct {
 
 func (x *BatchGetBooksRequest) Reset() {
 	*x = BatchGetBooksRequest{}
-	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[8]
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -591,7 +1996,7 @@ func (x *BatchGetBooksRequest) String() string {
 func (*BatchGetBooksRequest) ProtoMessage() {}
 
 func (x *BatchGetBooksRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[8]
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -604,7 +2009,7 @@ func (x *BatchGetBooksRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BatchGetBooksRequest.ProtoReflect.Descriptor instead.
 func (*BatchGetBooksRequest) Descriptor() ([]byte, []int) {
-	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{8}
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *BatchGetBooksRequest) GetBookIds() []uint64 {
@@ -625,7 +2030,7 @@ type BatchGetBooksResponse struct {
 
 func (x *BatchGetBooksResponse) Reset() {
 	*x = BatchGetBooksResponse{}
-	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[9]
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -637,7 +2042,7 @@ func (x *BatchGetBooksResponse) String() string {
 func (*BatchGetBooksResponse) ProtoMessage() {}
 
 func (x *BatchGetBooksResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[9]
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -650,7 +2055,7 @@ func (x *BatchGetBooksResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BatchGetBooksResponse.ProtoReflect.Descriptor instead.
 func (*BatchGetBooksResponse) Descriptor() ([]byte, []int) {
-	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{9}
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *BatchGetBooksResponse) GetCode() uint32 {
@@ -686,15 +2091,17 @@ type Book struct {
 	CoverUrl      string                 `protobuf:"bytes,7,opt,name=cover_url,json=coverUrl,proto3" json:"cover_url,omitempty"`
 	Description   string                 `protobuf:"bytes,8,opt,name=description,proto3" json:"description,omitempty"`
 	PublisherId   uint64                 `protobuf:"varint,9,opt,name=publisher_id,json=publisherId,proto3" json:"publisher_id,omitempty"` // 发布者用户ID
-	CreatedAt     int64                  `protobuf:"varint,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`      // Unix时间戳（秒）
+	CreatedAt     int64                  `protobuf:"varint,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`      // Unix时间戳（秒），即发布日期
 	UpdatedAt     int64                  `protobuf:"varint,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	InStock       bool                   `protobuf:"varint,12,opt,name=in_stock,json=inStock,proto3" json:"in_stock,omitempty"` // 是否有货（库存的冗余副本，最终一致）
+	Status        int32                  `protobuf:"varint,13,opt,name=status,proto3" json:"status,omitempty"`                  // 状态：1草稿 2在售 3已下架
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Book) Reset() {
 	*x = Book{}
-	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[10]
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -706,7 +2113,7 @@ func (x *Book) String() string {
 func (*Book) ProtoMessage() {}
 
 func (x *Book) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[10]
+	mi := &file_proto_catalog_v1_catalog_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -719,7 +2126,7 @@ func (x *Book) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Book.ProtoReflect.Descriptor instead.
 func (*Book) Descriptor() ([]byte, []int) {
-	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{10}
+	return file_proto_catalog_v1_catalog_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *Book) GetId() uint64 {
@@ -799,6 +2206,20 @@ func (x *Book) GetUpdatedAt() int64 {
 	return 0
 }
 
+func (x *Book) GetInStock() bool {
+	if x != nil {
+		return x.InStock
+	}
+	return false
+}
+
+func (x *Book) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
 var File_proto_catalog_v1_catalog_proto protoreflect.FileDescriptor
 
 const file_proto_catalog_v1_catalog_proto_rawDesc = "" +
@@ -810,28 +2231,92 @@ const file_proto_catalog_v1_catalog_proto_rawDesc = "" +
 	"\x0fGetBookResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12$\n" +
-	"\x04book\x18\x03 \x01(\v2\x10.catalog.v1.BookR\x04book\"r\n" +
+	"\x04book\x18\x03 \x01(\v2\x10.catalog.v1.BookR\x04book\"\xba\x01\n" +
 	"\x10ListBooksRequest\x12\x12\n" +
 	"\x04page\x18\x01 \x01(\rR\x04page\x12\x1b\n" +
 	"\tpage_size\x18\x02 \x01(\rR\bpageSize\x12\x17\n" +
 	"\asort_by\x18\x03 \x01(\tR\x06sortBy\x12\x14\n" +
-	"\x05order\x18\x04 \x01(\tR\x05order\"\xb0\x01\n" +
+	"\x05order\x18\x04 \x01(\tR\x05order\x12.\n" +
+	"\x06filter\x18\x05 \x01(\v2\x16.catalog.v1.BookFilterR\x06filter\x12\x16\n" +
+	"\x06cursor\x18\x06 \x01(\tR\x06cursor\"\x81\x02\n" +
 	"\x11ListBooksResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12&\n" +
 	"\x05books\x18\x03 \x03(\v2\x10.catalog.v1.BookR\x05books\x12\x14\n" +
 	"\x05total\x18\x04 \x01(\rR\x05total\x12\x12\n" +
 	"\x04page\x18\x05 \x01(\rR\x04page\x12\x1b\n" +
-	"\tpage_size\x18\x06 \x01(\rR\bpageSize\"_\n" +
+	"\tpage_size\x18\x06 \x01(\rR\bpageSize\x12.\n" +
+	"\x06facets\x18\a \x01(\v2\x16.catalog.v1.BookFacetsR\x06facets\x12\x1f\n" +
+	"\vnext_cursor\x18\b \x01(\tR\n" +
+	"nextCursor\"\xea\x01\n" +
+	"\n" +
+	"BookFilter\x12\x1b\n" +
+	"\tmin_price\x18\x01 \x01(\x03R\bminPrice\x12\x1b\n" +
+	"\tmax_price\x18\x02 \x01(\x03R\bmaxPrice\x12\x16\n" +
+	"\x06author\x18\x03 \x01(\tR\x06author\x12\x1c\n" +
+	"\tpublisher\x18\x04 \x01(\tR\tpublisher\x12%\n" +
+	"\x0epublished_from\x18\x05 \x01(\x03R\rpublishedFrom\x12!\n" +
+	"\fpublished_to\x18\x06 \x01(\x03R\vpublishedTo\x12\"\n" +
+	"\rin_stock_only\x18\a \x01(\bR\vinStockOnly\"\x81\x01\n" +
+	"\n" +
+	"BookFacets\x126\n" +
+	"\n" +
+	"publishers\x18\x01 \x03(\v2\x16.catalog.v1.FacetCountR\n" +
+	"publishers\x12;\n" +
+	"\vprice_bands\x18\x02 \x03(\v2\x1a.catalog.v1.PriceBandCountR\n" +
+	"priceBands\"8\n" +
+	"\n" +
+	"FacetCount\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\rR\x05count\"v\n" +
+	"\x0ePriceBandCount\x12\x14\n" +
+	"\x05label\x18\x01 \x01(\tR\x05label\x12\x1b\n" +
+	"\tmin_price\x18\x02 \x01(\x03R\bminPrice\x12\x1b\n" +
+	"\tmax_price\x18\x03 \x01(\x03R\bmaxPrice\x12\x14\n" +
+	"\x05count\x18\x04 \x01(\rR\x05count\"\x8f\x01\n" +
 	"\x12SearchBooksRequest\x12\x18\n" +
 	"\akeyword\x18\x01 \x01(\tR\akeyword\x12\x12\n" +
 	"\x04page\x18\x02 \x01(\rR\x04page\x12\x1b\n" +
-	"\tpage_size\x18\x03 \x01(\rR\bpageSize\"\x81\x01\n" +
+	"\tpage_size\x18\x03 \x01(\rR\bpageSize\x12.\n" +
+	"\x06filter\x18\x04 \x01(\v2\x16.catalog.v1.BookFilterR\x06filter\"\xec\x01\n" +
 	"\x13SearchBooksResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12&\n" +
 	"\x05books\x18\x03 \x03(\v2\x10.catalog.v1.BookR\x05books\x12\x14\n" +
-	"\x05total\x18\x04 \x01(\rR\x05total\"\xec\x01\n" +
+	"\x05total\x18\x04 \x01(\rR\x05total\x129\n" +
+	"\n" +
+	"highlights\x18\x05 \x03(\v2\x19.catalog.v1.BookHighlightR\n" +
+	"highlights\x12.\n" +
+	"\x06facets\x18\x06 \x01(\v2\x16.catalog.v1.BookFacetsR\x06facets\"\xac\x01\n" +
+	"\rBookHighlight\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x16\n" +
+	"\x06author\x18\x03 \x01(\tR\x06author\x12\x1c\n" +
+	"\tpublisher\x18\x04 \x01(\tR\tpublisher\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x14\n" +
+	"\x05score\x18\x06 \x01(\x01R\x05score\"S\n" +
+	"\fSynonymGroup\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x14\n" +
+	"\x05words\x18\x02 \x03(\tR\x05words\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x03 \x01(\x03R\tupdatedAt\"\x1a\n" +
+	"\x18ListSynonymGroupsRequest\"{\n" +
+	"\x19ListSynonymGroupsResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x120\n" +
+	"\x06groups\x18\x03 \x03(\v2\x18.catalog.v1.SynonymGroupR\x06groups\"?\n" +
+	"\x17SaveSynonymGroupRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x14\n" +
+	"\x05words\x18\x02 \x03(\tR\x05words\"x\n" +
+	"\x18SaveSynonymGroupResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12.\n" +
+	"\x05group\x18\x03 \x01(\v2\x18.catalog.v1.SynonymGroupR\x05group\"+\n" +
+	"\x19DeleteSynonymGroupRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"J\n" +
+	"\x1aDeleteSynonymGroupResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x82\x02\n" +
 	"\x12PublishBookRequest\x12\x12\n" +
 	"\x04isbn\x18\x01 \x01(\tR\x04isbn\x12\x14\n" +
 	"\x05title\x18\x02 \x01(\tR\x05title\x12\x16\n" +
@@ -840,17 +2325,65 @@ const file_proto_catalog_v1_catalog_proto_rawDesc = "" +
 	"\x05price\x18\x05 \x01(\x03R\x05price\x12\x1b\n" +
 	"\tcover_url\x18\x06 \x01(\tR\bcoverUrl\x12 \n" +
 	"\vdescription\x18\a \x01(\tR\vdescription\x12!\n" +
-	"\fpublisher_id\x18\b \x01(\x04R\vpublisherId\"\\\n" +
+	"\fpublisher_id\x18\b \x01(\x04R\vpublisherId\x12\x14\n" +
+	"\x05draft\x18\t \x01(\bR\x05draft\"\\\n" +
 	"\x13PublishBookResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x17\n" +
-	"\abook_id\x18\x03 \x01(\x04R\x06bookId\"1\n" +
+	"\abook_id\x18\x03 \x01(\x04R\x06bookId\"\xd8\x01\n" +
+	"\x11UpdateBookRequest\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x1f\n" +
+	"\voperator_id\x18\x02 \x01(\x04R\n" +
+	"operatorId\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x16\n" +
+	"\x06author\x18\x04 \x01(\tR\x06author\x12\x1c\n" +
+	"\tpublisher\x18\x05 \x01(\tR\tpublisher\x12\x1b\n" +
+	"\tcover_url\x18\x06 \x01(\tR\bcoverUrl\x12 \n" +
+	"\vdescription\x18\a \x01(\tR\vdescription\"h\n" +
+	"\x12UpdateBookResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12$\n" +
+	"\x04book\x18\x03 \x01(\v2\x10.catalog.v1.BookR\x04book\"h\n" +
+	"\x16UpdateBookPriceRequest\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x1f\n" +
+	"\voperator_id\x18\x02 \x01(\x04R\n" +
+	"operatorId\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x03R\x05price\"\x81\x01\n" +
+	"\x17UpdateBookPriceResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1b\n" +
+	"\told_price\x18\x03 \x01(\x03R\boldPrice\x12\x1b\n" +
+	"\tnew_price\x18\x04 \x01(\x03R\bnewPrice\"k\n" +
+	"\x17ChangeBookStatusRequest\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x1f\n" +
+	"\voperator_id\x18\x02 \x01(\x04R\n" +
+	"operatorId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\x05R\x06status\"`\n" +
+	"\x18ChangeBookStatusResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\x05R\x06status\"M\n" +
+	"\x11DeleteBookRequest\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x1f\n" +
+	"\voperator_id\x18\x02 \x01(\x04R\n" +
+	"operatorId\"B\n" +
+	"\x12DeleteBookResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"N\n" +
+	"\x12RestoreBookRequest\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x1f\n" +
+	"\voperator_id\x18\x02 \x01(\x04R\n" +
+	"operatorId\"i\n" +
+	"\x13RestoreBookResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12$\n" +
+	"\x04book\x18\x03 \x01(\v2\x10.catalog.v1.BookR\x04book\"1\n" +
 	"\x14BatchGetBooksRequest\x12\x19\n" +
 	"\bbook_ids\x18\x01 \x03(\x04R\abookIds\"m\n" +
 	"\x15BatchGetBooksResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12&\n" +
-	"\x05books\x18\x03 \x03(\v2\x10.catalog.v1.BookR\x05books\"\xac\x02\n" +
+	"\x05books\x18\x03 \x03(\v2\x10.catalog.v1.BookR\x05books\"\xdf\x02\n" +
 	"\x04Book\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
 	"\x04isbn\x18\x02 \x01(\tR\x04isbn\x12\x14\n" +
@@ -865,12 +2398,24 @@ const file_proto_catalog_v1_catalog_proto_rawDesc = "" +
 	"created_at\x18\n" +
 	" \x01(\x03R\tcreatedAt\x12\x1d\n" +
 	"\n" +
-	"updated_at\x18\v \x01(\x03R\tupdatedAt2\x94\x03\n" +
+	"updated_at\x18\v \x01(\x03R\tupdatedAt\x12\x19\n" +
+	"\bin_stock\x18\f \x01(\bR\ainStock\x12\x16\n" +
+	"\x06status\x18\r \x01(\x05R\x06status2\xdf\b\n" +
 	"\x0eCatalogService\x12B\n" +
 	"\aGetBook\x12\x1a.catalog.v1.GetBookRequest\x1a\x1b.catalog.v1.GetBookResponse\x12H\n" +
 	"\tListBooks\x12\x1c.catalog.v1.ListBooksRequest\x1a\x1d.catalog.v1.ListBooksResponse\x12N\n" +
-	"\vSearchBooks\x12\x1e.catalog.v1.SearchBooksRequest\x1a\x1f.catalog.v1.SearchBooksResponse\x12N\n" +
-	"\vPublishBook\x12\x1e.catalog.v1.PublishBookRequest\x1a\x1f.catalog.v1.PublishBookResponse\x12T\n" +
+	"\vSearchBooks\x12\x1e.catalog.v1.SearchBooksRequest\x1a\x1f.catalog.v1.SearchBooksResponse\x12`\n" +
+	"\x11ListSynonymGroups\x12$.catalog.v1.ListSynonymGroupsRequest\x1a%.catalog.v1.ListSynonymGroupsResponse\x12]\n" +
+	"\x10SaveSynonymGroup\x12#.catalog.v1.SaveSynonymGroupRequest\x1a$.catalog.v1.SaveSynonymGroupResponse\x12c\n" +
+	"\x12DeleteSynonymGroup\x12%.catalog.v1.DeleteSynonymGroupRequest\x1a&.catalog.v1.DeleteSynonymGroupResponse\x12N\n" +
+	"\vPublishBook\x12\x1e.catalog.v1.PublishBookRequest\x1a\x1f.catalog.v1.PublishBookResponse\x12K\n" +
+	"\n" +
+	"UpdateBook\x12\x1d.catalog.v1.UpdateBookRequest\x1a\x1e.catalog.v1.UpdateBookResponse\x12Z\n" +
+	"\x0fUpdateBookPrice\x12\".catalog.v1.UpdateBookPriceRequest\x1a#.catalog.v1.UpdateBookPriceResponse\x12]\n" +
+	"\x10ChangeBookStatus\x12#.catalog.v1.ChangeBookStatusRequest\x1a$.catalog.v1.ChangeBookStatusResponse\x12K\n" +
+	"\n" +
+	"DeleteBook\x12\x1d.catalog.v1.DeleteBookRequest\x1a\x1e.catalog.v1.DeleteBookResponse\x12N\n" +
+	"\vRestoreBook\x12\x1e.catalog.v1.RestoreBookRequest\x1a\x1f.catalog.v1.RestoreBookResponse\x12T\n" +
 	"\rBatchGetBooks\x12 .catalog.v1.BatchGetBooksRequest\x1a!.catalog.v1.BatchGetBooksResponseB9Z7github.com/xiebiao/bookstore/proto/catalog/v1;catalogv1b\x06proto3"
 
 var (
@@ -885,40 +2430,89 @@ func file_proto_catalog_v1_catalog_proto_rawDescGZIP() []byte {
 	return file_proto_catalog_v1_catalog_proto_rawDescData
 }
 
-var file_proto_catalog_v1_catalog_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_proto_catalog_v1_catalog_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
 var file_proto_catalog_v1_catalog_proto_goTypes = []any{
-	(*GetBookRequest)(nil),        // 0: catalog.v1.GetBookRequest
-	(*GetBookResponse)(nil),       // 1: catalog.v1.GetBookResponse
-	(*ListBooksRequest)(nil),      // 2: catalog.v1.ListBooksRequest
-	(*ListBooksResponse)(nil),     // 3: catalog.v1.ListBooksResponse
-	(*SearchBooksRequest)(nil),    // 4: catalog.v1.SearchBooksRequest
-	(*SearchBooksResponse)(nil),   // 5: catalog.v1.SearchBooksResponse
-	(*PublishBookRequest)(nil),    // 6: catalog.v1.PublishBookRequest
-	(*PublishBookResponse)(nil),   // 7: catalog.v1.PublishBookResponse
-	(*BatchGetBooksRequest)(nil),  // 8: catalog.v1.BatchGetBooksRequest
-	(*BatchGetBooksResponse)(nil), // 9: catalog.v1.BatchGetBooksResponse
-	(*Book)(nil),                  // 10: catalog.v1.Book
+	(*GetBookRequest)(nil),             // 0: catalog.v1.GetBookRequest
+	(*GetBookResponse)(nil),            // 1: catalog.v1.GetBookResponse
+	(*ListBooksRequest)(nil),           // 2: catalog.v1.ListBooksRequest
+	(*ListBooksResponse)(nil),          // 3: catalog.v1.ListBooksResponse
+	(*BookFilter)(nil),                 // 4: catalog.v1.BookFilter
+	(*BookFacets)(nil),                 // 5: catalog.v1.BookFacets
+	(*FacetCount)(nil),                 // 6: catalog.v1.FacetCount
+	(*PriceBandCount)(nil),             // 7: catalog.v1.PriceBandCount
+	(*SearchBooksRequest)(nil),         // 8: catalog.v1.SearchBooksRequest
+	(*SearchBooksResponse)(nil),        // 9: catalog.v1.SearchBooksResponse
+	(*BookHighlight)(nil),              // 10: catalog.v1.BookHighlight
+	(*SynonymGroup)(nil),               // 11: catalog.v1.SynonymGroup
+	(*ListSynonymGroupsRequest)(nil),   // 12: catalog.v1.ListSynonymGroupsRequest
+	(*ListSynonymGroupsResponse)(nil),  // 13: catalog.v1.ListSynonymGroupsResponse
+	(*SaveSynonymGroupRequest)(nil),    // 14: catalog.v1.SaveSynonymGroupRequest
+	(*SaveSynonymGroupResponse)(nil),   // 15: catalog.v1.SaveSynonymGroupResponse
+	(*DeleteSynonymGroupRequest)(nil),  // 16: catalog.v1.DeleteSynonymGroupRequest
+	(*DeleteSynonymGroupResponse)(nil), // 17: catalog.v1.DeleteSynonymGroupResponse
+	(*PublishBookRequest)(nil),         // 18: catalog.v1.PublishBookRequest
+	(*PublishBookResponse)(nil),        // 19: catalog.v1.PublishBookResponse
+	(*UpdateBookRequest)(nil),          // 20: catalog.v1.UpdateBookRequest
+	(*UpdateBookResponse)(nil),         // 21: catalog.v1.UpdateBookResponse
+	(*UpdateBookPriceRequest)(nil),     // 22: catalog.v1.UpdateBookPriceRequest
+	(*UpdateBookPriceResponse)(nil),    // 23: catalog.v1.UpdateBookPriceResponse
+	(*ChangeBookStatusRequest)(nil),    // 24: catalog.v1.ChangeBookStatusRequest
+	(*ChangeBookStatusResponse)(nil),   // 25: catalog.v1.ChangeBookStatusResponse
+	(*DeleteBookRequest)(nil),          // 26: catalog.v1.DeleteBookRequest
+	(*DeleteBookResponse)(nil),         // 27: catalog.v1.DeleteBookResponse
+	(*RestoreBookRequest)(nil),         // 28: catalog.v1.RestoreBookRequest
+	(*RestoreBookResponse)(nil),        // 29: catalog.v1.RestoreBookResponse
+	(*BatchGetBooksRequest)(nil),       // 30: catalog.v1.BatchGetBooksRequest
+	(*BatchGetBooksResponse)(nil),      // 31: catalog.v1.BatchGetBooksResponse
+	(*Book)(nil),                       // 32: catalog.v1.Book
 }
 var file_proto_catalog_v1_catalog_proto_depIdxs = []int32{
-	10, // 0: catalog.v1.GetBookResponse.book:type_name -> catalog.v1.Book
-	10, // 1: catalog.v1.ListBooksResponse.books:type_name -> catalog.v1.Book
-	10, // 2: catalog.v1.SearchBooksResponse.books:type_name -> catalog.v1.Book
-	10, // 3: catalog.v1.BatchGetBooksResponse.books:type_name -> catalog.v1.Book
-	0,  // 4: catalog.v1.CatalogService.GetBook:input_type -> catalog.v1.GetBookRequest
-	2,  // 5: catalog.v1.CatalogService.ListBooks:input_type -> catalog.v1.ListBooksRequest
-	4,  // 6: catalog.v1.CatalogService.SearchBooks:input_type -> catalog.v1.SearchBooksRequest
-	6,  // 7: catalog.v1.CatalogService.PublishBook:input_type -> catalog.v1.PublishBookRequest
-	8,  // 8: catalog.v1.CatalogService.BatchGetBooks:input_type -> catalog.v1.BatchGetBooksRequest
-	1,  // 9: catalog.v1.CatalogService.GetBook:output_type -> catalog.v1.GetBookResponse
-	3,  // 10: catalog.v1.CatalogService.ListBooks:output_type -> catalog.v1.ListBooksResponse
-	5,  // 11: catalog.v1.CatalogService.SearchBooks:output_type -> catalog.v1.SearchBooksResponse
-	7,  // 12: catalog.v1.CatalogService.PublishBook:output_type -> catalog.v1.PublishBookResponse
-	9,  // 13: catalog.v1.CatalogService.BatchGetBooks:output_type -> catalog.v1.BatchGetBooksResponse
-	9,  // [9:14] is the sub-list for method output_type
-	4,  // [4:9] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	32, // 0: catalog.v1.GetBookResponse.book:type_name -> catalog.v1.Book
+	4,  // 1: catalog.v1.ListBooksRequest.filter:type_name -> catalog.v1.BookFilter
+	32, // 2: catalog.v1.ListBooksResponse.books:type_name -> catalog.v1.Book
+	5,  // 3: catalog.v1.ListBooksResponse.facets:type_name -> catalog.v1.BookFacets
+	6,  // 4: catalog.v1.BookFacets.publishers:type_name -> catalog.v1.FacetCount
+	7,  // 5: catalog.v1.BookFacets.price_bands:type_name -> catalog.v1.PriceBandCount
+	4,  // 6: catalog.v1.SearchBooksRequest.filter:type_name -> catalog.v1.BookFilter
+	32, // 7: catalog.v1.SearchBooksResponse.books:type_name -> catalog.v1.Book
+	10, // 8: catalog.v1.SearchBooksResponse.highlights:type_name -> catalog.v1.BookHighlight
+	5,  // 9: catalog.v1.SearchBooksResponse.facets:type_name -> catalog.v1.BookFacets
+	11, // 10: catalog.v1.ListSynonymGroupsResponse.groups:type_name -> catalog.v1.SynonymGroup
+	11, // 11: catalog.v1.SaveSynonymGroupResponse.group:type_name -> catalog.v1.SynonymGroup
+	32, // 12: catalog.v1.UpdateBookResponse.book:type_name -> catalog.v1.Book
+	32, // 13: catalog.v1.RestoreBookResponse.book:type_name -> catalog.v1.Book
+	32, // 14: catalog.v1.BatchGetBooksResponse.books:type_name -> catalog.v1.Book
+	0,  // 15: catalog.v1.CatalogService.GetBook:input_type -> catalog.v1.GetBookRequest
+	2,  // 16: catalog.v1.CatalogService.ListBooks:input_type -> catalog.v1.ListBooksRequest
+	8,  // 17: catalog.v1.CatalogService.SearchBooks:input_type -> catalog.v1.SearchBooksRequest
+	12, // 18: catalog.v1.CatalogService.ListSynonymGroups:input_type -> catalog.v1.ListSynonymGroupsRequest
+	14, // 19: catalog.v1.CatalogService.SaveSynonymGroup:input_type -> catalog.v1.SaveSynonymGroupRequest
+	16, // 20: catalog.v1.CatalogService.DeleteSynonymGroup:input_type -> catalog.v1.DeleteSynonymGroupRequest
+	18, // 21: catalog.v1.CatalogService.PublishBook:input_type -> catalog.v1.PublishBookRequest
+	20, // 22: catalog.v1.CatalogService.UpdateBook:input_type -> catalog.v1.UpdateBookRequest
+	22, // 23: catalog.v1.CatalogService.UpdateBookPrice:input_type -> catalog.v1.UpdateBookPriceRequest
+	24, // 24: catalog.v1.CatalogService.ChangeBookStatus:input_type -> catalog.v1.ChangeBookStatusRequest
+	26, // 25: catalog.v1.CatalogService.DeleteBook:input_type -> catalog.v1.DeleteBookRequest
+	28, // 26: catalog.v1.CatalogService.RestoreBook:input_type -> catalog.v1.RestoreBookRequest
+	30, // 27: catalog.v1.CatalogService.BatchGetBooks:input_type -> catalog.v1.BatchGetBooksRequest
+	1,  // 28: catalog.v1.CatalogService.GetBook:output_type -> catalog.v1.GetBookResponse
+	3,  // 29: catalog.v1.CatalogService.ListBooks:output_type -> catalog.v1.ListBooksResponse
+	9,  // 30: catalog.v1.CatalogService.SearchBooks:output_type -> catalog.v1.SearchBooksResponse
+	13, // 31: catalog.v1.CatalogService.ListSynonymGroups:output_type -> catalog.v1.ListSynonymGroupsResponse
+	15, // 32: catalog.v1.CatalogService.SaveSynonymGroup:output_type -> catalog.v1.SaveSynonymGroupResponse
+	17, // 33: catalog.v1.CatalogService.DeleteSynonymGroup:output_type -> catalog.v1.DeleteSynonymGroupResponse
+	19, // 34: catalog.v1.CatalogService.PublishBook:output_type -> catalog.v1.PublishBookResponse
+	21, // 35: catalog.v1.CatalogService.UpdateBook:output_type -> catalog.v1.UpdateBookResponse
+	23, // 36: catalog.v1.CatalogService.UpdateBookPrice:output_type -> catalog.v1.UpdateBookPriceResponse
+	25, // 37: catalog.v1.CatalogService.ChangeBookStatus:output_type -> catalog.v1.ChangeBookStatusResponse
+	27, // 38: catalog.v1.CatalogService.DeleteBook:output_type -> catalog.v1.DeleteBookResponse
+	29, // 39: catalog.v1.CatalogService.RestoreBook:output_type -> catalog.v1.RestoreBookResponse
+	31, // 40: catalog.v1.CatalogService.BatchGetBooks:output_type -> catalog.v1.BatchGetBooksResponse
+	28, // [28:41] is the sub-list for method output_type
+	15, // [15:28] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_proto_catalog_v1_catalog_proto_init() }
@@ -932,7 +2526,7 @@ func file_proto_catalog_v1_catalog_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_catalog_v1_catalog_proto_rawDesc), len(file_proto_catalog_v1_catalog_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   11,
+			NumMessages:   33,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
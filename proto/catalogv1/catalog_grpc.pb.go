@@ -19,11 +19,19 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CatalogService_GetBook_FullMethodName       = "/catalog.v1.CatalogService/GetBook"
-	CatalogService_ListBooks_FullMethodName     = "/catalog.v1.CatalogService/ListBooks"
-	CatalogService_SearchBooks_FullMethodName   = "/catalog.v1.CatalogService/SearchBooks"
-	CatalogService_PublishBook_FullMethodName   = "/catalog.v1.CatalogService/PublishBook"
-	CatalogService_BatchGetBooks_FullMethodName = "/catalog.v1.CatalogService/BatchGetBooks"
+	CatalogService_GetBook_FullMethodName            = "/catalog.v1.CatalogService/GetBook"
+	CatalogService_ListBooks_FullMethodName          = "/catalog.v1.CatalogService/ListBooks"
+	CatalogService_SearchBooks_FullMethodName        = "/catalog.v1.CatalogService/SearchBooks"
+	CatalogService_ListSynonymGroups_FullMethodName  = "/catalog.v1.CatalogService/ListSynonymGroups"
+	CatalogService_SaveSynonymGroup_FullMethodName   = "/catalog.v1.CatalogService/SaveSynonymGroup"
+	CatalogService_DeleteSynonymGroup_FullMethodName = "/catalog.v1.CatalogService/DeleteSynonymGroup"
+	CatalogService_PublishBook_FullMethodName        = "/catalog.v1.CatalogService/PublishBook"
+	CatalogService_UpdateBook_FullMethodName         = "/catalog.v1.CatalogService/UpdateBook"
+	CatalogService_UpdateBookPrice_FullMethodName    = "/catalog.v1.CatalogService/UpdateBookPrice"
+	CatalogService_ChangeBookStatus_FullMethodName   = "/catalog.v1.CatalogService/ChangeBookStatus"
+	CatalogService_DeleteBook_FullMethodName         = "/catalog.v1.CatalogService/DeleteBook"
+	CatalogService_RestoreBook_FullMethodName        = "/catalog.v1.CatalogService/RestoreBook"
+	CatalogService_BatchGetBooks_FullMethodName      = "/catalog.v1.CatalogService/BatchGetBooks"
 )
 
 // CatalogServiceClient is the client API for CatalogService service.
@@ -33,21 +41,41 @@ type CatalogServiceClient interface {
 	// 获取图书详情
 	// 用例：用户点击图书详情页
 	GetBook(ctx context.Context, in *GetBookRequest, opts ...grpc.CallOption) (*GetBookResponse, error)
-	// 图书列表（分页、排序）
+	// 图书列表（分页、排序、筛选）
 	// 教学重点：
-	// 1. 分页参数（page、page_size）
+	// 1. 分页参数（page、page_size），或游标分页（cursor/next_cursor，深翻页不变慢、翻页期间上新不重复）
 	// 2. 排序参数（sort_by: created_at, price）
+	// 3. 筛选条件（价格区间、作者、出版社、发布日期、只看有货）与分面统计
 	ListBooks(ctx context.Context, in *ListBooksRequest, opts ...grpc.CallOption) (*ListBooksResponse, error)
 	// 搜索图书
 	// 教学重点：
-	// 1. 多字段搜索（title、author、publisher）
-	// 2. Phase 3可扩展为ElasticSearch
+	// 1. 全文搜索（title、author、publisher、description），倒排索引代替LIKE
+	// 2. 按相关性排序，返回命中字段的高亮
+	// 3. 支持拼音（全拼、首字母）、词典分词和同义词扩展
+	// 4. 与ListBooks相同的筛选条件和分面统计
 	SearchBooks(ctx context.Context, in *SearchBooksRequest, opts ...grpc.CallOption) (*SearchBooksResponse, error)
+	// 搜索同义词管理（管理员维护）
+	// 教学重点：词典只影响查询，修改后立即生效，不需要重建索引
+	ListSynonymGroups(ctx context.Context, in *ListSynonymGroupsRequest, opts ...grpc.CallOption) (*ListSynonymGroupsResponse, error)
+	SaveSynonymGroup(ctx context.Context, in *SaveSynonymGroupRequest, opts ...grpc.CallOption) (*SaveSynonymGroupResponse, error)
+	DeleteSynonymGroup(ctx context.Context, in *DeleteSynonymGroupRequest, opts ...grpc.CallOption) (*DeleteSynonymGroupResponse, error)
 	// 发布图书（内部接口，供api-gateway调用）
 	// 教学重点：
 	// 1. 需要验证用户身份（api-gateway已完成）
 	// 2. ISBN唯一性校验
+	// 3. draft=true时保存为草稿，不对外展示
 	PublishBook(ctx context.Context, in *PublishBookRequest, opts ...grpc.CallOption) (*PublishBookResponse, error)
+	// 图书管理（仅发布者本人可操作）
+	// 教学重点：
+	// 1. 归属校验：operator_id必须等于图书的publisher_id
+	// 2. 生命周期：草稿 → 在售 ⇄ 已下架；下架不删除数据，随时可以重新上架
+	// 3. 删除是软删除，可以恢复
+	// 4. 写操作后删除缓存（详情 + 列表）并更新搜索索引
+	UpdateBook(ctx context.Context, in *UpdateBookRequest, opts ...grpc.CallOption) (*UpdateBookResponse, error)
+	UpdateBookPrice(ctx context.Context, in *UpdateBookPriceRequest, opts ...grpc.CallOption) (*UpdateBookPriceResponse, error)
+	ChangeBookStatus(ctx context.Context, in *ChangeBookStatusRequest, opts ...grpc.CallOption) (*ChangeBookStatusResponse, error)
+	DeleteBook(ctx context.Context, in *DeleteBookRequest, opts ...grpc.CallOption) (*DeleteBookResponse, error)
+	RestoreBook(ctx context.Context, in *RestoreBookRequest, opts ...grpc.CallOption) (*RestoreBookResponse, error)
 	// 批量获取图书信息（供order-service调用）
 	// 用例：创建订单时需要获取图书价格
 	BatchGetBooks(ctx context.Context, in *BatchGetBooksRequest, opts ...grpc.CallOption) (*BatchGetBooksResponse, error)
@@ -91,6 +119,36 @@ func (c *catalogServiceClient) SearchBooks(ctx context.Context, in *SearchBooksR
 	return out, nil
 }
 
+func (c *catalogServiceClient) ListSynonymGroups(ctx context.Context, in *ListSynonymGroupsRequest, opts ...grpc.CallOption) (*ListSynonymGroupsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSynonymGroupsResponse)
+	err := c.cc.Invoke(ctx, CatalogService_ListSynonymGroups_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) SaveSynonymGroup(ctx context.Context, in *SaveSynonymGroupRequest, opts ...grpc.CallOption) (*SaveSynonymGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SaveSynonymGroupResponse)
+	err := c.cc.Invoke(ctx, CatalogService_SaveSynonymGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) DeleteSynonymGroup(ctx context.Context, in *DeleteSynonymGroupRequest, opts ...grpc.CallOption) (*DeleteSynonymGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteSynonymGroupResponse)
+	err := c.cc.Invoke(ctx, CatalogService_DeleteSynonymGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *catalogServiceClient) PublishBook(ctx context.Context, in *PublishBookRequest, opts ...grpc.CallOption) (*PublishBookResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PublishBookResponse)
@@ -101,6 +159,56 @@ func (c *catalogServiceClient) PublishBook(ctx context.Context, in *PublishBookR
 	return out, nil
 }
 
+func (c *catalogServiceClient) UpdateBook(ctx context.Context, in *UpdateBookRequest, opts ...grpc.CallOption) (*UpdateBookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateBookResponse)
+	err := c.cc.Invoke(ctx, CatalogService_UpdateBook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) UpdateBookPrice(ctx context.Context, in *UpdateBookPriceRequest, opts ...grpc.CallOption) (*UpdateBookPriceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateBookPriceResponse)
+	err := c.cc.Invoke(ctx, CatalogService_UpdateBookPrice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) ChangeBookStatus(ctx context.Context, in *ChangeBookStatusRequest, opts ...grpc.CallOption) (*ChangeBookStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChangeBookStatusResponse)
+	err := c.cc.Invoke(ctx, CatalogService_ChangeBookStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) DeleteBook(ctx context.Context, in *DeleteBookRequest, opts ...grpc.CallOption) (*DeleteBookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteBookResponse)
+	err := c.cc.Invoke(ctx, CatalogService_DeleteBook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) RestoreBook(ctx context.Context, in *RestoreBookRequest, opts ...grpc.CallOption) (*RestoreBookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreBookResponse)
+	err := c.cc.Invoke(ctx, CatalogService_RestoreBook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *catalogServiceClient) BatchGetBooks(ctx context.Context, in *BatchGetBooksRequest, opts ...grpc.CallOption) (*BatchGetBooksResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(BatchGetBooksResponse)
@@ -118,21 +226,41 @@ type CatalogServiceServer interface {
 	// 获取图书详情
 	// 用例：用户点击图书详情页
 	GetBook(context.Context, *GetBookRequest) (*GetBookResponse, error)
-	// 图书列表（分页、排序）
+	// 图书列表（分页、排序、筛选）
 	// 教学重点：
-	// 1. 分页参数（page、page_size）
+	// 1. 分页参数（page、page_size），或游标分页（cursor/next_cursor，深翻页不变慢、翻页期间上新不重复）
 	// 2. 排序参数（sort_by: created_at, price）
+	// 3. 筛选条件（价格区间、作者、出版社、发布日期、只看有货）与分面统计
 	ListBooks(context.Context, *ListBooksRequest) (*ListBooksResponse, error)
 	// 搜索图书
 	// 教学重点：
-	// 1. 多字段搜索（title、author、publisher）
-	// 2. Phase 3可扩展为ElasticSearch
+	// 1. 全文搜索（title、author、publisher、description），倒排索引代替LIKE
+	// 2. 按相关性排序，返回命中字段的高亮
+	// 3. 支持拼音（全拼、首字母）、词典分词和同义词扩展
+	// 4. 与ListBooks相同的筛选条件和分面统计
 	SearchBooks(context.Context, *SearchBooksRequest) (*SearchBooksResponse, error)
+	// 搜索同义词管理（管理员维护）
+	// 教学重点：词典只影响查询，修改后立即生效，不需要重建索引
+	ListSynonymGroups(context.Context, *ListSynonymGroupsRequest) (*ListSynonymGroupsResponse, error)
+	SaveSynonymGroup(context.Context, *SaveSynonymGroupRequest) (*SaveSynonymGroupResponse, error)
+	DeleteSynonymGroup(context.Context, *DeleteSynonymGroupRequest) (*DeleteSynonymGroupResponse, error)
 	// 发布图书（内部接口，供api-gateway调用）
 	// 教学重点：
 	// 1. 需要验证用户身份（api-gateway已完成）
 	// 2. ISBN唯一性校验
+	// 3. draft=true时保存为草稿，不对外展示
 	PublishBook(context.Context, *PublishBookRequest) (*PublishBookResponse, error)
+	// 图书管理（仅发布者本人可操作）
+	// 教学重点：
+	// 1. 归属校验：operator_id必须等于图书的publisher_id
+	// 2. 生命周期：草稿 → 在售 ⇄ 已下架；下架不删除数据，随时可以重新上架
+	// 3. 删除是软删除，可以恢复
+	// 4. 写操作后删除缓存（详情 + 列表）并更新搜索索引
+	UpdateBook(context.Context, *UpdateBookRequest) (*UpdateBookResponse, error)
+	UpdateBookPrice(context.Context, *UpdateBookPriceRequest) (*UpdateBookPriceResponse, error)
+	ChangeBookStatus(context.Context, *ChangeBookStatusRequest) (*ChangeBookStatusResponse, error)
+	DeleteBook(context.Context, *DeleteBookRequest) (*DeleteBookResponse, error)
+	RestoreBook(context.Context, *RestoreBookRequest) (*RestoreBookResponse, error)
 	// 批量获取图书信息（供order-service调用）
 	// 用例：创建订单时需要获取图书价格
 	BatchGetBooks(context.Context, *BatchGetBooksRequest) (*BatchGetBooksResponse, error)
@@ -155,9 +283,33 @@ func (UnimplementedCatalogServiceServer) ListBooks(context.Context, *ListBooksRe
 func (UnimplementedCatalogServiceServer) SearchBooks(context.Context, *SearchBooksRequest) (*SearchBooksResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SearchBooks not implemented")
 }
+func (UnimplementedCatalogServiceServer) ListSynonymGroups(context.Context, *ListSynonymGroupsRequest) (*ListSynonymGroupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSynonymGroups not implemented")
+}
+func (UnimplementedCatalogServiceServer) SaveSynonymGroup(context.Context, *SaveSynonymGroupRequest) (*SaveSynonymGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveSynonymGroup not implemented")
+}
+func (UnimplementedCatalogServiceServer) DeleteSynonymGroup(context.Context, *DeleteSynonymGroupRequest) (*DeleteSynonymGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSynonymGroup not implemented")
+}
 func (UnimplementedCatalogServiceServer) PublishBook(context.Context, *PublishBookRequest) (*PublishBookResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PublishBook not implemented")
 }
+func (UnimplementedCatalogServiceServer) UpdateBook(context.Context, *UpdateBookRequest) (*UpdateBookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateBook not implemented")
+}
+func (UnimplementedCatalogServiceServer) UpdateBookPrice(context.Context, *UpdateBookPriceRequest) (*UpdateBookPriceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateBookPrice not implemented")
+}
+func (UnimplementedCatalogServiceServer) ChangeBookStatus(context.Context, *ChangeBookStatusRequest) (*ChangeBookStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangeBookStatus not implemented")
+}
+func (UnimplementedCatalogServiceServer) DeleteBook(context.Context, *DeleteBookRequest) (*DeleteBookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteBook not implemented")
+}
+func (UnimplementedCatalogServiceServer) RestoreBook(context.Context, *RestoreBookRequest) (*RestoreBookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreBook not implemented")
+}
 func (UnimplementedCatalogServiceServer) BatchGetBooks(context.Context, *BatchGetBooksRequest) (*BatchGetBooksResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BatchGetBooks not implemented")
 }
@@ -236,6 +388,60 @@ func _CatalogService_SearchBooks_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CatalogService_ListSynonymGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSynonymGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).ListSynonymGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CatalogService_ListSynonymGroups_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).ListSynonymGroups(ctx, req.(*ListSynonymGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_SaveSynonymGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SaveSynonymGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).SaveSynonymGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CatalogService_SaveSynonymGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).SaveSynonymGroup(ctx, req.(*SaveSynonymGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_DeleteSynonymGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSynonymGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).DeleteSynonymGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CatalogService_DeleteSynonymGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).DeleteSynonymGroup(ctx, req.(*DeleteSynonymGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _CatalogService_PublishBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PublishBookRequest)
 	if err := dec(in); err != nil {
@@ -254,6 +460,96 @@ func _CatalogService_PublishBook_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CatalogService_UpdateBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateBookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).UpdateBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CatalogService_UpdateBook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).UpdateBook(ctx, req.(*UpdateBookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_UpdateBookPrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateBookPriceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).UpdateBookPrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CatalogService_UpdateBookPrice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).UpdateBookPrice(ctx, req.(*UpdateBookPriceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_ChangeBookStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeBookStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).ChangeBookStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CatalogService_ChangeBookStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).ChangeBookStatus(ctx, req.(*ChangeBookStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_DeleteBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).DeleteBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CatalogService_DeleteBook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).DeleteBook(ctx, req.(*DeleteBookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_RestoreBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreBookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).RestoreBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CatalogService_RestoreBook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).RestoreBook(ctx, req.(*RestoreBookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _CatalogService_BatchGetBooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(BatchGetBooksRequest)
 	if err := dec(in); err != nil {
@@ -291,10 +587,42 @@ var CatalogService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SearchBooks",
 			Handler:    _CatalogService_SearchBooks_Handler,
 		},
+		{
+			MethodName: "ListSynonymGroups",
+			Handler:    _CatalogService_ListSynonymGroups_Handler,
+		},
+		{
+			MethodName: "SaveSynonymGroup",
+			Handler:    _CatalogService_SaveSynonymGroup_Handler,
+		},
+		{
+			MethodName: "DeleteSynonymGroup",
+			Handler:    _CatalogService_DeleteSynonymGroup_Handler,
+		},
 		{
 			MethodName: "PublishBook",
 			Handler:    _CatalogService_PublishBook_Handler,
 		},
+		{
+			MethodName: "UpdateBook",
+			Handler:    _CatalogService_UpdateBook_Handler,
+		},
+		{
+			MethodName: "UpdateBookPrice",
+			Handler:    _CatalogService_UpdateBookPrice_Handler,
+		},
+		{
+			MethodName: "ChangeBookStatus",
+			Handler:    _CatalogService_ChangeBookStatus_Handler,
+		},
+		{
+			MethodName: "DeleteBook",
+			Handler:    _CatalogService_DeleteBook_Handler,
+		},
+		{
+			MethodName: "RestoreBook",
+			Handler:    _CatalogService_RestoreBook_Handler,
+		},
 		{
 			MethodName: "BatchGetBooks",
 			Handler:    _CatalogService_BatchGetBooks_Handler,
@@ -71,7 +71,8 @@ type GetStockResponse struct {
 	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	BookId        uint64                 `protobuf:"varint,3,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
-	Stock         int32                  `protobuf:"varint,4,opt,name=stock,proto3" json:"stock,omitempty"` // 库存数量
+	Stock         int32                  `protobuf:"varint,4,opt,name=stock,proto3" json:"stock,omitempty"`          // 库存数量（全部仓库合计的可售库存）
+	Warehouses    []*WarehouseStock      `protobuf:"bytes,5,rep,name=warehouses,proto3" json:"warehouses,omitempty"` // 各仓库库存（warehouse_id=0为未分配仓库的库存）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -134,6 +135,188 @@ func (x *GetStockResponse) GetStock() int32 {
 	return 0
 }
 
+func (x *GetStockResponse) GetWarehouses() []*WarehouseStock {
+	if x != nil {
+		return x.Warehouses
+	}
+	return nil
+}
+
+// 订阅库存变更
+type WatchStockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookIds       []uint64               `protobuf:"varint,1,rep,packed,name=book_ids,json=bookIds,proto3" json:"book_ids,omitempty"` // 订阅的图书（最多100本）
+	FromSeq       uint64                 `protobuf:"varint,2,opt,name=from_seq,json=fromSeq,proto3" json:"from_seq,omitempty"`        // 续传位置（上次收到的最大seq，0表示先推送全部图书的当前库存）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchStockRequest) Reset() {
+	*x = WatchStockRequest{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchStockRequest) ProtoMessage() {}
+
+func (x *WatchStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchStockRequest.ProtoReflect.Descriptor instead.
+func (*WatchStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WatchStockRequest) GetBookIds() []uint64 {
+	if x != nil {
+		return x.BookIds
+	}
+	return nil
+}
+
+func (x *WatchStockRequest) GetFromSeq() uint64 {
+	if x != nil {
+		return x.FromSeq
+	}
+	return 0
+}
+
+// 库存变更
+type StockChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"` // 全局递增序号（从未变更过的图书初始推送时为0）
+	BookId        uint64                 `protobuf:"varint,2,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	Stock         int32                  `protobuf:"varint,3,opt,name=stock,proto3" json:"stock,omitempty"` // 变更后的可售库存
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StockChange) Reset() {
+	*x = StockChange{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StockChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StockChange) ProtoMessage() {}
+
+func (x *StockChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StockChange.ProtoReflect.Descriptor instead.
+func (*StockChange) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StockChange) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *StockChange) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *StockChange) GetStock() int32 {
+	if x != nil {
+		return x.Stock
+	}
+	return 0
+}
+
+// 仓库库存
+type WarehouseStock struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WarehouseId   uint64                 `protobuf:"varint,1,opt,name=warehouse_id,json=warehouseId,proto3" json:"warehouse_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Stock         int32                  `protobuf:"varint,3,opt,name=stock,proto3" json:"stock,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WarehouseStock) Reset() {
+	*x = WarehouseStock{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WarehouseStock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WarehouseStock) ProtoMessage() {}
+
+func (x *WarehouseStock) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WarehouseStock.ProtoReflect.Descriptor instead.
+func (*WarehouseStock) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WarehouseStock) GetWarehouseId() uint64 {
+	if x != nil {
+		return x.WarehouseId
+	}
+	return 0
+}
+
+func (x *WarehouseStock) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *WarehouseStock) GetStock() int32 {
+	if x != nil {
+		return x.Stock
+	}
+	return 0
+}
+
 // 批量查询库存
 type BatchGetStockRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -144,7 +327,7 @@ type BatchGetStockRequest struct {
 
 func (x *BatchGetStockRequest) Reset() {
 	*x = BatchGetStockRequest{}
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[2]
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -156,7 +339,7 @@ func (x *BatchGetStockRequest) String() string {
 func (*BatchGetStockRequest) ProtoMessage() {}
 
 func (x *BatchGetStockRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[2]
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -169,7 +352,7 @@ func (x *BatchGetStockRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BatchGetStockRequest.ProtoReflect.Descriptor instead.
 func (*BatchGetStockRequest) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{2}
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *BatchGetStockRequest) GetBookIds() []uint64 {
@@ -190,7 +373,7 @@ type BatchGetStockResponse struct {
 
 func (x *BatchGetStockResponse) Reset() {
 	*x = BatchGetStockResponse{}
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[3]
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -202,7 +385,7 @@ func (x *BatchGetStockResponse) String() string {
 func (*BatchGetStockResponse) ProtoMessage() {}
 
 func (x *BatchGetStockResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[3]
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -215,7 +398,7 @@ func (x *BatchGetStockResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BatchGetStockResponse.ProtoReflect.Descriptor instead.
 func (*BatchGetStockResponse) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{3}
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *BatchGetStockResponse) GetCode() uint32 {
@@ -250,7 +433,7 @@ type StockInfo struct {
 
 func (x *StockInfo) Reset() {
 	*x = StockInfo{}
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[4]
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -262,7 +445,7 @@ func (x *StockInfo) String() string {
 func (*StockInfo) ProtoMessage() {}
 
 func (x *StockInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[4]
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -275,7 +458,7 @@ func (x *StockInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StockInfo.ProtoReflect.Descriptor instead.
 func (*StockInfo) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{4}
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *StockInfo) GetBookId() uint64 {
@@ -294,17 +477,18 @@ func (x *StockInfo) GetStock() int32 {
 
 // 扣减库存
 type DeductStockRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	BookId        uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
-	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`              // 扣减数量
-	OrderId       uint64                 `protobuf:"varint,3,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"` // 订单ID（用于幂等性控制）
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	BookId               uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	Quantity             int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`                                                       // 扣减数量
+	OrderId              uint64                 `protobuf:"varint,3,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`                                          // 订单ID（用于幂等性控制）
+	PreferredWarehouseId uint64                 `protobuf:"varint,4,opt,name=preferred_warehouse_id,json=preferredWarehouseId,proto3" json:"preferred_warehouse_id,omitempty"` // 优先发货仓库（0为不指定），不足部分按仓库优先级兜底
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *DeductStockRequest) Reset() {
 	*x = DeductStockRequest{}
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[5]
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -316,7 +500,7 @@ func (x *DeductStockRequest) String() string {
 func (*DeductStockRequest) ProtoMessage() {}
 
 func (x *DeductStockRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[5]
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -329,7 +513,7 @@ func (x *DeductStockRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeductStockRequest.ProtoReflect.Descriptor instead.
 func (*DeductStockRequest) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{5}
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *DeductStockRequest) GetBookId() uint64 {
@@ -353,6 +537,13 @@ func (x *DeductStockRequest) GetOrderId() uint64 {
 	return 0
 }
 
+func (x *DeductStockRequest) GetPreferredWarehouseId() uint64 {
+	if x != nil {
+		return x.PreferredWarehouseId
+	}
+	return 0
+}
+
 type DeductStockResponse struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	Code           uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0成功，1库存不足，2其他错误
@@ -364,7 +555,7 @@ type DeductStockResponse struct {
 
 func (x *DeductStockResponse) Reset() {
 	*x = DeductStockResponse{}
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[6]
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -376,7 +567,7 @@ func (x *DeductStockResponse) String() string {
 func (*DeductStockResponse) ProtoMessage() {}
 
 func (x *DeductStockResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[6]
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -389,7 +580,7 @@ func (x *DeductStockResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeductStockResponse.ProtoReflect.Descriptor instead.
 func (*DeductStockResponse) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{6}
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *DeductStockResponse) GetCode() uint32 {
@@ -426,7 +617,7 @@ type ReleaseStockRequest struct {
 
 func (x *ReleaseStockRequest) Reset() {
 	*x = ReleaseStockRequest{}
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[7]
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -438,7 +629,7 @@ func (x *ReleaseStockRequest) String() string {
 func (*ReleaseStockRequest) ProtoMessage() {}
 
 func (x *ReleaseStockRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[7]
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -451,7 +642,7 @@ func (x *ReleaseStockRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReleaseStockRequest.ProtoReflect.Descriptor instead.
 func (*ReleaseStockRequest) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{7}
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *ReleaseStockRequest) GetBookId() uint64 {
@@ -493,7 +684,7 @@ type ReleaseStockResponse struct {
 
 func (x *ReleaseStockResponse) Reset() {
 	*x = ReleaseStockResponse{}
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[8]
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -505,7 +696,7 @@ func (x *ReleaseStockResponse) String() string {
 func (*ReleaseStockResponse) ProtoMessage() {}
 
 func (x *ReleaseStockResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[8]
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -518,7 +709,7 @@ func (x *ReleaseStockResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReleaseStockResponse.ProtoReflect.Descriptor instead.
 func (*ReleaseStockResponse) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{8}
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *ReleaseStockResponse) GetCode() uint32 {
@@ -542,30 +733,30 @@ func (x *ReleaseStockResponse) GetCurrentStock() int32 {
 	return 0
 }
 
-// 补充库存
-type RestockInventoryRequest struct {
+// 批量操作的单项
+type StockItem struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	BookId        uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
-	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"` // 补充数量
+	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RestockInventoryRequest) Reset() {
-	*x = RestockInventoryRequest{}
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[9]
+func (x *StockItem) Reset() {
+	*x = StockItem{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RestockInventoryRequest) String() string {
+func (x *StockItem) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RestockInventoryRequest) ProtoMessage() {}
+func (*StockItem) ProtoMessage() {}
 
-func (x *RestockInventoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[9]
+func (x *StockItem) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -576,49 +767,50 @@ func (x *RestockInventoryRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RestockInventoryRequest.ProtoReflect.Descriptor instead.
-func (*RestockInventoryRequest) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use StockItem.ProtoReflect.Descriptor instead.
+func (*StockItem) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *RestockInventoryRequest) GetBookId() uint64 {
+func (x *StockItem) GetBookId() uint64 {
 	if x != nil {
 		return x.BookId
 	}
 	return 0
 }
 
-func (x *RestockInventoryRequest) GetQuantity() int32 {
+func (x *StockItem) GetQuantity() int32 {
 	if x != nil {
 		return x.Quantity
 	}
 	return 0
 }
 
-type RestockInventoryResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	CurrentStock  int32                  `protobuf:"varint,3,opt,name=current_stock,json=currentStock,proto3" json:"current_stock,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+// 批量扣减库存
+type BatchDeductStockRequest struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Items                []*StockItem           `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`                                                              // 同一图书出现多次时数量合并
+	OrderId              uint64                 `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`                                          // 订单ID（幂等键，与DeductStock共用扣减记录）
+	PreferredWarehouseId uint64                 `protobuf:"varint,3,opt,name=preferred_warehouse_id,json=preferredWarehouseId,proto3" json:"preferred_warehouse_id,omitempty"` // 优先发货仓库（0为不指定）
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
-func (x *RestockInventoryResponse) Reset() {
-	*x = RestockInventoryResponse{}
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[10]
+func (x *BatchDeductStockRequest) Reset() {
+	*x = BatchDeductStockRequest{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RestockInventoryResponse) String() string {
+func (x *BatchDeductStockRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RestockInventoryResponse) ProtoMessage() {}
+func (*BatchDeductStockRequest) ProtoMessage() {}
 
-func (x *RestockInventoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[10]
+func (x *BatchDeductStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -629,57 +821,57 @@ func (x *RestockInventoryResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RestockInventoryResponse.ProtoReflect.Descriptor instead.
-func (*RestockInventoryResponse) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use BatchDeductStockRequest.ProtoReflect.Descriptor instead.
+func (*BatchDeductStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *RestockInventoryResponse) GetCode() uint32 {
+func (x *BatchDeductStockRequest) GetItems() []*StockItem {
 	if x != nil {
-		return x.Code
+		return x.Items
 	}
-	return 0
+	return nil
 }
 
-func (x *RestockInventoryResponse) GetMessage() string {
+func (x *BatchDeductStockRequest) GetOrderId() uint64 {
 	if x != nil {
-		return x.Message
+		return x.OrderId
 	}
-	return ""
+	return 0
 }
 
-func (x *RestockInventoryResponse) GetCurrentStock() int32 {
+func (x *BatchDeductStockRequest) GetPreferredWarehouseId() uint64 {
 	if x != nil {
-		return x.CurrentStock
+		return x.PreferredWarehouseId
 	}
 	return 0
 }
 
-// 获取库存变更日志
-type GetInventoryLogsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	BookId        uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
-	Page          uint32                 `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
-	PageSize      uint32                 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type BatchDeductStockResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Code               uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0成功（含重复扣减），40001参数错误，40100库存不足
+	Message            string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	InsufficientBookId uint64                 `protobuf:"varint,3,opt,name=insufficient_book_id,json=insufficientBookId,proto3" json:"insufficient_book_id,omitempty"` // 库存不足的图书ID（code=40100时）
+	Stocks             []*StockInfo           `protobuf:"bytes,4,rep,name=stocks,proto3" json:"stocks,omitempty"`                                                      // 扣减后各图书的剩余库存
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
-func (x *GetInventoryLogsRequest) Reset() {
-	*x = GetInventoryLogsRequest{}
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[11]
+func (x *BatchDeductStockResponse) Reset() {
+	*x = BatchDeductStockResponse{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetInventoryLogsRequest) String() string {
+func (x *BatchDeductStockResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetInventoryLogsRequest) ProtoMessage() {}
+func (*BatchDeductStockResponse) ProtoMessage() {}
 
-func (x *GetInventoryLogsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[11]
+func (x *BatchDeductStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -690,57 +882,2377 @@ func (x *GetInventoryLogsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetInventoryLogsRequest.ProtoReflect.Descriptor instead.
-func (*GetInventoryLogsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use BatchDeductStockResponse.ProtoReflect.Descriptor instead.
+func (*BatchDeductStockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *GetInventoryLogsRequest) GetBookId() uint64 {
+func (x *BatchDeductStockResponse) GetCode() uint32 {
 	if x != nil {
-		return x.BookId
+		return x.Code
 	}
 	return 0
 }
 
-func (x *GetInventoryLogsRequest) GetPage() uint32 {
+func (x *BatchDeductStockResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *BatchDeductStockResponse) GetInsufficientBookId() uint64 {
+	if x != nil {
+		return x.InsufficientBookId
+	}
+	return 0
+}
+
+func (x *BatchDeductStockResponse) GetStocks() []*StockInfo {
+	if x != nil {
+		return x.Stocks
+	}
+	return nil
+}
+
+// 批量释放库存
+type BatchReleaseStockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*StockItem           `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	OrderId       uint64                 `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"` // 释放原因：order_cancelled, payment_failed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchReleaseStockRequest) Reset() {
+	*x = BatchReleaseStockRequest{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchReleaseStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchReleaseStockRequest) ProtoMessage() {}
+
+func (x *BatchReleaseStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchReleaseStockRequest.ProtoReflect.Descriptor instead.
+func (*BatchReleaseStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *BatchReleaseStockRequest) GetItems() []*StockItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *BatchReleaseStockRequest) GetOrderId() uint64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *BatchReleaseStockRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type BatchReleaseStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0成功（未扣减或已释放的图书跳过）
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Stocks        []*StockInfo           `protobuf:"bytes,3,rep,name=stocks,proto3" json:"stocks,omitempty"` // 释放后各图书的当前库存
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchReleaseStockResponse) Reset() {
+	*x = BatchReleaseStockResponse{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchReleaseStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchReleaseStockResponse) ProtoMessage() {}
+
+func (x *BatchReleaseStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchReleaseStockResponse.ProtoReflect.Descriptor instead.
+func (*BatchReleaseStockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *BatchReleaseStockResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *BatchReleaseStockResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *BatchReleaseStockResponse) GetStocks() []*StockInfo {
+	if x != nil {
+		return x.Stocks
+	}
+	return nil
+}
+
+// 预占库存
+type ReserveStockRequest struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	BookId               uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	Quantity             int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	OrderId              uint64                 `protobuf:"varint,3,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`                                          // 订单ID（幂等键）
+	TtlSeconds           int32                  `protobuf:"varint,4,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`                                 // 预占有效期（秒），0使用服务端默认值
+	PreferredWarehouseId uint64                 `protobuf:"varint,5,opt,name=preferred_warehouse_id,json=preferredWarehouseId,proto3" json:"preferred_warehouse_id,omitempty"` // 优先发货仓库（0为不指定）
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *ReserveStockRequest) Reset() {
+	*x = ReserveStockRequest{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReserveStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReserveStockRequest) ProtoMessage() {}
+
+func (x *ReserveStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReserveStockRequest.ProtoReflect.Descriptor instead.
+func (*ReserveStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ReserveStockRequest) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *ReserveStockRequest) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *ReserveStockRequest) GetOrderId() uint64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *ReserveStockRequest) GetTtlSeconds() int32 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *ReserveStockRequest) GetPreferredWarehouseId() uint64 {
+	if x != nil {
+		return x.PreferredWarehouseId
+	}
+	return 0
+}
+
+type ReserveStockResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Code           uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0成功，40100库存不足
+	Message        string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	RemainingStock int32                  `protobuf:"varint,3,opt,name=remaining_stock,json=remainingStock,proto3" json:"remaining_stock,omitempty"` // 预占后可用库存
+	ExpiresAt      int64                  `protobuf:"varint,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`                // 预占过期时间（Unix秒）
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ReserveStockResponse) Reset() {
+	*x = ReserveStockResponse{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReserveStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReserveStockResponse) ProtoMessage() {}
+
+func (x *ReserveStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReserveStockResponse.ProtoReflect.Descriptor instead.
+func (*ReserveStockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ReserveStockResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ReserveStockResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ReserveStockResponse) GetRemainingStock() int32 {
+	if x != nil {
+		return x.RemainingStock
+	}
+	return 0
+}
+
+func (x *ReserveStockResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+// 确认预占
+type ConfirmReservationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookId        uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	OrderId       uint64                 `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfirmReservationRequest) Reset() {
+	*x = ConfirmReservationRequest{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmReservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmReservationRequest) ProtoMessage() {}
+
+func (x *ConfirmReservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmReservationRequest.ProtoReflect.Descriptor instead.
+func (*ConfirmReservationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ConfirmReservationRequest) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *ConfirmReservationRequest) GetOrderId() uint64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+type ConfirmReservationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0成功，40401预占不存在，40101预占已取消
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfirmReservationResponse) Reset() {
+	*x = ConfirmReservationResponse{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmReservationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmReservationResponse) ProtoMessage() {}
+
+func (x *ConfirmReservationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmReservationResponse.ProtoReflect.Descriptor instead.
+func (*ConfirmReservationResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ConfirmReservationResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ConfirmReservationResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// 取消预占
+type CancelReservationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookId        uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	OrderId       uint64                 `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"` // 取消原因：order_cancelled, payment_timeout
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelReservationRequest) Reset() {
+	*x = CancelReservationRequest{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelReservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelReservationRequest) ProtoMessage() {}
+
+func (x *CancelReservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelReservationRequest.ProtoReflect.Descriptor instead.
+func (*CancelReservationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CancelReservationRequest) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *CancelReservationRequest) GetOrderId() uint64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *CancelReservationRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type CancelReservationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0成功，40401预占不存在，40102预占已确认
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	CurrentStock  int32                  `protobuf:"varint,3,opt,name=current_stock,json=currentStock,proto3" json:"current_stock,omitempty"` // 取消后可用库存
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelReservationResponse) Reset() {
+	*x = CancelReservationResponse{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelReservationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelReservationResponse) ProtoMessage() {}
+
+func (x *CancelReservationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelReservationResponse.ProtoReflect.Descriptor instead.
+func (*CancelReservationResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CancelReservationResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *CancelReservationResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CancelReservationResponse) GetCurrentStock() int32 {
+	if x != nil {
+		return x.CurrentStock
+	}
+	return 0
+}
+
+// 批量预占库存
+type BatchReserveStockRequest struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Items                []*StockItem           `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`                                                              // 同一图书出现多次时数量合并
+	OrderId              uint64                 `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`                                          // 订单ID（幂等键，与ReserveStock共用预占记录）
+	TtlSeconds           int32                  `protobuf:"varint,3,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`                                 // 预占有效期（秒），0使用服务端默认值
+	PreferredWarehouseId uint64                 `protobuf:"varint,4,opt,name=preferred_warehouse_id,json=preferredWarehouseId,proto3" json:"preferred_warehouse_id,omitempty"` // 优先发货仓库（0为不指定）
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *BatchReserveStockRequest) Reset() {
+	*x = BatchReserveStockRequest{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchReserveStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchReserveStockRequest) ProtoMessage() {}
+
+func (x *BatchReserveStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchReserveStockRequest.ProtoReflect.Descriptor instead.
+func (*BatchReserveStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *BatchReserveStockRequest) GetItems() []*StockItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *BatchReserveStockRequest) GetOrderId() uint64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *BatchReserveStockRequest) GetTtlSeconds() int32 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *BatchReserveStockRequest) GetPreferredWarehouseId() uint64 {
+	if x != nil {
+		return x.PreferredWarehouseId
+	}
+	return 0
+}
+
+type BatchReserveStockResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Code               uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0成功（含重复预占），40001参数错误，40100库存不足
+	Message            string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	InsufficientBookId uint64                 `protobuf:"varint,3,opt,name=insufficient_book_id,json=insufficientBookId,proto3" json:"insufficient_book_id,omitempty"` // 库存不足的图书ID（code=40100时）
+	Stocks             []*StockInfo           `protobuf:"bytes,4,rep,name=stocks,proto3" json:"stocks,omitempty"`                                                      // 预占后各图书的可用库存
+	ExpiresAt          int64                  `protobuf:"varint,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`                              // 本次预占的过期时间（Unix秒，全部为重复预占时为0）
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *BatchReserveStockResponse) Reset() {
+	*x = BatchReserveStockResponse{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchReserveStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchReserveStockResponse) ProtoMessage() {}
+
+func (x *BatchReserveStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchReserveStockResponse.ProtoReflect.Descriptor instead.
+func (*BatchReserveStockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *BatchReserveStockResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *BatchReserveStockResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *BatchReserveStockResponse) GetInsufficientBookId() uint64 {
+	if x != nil {
+		return x.InsufficientBookId
+	}
+	return 0
+}
+
+func (x *BatchReserveStockResponse) GetStocks() []*StockInfo {
+	if x != nil {
+		return x.Stocks
+	}
+	return nil
+}
+
+func (x *BatchReserveStockResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+// 补充库存
+type RestockInventoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookId        uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`                          // 补充数量
+	WarehouseId   uint64                 `protobuf:"varint,3,opt,name=warehouse_id,json=warehouseId,proto3" json:"warehouse_id,omitempty"` // 入库仓库（0为默认仓库）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestockInventoryRequest) Reset() {
+	*x = RestockInventoryRequest{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestockInventoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestockInventoryRequest) ProtoMessage() {}
+
+func (x *RestockInventoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestockInventoryRequest.ProtoReflect.Descriptor instead.
+func (*RestockInventoryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *RestockInventoryRequest) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *RestockInventoryRequest) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *RestockInventoryRequest) GetWarehouseId() uint64 {
+	if x != nil {
+		return x.WarehouseId
+	}
+	return 0
+}
+
+type RestockInventoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	CurrentStock  int32                  `protobuf:"varint,3,opt,name=current_stock,json=currentStock,proto3" json:"current_stock,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestockInventoryResponse) Reset() {
+	*x = RestockInventoryResponse{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestockInventoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestockInventoryResponse) ProtoMessage() {}
+
+func (x *RestockInventoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestockInventoryResponse.ProtoReflect.Descriptor instead.
+func (*RestockInventoryResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *RestockInventoryResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *RestockInventoryResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *RestockInventoryResponse) GetCurrentStock() int32 {
+	if x != nil {
+		return x.CurrentStock
+	}
+	return 0
+}
+
+// 仓库间调拨
+type TransferStockRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	BookId          uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	FromWarehouseId uint64                 `protobuf:"varint,2,opt,name=from_warehouse_id,json=fromWarehouseId,proto3" json:"from_warehouse_id,omitempty"` // 调出仓库（0为未分配库存：把分仓前的存量分配到仓库）
+	ToWarehouseId   uint64                 `protobuf:"varint,3,opt,name=to_warehouse_id,json=toWarehouseId,proto3" json:"to_warehouse_id,omitempty"`       // 调入仓库
+	Quantity        int32                  `protobuf:"varint,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Remark          string                 `protobuf:"bytes,5,opt,name=remark,proto3" json:"remark,omitempty"` // 调拨备注（如调拨单号）
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TransferStockRequest) Reset() {
+	*x = TransferStockRequest{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransferStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferStockRequest) ProtoMessage() {}
+
+func (x *TransferStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferStockRequest.ProtoReflect.Descriptor instead.
+func (*TransferStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *TransferStockRequest) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *TransferStockRequest) GetFromWarehouseId() uint64 {
+	if x != nil {
+		return x.FromWarehouseId
+	}
+	return 0
+}
+
+func (x *TransferStockRequest) GetToWarehouseId() uint64 {
+	if x != nil {
+		return x.ToWarehouseId
+	}
+	return 0
+}
+
+func (x *TransferStockRequest) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *TransferStockRequest) GetRemark() string {
+	if x != nil {
+		return x.Remark
+	}
+	return ""
+}
+
+type TransferStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0成功，40001参数错误，40103调出仓库库存不足
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Warehouses    []*WarehouseStock      `protobuf:"bytes,3,rep,name=warehouses,proto3" json:"warehouses,omitempty"` // 调拨后各仓库库存
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransferStockResponse) Reset() {
+	*x = TransferStockResponse{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransferStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferStockResponse) ProtoMessage() {}
+
+func (x *TransferStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferStockResponse.ProtoReflect.Descriptor instead.
+func (*TransferStockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *TransferStockResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *TransferStockResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *TransferStockResponse) GetWarehouses() []*WarehouseStock {
+	if x != nil {
+		return x.Warehouses
+	}
+	return nil
+}
+
+// 库存调整
+type AdjustStockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookId        uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`                          // 调整数量（正数增加，负数减少）
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`                               // 调整原因：DAMAGE报损，LOSS丢失，STOCKTAKE盘点差异，RETURN退货入库
+	WarehouseId   uint64                 `protobuf:"varint,4,opt,name=warehouse_id,json=warehouseId,proto3" json:"warehouse_id,omitempty"` // 调整仓库（0：增加计入默认仓库，减少按兜底顺序扣减）
+	Remark        string                 `protobuf:"bytes,5,opt,name=remark,proto3" json:"remark,omitempty"`                               // 备注（如报损单号）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdjustStockRequest) Reset() {
+	*x = AdjustStockRequest{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdjustStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdjustStockRequest) ProtoMessage() {}
+
+func (x *AdjustStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdjustStockRequest.ProtoReflect.Descriptor instead.
+func (*AdjustStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *AdjustStockRequest) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *AdjustStockRequest) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *AdjustStockRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *AdjustStockRequest) GetWarehouseId() uint64 {
+	if x != nil {
+		return x.WarehouseId
+	}
+	return 0
+}
+
+func (x *AdjustStockRequest) GetRemark() string {
+	if x != nil {
+		return x.Remark
+	}
+	return ""
+}
+
+type AdjustStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0成功，40001参数错误，40100可用库存不足，40103仓库库存不足
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	BeforeStock   int32                  `protobuf:"varint,3,opt,name=before_stock,json=beforeStock,proto3" json:"before_stock,omitempty"`    // 调整前可用库存
+	CurrentStock  int32                  `protobuf:"varint,4,opt,name=current_stock,json=currentStock,proto3" json:"current_stock,omitempty"` // 调整后可用库存
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdjustStockResponse) Reset() {
+	*x = AdjustStockResponse{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdjustStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdjustStockResponse) ProtoMessage() {}
+
+func (x *AdjustStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdjustStockResponse.ProtoReflect.Descriptor instead.
+func (*AdjustStockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *AdjustStockResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *AdjustStockResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *AdjustStockResponse) GetBeforeStock() int32 {
+	if x != nil {
+		return x.BeforeStock
+	}
+	return 0
+}
+
+func (x *AdjustStockResponse) GetCurrentStock() int32 {
+	if x != nil {
+		return x.CurrentStock
+	}
+	return 0
+}
+
+// 盘点导入
+type ImportStocktakeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*StocktakeItem       `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	WarehouseId   uint64                 `protobuf:"varint,2,opt,name=warehouse_id,json=warehouseId,proto3" json:"warehouse_id,omitempty"` // 差异计入的仓库（0同AdjustStock）
+	Remark        string                 `protobuf:"bytes,3,opt,name=remark,proto3" json:"remark,omitempty"`                               // 备注（如盘点单号）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportStocktakeRequest) Reset() {
+	*x = ImportStocktakeRequest{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportStocktakeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportStocktakeRequest) ProtoMessage() {}
+
+func (x *ImportStocktakeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportStocktakeRequest.ProtoReflect.Descriptor instead.
+func (*ImportStocktakeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ImportStocktakeRequest) GetItems() []*StocktakeItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ImportStocktakeRequest) GetWarehouseId() uint64 {
+	if x != nil {
+		return x.WarehouseId
+	}
+	return 0
+}
+
+func (x *ImportStocktakeRequest) GetRemark() string {
+	if x != nil {
+		return x.Remark
+	}
+	return ""
+}
+
+// 盘点明细
+type StocktakeItem struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	BookId          uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	CountedQuantity int32                  `protobuf:"varint,2,opt,name=counted_quantity,json=countedQuantity,proto3" json:"counted_quantity,omitempty"` // 实盘数量（含已锁定未发货的库存）
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *StocktakeItem) Reset() {
+	*x = StocktakeItem{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StocktakeItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StocktakeItem) ProtoMessage() {}
+
+func (x *StocktakeItem) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StocktakeItem.ProtoReflect.Descriptor instead.
+func (*StocktakeItem) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *StocktakeItem) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *StocktakeItem) GetCountedQuantity() int32 {
+	if x != nil {
+		return x.CountedQuantity
+	}
+	return 0
+}
+
+type ImportStocktakeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0成功（逐本结果见results），40001参数错误
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Results       []*StocktakeResult     `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`
+	Adjusted      uint32                 `protobuf:"varint,4,opt,name=adjusted,proto3" json:"adjusted,omitempty"` // 有差异并已调整的图书数
+	Failed        uint32                 `protobuf:"varint,5,opt,name=failed,proto3" json:"failed,omitempty"`     // 调整失败的图书数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportStocktakeResponse) Reset() {
+	*x = ImportStocktakeResponse{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportStocktakeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportStocktakeResponse) ProtoMessage() {}
+
+func (x *ImportStocktakeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportStocktakeResponse.ProtoReflect.Descriptor instead.
+func (*ImportStocktakeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ImportStocktakeResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ImportStocktakeResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ImportStocktakeResponse) GetResults() []*StocktakeResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *ImportStocktakeResponse) GetAdjusted() uint32 {
+	if x != nil {
+		return x.Adjusted
+	}
+	return 0
+}
+
+func (x *ImportStocktakeResponse) GetFailed() uint32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+// 盘点结果
+type StocktakeResult struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	BookId          uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	CountedQuantity int32                  `protobuf:"varint,2,opt,name=counted_quantity,json=countedQuantity,proto3" json:"counted_quantity,omitempty"`
+	Difference      int32                  `protobuf:"varint,3,opt,name=difference,proto3" json:"difference,omitempty"`                         // 差异（实盘数量 - 账面数量，账面数量 = 可用 + 锁定）
+	BeforeStock     int32                  `protobuf:"varint,4,opt,name=before_stock,json=beforeStock,proto3" json:"before_stock,omitempty"`    // 调整前可用库存
+	CurrentStock    int32                  `protobuf:"varint,5,opt,name=current_stock,json=currentStock,proto3" json:"current_stock,omitempty"` // 调整后可用库存
+	Code            uint32                 `protobuf:"varint,6,opt,name=code,proto3" json:"code,omitempty"`                                     // 0已调整或无差异，40100差异大于可用库存，40103仓库库存不足
+	Message         string                 `protobuf:"bytes,7,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *StocktakeResult) Reset() {
+	*x = StocktakeResult{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StocktakeResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StocktakeResult) ProtoMessage() {}
+
+func (x *StocktakeResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StocktakeResult.ProtoReflect.Descriptor instead.
+func (*StocktakeResult) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *StocktakeResult) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *StocktakeResult) GetCountedQuantity() int32 {
+	if x != nil {
+		return x.CountedQuantity
+	}
+	return 0
+}
+
+func (x *StocktakeResult) GetDifference() int32 {
+	if x != nil {
+		return x.Difference
+	}
+	return 0
+}
+
+func (x *StocktakeResult) GetBeforeStock() int32 {
+	if x != nil {
+		return x.BeforeStock
+	}
+	return 0
+}
+
+func (x *StocktakeResult) GetCurrentStock() int32 {
+	if x != nil {
+		return x.CurrentStock
+	}
+	return 0
+}
+
+func (x *StocktakeResult) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *StocktakeResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// 获取库存变更日志
+type GetInventoryLogsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookId        uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	Page          uint32                 `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      uint32                 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	OrderId       uint64                 `protobuf:"varint,4,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"` // 按订单查询（可选，与book_id同时指定时只返回该图书的流水）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInventoryLogsRequest) Reset() {
+	*x = GetInventoryLogsRequest{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInventoryLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInventoryLogsRequest) ProtoMessage() {}
+
+func (x *GetInventoryLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInventoryLogsRequest.ProtoReflect.Descriptor instead.
+func (*GetInventoryLogsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *GetInventoryLogsRequest) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *GetInventoryLogsRequest) GetPage() uint32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *GetInventoryLogsRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *GetInventoryLogsRequest) GetOrderId() uint64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+type GetInventoryLogsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Logs          []*InventoryLog        `protobuf:"bytes,3,rep,name=logs,proto3" json:"logs,omitempty"`
+	Total         uint32                 `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInventoryLogsResponse) Reset() {
+	*x = GetInventoryLogsResponse{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInventoryLogsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInventoryLogsResponse) ProtoMessage() {}
+
+func (x *GetInventoryLogsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInventoryLogsResponse.ProtoReflect.Descriptor instead.
+func (*GetInventoryLogsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *GetInventoryLogsResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *GetInventoryLogsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *GetInventoryLogsResponse) GetLogs() []*InventoryLog {
+	if x != nil {
+		return x.Logs
+	}
+	return nil
+}
+
+func (x *GetInventoryLogsResponse) GetTotal() uint32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// 设置低库存阈值
+type SetLowStockThresholdRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookId        uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	Threshold     int32                  `protobuf:"varint,2,opt,name=threshold,proto3" json:"threshold,omitempty"`                     // 低库存阈值（>=0）
+	UseDefault    bool                   `protobuf:"varint,3,opt,name=use_default,json=useDefault,proto3" json:"use_default,omitempty"` // true时清除单独设置，恢复默认阈值（忽略threshold）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetLowStockThresholdRequest) Reset() {
+	*x = SetLowStockThresholdRequest{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLowStockThresholdRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLowStockThresholdRequest) ProtoMessage() {}
+
+func (x *SetLowStockThresholdRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLowStockThresholdRequest.ProtoReflect.Descriptor instead.
+func (*SetLowStockThresholdRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *SetLowStockThresholdRequest) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *SetLowStockThresholdRequest) GetThreshold() int32 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *SetLowStockThresholdRequest) GetUseDefault() bool {
+	if x != nil {
+		return x.UseDefault
+	}
+	return false
+}
+
+type SetLowStockThresholdResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0成功，40001参数错误，40401库存记录不存在
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetLowStockThresholdResponse) Reset() {
+	*x = SetLowStockThresholdResponse{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLowStockThresholdResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLowStockThresholdResponse) ProtoMessage() {}
+
+func (x *SetLowStockThresholdResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLowStockThresholdResponse.ProtoReflect.Descriptor instead.
+func (*SetLowStockThresholdResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *SetLowStockThresholdResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *SetLowStockThresholdResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// 查询低库存图书
+type ListLowStockRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Page              uint32                 `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize          uint32                 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	IncludeOutOfStock bool                   `protobuf:"varint,3,opt,name=include_out_of_stock,json=includeOutOfStock,proto3" json:"include_out_of_stock,omitempty"` // 是否包含已缺货（库存为0）的图书
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ListLowStockRequest) Reset() {
+	*x = ListLowStockRequest{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLowStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLowStockRequest) ProtoMessage() {}
+
+func (x *ListLowStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLowStockRequest.ProtoReflect.Descriptor instead.
+func (*ListLowStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ListLowStockRequest) GetPage() uint32 {
 	if x != nil {
 		return x.Page
 	}
 	return 0
 }
 
-func (x *GetInventoryLogsRequest) GetPageSize() uint32 {
+func (x *ListLowStockRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListLowStockRequest) GetIncludeOutOfStock() bool {
+	if x != nil {
+		return x.IncludeOutOfStock
+	}
+	return false
+}
+
+type ListLowStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Items         []*LowStockItem        `protobuf:"bytes,3,rep,name=items,proto3" json:"items,omitempty"` // 按可用库存升序
+	Total         uint32                 `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLowStockResponse) Reset() {
+	*x = ListLowStockResponse{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLowStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLowStockResponse) ProtoMessage() {}
+
+func (x *ListLowStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLowStockResponse.ProtoReflect.Descriptor instead.
+func (*ListLowStockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *ListLowStockResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ListLowStockResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ListLowStockResponse) GetItems() []*LowStockItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ListLowStockResponse) GetTotal() uint32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// 低库存图书
+type LowStockItem struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	BookId           uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	Stock            int32                  `protobuf:"varint,2,opt,name=stock,proto3" json:"stock,omitempty"`                                               // 可用库存
+	LockedStock      int32                  `protobuf:"varint,3,opt,name=locked_stock,json=lockedStock,proto3" json:"locked_stock,omitempty"`                // 锁定库存（待支付订单）
+	Threshold        int32                  `protobuf:"varint,4,opt,name=threshold,proto3" json:"threshold,omitempty"`                                       // 生效的低库存阈值
+	DefaultThreshold bool                   `protobuf:"varint,5,opt,name=default_threshold,json=defaultThreshold,proto3" json:"default_threshold,omitempty"` // 是否使用默认阈值
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *LowStockItem) Reset() {
+	*x = LowStockItem{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LowStockItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LowStockItem) ProtoMessage() {}
+
+func (x *LowStockItem) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LowStockItem.ProtoReflect.Descriptor instead.
+func (*LowStockItem) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *LowStockItem) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *LowStockItem) GetStock() int32 {
+	if x != nil {
+		return x.Stock
+	}
+	return 0
+}
+
+func (x *LowStockItem) GetLockedStock() int32 {
+	if x != nil {
+		return x.LockedStock
+	}
+	return 0
+}
+
+func (x *LowStockItem) GetThreshold() int32 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *LowStockItem) GetDefaultThreshold() bool {
+	if x != nil {
+		return x.DefaultThreshold
+	}
+	return false
+}
+
+// 订阅到货提醒
+type SubscribeBackInStockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	BookId        uint64                 `protobuf:"varint,2,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeBackInStockRequest) Reset() {
+	*x = SubscribeBackInStockRequest{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeBackInStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeBackInStockRequest) ProtoMessage() {}
+
+func (x *SubscribeBackInStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeBackInStockRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeBackInStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *SubscribeBackInStockRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *SubscribeBackInStockRequest) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+type SubscribeBackInStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0成功，40001参数错误，40401库存记录不存在
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	CurrentStock  int32                  `protobuf:"varint,3,opt,name=current_stock,json=currentStock,proto3" json:"current_stock,omitempty"` // 当前可用库存（有货时也会订阅，下次从缺货到有货时通知）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeBackInStockResponse) Reset() {
+	*x = SubscribeBackInStockResponse{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeBackInStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeBackInStockResponse) ProtoMessage() {}
+
+func (x *SubscribeBackInStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeBackInStockResponse.ProtoReflect.Descriptor instead.
+func (*SubscribeBackInStockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *SubscribeBackInStockResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *SubscribeBackInStockResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SubscribeBackInStockResponse) GetCurrentStock() int32 {
+	if x != nil {
+		return x.CurrentStock
+	}
+	return 0
+}
+
+// 取消到货提醒
+type UnsubscribeBackInStockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	BookId        uint64                 `protobuf:"varint,2,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnsubscribeBackInStockRequest) Reset() {
+	*x = UnsubscribeBackInStockRequest{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnsubscribeBackInStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnsubscribeBackInStockRequest) ProtoMessage() {}
+
+func (x *UnsubscribeBackInStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnsubscribeBackInStockRequest.ProtoReflect.Descriptor instead.
+func (*UnsubscribeBackInStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *UnsubscribeBackInStockRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *UnsubscribeBackInStockRequest) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+type UnsubscribeBackInStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0成功，40001参数错误，40402未订阅
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnsubscribeBackInStockResponse) Reset() {
+	*x = UnsubscribeBackInStockResponse{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnsubscribeBackInStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnsubscribeBackInStockResponse) ProtoMessage() {}
+
+func (x *UnsubscribeBackInStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnsubscribeBackInStockResponse.ProtoReflect.Descriptor instead.
+func (*UnsubscribeBackInStockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *UnsubscribeBackInStockResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *UnsubscribeBackInStockResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// 库存对账
+type ReconcileStockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookIds       []uint64               `protobuf:"varint,1,rep,packed,name=book_ids,json=bookIds,proto3" json:"book_ids,omitempty"` // 对账的图书ID（为空时全量对账）
+	Policy        string                 `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`                          // 修复策略：report_only（默认）, auto, mysql_to_redis, redis_to_mysql
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReconcileStockRequest) Reset() {
+	*x = ReconcileStockRequest{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconcileStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconcileStockRequest) ProtoMessage() {}
+
+func (x *ReconcileStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconcileStockRequest.ProtoReflect.Descriptor instead.
+func (*ReconcileStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *ReconcileStockRequest) GetBookIds() []uint64 {
+	if x != nil {
+		return x.BookIds
+	}
+	return nil
+}
+
+func (x *ReconcileStockRequest) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+type ReconcileStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Checked       uint32                 `protobuf:"varint,3,opt,name=checked,proto3" json:"checked,omitempty"`   // 检查的图书数
+	Repaired      uint32                 `protobuf:"varint,4,opt,name=repaired,proto3" json:"repaired,omitempty"` // 修复的图书数
+	Drifts        []*StockDrift          `protobuf:"bytes,5,rep,name=drifts,proto3" json:"drifts,omitempty"`      // 不一致的图书（含账面不一致）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReconcileStockResponse) Reset() {
+	*x = ReconcileStockResponse{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconcileStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconcileStockResponse) ProtoMessage() {}
+
+func (x *ReconcileStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconcileStockResponse.ProtoReflect.Descriptor instead.
+func (*ReconcileStockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *ReconcileStockResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ReconcileStockResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ReconcileStockResponse) GetChecked() uint32 {
+	if x != nil {
+		return x.Checked
+	}
+	return 0
+}
+
+func (x *ReconcileStockResponse) GetRepaired() uint32 {
+	if x != nil {
+		return x.Repaired
+	}
+	return 0
+}
+
+func (x *ReconcileStockResponse) GetDrifts() []*StockDrift {
+	if x != nil {
+		return x.Drifts
+	}
+	return nil
+}
+
+// 库存不一致明细
+type StockDrift struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookId        uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	RedisStock    int32                  `protobuf:"varint,2,opt,name=redis_stock,json=redisStock,proto3" json:"redis_stock,omitempty"`
+	RedisLocked   int32                  `protobuf:"varint,3,opt,name=redis_locked,json=redisLocked,proto3" json:"redis_locked,omitempty"`
+	RedisMissing  bool                   `protobuf:"varint,4,opt,name=redis_missing,json=redisMissing,proto3" json:"redis_missing,omitempty"` // Redis中没有库存key
+	MysqlStock    int32                  `protobuf:"varint,5,opt,name=mysql_stock,json=mysqlStock,proto3" json:"mysql_stock,omitempty"`
+	MysqlLocked   int32                  `protobuf:"varint,6,opt,name=mysql_locked,json=mysqlLocked,proto3" json:"mysql_locked,omitempty"`
+	MysqlMissing  bool                   `protobuf:"varint,7,opt,name=mysql_missing,json=mysqlMissing,proto3" json:"mysql_missing,omitempty"` // MySQL中没有库存记录
+	LedgerStock   int32                  `protobuf:"varint,8,opt,name=ledger_stock,json=ledgerStock,proto3" json:"ledger_stock,omitempty"`    // 库存日志最后一条的变更后库存
+	HasLedger     bool                   `protobuf:"varint,9,opt,name=has_ledger,json=hasLedger,proto3" json:"has_ledger,omitempty"`
+	Action        string                 `protobuf:"bytes,10,opt,name=action,proto3" json:"action,omitempty"` // 修复动作：redis_repaired, mysql_repaired, changed, mysql_missing（空为未修复）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StockDrift) Reset() {
+	*x = StockDrift{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StockDrift) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StockDrift) ProtoMessage() {}
+
+func (x *StockDrift) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StockDrift.ProtoReflect.Descriptor instead.
+func (*StockDrift) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *StockDrift) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *StockDrift) GetRedisStock() int32 {
+	if x != nil {
+		return x.RedisStock
+	}
+	return 0
+}
+
+func (x *StockDrift) GetRedisLocked() int32 {
+	if x != nil {
+		return x.RedisLocked
+	}
+	return 0
+}
+
+func (x *StockDrift) GetRedisMissing() bool {
+	if x != nil {
+		return x.RedisMissing
+	}
+	return false
+}
+
+func (x *StockDrift) GetMysqlStock() int32 {
+	if x != nil {
+		return x.MysqlStock
+	}
+	return 0
+}
+
+func (x *StockDrift) GetMysqlLocked() int32 {
+	if x != nil {
+		return x.MysqlLocked
+	}
+	return 0
+}
+
+func (x *StockDrift) GetMysqlMissing() bool {
+	if x != nil {
+		return x.MysqlMissing
+	}
+	return false
+}
+
+func (x *StockDrift) GetLedgerStock() int32 {
+	if x != nil {
+		return x.LedgerStock
+	}
+	return 0
+}
+
+func (x *StockDrift) GetHasLedger() bool {
+	if x != nil {
+		return x.HasLedger
+	}
+	return false
+}
+
+func (x *StockDrift) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+// 日志重放
+type ReplayLedgerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookIds       []uint64               `protobuf:"varint,1,rep,packed,name=book_ids,json=bookIds,proto3" json:"book_ids,omitempty"`        // 重放的图书ID（为空时按after_book_id游标遍历全部图书）
+	AsOf          int64                  `protobuf:"varint,2,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`                        // 截止时间（Unix秒，0为当前时间）
+	AfterBookId   uint64                 `protobuf:"varint,3,opt,name=after_book_id,json=afterBookId,proto3" json:"after_book_id,omitempty"` // 遍历游标（上一页的next_book_id）
+	Limit         uint32                 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`                                  // 遍历时每页图书数（默认20，最大100）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayLedgerRequest) Reset() {
+	*x = ReplayLedgerRequest{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayLedgerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayLedgerRequest) ProtoMessage() {}
+
+func (x *ReplayLedgerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayLedgerRequest.ProtoReflect.Descriptor instead.
+func (*ReplayLedgerRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *ReplayLedgerRequest) GetBookIds() []uint64 {
+	if x != nil {
+		return x.BookIds
+	}
+	return nil
+}
+
+func (x *ReplayLedgerRequest) GetAsOf() int64 {
+	if x != nil {
+		return x.AsOf
+	}
+	return 0
+}
+
+func (x *ReplayLedgerRequest) GetAfterBookId() uint64 {
 	if x != nil {
-		return x.PageSize
+		return x.AfterBookId
 	}
 	return 0
 }
 
-type GetInventoryLogsResponse struct {
+func (x *ReplayLedgerRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ReplayLedgerResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Logs          []*InventoryLog        `protobuf:"bytes,3,rep,name=logs,proto3" json:"logs,omitempty"`
-	Total         uint32                 `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	Books         []*LedgerReplay        `protobuf:"bytes,3,rep,name=books,proto3" json:"books,omitempty"`
+	NextBookId    uint64                 `protobuf:"varint,4,opt,name=next_book_id,json=nextBookId,proto3" json:"next_book_id,omitempty"` // 遍历的下一页游标（0表示已遍历完或指定了book_ids）
+	Inconsistent  uint32                 `protobuf:"varint,5,opt,name=inconsistent,proto3" json:"inconsistent,omitempty"`                 // 日志链不完整的图书数
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetInventoryLogsResponse) Reset() {
-	*x = GetInventoryLogsResponse{}
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[12]
+func (x *ReplayLedgerResponse) Reset() {
+	*x = ReplayLedgerResponse{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetInventoryLogsResponse) String() string {
+func (x *ReplayLedgerResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetInventoryLogsResponse) ProtoMessage() {}
+func (*ReplayLedgerResponse) ProtoMessage() {}
 
-func (x *GetInventoryLogsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[12]
+func (x *ReplayLedgerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -751,35 +3263,260 @@ func (x *GetInventoryLogsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetInventoryLogsResponse.ProtoReflect.Descriptor instead.
-func (*GetInventoryLogsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use ReplayLedgerResponse.ProtoReflect.Descriptor instead.
+func (*ReplayLedgerResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{50}
 }
 
-func (x *GetInventoryLogsResponse) GetCode() uint32 {
+func (x *ReplayLedgerResponse) GetCode() uint32 {
 	if x != nil {
 		return x.Code
 	}
 	return 0
 }
 
-func (x *GetInventoryLogsResponse) GetMessage() string {
+func (x *ReplayLedgerResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *GetInventoryLogsResponse) GetLogs() []*InventoryLog {
+func (x *ReplayLedgerResponse) GetBooks() []*LedgerReplay {
 	if x != nil {
-		return x.Logs
+		return x.Books
 	}
 	return nil
 }
 
-func (x *GetInventoryLogsResponse) GetTotal() uint32 {
+func (x *ReplayLedgerResponse) GetNextBookId() uint64 {
 	if x != nil {
-		return x.Total
+		return x.NextBookId
+	}
+	return 0
+}
+
+func (x *ReplayLedgerResponse) GetInconsistent() uint32 {
+	if x != nil {
+		return x.Inconsistent
+	}
+	return 0
+}
+
+// 单本图书的重放结果
+type LedgerReplay struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookId        uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	OpeningStock  int32                  `protobuf:"varint,2,opt,name=opening_stock,json=openingStock,proto3" json:"opening_stock,omitempty"`    // 期初可用库存（第一条日志的变更前库存）
+	Stock         int32                  `protobuf:"varint,3,opt,name=stock,proto3" json:"stock,omitempty"`                                      // 重放得到的可用库存（期初 + 逐条变更数量）
+	RecordedStock int32                  `protobuf:"varint,4,opt,name=recorded_stock,json=recordedStock,proto3" json:"recorded_stock,omitempty"` // 截止时间前最后一条日志记录的可用库存
+	LockedStock   int32                  `protobuf:"varint,5,opt,name=locked_stock,json=lockedStock,proto3" json:"locked_stock,omitempty"`       // 按预占/取消/确认推算的锁定库存
+	Warehouses    []*WarehouseStock      `protobuf:"bytes,6,rep,name=warehouses,proto3" json:"warehouses,omitempty"`                             // 各仓库重放得到的库存
+	LogCount      uint32                 `protobuf:"varint,7,opt,name=log_count,json=logCount,proto3" json:"log_count,omitempty"`
+	LastLogId     uint64                 `protobuf:"varint,8,opt,name=last_log_id,json=lastLogId,proto3" json:"last_log_id,omitempty"`
+	LastLogAt     int64                  `protobuf:"varint,9,opt,name=last_log_at,json=lastLogAt,proto3" json:"last_log_at,omitempty"`
+	Consistent    bool                   `protobuf:"varint,10,opt,name=consistent,proto3" json:"consistent,omitempty"`             // 日志链完整（无断点且stock == recorded_stock）
+	GapCount      uint32                 `protobuf:"varint,11,opt,name=gap_count,json=gapCount,proto3" json:"gap_count,omitempty"` // 断点总数（gaps最多返回100条）
+	Gaps          []*LedgerGap           `protobuf:"bytes,12,rep,name=gaps,proto3" json:"gaps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LedgerReplay) Reset() {
+	*x = LedgerReplay{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LedgerReplay) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LedgerReplay) ProtoMessage() {}
+
+func (x *LedgerReplay) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LedgerReplay.ProtoReflect.Descriptor instead.
+func (*LedgerReplay) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *LedgerReplay) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *LedgerReplay) GetOpeningStock() int32 {
+	if x != nil {
+		return x.OpeningStock
+	}
+	return 0
+}
+
+func (x *LedgerReplay) GetStock() int32 {
+	if x != nil {
+		return x.Stock
+	}
+	return 0
+}
+
+func (x *LedgerReplay) GetRecordedStock() int32 {
+	if x != nil {
+		return x.RecordedStock
+	}
+	return 0
+}
+
+func (x *LedgerReplay) GetLockedStock() int32 {
+	if x != nil {
+		return x.LockedStock
+	}
+	return 0
+}
+
+func (x *LedgerReplay) GetWarehouses() []*WarehouseStock {
+	if x != nil {
+		return x.Warehouses
+	}
+	return nil
+}
+
+func (x *LedgerReplay) GetLogCount() uint32 {
+	if x != nil {
+		return x.LogCount
+	}
+	return 0
+}
+
+func (x *LedgerReplay) GetLastLogId() uint64 {
+	if x != nil {
+		return x.LastLogId
+	}
+	return 0
+}
+
+func (x *LedgerReplay) GetLastLogAt() int64 {
+	if x != nil {
+		return x.LastLogAt
+	}
+	return 0
+}
+
+func (x *LedgerReplay) GetConsistent() bool {
+	if x != nil {
+		return x.Consistent
+	}
+	return false
+}
+
+func (x *LedgerReplay) GetGapCount() uint32 {
+	if x != nil {
+		return x.GapCount
+	}
+	return 0
+}
+
+func (x *LedgerReplay) GetGaps() []*LedgerGap {
+	if x != nil {
+		return x.Gaps
+	}
+	return nil
+}
+
+// 日志链断点
+type LedgerGap struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LogId         uint64                 `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	PrevLogId     uint64                 `protobuf:"varint,2,opt,name=prev_log_id,json=prevLogId,proto3" json:"prev_log_id,omitempty"`     // 同一条链上的上一条日志（数量不符时为0）
+	WarehouseId   uint64                 `protobuf:"varint,3,opt,name=warehouse_id,json=warehouseId,proto3" json:"warehouse_id,omitempty"` // 0为可售库存链
+	Kind          string                 `protobuf:"bytes,4,opt,name=kind,proto3" json:"kind,omitempty"`                                   // CHAIN_BREAK：变更前库存与上一条变更后库存不符；QUANTITY_MISMATCH：前后差与变更数量不符
+	Expected      int32                  `protobuf:"varint,5,opt,name=expected,proto3" json:"expected,omitempty"`
+	Actual        int32                  `protobuf:"varint,6,opt,name=actual,proto3" json:"actual,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LedgerGap) Reset() {
+	*x = LedgerGap{}
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LedgerGap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LedgerGap) ProtoMessage() {}
+
+func (x *LedgerGap) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LedgerGap.ProtoReflect.Descriptor instead.
+func (*LedgerGap) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *LedgerGap) GetLogId() uint64 {
+	if x != nil {
+		return x.LogId
+	}
+	return 0
+}
+
+func (x *LedgerGap) GetPrevLogId() uint64 {
+	if x != nil {
+		return x.PrevLogId
+	}
+	return 0
+}
+
+func (x *LedgerGap) GetWarehouseId() uint64 {
+	if x != nil {
+		return x.WarehouseId
+	}
+	return 0
+}
+
+func (x *LedgerGap) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *LedgerGap) GetExpected() int32 {
+	if x != nil {
+		return x.Expected
+	}
+	return 0
+}
+
+func (x *LedgerGap) GetActual() int32 {
+	if x != nil {
+		return x.Actual
 	}
 	return 0
 }
@@ -789,19 +3526,20 @@ type InventoryLog struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	BookId        uint64                 `protobuf:"varint,2,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
-	ChangeType    string                 `protobuf:"bytes,3,opt,name=change_type,json=changeType,proto3" json:"change_type,omitempty"`     // DEDUCT, RELEASE, RESTOCK
+	ChangeType    string                 `protobuf:"bytes,3,opt,name=change_type,json=changeType,proto3" json:"change_type,omitempty"`     // DEDUCT, RELEASE, RESTOCK, LOCK, UNLOCK, RECONCILE, TRANSFER
 	Quantity      int32                  `protobuf:"varint,4,opt,name=quantity,proto3" json:"quantity,omitempty"`                          // 变更数量（正数为增加，负数为减少）
 	BeforeStock   int32                  `protobuf:"varint,5,opt,name=before_stock,json=beforeStock,proto3" json:"before_stock,omitempty"` // 变更前库存
 	AfterStock    int32                  `protobuf:"varint,6,opt,name=after_stock,json=afterStock,proto3" json:"after_stock,omitempty"`    // 变更后库存
 	OrderId       uint64                 `protobuf:"varint,7,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`             // 关联订单ID（可选）
 	CreatedAt     int64                  `protobuf:"varint,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	WarehouseId   uint64                 `protobuf:"varint,9,opt,name=warehouse_id,json=warehouseId,proto3" json:"warehouse_id,omitempty"` // 仓库ID（0为可售库存合计，>0为该仓库库存的变更）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *InventoryLog) Reset() {
 	*x = InventoryLog{}
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[13]
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -813,7 +3551,7 @@ func (x *InventoryLog) String() string {
 func (*InventoryLog) ProtoMessage() {}
 
 func (x *InventoryLog) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[13]
+	mi := &file_proto_inventory_v1_inventory_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -826,7 +3564,7 @@ func (x *InventoryLog) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InventoryLog.ProtoReflect.Descriptor instead.
 func (*InventoryLog) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{13}
+	return file_proto_inventory_v1_inventory_proto_rawDescGZIP(), []int{53}
 }
 
 func (x *InventoryLog) GetId() uint64 {
@@ -885,18 +3623,39 @@ func (x *InventoryLog) GetCreatedAt() int64 {
 	return 0
 }
 
+func (x *InventoryLog) GetWarehouseId() uint64 {
+	if x != nil {
+		return x.WarehouseId
+	}
+	return 0
+}
+
 var File_proto_inventory_v1_inventory_proto protoreflect.FileDescriptor
 
 const file_proto_inventory_v1_inventory_proto_rawDesc = "" +
 	"\n" +
 	"\"proto/inventory/v1/inventory.proto\x12\finventory.v1\"*\n" +
 	"\x0fGetStockRequest\x12\x17\n" +
-	"\abook_id\x18\x01 \x01(\x04R\x06bookId\"o\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\"\xad\x01\n" +
 	"\x10GetStockResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x17\n" +
 	"\abook_id\x18\x03 \x01(\x04R\x06bookId\x12\x14\n" +
-	"\x05stock\x18\x04 \x01(\x05R\x05stock\"1\n" +
+	"\x05stock\x18\x04 \x01(\x05R\x05stock\x12<\n" +
+	"\n" +
+	"warehouses\x18\x05 \x03(\v2\x1c.inventory.v1.WarehouseStockR\n" +
+	"warehouses\"I\n" +
+	"\x11WatchStockRequest\x12\x19\n" +
+	"\bbook_ids\x18\x01 \x03(\x04R\abookIds\x12\x19\n" +
+	"\bfrom_seq\x18\x02 \x01(\x04R\afromSeq\"N\n" +
+	"\vStockChange\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12\x17\n" +
+	"\abook_id\x18\x02 \x01(\x04R\x06bookId\x12\x14\n" +
+	"\x05stock\x18\x03 \x01(\x05R\x05stock\"]\n" +
+	"\x0eWarehouseStock\x12!\n" +
+	"\fwarehouse_id\x18\x01 \x01(\x04R\vwarehouseId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05stock\x18\x03 \x01(\x05R\x05stock\"1\n" +
 	"\x14BatchGetStockRequest\x12\x19\n" +
 	"\bbook_ids\x18\x01 \x03(\x04R\abookIds\"v\n" +
 	"\x15BatchGetStockResponse\x12\x12\n" +
@@ -905,11 +3664,12 @@ const file_proto_inventory_v1_inventory_proto_rawDesc = "" +
 	"\x06stocks\x18\x03 \x03(\v2\x17.inventory.v1.StockInfoR\x06stocks\":\n" +
 	"\tStockInfo\x12\x17\n" +
 	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x14\n" +
-	"\x05stock\x18\x02 \x01(\x05R\x05stock\"d\n" +
+	"\x05stock\x18\x02 \x01(\x05R\x05stock\"\x9a\x01\n" +
 	"\x12DeductStockRequest\x12\x17\n" +
 	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x1a\n" +
 	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12\x19\n" +
-	"\border_id\x18\x03 \x01(\x04R\aorderId\"l\n" +
+	"\border_id\x18\x03 \x01(\x04R\aorderId\x124\n" +
+	"\x16preferred_warehouse_id\x18\x04 \x01(\x04R\x14preferredWarehouseId\"l\n" +
 	"\x13DeductStockResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12'\n" +
@@ -922,23 +3682,229 @@ const file_proto_inventory_v1_inventory_proto_rawDesc = "" +
 	"\x14ReleaseStockResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12#\n" +
-	"\rcurrent_stock\x18\x03 \x01(\x05R\fcurrentStock\"N\n" +
+	"\rcurrent_stock\x18\x03 \x01(\x05R\fcurrentStock\"@\n" +
+	"\tStockItem\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"\x99\x01\n" +
+	"\x17BatchDeductStockRequest\x12-\n" +
+	"\x05items\x18\x01 \x03(\v2\x17.inventory.v1.StockItemR\x05items\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\x04R\aorderId\x124\n" +
+	"\x16preferred_warehouse_id\x18\x03 \x01(\x04R\x14preferredWarehouseId\"\xab\x01\n" +
+	"\x18BatchDeductStockResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x120\n" +
+	"\x14insufficient_book_id\x18\x03 \x01(\x04R\x12insufficientBookId\x12/\n" +
+	"\x06stocks\x18\x04 \x03(\v2\x17.inventory.v1.StockInfoR\x06stocks\"|\n" +
+	"\x18BatchReleaseStockRequest\x12-\n" +
+	"\x05items\x18\x01 \x03(\v2\x17.inventory.v1.StockItemR\x05items\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\x04R\aorderId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"z\n" +
+	"\x19BatchReleaseStockResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12/\n" +
+	"\x06stocks\x18\x03 \x03(\v2\x17.inventory.v1.StockInfoR\x06stocks\"\xbc\x01\n" +
+	"\x13ReserveStockRequest\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12\x19\n" +
+	"\border_id\x18\x03 \x01(\x04R\aorderId\x12\x1f\n" +
+	"\vttl_seconds\x18\x04 \x01(\x05R\n" +
+	"ttlSeconds\x124\n" +
+	"\x16preferred_warehouse_id\x18\x05 \x01(\x04R\x14preferredWarehouseId\"\x8c\x01\n" +
+	"\x14ReserveStockResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12'\n" +
+	"\x0fremaining_stock\x18\x03 \x01(\x05R\x0eremainingStock\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\x03R\texpiresAt\"O\n" +
+	"\x19ConfirmReservationRequest\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\x04R\aorderId\"J\n" +
+	"\x1aConfirmReservationResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"f\n" +
+	"\x18CancelReservationRequest\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\x04R\aorderId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"n\n" +
+	"\x19CancelReservationResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12#\n" +
+	"\rcurrent_stock\x18\x03 \x01(\x05R\fcurrentStock\"\xbb\x01\n" +
+	"\x18BatchReserveStockRequest\x12-\n" +
+	"\x05items\x18\x01 \x03(\v2\x17.inventory.v1.StockItemR\x05items\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\x04R\aorderId\x12\x1f\n" +
+	"\vttl_seconds\x18\x03 \x01(\x05R\n" +
+	"ttlSeconds\x124\n" +
+	"\x16preferred_warehouse_id\x18\x04 \x01(\x04R\x14preferredWarehouseId\"\xcb\x01\n" +
+	"\x19BatchReserveStockResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x120\n" +
+	"\x14insufficient_book_id\x18\x03 \x01(\x04R\x12insufficientBookId\x12/\n" +
+	"\x06stocks\x18\x04 \x03(\v2\x17.inventory.v1.StockInfoR\x06stocks\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\x03R\texpiresAt\"q\n" +
 	"\x17RestockInventoryRequest\x12\x17\n" +
 	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x1a\n" +
-	"\bquantity\x18\x02 \x01(\x05R\bquantity\"m\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12!\n" +
+	"\fwarehouse_id\x18\x03 \x01(\x04R\vwarehouseId\"m\n" +
 	"\x18RestockInventoryResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12#\n" +
-	"\rcurrent_stock\x18\x03 \x01(\x05R\fcurrentStock\"c\n" +
+	"\rcurrent_stock\x18\x03 \x01(\x05R\fcurrentStock\"\xb7\x01\n" +
+	"\x14TransferStockRequest\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12*\n" +
+	"\x11from_warehouse_id\x18\x02 \x01(\x04R\x0ffromWarehouseId\x12&\n" +
+	"\x0fto_warehouse_id\x18\x03 \x01(\x04R\rtoWarehouseId\x12\x1a\n" +
+	"\bquantity\x18\x04 \x01(\x05R\bquantity\x12\x16\n" +
+	"\x06remark\x18\x05 \x01(\tR\x06remark\"\x83\x01\n" +
+	"\x15TransferStockResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12<\n" +
+	"\n" +
+	"warehouses\x18\x03 \x03(\v2\x1c.inventory.v1.WarehouseStockR\n" +
+	"warehouses\"\x9c\x01\n" +
+	"\x12AdjustStockRequest\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12!\n" +
+	"\fwarehouse_id\x18\x04 \x01(\x04R\vwarehouseId\x12\x16\n" +
+	"\x06remark\x18\x05 \x01(\tR\x06remark\"\x8b\x01\n" +
+	"\x13AdjustStockResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12!\n" +
+	"\fbefore_stock\x18\x03 \x01(\x05R\vbeforeStock\x12#\n" +
+	"\rcurrent_stock\x18\x04 \x01(\x05R\fcurrentStock\"\x86\x01\n" +
+	"\x16ImportStocktakeRequest\x121\n" +
+	"\x05items\x18\x01 \x03(\v2\x1b.inventory.v1.StocktakeItemR\x05items\x12!\n" +
+	"\fwarehouse_id\x18\x02 \x01(\x04R\vwarehouseId\x12\x16\n" +
+	"\x06remark\x18\x03 \x01(\tR\x06remark\"S\n" +
+	"\rStocktakeItem\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12)\n" +
+	"\x10counted_quantity\x18\x02 \x01(\x05R\x0fcountedQuantity\"\xb4\x01\n" +
+	"\x17ImportStocktakeResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x127\n" +
+	"\aresults\x18\x03 \x03(\v2\x1d.inventory.v1.StocktakeResultR\aresults\x12\x1a\n" +
+	"\badjusted\x18\x04 \x01(\rR\badjusted\x12\x16\n" +
+	"\x06failed\x18\x05 \x01(\rR\x06failed\"\xeb\x01\n" +
+	"\x0fStocktakeResult\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12)\n" +
+	"\x10counted_quantity\x18\x02 \x01(\x05R\x0fcountedQuantity\x12\x1e\n" +
+	"\n" +
+	"difference\x18\x03 \x01(\x05R\n" +
+	"difference\x12!\n" +
+	"\fbefore_stock\x18\x04 \x01(\x05R\vbeforeStock\x12#\n" +
+	"\rcurrent_stock\x18\x05 \x01(\x05R\fcurrentStock\x12\x12\n" +
+	"\x04code\x18\x06 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\a \x01(\tR\amessage\"~\n" +
 	"\x17GetInventoryLogsRequest\x12\x17\n" +
 	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x12\n" +
 	"\x04page\x18\x02 \x01(\rR\x04page\x12\x1b\n" +
-	"\tpage_size\x18\x03 \x01(\rR\bpageSize\"\x8e\x01\n" +
+	"\tpage_size\x18\x03 \x01(\rR\bpageSize\x12\x19\n" +
+	"\border_id\x18\x04 \x01(\x04R\aorderId\"\x8e\x01\n" +
 	"\x18GetInventoryLogsResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12.\n" +
 	"\x04logs\x18\x03 \x03(\v2\x1a.inventory.v1.InventoryLogR\x04logs\x12\x14\n" +
-	"\x05total\x18\x04 \x01(\rR\x05total\"\xf2\x01\n" +
+	"\x05total\x18\x04 \x01(\rR\x05total\"u\n" +
+	"\x1bSetLowStockThresholdRequest\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x1c\n" +
+	"\tthreshold\x18\x02 \x01(\x05R\tthreshold\x12\x1f\n" +
+	"\vuse_default\x18\x03 \x01(\bR\n" +
+	"useDefault\"L\n" +
+	"\x1cSetLowStockThresholdResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"w\n" +
+	"\x13ListLowStockRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\rR\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\rR\bpageSize\x12/\n" +
+	"\x14include_out_of_stock\x18\x03 \x01(\bR\x11includeOutOfStock\"\x8c\x01\n" +
+	"\x14ListLowStockResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x120\n" +
+	"\x05items\x18\x03 \x03(\v2\x1a.inventory.v1.LowStockItemR\x05items\x12\x14\n" +
+	"\x05total\x18\x04 \x01(\rR\x05total\"\xab\x01\n" +
+	"\fLowStockItem\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x14\n" +
+	"\x05stock\x18\x02 \x01(\x05R\x05stock\x12!\n" +
+	"\flocked_stock\x18\x03 \x01(\x05R\vlockedStock\x12\x1c\n" +
+	"\tthreshold\x18\x04 \x01(\x05R\tthreshold\x12+\n" +
+	"\x11default_threshold\x18\x05 \x01(\bR\x10defaultThreshold\"O\n" +
+	"\x1bSubscribeBackInStockRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x17\n" +
+	"\abook_id\x18\x02 \x01(\x04R\x06bookId\"q\n" +
+	"\x1cSubscribeBackInStockResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12#\n" +
+	"\rcurrent_stock\x18\x03 \x01(\x05R\fcurrentStock\"Q\n" +
+	"\x1dUnsubscribeBackInStockRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x17\n" +
+	"\abook_id\x18\x02 \x01(\x04R\x06bookId\"N\n" +
+	"\x1eUnsubscribeBackInStockResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"J\n" +
+	"\x15ReconcileStockRequest\x12\x19\n" +
+	"\bbook_ids\x18\x01 \x03(\x04R\abookIds\x12\x16\n" +
+	"\x06policy\x18\x02 \x01(\tR\x06policy\"\xae\x01\n" +
+	"\x16ReconcileStockResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x18\n" +
+	"\achecked\x18\x03 \x01(\rR\achecked\x12\x1a\n" +
+	"\brepaired\x18\x04 \x01(\rR\brepaired\x120\n" +
+	"\x06drifts\x18\x05 \x03(\v2\x18.inventory.v1.StockDriftR\x06drifts\"\xd1\x02\n" +
+	"\n" +
+	"StockDrift\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x1f\n" +
+	"\vredis_stock\x18\x02 \x01(\x05R\n" +
+	"redisStock\x12!\n" +
+	"\fredis_locked\x18\x03 \x01(\x05R\vredisLocked\x12#\n" +
+	"\rredis_missing\x18\x04 \x01(\bR\fredisMissing\x12\x1f\n" +
+	"\vmysql_stock\x18\x05 \x01(\x05R\n" +
+	"mysqlStock\x12!\n" +
+	"\fmysql_locked\x18\x06 \x01(\x05R\vmysqlLocked\x12#\n" +
+	"\rmysql_missing\x18\a \x01(\bR\fmysqlMissing\x12!\n" +
+	"\fledger_stock\x18\b \x01(\x05R\vledgerStock\x12\x1d\n" +
+	"\n" +
+	"has_ledger\x18\t \x01(\bR\thasLedger\x12\x16\n" +
+	"\x06action\x18\n" +
+	" \x01(\tR\x06action\"\x7f\n" +
+	"\x13ReplayLedgerRequest\x12\x19\n" +
+	"\bbook_ids\x18\x01 \x03(\x04R\abookIds\x12\x13\n" +
+	"\x05as_of\x18\x02 \x01(\x03R\x04asOf\x12\"\n" +
+	"\rafter_book_id\x18\x03 \x01(\x04R\vafterBookId\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\rR\x05limit\"\xbc\x01\n" +
+	"\x14ReplayLedgerResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x120\n" +
+	"\x05books\x18\x03 \x03(\v2\x1a.inventory.v1.LedgerReplayR\x05books\x12 \n" +
+	"\fnext_book_id\x18\x04 \x01(\x04R\n" +
+	"nextBookId\x12\"\n" +
+	"\finconsistent\x18\x05 \x01(\rR\finconsistent\"\xb1\x03\n" +
+	"\fLedgerReplay\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12#\n" +
+	"\ropening_stock\x18\x02 \x01(\x05R\fopeningStock\x12\x14\n" +
+	"\x05stock\x18\x03 \x01(\x05R\x05stock\x12%\n" +
+	"\x0erecorded_stock\x18\x04 \x01(\x05R\rrecordedStock\x12!\n" +
+	"\flocked_stock\x18\x05 \x01(\x05R\vlockedStock\x12<\n" +
+	"\n" +
+	"warehouses\x18\x06 \x03(\v2\x1c.inventory.v1.WarehouseStockR\n" +
+	"warehouses\x12\x1b\n" +
+	"\tlog_count\x18\a \x01(\rR\blogCount\x12\x1e\n" +
+	"\vlast_log_id\x18\b \x01(\x04R\tlastLogId\x12\x1e\n" +
+	"\vlast_log_at\x18\t \x01(\x03R\tlastLogAt\x12\x1e\n" +
+	"\n" +
+	"consistent\x18\n" +
+	" \x01(\bR\n" +
+	"consistent\x12\x1b\n" +
+	"\tgap_count\x18\v \x01(\rR\bgapCount\x12+\n" +
+	"\x04gaps\x18\f \x03(\v2\x17.inventory.v1.LedgerGapR\x04gaps\"\xad\x01\n" +
+	"\tLedgerGap\x12\x15\n" +
+	"\x06log_id\x18\x01 \x01(\x04R\x05logId\x12\x1e\n" +
+	"\vprev_log_id\x18\x02 \x01(\x04R\tprevLogId\x12!\n" +
+	"\fwarehouse_id\x18\x03 \x01(\x04R\vwarehouseId\x12\x12\n" +
+	"\x04kind\x18\x04 \x01(\tR\x04kind\x12\x1a\n" +
+	"\bexpected\x18\x05 \x01(\x05R\bexpected\x12\x16\n" +
+	"\x06actual\x18\x06 \x01(\x05R\x06actual\"\x95\x02\n" +
 	"\fInventoryLog\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x17\n" +
 	"\abook_id\x18\x02 \x01(\x04R\x06bookId\x12\x1f\n" +
@@ -950,14 +3916,32 @@ const file_proto_inventory_v1_inventory_proto_rawDesc = "" +
 	"afterStock\x12\x19\n" +
 	"\border_id\x18\a \x01(\x04R\aorderId\x12\x1d\n" +
 	"\n" +
-	"created_at\x18\b \x01(\x03R\tcreatedAt2\xa8\x04\n" +
+	"created_at\x18\b \x01(\x03R\tcreatedAt\x12!\n" +
+	"\fwarehouse_id\x18\t \x01(\x04R\vwarehouseId2\xb5\x10\n" +
 	"\x10InventoryService\x12I\n" +
 	"\bGetStock\x12\x1d.inventory.v1.GetStockRequest\x1a\x1e.inventory.v1.GetStockResponse\x12X\n" +
-	"\rBatchGetStock\x12\".inventory.v1.BatchGetStockRequest\x1a#.inventory.v1.BatchGetStockResponse\x12R\n" +
+	"\rBatchGetStock\x12\".inventory.v1.BatchGetStockRequest\x1a#.inventory.v1.BatchGetStockResponse\x12J\n" +
+	"\n" +
+	"WatchStock\x12\x1f.inventory.v1.WatchStockRequest\x1a\x19.inventory.v1.StockChange0\x01\x12R\n" +
 	"\vDeductStock\x12 .inventory.v1.DeductStockRequest\x1a!.inventory.v1.DeductStockResponse\x12U\n" +
 	"\fReleaseStock\x12!.inventory.v1.ReleaseStockRequest\x1a\".inventory.v1.ReleaseStockResponse\x12a\n" +
-	"\x10RestockInventory\x12%.inventory.v1.RestockInventoryRequest\x1a&.inventory.v1.RestockInventoryResponse\x12a\n" +
-	"\x10GetInventoryLogs\x12%.inventory.v1.GetInventoryLogsRequest\x1a&.inventory.v1.GetInventoryLogsResponseB=Z;github.com/xiebiao/bookstore/proto/inventory/v1;inventoryv1b\x06proto3"
+	"\x10BatchDeductStock\x12%.inventory.v1.BatchDeductStockRequest\x1a&.inventory.v1.BatchDeductStockResponse\x12d\n" +
+	"\x11BatchReleaseStock\x12&.inventory.v1.BatchReleaseStockRequest\x1a'.inventory.v1.BatchReleaseStockResponse\x12U\n" +
+	"\fReserveStock\x12!.inventory.v1.ReserveStockRequest\x1a\".inventory.v1.ReserveStockResponse\x12g\n" +
+	"\x12ConfirmReservation\x12'.inventory.v1.ConfirmReservationRequest\x1a(.inventory.v1.ConfirmReservationResponse\x12d\n" +
+	"\x11CancelReservation\x12&.inventory.v1.CancelReservationRequest\x1a'.inventory.v1.CancelReservationResponse\x12d\n" +
+	"\x11BatchReserveStock\x12&.inventory.v1.BatchReserveStockRequest\x1a'.inventory.v1.BatchReserveStockResponse\x12a\n" +
+	"\x10RestockInventory\x12%.inventory.v1.RestockInventoryRequest\x1a&.inventory.v1.RestockInventoryResponse\x12X\n" +
+	"\rTransferStock\x12\".inventory.v1.TransferStockRequest\x1a#.inventory.v1.TransferStockResponse\x12R\n" +
+	"\vAdjustStock\x12 .inventory.v1.AdjustStockRequest\x1a!.inventory.v1.AdjustStockResponse\x12^\n" +
+	"\x0fImportStocktake\x12$.inventory.v1.ImportStocktakeRequest\x1a%.inventory.v1.ImportStocktakeResponse\x12a\n" +
+	"\x10GetInventoryLogs\x12%.inventory.v1.GetInventoryLogsRequest\x1a&.inventory.v1.GetInventoryLogsResponse\x12m\n" +
+	"\x14SetLowStockThreshold\x12).inventory.v1.SetLowStockThresholdRequest\x1a*.inventory.v1.SetLowStockThresholdResponse\x12U\n" +
+	"\fListLowStock\x12!.inventory.v1.ListLowStockRequest\x1a\".inventory.v1.ListLowStockResponse\x12m\n" +
+	"\x14SubscribeBackInStock\x12).inventory.v1.SubscribeBackInStockRequest\x1a*.inventory.v1.SubscribeBackInStockResponse\x12s\n" +
+	"\x16UnsubscribeBackInStock\x12+.inventory.v1.UnsubscribeBackInStockRequest\x1a,.inventory.v1.UnsubscribeBackInStockResponse\x12[\n" +
+	"\x0eReconcileStock\x12#.inventory.v1.ReconcileStockRequest\x1a$.inventory.v1.ReconcileStockResponse\x12U\n" +
+	"\fReplayLedger\x12!.inventory.v1.ReplayLedgerRequest\x1a\".inventory.v1.ReplayLedgerResponseB=Z;github.com/xiebiao/bookstore/proto/inventory/v1;inventoryv1b\x06proto3"
 
 var (
 	file_proto_inventory_v1_inventory_proto_rawDescOnce sync.Once
@@ -971,43 +3955,130 @@ func file_proto_inventory_v1_inventory_proto_rawDescGZIP() []byte {
 	return file_proto_inventory_v1_inventory_proto_rawDescData
 }
 
-var file_proto_inventory_v1_inventory_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_proto_inventory_v1_inventory_proto_msgTypes = make([]protoimpl.MessageInfo, 54)
 var file_proto_inventory_v1_inventory_proto_goTypes = []any{
-	(*GetStockRequest)(nil),          // 0: inventory.v1.GetStockRequest
-	(*GetStockResponse)(nil),         // 1: inventory.v1.GetStockResponse
-	(*BatchGetStockRequest)(nil),     // 2: inventory.v1.BatchGetStockRequest
-	(*BatchGetStockResponse)(nil),    // 3: inventory.v1.BatchGetStockResponse
-	(*StockInfo)(nil),                // 4: inventory.v1.StockInfo
-	(*DeductStockRequest)(nil),       // 5: inventory.v1.DeductStockRequest
-	(*DeductStockResponse)(nil),      // 6: inventory.v1.DeductStockResponse
-	(*ReleaseStockRequest)(nil),      // 7: inventory.v1.ReleaseStockRequest
-	(*ReleaseStockResponse)(nil),     // 8: inventory.v1.ReleaseStockResponse
-	(*RestockInventoryRequest)(nil),  // 9: inventory.v1.RestockInventoryRequest
-	(*RestockInventoryResponse)(nil), // 10: inventory.v1.RestockInventoryResponse
-	(*GetInventoryLogsRequest)(nil),  // 11: inventory.v1.GetInventoryLogsRequest
-	(*GetInventoryLogsResponse)(nil), // 12: inventory.v1.GetInventoryLogsResponse
-	(*InventoryLog)(nil),             // 13: inventory.v1.InventoryLog
+	(*GetStockRequest)(nil),                // 0: inventory.v1.GetStockRequest
+	(*GetStockResponse)(nil),               // 1: inventory.v1.GetStockResponse
+	(*WatchStockRequest)(nil),              // 2: inventory.v1.WatchStockRequest
+	(*StockChange)(nil),                    // 3: inventory.v1.StockChange
+	(*WarehouseStock)(nil),                 // 4: inventory.v1.WarehouseStock
+	(*BatchGetStockRequest)(nil),           // 5: inventory.v1.BatchGetStockRequest
+	(*BatchGetStockResponse)(nil),          // 6: inventory.v1.BatchGetStockResponse
+	(*StockInfo)(nil),                      // 7: inventory.v1.StockInfo
+	(*DeductStockRequest)(nil),             // 8: inventory.v1.DeductStockRequest
+	(*DeductStockResponse)(nil),            // 9: inventory.v1.DeductStockResponse
+	(*ReleaseStockRequest)(nil),            // 10: inventory.v1.ReleaseStockRequest
+	(*ReleaseStockResponse)(nil),           // 11: inventory.v1.ReleaseStockResponse
+	(*StockItem)(nil),                      // 12: inventory.v1.StockItem
+	(*BatchDeductStockRequest)(nil),        // 13: inventory.v1.BatchDeductStockRequest
+	(*BatchDeductStockResponse)(nil),       // 14: inventory.v1.BatchDeductStockResponse
+	(*BatchReleaseStockRequest)(nil),       // 15: inventory.v1.BatchReleaseStockRequest
+	(*BatchReleaseStockResponse)(nil),      // 16: inventory.v1.BatchReleaseStockResponse
+	(*ReserveStockRequest)(nil),            // 17: inventory.v1.ReserveStockRequest
+	(*ReserveStockResponse)(nil),           // 18: inventory.v1.ReserveStockResponse
+	(*ConfirmReservationRequest)(nil),      // 19: inventory.v1.ConfirmReservationRequest
+	(*ConfirmReservationResponse)(nil),     // 20: inventory.v1.ConfirmReservationResponse
+	(*CancelReservationRequest)(nil),       // 21: inventory.v1.CancelReservationRequest
+	(*CancelReservationResponse)(nil),      // 22: inventory.v1.CancelReservationResponse
+	(*BatchReserveStockRequest)(nil),       // 23: inventory.v1.BatchReserveStockRequest
+	(*BatchReserveStockResponse)(nil),      // 24: inventory.v1.BatchReserveStockResponse
+	(*RestockInventoryRequest)(nil),        // 25: inventory.v1.RestockInventoryRequest
+	(*RestockInventoryResponse)(nil),       // 26: inventory.v1.RestockInventoryResponse
+	(*TransferStockRequest)(nil),           // 27: inventory.v1.TransferStockRequest
+	(*TransferStockResponse)(nil),          // 28: inventory.v1.TransferStockResponse
+	(*AdjustStockRequest)(nil),             // 29: inventory.v1.AdjustStockRequest
+	(*AdjustStockResponse)(nil),            // 30: inventory.v1.AdjustStockResponse
+	(*ImportStocktakeRequest)(nil),         // 31: inventory.v1.ImportStocktakeRequest
+	(*StocktakeItem)(nil),                  // 32: inventory.v1.StocktakeItem
+	(*ImportStocktakeResponse)(nil),        // 33: inventory.v1.ImportStocktakeResponse
+	(*StocktakeResult)(nil),                // 34: inventory.v1.StocktakeResult
+	(*GetInventoryLogsRequest)(nil),        // 35: inventory.v1.GetInventoryLogsRequest
+	(*GetInventoryLogsResponse)(nil),       // 36: inventory.v1.GetInventoryLogsResponse
+	(*SetLowStockThresholdRequest)(nil),    // 37: inventory.v1.SetLowStockThresholdRequest
+	(*SetLowStockThresholdResponse)(nil),   // 38: inventory.v1.SetLowStockThresholdResponse
+	(*ListLowStockRequest)(nil),            // 39: inventory.v1.ListLowStockRequest
+	(*ListLowStockResponse)(nil),           // 40: inventory.v1.ListLowStockResponse
+	(*LowStockItem)(nil),                   // 41: inventory.v1.LowStockItem
+	(*SubscribeBackInStockRequest)(nil),    // 42: inventory.v1.SubscribeBackInStockRequest
+	(*SubscribeBackInStockResponse)(nil),   // 43: inventory.v1.SubscribeBackInStockResponse
+	(*UnsubscribeBackInStockRequest)(nil),  // 44: inventory.v1.UnsubscribeBackInStockRequest
+	(*UnsubscribeBackInStockResponse)(nil), // 45: inventory.v1.UnsubscribeBackInStockResponse
+	(*ReconcileStockRequest)(nil),          // 46: inventory.v1.ReconcileStockRequest
+	(*ReconcileStockResponse)(nil),         // 47: inventory.v1.ReconcileStockResponse
+	(*StockDrift)(nil),                     // 48: inventory.v1.StockDrift
+	(*ReplayLedgerRequest)(nil),            // 49: inventory.v1.ReplayLedgerRequest
+	(*ReplayLedgerResponse)(nil),           // 50: inventory.v1.ReplayLedgerResponse
+	(*LedgerReplay)(nil),                   // 51: inventory.v1.LedgerReplay
+	(*LedgerGap)(nil),                      // 52: inventory.v1.LedgerGap
+	(*InventoryLog)(nil),                   // 53: inventory.v1.InventoryLog
 }
 var file_proto_inventory_v1_inventory_proto_depIdxs = []int32{
-	4,  // 0: inventory.v1.BatchGetStockResponse.stocks:type_name -> inventory.v1.StockInfo
-	13, // 1: inventory.v1.GetInventoryLogsResponse.logs:type_name -> inventory.v1.InventoryLog
-	0,  // 2: inventory.v1.InventoryService.GetStock:input_type -> inventory.v1.GetStockRequest
-	2,  // 3: inventory.v1.InventoryService.BatchGetStock:input_type -> inventory.v1.BatchGetStockRequest
-	5,  // 4: inventory.v1.InventoryService.DeductStock:input_type -> inventory.v1.DeductStockRequest
-	7,  // 5: inventory.v1.InventoryService.ReleaseStock:input_type -> inventory.v1.ReleaseStockRequest
-	9,  // 6: inventory.v1.InventoryService.RestockInventory:input_type -> inventory.v1.RestockInventoryRequest
-	11, // 7: inventory.v1.InventoryService.GetInventoryLogs:input_type -> inventory.v1.GetInventoryLogsRequest
-	1,  // 8: inventory.v1.InventoryService.GetStock:output_type -> inventory.v1.GetStockResponse
-	3,  // 9: inventory.v1.InventoryService.BatchGetStock:output_type -> inventory.v1.BatchGetStockResponse
-	6,  // 10: inventory.v1.InventoryService.DeductStock:output_type -> inventory.v1.DeductStockResponse
-	8,  // 11: inventory.v1.InventoryService.ReleaseStock:output_type -> inventory.v1.ReleaseStockResponse
-	10, // 12: inventory.v1.InventoryService.RestockInventory:output_type -> inventory.v1.RestockInventoryResponse
-	12, // 13: inventory.v1.InventoryService.GetInventoryLogs:output_type -> inventory.v1.GetInventoryLogsResponse
-	8,  // [8:14] is the sub-list for method output_type
-	2,  // [2:8] is the sub-list for method input_type
-	2,  // [2:2] is the sub-list for extension type_name
-	2,  // [2:2] is the sub-list for extension extendee
-	0,  // [0:2] is the sub-list for field type_name
+	4,  // 0: inventory.v1.GetStockResponse.warehouses:type_name -> inventory.v1.WarehouseStock
+	7,  // 1: inventory.v1.BatchGetStockResponse.stocks:type_name -> inventory.v1.StockInfo
+	12, // 2: inventory.v1.BatchDeductStockRequest.items:type_name -> inventory.v1.StockItem
+	7,  // 3: inventory.v1.BatchDeductStockResponse.stocks:type_name -> inventory.v1.StockInfo
+	12, // 4: inventory.v1.BatchReleaseStockRequest.items:type_name -> inventory.v1.StockItem
+	7,  // 5: inventory.v1.BatchReleaseStockResponse.stocks:type_name -> inventory.v1.StockInfo
+	12, // 6: inventory.v1.BatchReserveStockRequest.items:type_name -> inventory.v1.StockItem
+	7,  // 7: inventory.v1.BatchReserveStockResponse.stocks:type_name -> inventory.v1.StockInfo
+	4,  // 8: inventory.v1.TransferStockResponse.warehouses:type_name -> inventory.v1.WarehouseStock
+	32, // 9: inventory.v1.ImportStocktakeRequest.items:type_name -> inventory.v1.StocktakeItem
+	34, // 10: inventory.v1.ImportStocktakeResponse.results:type_name -> inventory.v1.StocktakeResult
+	53, // 11: inventory.v1.GetInventoryLogsResponse.logs:type_name -> inventory.v1.InventoryLog
+	41, // 12: inventory.v1.ListLowStockResponse.items:type_name -> inventory.v1.LowStockItem
+	48, // 13: inventory.v1.ReconcileStockResponse.drifts:type_name -> inventory.v1.StockDrift
+	51, // 14: inventory.v1.ReplayLedgerResponse.books:type_name -> inventory.v1.LedgerReplay
+	4,  // 15: inventory.v1.LedgerReplay.warehouses:type_name -> inventory.v1.WarehouseStock
+	52, // 16: inventory.v1.LedgerReplay.gaps:type_name -> inventory.v1.LedgerGap
+	0,  // 17: inventory.v1.InventoryService.GetStock:input_type -> inventory.v1.GetStockRequest
+	5,  // 18: inventory.v1.InventoryService.BatchGetStock:input_type -> inventory.v1.BatchGetStockRequest
+	2,  // 19: inventory.v1.InventoryService.WatchStock:input_type -> inventory.v1.WatchStockRequest
+	8,  // 20: inventory.v1.InventoryService.DeductStock:input_type -> inventory.v1.DeductStockRequest
+	10, // 21: inventory.v1.InventoryService.ReleaseStock:input_type -> inventory.v1.ReleaseStockRequest
+	13, // 22: inventory.v1.InventoryService.BatchDeductStock:input_type -> inventory.v1.BatchDeductStockRequest
+	15, // 23: inventory.v1.InventoryService.BatchReleaseStock:input_type -> inventory.v1.BatchReleaseStockRequest
+	17, // 24: inventory.v1.InventoryService.ReserveStock:input_type -> inventory.v1.ReserveStockRequest
+	19, // 25: inventory.v1.InventoryService.ConfirmReservation:input_type -> inventory.v1.ConfirmReservationRequest
+	21, // 26: inventory.v1.InventoryService.CancelReservation:input_type -> inventory.v1.CancelReservationRequest
+	23, // 27: inventory.v1.InventoryService.BatchReserveStock:input_type -> inventory.v1.BatchReserveStockRequest
+	25, // 28: inventory.v1.InventoryService.RestockInventory:input_type -> inventory.v1.RestockInventoryRequest
+	27, // 29: inventory.v1.InventoryService.TransferStock:input_type -> inventory.v1.TransferStockRequest
+	29, // 30: inventory.v1.InventoryService.AdjustStock:input_type -> inventory.v1.AdjustStockRequest
+	31, // 31: inventory.v1.InventoryService.ImportStocktake:input_type -> inventory.v1.ImportStocktakeRequest
+	35, // 32: inventory.v1.InventoryService.GetInventoryLogs:input_type -> inventory.v1.GetInventoryLogsRequest
+	37, // 33: inventory.v1.InventoryService.SetLowStockThreshold:input_type -> inventory.v1.SetLowStockThresholdRequest
+	39, // 34: inventory.v1.InventoryService.ListLowStock:input_type -> inventory.v1.ListLowStockRequest
+	42, // 35: inventory.v1.InventoryService.SubscribeBackInStock:input_type -> inventory.v1.SubscribeBackInStockRequest
+	44, // 36: inventory.v1.InventoryService.UnsubscribeBackInStock:input_type -> inventory.v1.UnsubscribeBackInStockRequest
+	46, // 37: inventory.v1.InventoryService.ReconcileStock:input_type -> inventory.v1.ReconcileStockRequest
+	49, // 38: inventory.v1.InventoryService.ReplayLedger:input_type -> inventory.v1.ReplayLedgerRequest
+	1,  // 39: inventory.v1.InventoryService.GetStock:output_type -> inventory.v1.GetStockResponse
+	6,  // 40: inventory.v1.InventoryService.BatchGetStock:output_type -> inventory.v1.BatchGetStockResponse
+	3,  // 41: inventory.v1.InventoryService.WatchStock:output_type -> inventory.v1.StockChange
+	9,  // 42: inventory.v1.InventoryService.DeductStock:output_type -> inventory.v1.DeductStockResponse
+	11, // 43: inventory.v1.InventoryService.ReleaseStock:output_type -> inventory.v1.ReleaseStockResponse
+	14, // 44: inventory.v1.InventoryService.BatchDeductStock:output_type -> inventory.v1.BatchDeductStockResponse
+	16, // 45: inventory.v1.InventoryService.BatchReleaseStock:output_type -> inventory.v1.BatchReleaseStockResponse
+	18, // 46: inventory.v1.InventoryService.ReserveStock:output_type -> inventory.v1.ReserveStockResponse
+	20, // 47: inventory.v1.InventoryService.ConfirmReservation:output_type -> inventory.v1.ConfirmReservationResponse
+	22, // 48: inventory.v1.InventoryService.CancelReservation:output_type -> inventory.v1.CancelReservationResponse
+	24, // 49: inventory.v1.InventoryService.BatchReserveStock:output_type -> inventory.v1.BatchReserveStockResponse
+	26, // 50: inventory.v1.InventoryService.RestockInventory:output_type -> inventory.v1.RestockInventoryResponse
+	28, // 51: inventory.v1.InventoryService.TransferStock:output_type -> inventory.v1.TransferStockResponse
+	30, // 52: inventory.v1.InventoryService.AdjustStock:output_type -> inventory.v1.AdjustStockResponse
+	33, // 53: inventory.v1.InventoryService.ImportStocktake:output_type -> inventory.v1.ImportStocktakeResponse
+	36, // 54: inventory.v1.InventoryService.GetInventoryLogs:output_type -> inventory.v1.GetInventoryLogsResponse
+	38, // 55: inventory.v1.InventoryService.SetLowStockThreshold:output_type -> inventory.v1.SetLowStockThresholdResponse
+	40, // 56: inventory.v1.InventoryService.ListLowStock:output_type -> inventory.v1.ListLowStockResponse
+	43, // 57: inventory.v1.InventoryService.SubscribeBackInStock:output_type -> inventory.v1.SubscribeBackInStockResponse
+	45, // 58: inventory.v1.InventoryService.UnsubscribeBackInStock:output_type -> inventory.v1.UnsubscribeBackInStockResponse
+	47, // 59: inventory.v1.InventoryService.ReconcileStock:output_type -> inventory.v1.ReconcileStockResponse
+	50, // 60: inventory.v1.InventoryService.ReplayLedger:output_type -> inventory.v1.ReplayLedgerResponse
+	39, // [39:61] is the sub-list for method output_type
+	17, // [17:39] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
 }
 
 func init() { file_proto_inventory_v1_inventory_proto_init() }
@@ -1021,7 +4092,7 @@ func file_proto_inventory_v1_inventory_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_inventory_v1_inventory_proto_rawDesc), len(file_proto_inventory_v1_inventory_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   14,
+			NumMessages:   54,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
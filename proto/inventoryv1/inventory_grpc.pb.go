@@ -19,12 +19,28 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	InventoryService_GetStock_FullMethodName         = "/inventory.v1.InventoryService/GetStock"
-	InventoryService_BatchGetStock_FullMethodName    = "/inventory.v1.InventoryService/BatchGetStock"
-	InventoryService_DeductStock_FullMethodName      = "/inventory.v1.InventoryService/DeductStock"
-	InventoryService_ReleaseStock_FullMethodName     = "/inventory.v1.InventoryService/ReleaseStock"
-	InventoryService_RestockInventory_FullMethodName = "/inventory.v1.InventoryService/RestockInventory"
-	InventoryService_GetInventoryLogs_FullMethodName = "/inventory.v1.InventoryService/GetInventoryLogs"
+	InventoryService_GetStock_FullMethodName               = "/inventory.v1.InventoryService/GetStock"
+	InventoryService_BatchGetStock_FullMethodName          = "/inventory.v1.InventoryService/BatchGetStock"
+	InventoryService_WatchStock_FullMethodName             = "/inventory.v1.InventoryService/WatchStock"
+	InventoryService_DeductStock_FullMethodName            = "/inventory.v1.InventoryService/DeductStock"
+	InventoryService_ReleaseStock_FullMethodName           = "/inventory.v1.InventoryService/ReleaseStock"
+	InventoryService_BatchDeductStock_FullMethodName       = "/inventory.v1.InventoryService/BatchDeductStock"
+	InventoryService_BatchReleaseStock_FullMethodName      = "/inventory.v1.InventoryService/BatchReleaseStock"
+	InventoryService_ReserveStock_FullMethodName           = "/inventory.v1.InventoryService/ReserveStock"
+	InventoryService_ConfirmReservation_FullMethodName     = "/inventory.v1.InventoryService/ConfirmReservation"
+	InventoryService_CancelReservation_FullMethodName      = "/inventory.v1.InventoryService/CancelReservation"
+	InventoryService_BatchReserveStock_FullMethodName      = "/inventory.v1.InventoryService/BatchReserveStock"
+	InventoryService_RestockInventory_FullMethodName       = "/inventory.v1.InventoryService/RestockInventory"
+	InventoryService_TransferStock_FullMethodName          = "/inventory.v1.InventoryService/TransferStock"
+	InventoryService_AdjustStock_FullMethodName            = "/inventory.v1.InventoryService/AdjustStock"
+	InventoryService_ImportStocktake_FullMethodName        = "/inventory.v1.InventoryService/ImportStocktake"
+	InventoryService_GetInventoryLogs_FullMethodName       = "/inventory.v1.InventoryService/GetInventoryLogs"
+	InventoryService_SetLowStockThreshold_FullMethodName   = "/inventory.v1.InventoryService/SetLowStockThreshold"
+	InventoryService_ListLowStock_FullMethodName           = "/inventory.v1.InventoryService/ListLowStock"
+	InventoryService_SubscribeBackInStock_FullMethodName   = "/inventory.v1.InventoryService/SubscribeBackInStock"
+	InventoryService_UnsubscribeBackInStock_FullMethodName = "/inventory.v1.InventoryService/UnsubscribeBackInStock"
+	InventoryService_ReconcileStock_FullMethodName         = "/inventory.v1.InventoryService/ReconcileStock"
+	InventoryService_ReplayLedger_FullMethodName           = "/inventory.v1.InventoryService/ReplayLedger"
 )
 
 // InventoryServiceClient is the client API for InventoryService service.
@@ -37,6 +53,12 @@ type InventoryServiceClient interface {
 	// 批量查询库存
 	// 用例：商品列表页展示库存状态（有货/无货）
 	BatchGetStock(ctx context.Context, in *BatchGetStockRequest, opts ...grpc.CallOption) (*BatchGetStockResponse, error)
+	// 订阅库存变更（服务端流）
+	// 用例：商品页库存角标实时刷新，代替轮询GetStock/BatchGetStock
+	// 教学重点：
+	// 1. 变更经Redis Pub/Sub广播到所有副本，每个副本推送给自己持有的连接
+	// 2. 断线重连时带上收到的最大序号（from_seq），只补发之后有变化的图书
+	WatchStock(ctx context.Context, in *WatchStockRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StockChange], error)
 	// 扣减库存（下单时调用）
 	// 教学重点：
 	// 1. SELECT FOR UPDATE（悲观锁）
@@ -46,12 +68,60 @@ type InventoryServiceClient interface {
 	// 释放库存（订单取消、支付失败时调用）
 	// 教学重点：Saga补偿机制
 	ReleaseStock(ctx context.Context, in *ReleaseStockRequest, opts ...grpc.CallOption) (*ReleaseStockResponse, error)
+	// 批量扣减库存（一个订单的多本图书）
+	// 教学重点：单个Lua脚本先检查全部再扣减，要么全部成功要么全部不扣
+	BatchDeductStock(ctx context.Context, in *BatchDeductStockRequest, opts ...grpc.CallOption) (*BatchDeductStockResponse, error)
+	// 批量释放库存（BatchDeductStock的补偿操作）
+	BatchReleaseStock(ctx context.Context, in *BatchReleaseStockRequest, opts ...grpc.CallOption) (*BatchReleaseStockResponse, error)
+	// 预占库存（下单时调用，两阶段扣减的第一阶段）
+	// 教学重点：
+	// 1. 可用库存 → 锁定库存（Stock减少，LockedStock增加，TotalStock不变）
+	// 2. 预占带有效期：超时未确认由inventory-service自动取消
+	// 3. 幂等性：同一订单同一图书只预占一次
+	ReserveStock(ctx context.Context, in *ReserveStockRequest, opts ...grpc.CallOption) (*ReserveStockResponse, error)
+	// 确认预占（支付成功时调用）：锁定库存转为已售
+	ConfirmReservation(ctx context.Context, in *ConfirmReservationRequest, opts ...grpc.CallOption) (*ConfirmReservationResponse, error)
+	// 取消预占（订单取消、支付超时时调用）：锁定库存退回可用库存
+	CancelReservation(ctx context.Context, in *CancelReservationRequest, opts ...grpc.CallOption) (*CancelReservationResponse, error)
+	// 批量预占库存（一个订单的多本图书）
+	// 教学重点：单个Lua脚本先检查全部再预占，要么全部预占要么一本都不占；补偿仍按图书调用CancelReservation
+	BatchReserveStock(ctx context.Context, in *BatchReserveStockRequest, opts ...grpc.CallOption) (*BatchReserveStockResponse, error)
 	// 补充库存（补货）
 	// 用例：管理员补货操作
 	RestockInventory(ctx context.Context, in *RestockInventoryRequest, opts ...grpc.CallOption) (*RestockInventoryResponse, error)
+	// 仓库间调拨（管理接口）
+	// 教学重点：只在仓库之间移动，可售库存不变；调出、调入各记一条TRANSFER日志
+	TransferStock(ctx context.Context, in *TransferStockRequest, opts ...grpc.CallOption) (*TransferStockResponse, error)
+	// 库存调整（管理接口）
+	// 用例：报损、丢失、退货入库；原因码记为日志的change_type
+	// 教学重点：调整数量为有符号数，方向必须与原因一致（报损/丢失只减，退货只增）
+	AdjustStock(ctx context.Context, in *AdjustStockRequest, opts ...grpc.CallOption) (*AdjustStockResponse, error)
+	// 盘点导入（管理接口）
+	// 用例：按实盘数量逐本生成STOCKTAKE调整，实盘与账面一致的图书不调整
+	ImportStocktake(ctx context.Context, in *ImportStocktakeRequest, opts ...grpc.CallOption) (*ImportStocktakeResponse, error)
 	// 获取库存变更日志
 	// 用例：库存对账、审计
+	// 指定order_id时按订单查询（返回该订单的全部扣减/释放流水，不分页）
 	GetInventoryLogs(ctx context.Context, in *GetInventoryLogsRequest, opts ...grpc.CallOption) (*GetInventoryLogsResponse, error)
+	// 设置低库存阈值（管理接口）
+	// 用例：畅销书调高阈值提前补货，长尾书调低阈值避免频繁告警
+	SetLowStockThreshold(ctx context.Context, in *SetLowStockThresholdRequest, opts ...grpc.CallOption) (*SetLowStockThresholdResponse, error)
+	// 查询低库存图书
+	// 用例：采购团队按库存紧缺程度安排补货
+	ListLowStock(ctx context.Context, in *ListLowStockRequest, opts ...grpc.CallOption) (*ListLowStockResponse, error)
+	// 订阅到货提醒
+	// 用例：缺货图书详情页"到货通知我"，补货使库存从0变为有货时按批通知订阅用户
+	// 教学重点：补货只发布事件，通知由消费者异步分批发送，订阅者再多也不拖慢补货
+	SubscribeBackInStock(ctx context.Context, in *SubscribeBackInStockRequest, opts ...grpc.CallOption) (*SubscribeBackInStockResponse, error)
+	// 取消到货提醒
+	UnsubscribeBackInStock(ctx context.Context, in *UnsubscribeBackInStockRequest, opts ...grpc.CallOption) (*UnsubscribeBackInStockResponse, error)
+	// 库存对账（管理接口）
+	// 用例：Redis重启后、定时巡检，比较Redis与MySQL库存并按策略修复
+	ReconcileStock(ctx context.Context, in *ReconcileStockRequest, opts ...grpc.CallOption) (*ReconcileStockResponse, error)
+	// 日志重放（管理接口）
+	// 用例：财务月结查询某一时刻的库存、审计时校验日志链是否连续
+	// 教学重点：日志记录变更前后库存，按顺序首尾相接即为账本，期初 + 逐条变更 = 历史库存
+	ReplayLedger(ctx context.Context, in *ReplayLedgerRequest, opts ...grpc.CallOption) (*ReplayLedgerResponse, error)
 }
 
 type inventoryServiceClient struct {
@@ -82,6 +152,25 @@ func (c *inventoryServiceClient) BatchGetStock(ctx context.Context, in *BatchGet
 	return out, nil
 }
 
+func (c *inventoryServiceClient) WatchStock(ctx context.Context, in *WatchStockRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StockChange], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &InventoryService_ServiceDesc.Streams[0], InventoryService_WatchStock_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchStockRequest, StockChange]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type InventoryService_WatchStockClient = grpc.ServerStreamingClient[StockChange]
+
 func (c *inventoryServiceClient) DeductStock(ctx context.Context, in *DeductStockRequest, opts ...grpc.CallOption) (*DeductStockResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(DeductStockResponse)
@@ -102,6 +191,66 @@ func (c *inventoryServiceClient) ReleaseStock(ctx context.Context, in *ReleaseSt
 	return out, nil
 }
 
+func (c *inventoryServiceClient) BatchDeductStock(ctx context.Context, in *BatchDeductStockRequest, opts ...grpc.CallOption) (*BatchDeductStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchDeductStockResponse)
+	err := c.cc.Invoke(ctx, InventoryService_BatchDeductStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) BatchReleaseStock(ctx context.Context, in *BatchReleaseStockRequest, opts ...grpc.CallOption) (*BatchReleaseStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchReleaseStockResponse)
+	err := c.cc.Invoke(ctx, InventoryService_BatchReleaseStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) ReserveStock(ctx context.Context, in *ReserveStockRequest, opts ...grpc.CallOption) (*ReserveStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReserveStockResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ReserveStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) ConfirmReservation(ctx context.Context, in *ConfirmReservationRequest, opts ...grpc.CallOption) (*ConfirmReservationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConfirmReservationResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ConfirmReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) CancelReservation(ctx context.Context, in *CancelReservationRequest, opts ...grpc.CallOption) (*CancelReservationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelReservationResponse)
+	err := c.cc.Invoke(ctx, InventoryService_CancelReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) BatchReserveStock(ctx context.Context, in *BatchReserveStockRequest, opts ...grpc.CallOption) (*BatchReserveStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchReserveStockResponse)
+	err := c.cc.Invoke(ctx, InventoryService_BatchReserveStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *inventoryServiceClient) RestockInventory(ctx context.Context, in *RestockInventoryRequest, opts ...grpc.CallOption) (*RestockInventoryResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(RestockInventoryResponse)
@@ -112,6 +261,36 @@ func (c *inventoryServiceClient) RestockInventory(ctx context.Context, in *Resto
 	return out, nil
 }
 
+func (c *inventoryServiceClient) TransferStock(ctx context.Context, in *TransferStockRequest, opts ...grpc.CallOption) (*TransferStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransferStockResponse)
+	err := c.cc.Invoke(ctx, InventoryService_TransferStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) AdjustStock(ctx context.Context, in *AdjustStockRequest, opts ...grpc.CallOption) (*AdjustStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdjustStockResponse)
+	err := c.cc.Invoke(ctx, InventoryService_AdjustStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) ImportStocktake(ctx context.Context, in *ImportStocktakeRequest, opts ...grpc.CallOption) (*ImportStocktakeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImportStocktakeResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ImportStocktake_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *inventoryServiceClient) GetInventoryLogs(ctx context.Context, in *GetInventoryLogsRequest, opts ...grpc.CallOption) (*GetInventoryLogsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetInventoryLogsResponse)
@@ -122,6 +301,66 @@ func (c *inventoryServiceClient) GetInventoryLogs(ctx context.Context, in *GetIn
 	return out, nil
 }
 
+func (c *inventoryServiceClient) SetLowStockThreshold(ctx context.Context, in *SetLowStockThresholdRequest, opts ...grpc.CallOption) (*SetLowStockThresholdResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetLowStockThresholdResponse)
+	err := c.cc.Invoke(ctx, InventoryService_SetLowStockThreshold_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) ListLowStock(ctx context.Context, in *ListLowStockRequest, opts ...grpc.CallOption) (*ListLowStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLowStockResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ListLowStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) SubscribeBackInStock(ctx context.Context, in *SubscribeBackInStockRequest, opts ...grpc.CallOption) (*SubscribeBackInStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubscribeBackInStockResponse)
+	err := c.cc.Invoke(ctx, InventoryService_SubscribeBackInStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) UnsubscribeBackInStock(ctx context.Context, in *UnsubscribeBackInStockRequest, opts ...grpc.CallOption) (*UnsubscribeBackInStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnsubscribeBackInStockResponse)
+	err := c.cc.Invoke(ctx, InventoryService_UnsubscribeBackInStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) ReconcileStock(ctx context.Context, in *ReconcileStockRequest, opts ...grpc.CallOption) (*ReconcileStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReconcileStockResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ReconcileStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) ReplayLedger(ctx context.Context, in *ReplayLedgerRequest, opts ...grpc.CallOption) (*ReplayLedgerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReplayLedgerResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ReplayLedger_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // InventoryServiceServer is the server API for InventoryService service.
 // All implementations must embed UnimplementedInventoryServiceServer
 // for forward compatibility.
@@ -132,6 +371,12 @@ type InventoryServiceServer interface {
 	// 批量查询库存
 	// 用例：商品列表页展示库存状态（有货/无货）
 	BatchGetStock(context.Context, *BatchGetStockRequest) (*BatchGetStockResponse, error)
+	// 订阅库存变更（服务端流）
+	// 用例：商品页库存角标实时刷新，代替轮询GetStock/BatchGetStock
+	// 教学重点：
+	// 1. 变更经Redis Pub/Sub广播到所有副本，每个副本推送给自己持有的连接
+	// 2. 断线重连时带上收到的最大序号（from_seq），只补发之后有变化的图书
+	WatchStock(*WatchStockRequest, grpc.ServerStreamingServer[StockChange]) error
 	// 扣减库存（下单时调用）
 	// 教学重点：
 	// 1. SELECT FOR UPDATE（悲观锁）
@@ -141,12 +386,60 @@ type InventoryServiceServer interface {
 	// 释放库存（订单取消、支付失败时调用）
 	// 教学重点：Saga补偿机制
 	ReleaseStock(context.Context, *ReleaseStockRequest) (*ReleaseStockResponse, error)
+	// 批量扣减库存（一个订单的多本图书）
+	// 教学重点：单个Lua脚本先检查全部再扣减，要么全部成功要么全部不扣
+	BatchDeductStock(context.Context, *BatchDeductStockRequest) (*BatchDeductStockResponse, error)
+	// 批量释放库存（BatchDeductStock的补偿操作）
+	BatchReleaseStock(context.Context, *BatchReleaseStockRequest) (*BatchReleaseStockResponse, error)
+	// 预占库存（下单时调用，两阶段扣减的第一阶段）
+	// 教学重点：
+	// 1. 可用库存 → 锁定库存（Stock减少，LockedStock增加，TotalStock不变）
+	// 2. 预占带有效期：超时未确认由inventory-service自动取消
+	// 3. 幂等性：同一订单同一图书只预占一次
+	ReserveStock(context.Context, *ReserveStockRequest) (*ReserveStockResponse, error)
+	// 确认预占（支付成功时调用）：锁定库存转为已售
+	ConfirmReservation(context.Context, *ConfirmReservationRequest) (*ConfirmReservationResponse, error)
+	// 取消预占（订单取消、支付超时时调用）：锁定库存退回可用库存
+	CancelReservation(context.Context, *CancelReservationRequest) (*CancelReservationResponse, error)
+	// 批量预占库存（一个订单的多本图书）
+	// 教学重点：单个Lua脚本先检查全部再预占，要么全部预占要么一本都不占；补偿仍按图书调用CancelReservation
+	BatchReserveStock(context.Context, *BatchReserveStockRequest) (*BatchReserveStockResponse, error)
 	// 补充库存（补货）
 	// 用例：管理员补货操作
 	RestockInventory(context.Context, *RestockInventoryRequest) (*RestockInventoryResponse, error)
+	// 仓库间调拨（管理接口）
+	// 教学重点：只在仓库之间移动，可售库存不变；调出、调入各记一条TRANSFER日志
+	TransferStock(context.Context, *TransferStockRequest) (*TransferStockResponse, error)
+	// 库存调整（管理接口）
+	// 用例：报损、丢失、退货入库；原因码记为日志的change_type
+	// 教学重点：调整数量为有符号数，方向必须与原因一致（报损/丢失只减，退货只增）
+	AdjustStock(context.Context, *AdjustStockRequest) (*AdjustStockResponse, error)
+	// 盘点导入（管理接口）
+	// 用例：按实盘数量逐本生成STOCKTAKE调整，实盘与账面一致的图书不调整
+	ImportStocktake(context.Context, *ImportStocktakeRequest) (*ImportStocktakeResponse, error)
 	// 获取库存变更日志
 	// 用例：库存对账、审计
+	// 指定order_id时按订单查询（返回该订单的全部扣减/释放流水，不分页）
 	GetInventoryLogs(context.Context, *GetInventoryLogsRequest) (*GetInventoryLogsResponse, error)
+	// 设置低库存阈值（管理接口）
+	// 用例：畅销书调高阈值提前补货，长尾书调低阈值避免频繁告警
+	SetLowStockThreshold(context.Context, *SetLowStockThresholdRequest) (*SetLowStockThresholdResponse, error)
+	// 查询低库存图书
+	// 用例：采购团队按库存紧缺程度安排补货
+	ListLowStock(context.Context, *ListLowStockRequest) (*ListLowStockResponse, error)
+	// 订阅到货提醒
+	// 用例：缺货图书详情页"到货通知我"，补货使库存从0变为有货时按批通知订阅用户
+	// 教学重点：补货只发布事件，通知由消费者异步分批发送，订阅者再多也不拖慢补货
+	SubscribeBackInStock(context.Context, *SubscribeBackInStockRequest) (*SubscribeBackInStockResponse, error)
+	// 取消到货提醒
+	UnsubscribeBackInStock(context.Context, *UnsubscribeBackInStockRequest) (*UnsubscribeBackInStockResponse, error)
+	// 库存对账（管理接口）
+	// 用例：Redis重启后、定时巡检，比较Redis与MySQL库存并按策略修复
+	ReconcileStock(context.Context, *ReconcileStockRequest) (*ReconcileStockResponse, error)
+	// 日志重放（管理接口）
+	// 用例：财务月结查询某一时刻的库存、审计时校验日志链是否连续
+	// 教学重点：日志记录变更前后库存，按顺序首尾相接即为账本，期初 + 逐条变更 = 历史库存
+	ReplayLedger(context.Context, *ReplayLedgerRequest) (*ReplayLedgerResponse, error)
 	mustEmbedUnimplementedInventoryServiceServer()
 }
 
@@ -163,18 +456,66 @@ func (UnimplementedInventoryServiceServer) GetStock(context.Context, *GetStockRe
 func (UnimplementedInventoryServiceServer) BatchGetStock(context.Context, *BatchGetStockRequest) (*BatchGetStockResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BatchGetStock not implemented")
 }
+func (UnimplementedInventoryServiceServer) WatchStock(*WatchStockRequest, grpc.ServerStreamingServer[StockChange]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchStock not implemented")
+}
 func (UnimplementedInventoryServiceServer) DeductStock(context.Context, *DeductStockRequest) (*DeductStockResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeductStock not implemented")
 }
 func (UnimplementedInventoryServiceServer) ReleaseStock(context.Context, *ReleaseStockRequest) (*ReleaseStockResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReleaseStock not implemented")
 }
+func (UnimplementedInventoryServiceServer) BatchDeductStock(context.Context, *BatchDeductStockRequest) (*BatchDeductStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchDeductStock not implemented")
+}
+func (UnimplementedInventoryServiceServer) BatchReleaseStock(context.Context, *BatchReleaseStockRequest) (*BatchReleaseStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchReleaseStock not implemented")
+}
+func (UnimplementedInventoryServiceServer) ReserveStock(context.Context, *ReserveStockRequest) (*ReserveStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReserveStock not implemented")
+}
+func (UnimplementedInventoryServiceServer) ConfirmReservation(context.Context, *ConfirmReservationRequest) (*ConfirmReservationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmReservation not implemented")
+}
+func (UnimplementedInventoryServiceServer) CancelReservation(context.Context, *CancelReservationRequest) (*CancelReservationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelReservation not implemented")
+}
+func (UnimplementedInventoryServiceServer) BatchReserveStock(context.Context, *BatchReserveStockRequest) (*BatchReserveStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchReserveStock not implemented")
+}
 func (UnimplementedInventoryServiceServer) RestockInventory(context.Context, *RestockInventoryRequest) (*RestockInventoryResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RestockInventory not implemented")
 }
+func (UnimplementedInventoryServiceServer) TransferStock(context.Context, *TransferStockRequest) (*TransferStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TransferStock not implemented")
+}
+func (UnimplementedInventoryServiceServer) AdjustStock(context.Context, *AdjustStockRequest) (*AdjustStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdjustStock not implemented")
+}
+func (UnimplementedInventoryServiceServer) ImportStocktake(context.Context, *ImportStocktakeRequest) (*ImportStocktakeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportStocktake not implemented")
+}
 func (UnimplementedInventoryServiceServer) GetInventoryLogs(context.Context, *GetInventoryLogsRequest) (*GetInventoryLogsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetInventoryLogs not implemented")
 }
+func (UnimplementedInventoryServiceServer) SetLowStockThreshold(context.Context, *SetLowStockThresholdRequest) (*SetLowStockThresholdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLowStockThreshold not implemented")
+}
+func (UnimplementedInventoryServiceServer) ListLowStock(context.Context, *ListLowStockRequest) (*ListLowStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLowStock not implemented")
+}
+func (UnimplementedInventoryServiceServer) SubscribeBackInStock(context.Context, *SubscribeBackInStockRequest) (*SubscribeBackInStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubscribeBackInStock not implemented")
+}
+func (UnimplementedInventoryServiceServer) UnsubscribeBackInStock(context.Context, *UnsubscribeBackInStockRequest) (*UnsubscribeBackInStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnsubscribeBackInStock not implemented")
+}
+func (UnimplementedInventoryServiceServer) ReconcileStock(context.Context, *ReconcileStockRequest) (*ReconcileStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReconcileStock not implemented")
+}
+func (UnimplementedInventoryServiceServer) ReplayLedger(context.Context, *ReplayLedgerRequest) (*ReplayLedgerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplayLedger not implemented")
+}
 func (UnimplementedInventoryServiceServer) mustEmbedUnimplementedInventoryServiceServer() {}
 func (UnimplementedInventoryServiceServer) testEmbeddedByValue()                          {}
 
@@ -232,6 +573,17 @@ func _InventoryService_BatchGetStock_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _InventoryService_WatchStock_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchStockRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(InventoryServiceServer).WatchStock(m, &grpc.GenericServerStream[WatchStockRequest, StockChange]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type InventoryService_WatchStockServer = grpc.ServerStreamingServer[StockChange]
+
 func _InventoryService_DeductStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeductStockRequest)
 	if err := dec(in); err != nil {
@@ -268,6 +620,114 @@ func _InventoryService_ReleaseStock_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _InventoryService_BatchDeductStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDeductStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).BatchDeductStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_BatchDeductStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).BatchDeductStock(ctx, req.(*BatchDeductStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_BatchReleaseStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchReleaseStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).BatchReleaseStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_BatchReleaseStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).BatchReleaseStock(ctx, req.(*BatchReleaseStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_ReserveStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReserveStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ReserveStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ReserveStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ReserveStock(ctx, req.(*ReserveStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_ConfirmReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ConfirmReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ConfirmReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ConfirmReservation(ctx, req.(*ConfirmReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_CancelReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).CancelReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_CancelReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).CancelReservation(ctx, req.(*CancelReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_BatchReserveStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchReserveStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).BatchReserveStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_BatchReserveStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).BatchReserveStock(ctx, req.(*BatchReserveStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _InventoryService_RestockInventory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(RestockInventoryRequest)
 	if err := dec(in); err != nil {
@@ -286,6 +746,60 @@ func _InventoryService_RestockInventory_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _InventoryService_TransferStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransferStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).TransferStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_TransferStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).TransferStock(ctx, req.(*TransferStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_AdjustStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdjustStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).AdjustStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_AdjustStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).AdjustStock(ctx, req.(*AdjustStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_ImportStocktake_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportStocktakeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ImportStocktake(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ImportStocktake_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ImportStocktake(ctx, req.(*ImportStocktakeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _InventoryService_GetInventoryLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetInventoryLogsRequest)
 	if err := dec(in); err != nil {
@@ -304,6 +818,114 @@ func _InventoryService_GetInventoryLogs_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _InventoryService_SetLowStockThreshold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLowStockThresholdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).SetLowStockThreshold(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_SetLowStockThreshold_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).SetLowStockThreshold(ctx, req.(*SetLowStockThresholdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_ListLowStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLowStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ListLowStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ListLowStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ListLowStock(ctx, req.(*ListLowStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_SubscribeBackInStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubscribeBackInStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).SubscribeBackInStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_SubscribeBackInStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).SubscribeBackInStock(ctx, req.(*SubscribeBackInStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_UnsubscribeBackInStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnsubscribeBackInStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).UnsubscribeBackInStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_UnsubscribeBackInStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).UnsubscribeBackInStock(ctx, req.(*UnsubscribeBackInStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_ReconcileStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconcileStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ReconcileStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ReconcileStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ReconcileStock(ctx, req.(*ReconcileStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_ReplayLedger_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplayLedgerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ReplayLedger(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ReplayLedger_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ReplayLedger(ctx, req.(*ReplayLedgerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // InventoryService_ServiceDesc is the grpc.ServiceDesc for InventoryService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -327,15 +949,81 @@ var InventoryService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReleaseStock",
 			Handler:    _InventoryService_ReleaseStock_Handler,
 		},
+		{
+			MethodName: "BatchDeductStock",
+			Handler:    _InventoryService_BatchDeductStock_Handler,
+		},
+		{
+			MethodName: "BatchReleaseStock",
+			Handler:    _InventoryService_BatchReleaseStock_Handler,
+		},
+		{
+			MethodName: "ReserveStock",
+			Handler:    _InventoryService_ReserveStock_Handler,
+		},
+		{
+			MethodName: "ConfirmReservation",
+			Handler:    _InventoryService_ConfirmReservation_Handler,
+		},
+		{
+			MethodName: "CancelReservation",
+			Handler:    _InventoryService_CancelReservation_Handler,
+		},
+		{
+			MethodName: "BatchReserveStock",
+			Handler:    _InventoryService_BatchReserveStock_Handler,
+		},
 		{
 			MethodName: "RestockInventory",
 			Handler:    _InventoryService_RestockInventory_Handler,
 		},
+		{
+			MethodName: "TransferStock",
+			Handler:    _InventoryService_TransferStock_Handler,
+		},
+		{
+			MethodName: "AdjustStock",
+			Handler:    _InventoryService_AdjustStock_Handler,
+		},
+		{
+			MethodName: "ImportStocktake",
+			Handler:    _InventoryService_ImportStocktake_Handler,
+		},
 		{
 			MethodName: "GetInventoryLogs",
 			Handler:    _InventoryService_GetInventoryLogs_Handler,
 		},
+		{
+			MethodName: "SetLowStockThreshold",
+			Handler:    _InventoryService_SetLowStockThreshold_Handler,
+		},
+		{
+			MethodName: "ListLowStock",
+			Handler:    _InventoryService_ListLowStock_Handler,
+		},
+		{
+			MethodName: "SubscribeBackInStock",
+			Handler:    _InventoryService_SubscribeBackInStock_Handler,
+		},
+		{
+			MethodName: "UnsubscribeBackInStock",
+			Handler:    _InventoryService_UnsubscribeBackInStock_Handler,
+		},
+		{
+			MethodName: "ReconcileStock",
+			Handler:    _InventoryService_ReconcileStock_Handler,
+		},
+		{
+			MethodName: "ReplayLedger",
+			Handler:    _InventoryService_ReplayLedger_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchStock",
+			Handler:       _InventoryService_WatchStock_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/inventory/v1/inventory.proto",
 }
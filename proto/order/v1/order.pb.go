@@ -428,6 +428,7 @@ type ListUserOrdersRequest struct {
 	Page          uint32                 `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
 	PageSize      uint32                 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
 	Status        int32                  `protobuf:"varint,4,opt,name=status,proto3" json:"status,omitempty"` // 状态筛选（0为全部）
+	Cursor        string                 `protobuf:"bytes,5,opt,name=cursor,proto3" json:"cursor,omitempty"`  // 游标（上一页响应的next_cursor），非空时忽略page
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -490,12 +491,20 @@ func (x *ListUserOrdersRequest) GetStatus() int32 {
 	return 0
 }
 
+func (x *ListUserOrdersRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
 type ListUserOrdersResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	Orders        []*Order               `protobuf:"bytes,3,rep,name=orders,proto3" json:"orders,omitempty"`
 	Total         uint32                 `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,5,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // 下一页游标（没有下一页时为空）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -507,14 +516,883 @@ func (x *ListUserOrdersResponse) Reset() {
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListUserOrdersResponse) String() string {
+func (x *ListUserOrdersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUserOrdersResponse) ProtoMessage() {}
+
+func (x *ListUserOrdersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUserOrdersResponse.ProtoReflect.Descriptor instead.
+func (*ListUserOrdersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListUserOrdersResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ListUserOrdersResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ListUserOrdersResponse) GetOrders() []*Order {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+func (x *ListUserOrdersResponse) GetTotal() uint32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListUserOrdersResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+// 取消订单
+type CancelOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       uint64                 `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	UserId        uint64                 `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // 用于权限校验
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`                // 取消原因
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelOrderRequest) Reset() {
+	*x = CancelOrderRequest{}
+	mi := &file_proto_order_v1_order_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelOrderRequest) ProtoMessage() {}
+
+func (x *CancelOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelOrderRequest.ProtoReflect.Descriptor instead.
+func (*CancelOrderRequest) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CancelOrderRequest) GetOrderId() uint64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *CancelOrderRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *CancelOrderRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type CancelOrderResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelOrderResponse) Reset() {
+	*x = CancelOrderResponse{}
+	mi := &file_proto_order_v1_order_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelOrderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelOrderResponse) ProtoMessage() {}
+
+func (x *CancelOrderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelOrderResponse.ProtoReflect.Descriptor instead.
+func (*CancelOrderResponse) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CancelOrderResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *CancelOrderResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// 查询卡住的补偿
+type ListStuckCompensationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`               // 状态筛选：PENDING/RESOLVED（空为全部）
+	SagaId        string                 `protobuf:"bytes,2,opt,name=saga_id,json=sagaId,proto3" json:"saga_id,omitempty"` // Saga实例ID筛选（可选）
+	Page          uint32                 `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      uint32                 `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStuckCompensationsRequest) Reset() {
+	*x = ListStuckCompensationsRequest{}
+	mi := &file_proto_order_v1_order_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStuckCompensationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStuckCompensationsRequest) ProtoMessage() {}
+
+func (x *ListStuckCompensationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStuckCompensationsRequest.ProtoReflect.Descriptor instead.
+func (*ListStuckCompensationsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListStuckCompensationsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListStuckCompensationsRequest) GetSagaId() string {
+	if x != nil {
+		return x.SagaId
+	}
+	return ""
+}
+
+func (x *ListStuckCompensationsRequest) GetPage() uint32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListStuckCompensationsRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListStuckCompensationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Items         []*StuckCompensation   `protobuf:"bytes,3,rep,name=items,proto3" json:"items,omitempty"`
+	Total         uint32                 `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStuckCompensationsResponse) Reset() {
+	*x = ListStuckCompensationsResponse{}
+	mi := &file_proto_order_v1_order_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStuckCompensationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStuckCompensationsResponse) ProtoMessage() {}
+
+func (x *ListStuckCompensationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStuckCompensationsResponse.ProtoReflect.Descriptor instead.
+func (*ListStuckCompensationsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListStuckCompensationsResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ListStuckCompensationsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ListStuckCompensationsResponse) GetItems() []*StuckCompensation {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ListStuckCompensationsResponse) GetTotal() uint32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// 重新驱动卡住的补偿
+type RedriveCompensationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"` // 卡住的补偿记录ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RedriveCompensationRequest) Reset() {
+	*x = RedriveCompensationRequest{}
+	mi := &file_proto_order_v1_order_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RedriveCompensationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedriveCompensationRequest) ProtoMessage() {}
+
+func (x *RedriveCompensationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RedriveCompensationRequest.ProtoReflect.Descriptor instead.
+func (*RedriveCompensationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RedriveCompensationRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type RedriveCompensationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0成功，40400记录不存在，50000补偿仍失败
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Item          *StuckCompensation     `protobuf:"bytes,3,opt,name=item,proto3" json:"item,omitempty"` // 驱动后的记录
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RedriveCompensationResponse) Reset() {
+	*x = RedriveCompensationResponse{}
+	mi := &file_proto_order_v1_order_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RedriveCompensationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedriveCompensationResponse) ProtoMessage() {}
+
+func (x *RedriveCompensationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RedriveCompensationResponse.ProtoReflect.Descriptor instead.
+func (*RedriveCompensationResponse) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RedriveCompensationResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *RedriveCompensationResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *RedriveCompensationResponse) GetItem() *StuckCompensation {
+	if x != nil {
+		return x.Item
+	}
+	return nil
+}
+
+// 创建秒杀活动
+type CreateFlashSaleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookId        uint64                 `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	Price         int64                  `protobuf:"varint,2,opt,name=price,proto3" json:"price,omitempty"`                                     // 秒杀价（分）
+	Stock         int32                  `protobuf:"varint,3,opt,name=stock,proto3" json:"stock,omitempty"`                                     // 活动库存
+	PerUserLimit  int32                  `protobuf:"varint,4,opt,name=per_user_limit,json=perUserLimit,proto3" json:"per_user_limit,omitempty"` // 每人限购数量
+	StartAt       int64                  `protobuf:"varint,5,opt,name=start_at,json=startAt,proto3" json:"start_at,omitempty"`                  // 开始时间（Unix秒）
+	EndAt         int64                  `protobuf:"varint,6,opt,name=end_at,json=endAt,proto3" json:"end_at,omitempty"`                        // 结束时间（Unix秒）
+	OperatorId    uint64                 `protobuf:"varint,7,opt,name=operator_id,json=operatorId,proto3" json:"operator_id,omitempty"`         // 操作者用户ID（必须是图书的发布者）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateFlashSaleRequest) Reset() {
+	*x = CreateFlashSaleRequest{}
+	mi := &file_proto_order_v1_order_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateFlashSaleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateFlashSaleRequest) ProtoMessage() {}
+
+func (x *CreateFlashSaleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateFlashSaleRequest.ProtoReflect.Descriptor instead.
+func (*CreateFlashSaleRequest) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CreateFlashSaleRequest) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *CreateFlashSaleRequest) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *CreateFlashSaleRequest) GetStock() int32 {
+	if x != nil {
+		return x.Stock
+	}
+	return 0
+}
+
+func (x *CreateFlashSaleRequest) GetPerUserLimit() int32 {
+	if x != nil {
+		return x.PerUserLimit
+	}
+	return 0
+}
+
+func (x *CreateFlashSaleRequest) GetStartAt() int64 {
+	if x != nil {
+		return x.StartAt
+	}
+	return 0
+}
+
+func (x *CreateFlashSaleRequest) GetEndAt() int64 {
+	if x != nil {
+		return x.EndAt
+	}
+	return 0
+}
+
+func (x *CreateFlashSaleRequest) GetOperatorId() uint64 {
+	if x != nil {
+		return x.OperatorId
+	}
+	return 0
+}
+
+type CreateFlashSaleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0成功，40000参数错误，40300非图书发布者，40400图书不存在
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Sale          *FlashSale             `protobuf:"bytes,3,opt,name=sale,proto3" json:"sale,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateFlashSaleResponse) Reset() {
+	*x = CreateFlashSaleResponse{}
+	mi := &file_proto_order_v1_order_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateFlashSaleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateFlashSaleResponse) ProtoMessage() {}
+
+func (x *CreateFlashSaleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateFlashSaleResponse.ProtoReflect.Descriptor instead.
+func (*CreateFlashSaleResponse) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CreateFlashSaleResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *CreateFlashSaleResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CreateFlashSaleResponse) GetSale() *FlashSale {
+	if x != nil {
+		return x.Sale
+	}
+	return nil
+}
+
+// 查询秒杀活动
+type GetFlashSaleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SaleId        uint64                 `protobuf:"varint,1,opt,name=sale_id,json=saleId,proto3" json:"sale_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFlashSaleRequest) Reset() {
+	*x = GetFlashSaleRequest{}
+	mi := &file_proto_order_v1_order_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFlashSaleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFlashSaleRequest) ProtoMessage() {}
+
+func (x *GetFlashSaleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFlashSaleRequest.ProtoReflect.Descriptor instead.
+func (*GetFlashSaleRequest) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetFlashSaleRequest) GetSaleId() uint64 {
+	if x != nil {
+		return x.SaleId
+	}
+	return 0
+}
+
+type GetFlashSaleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Sale          *FlashSale             `protobuf:"bytes,3,opt,name=sale,proto3" json:"sale,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFlashSaleResponse) Reset() {
+	*x = GetFlashSaleResponse{}
+	mi := &file_proto_order_v1_order_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFlashSaleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFlashSaleResponse) ProtoMessage() {}
+
+func (x *GetFlashSaleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFlashSaleResponse.ProtoReflect.Descriptor instead.
+func (*GetFlashSaleResponse) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetFlashSaleResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *GetFlashSaleResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *GetFlashSaleResponse) GetSale() *FlashSale {
+	if x != nil {
+		return x.Sale
+	}
+	return nil
+}
+
+// 查询秒杀活动列表
+type ListFlashSalesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Page          uint32                 `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      uint32                 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFlashSalesRequest) Reset() {
+	*x = ListFlashSalesRequest{}
+	mi := &file_proto_order_v1_order_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFlashSalesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFlashSalesRequest) ProtoMessage() {}
+
+func (x *ListFlashSalesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFlashSalesRequest.ProtoReflect.Descriptor instead.
+func (*ListFlashSalesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListFlashSalesRequest) GetPage() uint32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListFlashSalesRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListFlashSalesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Sales         []*FlashSale           `protobuf:"bytes,3,rep,name=sales,proto3" json:"sales,omitempty"`
+	Total         uint32                 `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFlashSalesResponse) Reset() {
+	*x = ListFlashSalesResponse{}
+	mi := &file_proto_order_v1_order_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFlashSalesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFlashSalesResponse) ProtoMessage() {}
+
+func (x *ListFlashSalesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFlashSalesResponse.ProtoReflect.Descriptor instead.
+func (*ListFlashSalesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListFlashSalesResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ListFlashSalesResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ListFlashSalesResponse) GetSales() []*FlashSale {
+	if x != nil {
+		return x.Sales
+	}
+	return nil
+}
+
+func (x *ListFlashSalesResponse) GetTotal() uint32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// 秒杀抢购
+type PurchaseFlashSaleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SaleId        uint64                 `protobuf:"varint,1,opt,name=sale_id,json=saleId,proto3" json:"sale_id,omitempty"`
+	UserId        uint64                 `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Quantity      int32                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurchaseFlashSaleRequest) Reset() {
+	*x = PurchaseFlashSaleRequest{}
+	mi := &file_proto_order_v1_order_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurchaseFlashSaleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurchaseFlashSaleRequest) ProtoMessage() {}
+
+func (x *PurchaseFlashSaleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurchaseFlashSaleRequest.ProtoReflect.Descriptor instead.
+func (*PurchaseFlashSaleRequest) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *PurchaseFlashSaleRequest) GetSaleId() uint64 {
+	if x != nil {
+		return x.SaleId
+	}
+	return 0
+}
+
+func (x *PurchaseFlashSaleRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *PurchaseFlashSaleRequest) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type PurchaseFlashSaleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0已排队，40901已抢完，40902超过限购，40903未开始，40904已结束
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Ticket        string                 `protobuf:"bytes,3,opt,name=ticket,proto3" json:"ticket,omitempty"` // 排队凭证（用于查询结果）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurchaseFlashSaleResponse) Reset() {
+	*x = PurchaseFlashSaleResponse{}
+	mi := &file_proto_order_v1_order_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurchaseFlashSaleResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListUserOrdersResponse) ProtoMessage() {}
+func (*PurchaseFlashSaleResponse) ProtoMessage() {}
 
-func (x *ListUserOrdersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_order_v1_order_proto_msgTypes[8]
+func (x *PurchaseFlashSaleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -525,64 +1403,57 @@ func (x *ListUserOrdersResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListUserOrdersResponse.ProtoReflect.Descriptor instead.
-func (*ListUserOrdersResponse) Descriptor() ([]byte, []int) {
-	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use PurchaseFlashSaleResponse.ProtoReflect.Descriptor instead.
+func (*PurchaseFlashSaleResponse) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{22}
 }
 
-func (x *ListUserOrdersResponse) GetCode() uint32 {
+func (x *PurchaseFlashSaleResponse) GetCode() uint32 {
 	if x != nil {
 		return x.Code
 	}
 	return 0
 }
 
-func (x *ListUserOrdersResponse) GetMessage() string {
+func (x *PurchaseFlashSaleResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *ListUserOrdersResponse) GetOrders() []*Order {
-	if x != nil {
-		return x.Orders
-	}
-	return nil
-}
-
-func (x *ListUserOrdersResponse) GetTotal() uint32 {
+func (x *PurchaseFlashSaleResponse) GetTicket() string {
 	if x != nil {
-		return x.Total
+		return x.Ticket
 	}
-	return 0
+	return ""
 }
 
-// 取消订单
-type CancelOrderRequest struct {
+// 查询秒杀结果
+type GetFlashSaleResultRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	OrderId       uint64                 `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	SaleId        uint64                 `protobuf:"varint,1,opt,name=sale_id,json=saleId,proto3" json:"sale_id,omitempty"`
 	UserId        uint64                 `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // 用于权限校验
-	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`                // 取消原因
+	Ticket        string                 `protobuf:"bytes,3,opt,name=ticket,proto3" json:"ticket,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CancelOrderRequest) Reset() {
-	*x = CancelOrderRequest{}
-	mi := &file_proto_order_v1_order_proto_msgTypes[9]
+func (x *GetFlashSaleResultRequest) Reset() {
+	*x = GetFlashSaleResultRequest{}
+	mi := &file_proto_order_v1_order_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CancelOrderRequest) String() string {
+func (x *GetFlashSaleResultRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CancelOrderRequest) ProtoMessage() {}
+func (*GetFlashSaleResultRequest) ProtoMessage() {}
 
-func (x *CancelOrderRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_order_v1_order_proto_msgTypes[9]
+func (x *GetFlashSaleResultRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -593,55 +1464,58 @@ func (x *CancelOrderRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CancelOrderRequest.ProtoReflect.Descriptor instead.
-func (*CancelOrderRequest) Descriptor() ([]byte, []int) {
-	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use GetFlashSaleResultRequest.ProtoReflect.Descriptor instead.
+func (*GetFlashSaleResultRequest) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *CancelOrderRequest) GetOrderId() uint64 {
+func (x *GetFlashSaleResultRequest) GetSaleId() uint64 {
 	if x != nil {
-		return x.OrderId
+		return x.SaleId
 	}
 	return 0
 }
 
-func (x *CancelOrderRequest) GetUserId() uint64 {
+func (x *GetFlashSaleResultRequest) GetUserId() uint64 {
 	if x != nil {
 		return x.UserId
 	}
 	return 0
 }
 
-func (x *CancelOrderRequest) GetReason() string {
+func (x *GetFlashSaleResultRequest) GetTicket() string {
 	if x != nil {
-		return x.Reason
+		return x.Ticket
 	}
 	return ""
 }
 
-type CancelOrderResponse struct {
+type GetFlashSaleResultResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0成功，40400凭证不存在或已过期
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`                   // QUEUED排队中 / SUCCESS下单成功 / FAILED下单失败
+	OrderId       uint64                 `protobuf:"varint,4,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"` // 下单成功时的订单ID
+	Reason        string                 `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`                   // 下单失败原因
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CancelOrderResponse) Reset() {
-	*x = CancelOrderResponse{}
-	mi := &file_proto_order_v1_order_proto_msgTypes[10]
+func (x *GetFlashSaleResultResponse) Reset() {
+	*x = GetFlashSaleResultResponse{}
+	mi := &file_proto_order_v1_order_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CancelOrderResponse) String() string {
+func (x *GetFlashSaleResultResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CancelOrderResponse) ProtoMessage() {}
+func (*GetFlashSaleResultResponse) ProtoMessage() {}
 
-func (x *CancelOrderResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_order_v1_order_proto_msgTypes[10]
+func (x *GetFlashSaleResultResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -652,25 +1526,46 @@ func (x *CancelOrderResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CancelOrderResponse.ProtoReflect.Descriptor instead.
-func (*CancelOrderResponse) Descriptor() ([]byte, []int) {
-	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use GetFlashSaleResultResponse.ProtoReflect.Descriptor instead.
+func (*GetFlashSaleResultResponse) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *CancelOrderResponse) GetCode() uint32 {
+func (x *GetFlashSaleResultResponse) GetCode() uint32 {
 	if x != nil {
 		return x.Code
 	}
 	return 0
 }
 
-func (x *CancelOrderResponse) GetMessage() string {
+func (x *GetFlashSaleResultResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
+func (x *GetFlashSaleResultResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *GetFlashSaleResultResponse) GetOrderId() uint64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *GetFlashSaleResultResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
 // 订单信息
 type Order struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -688,7 +1583,7 @@ type Order struct {
 
 func (x *Order) Reset() {
 	*x = Order{}
-	mi := &file_proto_order_v1_order_proto_msgTypes[11]
+	mi := &file_proto_order_v1_order_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -700,7 +1595,7 @@ func (x *Order) String() string {
 func (*Order) ProtoMessage() {}
 
 func (x *Order) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_order_v1_order_proto_msgTypes[11]
+	mi := &file_proto_order_v1_order_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -713,7 +1608,7 @@ func (x *Order) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Order.ProtoReflect.Descriptor instead.
 func (*Order) Descriptor() ([]byte, []int) {
-	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{11}
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *Order) GetId() uint64 {
@@ -787,7 +1682,7 @@ type OrderItemDetail struct {
 
 func (x *OrderItemDetail) Reset() {
 	*x = OrderItemDetail{}
-	mi := &file_proto_order_v1_order_proto_msgTypes[12]
+	mi := &file_proto_order_v1_order_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -799,7 +1694,7 @@ func (x *OrderItemDetail) String() string {
 func (*OrderItemDetail) ProtoMessage() {}
 
 func (x *OrderItemDetail) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_order_v1_order_proto_msgTypes[12]
+	mi := &file_proto_order_v1_order_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -812,7 +1707,7 @@ func (x *OrderItemDetail) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use OrderItemDetail.ProtoReflect.Descriptor instead.
 func (*OrderItemDetail) Descriptor() ([]byte, []int) {
-	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{12}
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *OrderItemDetail) GetId() uint64 {
@@ -857,6 +1752,256 @@ func (x *OrderItemDetail) GetPrice() int64 {
 	return 0
 }
 
+// 卡住的补偿记录
+type StuckCompensation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	SagaId        string                 `protobuf:"bytes,2,opt,name=saga_id,json=sagaId,proto3" json:"saga_id,omitempty"`       // Saga实例ID
+	SagaName      string                 `protobuf:"bytes,3,opt,name=saga_name,json=sagaName,proto3" json:"saga_name,omitempty"` // Saga类型（如create_order）
+	StepIndex     int32                  `protobuf:"varint,4,opt,name=step_index,json=stepIndex,proto3" json:"step_index,omitempty"`
+	StepName      string                 `protobuf:"bytes,5,opt,name=step_name,json=stepName,proto3" json:"step_name,omitempty"`    // 步骤名称（如扣减库存）
+	Data          string                 `protobuf:"bytes,6,opt,name=data,proto3" json:"data,omitempty"`                            // 补偿数据（JSON）
+	Attempts      int32                  `protobuf:"varint,7,opt,name=attempts,proto3" json:"attempts,omitempty"`                   // 累计尝试次数
+	LastError     string                 `protobuf:"bytes,8,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"` // 最后一次错误
+	Status        string                 `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"`                        // PENDING/RESOLVED
+	CreatedAt     int64                  `protobuf:"varint,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     int64                  `protobuf:"varint,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StuckCompensation) Reset() {
+	*x = StuckCompensation{}
+	mi := &file_proto_order_v1_order_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StuckCompensation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StuckCompensation) ProtoMessage() {}
+
+func (x *StuckCompensation) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StuckCompensation.ProtoReflect.Descriptor instead.
+func (*StuckCompensation) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *StuckCompensation) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *StuckCompensation) GetSagaId() string {
+	if x != nil {
+		return x.SagaId
+	}
+	return ""
+}
+
+func (x *StuckCompensation) GetSagaName() string {
+	if x != nil {
+		return x.SagaName
+	}
+	return ""
+}
+
+func (x *StuckCompensation) GetStepIndex() int32 {
+	if x != nil {
+		return x.StepIndex
+	}
+	return 0
+}
+
+func (x *StuckCompensation) GetStepName() string {
+	if x != nil {
+		return x.StepName
+	}
+	return ""
+}
+
+func (x *StuckCompensation) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *StuckCompensation) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *StuckCompensation) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *StuckCompensation) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *StuckCompensation) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *StuckCompensation) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+// 秒杀活动
+type FlashSale struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	BookId         uint64                 `protobuf:"varint,2,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	BookTitle      string                 `protobuf:"bytes,3,opt,name=book_title,json=bookTitle,proto3" json:"book_title,omitempty"`
+	Price          int64                  `protobuf:"varint,4,opt,name=price,proto3" json:"price,omitempty"`                                         // 秒杀价（分）
+	OriginalPrice  int64                  `protobuf:"varint,5,opt,name=original_price,json=originalPrice,proto3" json:"original_price,omitempty"`    // 原价（分）
+	Stock          int32                  `protobuf:"varint,6,opt,name=stock,proto3" json:"stock,omitempty"`                                         // 活动库存
+	RemainingStock int32                  `protobuf:"varint,7,opt,name=remaining_stock,json=remainingStock,proto3" json:"remaining_stock,omitempty"` // 剩余活动库存（预热前等于活动库存）
+	PerUserLimit   int32                  `protobuf:"varint,8,opt,name=per_user_limit,json=perUserLimit,proto3" json:"per_user_limit,omitempty"`     // 每人限购数量
+	StartAt        int64                  `protobuf:"varint,9,opt,name=start_at,json=startAt,proto3" json:"start_at,omitempty"`
+	EndAt          int64                  `protobuf:"varint,10,opt,name=end_at,json=endAt,proto3" json:"end_at,omitempty"`
+	Phase          string                 `protobuf:"bytes,11,opt,name=phase,proto3" json:"phase,omitempty"` // UPCOMING未开始 / ONGOING进行中 / ENDED已结束 / CANCELLED已取消
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *FlashSale) Reset() {
+	*x = FlashSale{}
+	mi := &file_proto_order_v1_order_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlashSale) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlashSale) ProtoMessage() {}
+
+func (x *FlashSale) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_v1_order_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlashSale.ProtoReflect.Descriptor instead.
+func (*FlashSale) Descriptor() ([]byte, []int) {
+	return file_proto_order_v1_order_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *FlashSale) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *FlashSale) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *FlashSale) GetBookTitle() string {
+	if x != nil {
+		return x.BookTitle
+	}
+	return ""
+}
+
+func (x *FlashSale) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *FlashSale) GetOriginalPrice() int64 {
+	if x != nil {
+		return x.OriginalPrice
+	}
+	return 0
+}
+
+func (x *FlashSale) GetStock() int32 {
+	if x != nil {
+		return x.Stock
+	}
+	return 0
+}
+
+func (x *FlashSale) GetRemainingStock() int32 {
+	if x != nil {
+		return x.RemainingStock
+	}
+	return 0
+}
+
+func (x *FlashSale) GetPerUserLimit() int32 {
+	if x != nil {
+		return x.PerUserLimit
+	}
+	return 0
+}
+
+func (x *FlashSale) GetStartAt() int64 {
+	if x != nil {
+		return x.StartAt
+	}
+	return 0
+}
+
+func (x *FlashSale) GetEndAt() int64 {
+	if x != nil {
+		return x.EndAt
+	}
+	return 0
+}
+
+func (x *FlashSale) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
 var File_proto_order_v1_order_proto protoreflect.FileDescriptor
 
 const file_proto_order_v1_order_proto_rawDesc = "" +
@@ -886,24 +2031,88 @@ const file_proto_order_v1_order_proto_rawDesc = "" +
 	"\x10GetOrderResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12%\n" +
-	"\x05order\x18\x03 \x01(\v2\x0f.order.v1.OrderR\x05order\"y\n" +
+	"\x05order\x18\x03 \x01(\v2\x0f.order.v1.OrderR\x05order\"\x91\x01\n" +
 	"\x15ListUserOrdersRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x12\n" +
 	"\x04page\x18\x02 \x01(\rR\x04page\x12\x1b\n" +
 	"\tpage_size\x18\x03 \x01(\rR\bpageSize\x12\x16\n" +
-	"\x06status\x18\x04 \x01(\x05R\x06status\"\x85\x01\n" +
+	"\x06status\x18\x04 \x01(\x05R\x06status\x12\x16\n" +
+	"\x06cursor\x18\x05 \x01(\tR\x06cursor\"\xa6\x01\n" +
 	"\x16ListUserOrdersResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12'\n" +
 	"\x06orders\x18\x03 \x03(\v2\x0f.order.v1.OrderR\x06orders\x12\x14\n" +
-	"\x05total\x18\x04 \x01(\rR\x05total\"`\n" +
+	"\x05total\x18\x04 \x01(\rR\x05total\x12\x1f\n" +
+	"\vnext_cursor\x18\x05 \x01(\tR\n" +
+	"nextCursor\"`\n" +
 	"\x12CancelOrderRequest\x12\x19\n" +
 	"\border_id\x18\x01 \x01(\x04R\aorderId\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\x04R\x06userId\x12\x16\n" +
 	"\x06reason\x18\x03 \x01(\tR\x06reason\"C\n" +
 	"\x13CancelOrderResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"\xe8\x01\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x81\x01\n" +
+	"\x1dListStuckCompensationsRequest\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x17\n" +
+	"\asaga_id\x18\x02 \x01(\tR\x06sagaId\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\rR\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\rR\bpageSize\"\x97\x01\n" +
+	"\x1eListStuckCompensationsResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x121\n" +
+	"\x05items\x18\x03 \x03(\v2\x1b.order.v1.StuckCompensationR\x05items\x12\x14\n" +
+	"\x05total\x18\x04 \x01(\rR\x05total\",\n" +
+	"\x1aRedriveCompensationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"|\n" +
+	"\x1bRedriveCompensationResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12/\n" +
+	"\x04item\x18\x03 \x01(\v2\x1b.order.v1.StuckCompensationR\x04item\"\xd6\x01\n" +
+	"\x16CreateFlashSaleRequest\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x04R\x06bookId\x12\x14\n" +
+	"\x05price\x18\x02 \x01(\x03R\x05price\x12\x14\n" +
+	"\x05stock\x18\x03 \x01(\x05R\x05stock\x12$\n" +
+	"\x0eper_user_limit\x18\x04 \x01(\x05R\fperUserLimit\x12\x19\n" +
+	"\bstart_at\x18\x05 \x01(\x03R\astartAt\x12\x15\n" +
+	"\x06end_at\x18\x06 \x01(\x03R\x05endAt\x12\x1f\n" +
+	"\voperator_id\x18\a \x01(\x04R\n" +
+	"operatorId\"p\n" +
+	"\x17CreateFlashSaleResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12'\n" +
+	"\x04sale\x18\x03 \x01(\v2\x13.order.v1.FlashSaleR\x04sale\".\n" +
+	"\x13GetFlashSaleRequest\x12\x17\n" +
+	"\asale_id\x18\x01 \x01(\x04R\x06saleId\"m\n" +
+	"\x14GetFlashSaleResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12'\n" +
+	"\x04sale\x18\x03 \x01(\v2\x13.order.v1.FlashSaleR\x04sale\"H\n" +
+	"\x15ListFlashSalesRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\rR\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\rR\bpageSize\"\x87\x01\n" +
+	"\x16ListFlashSalesResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12)\n" +
+	"\x05sales\x18\x03 \x03(\v2\x13.order.v1.FlashSaleR\x05sales\x12\x14\n" +
+	"\x05total\x18\x04 \x01(\rR\x05total\"h\n" +
+	"\x18PurchaseFlashSaleRequest\x12\x17\n" +
+	"\asale_id\x18\x01 \x01(\x04R\x06saleId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x04R\x06userId\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x05R\bquantity\"a\n" +
+	"\x19PurchaseFlashSaleResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
+	"\x06ticket\x18\x03 \x01(\tR\x06ticket\"e\n" +
+	"\x19GetFlashSaleResultRequest\x12\x17\n" +
+	"\asale_id\x18\x01 \x01(\x04R\x06saleId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x04R\x06userId\x12\x16\n" +
+	"\x06ticket\x18\x03 \x01(\tR\x06ticket\"\x95\x01\n" +
+	"\x1aGetFlashSaleResultResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x19\n" +
+	"\border_id\x18\x04 \x01(\x04R\aorderId\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\"\xe8\x01\n" +
 	"\x05Order\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x19\n" +
 	"\border_no\x18\x02 \x01(\tR\aorderNo\x12\x17\n" +
@@ -922,13 +2131,51 @@ const file_proto_order_v1_order_proto_rawDesc = "" +
 	"\n" +
 	"book_title\x18\x04 \x01(\tR\tbookTitle\x12\x1a\n" +
 	"\bquantity\x18\x05 \x01(\x05R\bquantity\x12\x14\n" +
-	"\x05price\x18\x06 \x01(\x03R\x05price2\x9c\x03\n" +
+	"\x05price\x18\x06 \x01(\x03R\x05price\"\xba\x02\n" +
+	"\x11StuckCompensation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x17\n" +
+	"\asaga_id\x18\x02 \x01(\tR\x06sagaId\x12\x1b\n" +
+	"\tsaga_name\x18\x03 \x01(\tR\bsagaName\x12\x1d\n" +
+	"\n" +
+	"step_index\x18\x04 \x01(\x05R\tstepIndex\x12\x1b\n" +
+	"\tstep_name\x18\x05 \x01(\tR\bstepName\x12\x12\n" +
+	"\x04data\x18\x06 \x01(\tR\x04data\x12\x1a\n" +
+	"\battempts\x18\a \x01(\x05R\battempts\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\b \x01(\tR\tlastError\x12\x16\n" +
+	"\x06status\x18\t \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\v \x01(\x03R\tupdatedAt\"\xbd\x02\n" +
+	"\tFlashSale\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x17\n" +
+	"\abook_id\x18\x02 \x01(\x04R\x06bookId\x12\x1d\n" +
+	"\n" +
+	"book_title\x18\x03 \x01(\tR\tbookTitle\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\x03R\x05price\x12%\n" +
+	"\x0eoriginal_price\x18\x05 \x01(\x03R\roriginalPrice\x12\x14\n" +
+	"\x05stock\x18\x06 \x01(\x05R\x05stock\x12'\n" +
+	"\x0fremaining_stock\x18\a \x01(\x05R\x0eremainingStock\x12$\n" +
+	"\x0eper_user_limit\x18\b \x01(\x05R\fperUserLimit\x12\x19\n" +
+	"\bstart_at\x18\t \x01(\x03R\astartAt\x12\x15\n" +
+	"\x06end_at\x18\n" +
+	" \x01(\x03R\x05endAt\x12\x14\n" +
+	"\x05phase\x18\v \x01(\tR\x05phase2\xa8\b\n" +
 	"\fOrderService\x12J\n" +
 	"\vCreateOrder\x12\x1c.order.v1.CreateOrderRequest\x1a\x1d.order.v1.CreateOrderResponse\x12\\\n" +
 	"\x11UpdateOrderStatus\x12\".order.v1.UpdateOrderStatusRequest\x1a#.order.v1.UpdateOrderStatusResponse\x12A\n" +
 	"\bGetOrder\x12\x19.order.v1.GetOrderRequest\x1a\x1a.order.v1.GetOrderResponse\x12S\n" +
 	"\x0eListUserOrders\x12\x1f.order.v1.ListUserOrdersRequest\x1a .order.v1.ListUserOrdersResponse\x12J\n" +
-	"\vCancelOrder\x12\x1c.order.v1.CancelOrderRequest\x1a\x1d.order.v1.CancelOrderResponseB5Z3github.com/xiebiao/bookstore/proto/order/v1;orderv1b\x06proto3"
+	"\vCancelOrder\x12\x1c.order.v1.CancelOrderRequest\x1a\x1d.order.v1.CancelOrderResponse\x12V\n" +
+	"\x0fCreateFlashSale\x12 .order.v1.CreateFlashSaleRequest\x1a!.order.v1.CreateFlashSaleResponse\x12M\n" +
+	"\fGetFlashSale\x12\x1d.order.v1.GetFlashSaleRequest\x1a\x1e.order.v1.GetFlashSaleResponse\x12S\n" +
+	"\x0eListFlashSales\x12\x1f.order.v1.ListFlashSalesRequest\x1a .order.v1.ListFlashSalesResponse\x12\\\n" +
+	"\x11PurchaseFlashSale\x12\".order.v1.PurchaseFlashSaleRequest\x1a#.order.v1.PurchaseFlashSaleResponse\x12_\n" +
+	"\x12GetFlashSaleResult\x12#.order.v1.GetFlashSaleResultRequest\x1a$.order.v1.GetFlashSaleResultResponse\x12k\n" +
+	"\x16ListStuckCompensations\x12'.order.v1.ListStuckCompensationsRequest\x1a(.order.v1.ListStuckCompensationsResponse\x12b\n" +
+	"\x13RedriveCompensation\x12$.order.v1.RedriveCompensationRequest\x1a%.order.v1.RedriveCompensationResponseB5Z3github.com/xiebiao/bookstore/proto/order/v1;orderv1b\x06proto3"
 
 var (
 	file_proto_order_v1_order_proto_rawDescOnce sync.Once
@@ -942,42 +2189,77 @@ func file_proto_order_v1_order_proto_rawDescGZIP() []byte {
 	return file_proto_order_v1_order_proto_rawDescData
 }
 
-var file_proto_order_v1_order_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_proto_order_v1_order_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
 var file_proto_order_v1_order_proto_goTypes = []any{
-	(*CreateOrderRequest)(nil),        // 0: order.v1.CreateOrderRequest
-	(*CreateOrderResponse)(nil),       // 1: order.v1.CreateOrderResponse
-	(*OrderItem)(nil),                 // 2: order.v1.OrderItem
-	(*UpdateOrderStatusRequest)(nil),  // 3: order.v1.UpdateOrderStatusRequest
-	(*UpdateOrderStatusResponse)(nil), // 4: order.v1.UpdateOrderStatusResponse
-	(*GetOrderRequest)(nil),           // 5: order.v1.GetOrderRequest
-	(*GetOrderResponse)(nil),          // 6: order.v1.GetOrderResponse
-	(*ListUserOrdersRequest)(nil),     // 7: order.v1.ListUserOrdersRequest
-	(*ListUserOrdersResponse)(nil),    // 8: order.v1.ListUserOrdersResponse
-	(*CancelOrderRequest)(nil),        // 9: order.v1.CancelOrderRequest
-	(*CancelOrderResponse)(nil),       // 10: order.v1.CancelOrderResponse
-	(*Order)(nil),                     // 11: order.v1.Order
-	(*OrderItemDetail)(nil),           // 12: order.v1.OrderItemDetail
+	(*CreateOrderRequest)(nil),             // 0: order.v1.CreateOrderRequest
+	(*CreateOrderResponse)(nil),            // 1: order.v1.CreateOrderResponse
+	(*OrderItem)(nil),                      // 2: order.v1.OrderItem
+	(*UpdateOrderStatusRequest)(nil),       // 3: order.v1.UpdateOrderStatusRequest
+	(*UpdateOrderStatusResponse)(nil),      // 4: order.v1.UpdateOrderStatusResponse
+	(*GetOrderRequest)(nil),                // 5: order.v1.GetOrderRequest
+	(*GetOrderResponse)(nil),               // 6: order.v1.GetOrderResponse
+	(*ListUserOrdersRequest)(nil),          // 7: order.v1.ListUserOrdersRequest
+	(*ListUserOrdersResponse)(nil),         // 8: order.v1.ListUserOrdersResponse
+	(*CancelOrderRequest)(nil),             // 9: order.v1.CancelOrderRequest
+	(*CancelOrderResponse)(nil),            // 10: order.v1.CancelOrderResponse
+	(*ListStuckCompensationsRequest)(nil),  // 11: order.v1.ListStuckCompensationsRequest
+	(*ListStuckCompensationsResponse)(nil), // 12: order.v1.ListStuckCompensationsResponse
+	(*RedriveCompensationRequest)(nil),     // 13: order.v1.RedriveCompensationRequest
+	(*RedriveCompensationResponse)(nil),    // 14: order.v1.RedriveCompensationResponse
+	(*CreateFlashSaleRequest)(nil),         // 15: order.v1.CreateFlashSaleRequest
+	(*CreateFlashSaleResponse)(nil),        // 16: order.v1.CreateFlashSaleResponse
+	(*GetFlashSaleRequest)(nil),            // 17: order.v1.GetFlashSaleRequest
+	(*GetFlashSaleResponse)(nil),           // 18: order.v1.GetFlashSaleResponse
+	(*ListFlashSalesRequest)(nil),          // 19: order.v1.ListFlashSalesRequest
+	(*ListFlashSalesResponse)(nil),         // 20: order.v1.ListFlashSalesResponse
+	(*PurchaseFlashSaleRequest)(nil),       // 21: order.v1.PurchaseFlashSaleRequest
+	(*PurchaseFlashSaleResponse)(nil),      // 22: order.v1.PurchaseFlashSaleResponse
+	(*GetFlashSaleResultRequest)(nil),      // 23: order.v1.GetFlashSaleResultRequest
+	(*GetFlashSaleResultResponse)(nil),     // 24: order.v1.GetFlashSaleResultResponse
+	(*Order)(nil),                          // 25: order.v1.Order
+	(*OrderItemDetail)(nil),                // 26: order.v1.OrderItemDetail
+	(*StuckCompensation)(nil),              // 27: order.v1.StuckCompensation
+	(*FlashSale)(nil),                      // 28: order.v1.FlashSale
 }
 var file_proto_order_v1_order_proto_depIdxs = []int32{
 	2,  // 0: order.v1.CreateOrderRequest.items:type_name -> order.v1.OrderItem
-	11, // 1: order.v1.GetOrderResponse.order:type_name -> order.v1.Order
-	11, // 2: order.v1.ListUserOrdersResponse.orders:type_name -> order.v1.Order
-	12, // 3: order.v1.Order.items:type_name -> order.v1.OrderItemDetail
-	0,  // 4: order.v1.OrderService.CreateOrder:input_type -> order.v1.CreateOrderRequest
-	3,  // 5: order.v1.OrderService.UpdateOrderStatus:input_type -> order.v1.UpdateOrderStatusRequest
-	5,  // 6: order.v1.OrderService.GetOrder:input_type -> order.v1.GetOrderRequest
-	7,  // 7: order.v1.OrderService.ListUserOrders:input_type -> order.v1.ListUserOrdersRequest
-	9,  // 8: order.v1.OrderService.CancelOrder:input_type -> order.v1.CancelOrderRequest
-	1,  // 9: order.v1.OrderService.CreateOrder:output_type -> order.v1.CreateOrderResponse
-	4,  // 10: order.v1.OrderService.UpdateOrderStatus:output_type -> order.v1.UpdateOrderStatusResponse
-	6,  // 11: order.v1.OrderService.GetOrder:output_type -> order.v1.GetOrderResponse
-	8,  // 12: order.v1.OrderService.ListUserOrders:output_type -> order.v1.ListUserOrdersResponse
-	10, // 13: order.v1.OrderService.CancelOrder:output_type -> order.v1.CancelOrderResponse
-	9,  // [9:14] is the sub-list for method output_type
-	4,  // [4:9] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	25, // 1: order.v1.GetOrderResponse.order:type_name -> order.v1.Order
+	25, // 2: order.v1.ListUserOrdersResponse.orders:type_name -> order.v1.Order
+	27, // 3: order.v1.ListStuckCompensationsResponse.items:type_name -> order.v1.StuckCompensation
+	27, // 4: order.v1.RedriveCompensationResponse.item:type_name -> order.v1.StuckCompensation
+	28, // 5: order.v1.CreateFlashSaleResponse.sale:type_name -> order.v1.FlashSale
+	28, // 6: order.v1.GetFlashSaleResponse.sale:type_name -> order.v1.FlashSale
+	28, // 7: order.v1.ListFlashSalesResponse.sales:type_name -> order.v1.FlashSale
+	26, // 8: order.v1.Order.items:type_name -> order.v1.OrderItemDetail
+	0,  // 9: order.v1.OrderService.CreateOrder:input_type -> order.v1.CreateOrderRequest
+	3,  // 10: order.v1.OrderService.UpdateOrderStatus:input_type -> order.v1.UpdateOrderStatusRequest
+	5,  // 11: order.v1.OrderService.GetOrder:input_type -> order.v1.GetOrderRequest
+	7,  // 12: order.v1.OrderService.ListUserOrders:input_type -> order.v1.ListUserOrdersRequest
+	9,  // 13: order.v1.OrderService.CancelOrder:input_type -> order.v1.CancelOrderRequest
+	15, // 14: order.v1.OrderService.CreateFlashSale:input_type -> order.v1.CreateFlashSaleRequest
+	17, // 15: order.v1.OrderService.GetFlashSale:input_type -> order.v1.GetFlashSaleRequest
+	19, // 16: order.v1.OrderService.ListFlashSales:input_type -> order.v1.ListFlashSalesRequest
+	21, // 17: order.v1.OrderService.PurchaseFlashSale:input_type -> order.v1.PurchaseFlashSaleRequest
+	23, // 18: order.v1.OrderService.GetFlashSaleResult:input_type -> order.v1.GetFlashSaleResultRequest
+	11, // 19: order.v1.OrderService.ListStuckCompensations:input_type -> order.v1.ListStuckCompensationsRequest
+	13, // 20: order.v1.OrderService.RedriveCompensation:input_type -> order.v1.RedriveCompensationRequest
+	1,  // 21: order.v1.OrderService.CreateOrder:output_type -> order.v1.CreateOrderResponse
+	4,  // 22: order.v1.OrderService.UpdateOrderStatus:output_type -> order.v1.UpdateOrderStatusResponse
+	6,  // 23: order.v1.OrderService.GetOrder:output_type -> order.v1.GetOrderResponse
+	8,  // 24: order.v1.OrderService.ListUserOrders:output_type -> order.v1.ListUserOrdersResponse
+	10, // 25: order.v1.OrderService.CancelOrder:output_type -> order.v1.CancelOrderResponse
+	16, // 26: order.v1.OrderService.CreateFlashSale:output_type -> order.v1.CreateFlashSaleResponse
+	18, // 27: order.v1.OrderService.GetFlashSale:output_type -> order.v1.GetFlashSaleResponse
+	20, // 28: order.v1.OrderService.ListFlashSales:output_type -> order.v1.ListFlashSalesResponse
+	22, // 29: order.v1.OrderService.PurchaseFlashSale:output_type -> order.v1.PurchaseFlashSaleResponse
+	24, // 30: order.v1.OrderService.GetFlashSaleResult:output_type -> order.v1.GetFlashSaleResultResponse
+	12, // 31: order.v1.OrderService.ListStuckCompensations:output_type -> order.v1.ListStuckCompensationsResponse
+	14, // 32: order.v1.OrderService.RedriveCompensation:output_type -> order.v1.RedriveCompensationResponse
+	21, // [21:33] is the sub-list for method output_type
+	9,  // [9:21] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_proto_order_v1_order_proto_init() }
@@ -991,7 +2273,7 @@ func file_proto_order_v1_order_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_order_v1_order_proto_rawDesc), len(file_proto_order_v1_order_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   13,
+			NumMessages:   29,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
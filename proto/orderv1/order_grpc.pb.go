@@ -19,11 +19,18 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	OrderService_CreateOrder_FullMethodName       = "/order.v1.OrderService/CreateOrder"
-	OrderService_UpdateOrderStatus_FullMethodName = "/order.v1.OrderService/UpdateOrderStatus"
-	OrderService_GetOrder_FullMethodName          = "/order.v1.OrderService/GetOrder"
-	OrderService_ListUserOrders_FullMethodName    = "/order.v1.OrderService/ListUserOrders"
-	OrderService_CancelOrder_FullMethodName       = "/order.v1.OrderService/CancelOrder"
+	OrderService_CreateOrder_FullMethodName            = "/order.v1.OrderService/CreateOrder"
+	OrderService_UpdateOrderStatus_FullMethodName      = "/order.v1.OrderService/UpdateOrderStatus"
+	OrderService_GetOrder_FullMethodName               = "/order.v1.OrderService/GetOrder"
+	OrderService_ListUserOrders_FullMethodName         = "/order.v1.OrderService/ListUserOrders"
+	OrderService_CancelOrder_FullMethodName            = "/order.v1.OrderService/CancelOrder"
+	OrderService_CreateFlashSale_FullMethodName        = "/order.v1.OrderService/CreateFlashSale"
+	OrderService_GetFlashSale_FullMethodName           = "/order.v1.OrderService/GetFlashSale"
+	OrderService_ListFlashSales_FullMethodName         = "/order.v1.OrderService/ListFlashSales"
+	OrderService_PurchaseFlashSale_FullMethodName      = "/order.v1.OrderService/PurchaseFlashSale"
+	OrderService_GetFlashSaleResult_FullMethodName     = "/order.v1.OrderService/GetFlashSaleResult"
+	OrderService_ListStuckCompensations_FullMethodName = "/order.v1.OrderService/ListStuckCompensations"
+	OrderService_RedriveCompensation_FullMethodName    = "/order.v1.OrderService/RedriveCompensation"
 )
 
 // OrderServiceClient is the client API for OrderService service.
@@ -41,7 +48,7 @@ type OrderServiceClient interface {
 	UpdateOrderStatus(ctx context.Context, in *UpdateOrderStatusRequest, opts ...grpc.CallOption) (*UpdateOrderStatusResponse, error)
 	// 查询订单详情
 	GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*GetOrderResponse, error)
-	// 查询用户订单列表
+	// 查询用户订单列表（按创建时间倒序，支持页码分页和游标分页）
 	ListUserOrders(ctx context.Context, in *ListUserOrdersRequest, opts ...grpc.CallOption) (*ListUserOrdersResponse, error)
 	// 取消订单
 	// 教学重点：
@@ -49,6 +56,25 @@ type OrderServiceClient interface {
 	// 2. 调用inventory-service释放库存
 	// 3. 如果已支付，调用payment-service退款
 	CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*CancelOrderResponse, error)
+	// 创建秒杀活动（只有图书的发布者可以创建）
+	// 教学重点：活动库存在开始前预热到Redis，秒杀期间不访问MySQL
+	CreateFlashSale(ctx context.Context, in *CreateFlashSaleRequest, opts ...grpc.CallOption) (*CreateFlashSaleResponse, error)
+	// 查询秒杀活动（含剩余活动库存）
+	GetFlashSale(ctx context.Context, in *GetFlashSaleRequest, opts ...grpc.CallOption) (*GetFlashSaleResponse, error)
+	// 查询未结束的秒杀活动列表
+	ListFlashSales(ctx context.Context, in *ListFlashSalesRequest, opts ...grpc.CallOption) (*ListFlashSalesResponse, error)
+	// 秒杀抢购
+	// 教学重点：
+	// 1. Redis Lua原子判定活动时间、每人限购、活动库存
+	// 2. 抢到名额后进入MQ排队，订单异步创建，立即返回排队凭证
+	PurchaseFlashSale(ctx context.Context, in *PurchaseFlashSaleRequest, opts ...grpc.CallOption) (*PurchaseFlashSaleResponse, error)
+	// 查询秒杀结果（凭排队凭证轮询）
+	GetFlashSaleResult(ctx context.Context, in *GetFlashSaleResultRequest, opts ...grpc.CallOption) (*GetFlashSaleResultResponse, error)
+	// 查询卡住的补偿（Saga补偿重试耗尽后写入的记录）
+	// 教学重点：补偿失败不能只打日志，必须可检索、可重新驱动
+	ListStuckCompensations(ctx context.Context, in *ListStuckCompensationsRequest, opts ...grpc.CallOption) (*ListStuckCompensationsResponse, error)
+	// 重新驱动卡住的补偿（下游恢复后由运维触发，补偿函数幂等，可重复调用）
+	RedriveCompensation(ctx context.Context, in *RedriveCompensationRequest, opts ...grpc.CallOption) (*RedriveCompensationResponse, error)
 }
 
 type orderServiceClient struct {
@@ -109,6 +135,76 @@ func (c *orderServiceClient) CancelOrder(ctx context.Context, in *CancelOrderReq
 	return out, nil
 }
 
+func (c *orderServiceClient) CreateFlashSale(ctx context.Context, in *CreateFlashSaleRequest, opts ...grpc.CallOption) (*CreateFlashSaleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateFlashSaleResponse)
+	err := c.cc.Invoke(ctx, OrderService_CreateFlashSale_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetFlashSale(ctx context.Context, in *GetFlashSaleRequest, opts ...grpc.CallOption) (*GetFlashSaleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetFlashSaleResponse)
+	err := c.cc.Invoke(ctx, OrderService_GetFlashSale_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ListFlashSales(ctx context.Context, in *ListFlashSalesRequest, opts ...grpc.CallOption) (*ListFlashSalesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFlashSalesResponse)
+	err := c.cc.Invoke(ctx, OrderService_ListFlashSales_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) PurchaseFlashSale(ctx context.Context, in *PurchaseFlashSaleRequest, opts ...grpc.CallOption) (*PurchaseFlashSaleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurchaseFlashSaleResponse)
+	err := c.cc.Invoke(ctx, OrderService_PurchaseFlashSale_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetFlashSaleResult(ctx context.Context, in *GetFlashSaleResultRequest, opts ...grpc.CallOption) (*GetFlashSaleResultResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetFlashSaleResultResponse)
+	err := c.cc.Invoke(ctx, OrderService_GetFlashSaleResult_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ListStuckCompensations(ctx context.Context, in *ListStuckCompensationsRequest, opts ...grpc.CallOption) (*ListStuckCompensationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListStuckCompensationsResponse)
+	err := c.cc.Invoke(ctx, OrderService_ListStuckCompensations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) RedriveCompensation(ctx context.Context, in *RedriveCompensationRequest, opts ...grpc.CallOption) (*RedriveCompensationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RedriveCompensationResponse)
+	err := c.cc.Invoke(ctx, OrderService_RedriveCompensation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // OrderServiceServer is the server API for OrderService service.
 // All implementations must embed UnimplementedOrderServiceServer
 // for forward compatibility.
@@ -124,7 +220,7 @@ type OrderServiceServer interface {
 	UpdateOrderStatus(context.Context, *UpdateOrderStatusRequest) (*UpdateOrderStatusResponse, error)
 	// 查询订单详情
 	GetOrder(context.Context, *GetOrderRequest) (*GetOrderResponse, error)
-	// 查询用户订单列表
+	// 查询用户订单列表（按创建时间倒序，支持页码分页和游标分页）
 	ListUserOrders(context.Context, *ListUserOrdersRequest) (*ListUserOrdersResponse, error)
 	// 取消订单
 	// 教学重点：
@@ -132,6 +228,25 @@ type OrderServiceServer interface {
 	// 2. 调用inventory-service释放库存
 	// 3. 如果已支付，调用payment-service退款
 	CancelOrder(context.Context, *CancelOrderRequest) (*CancelOrderResponse, error)
+	// 创建秒杀活动（只有图书的发布者可以创建）
+	// 教学重点：活动库存在开始前预热到Redis，秒杀期间不访问MySQL
+	CreateFlashSale(context.Context, *CreateFlashSaleRequest) (*CreateFlashSaleResponse, error)
+	// 查询秒杀活动（含剩余活动库存）
+	GetFlashSale(context.Context, *GetFlashSaleRequest) (*GetFlashSaleResponse, error)
+	// 查询未结束的秒杀活动列表
+	ListFlashSales(context.Context, *ListFlashSalesRequest) (*ListFlashSalesResponse, error)
+	// 秒杀抢购
+	// 教学重点：
+	// 1. Redis Lua原子判定活动时间、每人限购、活动库存
+	// 2. 抢到名额后进入MQ排队，订单异步创建，立即返回排队凭证
+	PurchaseFlashSale(context.Context, *PurchaseFlashSaleRequest) (*PurchaseFlashSaleResponse, error)
+	// 查询秒杀结果（凭排队凭证轮询）
+	GetFlashSaleResult(context.Context, *GetFlashSaleResultRequest) (*GetFlashSaleResultResponse, error)
+	// 查询卡住的补偿（Saga补偿重试耗尽后写入的记录）
+	// 教学重点：补偿失败不能只打日志，必须可检索、可重新驱动
+	ListStuckCompensations(context.Context, *ListStuckCompensationsRequest) (*ListStuckCompensationsResponse, error)
+	// 重新驱动卡住的补偿（下游恢复后由运维触发，补偿函数幂等，可重复调用）
+	RedriveCompensation(context.Context, *RedriveCompensationRequest) (*RedriveCompensationResponse, error)
 	mustEmbedUnimplementedOrderServiceServer()
 }
 
@@ -157,6 +272,27 @@ func (UnimplementedOrderServiceServer) ListUserOrders(context.Context, *ListUser
 func (UnimplementedOrderServiceServer) CancelOrder(context.Context, *CancelOrderRequest) (*CancelOrderResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CancelOrder not implemented")
 }
+func (UnimplementedOrderServiceServer) CreateFlashSale(context.Context, *CreateFlashSaleRequest) (*CreateFlashSaleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateFlashSale not implemented")
+}
+func (UnimplementedOrderServiceServer) GetFlashSale(context.Context, *GetFlashSaleRequest) (*GetFlashSaleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFlashSale not implemented")
+}
+func (UnimplementedOrderServiceServer) ListFlashSales(context.Context, *ListFlashSalesRequest) (*ListFlashSalesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFlashSales not implemented")
+}
+func (UnimplementedOrderServiceServer) PurchaseFlashSale(context.Context, *PurchaseFlashSaleRequest) (*PurchaseFlashSaleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurchaseFlashSale not implemented")
+}
+func (UnimplementedOrderServiceServer) GetFlashSaleResult(context.Context, *GetFlashSaleResultRequest) (*GetFlashSaleResultResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFlashSaleResult not implemented")
+}
+func (UnimplementedOrderServiceServer) ListStuckCompensations(context.Context, *ListStuckCompensationsRequest) (*ListStuckCompensationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStuckCompensations not implemented")
+}
+func (UnimplementedOrderServiceServer) RedriveCompensation(context.Context, *RedriveCompensationRequest) (*RedriveCompensationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RedriveCompensation not implemented")
+}
 func (UnimplementedOrderServiceServer) mustEmbedUnimplementedOrderServiceServer() {}
 func (UnimplementedOrderServiceServer) testEmbeddedByValue()                      {}
 
@@ -268,6 +404,132 @@ func _OrderService_CancelOrder_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrderService_CreateFlashSale_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateFlashSaleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).CreateFlashSale(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_CreateFlashSale_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).CreateFlashSale(ctx, req.(*CreateFlashSaleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetFlashSale_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFlashSaleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetFlashSale(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_GetFlashSale_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetFlashSale(ctx, req.(*GetFlashSaleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ListFlashSales_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFlashSalesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ListFlashSales(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_ListFlashSales_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ListFlashSales(ctx, req.(*ListFlashSalesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_PurchaseFlashSale_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurchaseFlashSaleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).PurchaseFlashSale(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_PurchaseFlashSale_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).PurchaseFlashSale(ctx, req.(*PurchaseFlashSaleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetFlashSaleResult_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFlashSaleResultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetFlashSaleResult(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_GetFlashSaleResult_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetFlashSaleResult(ctx, req.(*GetFlashSaleResultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ListStuckCompensations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStuckCompensationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ListStuckCompensations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_ListStuckCompensations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ListStuckCompensations(ctx, req.(*ListStuckCompensationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_RedriveCompensation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RedriveCompensationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).RedriveCompensation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_RedriveCompensation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).RedriveCompensation(ctx, req.(*RedriveCompensationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // OrderService_ServiceDesc is the grpc.ServiceDesc for OrderService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -295,6 +557,34 @@ var OrderService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CancelOrder",
 			Handler:    _OrderService_CancelOrder_Handler,
 		},
+		{
+			MethodName: "CreateFlashSale",
+			Handler:    _OrderService_CreateFlashSale_Handler,
+		},
+		{
+			MethodName: "GetFlashSale",
+			Handler:    _OrderService_GetFlashSale_Handler,
+		},
+		{
+			MethodName: "ListFlashSales",
+			Handler:    _OrderService_ListFlashSales_Handler,
+		},
+		{
+			MethodName: "PurchaseFlashSale",
+			Handler:    _OrderService_PurchaseFlashSale_Handler,
+		},
+		{
+			MethodName: "GetFlashSaleResult",
+			Handler:    _OrderService_GetFlashSaleResult_Handler,
+		},
+		{
+			MethodName: "ListStuckCompensations",
+			Handler:    _OrderService_ListStuckCompensations_Handler,
+		},
+		{
+			MethodName: "RedriveCompensation",
+			Handler:    _OrderService_RedriveCompensation_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/order/v1/order.proto",
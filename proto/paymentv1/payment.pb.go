@@ -84,10 +84,11 @@ func (x *PayRequest) GetPaymentMethod() string {
 
 type PayResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0成功，1失败
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0已受理，1失败
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	PaymentNo     string                 `protobuf:"bytes,3,opt,name=payment_no,json=paymentNo,proto3" json:"payment_no,omitempty"`            // 支付流水号
-	ThirdPartyNo  string                 `protobuf:"bytes,4,opt,name=third_party_no,json=thirdPartyNo,proto3" json:"third_party_no,omitempty"` // 第三方支付流水号（Mock时为空）
+	ThirdPartyNo  string                 `protobuf:"bytes,4,opt,name=third_party_no,json=thirdPartyNo,proto3" json:"third_party_no,omitempty"` // 第三方支付流水号
+	Status        int32                  `protobuf:"varint,5,opt,name=status,proto3" json:"status,omitempty"`                                  // 受理后的支付状态：1待支付 2已支付 4失败
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -150,6 +151,13 @@ func (x *PayResponse) GetThirdPartyNo() string {
 	return ""
 }
 
+func (x *PayResponse) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
 // 查询支付状态
 type GetPaymentStatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -256,11 +264,17 @@ func (x *GetPaymentStatusResponse) GetPayment() *Payment {
 }
 
 // 退款
+//
+// 金额规则：
+// - 带明细：退款金额为明细金额之和（amount为0或与之相等）
+// - 不带明细：按amount退款；amount为0表示退还全部剩余可退金额
 type RefundRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	OrderId       uint64                 `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
-	Amount        int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"` // 退款金额（分）
-	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`  // 退款原因
+	Amount        int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`                       // 退款金额（分）
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`                        // 退款原因
+	Items         []*RefundItem          `protobuf:"bytes,4,rep,name=items,proto3" json:"items,omitempty"`                          // 退款明细（可选）
+	RequestId     string                 `protobuf:"bytes,5,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"` // 调用方幂等键（可选，重复提交返回同一笔退款）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -316,11 +330,26 @@ func (x *RefundRequest) GetReason() string {
 	return ""
 }
 
+func (x *RefundRequest) GetItems() []*RefundItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *RefundRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
 type RefundResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0成功，40000参数错误，40001超过可退金额/数量，40400支付记录不存在，1网关退款失败
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	RefundNo      string                 `protobuf:"bytes,3,opt,name=refund_no,json=refundNo,proto3" json:"refund_no,omitempty"` // 退款流水号
+	Refund        *Refund                `protobuf:"bytes,4,opt,name=refund,proto3" json:"refund,omitempty"`                     // 退款详情
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -376,25 +405,267 @@ func (x *RefundResponse) GetRefundNo() string {
 	return ""
 }
 
-// 支付信息
-type Payment struct {
+func (x *RefundResponse) GetRefund() *Refund {
+	if x != nil {
+		return x.Refund
+	}
+	return nil
+}
+
+// 查询退款记录
+type ListRefundsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	PaymentNo     string                 `protobuf:"bytes,2,opt,name=payment_no,json=paymentNo,proto3" json:"payment_no,omitempty"` // 支付流水号
-	OrderId       uint64                 `protobuf:"varint,3,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
-	Amount        int64                  `protobuf:"varint,4,opt,name=amount,proto3" json:"amount,omitempty"`                                   // 支付金额（分）
-	Status        int32                  `protobuf:"varint,5,opt,name=status,proto3" json:"status,omitempty"`                                   // 状态：1待支付 2已支付 3已退款 4失败
-	PaymentMethod string                 `protobuf:"bytes,6,opt,name=payment_method,json=paymentMethod,proto3" json:"payment_method,omitempty"` // 支付方式
-	ThirdPartyNo  string                 `protobuf:"bytes,7,opt,name=third_party_no,json=thirdPartyNo,proto3" json:"third_party_no,omitempty"`  // 第三方支付流水号
-	CreatedAt     int64                  `protobuf:"varint,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     int64                  `protobuf:"varint,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	OrderId       uint64                 `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *ListRefundsRequest) Reset() {
+	*x = ListRefundsRequest{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRefundsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRefundsRequest) ProtoMessage() {}
+
+func (x *ListRefundsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRefundsRequest.ProtoReflect.Descriptor instead.
+func (*ListRefundsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListRefundsRequest) GetOrderId() uint64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+type ListRefundsResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Code           uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message        string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Refunds        []*Refund              `protobuf:"bytes,3,rep,name=refunds,proto3" json:"refunds,omitempty"`
+	PaidAmount     int64                  `protobuf:"varint,4,opt,name=paid_amount,json=paidAmount,proto3" json:"paid_amount,omitempty"`             // 支付金额（分）
+	RefundedAmount int64                  `protobuf:"varint,5,opt,name=refunded_amount,json=refundedAmount,proto3" json:"refunded_amount,omitempty"` // 已退金额（分，仅统计成功的退款）
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListRefundsResponse) Reset() {
+	*x = ListRefundsResponse{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRefundsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRefundsResponse) ProtoMessage() {}
+
+func (x *ListRefundsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRefundsResponse.ProtoReflect.Descriptor instead.
+func (*ListRefundsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListRefundsResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ListRefundsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ListRefundsResponse) GetRefunds() []*Refund {
+	if x != nil {
+		return x.Refunds
+	}
+	return nil
+}
+
+func (x *ListRefundsResponse) GetPaidAmount() int64 {
+	if x != nil {
+		return x.PaidAmount
+	}
+	return 0
+}
+
+func (x *ListRefundsResponse) GetRefundedAmount() int64 {
+	if x != nil {
+		return x.RefundedAmount
+	}
+	return 0
+}
+
+// 支付网关回调
+type PaymentCallbackRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Provider      string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`   // 网关标识（如sandbox）
+	Payload       []byte                 `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`     // 原始回调报文（验签必须使用原始字节）
+	Signature     string                 `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"` // 回调签名
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaymentCallbackRequest) Reset() {
+	*x = PaymentCallbackRequest{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentCallbackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentCallbackRequest) ProtoMessage() {}
+
+func (x *PaymentCallbackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentCallbackRequest.ProtoReflect.Descriptor instead.
+func (*PaymentCallbackRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PaymentCallbackRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *PaymentCallbackRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *PaymentCallbackRequest) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+type PaymentCallbackResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // 0已处理（含重复回调），40000报文错误，40100验签失败
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaymentCallbackResponse) Reset() {
+	*x = PaymentCallbackResponse{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentCallbackResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentCallbackResponse) ProtoMessage() {}
+
+func (x *PaymentCallbackResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentCallbackResponse.ProtoReflect.Descriptor instead.
+func (*PaymentCallbackResponse) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PaymentCallbackResponse) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *PaymentCallbackResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// 支付信息
+type Payment struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	PaymentNo      string                 `protobuf:"bytes,2,opt,name=payment_no,json=paymentNo,proto3" json:"payment_no,omitempty"` // 支付流水号
+	OrderId        uint64                 `protobuf:"varint,3,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Amount         int64                  `protobuf:"varint,4,opt,name=amount,proto3" json:"amount,omitempty"`                                   // 支付金额（分）
+	Status         int32                  `protobuf:"varint,5,opt,name=status,proto3" json:"status,omitempty"`                                   // 状态：1待支付 2已支付 3已退款 4失败 5部分退款
+	PaymentMethod  string                 `protobuf:"bytes,6,opt,name=payment_method,json=paymentMethod,proto3" json:"payment_method,omitempty"` // 支付方式
+	ThirdPartyNo   string                 `protobuf:"bytes,7,opt,name=third_party_no,json=thirdPartyNo,proto3" json:"third_party_no,omitempty"`  // 第三方支付流水号
+	CreatedAt      int64                  `protobuf:"varint,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt      int64                  `protobuf:"varint,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	RefundedAmount int64                  `protobuf:"varint,10,opt,name=refunded_amount,json=refundedAmount,proto3" json:"refunded_amount,omitempty"` // 已退金额（分）
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
 func (x *Payment) Reset() {
 	*x = Payment{}
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[6]
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -406,7 +677,7 @@ func (x *Payment) String() string {
 func (*Payment) ProtoMessage() {}
 
 func (x *Payment) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[6]
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -419,7 +690,7 @@ func (x *Payment) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Payment.ProtoReflect.Descriptor instead.
 func (*Payment) Descriptor() ([]byte, []int) {
-	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{6}
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Payment) GetId() uint64 {
@@ -485,6 +756,223 @@ func (x *Payment) GetUpdatedAt() int64 {
 	return 0
 }
 
+func (x *Payment) GetRefundedAmount() int64 {
+	if x != nil {
+		return x.RefundedAmount
+	}
+	return 0
+}
+
+// 退款明细（关联订单明细）
+type RefundItem struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	OrderItemId     uint64                 `protobuf:"varint,1,opt,name=order_item_id,json=orderItemId,proto3" json:"order_item_id,omitempty"` // 订单明细ID
+	BookId          uint64                 `protobuf:"varint,2,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	Quantity        int32                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`                                      // 退款数量
+	Amount          int64                  `protobuf:"varint,4,opt,name=amount,proto3" json:"amount,omitempty"`                                          // 退款金额（分）
+	OrderedQuantity int32                  `protobuf:"varint,5,opt,name=ordered_quantity,json=orderedQuantity,proto3" json:"ordered_quantity,omitempty"` // 下单数量（调用方从订单读取，用于校验累计退款数量）
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RefundItem) Reset() {
+	*x = RefundItem{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundItem) ProtoMessage() {}
+
+func (x *RefundItem) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundItem.ProtoReflect.Descriptor instead.
+func (*RefundItem) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RefundItem) GetOrderItemId() uint64 {
+	if x != nil {
+		return x.OrderItemId
+	}
+	return 0
+}
+
+func (x *RefundItem) GetBookId() uint64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *RefundItem) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *RefundItem) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *RefundItem) GetOrderedQuantity() int32 {
+	if x != nil {
+		return x.OrderedQuantity
+	}
+	return 0
+}
+
+// 退款信息
+type Refund struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	RefundNo           string                 `protobuf:"bytes,2,opt,name=refund_no,json=refundNo,proto3" json:"refund_no,omitempty"` // 退款流水号
+	OrderId            uint64                 `protobuf:"varint,3,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	PaymentNo          string                 `protobuf:"bytes,4,opt,name=payment_no,json=paymentNo,proto3" json:"payment_no,omitempty"`
+	Amount             int64                  `protobuf:"varint,5,opt,name=amount,proto3" json:"amount,omitempty"` // 退款金额（分）
+	Reason             string                 `protobuf:"bytes,6,opt,name=reason,proto3" json:"reason,omitempty"`
+	Status             int32                  `protobuf:"varint,7,opt,name=status,proto3" json:"status,omitempty"` // 状态：1处理中 2成功 3失败
+	ThirdPartyRefundNo string                 `protobuf:"bytes,8,opt,name=third_party_refund_no,json=thirdPartyRefundNo,proto3" json:"third_party_refund_no,omitempty"`
+	FailReason         string                 `protobuf:"bytes,9,opt,name=fail_reason,json=failReason,proto3" json:"fail_reason,omitempty"`
+	Items              []*RefundItem          `protobuf:"bytes,10,rep,name=items,proto3" json:"items,omitempty"`
+	CreatedAt          int64                  `protobuf:"varint,11,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt          int64                  `protobuf:"varint,12,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Refund) Reset() {
+	*x = Refund{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Refund) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Refund) ProtoMessage() {}
+
+func (x *Refund) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Refund.ProtoReflect.Descriptor instead.
+func (*Refund) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Refund) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Refund) GetRefundNo() string {
+	if x != nil {
+		return x.RefundNo
+	}
+	return ""
+}
+
+func (x *Refund) GetOrderId() uint64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *Refund) GetPaymentNo() string {
+	if x != nil {
+		return x.PaymentNo
+	}
+	return ""
+}
+
+func (x *Refund) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Refund) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Refund) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *Refund) GetThirdPartyRefundNo() string {
+	if x != nil {
+		return x.ThirdPartyRefundNo
+	}
+	return ""
+}
+
+func (x *Refund) GetFailReason() string {
+	if x != nil {
+		return x.FailReason
+	}
+	return ""
+}
+
+func (x *Refund) GetItems() []*RefundItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *Refund) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Refund) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
 var File_proto_payment_v1_payment_proto protoreflect.FileDescriptor
 
 const file_proto_payment_v1_payment_proto_rawDesc = "" +
@@ -495,27 +983,48 @@ const file_proto_payment_v1_payment_proto_rawDesc = "" +
 	"PayRequest\x12\x19\n" +
 	"\border_id\x18\x01 \x01(\x04R\aorderId\x12\x16\n" +
 	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12%\n" +
-	"\x0epayment_method\x18\x03 \x01(\tR\rpaymentMethod\"\x80\x01\n" +
+	"\x0epayment_method\x18\x03 \x01(\tR\rpaymentMethod\"\x98\x01\n" +
 	"\vPayResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1d\n" +
 	"\n" +
 	"payment_no\x18\x03 \x01(\tR\tpaymentNo\x12$\n" +
-	"\x0ethird_party_no\x18\x04 \x01(\tR\fthirdPartyNo\"4\n" +
+	"\x0ethird_party_no\x18\x04 \x01(\tR\fthirdPartyNo\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\x05R\x06status\"4\n" +
 	"\x17GetPaymentStatusRequest\x12\x19\n" +
 	"\border_id\x18\x01 \x01(\x04R\aorderId\"w\n" +
 	"\x18GetPaymentStatusResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12-\n" +
-	"\apayment\x18\x03 \x01(\v2\x13.payment.v1.PaymentR\apayment\"Z\n" +
+	"\apayment\x18\x03 \x01(\v2\x13.payment.v1.PaymentR\apayment\"\xa7\x01\n" +
 	"\rRefundRequest\x12\x19\n" +
 	"\border_id\x18\x01 \x01(\x04R\aorderId\x12\x16\n" +
 	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x16\n" +
-	"\x06reason\x18\x03 \x01(\tR\x06reason\"[\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12,\n" +
+	"\x05items\x18\x04 \x03(\v2\x16.payment.v1.RefundItemR\x05items\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x05 \x01(\tR\trequestId\"\x87\x01\n" +
 	"\x0eRefundResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1b\n" +
-	"\trefund_no\x18\x03 \x01(\tR\brefundNo\"\x8e\x02\n" +
+	"\trefund_no\x18\x03 \x01(\tR\brefundNo\x12*\n" +
+	"\x06refund\x18\x04 \x01(\v2\x12.payment.v1.RefundR\x06refund\"/\n" +
+	"\x12ListRefundsRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x04R\aorderId\"\xbb\x01\n" +
+	"\x13ListRefundsResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12,\n" +
+	"\arefunds\x18\x03 \x03(\v2\x12.payment.v1.RefundR\arefunds\x12\x1f\n" +
+	"\vpaid_amount\x18\x04 \x01(\x03R\n" +
+	"paidAmount\x12'\n" +
+	"\x0frefunded_amount\x18\x05 \x01(\x03R\x0erefundedAmount\"l\n" +
+	"\x16PaymentCallbackRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x18\n" +
+	"\apayload\x18\x02 \x01(\fR\apayload\x12\x1c\n" +
+	"\tsignature\x18\x03 \x01(\tR\tsignature\"G\n" +
+	"\x17PaymentCallbackResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xb7\x02\n" +
 	"\aPayment\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1d\n" +
 	"\n" +
@@ -528,11 +1037,40 @@ const file_proto_payment_v1_payment_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\b \x01(\x03R\tcreatedAt\x12\x1d\n" +
 	"\n" +
-	"updated_at\x18\t \x01(\x03R\tupdatedAt2\xe8\x01\n" +
+	"updated_at\x18\t \x01(\x03R\tupdatedAt\x12'\n" +
+	"\x0frefunded_amount\x18\n" +
+	" \x01(\x03R\x0erefundedAmount\"\xa8\x01\n" +
+	"\n" +
+	"RefundItem\x12\"\n" +
+	"\rorder_item_id\x18\x01 \x01(\x04R\vorderItemId\x12\x17\n" +
+	"\abook_id\x18\x02 \x01(\x04R\x06bookId\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x05R\bquantity\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x03R\x06amount\x12)\n" +
+	"\x10ordered_quantity\x18\x05 \x01(\x05R\x0forderedQuantity\"\xf7\x02\n" +
+	"\x06Refund\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1b\n" +
+	"\trefund_no\x18\x02 \x01(\tR\brefundNo\x12\x19\n" +
+	"\border_id\x18\x03 \x01(\x04R\aorderId\x12\x1d\n" +
+	"\n" +
+	"payment_no\x18\x04 \x01(\tR\tpaymentNo\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\x03R\x06amount\x12\x16\n" +
+	"\x06reason\x18\x06 \x01(\tR\x06reason\x12\x16\n" +
+	"\x06status\x18\a \x01(\x05R\x06status\x121\n" +
+	"\x15third_party_refund_no\x18\b \x01(\tR\x12thirdPartyRefundNo\x12\x1f\n" +
+	"\vfail_reason\x18\t \x01(\tR\n" +
+	"failReason\x12,\n" +
+	"\x05items\x18\n" +
+	" \x03(\v2\x16.payment.v1.RefundItemR\x05items\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\v \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\f \x01(\x03R\tupdatedAt2\x94\x03\n" +
 	"\x0ePaymentService\x126\n" +
 	"\x03Pay\x12\x16.payment.v1.PayRequest\x1a\x17.payment.v1.PayResponse\x12]\n" +
 	"\x10GetPaymentStatus\x12#.payment.v1.GetPaymentStatusRequest\x1a$.payment.v1.GetPaymentStatusResponse\x12?\n" +
-	"\x06Refund\x12\x19.payment.v1.RefundRequest\x1a\x1a.payment.v1.RefundResponseB9Z7github.com/xiebiao/bookstore/proto/payment/v1;paymentv1b\x06proto3"
+	"\x06Refund\x12\x19.payment.v1.RefundRequest\x1a\x1a.payment.v1.RefundResponse\x12N\n" +
+	"\vListRefunds\x12\x1e.payment.v1.ListRefundsRequest\x1a\x1f.payment.v1.ListRefundsResponse\x12Z\n" +
+	"\x0fPaymentCallback\x12\".payment.v1.PaymentCallbackRequest\x1a#.payment.v1.PaymentCallbackResponseB9Z7github.com/xiebiao/bookstore/proto/payment/v1;paymentv1b\x06proto3"
 
 var (
 	file_proto_payment_v1_payment_proto_rawDescOnce sync.Once
@@ -546,7 +1084,7 @@ func file_proto_payment_v1_payment_proto_rawDescGZIP() []byte {
 	return file_proto_payment_v1_payment_proto_rawDescData
 }
 
-var file_proto_payment_v1_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_proto_payment_v1_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_proto_payment_v1_payment_proto_goTypes = []any{
 	(*PayRequest)(nil),               // 0: payment.v1.PayRequest
 	(*PayResponse)(nil),              // 1: payment.v1.PayResponse
@@ -554,21 +1092,35 @@ var file_proto_payment_v1_payment_proto_goTypes = []any{
 	(*GetPaymentStatusResponse)(nil), // 3: payment.v1.GetPaymentStatusResponse
 	(*RefundRequest)(nil),            // 4: payment.v1.RefundRequest
 	(*RefundResponse)(nil),           // 5: payment.v1.RefundResponse
-	(*Payment)(nil),                  // 6: payment.v1.Payment
+	(*ListRefundsRequest)(nil),       // 6: payment.v1.ListRefundsRequest
+	(*ListRefundsResponse)(nil),      // 7: payment.v1.ListRefundsResponse
+	(*PaymentCallbackRequest)(nil),   // 8: payment.v1.PaymentCallbackRequest
+	(*PaymentCallbackResponse)(nil),  // 9: payment.v1.PaymentCallbackResponse
+	(*Payment)(nil),                  // 10: payment.v1.Payment
+	(*RefundItem)(nil),               // 11: payment.v1.RefundItem
+	(*Refund)(nil),                   // 12: payment.v1.Refund
 }
 var file_proto_payment_v1_payment_proto_depIdxs = []int32{
-	6, // 0: payment.v1.GetPaymentStatusResponse.payment:type_name -> payment.v1.Payment
-	0, // 1: payment.v1.PaymentService.Pay:input_type -> payment.v1.PayRequest
-	2, // 2: payment.v1.PaymentService.GetPaymentStatus:input_type -> payment.v1.GetPaymentStatusRequest
-	4, // 3: payment.v1.PaymentService.Refund:input_type -> payment.v1.RefundRequest
-	1, // 4: payment.v1.PaymentService.Pay:output_type -> payment.v1.PayResponse
-	3, // 5: payment.v1.PaymentService.GetPaymentStatus:output_type -> payment.v1.GetPaymentStatusResponse
-	5, // 6: payment.v1.PaymentService.Refund:output_type -> payment.v1.RefundResponse
-	4, // [4:7] is the sub-list for method output_type
-	1, // [1:4] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	10, // 0: payment.v1.GetPaymentStatusResponse.payment:type_name -> payment.v1.Payment
+	11, // 1: payment.v1.RefundRequest.items:type_name -> payment.v1.RefundItem
+	12, // 2: payment.v1.RefundResponse.refund:type_name -> payment.v1.Refund
+	12, // 3: payment.v1.ListRefundsResponse.refunds:type_name -> payment.v1.Refund
+	11, // 4: payment.v1.Refund.items:type_name -> payment.v1.RefundItem
+	0,  // 5: payment.v1.PaymentService.Pay:input_type -> payment.v1.PayRequest
+	2,  // 6: payment.v1.PaymentService.GetPaymentStatus:input_type -> payment.v1.GetPaymentStatusRequest
+	4,  // 7: payment.v1.PaymentService.Refund:input_type -> payment.v1.RefundRequest
+	6,  // 8: payment.v1.PaymentService.ListRefunds:input_type -> payment.v1.ListRefundsRequest
+	8,  // 9: payment.v1.PaymentService.PaymentCallback:input_type -> payment.v1.PaymentCallbackRequest
+	1,  // 10: payment.v1.PaymentService.Pay:output_type -> payment.v1.PayResponse
+	3,  // 11: payment.v1.PaymentService.GetPaymentStatus:output_type -> payment.v1.GetPaymentStatusResponse
+	5,  // 12: payment.v1.PaymentService.Refund:output_type -> payment.v1.RefundResponse
+	7,  // 13: payment.v1.PaymentService.ListRefunds:output_type -> payment.v1.ListRefundsResponse
+	9,  // 14: payment.v1.PaymentService.PaymentCallback:output_type -> payment.v1.PaymentCallbackResponse
+	10, // [10:15] is the sub-list for method output_type
+	5,  // [5:10] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_proto_payment_v1_payment_proto_init() }
@@ -582,7 +1134,7 @@ func file_proto_payment_v1_payment_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_payment_v1_payment_proto_rawDesc), len(file_proto_payment_v1_payment_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
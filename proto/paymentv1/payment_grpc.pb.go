@@ -22,26 +22,36 @@ const (
 	PaymentService_Pay_FullMethodName              = "/payment.v1.PaymentService/Pay"
 	PaymentService_GetPaymentStatus_FullMethodName = "/payment.v1.PaymentService/GetPaymentStatus"
 	PaymentService_Refund_FullMethodName           = "/payment.v1.PaymentService/Refund"
+	PaymentService_ListRefunds_FullMethodName      = "/payment.v1.PaymentService/ListRefunds"
+	PaymentService_PaymentCallback_FullMethodName  = "/payment.v1.PaymentService/PaymentCallback"
 )
 
 // PaymentServiceClient is the client API for PaymentService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type PaymentServiceClient interface {
-	// 创建支付（Mock实现：随机成功/失败）
+	// 创建支付
 	// 教学重点：
 	// 1. 幂等性：相同order_id只能支付一次
-	// 2. Mock实现：70%成功率（用于测试Saga补偿机制）
-	// 3. 真实场景：对接支付宝、微信支付
+	// 2. 向支付网关下单后立即返回"待支付"，不阻塞等待结果
+	// 3. 最终结果由PaymentCallback（或查询兜底）推进
 	Pay(ctx context.Context, in *PayRequest, opts ...grpc.CallOption) (*PayResponse, error)
 	// 查询支付状态
-	// 用例：支付回调后查询最终状态
+	// 用例：支付回调后查询最终状态；待支付时主动向网关查询（回调丢失兜底）
 	GetPaymentStatus(ctx context.Context, in *GetPaymentStatusRequest, opts ...grpc.CallOption) (*GetPaymentStatusResponse, error)
-	// 退款（订单取消时调用）
+	// 退款（订单取消、客服部分退款时调用）
 	// 教学重点：
-	// 1. 只有已支付的订单可以退款
-	// 2. 退款也需要幂等性控制
+	// 1. 只有已支付（含部分退款）的订单可以退款
+	// 2. 支持多次部分退款，累计不超过支付金额；可按订单明细退（如只退其中一本书）
+	// 3. 退款也需要幂等性控制（request_id）
 	Refund(ctx context.Context, in *RefundRequest, opts ...grpc.CallOption) (*RefundResponse, error)
+	// 查询订单的退款记录
+	ListRefunds(ctx context.Context, in *ListRefundsRequest, opts ...grpc.CallOption) (*ListRefundsResponse, error)
+	// 处理支付网关回调（api-gateway透传原始报文和签名）
+	// 教学重点：
+	// 1. 必须先验签，防止伪造"支付成功"通知
+	// 2. 回调可能重复投递，状态推进必须幂等
+	PaymentCallback(ctx context.Context, in *PaymentCallbackRequest, opts ...grpc.CallOption) (*PaymentCallbackResponse, error)
 }
 
 type paymentServiceClient struct {
@@ -82,24 +92,52 @@ func (c *paymentServiceClient) Refund(ctx context.Context, in *RefundRequest, op
 	return out, nil
 }
 
+func (c *paymentServiceClient) ListRefunds(ctx context.Context, in *ListRefundsRequest, opts ...grpc.CallOption) (*ListRefundsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRefundsResponse)
+	err := c.cc.Invoke(ctx, PaymentService_ListRefunds_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) PaymentCallback(ctx context.Context, in *PaymentCallbackRequest, opts ...grpc.CallOption) (*PaymentCallbackResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PaymentCallbackResponse)
+	err := c.cc.Invoke(ctx, PaymentService_PaymentCallback_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PaymentServiceServer is the server API for PaymentService service.
 // All implementations must embed UnimplementedPaymentServiceServer
 // for forward compatibility.
 type PaymentServiceServer interface {
-	// 创建支付（Mock实现：随机成功/失败）
+	// 创建支付
 	// 教学重点：
 	// 1. 幂等性：相同order_id只能支付一次
-	// 2. Mock实现：70%成功率（用于测试Saga补偿机制）
-	// 3. 真实场景：对接支付宝、微信支付
+	// 2. 向支付网关下单后立即返回"待支付"，不阻塞等待结果
+	// 3. 最终结果由PaymentCallback（或查询兜底）推进
 	Pay(context.Context, *PayRequest) (*PayResponse, error)
 	// 查询支付状态
-	// 用例：支付回调后查询最终状态
+	// 用例：支付回调后查询最终状态；待支付时主动向网关查询（回调丢失兜底）
 	GetPaymentStatus(context.Context, *GetPaymentStatusRequest) (*GetPaymentStatusResponse, error)
-	// 退款（订单取消时调用）
+	// 退款（订单取消、客服部分退款时调用）
 	// 教学重点：
-	// 1. 只有已支付的订单可以退款
-	// 2. 退款也需要幂等性控制
+	// 1. 只有已支付（含部分退款）的订单可以退款
+	// 2. 支持多次部分退款，累计不超过支付金额；可按订单明细退（如只退其中一本书）
+	// 3. 退款也需要幂等性控制（request_id）
 	Refund(context.Context, *RefundRequest) (*RefundResponse, error)
+	// 查询订单的退款记录
+	ListRefunds(context.Context, *ListRefundsRequest) (*ListRefundsResponse, error)
+	// 处理支付网关回调（api-gateway透传原始报文和签名）
+	// 教学重点：
+	// 1. 必须先验签，防止伪造"支付成功"通知
+	// 2. 回调可能重复投递，状态推进必须幂等
+	PaymentCallback(context.Context, *PaymentCallbackRequest) (*PaymentCallbackResponse, error)
 	mustEmbedUnimplementedPaymentServiceServer()
 }
 
@@ -119,6 +157,12 @@ func (UnimplementedPaymentServiceServer) GetPaymentStatus(context.Context, *GetP
 func (UnimplementedPaymentServiceServer) Refund(context.Context, *RefundRequest) (*RefundResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Refund not implemented")
 }
+func (UnimplementedPaymentServiceServer) ListRefunds(context.Context, *ListRefundsRequest) (*ListRefundsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRefunds not implemented")
+}
+func (UnimplementedPaymentServiceServer) PaymentCallback(context.Context, *PaymentCallbackRequest) (*PaymentCallbackResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PaymentCallback not implemented")
+}
 func (UnimplementedPaymentServiceServer) mustEmbedUnimplementedPaymentServiceServer() {}
 func (UnimplementedPaymentServiceServer) testEmbeddedByValue()                        {}
 
@@ -194,6 +238,42 @@ func _PaymentService_Refund_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaymentService_ListRefunds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRefundsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).ListRefunds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_ListRefunds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).ListRefunds(ctx, req.(*ListRefundsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_PaymentCallback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PaymentCallbackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).PaymentCallback(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_PaymentCallback_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).PaymentCallback(ctx, req.(*PaymentCallbackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PaymentService_ServiceDesc is the grpc.ServiceDesc for PaymentService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -213,6 +293,14 @@ var PaymentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Refund",
 			Handler:    _PaymentService_Refund_Handler,
 		},
+		{
+			MethodName: "ListRefunds",
+			Handler:    _PaymentService_ListRefunds_Handler,
+		},
+		{
+			MethodName: "PaymentCallback",
+			Handler:    _PaymentService_PaymentCallback_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/payment/v1/payment.proto",
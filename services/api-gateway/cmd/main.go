@@ -49,12 +49,44 @@ func main() {
 	defer userClient.Close()
 	fmt.Println("✓ user-service客户端连接成功")
 
-	// 后续添加其他服务客户端：
-	// catalogClient, _ := client.NewCatalogClient(cfg.GRPC.CatalogService)
-	// orderClient, _ := client.NewOrderClient(cfg.GRPC.OrderService)
+	catalogClient, err := client.NewCatalogClient(cfg.GRPC.CatalogService)
+	if err != nil {
+		log.Fatalf("❌ 初始化catalog-service客户端失败: %v", err)
+	}
+	defer catalogClient.Close()
+	fmt.Println("✓ catalog-service客户端连接成功")
+
+	inventoryClient, err := client.NewInventoryClient(cfg.GRPC.InventoryService)
+	if err != nil {
+		log.Fatalf("❌ 初始化inventory-service客户端失败: %v", err)
+	}
+	defer inventoryClient.Close()
+	fmt.Println("✓ inventory-service客户端连接成功")
+
+	orderClient, err := client.NewOrderClient(cfg.GRPC.OrderService)
+	if err != nil {
+		log.Fatalf("❌ 初始化order-service客户端失败: %v", err)
+	}
+	defer orderClient.Close()
+	fmt.Println("✓ order-service客户端连接成功")
+
+	paymentClient, err := client.NewPaymentClient(cfg.GRPC.PaymentService)
+	if err != nil {
+		log.Fatalf("❌ 初始化payment-service客户端失败: %v", err)
+	}
+	defer paymentClient.Close()
+	fmt.Println("✓ payment-service客户端连接成功")
 
 	// 步骤3: 初始化Handler
-	userHandler := handler.NewUserHandler(userClient)
+	handlers := &routeHandlers{
+		user:      handler.NewUserHandler(userClient),
+		book:      handler.NewBookHandler(catalogClient, inventoryClient),
+		search:    handler.NewSearchHandler(catalogClient),
+		inventory: handler.NewInventoryHandler(inventoryClient, catalogClient),
+		order:     handler.NewOrderHandler(orderClient),
+		payment:   handler.NewPaymentHandler(paymentClient, orderClient),
+		flashSale: handler.NewFlashSaleHandler(orderClient),
+	}
 
 	// 步骤4: 设置Gin模式
 	gin.SetMode(cfg.Server.Mode)
@@ -73,7 +105,7 @@ func main() {
 	// 教学重点：
 	// 1. 公开路由（不需要鉴权）
 	// 2. 受保护路由（需要Auth中间件鉴权）
-	setupRoutes(router, userHandler, userClient)
+	setupRoutes(router, handlers, userClient, cfg.Admin.UserIDs)
 
 	// 步骤8: 创建HTTP服务器
 	srv := &http.Server{
@@ -91,6 +123,40 @@ func main() {
 		fmt.Println("  POST /api/v1/auth/login      - 用户登录")
 		fmt.Println("  POST /api/v1/auth/refresh    - 刷新Token")
 		fmt.Println("  GET  /api/v1/users/:id       - 获取用户信息（需要鉴权）")
+		fmt.Println("  GET  /api/v1/books           - 图书列表/搜索")
+		fmt.Println("  GET  /api/v1/books/:id       - 图书详情")
+		fmt.Println("  POST /api/v1/books           - 上架图书（需要鉴权）")
+		fmt.Println("  PUT  /api/v1/books/:id       - 修改图书信息（需要鉴权，仅发布者）")
+		fmt.Println("  PUT  /api/v1/books/:id/price - 修改价格（需要鉴权，仅发布者）")
+		fmt.Println("  PUT  /api/v1/books/:id/status - 上架/下架（需要鉴权，仅发布者）")
+		fmt.Println("  DELETE /api/v1/books/:id     - 删除图书（需要鉴权，仅发布者）")
+		fmt.Println("  POST /api/v1/books/:id/restore - 恢复图书（需要鉴权，仅发布者）")
+		fmt.Println("  GET  /api/v1/search/synonyms        - 搜索同义词列表（需要鉴权）")
+		fmt.Println("  POST /api/v1/search/synonyms        - 新建同义词组（需要管理员）")
+		fmt.Println("  PUT  /api/v1/search/synonyms/:id    - 更新同义词组（需要管理员）")
+		fmt.Println("  DELETE /api/v1/search/synonyms/:id  - 删除同义词组（需要管理员）")
+		fmt.Println("  GET  /api/v1/inventory/:id   - 查询库存")
+		fmt.Println("  POST /api/v1/inventory/:id/restock - 补充库存（需要鉴权，仅图书发布者）")
+		fmt.Println("  POST /api/v1/inventory/:id/adjust  - 库存调整（需要鉴权，仅图书发布者）")
+		fmt.Println("  POST /api/v1/inventory/stocktake   - 盘点导入（需要鉴权，仅图书发布者）")
+		fmt.Println("  GET  /api/v1/inventory/:id/logs    - 库存日志（需要鉴权）")
+		fmt.Println("  GET  /api/v1/inventory/:id/ledger  - 历史库存（需要鉴权）")
+		fmt.Println("  POST /api/v1/inventory/:id/subscription - 订阅到货提醒（需要鉴权）")
+		fmt.Println("  DELETE /api/v1/inventory/:id/subscription - 取消到货提醒（需要鉴权）")
+		fmt.Println("  POST /api/v1/orders          - 创建订单（需要鉴权）")
+		fmt.Println("  GET  /api/v1/orders          - 我的订单（需要鉴权）")
+		fmt.Println("  GET  /api/v1/orders/:id      - 订单详情（需要鉴权）")
+		fmt.Println("  POST /api/v1/orders/:id/cancel  - 取消订单（需要鉴权）")
+		fmt.Println("  POST /api/v1/orders/:id/pay     - 支付订单（需要鉴权）")
+		fmt.Println("  GET  /api/v1/orders/:id/payment - 支付状态（需要鉴权）")
+		fmt.Println("  POST /api/v1/orders/:id/refund  - 申请退款（需要鉴权）")
+		fmt.Println("  GET  /api/v1/orders/:id/refunds - 退款记录（需要鉴权）")
+		fmt.Println("  GET  /api/v1/flash-sales     - 秒杀活动列表")
+		fmt.Println("  GET  /api/v1/flash-sales/:id - 秒杀活动详情")
+		fmt.Println("  POST /api/v1/flash-sales     - 创建秒杀活动（需要鉴权，仅图书发布者）")
+		fmt.Println("  POST /api/v1/flash-sales/:id/purchase        - 秒杀抢购（需要鉴权）")
+		fmt.Println("  GET  /api/v1/flash-sales/:id/results/:ticket - 秒杀结果（需要鉴权）")
+		fmt.Println("  POST /api/v1/payments/callback/:provider - 支付网关回调（验签）")
 		fmt.Println("  GET  /health                 - 健康检查")
 		fmt.Println()
 
@@ -118,13 +184,31 @@ func main() {
 	fmt.Println("👋 服务已完全关闭")
 }
 
+// routeHandlers 路由依赖的全部Handler
+//
+// 教学说明：
+// 后端服务越来越多，用结构体聚合Handler，避免setupRoutes参数列表无限变长
+type routeHandlers struct {
+	user      *handler.UserHandler
+	book      *handler.BookHandler
+	search    *handler.SearchHandler
+	inventory *handler.InventoryHandler
+	order     *handler.OrderHandler
+	payment   *handler.PaymentHandler
+	flashSale *handler.FlashSaleHandler
+}
+
 // setupRoutes 设置路由
 //
 // 教学要点：
-// 1. 路由分组：按功能模块分组（auth、users、books、orders）
+// 1. 路由分组：按功能模块分组（auth、users、books、inventory、orders）
 // 2. 中间件应用：公开路由 vs 受保护路由
 // 3. RESTful设计：统一的API风格
-func setupRoutes(router *gin.Engine, userHandler *handler.UserHandler, userClient *client.UserClient) {
+// 4. 管理接口在Auth之后再加AdminRequired（管理员用户ID见配置admin.user_ids）
+func setupRoutes(router *gin.Engine, h *routeHandlers, userClient *client.UserClient, adminUserIDs []uint64) {
+	authRequired := middleware.Auth(userClient)
+	adminRequired := middleware.AdminRequired(adminUserIDs)
+
 	// 健康检查（无需鉴权）
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -140,31 +224,85 @@ func setupRoutes(router *gin.Engine, userHandler *handler.UserHandler, userClien
 		// 认证路由（公开，无需鉴权）
 		auth := v1.Group("/auth")
 		{
-			auth.POST("/register", userHandler.Register)    // 注册
-			auth.POST("/login", userHandler.Login)          // 登录
-			auth.POST("/refresh", userHandler.RefreshToken) // 刷新Token
+			auth.POST("/register", h.user.Register)    // 注册
+			auth.POST("/login", h.user.Login)          // 登录
+			auth.POST("/refresh", h.user.RefreshToken) // 刷新Token
 		}
 
 		// 用户路由（需要鉴权）
 		users := v1.Group("/users")
-		users.Use(middleware.Auth(userClient)) // 应用Auth中间件
+		users.Use(authRequired) // 应用Auth中间件
+		{
+			users.GET("/:id", h.user.GetUser) // 获取用户信息
+		}
+
+		// 图书路由（查询公开，上架和管理需要鉴权）
+		books := v1.Group("/books")
+		{
+			books.GET("", h.book.ListBooks)                                 // 列表/搜索
+			books.GET("/:id", h.book.GetBook)                               // 详情
+			books.POST("", authRequired, h.book.PublishBook)                // 上架
+			books.PUT("/:id", authRequired, h.book.UpdateBook)              // 修改信息
+			books.PUT("/:id/price", authRequired, h.book.UpdateBookPrice)   // 改价
+			books.PUT("/:id/status", authRequired, h.book.ChangeBookStatus) // 上架/下架
+			books.DELETE("/:id", authRequired, h.book.DeleteBook)           // 删除
+			books.POST("/:id/restore", authRequired, h.book.RestoreBook)    // 恢复
+		}
+
+		// 搜索词典路由（全部需要鉴权，修改仅限管理员：词典影响全站搜索结果）
+		synonyms := v1.Group("/search/synonyms")
+		synonyms.Use(authRequired)
 		{
-			users.GET("/:id", userHandler.GetUser) // 获取用户信息
+			synonyms.GET("", h.search.ListSynonyms)
+			synonyms.POST("", adminRequired, h.search.CreateSynonyms)
+			synonyms.PUT("/:id", adminRequired, h.search.UpdateSynonyms)
+			synonyms.DELETE("/:id", adminRequired, h.search.DeleteSynonyms)
 		}
 
-		// 后续添加其他路由组：
-		// books := v1.Group("/books")
-		// {
-		//     books.GET("", bookHandler.List)       // 列表（公开）
-		//     books.POST("", middleware.Auth(userClient), bookHandler.Create) // 上架（需要鉴权）
-		// }
-		//
-		// orders := v1.Group("/orders")
-		// orders.Use(middleware.Auth(userClient)) // 所有订单接口都需要鉴权
-		// {
-		//     orders.POST("", orderHandler.Create)
-		//     orders.GET("/:id", orderHandler.GetByID)
-		// }
+		// 库存路由（查询公开，其余需要鉴权；补货、调整、盘点仅限图书发布者）
+		inventory := v1.Group("/inventory")
+		{
+			inventory.GET("/:id", h.inventory.GetStock)
+			inventory.POST("/:id/restock", authRequired, h.inventory.Restock)
+			inventory.POST("/:id/adjust", authRequired, h.inventory.Adjust)
+			inventory.POST("/stocktake", authRequired, h.inventory.Stocktake)
+			inventory.GET("/:id/logs", authRequired, h.inventory.GetLogs)
+			inventory.GET("/:id/ledger", authRequired, h.inventory.GetLedger)
+			inventory.POST("/:id/subscription", authRequired, h.inventory.SubscribeBackInStock)
+			inventory.DELETE("/:id/subscription", authRequired, h.inventory.UnsubscribeBackInStock)
+		}
+
+		// 订单路由（全部需要鉴权）
+		orders := v1.Group("/orders")
+		orders.Use(authRequired)
+		{
+			orders.POST("", h.order.CreateOrder)
+			orders.GET("", h.order.ListOrders)
+			orders.GET("/:id", h.order.GetOrder)
+			orders.POST("/:id/cancel", h.order.CancelOrder)
+
+			// 支付子资源
+			orders.POST("/:id/pay", h.payment.Pay)
+			orders.GET("/:id/payment", h.payment.GetPayment)
+			orders.POST("/:id/refund", h.payment.Refund)
+			orders.GET("/:id/refunds", h.payment.ListRefunds)
+		}
+
+		// 秒杀路由（活动查询公开，创建/抢购/查询结果需要鉴权，创建仅限图书发布者）
+		flashSales := v1.Group("/flash-sales")
+		{
+			flashSales.GET("", h.flashSale.ListFlashSales)
+			flashSales.GET("/:id", h.flashSale.GetFlashSale)
+			flashSales.POST("", authRequired, h.flashSale.CreateFlashSale)
+			flashSales.POST("/:id/purchase", authRequired, h.flashSale.Purchase)
+			flashSales.GET("/:id/results/:ticket", authRequired, h.flashSale.GetResult)
+		}
+
+		// 支付网关回调（第三方调用，不鉴权，由payment-service验签）
+		payments := v1.Group("/payments")
+		{
+			payments.POST("/callback/:provider", h.payment.Callback)
+		}
 	}
 }
 
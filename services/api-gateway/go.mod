@@ -6,6 +6,10 @@ require (
 	github.com/gin-gonic/gin v1.11.0
 	github.com/google/uuid v1.6.0
 	github.com/spf13/viper v1.21.0
+	github.com/xiebiao/bookstore/proto/catalogv1 v0.0.0-00010101000000-000000000000
+	github.com/xiebiao/bookstore/proto/inventoryv1 v0.0.0-00010101000000-000000000000
+	github.com/xiebiao/bookstore/proto/orderv1 v0.0.0-00010101000000-000000000000
+	github.com/xiebiao/bookstore/proto/paymentv1 v0.0.0-00010101000000-000000000000
 	github.com/xiebiao/bookstore/proto/userv1 v0.0.0-00010101000000-000000000000
 	google.golang.org/grpc v1.76.0
 )
@@ -55,4 +59,10 @@ require (
 )
 
 // 使用本地proto包
-replace github.com/xiebiao/bookstore/proto/userv1 => ../../proto/user/v1
+replace (
+	github.com/xiebiao/bookstore/proto/catalogv1 => ../../proto/catalogv1
+	github.com/xiebiao/bookstore/proto/inventoryv1 => ../../proto/inventoryv1
+	github.com/xiebiao/bookstore/proto/orderv1 => ../../proto/orderv1
+	github.com/xiebiao/bookstore/proto/paymentv1 => ../../proto/paymentv1
+	github.com/xiebiao/bookstore/proto/userv1 => ../../proto/user/v1
+)
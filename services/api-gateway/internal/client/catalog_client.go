@@ -0,0 +1,260 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	catalogv1 "github.com/xiebiao/bookstore/proto/catalogv1"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/config"
+)
+
+// CatalogClient catalog-service gRPC客户端封装
+//
+// 教学要点：
+// 1. 与UserClient结构一致：client + conn + timeout
+// 2. 图书查询是高频读操作，catalog-service内部有Redis缓存
+// 3. Gateway只做转发，不做缓存（避免多级缓存一致性问题）
+type CatalogClient struct {
+	client  catalogv1.CatalogServiceClient
+	conn    *grpc.ClientConn
+	timeout time.Duration
+}
+
+// NewCatalogClient 创建catalog-service客户端
+func NewCatalogClient(cfg config.ServiceConfig) (*CatalogClient, error) {
+	conn, err := grpc.NewClient(
+		cfg.Addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("连接catalog-service失败: %w", err)
+	}
+
+	return &CatalogClient{
+		client:  catalogv1.NewCatalogServiceClient(conn),
+		conn:    conn,
+		timeout: cfg.GetTimeout(),
+	}, nil
+}
+
+// Close 关闭连接
+func (c *CatalogClient) Close() error {
+	if c.conn != nil {
+		return c.conn.Close()
+	}
+	return nil
+}
+
+// GetBook 获取图书详情
+func (c *CatalogClient) GetBook(ctx context.Context, bookID uint64) (*catalogv1.GetBookResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.GetBook(ctx, &catalogv1.GetBookRequest{
+		BookId: bookID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取图书失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ListBooks 图书列表（cursor非空时为游标分页，忽略page）
+func (c *CatalogClient) ListBooks(ctx context.Context, page, pageSize uint32, sortBy, order, cursor string, filter *catalogv1.BookFilter) (*catalogv1.ListBooksResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.ListBooks(ctx, &catalogv1.ListBooksRequest{
+		Page:     page,
+		PageSize: pageSize,
+		SortBy:   sortBy,
+		Order:    order,
+		Filter:   filter,
+		Cursor:   cursor,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询图书列表失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// SearchBooks 搜索图书
+func (c *CatalogClient) SearchBooks(ctx context.Context, keyword string, page, pageSize uint32, filter *catalogv1.BookFilter) (*catalogv1.SearchBooksResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.SearchBooks(ctx, &catalogv1.SearchBooksRequest{
+		Keyword:  keyword,
+		Page:     page,
+		PageSize: pageSize,
+		Filter:   filter,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("搜索图书失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// PublishBook 上架图书
+//
+// 教学说明：
+// publisher_id由Gateway从JWT中提取后填入，不信任客户端传入的值
+func (c *CatalogClient) PublishBook(ctx context.Context, req *catalogv1.PublishBookRequest) (*catalogv1.PublishBookResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.PublishBook(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("上架图书失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// UpdateBook 修改图书信息
+//
+// 教学说明：
+// operator_id与PublishBook的publisher_id一样取自JWT，catalog-service据此校验归属
+func (c *CatalogClient) UpdateBook(ctx context.Context, req *catalogv1.UpdateBookRequest) (*catalogv1.UpdateBookResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.UpdateBook(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("修改图书失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// UpdateBookPrice 修改价格
+func (c *CatalogClient) UpdateBookPrice(ctx context.Context, bookID, operatorID uint64, price int64) (*catalogv1.UpdateBookPriceResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.UpdateBookPrice(ctx, &catalogv1.UpdateBookPriceRequest{
+		BookId:     bookID,
+		OperatorId: operatorID,
+		Price:      price,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("修改价格失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ChangeBookStatus 上架/下架
+func (c *CatalogClient) ChangeBookStatus(ctx context.Context, bookID, operatorID uint64, status int32) (*catalogv1.ChangeBookStatusResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.ChangeBookStatus(ctx, &catalogv1.ChangeBookStatusRequest{
+		BookId:     bookID,
+		OperatorId: operatorID,
+		Status:     status,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("修改图书状态失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// DeleteBook 删除图书（软删除）
+func (c *CatalogClient) DeleteBook(ctx context.Context, bookID, operatorID uint64) (*catalogv1.DeleteBookResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.DeleteBook(ctx, &catalogv1.DeleteBookRequest{
+		BookId:     bookID,
+		OperatorId: operatorID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("删除图书失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// RestoreBook 恢复已删除的图书
+func (c *CatalogClient) RestoreBook(ctx context.Context, bookID, operatorID uint64) (*catalogv1.RestoreBookResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.RestoreBook(ctx, &catalogv1.RestoreBookRequest{
+		BookId:     bookID,
+		OperatorId: operatorID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("恢复图书失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// BatchGetBooks 批量获取图书
+func (c *CatalogClient) BatchGetBooks(ctx context.Context, bookIDs []uint64) (*catalogv1.BatchGetBooksResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.BatchGetBooks(ctx, &catalogv1.BatchGetBooksRequest{
+		BookIds: bookIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("批量获取图书失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ListSynonymGroups 查询搜索同义词
+func (c *CatalogClient) ListSynonymGroups(ctx context.Context) (*catalogv1.ListSynonymGroupsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.ListSynonymGroups(ctx, &catalogv1.ListSynonymGroupsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("查询同义词失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// SaveSynonymGroup 新建（id为0）或更新同义词组
+func (c *CatalogClient) SaveSynonymGroup(ctx context.Context, id uint64, words []string) (*catalogv1.SaveSynonymGroupResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.SaveSynonymGroup(ctx, &catalogv1.SaveSynonymGroupRequest{
+		Id:    id,
+		Words: words,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("保存同义词失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// DeleteSynonymGroup 删除同义词组
+func (c *CatalogClient) DeleteSynonymGroup(ctx context.Context, id uint64) (*catalogv1.DeleteSynonymGroupResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.DeleteSynonymGroup(ctx, &catalogv1.DeleteSynonymGroupRequest{
+		Id: id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("删除同义词失败: %w", err)
+	}
+
+	return resp, nil
+}
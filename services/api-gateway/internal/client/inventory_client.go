@@ -0,0 +1,191 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	inventoryv1 "github.com/xiebiao/bookstore/proto/inventoryv1"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/config"
+)
+
+// InventoryClient inventory-service gRPC客户端封装
+//
+// 教学要点：
+// 1. Gateway只暴露查询和补货接口
+// 2. 扣减/释放库存由order-service在Saga中调用，不对外开放（否则用户可以绕过下单直接扣库存）
+type InventoryClient struct {
+	client  inventoryv1.InventoryServiceClient
+	conn    *grpc.ClientConn
+	timeout time.Duration
+}
+
+// NewInventoryClient 创建inventory-service客户端
+func NewInventoryClient(cfg config.ServiceConfig) (*InventoryClient, error) {
+	conn, err := grpc.NewClient(
+		cfg.Addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("连接inventory-service失败: %w", err)
+	}
+
+	return &InventoryClient{
+		client:  inventoryv1.NewInventoryServiceClient(conn),
+		conn:    conn,
+		timeout: cfg.GetTimeout(),
+	}, nil
+}
+
+// Close 关闭连接
+func (c *InventoryClient) Close() error {
+	if c.conn != nil {
+		return c.conn.Close()
+	}
+	return nil
+}
+
+// GetStock 查询单本图书库存
+func (c *InventoryClient) GetStock(ctx context.Context, bookID uint64) (*inventoryv1.GetStockResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.GetStock(ctx, &inventoryv1.GetStockRequest{
+		BookId: bookID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询库存失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// BatchGetStock 批量查询库存
+//
+// 教学说明：
+// 图书列表页需要展示库存，批量查询避免N+1次RPC
+func (c *InventoryClient) BatchGetStock(ctx context.Context, bookIDs []uint64) (*inventoryv1.BatchGetStockResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.BatchGetStock(ctx, &inventoryv1.BatchGetStockRequest{
+		BookIds: bookIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("批量查询库存失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// RestockInventory 补充库存（warehouseID为0时入库到默认仓库）
+func (c *InventoryClient) RestockInventory(ctx context.Context, bookID uint64, quantity int32, warehouseID uint64) (*inventoryv1.RestockInventoryResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.RestockInventory(ctx, &inventoryv1.RestockInventoryRequest{
+		BookId:      bookID,
+		Quantity:    quantity,
+		WarehouseId: warehouseID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("补充库存失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// AdjustStock 库存调整（quantity为有符号数，reason为调整原因）
+func (c *InventoryClient) AdjustStock(ctx context.Context, req *inventoryv1.AdjustStockRequest) (*inventoryv1.AdjustStockResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.AdjustStock(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("调整库存失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ImportStocktake 盘点导入
+func (c *InventoryClient) ImportStocktake(ctx context.Context, req *inventoryv1.ImportStocktakeRequest) (*inventoryv1.ImportStocktakeResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.ImportStocktake(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("盘点导入失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// GetInventoryLogs 查询库存变更日志（orderID非0时只返回该订单的流水）
+func (c *InventoryClient) GetInventoryLogs(ctx context.Context, bookID, orderID uint64, page, pageSize uint32) (*inventoryv1.GetInventoryLogsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.GetInventoryLogs(ctx, &inventoryv1.GetInventoryLogsRequest{
+		BookId:   bookID,
+		OrderId:  orderID,
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询库存日志失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ReplayLedger 按库存日志重放历史库存（asOf为0时截止到当前时间）
+func (c *InventoryClient) ReplayLedger(ctx context.Context, bookIDs []uint64, asOf int64) (*inventoryv1.ReplayLedgerResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.ReplayLedger(ctx, &inventoryv1.ReplayLedgerRequest{
+		BookIds: bookIDs,
+		AsOf:    asOf,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("重放库存日志失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// SubscribeBackInStock 订阅到货提醒
+func (c *InventoryClient) SubscribeBackInStock(ctx context.Context, userID, bookID uint64) (*inventoryv1.SubscribeBackInStockResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.SubscribeBackInStock(ctx, &inventoryv1.SubscribeBackInStockRequest{
+		UserId: userID,
+		BookId: bookID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("订阅到货提醒失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// UnsubscribeBackInStock 取消到货提醒
+func (c *InventoryClient) UnsubscribeBackInStock(ctx context.Context, userID, bookID uint64) (*inventoryv1.UnsubscribeBackInStockResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.UnsubscribeBackInStock(ctx, &inventoryv1.UnsubscribeBackInStockRequest{
+		UserId: userID,
+		BookId: bookID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("取消到货提醒失败: %w", err)
+	}
+
+	return resp, nil
+}
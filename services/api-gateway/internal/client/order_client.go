@@ -0,0 +1,194 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	orderv1 "github.com/xiebiao/bookstore/proto/orderv1"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/config"
+)
+
+// OrderClient order-service gRPC客户端封装
+//
+// 教学要点：
+// 1. CreateOrder内部是Saga（跨catalog/inventory），超时应比普通查询长
+// 2. user_id一律取自JWT，防止越权操作他人订单
+type OrderClient struct {
+	client  orderv1.OrderServiceClient
+	conn    *grpc.ClientConn
+	timeout time.Duration
+}
+
+// NewOrderClient 创建order-service客户端
+func NewOrderClient(cfg config.ServiceConfig) (*OrderClient, error) {
+	conn, err := grpc.NewClient(
+		cfg.Addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("连接order-service失败: %w", err)
+	}
+
+	return &OrderClient{
+		client:  orderv1.NewOrderServiceClient(conn),
+		conn:    conn,
+		timeout: cfg.GetTimeout(),
+	}, nil
+}
+
+// Close 关闭连接
+func (c *OrderClient) Close() error {
+	if c.conn != nil {
+		return c.conn.Close()
+	}
+	return nil
+}
+
+// CreateOrder 创建订单
+func (c *OrderClient) CreateOrder(ctx context.Context, userID uint64, items []*orderv1.OrderItem) (*orderv1.CreateOrderResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.CreateOrder(ctx, &orderv1.CreateOrderRequest{
+		UserId: userID,
+		Items:  items,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建订单失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// GetOrder 获取订单详情
+func (c *OrderClient) GetOrder(ctx context.Context, orderID uint64) (*orderv1.GetOrderResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.GetOrder(ctx, &orderv1.GetOrderRequest{
+		OrderId: orderID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取订单失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ListUserOrders 查询用户订单列表（cursor非空时为游标分页，忽略page）
+func (c *OrderClient) ListUserOrders(ctx context.Context, userID uint64, page, pageSize uint32, status int32, cursor string) (*orderv1.ListUserOrdersResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.ListUserOrders(ctx, &orderv1.ListUserOrdersRequest{
+		UserId:   userID,
+		Page:     page,
+		PageSize: pageSize,
+		Status:   status,
+		Cursor:   cursor,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询订单列表失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// CancelOrder 取消订单
+func (c *OrderClient) CancelOrder(ctx context.Context, orderID, userID uint64, reason string) (*orderv1.CancelOrderResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.CancelOrder(ctx, &orderv1.CancelOrderRequest{
+		OrderId: orderID,
+		UserId:  userID,
+		Reason:  reason,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("取消订单失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// CreateFlashSale 创建秒杀活动
+func (c *OrderClient) CreateFlashSale(ctx context.Context, req *orderv1.CreateFlashSaleRequest) (*orderv1.CreateFlashSaleResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.CreateFlashSale(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("创建秒杀活动失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// GetFlashSale 查询秒杀活动
+func (c *OrderClient) GetFlashSale(ctx context.Context, saleID uint64) (*orderv1.GetFlashSaleResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.GetFlashSale(ctx, &orderv1.GetFlashSaleRequest{
+		SaleId: saleID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询秒杀活动失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ListFlashSales 查询未结束的秒杀活动
+func (c *OrderClient) ListFlashSales(ctx context.Context, page, pageSize uint32) (*orderv1.ListFlashSalesResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.ListFlashSales(ctx, &orderv1.ListFlashSalesRequest{
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询秒杀活动列表失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// PurchaseFlashSale 秒杀抢购
+func (c *OrderClient) PurchaseFlashSale(ctx context.Context, saleID, userID uint64, quantity int32) (*orderv1.PurchaseFlashSaleResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.PurchaseFlashSale(ctx, &orderv1.PurchaseFlashSaleRequest{
+		SaleId:   saleID,
+		UserId:   userID,
+		Quantity: quantity,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("秒杀抢购失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// GetFlashSaleResult 查询秒杀结果
+func (c *OrderClient) GetFlashSaleResult(ctx context.Context, saleID, userID uint64, ticket string) (*orderv1.GetFlashSaleResultResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.GetFlashSaleResult(ctx, &orderv1.GetFlashSaleResultRequest{
+		SaleId: saleID,
+		UserId: userID,
+		Ticket: ticket,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询秒杀结果失败: %w", err)
+	}
+
+	return resp, nil
+}
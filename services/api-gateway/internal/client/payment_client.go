@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	paymentv1 "github.com/xiebiao/bookstore/proto/paymentv1"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/config"
+)
+
+// PaymentClient payment-service gRPC客户端封装
+//
+// 教学要点：
+// 支付金额不信任客户端，由Gateway先查询订单再取订单金额
+type PaymentClient struct {
+	client  paymentv1.PaymentServiceClient
+	conn    *grpc.ClientConn
+	timeout time.Duration
+}
+
+// NewPaymentClient 创建payment-service客户端
+func NewPaymentClient(cfg config.ServiceConfig) (*PaymentClient, error) {
+	conn, err := grpc.NewClient(
+		cfg.Addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("连接payment-service失败: %w", err)
+	}
+
+	return &PaymentClient{
+		client:  paymentv1.NewPaymentServiceClient(conn),
+		conn:    conn,
+		timeout: cfg.GetTimeout(),
+	}, nil
+}
+
+// Close 关闭连接
+func (c *PaymentClient) Close() error {
+	if c.conn != nil {
+		return c.conn.Close()
+	}
+	return nil
+}
+
+// Pay 发起支付
+func (c *PaymentClient) Pay(ctx context.Context, orderID uint64, amount int64, paymentMethod string) (*paymentv1.PayResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.Pay(ctx, &paymentv1.PayRequest{
+		OrderId:       orderID,
+		Amount:        amount,
+		PaymentMethod: paymentMethod,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("发起支付失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// GetPaymentStatus 查询支付状态
+func (c *PaymentClient) GetPaymentStatus(ctx context.Context, orderID uint64) (*paymentv1.GetPaymentStatusResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.GetPaymentStatus(ctx, &paymentv1.GetPaymentStatusRequest{
+		OrderId: orderID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询支付状态失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// Refund 申请退款（items为空时按金额退款，amount为0表示退还全部剩余金额）
+func (c *PaymentClient) Refund(ctx context.Context, req *paymentv1.RefundRequest) (*paymentv1.RefundResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.Refund(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("申请退款失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ListRefunds 查询订单的退款记录
+func (c *PaymentClient) ListRefunds(ctx context.Context, orderID uint64) (*paymentv1.ListRefundsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.ListRefunds(ctx, &paymentv1.ListRefundsRequest{
+		OrderId: orderID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询退款记录失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// PaymentCallback 转发支付网关回调（原始报文 + 签名，由payment-service验签）
+func (c *PaymentClient) PaymentCallback(ctx context.Context, provider string, payload []byte, signature string) (*paymentv1.PaymentCallbackResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.PaymentCallback(ctx, &paymentv1.PaymentCallbackRequest{
+		Provider:  provider,
+		Payload:   payload,
+		Signature: signature,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("处理支付回调失败: %w", err)
+	}
+
+	return resp, nil
+}
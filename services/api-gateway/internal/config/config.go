@@ -19,6 +19,7 @@ type Config struct {
 	JWT    JWTConfig    `mapstructure:"jwt"`
 	Log    LogConfig    `mapstructure:"log"`
 	CORS   CORSConfig   `mapstructure:"cors"`
+	Admin  AdminConfig  `mapstructure:"admin"`
 }
 
 // ServerConfig HTTP服务器配置
@@ -37,10 +38,11 @@ type ServerConfig struct {
 // Phase 2 Week 5: 使用直连模式（host:port）
 // Phase 2 Week 6: 将升级为服务发现模式（consul://service-name）
 type GRPCConfig struct {
-	UserService ServiceConfig `mapstructure:"user_service"`
-	// 后续添加其他服务
-	// CatalogService ServiceConfig `mapstructure:"catalog_service"`
-	// OrderService   ServiceConfig `mapstructure:"order_service"`
+	UserService      ServiceConfig `mapstructure:"user_service"`
+	CatalogService   ServiceConfig `mapstructure:"catalog_service"`
+	InventoryService ServiceConfig `mapstructure:"inventory_service"`
+	OrderService     ServiceConfig `mapstructure:"order_service"`
+	PaymentService   ServiceConfig `mapstructure:"payment_service"`
 }
 
 // ServiceConfig 单个gRPC服务配置
@@ -85,6 +87,14 @@ func (j *JWTConfig) GetRefreshTokenDuration() time.Duration {
 	return d
 }
 
+// AdminConfig 管理员配置
+//
+// 教学说明：
+// 用户体系还没有角色，管理接口按用户ID白名单放行（为空时管理接口全部拒绝）
+type AdminConfig struct {
+	UserIDs []uint64 `mapstructure:"user_ids"`
+}
+
 // LogConfig 日志配置
 type LogConfig struct {
 	Level  string `mapstructure:"level"`  // debug/info/warn/error
@@ -150,6 +160,18 @@ func (c *Config) Validate() error {
 	if c.GRPC.UserService.Addr == "" {
 		return fmt.Errorf("grpc.user_service.addr 不能为空")
 	}
+	if c.GRPC.CatalogService.Addr == "" {
+		return fmt.Errorf("grpc.catalog_service.addr 不能为空")
+	}
+	if c.GRPC.InventoryService.Addr == "" {
+		return fmt.Errorf("grpc.inventory_service.addr 不能为空")
+	}
+	if c.GRPC.OrderService.Addr == "" {
+		return fmt.Errorf("grpc.order_service.addr 不能为空")
+	}
+	if c.GRPC.PaymentService.Addr == "" {
+		return fmt.Errorf("grpc.payment_service.addr 不能为空")
+	}
 
 	if c.JWT.Secret == "" || c.JWT.Secret == "your-256-bit-secret-key-change-in-production" {
 		// 生产环境警告
@@ -179,5 +201,5 @@ func (c *Config) Validate() error {
 //    - 编译期类型检查
 //
 // 4. 扩展性：
-//    - 预留字段（注释掉的catalog_service）
-//    - 便于后续添加新服务
+//    - 每个后端服务一个ServiceConfig（addr + timeout）
+//    - 新增服务只需加字段和YAML配置
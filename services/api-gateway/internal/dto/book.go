@@ -0,0 +1,194 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+)
+
+// =========================================
+// 图书相关DTO
+// =========================================
+
+// PublishBookRequest 上架图书请求
+//
+// 教学说明：
+// 1. 与Phase 1单体的请求体保持一致，前端无需改动
+// 2. publisher_id不在请求体中，由Gateway从JWT提取
+// 3. stock为初始库存，Gateway上架成功后转调inventory-service补货
+type PublishBookRequest struct {
+	ISBN        string `json:"isbn" binding:"required"`
+	Title       string `json:"title" binding:"required,max=200"`
+	Author      string `json:"author" binding:"required,max=100"`
+	Publisher   string `json:"publisher" binding:"required,max=100"`
+	Price       int64  `json:"price" binding:"required,min=1,max=999999"` // 价格（分）
+	Stock       int32  `json:"stock" binding:"min=0"`
+	CoverURL    string `json:"cover_url" binding:"omitempty,url,max=500"`
+	Description string `json:"description" binding:"max=5000"`
+	Draft       bool   `json:"draft"` // 保存为草稿（不出现在列表和搜索中，确认后再上架）
+}
+
+// PublishBookResponse 上架图书响应
+type PublishBookResponse struct {
+	BookID uint64 `json:"book_id"`
+	Stock  int32  `json:"stock"` // 初始库存（补货失败时为0）
+}
+
+// UpdateBookRequest 修改图书信息请求
+//
+// 教学说明：
+// 1. 整体覆盖（PUT语义）：未传的可选字段会被清空
+// 2. ISBN不可修改；价格、状态走单独的接口
+type UpdateBookRequest struct {
+	Title       string `json:"title" binding:"required,max=200"`
+	Author      string `json:"author" binding:"required,max=100"`
+	Publisher   string `json:"publisher" binding:"required,max=100"`
+	CoverURL    string `json:"cover_url" binding:"omitempty,url,max=500"`
+	Description string `json:"description" binding:"max=5000"`
+}
+
+// UpdateBookPriceRequest 修改价格请求
+type UpdateBookPriceRequest struct {
+	Price int64 `json:"price" binding:"required,min=1,max=999999"` // 新价格（分）
+}
+
+// UpdateBookPriceResponse 修改价格响应
+type UpdateBookPriceResponse struct {
+	BookID   uint64 `json:"book_id"`
+	OldPrice int64  `json:"old_price"`
+	NewPrice int64  `json:"new_price"`
+}
+
+// ChangeBookStatusRequest 上架/下架请求
+type ChangeBookStatusRequest struct {
+	Status int32 `json:"status" binding:"required,oneof=2 3"` // 目标状态：2在售（上架） 3已下架
+}
+
+// ChangeBookStatusResponse 上架/下架响应
+type ChangeBookStatusResponse struct {
+	BookID     uint64 `json:"book_id"`
+	Status     int32  `json:"status"`
+	StatusText string `json:"status_text"`
+}
+
+// ListBooksRequest 图书列表请求（Query参数）
+//
+// 教学说明：
+// keyword非空时走SearchBooks，否则走ListBooks；两者支持相同的筛选条件
+type ListBooksRequest struct {
+	Page     uint32 `form:"page" binding:"omitempty,min=1"`
+	PageSize uint32 `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Keyword  string `form:"keyword" binding:"omitempty,max=100"`
+	SortBy   string `form:"sort_by" binding:"omitempty,oneof=created_at price"`
+	Order    string `form:"order" binding:"omitempty,oneof=asc desc"`
+	Cursor   string `form:"cursor" binding:"omitempty,max=200"` // 游标（上一页的next_cursor），非空时忽略page；搜索不支持
+
+	// 筛选条件
+	MinPrice      int64  `form:"min_price" binding:"omitempty,min=0"`                    // 最低价格（分，含）
+	MaxPrice      int64  `form:"max_price" binding:"omitempty,min=0"`                    // 最高价格（分，含）
+	Author        string `form:"author" binding:"omitempty,max=100"`                     // 作者（精确匹配）
+	Publisher     string `form:"publisher" binding:"omitempty,max=100"`                  // 出版社（精确匹配）
+	PublishedFrom string `form:"published_from" binding:"omitempty,datetime=2006-01-02"` // 发布日期起（含）
+	PublishedTo   string `form:"published_to" binding:"omitempty,datetime=2006-01-02"`   // 发布日期止（含当天）
+	InStock       bool   `form:"in_stock"`                                               // 只看有货
+}
+
+// BookResponse 图书响应
+type BookResponse struct {
+	ID          uint64 `json:"id"`
+	ISBN        string `json:"isbn"`
+	Title       string `json:"title"`
+	Author      string `json:"author"`
+	Publisher   string `json:"publisher"`
+	Price       int64  `json:"price"`      // 价格（分）
+	PriceYuan   string `json:"price_yuan"` // 价格（元），方便前端显示
+	Stock       int32  `json:"stock"`      // 来自inventory-service
+	CoverURL    string `json:"cover_url"`
+	Description string `json:"description,omitempty"`
+	PublisherID uint64 `json:"publisher_id"`
+	Status      int32  `json:"status"`
+	StatusText  string `json:"status_text"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+
+	Highlight *BookHighlight `json:"highlight,omitempty"` // 仅搜索结果返回
+}
+
+// BookHighlight 搜索高亮
+//
+// 教学说明：
+// 命中的词用<em></em>包裹，其余文本已由catalog-service做HTML转义，前端可直接渲染
+type BookHighlight struct {
+	Title       string  `json:"title,omitempty"`
+	Author      string  `json:"author,omitempty"`
+	Publisher   string  `json:"publisher,omitempty"`
+	Description string  `json:"description,omitempty"` // 命中位置附近的摘要
+	Score       float64 `json:"score"`
+}
+
+// ListBooksResponse 图书列表响应
+type ListBooksResponse struct {
+	List       []BookResponse `json:"list"`
+	Total      uint32         `json:"total"`
+	Page       uint32         `json:"page"` // 游标分页时为0
+	PageSize   uint32         `json:"page_size"`
+	NextCursor string         `json:"next_cursor,omitempty"` // 下一页游标（没有下一页时为空；搜索结果不返回）
+	Facets     *BookFacets    `json:"facets,omitempty"`
+}
+
+// BookFacets 分面统计
+//
+// 教学说明：
+// 每个分面忽略自身的筛选条件（选中某出版社后仍能看到其他出版社的数量）；
+// 点击价格档位时传min_price=min_price、max_price=max_price-1（档位不含上限，max_price为0时不传）
+type BookFacets struct {
+	Publishers []FacetCount     `json:"publishers"`
+	PriceBands []PriceBandCount `json:"price_bands"`
+}
+
+// FacetCount 分面的一项
+type FacetCount struct {
+	Value string `json:"value"`
+	Count uint32 `json:"count"`
+}
+
+// PriceBandCount 价格档位 [min_price, max_price)
+type PriceBandCount struct {
+	Label    string `json:"label"`
+	MinPrice int64  `json:"min_price"` // 分
+	MaxPrice int64  `json:"max_price"` // 分，0表示无上限
+	Count    uint32 `json:"count"`
+}
+
+// FormatPriceYuan 格式化价格（分→元）
+// 例如：5900分 → "59.00"
+func FormatPriceYuan(priceFen int64) string {
+	return fmt.Sprintf("%.2f", float64(priceFen)/100.0)
+}
+
+// FormatUnixTime 格式化Unix时间戳
+//
+// 教学说明：
+// gRPC使用int64时间戳传输（跨语言），HTTP响应转为可读字符串
+func FormatUnixTime(ts int64) string {
+	if ts <= 0 {
+		return ""
+	}
+	return time.Unix(ts, 0).Format("2006-01-02 15:04:05")
+}
+
+// BookStatusText 图书状态文案
+//
+// 教学说明：
+// 状态值与catalog-service的BookStatus保持一致（1草稿 2在售 3已下架）
+func BookStatusText(status int32) string {
+	switch status {
+	case 1:
+		return "草稿"
+	case 2:
+		return "在售"
+	case 3:
+		return "已下架"
+	default:
+		return "未知状态"
+	}
+}
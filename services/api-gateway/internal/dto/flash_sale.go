@@ -0,0 +1,63 @@
+package dto
+
+// =========================================
+// 秒杀相关DTO
+// =========================================
+
+// CreateFlashSaleRequest 创建秒杀活动请求
+type CreateFlashSaleRequest struct {
+	BookID       uint64 `json:"book_id" binding:"required"`
+	Price        int64  `json:"price" binding:"required,min=1"` // 秒杀价（分）
+	Stock        int32  `json:"stock" binding:"required,min=1,max=100000"`
+	PerUserLimit int32  `json:"per_user_limit" binding:"required,min=1"`
+	StartAt      int64  `json:"start_at" binding:"required"` // 开始时间（Unix秒）
+	EndAt        int64  `json:"end_at" binding:"required"`   // 结束时间（Unix秒）
+}
+
+// ListFlashSalesRequest 秒杀活动列表请求（Query参数）
+type ListFlashSalesRequest struct {
+	Page     uint32 `form:"page" binding:"omitempty,min=1"`
+	PageSize uint32 `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// PurchaseFlashSaleRequest 秒杀抢购请求
+type PurchaseFlashSaleRequest struct {
+	Quantity int32 `json:"quantity" binding:"omitempty,min=1,max=99"` // 不传为1件
+}
+
+// FlashSaleResponse 秒杀活动
+type FlashSaleResponse struct {
+	ID                uint64 `json:"id"`
+	BookID            uint64 `json:"book_id"`
+	BookTitle         string `json:"book_title"`
+	Price             int64  `json:"price"`
+	PriceYuan         string `json:"price_yuan"`
+	OriginalPrice     int64  `json:"original_price"`
+	OriginalPriceYuan string `json:"original_price_yuan"`
+	Stock             int32  `json:"stock"`
+	RemainingStock    int32  `json:"remaining_stock"`
+	PerUserLimit      int32  `json:"per_user_limit"`
+	StartAt           string `json:"start_at"`
+	EndAt             string `json:"end_at"`
+	Phase             string `json:"phase"` // UPCOMING/ONGOING/ENDED/CANCELLED
+}
+
+// ListFlashSalesResponse 秒杀活动列表响应
+type ListFlashSalesResponse struct {
+	List     []FlashSaleResponse `json:"list"`
+	Total    uint32              `json:"total"`
+	Page     uint32              `json:"page"`
+	PageSize uint32              `json:"page_size"`
+}
+
+// PurchaseFlashSaleResponse 秒杀抢购响应（订单异步创建，凭凭证查询结果）
+type PurchaseFlashSaleResponse struct {
+	Ticket string `json:"ticket"`
+}
+
+// FlashSaleResultResponse 秒杀结果
+type FlashSaleResultResponse struct {
+	Status  string `json:"status"` // QUEUED/SUCCESS/FAILED
+	OrderID uint64 `json:"order_id,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
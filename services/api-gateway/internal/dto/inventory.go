@@ -0,0 +1,134 @@
+package dto
+
+// =========================================
+// 库存相关DTO
+// =========================================
+
+// StockResponse 库存响应
+type StockResponse struct {
+	BookID     uint64                   `json:"book_id"`
+	Stock      int32                    `json:"stock"`                // 全部仓库合计
+	Warehouses []WarehouseStockResponse `json:"warehouses,omitempty"` // 各仓库明细
+}
+
+// WarehouseStockResponse 仓库库存
+type WarehouseStockResponse struct {
+	WarehouseID uint64 `json:"warehouse_id"` // 0为未分配仓库的库存
+	Name        string `json:"name"`
+	Stock       int32  `json:"stock"`
+}
+
+// RestockRequest 补货请求
+type RestockRequest struct {
+	Quantity    int32  `json:"quantity" binding:"required,min=1,max=100000"`
+	WarehouseID uint64 `json:"warehouse_id"` // 入库仓库（不传为默认仓库）
+}
+
+// AdjustStockRequest 库存调整请求
+type AdjustStockRequest struct {
+	Quantity    int32  `json:"quantity" binding:"required,min=-100000,max=100000"` // 正数增加，负数减少
+	Reason      string `json:"reason" binding:"required,oneof=DAMAGE LOSS STOCKTAKE RETURN"`
+	WarehouseID uint64 `json:"warehouse_id"` // 调整仓库（不传时增加计入默认仓库，减少按兜底顺序扣减）
+	Remark      string `json:"remark" binding:"max=255"`
+}
+
+// AdjustStockResponse 库存调整响应
+type AdjustStockResponse struct {
+	BookID      uint64 `json:"book_id"`
+	BeforeStock int32  `json:"before_stock"`
+	Stock       int32  `json:"stock"`
+}
+
+// StocktakeRequest 盘点导入请求
+type StocktakeRequest struct {
+	Items       []StocktakeItem `json:"items" binding:"required,min=1,max=100,dive"`
+	WarehouseID uint64          `json:"warehouse_id"`             // 差异计入的仓库（不传时同库存调整）
+	Remark      string          `json:"remark" binding:"max=255"` // 盘点单号
+}
+
+// StocktakeItem 盘点明细
+type StocktakeItem struct {
+	BookID          uint64 `json:"book_id" binding:"required"`
+	CountedQuantity *int32 `json:"counted_quantity" binding:"required,min=0"` // 实盘数量（含已锁定未发货的库存）
+}
+
+// StocktakeResponse 盘点导入响应
+type StocktakeResponse struct {
+	Results  []StocktakeResult `json:"results"`
+	Adjusted uint32            `json:"adjusted"` // 已调整的图书数
+	Failed   uint32            `json:"failed"`   // 调整失败的图书数（需人工处理）
+}
+
+// StocktakeResult 盘点结果
+type StocktakeResult struct {
+	BookID          uint64 `json:"book_id"`
+	CountedQuantity int32  `json:"counted_quantity"`
+	Difference      int32  `json:"difference"` // 实盘 - 账面（账面 = 可用 + 锁定）
+	BeforeStock     int32  `json:"before_stock"`
+	Stock           int32  `json:"stock"`
+	Code            uint32 `json:"code"`
+	Message         string `json:"message"`
+}
+
+// InventoryLogsRequest 库存日志请求（Query参数）
+type InventoryLogsRequest struct {
+	Page     uint32 `form:"page" binding:"omitempty,min=1"`
+	PageSize uint32 `form:"page_size" binding:"omitempty,min=1,max=100"`
+	OrderID  uint64 `form:"order_id"` // 按订单过滤（对账）
+}
+
+// InventoryLogResponse 库存日志
+type InventoryLogResponse struct {
+	ID          uint64 `json:"id"`
+	BookID      uint64 `json:"book_id"`
+	ChangeType  string `json:"change_type"`
+	Quantity    int32  `json:"quantity"`
+	BeforeStock int32  `json:"before_stock"`
+	AfterStock  int32  `json:"after_stock"`
+	OrderID     uint64 `json:"order_id,omitempty"`
+	WarehouseID uint64 `json:"warehouse_id,omitempty"` // 仓库日志的仓库ID（合计库存的日志不返回）
+	CreatedAt   string `json:"created_at"`
+}
+
+// InventoryLogsResponse 库存日志列表响应
+type InventoryLogsResponse struct {
+	List  []InventoryLogResponse `json:"list"`
+	Total uint32                 `json:"total"`
+}
+
+// LedgerRequest 日志重放请求（Query参数）
+type LedgerRequest struct {
+	AsOf int64 `form:"as_of" binding:"omitempty,min=1"` // 截止时间（Unix秒，不传为当前时间）
+}
+
+// LedgerResponse 日志重放结果
+type LedgerResponse struct {
+	BookID        uint64                   `json:"book_id"`
+	AsOf          string                   `json:"as_of"`
+	OpeningStock  int32                    `json:"opening_stock"`  // 期初可用库存
+	Stock         int32                    `json:"stock"`          // 重放得到的可用库存
+	RecordedStock int32                    `json:"recorded_stock"` // 最后一条日志记录的可用库存
+	LockedStock   int32                    `json:"locked_stock"`   // 推算的锁定库存
+	Warehouses    []WarehouseStockResponse `json:"warehouses,omitempty"`
+	LogCount      uint32                   `json:"log_count"`
+	LastLogAt     string                   `json:"last_log_at,omitempty"`
+	Consistent    bool                     `json:"consistent"` // 日志链是否完整
+	GapCount      uint32                   `json:"gap_count"`
+	Gaps          []LedgerGapResponse      `json:"gaps,omitempty"`
+}
+
+// LedgerGapResponse 日志链断点
+type LedgerGapResponse struct {
+	LogID       uint64 `json:"log_id"`
+	PrevLogID   uint64 `json:"prev_log_id,omitempty"`
+	WarehouseID uint64 `json:"warehouse_id,omitempty"`
+	Kind        string `json:"kind"` // CHAIN_BREAK / QUANTITY_MISMATCH
+	Expected    int32  `json:"expected"`
+	Actual      int32  `json:"actual"`
+}
+
+// BackInStockSubscriptionResponse 到货提醒订阅结果
+type BackInStockSubscriptionResponse struct {
+	BookID uint64 `json:"book_id"`
+	Stock  int32  `json:"stock"` // 当前可用库存（有货时也会订阅，下次从缺货到有货时通知）
+}
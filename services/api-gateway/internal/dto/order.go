@@ -0,0 +1,94 @@
+package dto
+
+// =========================================
+// 订单相关DTO
+// =========================================
+
+// CreateOrderRequest 下单请求（与Phase 1单体保持一致）
+type CreateOrderRequest struct {
+	Items []CreateOrderItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// CreateOrderItemRequest 订单明细项
+type CreateOrderItemRequest struct {
+	BookID   uint64 `json:"book_id" binding:"required"`
+	Quantity int32  `json:"quantity" binding:"required,min=1,max=999"`
+}
+
+// CreateOrderResponse 下单响应
+type CreateOrderResponse struct {
+	OrderID   uint64 `json:"order_id"`
+	OrderNo   string `json:"order_no"`
+	Total     int64  `json:"total"`
+	TotalYuan string `json:"total_yuan"`
+	Status    string `json:"status"`
+}
+
+// ListOrdersRequest 订单列表请求（Query参数）
+type ListOrdersRequest struct {
+	Page     uint32 `form:"page" binding:"omitempty,min=1"`
+	PageSize uint32 `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Status   int32  `form:"status" binding:"omitempty,min=1,max=5"` // 0或不传为全部
+	Cursor   string `form:"cursor" binding:"omitempty,max=200"`     // 游标（上一页的next_cursor），非空时忽略page
+}
+
+// CancelOrderRequest 取消订单请求
+type CancelOrderRequest struct {
+	Reason string `json:"reason" binding:"max=200"`
+}
+
+// OrderItemResponse 订单明细
+type OrderItemResponse struct {
+	BookID    uint64 `json:"book_id"`
+	BookTitle string `json:"book_title"`
+	Quantity  int32  `json:"quantity"`
+	Price     int64  `json:"price"`
+	PriceYuan string `json:"price_yuan"`
+}
+
+// OrderResponse 订单详情
+type OrderResponse struct {
+	ID         uint64              `json:"id"`
+	OrderNo    string              `json:"order_no"`
+	UserID     uint64              `json:"user_id"`
+	Total      int64               `json:"total"`
+	TotalYuan  string              `json:"total_yuan"`
+	Status     int32               `json:"status"`
+	StatusText string              `json:"status_text"`
+	Items      []OrderItemResponse `json:"items"`
+	CreatedAt  string              `json:"created_at"`
+	UpdatedAt  string              `json:"updated_at"`
+}
+
+// ListOrdersResponse 订单列表响应
+type ListOrdersResponse struct {
+	List       []OrderResponse `json:"list"`
+	Total      uint32          `json:"total"`
+	Page       uint32          `json:"page"` // 游标分页时为0
+	PageSize   uint32          `json:"page_size"`
+	NextCursor string          `json:"next_cursor,omitempty"` // 下一页游标（没有下一页时为空）
+}
+
+// OrderStatusPending 待支付（只有待支付的订单可以发起支付）
+const OrderStatusPending int32 = 1
+
+// OrderStatusText 订单状态文案
+//
+// 教学说明：
+// 状态值与order-service的OrderStatus保持一致（1待支付 2已支付 3已发货 4已完成 5已取消）
+func OrderStatusText(status int32) string {
+	switch status {
+	case 1:
+		return "待支付"
+	case 2:
+		return "已支付"
+	case 3:
+		return "已发货"
+	case 4:
+		return "已完成"
+	case 5:
+		return "已取消"
+	default:
+		return "未知状态"
+	}
+}
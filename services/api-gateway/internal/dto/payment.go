@@ -0,0 +1,119 @@
+package dto
+
+// =========================================
+// 支付相关DTO
+// =========================================
+
+// PayRequest 发起支付请求
+//
+// 教学说明：
+// 请求体不包含金额，金额由Gateway从订单中读取（防篡改）
+type PayRequest struct {
+	PaymentMethod string `json:"payment_method" binding:"required,oneof=alipay wechat mock"`
+}
+
+// PayResponse 发起支付响应
+//
+// 教学说明：
+// 支付是异步的，通常返回"待支付"，客户端轮询GET /orders/:id/payment获取最终结果
+type PayResponse struct {
+	PaymentNo    string `json:"payment_no"`
+	ThirdPartyNo string `json:"third_party_no,omitempty"`
+	Status       int32  `json:"status"`
+	StatusText   string `json:"status_text"`
+}
+
+// RefundRequest 退款请求
+//
+// 教学说明：
+// 1. 按明细退款：只传items，金额由Gateway按订单中的单价计算（防篡改）
+// 2. 按金额退款：只传amount（如运费补偿）
+// 3. 都不传：退还全部剩余可退金额
+// 4. request_id为客户端幂等键，网络重试时重复提交不会重复退款
+type RefundRequest struct {
+	Amount    int64               `json:"amount" binding:"omitempty,min=1"`
+	Reason    string              `json:"reason" binding:"required,max=200"`
+	Items     []RefundItemRequest `json:"items" binding:"omitempty,dive"`
+	RequestID string              `json:"request_id" binding:"omitempty,max=64"`
+}
+
+// RefundItemRequest 退款明细
+type RefundItemRequest struct {
+	OrderItemID uint64 `json:"order_item_id" binding:"required"`
+	Quantity    int32  `json:"quantity" binding:"required,min=1"`
+}
+
+// RefundResponse 退款记录
+type RefundResponse struct {
+	RefundNo   string               `json:"refund_no"`
+	Amount     int64                `json:"amount"`
+	AmountYuan string               `json:"amount_yuan"`
+	Reason     string               `json:"reason"`
+	Status     int32                `json:"status"`
+	StatusText string               `json:"status_text"`
+	FailReason string               `json:"fail_reason,omitempty"`
+	Items      []RefundItemResponse `json:"items,omitempty"`
+	CreatedAt  string               `json:"created_at"`
+}
+
+// RefundItemResponse 退款明细
+type RefundItemResponse struct {
+	OrderItemID uint64 `json:"order_item_id"`
+	BookID      uint64 `json:"book_id"`
+	Quantity    int32  `json:"quantity"`
+	Amount      int64  `json:"amount"`
+}
+
+// ListRefundsResponse 订单退款记录
+type ListRefundsResponse struct {
+	PaidAmount     int64            `json:"paid_amount"`
+	RefundedAmount int64            `json:"refunded_amount"`
+	Refunds        []RefundResponse `json:"refunds"`
+}
+
+// PaymentResponse 支付记录
+type PaymentResponse struct {
+	PaymentNo      string `json:"payment_no"`
+	OrderID        uint64 `json:"order_id"`
+	Amount         int64  `json:"amount"`
+	AmountYuan     string `json:"amount_yuan"`
+	Status         int32  `json:"status"`
+	StatusText     string `json:"status_text"`
+	PaymentMethod  string `json:"payment_method"`
+	ThirdPartyNo   string `json:"third_party_no,omitempty"`
+	RefundedAmount int64  `json:"refunded_amount"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+}
+
+// PaymentStatusText 支付状态文案（1待支付 2已支付 3已退款 4失败 5部分退款）
+func PaymentStatusText(status int32) string {
+	switch status {
+	case 1:
+		return "待支付"
+	case 2:
+		return "已支付"
+	case 3:
+		return "已退款"
+	case 4:
+		return "支付失败"
+	case 5:
+		return "部分退款"
+	default:
+		return "未知状态"
+	}
+}
+
+// RefundStatusText 退款状态文案（1处理中 2成功 3失败）
+func RefundStatusText(status int32) string {
+	switch status {
+	case 1:
+		return "处理中"
+	case 2:
+		return "退款成功"
+	case 3:
+		return "退款失败"
+	default:
+		return "未知状态"
+	}
+}
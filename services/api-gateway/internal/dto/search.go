@@ -0,0 +1,18 @@
+package dto
+
+// SaveSynonymGroupRequest 新建/更新同义词组请求
+type SaveSynonymGroupRequest struct {
+	Words []string `json:"words" binding:"required,min=1,max=20,dive,required,max=50"`
+}
+
+// SynonymGroupResponse 同义词组
+type SynonymGroupResponse struct {
+	ID        uint64   `json:"id"`
+	Words     []string `json:"words"`
+	UpdatedAt string   `json:"updated_at"`
+}
+
+// ListSynonymGroupsResponse 同义词列表响应
+type ListSynonymGroupsResponse struct {
+	List []SynonymGroupResponse `json:"list"`
+}
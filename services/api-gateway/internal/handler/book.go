@@ -0,0 +1,541 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	catalogv1 "github.com/xiebiao/bookstore/proto/catalogv1"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/client"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/dto"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/middleware"
+)
+
+// BookHandler 图书相关HTTP处理器
+//
+// 教学要点：
+// 1. 一个HTTP接口可能聚合多个后端服务（API组合模式）：
+// 图书信息来自catalog-service，库存来自inventory-service
+// 2. 次要数据（库存）查询失败时降级为0，不影响主数据展示
+type BookHandler struct {
+	catalogClient   *client.CatalogClient
+	inventoryClient *client.InventoryClient
+}
+
+// NewBookHandler 创建图书处理器
+func NewBookHandler(catalogClient *client.CatalogClient, inventoryClient *client.InventoryClient) *BookHandler {
+	return &BookHandler{
+		catalogClient:   catalogClient,
+		inventoryClient: inventoryClient,
+	}
+}
+
+// ListBooks 图书列表/搜索
+//
+// 教学说明：
+// keyword非空时转调SearchBooks，保持与Phase 1 GET /books?keyword=xx 一致；
+// 搜索结果按相关性排序（忽略sort_by/order），并附带命中高亮。
+// 筛选条件两种方式都支持，响应附带分面统计（出版社、价格区间）。
+// 列表支持游标分页：用响应中的next_cursor请求下一页，深翻页不变慢、翻页期间上新不会重复
+//
+// @Summary 图书列表
+// @Tags 图书
+// @Produce json
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Param keyword query string false "搜索关键词"
+// @Param sort_by query string false "排序字段（created_at/price）"
+// @Param order query string false "排序方向（asc/desc）"
+// @Param cursor query string false "分页游标（上一页的next_cursor）"
+// @Param min_price query int false "最低价格（分）"
+// @Param max_price query int false "最高价格（分）"
+// @Param author query string false "作者"
+// @Param publisher query string false "出版社"
+// @Param published_from query string false "发布日期起（2006-01-02）"
+// @Param published_to query string false "发布日期止（2006-01-02，含当天）"
+// @Param in_stock query bool false "只看有货"
+// @Success 200 {object} dto.Response{data=dto.ListBooksResponse}
+// @Router /api/v1/books [get]
+func (h *BookHandler) ListBooks(c *gin.Context) {
+	var req dto.ListBooksRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		dto.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+	if req.Keyword != "" && req.Cursor != "" {
+		dto.BadRequest(c, "搜索结果按相关性排序，不支持游标分页")
+		return
+	}
+	filter, err := toBookFilter(&req)
+	if err != nil {
+		dto.BadRequest(c, err.Error())
+		return
+	}
+
+	ctx := context.Background()
+
+	var (
+		books      []*catalogv1.Book
+		total      uint32
+		page       = req.Page
+		nextCursor string
+		highlights map[uint64]*dto.BookHighlight
+		facets     *catalogv1.BookFacets
+	)
+	if req.Keyword != "" {
+		resp, err := h.catalogClient.SearchBooks(ctx, req.Keyword, req.Page, req.PageSize, filter)
+		if err != nil {
+			handleGRPCError(c, err)
+			return
+		}
+		if resp.Code != 0 {
+			handleBizError(c, resp.Code, resp.Message)
+			return
+		}
+		books, total, facets = resp.Books, resp.Total, resp.Facets
+		highlights = toBookHighlights(resp.Highlights)
+	} else {
+		resp, err := h.catalogClient.ListBooks(ctx, req.Page, req.PageSize, req.SortBy, req.Order, req.Cursor, filter)
+		if err != nil {
+			handleGRPCError(c, err)
+			return
+		}
+		if resp.Code != 0 {
+			handleBizError(c, resp.Code, resp.Message)
+			return
+		}
+		books, total, facets = resp.Books, resp.Total, resp.Facets
+		page, nextCursor = resp.Page, resp.NextCursor
+	}
+
+	stocks := h.batchGetStock(ctx, books)
+
+	list := make([]dto.BookResponse, 0, len(books))
+	for _, b := range books {
+		item := toBookResponse(b, stocks[b.Id])
+		item.Description = "" // 列表不返回描述，减少传输量
+		item.Highlight = highlights[b.Id]
+		list = append(list, item)
+	}
+
+	dto.Success(c, dto.ListBooksResponse{
+		List:       list,
+		Total:      total,
+		Page:       page,
+		PageSize:   req.PageSize,
+		NextCursor: nextCursor,
+		Facets:     toBookFacets(facets),
+	})
+}
+
+// GetBook 图书详情
+//
+// @Summary 图书详情
+// @Tags 图书
+// @Produce json
+// @Param id path int true "图书ID"
+// @Success 200 {object} dto.Response{data=dto.BookResponse}
+// @Router /api/v1/books/{id} [get]
+func (h *BookHandler) GetBook(c *gin.Context) {
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || bookID == 0 {
+		dto.BadRequest(c, "图书ID格式错误")
+		return
+	}
+
+	ctx := context.Background()
+
+	resp, err := h.catalogClient.GetBook(ctx, bookID)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	stocks := h.batchGetStock(ctx, []*catalogv1.Book{resp.Book})
+	dto.Success(c, toBookResponse(resp.Book, stocks[resp.Book.Id]))
+}
+
+// PublishBook 上架图书
+//
+// 教学重点：
+// 1. publisher_id取自JWT（middleware.GetUserID），防止冒充他人上架
+// 2. 初始库存：catalog-service只管图书信息，库存需转调inventory-service补货
+// 3. 补货失败不回滚上架（图书已存在，可稍后补货），在响应中返回实际库存
+//
+// @Summary 上架图书
+// @Tags 图书
+// @Accept json
+// @Produce json
+// @Param request body dto.PublishBookRequest true "图书信息"
+// @Success 200 {object} dto.Response{data=dto.PublishBookResponse}
+// @Security BearerAuth
+// @Router /api/v1/books [post]
+func (h *BookHandler) PublishBook(c *gin.Context) {
+	var req dto.PublishBookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	ctx := context.Background()
+
+	resp, err := h.catalogClient.PublishBook(ctx, &catalogv1.PublishBookRequest{
+		Isbn:        req.ISBN,
+		Title:       req.Title,
+		Author:      req.Author,
+		Publisher:   req.Publisher,
+		Price:       req.Price,
+		CoverUrl:    req.CoverURL,
+		Description: req.Description,
+		PublisherId: middleware.GetUserID(c),
+		Draft:       req.Draft,
+	})
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	result := dto.PublishBookResponse{BookID: resp.BookId}
+	message := resp.Message
+	if req.Stock > 0 {
+		stockResp, err := h.inventoryClient.RestockInventory(ctx, resp.BookId, req.Stock, 0)
+		if err == nil && stockResp.Code == 0 {
+			result.Stock = stockResp.CurrentStock
+		} else {
+			message = "上架成功，初始库存设置失败，请稍后补货"
+		}
+	}
+
+	dto.SuccessWithMessage(c, message, result)
+}
+
+// UpdateBook 修改图书信息
+//
+// 教学重点：
+// 1. operator_id取自JWT，只有发布者本人可以修改（catalog-service校验，非本人返回403）
+// 2. 返回修改后的图书，前端无需再查一次详情
+//
+// @Summary 修改图书信息
+// @Tags 图书
+// @Accept json
+// @Produce json
+// @Param id path int true "图书ID"
+// @Param request body dto.UpdateBookRequest true "图书信息"
+// @Success 200 {object} dto.Response{data=dto.BookResponse}
+// @Security BearerAuth
+// @Router /api/v1/books/{id} [put]
+func (h *BookHandler) UpdateBook(c *gin.Context) {
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || bookID == 0 {
+		dto.BadRequest(c, "图书ID格式错误")
+		return
+	}
+
+	var req dto.UpdateBookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	ctx := context.Background()
+
+	resp, err := h.catalogClient.UpdateBook(ctx, &catalogv1.UpdateBookRequest{
+		BookId:      bookID,
+		OperatorId:  middleware.GetUserID(c),
+		Title:       req.Title,
+		Author:      req.Author,
+		Publisher:   req.Publisher,
+		CoverUrl:    req.CoverURL,
+		Description: req.Description,
+	})
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	stocks := h.batchGetStock(ctx, []*catalogv1.Book{resp.Book})
+	dto.SuccessWithMessage(c, resp.Message, toBookResponse(resp.Book, stocks[resp.Book.Id]))
+}
+
+// UpdateBookPrice 修改价格
+//
+// @Summary 修改图书价格
+// @Tags 图书
+// @Accept json
+// @Produce json
+// @Param id path int true "图书ID"
+// @Param request body dto.UpdateBookPriceRequest true "新价格"
+// @Success 200 {object} dto.Response{data=dto.UpdateBookPriceResponse}
+// @Security BearerAuth
+// @Router /api/v1/books/{id}/price [put]
+func (h *BookHandler) UpdateBookPrice(c *gin.Context) {
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || bookID == 0 {
+		dto.BadRequest(c, "图书ID格式错误")
+		return
+	}
+
+	var req dto.UpdateBookPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.catalogClient.UpdateBookPrice(context.Background(), bookID, middleware.GetUserID(c), req.Price)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	dto.SuccessWithMessage(c, resp.Message, dto.UpdateBookPriceResponse{
+		BookID:   bookID,
+		OldPrice: resp.OldPrice,
+		NewPrice: resp.NewPrice,
+	})
+}
+
+// ChangeBookStatus 上架/下架
+//
+// 教学重点：
+// 下架不删除图书：列表和搜索中不再出现、不能下单，详情和历史订单不受影响，随时可以重新上架
+//
+// @Summary 图书上架/下架
+// @Tags 图书
+// @Accept json
+// @Produce json
+// @Param id path int true "图书ID"
+// @Param request body dto.ChangeBookStatusRequest true "目标状态"
+// @Success 200 {object} dto.Response{data=dto.ChangeBookStatusResponse}
+// @Security BearerAuth
+// @Router /api/v1/books/{id}/status [put]
+func (h *BookHandler) ChangeBookStatus(c *gin.Context) {
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || bookID == 0 {
+		dto.BadRequest(c, "图书ID格式错误")
+		return
+	}
+
+	var req dto.ChangeBookStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.catalogClient.ChangeBookStatus(context.Background(), bookID, middleware.GetUserID(c), req.Status)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	dto.SuccessWithMessage(c, resp.Message, dto.ChangeBookStatusResponse{
+		BookID:     bookID,
+		Status:     resp.Status,
+		StatusText: dto.BookStatusText(resp.Status),
+	})
+}
+
+// DeleteBook 删除图书（软删除，可恢复）
+//
+// @Summary 删除图书
+// @Tags 图书
+// @Produce json
+// @Param id path int true "图书ID"
+// @Success 200 {object} dto.Response
+// @Security BearerAuth
+// @Router /api/v1/books/{id} [delete]
+func (h *BookHandler) DeleteBook(c *gin.Context) {
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || bookID == 0 {
+		dto.BadRequest(c, "图书ID格式错误")
+		return
+	}
+
+	resp, err := h.catalogClient.DeleteBook(context.Background(), bookID, middleware.GetUserID(c))
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	dto.SuccessWithMessage(c, resp.Message, nil)
+}
+
+// RestoreBook 恢复已删除的图书
+//
+// @Summary 恢复图书
+// @Tags 图书
+// @Produce json
+// @Param id path int true "图书ID"
+// @Success 200 {object} dto.Response{data=dto.BookResponse}
+// @Security BearerAuth
+// @Router /api/v1/books/{id}/restore [post]
+func (h *BookHandler) RestoreBook(c *gin.Context) {
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || bookID == 0 {
+		dto.BadRequest(c, "图书ID格式错误")
+		return
+	}
+
+	ctx := context.Background()
+
+	resp, err := h.catalogClient.RestoreBook(ctx, bookID, middleware.GetUserID(c))
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	stocks := h.batchGetStock(ctx, []*catalogv1.Book{resp.Book})
+	dto.SuccessWithMessage(c, resp.Message, toBookResponse(resp.Book, stocks[resp.Book.Id]))
+}
+
+// batchGetStock 批量查询库存（降级：失败时返回空map，库存显示为0）
+func (h *BookHandler) batchGetStock(ctx context.Context, books []*catalogv1.Book) map[uint64]int32 {
+	stocks := make(map[uint64]int32, len(books))
+	if len(books) == 0 {
+		return stocks
+	}
+
+	bookIDs := make([]uint64, 0, len(books))
+	for _, b := range books {
+		bookIDs = append(bookIDs, b.Id)
+	}
+
+	resp, err := h.inventoryClient.BatchGetStock(ctx, bookIDs)
+	if err != nil || resp.Code != 0 {
+		return stocks
+	}
+	for _, s := range resp.Stocks {
+		stocks[s.BookId] = s.Stock
+	}
+	return stocks
+}
+
+// toBookFilter Query参数 → Protobuf筛选条件
+//
+// 教学说明：日期按服务器本地时区解析；published_to含当天，转换为次日零点（不含）
+func toBookFilter(req *dto.ListBooksRequest) (*catalogv1.BookFilter, error) {
+	if req.MaxPrice > 0 && req.MinPrice > req.MaxPrice {
+		return nil, errors.New("最低价格不能高于最高价格")
+	}
+
+	filter := &catalogv1.BookFilter{
+		MinPrice:    req.MinPrice,
+		MaxPrice:    req.MaxPrice,
+		Author:      req.Author,
+		Publisher:   req.Publisher,
+		InStockOnly: req.InStock,
+	}
+	if req.PublishedFrom != "" {
+		from, err := time.ParseInLocation("2006-01-02", req.PublishedFrom, time.Local)
+		if err != nil {
+			return nil, errors.New("发布日期格式错误")
+		}
+		filter.PublishedFrom = from.Unix()
+	}
+	if req.PublishedTo != "" {
+		to, err := time.ParseInLocation("2006-01-02", req.PublishedTo, time.Local)
+		if err != nil {
+			return nil, errors.New("发布日期格式错误")
+		}
+		filter.PublishedTo = to.AddDate(0, 0, 1).Unix()
+	}
+	if filter.PublishedFrom > 0 && filter.PublishedTo > 0 && filter.PublishedFrom >= filter.PublishedTo {
+		return nil, errors.New("发布日期起不能晚于发布日期止")
+	}
+	return filter, nil
+}
+
+// toBookFacets Protobuf → HTTP DTO
+func toBookFacets(facets *catalogv1.BookFacets) *dto.BookFacets {
+	if facets == nil {
+		return nil
+	}
+	result := &dto.BookFacets{
+		Publishers: make([]dto.FacetCount, 0, len(facets.Publishers)),
+		PriceBands: make([]dto.PriceBandCount, 0, len(facets.PriceBands)),
+	}
+	for _, p := range facets.Publishers {
+		result.Publishers = append(result.Publishers, dto.FacetCount{Value: p.Value, Count: p.Count})
+	}
+	for _, b := range facets.PriceBands {
+		result.PriceBands = append(result.PriceBands, dto.PriceBandCount{
+			Label:    b.Label,
+			MinPrice: b.MinPrice,
+			MaxPrice: b.MaxPrice,
+			Count:    b.Count,
+		})
+	}
+	return result
+}
+
+// toBookHighlights 搜索高亮按图书ID索引
+func toBookHighlights(highlights []*catalogv1.BookHighlight) map[uint64]*dto.BookHighlight {
+	result := make(map[uint64]*dto.BookHighlight, len(highlights))
+	for _, h := range highlights {
+		result[h.BookId] = &dto.BookHighlight{
+			Title:       h.Title,
+			Author:      h.Author,
+			Publisher:   h.Publisher,
+			Description: h.Description,
+			Score:       h.Score,
+		}
+	}
+	return result
+}
+
+// toBookResponse Protobuf → HTTP DTO
+func toBookResponse(b *catalogv1.Book, stock int32) dto.BookResponse {
+	return dto.BookResponse{
+		ID:          b.Id,
+		ISBN:        b.Isbn,
+		Title:       b.Title,
+		Author:      b.Author,
+		Publisher:   b.Publisher,
+		Price:       b.Price,
+		PriceYuan:   dto.FormatPriceYuan(b.Price),
+		Stock:       stock,
+		CoverURL:    b.CoverUrl,
+		Description: b.Description,
+		PublisherID: b.PublisherId,
+		Status:      b.Status,
+		StatusText:  dto.BookStatusText(b.Status),
+		CreatedAt:   dto.FormatUnixTime(b.CreatedAt),
+		UpdatedAt:   dto.FormatUnixTime(b.UpdatedAt),
+	}
+}
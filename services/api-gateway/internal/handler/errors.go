@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/dto"
+)
+
+// handleGRPCError 处理gRPC错误
+//
+// 教学重点：
+// gRPC错误码 → HTTP状态码映射
+//
+// gRPC常见错误码：
+// - codes.InvalidArgument: 参数错误 → 400
+// - codes.Unauthenticated: 未认证 → 401
+// - codes.PermissionDenied: 无权限 → 403
+// - codes.NotFound: 未找到 → 404
+// - codes.Internal: 内部错误 → 500
+// - codes.Unimplemented: 后端未实现 → 501
+// - codes.Unavailable: 服务不可用 → 503
+// - codes.DeadlineExceeded: 调用超时 → 504
+func handleGRPCError(c *gin.Context, err error) {
+	// 提取gRPC状态码
+	st, ok := status.FromError(err)
+	if !ok {
+		// 不是gRPC错误（网络错误等）
+		dto.InternalError(c, "服务调用失败")
+		return
+	}
+
+	// 根据gRPC错误码返回相应的HTTP错误
+	switch st.Code() {
+	case codes.InvalidArgument:
+		dto.BadRequest(c, st.Message())
+	case codes.Unauthenticated:
+		dto.Unauthorized(c, st.Message())
+	case codes.PermissionDenied:
+		dto.Forbidden(c, st.Message())
+	case codes.NotFound:
+		dto.NotFound(c, st.Message())
+	case codes.Unavailable:
+		// 服务不可用（后端服务宕机）
+		dto.Error(c, 503, 50300, "服务暂时不可用，请稍后重试")
+	case codes.Unimplemented:
+		// 后端服务尚未实现该RPC（proto已定义）
+		dto.Error(c, http.StatusNotImplemented, 50100, "接口暂未开放")
+	case codes.DeadlineExceeded:
+		dto.Error(c, http.StatusGatewayTimeout, 50400, "服务响应超时，请稍后重试")
+	default:
+		// 其他错误统一返回500
+		dto.InternalError(c, st.Message())
+	}
+}
+
+// handleBizError 处理后端服务返回的业务错误码
+//
+// 教学重点：
+// catalog/inventory/order/payment服务的业务失败不走gRPC error，
+// 而是在响应体里返回code + message（例如40401图书不存在、40100库存不足）。
+// Gateway按"业务码前三位≈HTTP状态码"的约定转换，业务码原样透传给前端。
+//
+// 特例：
+// - 40100在inventory-service中表示"库存不足"，不是认证失败，映射为409
+// - 无法识别的非0码（如payment的1=支付失败）按400处理
+func handleBizError(c *gin.Context, code uint32, message string) {
+	httpCode := http.StatusBadRequest
+	switch code / 100 {
+	case 400:
+		httpCode = http.StatusBadRequest
+	case 401, 409:
+		httpCode = http.StatusConflict
+	case 403:
+		httpCode = http.StatusForbidden
+	case 404:
+		httpCode = http.StatusNotFound
+	case 500:
+		httpCode = http.StatusInternalServerError
+	}
+
+	dto.Error(c, httpCode, int(code), message)
+}
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	orderv1 "github.com/xiebiao/bookstore/proto/orderv1"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/client"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/dto"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/middleware"
+)
+
+// FlashSaleHandler 秒杀相关HTTP处理器
+//
+// 教学要点：
+// 1. 抢购接口只返回排队凭证，订单由order-service异步创建，前端凭凭证轮询结果
+// 2. 活动列表/详情公开，抢购和查询结果需要鉴权（user_id取自JWT）
+type FlashSaleHandler struct {
+	orderClient *client.OrderClient
+}
+
+// NewFlashSaleHandler 创建秒杀处理器
+func NewFlashSaleHandler(orderClient *client.OrderClient) *FlashSaleHandler {
+	return &FlashSaleHandler{
+		orderClient: orderClient,
+	}
+}
+
+// CreateFlashSale 创建秒杀活动
+//
+// 教学说明：
+// operator_id取自JWT，只有图书的发布者可以创建（order-service校验，非本人返回403）
+//
+// @Summary 创建秒杀活动
+// @Tags 秒杀
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateFlashSaleRequest true "活动信息"
+// @Success 200 {object} dto.Response{data=dto.FlashSaleResponse}
+// @Security BearerAuth
+// @Router /api/v1/flash-sales [post]
+func (h *FlashSaleHandler) CreateFlashSale(c *gin.Context) {
+	var req dto.CreateFlashSaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.orderClient.CreateFlashSale(context.Background(), &orderv1.CreateFlashSaleRequest{
+		BookId:       req.BookID,
+		Price:        req.Price,
+		Stock:        req.Stock,
+		PerUserLimit: req.PerUserLimit,
+		StartAt:      req.StartAt,
+		EndAt:        req.EndAt,
+		OperatorId:   middleware.GetUserID(c),
+	})
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	dto.SuccessWithMessage(c, resp.Message, toFlashSaleResponse(resp.Sale))
+}
+
+// ListFlashSales 秒杀活动列表（未结束的活动，按开始时间升序）
+//
+// @Summary 秒杀活动列表
+// @Tags 秒杀
+// @Produce json
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} dto.Response{data=dto.ListFlashSalesResponse}
+// @Router /api/v1/flash-sales [get]
+func (h *FlashSaleHandler) ListFlashSales(c *gin.Context) {
+	var req dto.ListFlashSalesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		dto.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+
+	resp, err := h.orderClient.ListFlashSales(context.Background(), req.Page, req.PageSize)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	list := make([]dto.FlashSaleResponse, 0, len(resp.Sales))
+	for _, sale := range resp.Sales {
+		list = append(list, toFlashSaleResponse(sale))
+	}
+
+	dto.Success(c, dto.ListFlashSalesResponse{
+		List:     list,
+		Total:    resp.Total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	})
+}
+
+// GetFlashSale 秒杀活动详情（含剩余活动库存）
+//
+// @Summary 秒杀活动详情
+// @Tags 秒杀
+// @Produce json
+// @Param id path int true "活动ID"
+// @Success 200 {object} dto.Response{data=dto.FlashSaleResponse}
+// @Router /api/v1/flash-sales/{id} [get]
+func (h *FlashSaleHandler) GetFlashSale(c *gin.Context) {
+	saleID, ok := parseSaleID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.orderClient.GetFlashSale(context.Background(), saleID)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	dto.Success(c, toFlashSaleResponse(resp.Sale))
+}
+
+// Purchase 秒杀抢购
+//
+// 教学说明：
+// 返回成功只代表抢到名额，订单异步创建；40901已抢完、40902超过限购、40903未开始、40904已结束
+//
+// @Summary 秒杀抢购
+// @Tags 秒杀
+// @Accept json
+// @Produce json
+// @Param id path int true "活动ID"
+// @Param request body dto.PurchaseFlashSaleRequest false "抢购数量（默认1件）"
+// @Success 200 {object} dto.Response{data=dto.PurchaseFlashSaleResponse}
+// @Security BearerAuth
+// @Router /api/v1/flash-sales/{id}/purchase [post]
+func (h *FlashSaleHandler) Purchase(c *gin.Context) {
+	saleID, ok := parseSaleID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.PurchaseFlashSaleRequest
+	// 请求体可选，允许空body
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			dto.BadRequest(c, "参数错误: "+err.Error())
+			return
+		}
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+
+	resp, err := h.orderClient.PurchaseFlashSale(context.Background(), saleID, middleware.GetUserID(c), req.Quantity)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	dto.SuccessWithMessage(c, resp.Message, dto.PurchaseFlashSaleResponse{Ticket: resp.Ticket})
+}
+
+// GetResult 查询秒杀结果
+//
+// @Summary 查询秒杀结果
+// @Tags 秒杀
+// @Produce json
+// @Param id path int true "活动ID"
+// @Param ticket path string true "排队凭证"
+// @Success 200 {object} dto.Response{data=dto.FlashSaleResultResponse}
+// @Security BearerAuth
+// @Router /api/v1/flash-sales/{id}/results/{ticket} [get]
+func (h *FlashSaleHandler) GetResult(c *gin.Context) {
+	saleID, ok := parseSaleID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.orderClient.GetFlashSaleResult(context.Background(), saleID, middleware.GetUserID(c), c.Param("ticket"))
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	dto.Success(c, dto.FlashSaleResultResponse{
+		Status:  resp.Status,
+		OrderID: resp.OrderId,
+		Reason:  resp.Reason,
+	})
+}
+
+// parseSaleID 解析路径中的活动ID
+func parseSaleID(c *gin.Context) (uint64, bool) {
+	saleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || saleID == 0 {
+		dto.BadRequest(c, "活动ID格式错误")
+		return 0, false
+	}
+	return saleID, true
+}
+
+// toFlashSaleResponse Protobuf → HTTP DTO
+func toFlashSaleResponse(s *orderv1.FlashSale) dto.FlashSaleResponse {
+	if s == nil {
+		return dto.FlashSaleResponse{}
+	}
+	return dto.FlashSaleResponse{
+		ID:                s.Id,
+		BookID:            s.BookId,
+		BookTitle:         s.BookTitle,
+		Price:             s.Price,
+		PriceYuan:         dto.FormatPriceYuan(s.Price),
+		OriginalPrice:     s.OriginalPrice,
+		OriginalPriceYuan: dto.FormatPriceYuan(s.OriginalPrice),
+		Stock:             s.Stock,
+		RemainingStock:    s.RemainingStock,
+		PerUserLimit:      s.PerUserLimit,
+		StartAt:           dto.FormatUnixTime(s.StartAt),
+		EndAt:             dto.FormatUnixTime(s.EndAt),
+		Phase:             s.Phase,
+	}
+}
@@ -0,0 +1,497 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	inventoryv1 "github.com/xiebiao/bookstore/proto/inventoryv1"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/client"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/dto"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/middleware"
+)
+
+// InventoryHandler 库存相关HTTP处理器
+//
+// 教学要点：
+// 只开放查询、补货、库存调整和到货提醒；扣减/释放由order-service的Saga内部调用
+type InventoryHandler struct {
+	inventoryClient *client.InventoryClient
+	catalogClient   *client.CatalogClient // 写库存前查询图书的发布者
+}
+
+// NewInventoryHandler 创建库存处理器
+func NewInventoryHandler(inventoryClient *client.InventoryClient, catalogClient *client.CatalogClient) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryClient: inventoryClient,
+		catalogClient:   catalogClient,
+	}
+}
+
+// GetStock 查询库存
+//
+// @Summary 查询库存
+// @Tags 库存
+// @Produce json
+// @Param id path int true "图书ID"
+// @Success 200 {object} dto.Response{data=dto.StockResponse}
+// @Router /api/v1/inventory/{id} [get]
+func (h *InventoryHandler) GetStock(c *gin.Context) {
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || bookID == 0 {
+		dto.BadRequest(c, "图书ID格式错误")
+		return
+	}
+
+	resp, err := h.inventoryClient.GetStock(context.Background(), bookID)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	warehouses := make([]dto.WarehouseStockResponse, 0, len(resp.Warehouses))
+	for _, wh := range resp.Warehouses {
+		warehouses = append(warehouses, dto.WarehouseStockResponse{
+			WarehouseID: wh.WarehouseId,
+			Name:        wh.Name,
+			Stock:       wh.Stock,
+		})
+	}
+
+	dto.Success(c, dto.StockResponse{
+		BookID:     resp.BookId,
+		Stock:      resp.Stock,
+		Warehouses: warehouses,
+	})
+}
+
+// Restock 补充库存
+//
+// 教学说明：
+// 只有图书的发布者可以补货（与修改图书、调价相同的归属规则），普通登录用户返回403
+//
+// @Summary 补充库存
+// @Tags 库存
+// @Accept json
+// @Produce json
+// @Param id path int true "图书ID"
+// @Param request body dto.RestockRequest true "补货数量"
+// @Success 200 {object} dto.Response{data=dto.StockResponse}
+// @Security BearerAuth
+// @Router /api/v1/inventory/{id}/restock [post]
+func (h *InventoryHandler) Restock(c *gin.Context) {
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || bookID == 0 {
+		dto.BadRequest(c, "图书ID格式错误")
+		return
+	}
+
+	var req dto.RestockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if !h.requireBookPublisher(c, bookID) {
+		return
+	}
+
+	resp, err := h.inventoryClient.RestockInventory(context.Background(), bookID, req.Quantity, req.WarehouseID)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	dto.SuccessWithMessage(c, resp.Message, dto.StockResponse{
+		BookID: bookID,
+		Stock:  resp.CurrentStock,
+	})
+}
+
+// requireBookPublisher 校验当前用户是图书的发布者，失败时已写入响应
+//
+// 教学说明：
+// 网关没有管理员角色，图书的写权限归发布者，与catalog-service的归属校验一致
+func (h *InventoryHandler) requireBookPublisher(c *gin.Context, bookID uint64) bool {
+	resp, err := h.catalogClient.GetBook(context.Background(), bookID)
+	if err != nil {
+		handleGRPCError(c, err)
+		return false
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return false
+	}
+	if resp.Book == nil || resp.Book.PublisherId != middleware.GetUserID(c) {
+		dto.Forbidden(c, "只有图书发布者可以操作库存")
+		return false
+	}
+	return true
+}
+
+// requireBooksPublisher 校验批量图书都由当前用户发布，任一本不满足时整批拒绝，失败时已写入响应
+func (h *InventoryHandler) requireBooksPublisher(c *gin.Context, bookIDs []uint64) bool {
+	resp, err := h.catalogClient.BatchGetBooks(context.Background(), bookIDs)
+	if err != nil {
+		handleGRPCError(c, err)
+		return false
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return false
+	}
+
+	// BatchGetBooks不返回不存在的图书，按ID对照
+	publishers := make(map[uint64]uint64, len(resp.Books))
+	for _, b := range resp.Books {
+		publishers[b.Id] = b.PublisherId
+	}
+	userID := middleware.GetUserID(c)
+	for _, id := range bookIDs {
+		if publisherID, ok := publishers[id]; !ok || publisherID != userID {
+			dto.Forbidden(c, fmt.Sprintf("只有图书发布者可以操作库存[图书:%d]", id))
+			return false
+		}
+	}
+	return true
+}
+
+// Adjust 库存调整（报损、丢失、盘点差异、退货入库）
+//
+// 教学说明：
+// 与补货相同，只有图书的发布者可以调整库存
+//
+// @Summary 库存调整
+// @Tags 库存
+// @Accept json
+// @Produce json
+// @Param id path int true "图书ID"
+// @Param request body dto.AdjustStockRequest true "调整数量和原因"
+// @Success 200 {object} dto.Response{data=dto.AdjustStockResponse}
+// @Security BearerAuth
+// @Router /api/v1/inventory/{id}/adjust [post]
+func (h *InventoryHandler) Adjust(c *gin.Context) {
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || bookID == 0 {
+		dto.BadRequest(c, "图书ID格式错误")
+		return
+	}
+
+	var req dto.AdjustStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if !h.requireBookPublisher(c, bookID) {
+		return
+	}
+
+	resp, err := h.inventoryClient.AdjustStock(context.Background(), &inventoryv1.AdjustStockRequest{
+		BookId:      bookID,
+		Quantity:    req.Quantity,
+		Reason:      req.Reason,
+		WarehouseId: req.WarehouseID,
+		Remark:      req.Remark,
+	})
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	dto.SuccessWithMessage(c, resp.Message, dto.AdjustStockResponse{
+		BookID:      bookID,
+		BeforeStock: resp.BeforeStock,
+		Stock:       resp.CurrentStock,
+	})
+}
+
+// Stocktake 盘点导入
+//
+// 教学说明：
+// 1. 先校验每本图书都由当前用户发布，任一本不满足时整批拒绝（403），不调整任何图书
+// 2. 校验通过后逐本返回结果，部分图书失败（盘亏大于可用库存等）不影响其他图书，HTTP状态仍为200
+//
+// @Summary 盘点导入
+// @Tags 库存
+// @Accept json
+// @Produce json
+// @Param request body dto.StocktakeRequest true "实盘数量"
+// @Success 200 {object} dto.Response{data=dto.StocktakeResponse}
+// @Security BearerAuth
+// @Router /api/v1/inventory/stocktake [post]
+func (h *InventoryHandler) Stocktake(c *gin.Context) {
+	var req dto.StocktakeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	items := make([]*inventoryv1.StocktakeItem, 0, len(req.Items))
+	bookIDs := make([]uint64, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, &inventoryv1.StocktakeItem{
+			BookId:          item.BookID,
+			CountedQuantity: *item.CountedQuantity,
+		})
+		bookIDs = append(bookIDs, item.BookID)
+	}
+
+	if !h.requireBooksPublisher(c, bookIDs) {
+		return
+	}
+
+	resp, err := h.inventoryClient.ImportStocktake(context.Background(), &inventoryv1.ImportStocktakeRequest{
+		Items:       items,
+		WarehouseId: req.WarehouseID,
+		Remark:      req.Remark,
+	})
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	results := make([]dto.StocktakeResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		results = append(results, dto.StocktakeResult{
+			BookID:          r.BookId,
+			CountedQuantity: r.CountedQuantity,
+			Difference:      r.Difference,
+			BeforeStock:     r.BeforeStock,
+			Stock:           r.CurrentStock,
+			Code:            r.Code,
+			Message:         r.Message,
+		})
+	}
+
+	dto.SuccessWithMessage(c, resp.Message, dto.StocktakeResponse{
+		Results:  results,
+		Adjusted: resp.Adjusted,
+		Failed:   resp.Failed,
+	})
+}
+
+// GetLogs 查询库存变更日志
+//
+// @Summary 库存变更日志
+// @Tags 库存
+// @Produce json
+// @Param id path int true "图书ID"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Param order_id query int false "订单ID（指定时返回该订单在此图书上的全部流水）"
+// @Success 200 {object} dto.Response{data=dto.InventoryLogsResponse}
+// @Security BearerAuth
+// @Router /api/v1/inventory/{id}/logs [get]
+func (h *InventoryHandler) GetLogs(c *gin.Context) {
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || bookID == 0 {
+		dto.BadRequest(c, "图书ID格式错误")
+		return
+	}
+
+	var req dto.InventoryLogsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		dto.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+
+	resp, err := h.inventoryClient.GetInventoryLogs(context.Background(), bookID, req.OrderID, req.Page, req.PageSize)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	list := make([]dto.InventoryLogResponse, 0, len(resp.Logs))
+	for _, l := range resp.Logs {
+		list = append(list, dto.InventoryLogResponse{
+			ID:          l.Id,
+			BookID:      l.BookId,
+			ChangeType:  l.ChangeType,
+			Quantity:    l.Quantity,
+			BeforeStock: l.BeforeStock,
+			AfterStock:  l.AfterStock,
+			OrderID:     l.OrderId,
+			WarehouseID: l.WarehouseId,
+			CreatedAt:   dto.FormatUnixTime(l.CreatedAt),
+		})
+	}
+
+	dto.Success(c, dto.InventoryLogsResponse{
+		List:  list,
+		Total: resp.Total,
+	})
+}
+
+// GetLedger 按库存日志重放历史库存
+//
+// 教学说明：
+// 财务月结按截止时间查询库存（如"1号零点的库存"），consistent=false时gaps列出日志链断点
+//
+// @Summary 历史库存（日志重放）
+// @Tags 库存
+// @Produce json
+// @Param id path int true "图书ID"
+// @Param as_of query int false "截止时间（Unix秒）"
+// @Success 200 {object} dto.Response{data=dto.LedgerResponse}
+// @Security BearerAuth
+// @Router /api/v1/inventory/{id}/ledger [get]
+func (h *InventoryHandler) GetLedger(c *gin.Context) {
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || bookID == 0 {
+		dto.BadRequest(c, "图书ID格式错误")
+		return
+	}
+
+	var req dto.LedgerRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		dto.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+	if req.AsOf == 0 {
+		req.AsOf = time.Now().Unix()
+	}
+
+	resp, err := h.inventoryClient.ReplayLedger(context.Background(), []uint64{bookID}, req.AsOf)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+	if len(resp.Books) == 0 {
+		dto.InternalError(c, "重放结果为空")
+		return
+	}
+
+	r := resp.Books[0]
+	warehouses := make([]dto.WarehouseStockResponse, 0, len(r.Warehouses))
+	for _, wh := range r.Warehouses {
+		warehouses = append(warehouses, dto.WarehouseStockResponse{
+			WarehouseID: wh.WarehouseId,
+			Name:        wh.Name,
+			Stock:       wh.Stock,
+		})
+	}
+	gaps := make([]dto.LedgerGapResponse, 0, len(r.Gaps))
+	for _, g := range r.Gaps {
+		gaps = append(gaps, dto.LedgerGapResponse{
+			LogID:       g.LogId,
+			PrevLogID:   g.PrevLogId,
+			WarehouseID: g.WarehouseId,
+			Kind:        g.Kind,
+			Expected:    g.Expected,
+			Actual:      g.Actual,
+		})
+	}
+
+	dto.Success(c, dto.LedgerResponse{
+		BookID:        r.BookId,
+		AsOf:          dto.FormatUnixTime(req.AsOf),
+		OpeningStock:  r.OpeningStock,
+		Stock:         r.Stock,
+		RecordedStock: r.RecordedStock,
+		LockedStock:   r.LockedStock,
+		Warehouses:    warehouses,
+		LogCount:      r.LogCount,
+		LastLogAt:     dto.FormatUnixTime(r.LastLogAt),
+		Consistent:    r.Consistent,
+		GapCount:      r.GapCount,
+		Gaps:          gaps,
+	})
+}
+
+// SubscribeBackInStock 订阅到货提醒
+//
+// 教学说明：
+// user_id取自JWT；补货使库存从0变为有货时，inventory-service按批通知订阅用户（每次订阅通知一次）
+//
+// @Summary 订阅到货提醒
+// @Tags 库存
+// @Produce json
+// @Param id path int true "图书ID"
+// @Success 200 {object} dto.Response{data=dto.BackInStockSubscriptionResponse}
+// @Security BearerAuth
+// @Router /api/v1/inventory/{id}/subscription [post]
+func (h *InventoryHandler) SubscribeBackInStock(c *gin.Context) {
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || bookID == 0 {
+		dto.BadRequest(c, "图书ID格式错误")
+		return
+	}
+
+	resp, err := h.inventoryClient.SubscribeBackInStock(context.Background(), middleware.GetUserID(c), bookID)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	dto.SuccessWithMessage(c, resp.Message, dto.BackInStockSubscriptionResponse{
+		BookID: bookID,
+		Stock:  resp.CurrentStock,
+	})
+}
+
+// UnsubscribeBackInStock 取消到货提醒
+//
+// @Summary 取消到货提醒
+// @Tags 库存
+// @Produce json
+// @Param id path int true "图书ID"
+// @Success 200 {object} dto.Response
+// @Security BearerAuth
+// @Router /api/v1/inventory/{id}/subscription [delete]
+func (h *InventoryHandler) UnsubscribeBackInStock(c *gin.Context) {
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || bookID == 0 {
+		dto.BadRequest(c, "图书ID格式错误")
+		return
+	}
+
+	resp, err := h.inventoryClient.UnsubscribeBackInStock(context.Background(), middleware.GetUserID(c), bookID)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	dto.SuccessWithMessage(c, resp.Message, nil)
+}
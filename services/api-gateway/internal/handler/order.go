@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	orderv1 "github.com/xiebiao/bookstore/proto/orderv1"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/client"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/dto"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/middleware"
+)
+
+// OrderHandler 订单相关HTTP处理器
+//
+// 教学要点：
+// 1. 所有订单接口都需要鉴权（路由组挂Auth中间件）
+// 2. user_id一律取自JWT，不接受客户端传入
+// 3. 查询单个订单时校验归属，防止水平越权（遍历订单ID看他人订单）
+type OrderHandler struct {
+	orderClient *client.OrderClient
+}
+
+// NewOrderHandler 创建订单处理器
+func NewOrderHandler(orderClient *client.OrderClient) *OrderHandler {
+	return &OrderHandler{
+		orderClient: orderClient,
+	}
+}
+
+// CreateOrder 创建订单
+//
+// @Summary 创建订单
+// @Tags 订单
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateOrderRequest true "订单明细"
+// @Success 200 {object} dto.Response{data=dto.CreateOrderResponse}
+// @Security BearerAuth
+// @Router /api/v1/orders [post]
+func (h *OrderHandler) CreateOrder(c *gin.Context) {
+	var req dto.CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	items := make([]*orderv1.OrderItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, &orderv1.OrderItem{
+			BookId:   item.BookID,
+			Quantity: item.Quantity,
+		})
+	}
+
+	resp, err := h.orderClient.CreateOrder(context.Background(), middleware.GetUserID(c), items)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	dto.SuccessWithMessage(c, resp.Message, dto.CreateOrderResponse{
+		OrderID:   resp.OrderId,
+		OrderNo:   resp.OrderNo,
+		Total:     resp.Total,
+		TotalYuan: dto.FormatPriceYuan(resp.Total),
+		Status:    dto.OrderStatusText(1),
+	})
+}
+
+// GetOrder 订单详情
+//
+// @Summary 订单详情
+// @Tags 订单
+// @Produce json
+// @Param id path int true "订单ID"
+// @Success 200 {object} dto.Response{data=dto.OrderResponse}
+// @Security BearerAuth
+// @Router /api/v1/orders/{id} [get]
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	order, ok := loadOwnedOrder(c, h.orderClient)
+	if !ok {
+		return
+	}
+
+	dto.Success(c, toOrderResponse(order))
+}
+
+// ListOrders 我的订单列表
+//
+// @Summary 我的订单列表
+// @Tags 订单
+// @Produce json
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Param status query int false "状态筛选（1-5，不传为全部）"
+// @Param cursor query string false "分页游标（上一页的next_cursor）"
+// @Success 200 {object} dto.Response{data=dto.ListOrdersResponse}
+// @Security BearerAuth
+// @Router /api/v1/orders [get]
+func (h *OrderHandler) ListOrders(c *gin.Context) {
+	var req dto.ListOrdersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		dto.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+
+	resp, err := h.orderClient.ListUserOrders(context.Background(), middleware.GetUserID(c), req.Page, req.PageSize, req.Status, req.Cursor)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	list := make([]dto.OrderResponse, 0, len(resp.Orders))
+	for _, o := range resp.Orders {
+		list = append(list, toOrderResponse(o))
+	}
+
+	page := req.Page
+	if req.Cursor != "" {
+		page = 0 // 游标分页没有页码
+	}
+
+	dto.Success(c, dto.ListOrdersResponse{
+		List:       list,
+		Total:      resp.Total,
+		Page:       page,
+		PageSize:   req.PageSize,
+		NextCursor: resp.NextCursor,
+	})
+}
+
+// CancelOrder 取消订单
+//
+// 教学说明：
+// 归属校验由order-service完成（CancelOrderRequest携带user_id）
+//
+// @Summary 取消订单
+// @Tags 订单
+// @Accept json
+// @Produce json
+// @Param id path int true "订单ID"
+// @Param request body dto.CancelOrderRequest false "取消原因"
+// @Success 200 {object} dto.Response
+// @Security BearerAuth
+// @Router /api/v1/orders/{id}/cancel [post]
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || orderID == 0 {
+		dto.BadRequest(c, "订单ID格式错误")
+		return
+	}
+
+	var req dto.CancelOrderRequest
+	// 请求体可选，允许空body
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			dto.BadRequest(c, "参数错误: "+err.Error())
+			return
+		}
+	}
+	if req.Reason == "" {
+		req.Reason = "用户取消"
+	}
+
+	resp, err := h.orderClient.CancelOrder(context.Background(), orderID, middleware.GetUserID(c), req.Reason)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	dto.SuccessWithMessage(c, resp.Message, nil)
+}
+
+// loadOwnedOrder 查询订单并校验归属
+//
+// 教学重点：
+// 订单不属于当前用户时返回404而不是403，避免泄露"该订单ID存在"的信息
+func loadOwnedOrder(c *gin.Context, orderClient *client.OrderClient) (*orderv1.Order, bool) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || orderID == 0 {
+		dto.BadRequest(c, "订单ID格式错误")
+		return nil, false
+	}
+
+	resp, err := orderClient.GetOrder(context.Background(), orderID)
+	if err != nil {
+		handleGRPCError(c, err)
+		return nil, false
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return nil, false
+	}
+	if resp.Order == nil || resp.Order.UserId != middleware.GetUserID(c) {
+		dto.NotFound(c, "订单不存在")
+		return nil, false
+	}
+
+	return resp.Order, true
+}
+
+// toOrderResponse Protobuf → HTTP DTO
+func toOrderResponse(o *orderv1.Order) dto.OrderResponse {
+	items := make([]dto.OrderItemResponse, 0, len(o.Items))
+	for _, item := range o.Items {
+		items = append(items, dto.OrderItemResponse{
+			BookID:    item.BookId,
+			BookTitle: item.BookTitle,
+			Quantity:  item.Quantity,
+			Price:     item.Price,
+			PriceYuan: dto.FormatPriceYuan(item.Price),
+		})
+	}
+
+	return dto.OrderResponse{
+		ID:         o.Id,
+		OrderNo:    o.OrderNo,
+		UserID:     o.UserId,
+		Total:      o.Total,
+		TotalYuan:  dto.FormatPriceYuan(o.Total),
+		Status:     o.Status,
+		StatusText: dto.OrderStatusText(o.Status),
+		Items:      items,
+		CreatedAt:  dto.FormatUnixTime(o.CreatedAt),
+		UpdatedAt:  dto.FormatUnixTime(o.UpdatedAt),
+	}
+}
@@ -0,0 +1,311 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	orderv1 "github.com/xiebiao/bookstore/proto/orderv1"
+	paymentv1 "github.com/xiebiao/bookstore/proto/paymentv1"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/client"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/dto"
+)
+
+// PaymentHandler 支付相关HTTP处理器
+//
+// 教学要点：
+// 1. 支付接口挂在订单资源下：/orders/:id/pay、/orders/:id/payment、/orders/:id/refund
+// 2. 先查订单校验归属，再用订单金额发起支付（客户端不能指定金额）
+type PaymentHandler struct {
+	paymentClient *client.PaymentClient
+	orderClient   *client.OrderClient
+}
+
+// NewPaymentHandler 创建支付处理器
+func NewPaymentHandler(paymentClient *client.PaymentClient, orderClient *client.OrderClient) *PaymentHandler {
+	return &PaymentHandler{
+		paymentClient: paymentClient,
+		orderClient:   orderClient,
+	}
+}
+
+// Pay 支付订单
+//
+// 教学说明：
+// 只有待支付的订单可以发起支付（已取消、已支付的订单返回40001），
+// 避免订单超时取消后用户仍然付款，只能再走退款
+//
+// @Summary 支付订单
+// @Tags 支付
+// @Accept json
+// @Produce json
+// @Param id path int true "订单ID"
+// @Param request body dto.PayRequest true "支付方式"
+// @Success 200 {object} dto.Response{data=dto.PayResponse}
+// @Security BearerAuth
+// @Router /api/v1/orders/{id}/pay [post]
+func (h *PaymentHandler) Pay(c *gin.Context) {
+	var req dto.PayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	order, ok := loadOwnedOrder(c, h.orderClient)
+	if !ok {
+		return
+	}
+	if order.Status != dto.OrderStatusPending {
+		dto.Error(c, http.StatusBadRequest, 40001, fmt.Sprintf("订单%s，不能支付", dto.OrderStatusText(order.Status)))
+		return
+	}
+
+	resp, err := h.paymentClient.Pay(context.Background(), order.Id, order.Total, req.PaymentMethod)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	dto.SuccessWithMessage(c, resp.Message, dto.PayResponse{
+		PaymentNo:    resp.PaymentNo,
+		ThirdPartyNo: resp.ThirdPartyNo,
+		Status:       resp.Status,
+		StatusText:   dto.PaymentStatusText(resp.Status),
+	})
+}
+
+// GetPayment 查询订单支付状态
+//
+// @Summary 查询支付状态
+// @Tags 支付
+// @Produce json
+// @Param id path int true "订单ID"
+// @Success 200 {object} dto.Response{data=dto.PaymentResponse}
+// @Security BearerAuth
+// @Router /api/v1/orders/{id}/payment [get]
+func (h *PaymentHandler) GetPayment(c *gin.Context) {
+	order, ok := loadOwnedOrder(c, h.orderClient)
+	if !ok {
+		return
+	}
+
+	resp, err := h.paymentClient.GetPaymentStatus(context.Background(), order.Id)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	p := resp.Payment
+	dto.Success(c, dto.PaymentResponse{
+		PaymentNo:      p.PaymentNo,
+		OrderID:        p.OrderId,
+		Amount:         p.Amount,
+		AmountYuan:     dto.FormatPriceYuan(p.Amount),
+		Status:         p.Status,
+		StatusText:     dto.PaymentStatusText(p.Status),
+		PaymentMethod:  p.PaymentMethod,
+		ThirdPartyNo:   p.ThirdPartyNo,
+		RefundedAmount: p.RefundedAmount,
+		CreatedAt:      dto.FormatUnixTime(p.CreatedAt),
+		UpdatedAt:      dto.FormatUnixTime(p.UpdatedAt),
+	})
+}
+
+// Refund 申请退款
+//
+// 教学要点：
+// 1. 按明细退款时，单价和下单数量从订单读取，客户端只能指定退哪一项、退几本
+// 2. 可退额度和累计数量由payment-service在事务中校验（支持多次部分退款）
+//
+// @Summary 申请退款
+// @Tags 支付
+// @Accept json
+// @Produce json
+// @Param id path int true "订单ID"
+// @Param request body dto.RefundRequest true "退款信息"
+// @Success 200 {object} dto.Response{data=dto.RefundResponse}
+// @Security BearerAuth
+// @Router /api/v1/orders/{id}/refund [post]
+func (h *PaymentHandler) Refund(c *gin.Context) {
+	var req dto.RefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	order, ok := loadOwnedOrder(c, h.orderClient)
+	if !ok {
+		return
+	}
+	if req.Amount > order.Total {
+		dto.BadRequest(c, "退款金额不能超过订单金额")
+		return
+	}
+
+	orderItems := make(map[uint64]*orderv1.OrderItemDetail, len(order.Items))
+	for _, item := range order.Items {
+		orderItems[item.Id] = item
+	}
+
+	items := make([]*paymentv1.RefundItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		orderItem, exists := orderItems[item.OrderItemID]
+		if !exists {
+			dto.BadRequest(c, fmt.Sprintf("订单明细[%d]不存在", item.OrderItemID))
+			return
+		}
+		if item.Quantity > orderItem.Quantity {
+			dto.BadRequest(c, fmt.Sprintf("订单明细[%d]退款数量超过购买数量", item.OrderItemID))
+			return
+		}
+		items = append(items, &paymentv1.RefundItem{
+			OrderItemId:     orderItem.Id,
+			BookId:          orderItem.BookId,
+			Quantity:        item.Quantity,
+			Amount:          orderItem.Price * int64(item.Quantity),
+			OrderedQuantity: orderItem.Quantity,
+		})
+	}
+
+	resp, err := h.paymentClient.Refund(context.Background(), &paymentv1.RefundRequest{
+		OrderId:   order.Id,
+		Amount:    req.Amount,
+		Reason:    req.Reason,
+		Items:     items,
+		RequestId: req.RequestID,
+	})
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	dto.SuccessWithMessage(c, resp.Message, toRefundResponse(resp.Refund))
+}
+
+// ListRefunds 查询订单退款记录
+//
+// @Summary 查询退款记录
+// @Tags 支付
+// @Produce json
+// @Param id path int true "订单ID"
+// @Success 200 {object} dto.Response{data=dto.ListRefundsResponse}
+// @Security BearerAuth
+// @Router /api/v1/orders/{id}/refunds [get]
+func (h *PaymentHandler) ListRefunds(c *gin.Context) {
+	order, ok := loadOwnedOrder(c, h.orderClient)
+	if !ok {
+		return
+	}
+
+	resp, err := h.paymentClient.ListRefunds(context.Background(), order.Id)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	refunds := make([]dto.RefundResponse, 0, len(resp.Refunds))
+	for _, refund := range resp.Refunds {
+		refunds = append(refunds, toRefundResponse(refund))
+	}
+	dto.Success(c, dto.ListRefundsResponse{
+		PaidAmount:     resp.PaidAmount,
+		RefundedAmount: resp.RefundedAmount,
+		Refunds:        refunds,
+	})
+}
+
+// toRefundResponse Protobuf退款记录 → HTTP响应
+func toRefundResponse(refund *paymentv1.Refund) dto.RefundResponse {
+	if refund == nil {
+		return dto.RefundResponse{}
+	}
+
+	items := make([]dto.RefundItemResponse, 0, len(refund.Items))
+	for _, item := range refund.Items {
+		items = append(items, dto.RefundItemResponse{
+			OrderItemID: item.OrderItemId,
+			BookID:      item.BookId,
+			Quantity:    item.Quantity,
+			Amount:      item.Amount,
+		})
+	}
+
+	return dto.RefundResponse{
+		RefundNo:   refund.RefundNo,
+		Amount:     refund.Amount,
+		AmountYuan: dto.FormatPriceYuan(refund.Amount),
+		Reason:     refund.Reason,
+		Status:     refund.Status,
+		StatusText: dto.RefundStatusText(refund.Status),
+		FailReason: refund.FailReason,
+		Items:      items,
+		CreatedAt:  dto.FormatUnixTime(refund.CreatedAt),
+	}
+}
+
+// paymentSignatureHeader 支付网关回调签名头
+const paymentSignatureHeader = "X-Payment-Signature"
+
+// maxCallbackBodySize 回调报文大小上限（防止恶意大包）
+const maxCallbackBodySize = 64 << 10
+
+// Callback 支付网关回调
+//
+// 教学要点：
+// 1. 回调由第三方网关发起，不走JWT鉴权，安全性依赖payment-service验签
+// 2. 必须透传原始字节：重新序列化JSON会改变字段顺序/空格，导致验签失败
+// 3. 响应遵循网关约定：处理成功返回200 "success"，否则返回非200，网关会重试
+//
+// @Summary 支付网关回调
+// @Tags 支付
+// @Accept json
+// @Produce plain
+// @Param provider path string true "网关标识（如sandbox）"
+// @Success 200 {string} string "success"
+// @Router /api/v1/payments/callback/{provider} [post]
+func (h *PaymentHandler) Callback(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxCallbackBodySize))
+	if err != nil {
+		c.String(http.StatusBadRequest, "fail")
+		return
+	}
+
+	resp, err := h.paymentClient.PaymentCallback(
+		context.Background(),
+		c.Param("provider"),
+		payload,
+		c.GetHeader(paymentSignatureHeader),
+	)
+	if err != nil {
+		c.String(http.StatusBadGateway, "fail")
+		return
+	}
+
+	switch resp.Code {
+	case 0:
+		c.String(http.StatusOK, "success")
+	case 40100:
+		c.String(http.StatusUnauthorized, "fail")
+	default:
+		c.String(http.StatusBadRequest, "fail")
+	}
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	catalogv1 "github.com/xiebiao/bookstore/proto/catalogv1"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/client"
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/dto"
+)
+
+// SearchHandler 搜索词典管理HTTP处理器
+//
+// 教学要点：
+// 同义词组里的词互为同义词（如"计算机、电脑"），同时作为分词词表；
+// 修改后catalog-service立即生效，无需重建索引
+type SearchHandler struct {
+	catalogClient *client.CatalogClient
+}
+
+// NewSearchHandler 创建搜索词典处理器
+func NewSearchHandler(catalogClient *client.CatalogClient) *SearchHandler {
+	return &SearchHandler{catalogClient: catalogClient}
+}
+
+// ListSynonyms 同义词列表
+//
+// @Summary 同义词列表
+// @Tags 搜索
+// @Produce json
+// @Success 200 {object} dto.Response{data=dto.ListSynonymGroupsResponse}
+// @Security BearerAuth
+// @Router /api/v1/search/synonyms [get]
+func (h *SearchHandler) ListSynonyms(c *gin.Context) {
+	resp, err := h.catalogClient.ListSynonymGroups(context.Background())
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	list := make([]dto.SynonymGroupResponse, 0, len(resp.Groups))
+	for _, g := range resp.Groups {
+		list = append(list, toSynonymGroupResponse(g))
+	}
+	dto.Success(c, dto.ListSynonymGroupsResponse{List: list})
+}
+
+// CreateSynonyms 新建同义词组（仅管理员，见middleware.AdminRequired）
+//
+// @Summary 新建同义词组
+// @Tags 搜索
+// @Accept json
+// @Produce json
+// @Param request body dto.SaveSynonymGroupRequest true "同义词"
+// @Success 200 {object} dto.Response{data=dto.SynonymGroupResponse}
+// @Security BearerAuth
+// @Router /api/v1/search/synonyms [post]
+func (h *SearchHandler) CreateSynonyms(c *gin.Context) {
+	h.saveSynonyms(c, 0)
+}
+
+// UpdateSynonyms 更新同义词组（整组替换）
+//
+// @Summary 更新同义词组
+// @Tags 搜索
+// @Accept json
+// @Produce json
+// @Param id path int true "同义词组ID"
+// @Param request body dto.SaveSynonymGroupRequest true "同义词"
+// @Success 200 {object} dto.Response{data=dto.SynonymGroupResponse}
+// @Security BearerAuth
+// @Router /api/v1/search/synonyms/{id} [put]
+func (h *SearchHandler) UpdateSynonyms(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		dto.BadRequest(c, "同义词组ID格式错误")
+		return
+	}
+	h.saveSynonyms(c, id)
+}
+
+// DeleteSynonyms 删除同义词组
+//
+// @Summary 删除同义词组
+// @Tags 搜索
+// @Produce json
+// @Param id path int true "同义词组ID"
+// @Success 200 {object} dto.Response
+// @Security BearerAuth
+// @Router /api/v1/search/synonyms/{id} [delete]
+func (h *SearchHandler) DeleteSynonyms(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		dto.BadRequest(c, "同义词组ID格式错误")
+		return
+	}
+
+	resp, err := h.catalogClient.DeleteSynonymGroup(context.Background(), id)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	dto.SuccessWithMessage(c, resp.Message, nil)
+}
+
+func (h *SearchHandler) saveSynonyms(c *gin.Context, id uint64) {
+	var req dto.SaveSynonymGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.catalogClient.SaveSynonymGroup(context.Background(), id, req.Words)
+	if err != nil {
+		handleGRPCError(c, err)
+		return
+	}
+	if resp.Code != 0 {
+		handleBizError(c, resp.Code, resp.Message)
+		return
+	}
+
+	dto.SuccessWithMessage(c, resp.Message, toSynonymGroupResponse(resp.Group))
+}
+
+// toSynonymGroupResponse Protobuf → HTTP DTO
+func toSynonymGroupResponse(g *catalogv1.SynonymGroup) dto.SynonymGroupResponse {
+	return dto.SynonymGroupResponse{
+		ID:        g.Id,
+		Words:     g.Words,
+		UpdatedAt: dto.FormatUnixTime(g.UpdatedAt),
+	}
+}
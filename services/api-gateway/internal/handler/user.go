@@ -5,8 +5,6 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 
 	"github.com/xiebiao/bookstore/services/api-gateway/internal/client"
 	"github.com/xiebiao/bookstore/services/api-gateway/internal/dto"
@@ -60,7 +58,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 	resp, err := h.userClient.Register(context.Background(), req.Email, req.Password, req.Nickname)
 	if err != nil {
 		// 步骤3: gRPC错误处理
-		h.handleGRPCError(c, err)
+		handleGRPCError(c, err)
 		return
 	}
 
@@ -91,7 +89,7 @@ func (h *UserHandler) Login(c *gin.Context) {
 	// 步骤2: 调用gRPC服务
 	resp, err := h.userClient.Login(context.Background(), req.Email, req.Password)
 	if err != nil {
-		h.handleGRPCError(c, err)
+		handleGRPCError(c, err)
 		return
 	}
 
@@ -122,7 +120,7 @@ func (h *UserHandler) RefreshToken(c *gin.Context) {
 
 	resp, err := h.userClient.RefreshToken(context.Background(), req.RefreshToken)
 	if err != nil {
-		h.handleGRPCError(c, err)
+		handleGRPCError(c, err)
 		return
 	}
 
@@ -153,7 +151,7 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 	// 步骤2: 调用gRPC服务
 	resp, err := h.userClient.GetUser(context.Background(), userID)
 	if err != nil {
-		h.handleGRPCError(c, err)
+		handleGRPCError(c, err)
 		return
 	}
 
@@ -165,46 +163,6 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 	})
 }
 
-// handleGRPCError 处理gRPC错误
-//
-// 教学重点：
-// gRPC错误码 → HTTP状态码映射
-//
-// gRPC常见错误码：
-// - codes.InvalidArgument: 参数错误 → 400
-// - codes.Unauthenticated: 未认证 → 401
-// - codes.PermissionDenied: 无权限 → 403
-// - codes.NotFound: 未找到 → 404
-// - codes.Internal: 内部错误 → 500
-// - codes.Unavailable: 服务不可用 → 503
-func (h *UserHandler) handleGRPCError(c *gin.Context, err error) {
-	// 提取gRPC状态码
-	st, ok := status.FromError(err)
-	if !ok {
-		// 不是gRPC错误（网络错误等）
-		dto.InternalError(c, "服务调用失败")
-		return
-	}
-
-	// 根据gRPC错误码返回相应的HTTP错误
-	switch st.Code() {
-	case codes.InvalidArgument:
-		dto.BadRequest(c, st.Message())
-	case codes.Unauthenticated:
-		dto.Unauthorized(c, st.Message())
-	case codes.PermissionDenied:
-		dto.Forbidden(c, st.Message())
-	case codes.NotFound:
-		dto.NotFound(c, st.Message())
-	case codes.Unavailable:
-		// 服务不可用（user-service宕机）
-		dto.Error(c, 503, 50300, "服务暂时不可用，请稍后重试")
-	default:
-		// 其他错误统一返回500
-		dto.InternalError(c, st.Message())
-	}
-}
-
 // =========================================
 // 教学总结：API Gateway Handler设计
 // =========================================
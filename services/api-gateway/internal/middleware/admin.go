@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiebiao/bookstore/services/api-gateway/internal/dto"
+)
+
+// AdminRequired 管理员鉴权中间件（必须放在Auth之后，依赖Auth注入的user_id）
+//
+// 教学要点：
+// 1. 用户体系还没有角色（RBAC），先按配置的管理员用户ID白名单放行
+// 2. 白名单为空时所有请求都返回403：管理接口默认关闭，而不是默认对所有登录用户开放
+// 3. 用于影响全站的写操作（如搜索同义词词典），普通登录用户只能查询
+func AdminRequired(adminUserIDs []uint64) gin.HandlerFunc {
+	admins := make(map[uint64]struct{}, len(adminUserIDs))
+	for _, id := range adminUserIDs {
+		admins[id] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		if _, ok := admins[GetUserID(c)]; !ok {
+			dto.Forbidden(c, "需要管理员权限")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
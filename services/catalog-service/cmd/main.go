@@ -14,11 +14,15 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
+	"github.com/xiebiao/bookstore/pkg/events"
+	"github.com/xiebiao/bookstore/pkg/mq"
 	catalogv1 "github.com/xiebiao/bookstore/proto/catalogv1"
 	"github.com/xiebiao/bookstore/services/catalog-service/internal/grpc/handler"
 	"github.com/xiebiao/bookstore/services/catalog-service/internal/infrastructure/config"
+	"github.com/xiebiao/bookstore/services/catalog-service/internal/infrastructure/grpc_client"
 	"github.com/xiebiao/bookstore/services/catalog-service/internal/infrastructure/persistence/mysql"
 	redisStore "github.com/xiebiao/bookstore/services/catalog-service/internal/infrastructure/persistence/redis"
+	"github.com/xiebiao/bookstore/services/catalog-service/internal/infrastructure/search"
 )
 
 // main catalog-service主程序
@@ -81,17 +85,54 @@ func main() {
 
 	// 步骤4：创建仓储和缓存实例
 	bookRepo := mysql.NewBookRepository(db)
+	synonymRepo := mysql.NewSynonymRepository(db)
 	cacheStore := redisStore.NewCacheStore(
 		redisClient,
 		cfg.Cache.GetListTTL(),
 		cfg.Cache.GetDetailTTL(),
-		cfg.Cache.GetSearchTTL(),
 	)
 
-	// 步骤5：创建gRPC Handler
-	catalogHandler := handler.NewCatalogServiceServer(bookRepo, cacheStore)
+	// 步骤5：从MySQL构建搜索索引
+	// 教学要点：索引建好之前不对外提供服务，避免搜索结果不完整
+	searchIndex := search.NewIndex()
+	searchSyncer := search.NewSyncer(bookRepo, synonymRepo, searchIndex, cfg.Search.GetBatchSize())
 
-	// 步骤6：创建gRPC服务器
+	indexed, err := searchSyncer.Rebuild(context.Background())
+	if err != nil {
+		log.Fatalf("构建搜索索引失败: %v", err)
+	}
+	log.Printf("✅ 搜索索引构建完成（%d本图书）", indexed)
+
+	syncCtx, stopSync := context.WithCancel(context.Background())
+	defer stopSync()
+	go startSearchSync(syncCtx, searchSyncer, cfg.Search.GetRefreshInterval(), cfg.Search.GetRebuildInterval())
+
+	// 步骤6：创建gRPC Handler
+	// 教学要点：inventory-service客户端只用于对账有货状态，不在请求链路上
+	inventoryClient, err := grpc_client.NewInventoryClient(cfg.GetServiceAddr("inventory"), cfg.GetServiceTimeout("inventory"))
+	if err != nil {
+		log.Fatalf("创建inventory-service客户端失败: %v", err)
+	}
+	defer inventoryClient.Close()
+
+	catalogHandler := handler.NewCatalogServiceServer(bookRepo, synonymRepo, cacheStore, searchIndex, inventoryClient)
+
+	// 消费库存事件（缺货/到货），实时更新有货状态；定时对账兜底
+	stockConsumer, err := mq.NewConsumer(
+		cfg.MQ.URL,
+		events.Exchange,
+		events.ExchangeType,
+		cfg.MQ.GetStockQueue(),
+		[]string{events.InventoryOutOfStock, events.InventoryBackInStock},
+	)
+	if err != nil {
+		log.Fatalf("创建库存事件消费者失败: %v", err)
+	}
+	defer stockConsumer.Close()
+	go startStockConsumer(syncCtx, stockConsumer, catalogHandler)
+	go startStockStatusSync(syncCtx, catalogHandler, cfg.Stock.GetSyncInterval(), cfg.Stock.GetBatchSize())
+
+	// 步骤7：创建gRPC服务器
 	grpcServer := grpc.NewServer(
 		// 教学要点：gRPC服务器选项
 		// 1. MaxRecvMsgSize：最大接收消息大小（默认4MB）
@@ -110,7 +151,7 @@ func main() {
 	// - 生产环境可以禁用（安全性）
 	reflection.Register(grpcServer)
 
-	// 步骤7：启动gRPC服务器
+	// 步骤8：启动gRPC服务器
 	addr := fmt.Sprintf(":%d", cfg.Server.Port)
 	listener, err := net.Listen("tcp", addr)
 	if err != nil {
@@ -125,7 +166,7 @@ func main() {
 		}
 	}()
 
-	// 步骤8：优雅关闭
+	// 步骤9：优雅关闭
 	// 教学要点：
 	// 1. 监听系统信号（SIGINT、SIGTERM）
 	// 2. 收到信号后停止接受新请求
@@ -137,8 +178,82 @@ func main() {
 
 	log.Println("📴 收到关闭信号，开始优雅关闭...")
 
+	// 停止索引同步、库存事件消费和对账任务
+	stopSync()
+
 	// 停止gRPC服务器（等待现有请求完成）
 	grpcServer.GracefulStop()
 
 	log.Println("✅ catalog-service 已安全关闭")
 }
+
+// startSearchSync 搜索索引定时同步
+//
+// 教学要点：
+// 1. 增量同步：按updated_at追上其他副本的写入（本副本的写入已在Handler中直接更新）
+// 2. 全量重建：物理删除的图书不会出现在增量同步中，靠定时重建清理
+func startSearchSync(ctx context.Context, syncer *search.Syncer, refreshInterval, rebuildInterval time.Duration) {
+	log.Printf("🔍 搜索索引同步任务已启动（增量:%s，全量:%s）", refreshInterval, rebuildInterval)
+
+	refreshTicker := time.NewTicker(refreshInterval)
+	defer refreshTicker.Stop()
+	rebuildTicker := time.NewTicker(rebuildInterval)
+	defer rebuildTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("搜索索引同步任务已停止")
+			return
+		case <-refreshTicker.C:
+			if _, err := syncer.Refresh(ctx); err != nil {
+				log.Printf("⚠️ %v", err)
+			}
+		case <-rebuildTicker.C:
+			if _, err := syncer.Rebuild(ctx); err != nil {
+				log.Printf("⚠️ %v", err)
+			}
+		}
+	}
+}
+
+// startStockConsumer 启动库存事件消费（更新有货状态）
+//
+// 教学要点：处理按事件时间条件更新，重复投递和乱序都是幂等的，不需要按消息ID去重
+func startStockConsumer(ctx context.Context, consumer *mq.Consumer, h *handler.CatalogServiceServer) {
+	err := consumer.Consume(ctx, func(body []byte) error {
+		return h.HandleStockEvent(ctx, body)
+	})
+	if err != nil {
+		log.Printf("❌ 库存事件消费已停止: %v", err)
+	}
+}
+
+// startStockStatusSync 定时对账有货状态
+//
+// 教学要点：启动时先对账一次，补齐上线前已有图书的有货状态
+func startStockStatusSync(ctx context.Context, h *handler.CatalogServiceServer, interval time.Duration, batchSize int) {
+	syncOnce := func() {
+		corrected, err := h.SyncStockStatus(ctx, batchSize)
+		if err != nil {
+			log.Printf("⚠️ 有货状态对账失败: %v", err)
+			return
+		}
+		if corrected > 0 {
+			log.Printf("📦 有货状态对账完成，修正%d本图书", corrected)
+		}
+	}
+
+	syncOnce()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("有货状态对账任务已停止")
+			return
+		case <-ticker.C:
+			syncOnce()
+		}
+	}
+}
@@ -6,13 +6,17 @@ require (
 	github.com/go-redis/redis/v8 v8.11.5
 	github.com/spf13/viper v1.17.0
 	github.com/xiebiao/bookstore/proto/catalogv1 v0.0.0
+	github.com/xiebiao/bookstore/proto/inventoryv1 v0.0.0
 	google.golang.org/grpc v1.59.0
 	gorm.io/driver/mysql v1.5.2
 	gorm.io/gorm v1.25.5
 )
 
 // 本地proto包替换
-replace github.com/xiebiao/bookstore/proto/catalogv1 => ../../proto/catalogv1
+replace (
+	github.com/xiebiao/bookstore/proto/catalogv1 => ../../proto/catalogv1
+	github.com/xiebiao/bookstore/proto/inventoryv1 => ../../proto/inventoryv1
+)
 
 require (
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
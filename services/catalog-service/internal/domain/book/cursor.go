@@ -0,0 +1,51 @@
+package book
+
+// 排序字段和方向
+const (
+	SortByCreatedAt = "created_at"
+	SortByPrice     = "price"
+	SortByID        = "id"
+
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// NormalizeSort 排序参数白名单，不支持的值换成默认值（按发布时间降序）
+//
+// 教学要点：排序字段会拼进ORDER BY，必须走白名单（防止SQL注入）；
+// 游标也按规范化后的排序方式生成和校验，两边一致
+func NormalizeSort(sortBy, order string) (string, string) {
+	switch sortBy {
+	case SortByCreatedAt, SortByPrice, SortByID:
+	default:
+		sortBy = SortByCreatedAt
+	}
+	if order != OrderAsc && order != OrderDesc {
+		order = OrderDesc
+	}
+	return sortBy, order
+}
+
+// ListCursor 游标分页的起点：上一页最后一本书的排序字段值和ID
+//
+// 教学要点：
+// 1. 价格、发布时间都可能重复，只用排序字段定位会漏掉或重复同值的图书，ID作为第二排序键保证唯一
+// 2. 下一页条件：(排序字段, id) 严格小于（降序）或大于（升序）游标
+type ListCursor struct {
+	Value int64 // 排序字段的值：created_at为UnixNano，price为分，id为ID
+	ID    uint
+}
+
+// CursorOf 图书在指定排序方式下的游标（sortBy需已规范化）
+func CursorOf(b *Book, sortBy string) ListCursor {
+	c := ListCursor{ID: b.ID}
+	switch sortBy {
+	case SortByPrice:
+		c.Value = b.Price
+	case SortByID:
+		c.Value = int64(b.ID)
+	default:
+		c.Value = b.CreatedAt.UnixNano()
+	}
+	return c
+}
@@ -1,6 +1,10 @@
 package book
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Book 图书实体（领域模型）
 //
@@ -38,7 +42,7 @@ type Book struct {
 	// 教学要点：为什么用int64而非float64？
 	// - 浮点数有精度问题（0.1 + 0.2 != 0.3）
 	// - 金额计算必须精确，使用整数（分）
-	Price int64 `gorm:"not null;index:idx_price" json:"price"`
+	Price int64 `gorm:"not null;index:idx_price;index:idx_status_price,priority:2" json:"price"`
 
 	// 封面URL
 	CoverURL string `gorm:"size:500" json:"cover_url"`
@@ -51,18 +55,67 @@ type Book struct {
 	// 原因：catalog-service和user-service是独立数据库
 	PublisherID uint `gorm:"index:idx_publisher" json:"publisher_id"`
 
-	// 创建时间
-	CreatedAt time.Time `gorm:"index:idx_created_at" json:"created_at"`
+	// 状态：1草稿 2在售 3已下架
+	// 教学要点：
+	// - 默认值为在售，迁移时已有的图书保持可见
+	// - 复合索引(status, created_at)、(status, price)支撑在售列表按时间/价格排序的游标分页
+	Status BookStatus `gorm:"type:tinyint;not null;default:2;index:idx_status;index:idx_status_created,priority:1;index:idx_status_price,priority:1" json:"status"`
+
+	// 是否有货（inventory-service库存的冗余副本，供"只看有货"筛选）
+	// 教学要点：
+	// - 库存属于inventory-service，跨服务无法JOIN，分页筛选只能查本库
+	// - 由缺货/到货事件更新，定时对账兜底（最终一致）
+	InStock bool `gorm:"not null;default:false;index:idx_in_stock" json:"in_stock"`
+
+	// 最近一次更新有货状态的事件时间（Unix秒），丢弃乱序到达的旧事件
+	StockEventAt int64 `gorm:"not null;default:0" json:"-"`
+
+	// 创建时间（即发布日期）
+	CreatedAt time.Time `gorm:"index:idx_created_at;index:idx_status_created,priority:2" json:"created_at"`
 
 	// 更新时间
-	UpdatedAt time.Time `json:"updated_at"`
+	// 教学要点：搜索索引按updated_at增量同步，需要索引
+	UpdatedAt time.Time `gorm:"index:idx_updated_at" json:"updated_at"`
 
 	// 软删除字段（GORM自动处理）
 	// 教学要点：软删除 vs 硬删除
 	// - 软删除：标记deleted_at，查询时自动过滤
 	// - 硬删除：物理删除，数据不可恢复
-	// - 图书系统应使用软删除（保留历史记录、审计需求）
-	DeletedAt *time.Time `gorm:"index:idx_deleted_at" json:"-"`
+	// - 图书系统应使用软删除（保留历史记录、审计需求，可以恢复）
+	// - 必须是gorm.DeletedAt类型，GORM才会把Delete变成UPDATE并在查询时自动过滤
+	DeletedAt gorm.DeletedAt `gorm:"index:idx_deleted_at" json:"-"`
+}
+
+// BookStatus 图书状态
+//
+// 教学要点：
+// 1. 下架与删除是两回事：下架的图书不再售卖、不出现在列表和搜索中，但详情和历史订单仍可查看
+// 2. 草稿用于先录入信息、确认无误后再上架
+type BookStatus int
+
+const (
+	BookStatusDraft    BookStatus = 1 // 草稿
+	BookStatusOnSale   BookStatus = 2 // 在售
+	BookStatusOffShelf BookStatus = 3 // 已下架
+)
+
+// String 实现Stringer接口
+func (s BookStatus) String() string {
+	switch s {
+	case BookStatusDraft:
+		return "草稿"
+	case BookStatusOnSale:
+		return "在售"
+	case BookStatusOffShelf:
+		return "已下架"
+	default:
+		return "未知状态"
+	}
+}
+
+// IsValid 检查状态值是否合法
+func (s BookStatus) IsValid() bool {
+	return s >= BookStatusDraft && s <= BookStatusOffShelf
 }
 
 // TableName 指定表名
@@ -112,6 +165,31 @@ func (b *Book) Validate() error {
 	return nil
 }
 
+// CanTransitionTo 判断是否可以转换到目标状态
+//
+// 状态流转：
+//
+//	草稿 → 在售 ⇄ 已下架
+//
+// 上架后不能退回草稿（已经有人看到、可能已经下单）
+func (b *Book) CanTransitionTo(target BookStatus) bool {
+	switch b.Status {
+	case BookStatusDraft:
+		return target == BookStatusOnSale
+	case BookStatusOnSale:
+		return target == BookStatusOffShelf
+	case BookStatusOffShelf:
+		return target == BookStatusOnSale
+	default:
+		return false
+	}
+}
+
+// IsOnSale 是否在售（未删除且状态为在售），只有在售图书出现在列表和搜索中
+func (b *Book) IsOnSale() bool {
+	return b.Status == BookStatusOnSale && !b.DeletedAt.Valid
+}
+
 // IsPublishedBy 判断图书是否由指定用户发布
 // 教学要点：领域方法封装业务逻辑，避免外部直接访问字段
 func (b *Book) IsPublishedBy(userID uint) bool {
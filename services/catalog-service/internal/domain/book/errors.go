@@ -29,6 +29,21 @@ var (
 	// 价格相关错误
 	ErrInvalidPrice = errors.New("价格必须大于0")
 
+	// 筛选条件相关错误
+	ErrInvalidPriceRange = errors.New("价格区间不正确")
+	ErrInvalidDateRange  = errors.New("发布日期区间不正确")
+
 	// 图书不存在
 	ErrBookNotFound = errors.New("图书不存在")
+
+	// 状态与权限相关错误
+	ErrInvalidStatus           = errors.New("图书状态不正确")
+	ErrInvalidStatusTransition = errors.New("当前状态不允许该操作")
+	ErrNotPublisher            = errors.New("无权操作该图书")
+	ErrBookNotDeleted          = errors.New("图书未被删除")
+
+	// 同义词相关错误
+	ErrSynonymWordsRequired = errors.New("同义词不能为空")
+	ErrTooManySynonyms      = errors.New("同义词过多")
+	ErrSynonymGroupNotFound = errors.New("同义词组不存在")
 )
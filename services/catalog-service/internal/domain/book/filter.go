@@ -0,0 +1,161 @@
+package book
+
+import (
+	"sort"
+	"time"
+)
+
+// ListFilter 图书列表/搜索的筛选条件（零值表示不限）
+//
+// 教学要点：
+// 1. 同一个筛选条件既用于MySQL查询（ListBooks），也用于内存索引（SearchBooks）
+// 2. 价格区间为闭区间[MinPrice, MaxPrice]，发布日期区间为[PublishedFrom, PublishedTo)
+// 3. 作者、出版社精确匹配：取值通常来自分面统计，用户点击即可筛选
+type ListFilter struct {
+	MinPrice      int64     // 最低价格（分）
+	MaxPrice      int64     // 最高价格（分）
+	Author        string    // 作者
+	Publisher     string    // 出版社
+	PublishedFrom time.Time // 发布时间下限（含）
+	PublishedTo   time.Time // 发布时间上限（不含）
+	InStockOnly   bool      // 只看有货
+}
+
+// Validate 验证筛选条件
+func (f ListFilter) Validate() error {
+	if f.MinPrice < 0 || f.MaxPrice < 0 || (f.MaxPrice > 0 && f.MinPrice > f.MaxPrice) {
+		return ErrInvalidPriceRange
+	}
+	if !f.PublishedFrom.IsZero() && !f.PublishedTo.IsZero() && !f.PublishedFrom.Before(f.PublishedTo) {
+		return ErrInvalidDateRange
+	}
+	return nil
+}
+
+// IsEmpty 是否没有任何筛选条件
+func (f ListFilter) IsEmpty() bool {
+	return f == ListFilter{}
+}
+
+// Matches 图书是否满足筛选条件
+func (f ListFilter) Matches(b *Book) bool {
+	if f.MinPrice > 0 && b.Price < f.MinPrice {
+		return false
+	}
+	if f.MaxPrice > 0 && b.Price > f.MaxPrice {
+		return false
+	}
+	if f.Author != "" && b.Author != f.Author {
+		return false
+	}
+	if f.Publisher != "" && b.Publisher != f.Publisher {
+		return false
+	}
+	if !f.PublishedFrom.IsZero() && b.CreatedAt.Before(f.PublishedFrom) {
+		return false
+	}
+	if !f.PublishedTo.IsZero() && !b.CreatedAt.Before(f.PublishedTo) {
+		return false
+	}
+	if f.InStockOnly && !b.InStock {
+		return false
+	}
+	return true
+}
+
+// WithoutPublisher 去掉出版社条件（统计出版社分面用）
+func (f ListFilter) WithoutPublisher() ListFilter {
+	f.Publisher = ""
+	return f
+}
+
+// WithoutPrice 去掉价格条件（统计价格区间分面用）
+func (f ListFilter) WithoutPrice() ListFilter {
+	f.MinPrice, f.MaxPrice = 0, 0
+	return f
+}
+
+// PriceBand 价格区间分面的一档，[Min, Max)，Max为0表示无上限
+type PriceBand struct {
+	Label string
+	Min   int64 // 分
+	Max   int64 // 分
+}
+
+// PriceBands 价格区间分面的档位（按价格升序）
+var PriceBands = []PriceBand{
+	{Label: "20元以下", Min: 0, Max: 2000},
+	{Label: "20-50元", Min: 2000, Max: 5000},
+	{Label: "50-100元", Min: 5000, Max: 10000},
+	{Label: "100-200元", Min: 10000, Max: 20000},
+	{Label: "200元以上", Min: 20000, Max: 0},
+}
+
+// PriceBandOf 价格所在档位的下标
+func PriceBandOf(price int64) int {
+	for i, band := range PriceBands {
+		if band.Max == 0 || price < band.Max {
+			return i
+		}
+	}
+	return len(PriceBands) - 1
+}
+
+// MaxPublisherFacets 出版社分面最多返回的项数（按图书数降序）
+const MaxPublisherFacets = 20
+
+// FacetCount 分面的一项
+type FacetCount struct {
+	Value string
+	Count int64
+}
+
+// PriceBandCount 价格区间分面的一项
+type PriceBandCount struct {
+	PriceBand
+	Count int64
+}
+
+// Facets 分面统计
+//
+// 教学要点：
+// 1. 每个分面统计时忽略自身的筛选条件，只应用其他条件
+//   - 选中"出版社A"后，出版社分面仍显示B、C各有多少本，用户可以切换
+//   - 价格分面同理；这种统计方式常称为disjunctive faceting
+//
+// 2. 没有图书的档位/出版社不返回
+type Facets struct {
+	Publishers []FacetCount
+	PriceBands []PriceBandCount
+}
+
+// NewFacets 由计数生成分面（出版社按图书数降序、同数按名称升序，截取前MaxPublisherFacets项）
+func NewFacets(publisherCounts map[string]int64, bandCounts []int64) *Facets {
+	facets := &Facets{
+		Publishers: make([]FacetCount, 0, len(publisherCounts)),
+		PriceBands: make([]PriceBandCount, 0, len(PriceBands)),
+	}
+	for publisher, count := range publisherCounts {
+		if publisher == "" || count == 0 {
+			continue
+		}
+		facets.Publishers = append(facets.Publishers, FacetCount{Value: publisher, Count: count})
+	}
+	sort.Slice(facets.Publishers, func(i, j int) bool {
+		a, b := facets.Publishers[i], facets.Publishers[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Value < b.Value
+	})
+	if len(facets.Publishers) > MaxPublisherFacets {
+		facets.Publishers = facets.Publishers[:MaxPublisherFacets]
+	}
+
+	for i, band := range PriceBands {
+		if i < len(bandCounts) && bandCounts[i] > 0 {
+			facets.PriceBands = append(facets.PriceBands, PriceBandCount{PriceBand: band, Count: bandCounts[i]})
+		}
+	}
+	return facets
+}
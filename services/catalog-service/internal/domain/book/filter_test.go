@@ -0,0 +1,133 @@
+package book
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestPriceBandOf 测试价格区间的边界（左闭右开）
+func TestPriceBandOf(t *testing.T) {
+	tests := []struct {
+		price int64
+		want  int
+	}{
+		{price: 0, want: 0},
+		{price: 1999, want: 0},
+		{price: 2000, want: 1},
+		{price: 4999, want: 1},
+		{price: 5000, want: 2},
+		{price: 9999, want: 2},
+		{price: 10000, want: 3},
+		{price: 19999, want: 3},
+		{price: 20000, want: 4},
+		{price: 1000000, want: 4},
+	}
+
+	for _, tt := range tests {
+		if got := PriceBandOf(tt.price); got != tt.want {
+			t.Errorf("PriceBandOf(%d) = %d（%s），期望%d（%s）", tt.price, got, PriceBands[got].Label, tt.want, PriceBands[tt.want].Label)
+		}
+	}
+}
+
+// TestListFilter_Matches 测试价格闭区间、发布时间左闭右开和有货筛选
+func TestListFilter_Matches(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	b := &Book{Price: 5000, Author: "刘慈欣", Publisher: "重庆出版社", CreatedAt: day, InStock: true}
+
+	tests := []struct {
+		name   string
+		filter ListFilter
+		want   bool
+	}{
+		{name: "不限", filter: ListFilter{}, want: true},
+		{name: "价格等于下限", filter: ListFilter{MinPrice: 5000}, want: true},
+		{name: "价格等于上限", filter: ListFilter{MaxPrice: 5000}, want: true},
+		{name: "价格低于下限", filter: ListFilter{MinPrice: 5001}, want: false},
+		{name: "价格高于上限", filter: ListFilter{MaxPrice: 4999}, want: false},
+		{name: "作者精确匹配", filter: ListFilter{Author: "刘慈欣"}, want: true},
+		{name: "作者不支持部分匹配", filter: ListFilter{Author: "刘"}, want: false},
+		{name: "出版社不同", filter: ListFilter{Publisher: "人民文学出版社"}, want: false},
+		{name: "发布时间等于下限", filter: ListFilter{PublishedFrom: day}, want: true},
+		{name: "发布时间等于上限（不含）", filter: ListFilter{PublishedTo: day}, want: false},
+		{name: "发布时间早于上限", filter: ListFilter{PublishedTo: day.Add(time.Second)}, want: true},
+		{name: "只看有货", filter: ListFilter{InStockOnly: true}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(b); got != tt.want {
+				t.Errorf("期望%v，实际%v", tt.want, got)
+			}
+		})
+	}
+
+	soldOut := *b
+	soldOut.InStock = false
+	if (ListFilter{InStockOnly: true}).Matches(&soldOut) {
+		t.Error("无货图书不应满足只看有货")
+	}
+}
+
+// TestListFilter_Validate 测试价格和日期区间校验
+func TestListFilter_Validate(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		filter  ListFilter
+		wantErr error
+	}{
+		{name: "不限", filter: ListFilter{}},
+		{name: "上下限相等", filter: ListFilter{MinPrice: 100, MaxPrice: 100}},
+		{name: "只有下限", filter: ListFilter{MinPrice: 100}},
+		{name: "下限大于上限", filter: ListFilter{MinPrice: 200, MaxPrice: 100}, wantErr: ErrInvalidPriceRange},
+		{name: "负数价格", filter: ListFilter{MinPrice: -1}, wantErr: ErrInvalidPriceRange},
+		{name: "日期区间", filter: ListFilter{PublishedFrom: day, PublishedTo: day.AddDate(0, 0, 1)}},
+		{name: "日期上下限相等", filter: ListFilter{PublishedFrom: day, PublishedTo: day}, wantErr: ErrInvalidDateRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("期望%v，实际%v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestNewFacets 测试分面排序、截断和空档位过滤
+func TestNewFacets(t *testing.T) {
+	publishers := map[string]int64{"B社": 3, "A社": 3, "C社": 5, "": 9, "D社": 0}
+	facets := NewFacets(publishers, []int64{2, 0, 1})
+
+	wantPublishers := []FacetCount{{Value: "C社", Count: 5}, {Value: "A社", Count: 3}, {Value: "B社", Count: 3}}
+	if len(facets.Publishers) != len(wantPublishers) {
+		t.Fatalf("期望出版社分面%v，实际%v", wantPublishers, facets.Publishers)
+	}
+	for i, want := range wantPublishers {
+		if facets.Publishers[i] != want {
+			t.Errorf("出版社分面第%d项期望%v，实际%v", i+1, want, facets.Publishers[i])
+		}
+	}
+
+	if len(facets.PriceBands) != 2 ||
+		facets.PriceBands[0].Label != PriceBands[0].Label || facets.PriceBands[0].Count != 2 ||
+		facets.PriceBands[1].Label != PriceBands[2].Label || facets.PriceBands[1].Count != 1 {
+		t.Errorf("价格分面应只包含有图书的档位，实际%+v", facets.PriceBands)
+	}
+
+	many := make(map[string]int64)
+	for i := 0; i < MaxPublisherFacets+5; i++ {
+		many[fmt.Sprintf("出版社%02d", i)] = int64(i + 1)
+	}
+	facets = NewFacets(many, nil)
+	if len(facets.Publishers) != MaxPublisherFacets || facets.Publishers[0].Count != int64(MaxPublisherFacets+5) {
+		t.Errorf("期望保留图书数最多的%d个出版社，实际%d个（第一项%v）", MaxPublisherFacets, len(facets.Publishers), facets.Publishers[0])
+	}
+	if len(facets.PriceBands) != 0 {
+		t.Errorf("没有价格计数时期望价格分面为空，实际%v", facets.PriceBands)
+	}
+}
@@ -1,6 +1,9 @@
 package book
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Repository 图书仓储接口（领域层定义）
 //
@@ -39,20 +42,33 @@ type Repository interface {
 	// - pageSize默认10，最大100（防止大查询）
 	// - sortBy支持：created_at（默认）、price
 	// - order支持：desc（默认）、asc
-	List(ctx context.Context, page, pageSize int, sortBy, order string) ([]*Book, int64, error)
+	// - filter为筛选条件，total为满足条件的总数
+	// - 翻到很深的页会变慢，翻页期间有新书发布会重复或遗漏，App滚动加载应使用ListByCursor
+	List(ctx context.Context, filter ListFilter, page, pageSize int, sortBy, order string) ([]*Book, int64, error)
 
-	// Search 搜索图书
+	// ListByCursor 游标分页查询图书列表
 	// 教学要点：
-	// - 使用LIKE查询（Phase 2简化实现）
-	// - Week 7会引入ElasticSearch（全文搜索）
-	// - 搜索字段：title、author、publisher
-	Search(ctx context.Context, keyword string, page, pageSize int) ([]*Book, int64, error)
+	// - after为上一页最后一本书的游标（见CursorOf），nil表示第一页
+	// - 用WHERE条件定位起点，不用OFFSET，任何深度的翻页代价都一样
+	// - 排序、筛选规则与List一致，total通过Count单独查询
+	ListByCursor(ctx context.Context, filter ListFilter, after *ListCursor, limit int, sortBy, order string) ([]*Book, error)
+
+	// Count 满足筛选条件的图书总数
+	Count(ctx context.Context, filter ListFilter) (int64, error)
+
+	// Facets 统计满足筛选条件的图书分面（出版社、价格区间）
+	// 教学要点：每个分面忽略自身的筛选条件，见Facets
+	Facets(ctx context.Context, filter ListFilter) (*Facets, error)
 
 	// Update 更新图书
 	// 教学要点：
-	// - 只更新非零值字段（使用GORM的Updates）
-	// - 避免覆盖未传入的字段
-	Update(ctx context.Context, book *Book) error
+	// - 未指定fields时只更新非零值字段（使用GORM的Updates），避免覆盖未传入的字段
+	// - 指定fields时只更新这些字段（包括零值，如清空描述），其他字段不受并发修改影响
+	Update(ctx context.Context, book *Book, fields ...string) error
+
+	// UpdateStatus 状态从from变为to（条件更新，当前状态不是from时返回ErrInvalidStatusTransition）
+	// 教学要点：WHERE status = from 保证并发修改状态时只有一个成功（CAS）
+	UpdateStatus(ctx context.Context, id uint, from, to BookStatus) error
 
 	// Delete 软删除图书
 	// 教学要点：
@@ -60,9 +76,32 @@ type Repository interface {
 	// - 查询时自动过滤已删除记录
 	Delete(ctx context.Context, id uint) error
 
+	// FindDeletedByID 查询已删除的图书（恢复前校验归属）
+	// 不存在返回ErrBookNotFound，未删除返回ErrBookNotDeleted
+	FindDeletedByID(ctx context.Context, id uint) (*Book, error)
+
+	// Restore 恢复已删除的图书
+	Restore(ctx context.Context, id uint) error
+
 	// BatchFindByIDs 批量查询图书（供order-service调用）
 	// 教学要点：
 	// - 避免N+1查询（一次查询多本书）
 	// - 返回map便于按ID查找
 	BatchFindByIDs(ctx context.Context, ids []uint) (map[uint]*Book, error)
+
+	// ListUpdatedSince 按(updated_at, id)升序查询更新时间不早于since的图书（供搜索索引同步）
+	// 教学要点：
+	// - 游标分页：返回(updated_at, id)大于(since, afterID)的记录，下一批从上一批最后一条继续
+	// - afterID为0时包含updated_at等于since的全部记录
+	// - 包含已删除的图书（DeletedAt有值），同步方据此从索引中删除
+	ListUpdatedSince(ctx context.Context, since time.Time, afterID uint, limit int) ([]*Book, error)
+
+	// ListAfterID 按ID升序查询ID大于afterID的图书（供有货状态对账）
+	ListAfterID(ctx context.Context, afterID uint, limit int) ([]*Book, error)
+
+	// SetInStock 更新有货状态，返回是否发生了变化
+	// 教学要点：
+	// - at早于上次更新时间的事件是乱序到达的旧事件，直接忽略
+	// - 状态变化时同时更新updated_at，搜索索引的增量同步会带上新状态
+	SetInStock(ctx context.Context, id uint, inStock bool, at time.Time) (bool, error)
 }
@@ -0,0 +1,82 @@
+package book
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// MaxSynonymWords 每组同义词最多包含的词数
+const MaxSynonymWords = 20
+
+// SynonymGroup 同义词组（搜索词典，由管理员维护）
+//
+// 教学要点：
+// 1. 同一组里的词互为同义词，搜索其中任何一个词都会匹配其他词
+// 2. 组里的词同时加入分词词表：只有一个词的组可以用来教搜索"认识"新词
+// 3. 词典只影响查询的切分和扩展，修改后不需要重建索引
+type SynonymGroup struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// 同义词，以逗号分隔（如"计算机,电脑"）
+	Words string `gorm:"size:500;not null" json:"words"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (SynonymGroup) TableName() string {
+	return "search_synonyms"
+}
+
+// WordList 拆分同义词
+func (g *SynonymGroup) WordList() []string {
+	var words []string
+	for _, w := range strings.Split(g.Words, ",") {
+		if w = strings.TrimSpace(w); w != "" {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// SetWords 设置同义词（去空白、去重）
+func (g *SynonymGroup) SetWords(words []string) {
+	seen := make(map[string]bool, len(words))
+	list := make([]string, 0, len(words))
+	for _, w := range words {
+		w = strings.TrimSpace(w)
+		key := strings.ToLower(w)
+		if w == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		list = append(list, w)
+	}
+	g.Words = strings.Join(list, ",")
+}
+
+// Validate 验证同义词组
+func (g *SynonymGroup) Validate() error {
+	words := g.WordList()
+	if len(words) == 0 {
+		return ErrSynonymWordsRequired
+	}
+	if len(words) > MaxSynonymWords || len(g.Words) > 500 {
+		return ErrTooManySynonyms
+	}
+	return nil
+}
+
+// SynonymRepository 同义词仓储接口
+type SynonymRepository interface {
+	// List 查询全部同义词组（按ID升序）
+	List(ctx context.Context) ([]*SynonymGroup, error)
+
+	// Save 新建（ID为0）或更新同义词组，更新不存在的组返回ErrSynonymGroupNotFound
+	Save(ctx context.Context, group *SynonymGroup) error
+
+	// Delete 删除同义词组，不存在时返回ErrSynonymGroupNotFound
+	Delete(ctx context.Context, id uint) error
+}
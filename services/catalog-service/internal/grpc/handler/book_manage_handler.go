@@ -0,0 +1,330 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	catalogv1 "github.com/xiebiao/bookstore/proto/catalogv1"
+	"github.com/xiebiao/bookstore/services/catalog-service/internal/domain/book"
+)
+
+// UpdateBook 修改图书信息
+//
+// 教学要点：
+// 1. 先查出图书校验归属，再修改字段并做领域验证（与发布时的规则一致）
+// 2. 只更新本接口负责的字段，不会覆盖并发的改价、上下架
+// 3. ISBN是图书的业务标识，不允许修改（填错了应删除后重新发布）
+func (s *CatalogServiceServer) UpdateBook(ctx context.Context, req *catalogv1.UpdateBookRequest) (*catalogv1.UpdateBookResponse, error) {
+	// 步骤1：参数验证
+	if req.BookId == 0 {
+		return &catalogv1.UpdateBookResponse{
+			Code:    40001,
+			Message: "图书ID不能为空",
+		}, nil
+	}
+
+	// 步骤2：查询图书并校验归属
+	b, err := s.findOwnedBook(ctx, uint(req.BookId), uint(req.OperatorId))
+	if err != nil {
+		if code := manageErrorCode(err); code != 0 {
+			return &catalogv1.UpdateBookResponse{Code: code, Message: err.Error()}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "查询图书失败: %v", err)
+	}
+
+	// 步骤3：修改字段并验证
+	b.Title = req.Title
+	b.Author = req.Author
+	b.Publisher = req.Publisher
+	b.CoverURL = req.CoverUrl
+	b.Description = req.Description
+	if err := b.Validate(); err != nil {
+		return &catalogv1.UpdateBookResponse{
+			Code:    40001,
+			Message: err.Error(),
+		}, nil
+	}
+
+	// 步骤4：保存（指定字段，允许清空封面和描述）
+	if err := s.repo.Update(ctx, b, "title", "author", "publisher", "cover_url", "description"); err != nil {
+		if code := manageErrorCode(err); code != 0 {
+			return &catalogv1.UpdateBookResponse{Code: code, Message: err.Error()}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "更新图书失败: %v", err)
+	}
+
+	// 步骤5：删除缓存、更新索引
+	if updated := s.afterBookChanged(ctx, b.ID); updated != nil {
+		b = updated
+	}
+
+	return &catalogv1.UpdateBookResponse{
+		Code:    0,
+		Message: "修改成功",
+		Book:    s.toProtoBook(b),
+	}, nil
+}
+
+// UpdateBookPrice 修改价格
+//
+// 教学要点：
+// 1. 改价单独成接口：价格变化影响下单金额，便于单独审计和限制
+// 2. 已创建的订单保存了下单时的价格，改价不影响历史订单
+func (s *CatalogServiceServer) UpdateBookPrice(ctx context.Context, req *catalogv1.UpdateBookPriceRequest) (*catalogv1.UpdateBookPriceResponse, error) {
+	// 步骤1：参数验证
+	if req.BookId == 0 {
+		return &catalogv1.UpdateBookPriceResponse{
+			Code:    40001,
+			Message: "图书ID不能为空",
+		}, nil
+	}
+	if req.Price <= 0 {
+		return &catalogv1.UpdateBookPriceResponse{
+			Code:    40001,
+			Message: book.ErrInvalidPrice.Error(),
+		}, nil
+	}
+
+	// 步骤2：查询图书并校验归属
+	b, err := s.findOwnedBook(ctx, uint(req.BookId), uint(req.OperatorId))
+	if err != nil {
+		if code := manageErrorCode(err); code != 0 {
+			return &catalogv1.UpdateBookPriceResponse{Code: code, Message: err.Error()}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "查询图书失败: %v", err)
+	}
+
+	oldPrice := b.Price
+	if oldPrice == req.Price {
+		return &catalogv1.UpdateBookPriceResponse{
+			Code:     0,
+			Message:  "价格未变化",
+			OldPrice: oldPrice,
+			NewPrice: req.Price,
+		}, nil
+	}
+
+	// 步骤3：只更新价格
+	b.Price = req.Price
+	if err := s.repo.Update(ctx, b, "price"); err != nil {
+		if code := manageErrorCode(err); code != 0 {
+			return &catalogv1.UpdateBookPriceResponse{Code: code, Message: err.Error()}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "修改价格失败: %v", err)
+	}
+
+	// 步骤4：删除缓存、更新索引（价格影响排序、价格筛选和分面）
+	s.afterBookChanged(ctx, b.ID)
+
+	return &catalogv1.UpdateBookPriceResponse{
+		Code:     0,
+		Message:  "改价成功",
+		OldPrice: oldPrice,
+		NewPrice: req.Price,
+	}, nil
+}
+
+// ChangeBookStatus 上架/下架
+//
+// 教学要点：
+// 1. 状态机校验在领域层（Book.CanTransitionTo），handler只负责转换错误码
+// 2. 数据库层再做一次条件更新（WHERE status = 原状态），并发操作只有一个成功
+// 3. 下架的图书从列表和搜索中消失，详情仍可查看，下单会被拒绝
+func (s *CatalogServiceServer) ChangeBookStatus(ctx context.Context, req *catalogv1.ChangeBookStatusRequest) (*catalogv1.ChangeBookStatusResponse, error) {
+	// 步骤1：参数验证
+	if req.BookId == 0 {
+		return &catalogv1.ChangeBookStatusResponse{
+			Code:    40001,
+			Message: "图书ID不能为空",
+		}, nil
+	}
+	target := book.BookStatus(req.Status)
+	if !target.IsValid() {
+		return &catalogv1.ChangeBookStatusResponse{
+			Code:    40001,
+			Message: book.ErrInvalidStatus.Error(),
+		}, nil
+	}
+
+	// 步骤2：查询图书并校验归属
+	b, err := s.findOwnedBook(ctx, uint(req.BookId), uint(req.OperatorId))
+	if err != nil {
+		if code := manageErrorCode(err); code != 0 {
+			return &catalogv1.ChangeBookStatusResponse{Code: code, Message: err.Error()}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "查询图书失败: %v", err)
+	}
+
+	// 步骤3：状态机校验
+	if !b.CanTransitionTo(target) {
+		return &catalogv1.ChangeBookStatusResponse{
+			Code:    40902,
+			Message: fmt.Sprintf("图书%s，不能变更为%s", b.Status, target),
+			Status:  int32(b.Status),
+		}, nil
+	}
+
+	// 步骤4：条件更新
+	if err := s.repo.UpdateStatus(ctx, b.ID, b.Status, target); err != nil {
+		if code := manageErrorCode(err); code != 0 {
+			return &catalogv1.ChangeBookStatusResponse{Code: code, Message: err.Error()}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "修改图书状态失败: %v", err)
+	}
+
+	// 步骤5：删除缓存、更新索引（下架时从索引中删除）
+	s.afterBookChanged(ctx, b.ID)
+
+	return &catalogv1.ChangeBookStatusResponse{
+		Code:    0,
+		Message: fmt.Sprintf("已%s", statusAction(target)),
+		Status:  int32(target),
+	}, nil
+}
+
+// DeleteBook 删除图书（软删除）
+//
+// 教学要点：
+// 1. 软删除只设置deleted_at，历史订单引用的图书数据仍在，可以恢复
+// 2. 删除后GetBook返回不存在，列表和搜索中都不再出现
+func (s *CatalogServiceServer) DeleteBook(ctx context.Context, req *catalogv1.DeleteBookRequest) (*catalogv1.DeleteBookResponse, error) {
+	// 步骤1：参数验证
+	if req.BookId == 0 {
+		return &catalogv1.DeleteBookResponse{
+			Code:    40001,
+			Message: "图书ID不能为空",
+		}, nil
+	}
+
+	// 步骤2：查询图书并校验归属
+	b, err := s.findOwnedBook(ctx, uint(req.BookId), uint(req.OperatorId))
+	if err != nil {
+		if code := manageErrorCode(err); code != 0 {
+			return &catalogv1.DeleteBookResponse{Code: code, Message: err.Error()}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "查询图书失败: %v", err)
+	}
+
+	// 步骤3：软删除
+	if err := s.repo.Delete(ctx, b.ID); err != nil {
+		if code := manageErrorCode(err); code != 0 {
+			return &catalogv1.DeleteBookResponse{Code: code, Message: err.Error()}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "删除图书失败: %v", err)
+	}
+
+	// 步骤4：删除缓存、从索引中删除
+	s.afterBookChanged(ctx, b.ID)
+
+	return &catalogv1.DeleteBookResponse{
+		Code:    0,
+		Message: "删除成功",
+	}, nil
+}
+
+// RestoreBook 恢复已删除的图书
+//
+// 教学要点：恢复后保持删除前的状态（在售的图书恢复后立即重新可见）
+func (s *CatalogServiceServer) RestoreBook(ctx context.Context, req *catalogv1.RestoreBookRequest) (*catalogv1.RestoreBookResponse, error) {
+	// 步骤1：参数验证
+	if req.BookId == 0 {
+		return &catalogv1.RestoreBookResponse{
+			Code:    40001,
+			Message: "图书ID不能为空",
+		}, nil
+	}
+
+	// 步骤2：查询已删除的图书并校验归属
+	b, err := s.repo.FindDeletedByID(ctx, uint(req.BookId))
+	if err == nil && !b.IsPublishedBy(uint(req.OperatorId)) {
+		err = book.ErrNotPublisher
+	}
+	if err != nil {
+		if code := manageErrorCode(err); code != 0 {
+			return &catalogv1.RestoreBookResponse{Code: code, Message: err.Error()}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "查询图书失败: %v", err)
+	}
+
+	// 步骤3：恢复
+	if err := s.repo.Restore(ctx, b.ID); err != nil {
+		if code := manageErrorCode(err); code != 0 {
+			return &catalogv1.RestoreBookResponse{Code: code, Message: err.Error()}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "恢复图书失败: %v", err)
+	}
+
+	// 步骤4：删除缓存、重新加入索引
+	if restored := s.afterBookChanged(ctx, b.ID); restored != nil {
+		b = restored
+	}
+
+	return &catalogv1.RestoreBookResponse{
+		Code:    0,
+		Message: "恢复成功",
+		Book:    s.toProtoBook(b),
+	}, nil
+}
+
+// findOwnedBook 查询图书并校验操作者是发布者
+func (s *CatalogServiceServer) findOwnedBook(ctx context.Context, bookID, operatorID uint) (*book.Book, error) {
+	b, err := s.repo.FindByID(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if !b.IsPublishedBy(operatorID) {
+		return nil, book.ErrNotPublisher
+	}
+	return b, nil
+}
+
+// afterBookChanged 图书变更后删除缓存并更新本副本的搜索索引，返回最新的图书（已删除或查询失败时返回nil）
+//
+// 教学要点：
+// 1. 重新查询而不是用内存中的对象：拿到数据库里的updated_at等最新值
+// 2. Index.Add会把不在售的图书从索引中删除，已删除的图书查不到，直接Remove
+// 3. 缓存删除失败只影响时效（缓存按TTL过期）；其他副本的索引由增量同步追上
+func (s *CatalogServiceServer) afterBookChanged(ctx context.Context, bookID uint) *book.Book {
+	if err := s.cache.InvalidateBook(ctx, bookID); err != nil {
+		log.Printf("⚠️ 删除图书缓存失败 (book_id=%d): %v", bookID, err)
+	}
+
+	b, err := s.repo.FindByID(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, book.ErrBookNotFound) {
+			s.index.Remove(bookID)
+		} else {
+			log.Printf("⚠️ 查询图书失败，索引将在增量同步时更新 (book_id=%d): %v", bookID, err)
+		}
+		return nil
+	}
+	s.index.Add(b)
+	return b
+}
+
+// manageErrorCode 图书管理的领域错误 → 业务错误码（系统错误返回0）
+func manageErrorCode(err error) uint32 {
+	switch {
+	case errors.Is(err, book.ErrBookNotFound):
+		return 40401
+	case errors.Is(err, book.ErrNotPublisher):
+		return 40301
+	case errors.Is(err, book.ErrInvalidStatusTransition), errors.Is(err, book.ErrBookNotDeleted):
+		return 40902
+	default:
+		return 0
+	}
+}
+
+// statusAction 状态变更的动作名称
+func statusAction(target book.BookStatus) string {
+	if target == book.BookStatusOnSale {
+		return "上架"
+	}
+	return "下架"
+}
@@ -3,13 +3,17 @@ package handler
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/xiebiao/bookstore/pkg/pagination"
 	catalogv1 "github.com/xiebiao/bookstore/proto/catalogv1"
 	"github.com/xiebiao/bookstore/services/catalog-service/internal/domain/book"
 	"github.com/xiebiao/bookstore/services/catalog-service/internal/infrastructure/persistence/redis"
+	"github.com/xiebiao/bookstore/services/catalog-service/internal/infrastructure/search"
 )
 
 // CatalogServiceServer 图书目录服务gRPC实现
@@ -33,17 +37,26 @@ import (
 //   - 查询：先查缓存，未命中再查数据库，结果写入缓存
 //   - 更新：更新数据库后删除缓存
 //   - 删除：删除数据库后删除缓存
+//
+// 4. 搜索走内存全文索引，写操作成功后同步更新索引
+// 5. 有货状态是库存的冗余副本，由库存事件和定时对账维护（见stock_status_handler.go）
 type CatalogServiceServer struct {
 	catalogv1.UnimplementedCatalogServiceServer
-	repo  book.Repository
-	cache *redis.CacheStore
+	repo     book.Repository
+	synonyms book.SynonymRepository
+	cache    *redis.CacheStore
+	index    *search.Index
+	stock    StockQuerier
 }
 
 // NewCatalogServiceServer 创建gRPC服务实例
-func NewCatalogServiceServer(repo book.Repository, cache *redis.CacheStore) *CatalogServiceServer {
+func NewCatalogServiceServer(repo book.Repository, synonyms book.SynonymRepository, cache *redis.CacheStore, index *search.Index, stock StockQuerier) *CatalogServiceServer {
 	return &CatalogServiceServer{
-		repo:  repo,
-		cache: cache,
+		repo:     repo,
+		synonyms: synonyms,
+		cache:    cache,
+		index:    index,
+		stock:    stock,
 	}
 }
 
@@ -108,12 +121,16 @@ func (s *CatalogServiceServer) GetBook(ctx context.Context, req *catalogv1.GetBo
 	}, nil
 }
 
-// ListBooks 图书列表（分页、排序）
+// ListBooks 图书列表（分页、排序、筛选）
 //
 // 教学要点：
 // 1. 分页参数默认值处理
 // 2. 排序参数验证（白名单）
-// 3. 列表缓存策略
+// 3. 列表缓存策略：缓存key包含筛选条件和游标，分面统计、下一页游标与列表一起缓存
+// 4. 两种分页方式
+//   - 页码分页（page）：可以跳页，深翻页慢，翻页期间上新会重复/遗漏（兼容旧客户端）
+//   - 游标分页（cursor）：只能下一页，任何深度都一样快，翻页期间上新不影响已翻过的位置
+//   - 两种方式都返回next_cursor，客户端可以第一页用page=1，之后改用游标
 func (s *CatalogServiceServer) ListBooks(ctx context.Context, req *catalogv1.ListBooksRequest) (*catalogv1.ListBooksResponse, error) {
 	// 步骤1：参数验证和默认值
 	page := int(req.Page)
@@ -129,62 +146,125 @@ func (s *CatalogServiceServer) ListBooks(ctx context.Context, req *catalogv1.Lis
 		pageSize = 100 // 限制最大每页数量
 	}
 
-	sortBy := req.SortBy
-	if sortBy == "" {
-		sortBy = "created_at"
+	sortBy, order := book.NormalizeSort(req.SortBy, req.Order)
+
+	filter := toBookFilter(req.Filter)
+	if err := filter.Validate(); err != nil {
+		return &catalogv1.ListBooksResponse{
+			Code:    40001,
+			Message: err.Error(),
+		}, nil
+	}
+
+	var after *book.ListCursor
+	if req.Cursor != "" {
+		c, err := pagination.Decode(req.Cursor, pagination.SortKey(sortBy, order))
+		if err != nil {
+			return &catalogv1.ListBooksResponse{
+				Code:    40001,
+				Message: err.Error(),
+			}, nil
+		}
+		after = &book.ListCursor{Value: c.Value, ID: uint(c.ID)}
+		page = 0 // 游标分页没有页码
 	}
 
-	order := req.Order
-	if order == "" {
-		order = "desc"
+	query := redis.BookListQuery{
+		Filter:   filter,
+		Page:     page,
+		PageSize: pageSize,
+		SortBy:   sortBy,
+		Order:    order,
+		Cursor:   req.Cursor,
 	}
 
 	// 步骤2：先查缓存
-	cachedBooks, cachedTotal, err := s.cache.GetBookList(ctx, page, pageSize, sortBy, order)
+	cached, err := s.cache.GetBookList(ctx, query)
 	if err != nil {
 		// 缓存失败不影响主流程
 	}
 
-	if cachedBooks != nil {
+	if cached != nil {
 		// 缓存命中
-		return &catalogv1.ListBooksResponse{
-			Code:     0,
-			Message:  "success",
-			Books:    s.toProtoBooks(cachedBooks),
-			Total:    uint32(cachedTotal),
-			Page:     uint32(page),
-			PageSize: uint32(pageSize),
-		}, nil
+		return s.toListBooksResponse(cached, page, pageSize), nil
 	}
 
 	// 步骤3：查询数据库
-	books, total, err := s.repo.List(ctx, page, pageSize, sortBy, order)
+	var (
+		books   []*book.Book
+		total   int64
+		hasMore bool
+	)
+	if after != nil {
+		// 多查一条判断是否还有下一页
+		books, err = s.repo.ListByCursor(ctx, filter, after, pageSize+1, sortBy, order)
+		if err == nil {
+			total, err = s.repo.Count(ctx, filter)
+		}
+		if hasMore = len(books) > pageSize; hasMore {
+			books = books[:pageSize]
+		}
+	} else {
+		books, total, err = s.repo.List(ctx, filter, page, pageSize, sortBy, order)
+		hasMore = int64((page-1)*pageSize+len(books)) < total
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "查询图书列表失败: %v", err)
 	}
+	facets, err := s.repo.Facets(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "统计图书分面失败: %v", err)
+	}
+
+	result := &redis.BookListPage{
+		Books:  books,
+		Total:  total,
+		Facets: facets,
+	}
+	if hasMore && len(books) > 0 {
+		last := book.CursorOf(books[len(books)-1], sortBy)
+		result.NextCursor = pagination.Cursor{
+			Sort:  pagination.SortKey(sortBy, order),
+			Value: last.Value,
+			ID:    uint64(last.ID),
+		}.Encode()
+	}
 
 	// 步骤4：写入缓存
 	go func() {
-		if err := s.cache.SetBookList(context.Background(), books, total, page, pageSize, sortBy, order); err != nil {
+		if err := s.cache.SetBookList(context.Background(), query, result); err != nil {
 			// logger.Error("failed to set book list cache", zap.Error(err))
 		}
 	}()
 
 	// 步骤5：返回结果
+	return s.toListBooksResponse(result, page, pageSize), nil
+}
+
+// toListBooksResponse 列表结果 → Protobuf响应
+func (s *CatalogServiceServer) toListBooksResponse(result *redis.BookListPage, page, pageSize int) *catalogv1.ListBooksResponse {
 	return &catalogv1.ListBooksResponse{
-		Code:     0,
-		Message:  "success",
-		Books:    s.toProtoBooks(books),
-		Total:    uint32(total),
-		Page:     uint32(page),
-		PageSize: uint32(pageSize),
-	}, nil
+		Code:       0,
+		Message:    "success",
+		Books:      s.toProtoBooks(result.Books),
+		Total:      uint32(result.Total),
+		Page:       uint32(page),
+		PageSize:   uint32(pageSize),
+		Facets:     toProtoFacets(result.Facets),
+		NextCursor: result.NextCursor,
+	}
 }
 
 // SearchBooks 搜索图书
+//
+// 教学要点：
+// 1. 查询内存倒排索引，按相关性排序，不再访问MySQL和Redis
+// 2. 索引随写操作增量更新，不存在搜索结果缓存过期前搜不到新书的问题
+// 3. highlights与books一一对应，供前端展示命中片段
+// 4. 中文友好：支持拼音全拼/首字母（"santi"、"st"）、词典分词和同义词（"电脑"→"计算机"）
 func (s *CatalogServiceServer) SearchBooks(ctx context.Context, req *catalogv1.SearchBooksRequest) (*catalogv1.SearchBooksResponse, error) {
 	// 参数验证
-	keyword := req.Keyword
+	keyword := strings.TrimSpace(req.Keyword)
 	if keyword == "" {
 		return &catalogv1.SearchBooksResponse{
 			Code:    40001,
@@ -205,35 +285,37 @@ func (s *CatalogServiceServer) SearchBooks(ctx context.Context, req *catalogv1.S
 		pageSize = 100
 	}
 
-	// 先查缓存
-	cachedBooks, cachedTotal, err := s.cache.GetSearchResult(ctx, keyword, page, pageSize)
-	if err == nil && cachedBooks != nil {
+	filter := toBookFilter(req.Filter)
+	if err := filter.Validate(); err != nil {
 		return &catalogv1.SearchBooksResponse{
-			Code:    0,
-			Message: "success",
-			Books:   s.toProtoBooks(cachedBooks),
-			Total:   uint32(cachedTotal),
+			Code:    40001,
+			Message: err.Error(),
 		}, nil
 	}
 
-	// 查询数据库
-	books, total, err := s.repo.Search(ctx, keyword, page, pageSize)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "搜索图书失败: %v", err)
-	}
-
-	// 写入缓存
-	go func() {
-		if err := s.cache.SetSearchResult(context.Background(), books, total, keyword, page, pageSize); err != nil {
-			// logger.Error("failed to set search result cache", zap.Error(err))
+	hits, total, facets := s.index.Search(keyword, filter, page, pageSize)
+
+	books := make([]*catalogv1.Book, len(hits))
+	highlights := make([]*catalogv1.BookHighlight, len(hits))
+	for i, hit := range hits {
+		books[i] = s.toProtoBook(hit.Book)
+		highlights[i] = &catalogv1.BookHighlight{
+			BookId:      uint64(hit.Book.ID),
+			Title:       hit.Highlight.Title,
+			Author:      hit.Highlight.Author,
+			Publisher:   hit.Highlight.Publisher,
+			Description: hit.Highlight.Description,
+			Score:       hit.Score,
 		}
-	}()
+	}
 
 	return &catalogv1.SearchBooksResponse{
-		Code:    0,
-		Message: "success",
-		Books:   s.toProtoBooks(books),
-		Total:   uint32(total),
+		Code:       0,
+		Message:    "success",
+		Books:      books,
+		Total:      uint32(total),
+		Highlights: highlights,
+		Facets:     toProtoFacets(facets),
 	}, nil
 }
 
@@ -242,6 +324,7 @@ func (s *CatalogServiceServer) SearchBooks(ctx context.Context, req *catalogv1.S
 // 教学要点：
 // 1. 写操作需要删除缓存（保持数据一致性）
 // 2. 删除所有列表缓存（因为新图书会影响所有列表查询）
+// 3. 增量更新搜索索引
 func (s *CatalogServiceServer) PublishBook(ctx context.Context, req *catalogv1.PublishBookRequest) (*catalogv1.PublishBookResponse, error) {
 	// 步骤1：Protobuf → 领域实体
 	b := &book.Book{
@@ -253,6 +336,10 @@ func (s *CatalogServiceServer) PublishBook(ctx context.Context, req *catalogv1.P
 		CoverURL:    req.CoverUrl,
 		Description: req.Description,
 		PublisherID: uint(req.PublisherId),
+		Status:      book.BookStatusOnSale,
+	}
+	if req.Draft {
+		b.Status = book.BookStatusDraft
 	}
 
 	// 步骤2：领域验证
@@ -295,7 +382,10 @@ func (s *CatalogServiceServer) PublishBook(ctx context.Context, req *catalogv1.P
 		}
 	}()
 
-	// 步骤6：返回结果
+	// 步骤6：加入搜索索引（本副本立即可搜，其他副本由增量同步追上；草稿不会被索引）
+	s.index.Add(b)
+
+	// 步骤7：返回结果
 	return &catalogv1.PublishBookResponse{
 		Code:    0,
 		Message: "发布成功",
@@ -363,6 +453,8 @@ func (s *CatalogServiceServer) toProtoBook(b *book.Book) *catalogv1.Book {
 		PublisherId: uint64(b.PublisherID),
 		CreatedAt:   b.CreatedAt.Unix(),
 		UpdatedAt:   b.UpdatedAt.Unix(),
+		InStock:     b.InStock,
+		Status:      int32(b.Status),
 	}
 }
 
@@ -374,3 +466,47 @@ func (s *CatalogServiceServer) toProtoBooks(books []*book.Book) []*catalogv1.Boo
 	}
 	return result
 }
+
+// toBookFilter Protobuf筛选条件 → 领域筛选条件
+func toBookFilter(f *catalogv1.BookFilter) book.ListFilter {
+	if f == nil {
+		return book.ListFilter{}
+	}
+	filter := book.ListFilter{
+		MinPrice:    f.MinPrice,
+		MaxPrice:    f.MaxPrice,
+		Author:      strings.TrimSpace(f.Author),
+		Publisher:   strings.TrimSpace(f.Publisher),
+		InStockOnly: f.InStockOnly,
+	}
+	if f.PublishedFrom > 0 {
+		filter.PublishedFrom = time.Unix(f.PublishedFrom, 0)
+	}
+	if f.PublishedTo > 0 {
+		filter.PublishedTo = time.Unix(f.PublishedTo, 0)
+	}
+	return filter
+}
+
+// toProtoFacets 领域分面 → Protobuf
+func toProtoFacets(facets *book.Facets) *catalogv1.BookFacets {
+	if facets == nil {
+		return nil
+	}
+	result := &catalogv1.BookFacets{
+		Publishers: make([]*catalogv1.FacetCount, len(facets.Publishers)),
+		PriceBands: make([]*catalogv1.PriceBandCount, len(facets.PriceBands)),
+	}
+	for i, p := range facets.Publishers {
+		result.Publishers[i] = &catalogv1.FacetCount{Value: p.Value, Count: uint32(p.Count)}
+	}
+	for i, b := range facets.PriceBands {
+		result.PriceBands[i] = &catalogv1.PriceBandCount{
+			Label:    b.Label,
+			MinPrice: b.Min,
+			MaxPrice: b.Max,
+			Count:    uint32(b.Count),
+		}
+	}
+	return result
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/xiebiao/bookstore/pkg/events"
+)
+
+// StockQuerier 库存查询接口（*grpc_client.InventoryClient实现）
+type StockQuerier interface {
+	BatchGetStock(ctx context.Context, bookIDs []uint) (map[uint]int, error)
+}
+
+// stockEvent 库存事件中更新有货状态需要的字段（缺货、到货事件共用）
+type stockEvent struct {
+	Type       string `json:"type"`
+	BookID     uint64 `json:"book_id"`
+	OccurredAt int64  `json:"occurred_at"`
+}
+
+// HandleStockEvent 消费库存事件，更新图书的有货状态
+//
+// 教学要点：
+// 1. 只关心状态切换：inventory.out_of_stock → 无货，inventory.back_in_stock → 有货
+// 2. 按事件时间条件更新，乱序到达的旧事件不会覆盖新状态；重复投递也是幂等的
+// 3. 状态变化后删除列表缓存（"只看有货"的结果变了），并更新本副本的搜索索引
+func (s *CatalogServiceServer) HandleStockEvent(ctx context.Context, body []byte) error {
+	var event stockEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Printf("⚠️ 库存事件格式错误，丢弃: %v", err)
+		return nil
+	}
+	if event.BookID == 0 {
+		return nil
+	}
+
+	var inStock bool
+	switch event.Type {
+	case events.InventoryOutOfStock:
+		inStock = false
+	case events.InventoryBackInStock:
+		inStock = true
+	default:
+		return nil
+	}
+
+	at := time.Unix(event.OccurredAt, 0)
+	if event.OccurredAt <= 0 {
+		at = time.Now()
+	}
+	_, err := s.setInStock(ctx, uint(event.BookID), inStock, at)
+	return err
+}
+
+// SyncStockStatus 按批查询库存，对账全部图书的有货状态，返回状态被修正的图书数
+//
+// 教学要点：
+// 1. 按ID游标分批，每批一次BatchGetStock，不会一次把全部图书加载进内存
+// 2. 事件是实时通道，对账是兜底：事件丢失、消费者宕机期间的变化、上线前已有的图书
+// 3. 对账用当前时间作为事件时间，对账之后才到达的旧事件会被丢弃
+func (s *CatalogServiceServer) SyncStockStatus(ctx context.Context, batchSize int) (int, error) {
+	corrected := 0
+	var afterID uint
+	for {
+		books, err := s.repo.ListAfterID(ctx, afterID, batchSize)
+		if err != nil {
+			return corrected, err
+		}
+		if len(books) == 0 {
+			return corrected, nil
+		}
+
+		ids := make([]uint, len(books))
+		for i, b := range books {
+			ids[i] = b.ID
+		}
+		now := time.Now()
+		stocks, err := s.stock.BatchGetStock(ctx, ids)
+		if err != nil {
+			return corrected, err
+		}
+
+		for _, b := range books {
+			inStock := stocks[b.ID] > 0
+			if b.InStock == inStock {
+				continue
+			}
+			changed, err := s.setInStock(ctx, b.ID, inStock, now)
+			if err != nil {
+				return corrected, err
+			}
+			if changed {
+				corrected++
+			}
+		}
+
+		if len(books) < batchSize {
+			return corrected, nil
+		}
+		afterID = books[len(books)-1].ID
+	}
+}
+
+// setInStock 更新有货状态，状态变化时刷新缓存和本地索引
+func (s *CatalogServiceServer) setInStock(ctx context.Context, bookID uint, inStock bool, at time.Time) (bool, error) {
+	changed, err := s.repo.SetInStock(ctx, bookID, inStock, at)
+	if err != nil || !changed {
+		return false, err
+	}
+
+	// 其他副本由搜索索引的增量同步追上（SetInStock更新了updated_at）
+	s.afterBookChanged(ctx, bookID)
+	return true, nil
+}
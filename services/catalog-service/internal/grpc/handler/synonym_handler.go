@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	catalogv1 "github.com/xiebiao/bookstore/proto/catalogv1"
+	"github.com/xiebiao/bookstore/services/catalog-service/internal/domain/book"
+	"github.com/xiebiao/bookstore/services/catalog-service/internal/infrastructure/search"
+)
+
+// ListSynonymGroups 查询全部同义词组
+func (s *CatalogServiceServer) ListSynonymGroups(ctx context.Context, req *catalogv1.ListSynonymGroupsRequest) (*catalogv1.ListSynonymGroupsResponse, error) {
+	groups, err := s.synonyms.List(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "查询同义词失败: %v", err)
+	}
+
+	protoGroups := make([]*catalogv1.SynonymGroup, 0, len(groups))
+	for _, g := range groups {
+		protoGroups = append(protoGroups, toProtoSynonymGroup(g))
+	}
+	return &catalogv1.ListSynonymGroupsResponse{
+		Code:    0,
+		Message: "success",
+		Groups:  protoGroups,
+	}, nil
+}
+
+// SaveSynonymGroup 新建或更新同义词组
+//
+// 教学要点：
+// 1. 写入数据库后立即重新加载本副本的词典，其他副本在下次增量同步时加载
+// 2. 词典只作用于查询，已建好的索引不需要重建
+func (s *CatalogServiceServer) SaveSynonymGroup(ctx context.Context, req *catalogv1.SaveSynonymGroupRequest) (*catalogv1.SaveSynonymGroupResponse, error) {
+	// 步骤1：参数验证
+	g := &book.SynonymGroup{ID: uint(req.Id)}
+	g.SetWords(req.Words)
+	if err := g.Validate(); err != nil {
+		return &catalogv1.SaveSynonymGroupResponse{
+			Code:    40001,
+			Message: err.Error(),
+		}, nil
+	}
+
+	// 步骤2：保存
+	if err := s.synonyms.Save(ctx, g); err != nil {
+		if errors.Is(err, book.ErrSynonymGroupNotFound) {
+			return &catalogv1.SaveSynonymGroupResponse{
+				Code:    40401,
+				Message: "同义词组不存在",
+			}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "保存同义词失败: %v", err)
+	}
+
+	// 步骤3：刷新本地词典
+	s.reloadDictionary(ctx)
+
+	return &catalogv1.SaveSynonymGroupResponse{
+		Code:    0,
+		Message: "保存成功",
+		Group:   toProtoSynonymGroup(g),
+	}, nil
+}
+
+// DeleteSynonymGroup 删除同义词组
+func (s *CatalogServiceServer) DeleteSynonymGroup(ctx context.Context, req *catalogv1.DeleteSynonymGroupRequest) (*catalogv1.DeleteSynonymGroupResponse, error) {
+	if req.Id == 0 {
+		return &catalogv1.DeleteSynonymGroupResponse{
+			Code:    40001,
+			Message: "同义词组ID不能为空",
+		}, nil
+	}
+
+	if err := s.synonyms.Delete(ctx, uint(req.Id)); err != nil {
+		if errors.Is(err, book.ErrSynonymGroupNotFound) {
+			return &catalogv1.DeleteSynonymGroupResponse{
+				Code:    40401,
+				Message: "同义词组不存在",
+			}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "删除同义词失败: %v", err)
+	}
+
+	s.reloadDictionary(ctx)
+
+	return &catalogv1.DeleteSynonymGroupResponse{
+		Code:    0,
+		Message: "删除成功",
+	}, nil
+}
+
+// reloadDictionary 重新加载本地词典
+//
+// 加载失败不影响写操作的结果：下次增量同步会再次加载
+func (s *CatalogServiceServer) reloadDictionary(ctx context.Context) {
+	dict, err := search.LoadDictionary(ctx, s.synonyms)
+	if err != nil {
+		// logger.Error("failed to reload search dictionary", zap.Error(err))
+		return
+	}
+	s.index.SetDictionary(dict)
+}
+
+func toProtoSynonymGroup(g *book.SynonymGroup) *catalogv1.SynonymGroup {
+	return &catalogv1.SynonymGroup{
+		Id:        uint64(g.ID),
+		Words:     g.WordList(),
+		UpdatedAt: g.UpdatedAt.Unix(),
+	}
+}
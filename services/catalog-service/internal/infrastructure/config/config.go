@@ -13,11 +13,15 @@ import (
 // 2. 支持嵌套配置（server、database、redis等）
 // 3. 配置验证（确保必填项不为空）
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	Cache    CacheConfig    `mapstructure:"cache"`
-	Log      LogConfig      `mapstructure:"log"`
+	Server   ServerConfig             `mapstructure:"server"`
+	Database DatabaseConfig           `mapstructure:"database"`
+	Redis    RedisConfig              `mapstructure:"redis"`
+	Cache    CacheConfig              `mapstructure:"cache"`
+	Search   SearchConfig             `mapstructure:"search"`
+	Stock    StockConfig              `mapstructure:"stock"`
+	MQ       MQConfig                 `mapstructure:"mq"`
+	Services map[string]ServiceConfig `mapstructure:"services"` // 下游服务配置
+	Log      LogConfig                `mapstructure:"log"`
 }
 
 // ServerConfig 服务器配置
@@ -50,7 +54,34 @@ type RedisConfig struct {
 type CacheConfig struct {
 	ListTTL   int `mapstructure:"list_ttl"`
 	DetailTTL int `mapstructure:"detail_ttl"`
-	SearchTTL int `mapstructure:"search_ttl"`
+}
+
+// SearchConfig 搜索索引配置
+type SearchConfig struct {
+	RefreshInterval int `mapstructure:"refresh_interval"` // 增量同步间隔（秒）
+	RebuildInterval int `mapstructure:"rebuild_interval"` // 全量重建间隔（秒）
+	BatchSize       int `mapstructure:"batch_size"`       // 同步时每批读取的图书数
+}
+
+// StockConfig 有货状态同步配置
+//
+// 教学要点：有货状态随库存事件实时更新，事件可能丢失或早于上线前发生，
+// 定时按批调用inventory-service查询库存对账兜底
+type StockConfig struct {
+	SyncInterval int `mapstructure:"sync_interval"` // 对账间隔（秒）
+	BatchSize    int `mapstructure:"batch_size"`    // 每批查询的图书数
+}
+
+// MQConfig 消息队列配置
+type MQConfig struct {
+	URL        string `mapstructure:"url"`         // RabbitMQ连接URL
+	StockQueue string `mapstructure:"stock_queue"` // 库存事件队列（更新有货状态）
+}
+
+// ServiceConfig 下游服务配置
+type ServiceConfig struct {
+	Addr    string `mapstructure:"addr"`
+	Timeout int    `mapstructure:"timeout"` // 超时时间（秒）
 }
 
 // LogConfig 日志配置
@@ -120,6 +151,14 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("Redis地址不能为空")
 	}
 
+	// 验证消息队列和下游服务配置
+	if c.MQ.URL == "" {
+		return fmt.Errorf("消息队列URL不能为空")
+	}
+	if c.GetServiceAddr("inventory") == "" {
+		return fmt.Errorf("services.inventory.addr 不能为空")
+	}
+
 	return nil
 }
 
@@ -138,7 +177,66 @@ func (c *CacheConfig) GetDetailTTL() time.Duration {
 	return time.Duration(c.DetailTTL) * time.Second
 }
 
-// GetSearchTTL 获取搜索缓存时间（time.Duration）
-func (c *CacheConfig) GetSearchTTL() time.Duration {
-	return time.Duration(c.SearchTTL) * time.Second
+// GetRefreshInterval 获取索引增量同步间隔（默认30秒）
+func (c *SearchConfig) GetRefreshInterval() time.Duration {
+	if c.RefreshInterval <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.RefreshInterval) * time.Second
+}
+
+// GetRebuildInterval 获取索引全量重建间隔（默认1小时）
+func (c *SearchConfig) GetRebuildInterval() time.Duration {
+	if c.RebuildInterval <= 0 {
+		return time.Hour
+	}
+	return time.Duration(c.RebuildInterval) * time.Second
+}
+
+// GetBatchSize 获取同步批大小（默认500）
+func (c *SearchConfig) GetBatchSize() int {
+	if c.BatchSize <= 0 {
+		return 500
+	}
+	return c.BatchSize
+}
+
+// GetSyncInterval 获取有货状态对账间隔（默认10分钟）
+func (c *StockConfig) GetSyncInterval() time.Duration {
+	if c.SyncInterval <= 0 {
+		return 10 * time.Minute
+	}
+	return time.Duration(c.SyncInterval) * time.Second
+}
+
+// GetBatchSize 获取对账批大小（默认100）
+func (c *StockConfig) GetBatchSize() int {
+	if c.BatchSize <= 0 {
+		return 100
+	}
+	return c.BatchSize
+}
+
+// GetStockQueue 库存事件队列名（未配置时catalog.stock-status）
+func (c *MQConfig) GetStockQueue() string {
+	if c.StockQueue == "" {
+		return "catalog.stock-status"
+	}
+	return c.StockQueue
+}
+
+// GetServiceAddr 获取下游服务地址
+func (c *Config) GetServiceAddr(serviceName string) string {
+	if svc, ok := c.Services[serviceName]; ok {
+		return svc.Addr
+	}
+	return ""
+}
+
+// GetServiceTimeout 获取下游服务超时时间（默认3秒）
+func (c *Config) GetServiceTimeout(serviceName string) time.Duration {
+	if svc, ok := c.Services[serviceName]; ok && svc.Timeout > 0 {
+		return time.Duration(svc.Timeout) * time.Second
+	}
+	return 3 * time.Second
 }
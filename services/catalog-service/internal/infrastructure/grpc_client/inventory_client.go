@@ -0,0 +1,66 @@
+package grpc_client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	inventoryv1 "github.com/xiebiao/bookstore/proto/inventoryv1"
+)
+
+// InventoryClient inventory-service客户端（只用于查询库存对账有货状态）
+type InventoryClient struct {
+	conn    *grpc.ClientConn
+	client  inventoryv1.InventoryServiceClient
+	timeout time.Duration
+}
+
+// NewInventoryClient 创建inventory-service客户端
+func NewInventoryClient(addr string, timeout time.Duration) (*InventoryClient, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("连接inventory-service失败: %w", err)
+	}
+
+	return &InventoryClient{
+		conn:    conn,
+		client:  inventoryv1.NewInventoryServiceClient(conn),
+		timeout: timeout,
+	}, nil
+}
+
+// Close 关闭连接
+func (c *InventoryClient) Close() error {
+	if c.conn != nil {
+		return c.conn.Close()
+	}
+	return nil
+}
+
+// BatchGetStock 批量查询可售库存（没有库存记录的图书不在结果中，视为0）
+func (c *InventoryClient) BatchGetStock(ctx context.Context, bookIDs []uint) (map[uint]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	ids := make([]uint64, len(bookIDs))
+	for i, id := range bookIDs {
+		ids[i] = uint64(id)
+	}
+
+	resp, err := c.client.BatchGetStock(ctx, &inventoryv1.BatchGetStockRequest{BookIds: ids})
+	if err != nil {
+		return nil, fmt.Errorf("批量查询库存失败: %w", err)
+	}
+	if resp.Code != 0 {
+		return nil, fmt.Errorf("批量查询库存失败: %s", resp.Message)
+	}
+
+	stocks := make(map[uint]int, len(resp.Stocks))
+	for _, s := range resp.Stocks {
+		stocks[uint(s.BookId)] = int(s.Stock)
+	}
+	return stocks, nil
+}
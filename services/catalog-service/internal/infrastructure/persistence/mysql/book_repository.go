@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/xiebiao/bookstore/services/catalog-service/internal/domain/book"
 	"gorm.io/gorm"
@@ -85,7 +87,7 @@ func (r *bookRepository) FindByISBN(ctx context.Context, isbn string) (*book.Boo
 }
 
 // List 分页查询图书列表
-func (r *bookRepository) List(ctx context.Context, page, pageSize int, sortBy, order string) ([]*book.Book, int64, error) {
+func (r *bookRepository) List(ctx context.Context, filter book.ListFilter, page, pageSize int, sortBy, order string) ([]*book.Book, int64, error) {
 	// 教学要点：
 	// 1. 参数验证和默认值处理
 	// 2. 分页计算：offset = (page - 1) * pageSize
@@ -103,25 +105,13 @@ func (r *bookRepository) List(ctx context.Context, page, pageSize int, sortBy, o
 		pageSize = 100 // 限制最大每页数量，防止大查询
 	}
 
-	// 排序字段白名单
-	allowedSortFields := map[string]bool{
-		"created_at": true,
-		"price":      true,
-		"id":         true,
-	}
-	if !allowedSortFields[sortBy] {
-		sortBy = "created_at" // 默认按创建时间排序
-	}
-
-	// 排序方向白名单
-	if order != "asc" && order != "desc" {
-		order = "desc" // 默认降序
-	}
+	// 排序字段和方向白名单（默认按创建时间降序）
+	sortBy, order = book.NormalizeSort(sortBy, order)
 
-	// 步骤2：查询总数
-	var total int64
-	if err := r.db.WithContext(ctx).Model(&book.Book{}).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("查询图书总数失败: %w", err)
+	// 步骤2：查询总数（满足筛选条件的）
+	total, err := r.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	// 步骤3：分页查询
@@ -129,11 +119,11 @@ func (r *bookRepository) List(ctx context.Context, page, pageSize int, sortBy, o
 	offset := (page - 1) * pageSize
 
 	// 教学要点：链式调用构建查询
-	// - Order: 排序
+	// - Order: 排序（排序字段相同时按ID，保证翻页顺序稳定）
 	// - Offset: 跳过前N条
 	// - Limit: 返回M条
-	if err := r.db.WithContext(ctx).
-		Order(fmt.Sprintf("%s %s", sortBy, order)).
+	if err := applyFilter(r.db.WithContext(ctx), filter).
+		Order(orderClause(sortBy, order)).
 		Offset(offset).
 		Limit(pageSize).
 		Find(&books).Error; err != nil {
@@ -143,79 +133,99 @@ func (r *bookRepository) List(ctx context.Context, page, pageSize int, sortBy, o
 	return books, total, nil
 }
 
-// Search 搜索图书
-func (r *bookRepository) Search(ctx context.Context, keyword string, page, pageSize int) ([]*book.Book, int64, error) {
+// ListByCursor 游标分页查询图书列表
+func (r *bookRepository) ListByCursor(ctx context.Context, filter book.ListFilter, after *book.ListCursor, limit int, sortBy, order string) ([]*book.Book, error) {
 	// 教学要点：
-	// 1. 多字段模糊查询（LIKE）
-	// 2. OR条件组合（title OR author OR publisher）
-	// 3. Phase 2简化实现，Week 7会引入ElasticSearch
-	//
-	// LIKE查询的局限性：
-	// - 无法分词（"Go语言"搜不到"Go 语言"）
-	// - 无法相关性排序
-	// - 大数据量性能差（全表扫描）
-	// - 无法处理同义词、拼音搜索
-	//
-	// ElasticSearch优势：
-	// - 分词搜索
-	// - 相关性评分
-	// - 高性能（倒排索引）
-	// - 支持拼音、同义词
-
-	// 参数验证
-	if page < 1 {
-		page = 1
+	// 1. 与List的区别只在于用WHERE定位起点代替OFFSET
+	// 2. 复合索引(status, created_at)、(status, price)覆盖"在售 + 排序"，
+	//    InnoDB二级索引自带主键，ORDER BY 排序字段, id 可以直接走索引
+	sortBy, order = book.NormalizeSort(sortBy, order)
+	if limit < 1 {
+		limit = 10
 	}
-	if pageSize < 1 {
-		pageSize = 10
-	}
-	if pageSize > 100 {
-		pageSize = 100
+
+	db := applyFilter(r.db.WithContext(ctx), filter)
+	if after != nil {
+		db = applyCursor(db, *after, sortBy, order)
 	}
 
-	// 如果关键词为空，返回空结果
-	if keyword == "" {
-		return []*book.Book{}, 0, nil
+	var books []*book.Book
+	if err := db.Order(orderClause(sortBy, order)).
+		Limit(limit).
+		Find(&books).Error; err != nil {
+		return nil, fmt.Errorf("查询图书列表失败: %w", err)
 	}
 
-	// LIKE查询需要加通配符
-	pattern := "%" + keyword + "%"
+	return books, nil
+}
 
-	// 查询总数
+// Count 满足筛选条件的图书总数
+func (r *bookRepository) Count(ctx context.Context, filter book.ListFilter) (int64, error) {
 	var total int64
-	query := r.db.WithContext(ctx).Model(&book.Book{}).
-		Where("title LIKE ? OR author LIKE ? OR publisher LIKE ?", pattern, pattern, pattern)
-
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("查询搜索结果总数失败: %w", err)
+	if err := applyFilter(r.db.WithContext(ctx).Model(&book.Book{}), filter).Count(&total).Error; err != nil {
+		return 0, fmt.Errorf("查询图书总数失败: %w", err)
 	}
+	return total, nil
+}
 
-	// 分页查询
-	var books []*book.Book
-	offset := (page - 1) * pageSize
+// Facets 统计分面
+//
+// 教学要点：
+// 1. 出版社分面：GROUP BY publisher，应用除出版社以外的筛选条件
+// 2. 价格分面：CASE WHEN把价格映射到档位再GROUP BY，应用除价格以外的筛选条件
+// 3. 两条聚合查询与列表查询一起写入列表缓存，不会每次请求都执行
+func (r *bookRepository) Facets(ctx context.Context, filter book.ListFilter) (*book.Facets, error) {
+	var publisherRows []struct {
+		Publisher string
+		Count     int64
+	}
+	if err := applyFilter(r.db.WithContext(ctx).Model(&book.Book{}), filter.WithoutPublisher()).
+		Select("publisher, COUNT(*) AS count").
+		Where("publisher <> ''").
+		Group("publisher").
+		Find(&publisherRows).Error; err != nil {
+		return nil, fmt.Errorf("统计出版社分面失败: %w", err)
+	}
+	publisherCounts := make(map[string]int64, len(publisherRows))
+	for _, row := range publisherRows {
+		publisherCounts[row.Publisher] = row.Count
+	}
 
-	if err := query.
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(pageSize).
-		Find(&books).Error; err != nil {
-		return nil, 0, fmt.Errorf("搜索图书失败: %w", err)
+	var bandRows []struct {
+		Band  int
+		Count int64
+	}
+	if err := applyFilter(r.db.WithContext(ctx).Model(&book.Book{}), filter.WithoutPrice()).
+		Select(priceBandExpr() + " AS band, COUNT(*) AS count").
+		Group("band").
+		Find(&bandRows).Error; err != nil {
+		return nil, fmt.Errorf("统计价格分面失败: %w", err)
+	}
+	bandCounts := make([]int64, len(book.PriceBands))
+	for _, row := range bandRows {
+		if row.Band >= 0 && row.Band < len(bandCounts) {
+			bandCounts[row.Band] = row.Count
+		}
 	}
 
-	return books, total, nil
+	return book.NewFacets(publisherCounts, bandCounts), nil
 }
 
 // Update 更新图书
-func (r *bookRepository) Update(ctx context.Context, b *book.Book) error {
+func (r *bookRepository) Update(ctx context.Context, b *book.Book, fields ...string) error {
 	// 教学要点：
 	// 1. Updates只更新非零值字段
 	// 2. Save会更新所有字段（包括零值）
 	// 3. 使用Updates避免误覆盖
+	// 4. Select指定字段时零值也会更新；带上updated_at，GORM会自动填充当前时间
+
+	db := r.db.WithContext(ctx).Model(&book.Book{}).Where("id = ?", b.ID)
+	if len(fields) > 0 {
+		db = db.Select(append(fields, "updated_at"))
+	}
 
 	// DO（正确做法）：
-	result := r.db.WithContext(ctx).Model(&book.Book{}).
-		Where("id = ?", b.ID).
-		Updates(b)
+	result := db.Updates(b)
 
 	// DON'T（错误做法）：
 	// r.db.Save(b) // 会覆盖所有字段，包括零值
@@ -232,14 +242,36 @@ func (r *bookRepository) Update(ctx context.Context, b *book.Book) error {
 	return nil
 }
 
+// UpdateStatus 条件更新图书状态
+func (r *bookRepository) UpdateStatus(ctx context.Context, id uint, from, to book.BookStatus) error {
+	result := r.db.WithContext(ctx).Model(&book.Book{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	if err := result.Error; err != nil {
+		return fmt.Errorf("更新图书状态失败: %w", err)
+	}
+	if result.RowsAffected == 0 {
+		// 图书不存在或状态已被并发修改
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return book.ErrInvalidStatusTransition
+	}
+	return nil
+}
+
 // Delete 软删除图书
 func (r *bookRepository) Delete(ctx context.Context, id uint) error {
 	// 教学要点：
 	// 1. GORM的Delete会自动设置DeletedAt字段（软删除）
 	// 2. 后续查询会自动过滤已删除记录
 	// 3. 永久删除需要使用Unscoped().Delete()
+	// 4. 这里用Updates代替Delete：同时刷新updated_at，搜索索引的增量同步才能发现删除
+	//    （Model带gorm.DeletedAt时，GORM自动追加deleted_at IS NULL条件，重复删除不会生效）
 
-	result := r.db.WithContext(ctx).Delete(&book.Book{}, id)
+	result := r.db.WithContext(ctx).Model(&book.Book{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"deleted_at": time.Now()})
 
 	if err := result.Error; err != nil {
 		return fmt.Errorf("删除图书失败: %w", err)
@@ -252,6 +284,41 @@ func (r *bookRepository) Delete(ctx context.Context, id uint) error {
 	return nil
 }
 
+// FindDeletedByID 查询已删除的图书
+func (r *bookRepository) FindDeletedByID(ctx context.Context, id uint) (*book.Book, error) {
+	var b book.Book
+
+	// 教学要点：Unscoped取消软删除的自动过滤
+	if err := r.db.WithContext(ctx).Unscoped().First(&b, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, book.ErrBookNotFound
+		}
+		return nil, fmt.Errorf("查询图书失败: %w", err)
+	}
+	if !b.DeletedAt.Valid {
+		return nil, book.ErrBookNotDeleted
+	}
+
+	return &b, nil
+}
+
+// Restore 恢复已删除的图书
+func (r *bookRepository) Restore(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Unscoped().Model(&book.Book{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{"deleted_at": nil})
+
+	if err := result.Error; err != nil {
+		return fmt.Errorf("恢复图书失败: %w", err)
+	}
+
+	if result.RowsAffected == 0 {
+		return book.ErrBookNotDeleted
+	}
+
+	return nil
+}
+
 // BatchFindByIDs 批量查询图书
 func (r *bookRepository) BatchFindByIDs(ctx context.Context, ids []uint) (map[uint]*book.Book, error) {
 	// 教学要点：
@@ -284,6 +351,132 @@ func (r *bookRepository) BatchFindByIDs(ctx context.Context, ids []uint) (map[ui
 	return bookMap, nil
 }
 
+// ListUpdatedSince 按(updated_at, id)游标查询更新过的图书
+func (r *bookRepository) ListUpdatedSince(ctx context.Context, since time.Time, afterID uint, limit int) ([]*book.Book, error) {
+	// 教学要点：
+	// 1. 行比较 (updated_at, id) > (?, ?) 展开为OR条件，走idx_updated_at索引
+	// 2. id作为第二排序键，updated_at相同的记录也不会重复或遗漏
+	// 3. Unscoped：已删除的图书也要返回，同步方才能把它们从索引中删除
+	var books []*book.Book
+	if err := r.db.WithContext(ctx).Unscoped().
+		Where("updated_at > ? OR (updated_at = ? AND id > ?)", since, since, afterID).
+		Order("updated_at ASC, id ASC").
+		Limit(limit).
+		Find(&books).Error; err != nil {
+		return nil, fmt.Errorf("查询更新图书失败: %w", err)
+	}
+	return books, nil
+}
+
+// ListAfterID 按ID游标查询图书
+func (r *bookRepository) ListAfterID(ctx context.Context, afterID uint, limit int) ([]*book.Book, error) {
+	var books []*book.Book
+	if err := r.db.WithContext(ctx).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&books).Error; err != nil {
+		return nil, fmt.Errorf("查询图书失败: %w", err)
+	}
+	return books, nil
+}
+
+// SetInStock 更新有货状态
+func (r *bookRepository) SetInStock(ctx context.Context, id uint, inStock bool, at time.Time) (bool, error) {
+	// 教学要点：
+	// 1. 条件更新：只有状态确实变化、且事件不比上次更旧时才更新（乐观并发，不需要先查再改）
+	// 2. 状态没变时不更新，避免无谓地刷新updated_at导致搜索索引重复同步
+	// 3. Updates(map)会自动更新updated_at
+	result := r.db.WithContext(ctx).Model(&book.Book{}).
+		Where("id = ? AND stock_event_at <= ? AND in_stock <> ?", id, at.Unix(), inStock).
+		Updates(map[string]interface{}{
+			"in_stock":       inStock,
+			"stock_event_at": at.Unix(),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("更新有货状态失败: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// orderClause 排序子句：排序字段相同时按ID排序（sortBy、order需已通过白名单）
+func orderClause(sortBy, order string) string {
+	if sortBy == book.SortByID {
+		return "id " + order
+	}
+	return fmt.Sprintf("%s %s, id %s", sortBy, order, order)
+}
+
+// applyCursor 把游标转换为WHERE子句：(排序字段, id) 在游标之后
+//
+// 教学要点：
+// 1. 降序取小于游标的记录，升序取大于游标的记录
+// 2. 展开写成 a < ? OR (a = ? AND id < ?)，不用行比较 (a, id) < (?, ?)：旧版本MySQL对行比较不能有效利用索引
+func applyCursor(db *gorm.DB, after book.ListCursor, sortBy, order string) *gorm.DB {
+	op := "<"
+	if order == book.OrderAsc {
+		op = ">"
+	}
+
+	if sortBy == book.SortByID {
+		return db.Where("id "+op+" ?", after.ID)
+	}
+
+	var value interface{} = after.Value
+	if sortBy == book.SortByCreatedAt {
+		value = time.Unix(0, after.Value)
+	}
+	return db.Where(
+		fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", sortBy, op, sortBy, op),
+		value, value, after.ID,
+	)
+}
+
+// applyFilter 把筛选条件转换为WHERE子句
+//
+// 教学要点：
+// 1. 条件全部参数化，作者、出版社等用户输入不会拼进SQL
+// 2. 列表和分面只包含在售图书（草稿、已下架不对外展示）
+func applyFilter(db *gorm.DB, f book.ListFilter) *gorm.DB {
+	db = db.Where("status = ?", book.BookStatusOnSale)
+	if f.MinPrice > 0 {
+		db = db.Where("price >= ?", f.MinPrice)
+	}
+	if f.MaxPrice > 0 {
+		db = db.Where("price <= ?", f.MaxPrice)
+	}
+	if f.Author != "" {
+		db = db.Where("author = ?", f.Author)
+	}
+	if f.Publisher != "" {
+		db = db.Where("publisher = ?", f.Publisher)
+	}
+	if !f.PublishedFrom.IsZero() {
+		db = db.Where("created_at >= ?", f.PublishedFrom)
+	}
+	if !f.PublishedTo.IsZero() {
+		db = db.Where("created_at < ?", f.PublishedTo)
+	}
+	if f.InStockOnly {
+		db = db.Where("in_stock = ?", true)
+	}
+	return db
+}
+
+// priceBandExpr 价格 → 档位下标的SQL表达式（与book.PriceBandOf一致）
+//
+// 档位边界来自代码中的常量，不是用户输入，可以直接拼入SQL
+func priceBandExpr() string {
+	var sb strings.Builder
+	sb.WriteString("CASE")
+	last := len(book.PriceBands) - 1
+	for i, band := range book.PriceBands[:last] {
+		fmt.Fprintf(&sb, " WHEN price < %d THEN %d", band.Max, i)
+	}
+	fmt.Fprintf(&sb, " ELSE %d END", last)
+	return sb.String()
+}
+
 // isDuplicateError 判断是否是唯一索引冲突错误
 //
 // 教学要点：
@@ -82,7 +82,7 @@ func NewDB(cfg *config.DatabaseConfig) (*gorm.DB, error) {
 	// 1. AutoMigrate会创建表、索引、缺失的列
 	// 2. 不会删除已存在的列（安全）
 	// 3. 生产环境推荐使用migrate工具（版本控制）
-	if err := db.AutoMigrate(&book.Book{}); err != nil {
+	if err := db.AutoMigrate(&book.Book{}, &book.SynonymGroup{}); err != nil {
 		return nil, fmt.Errorf("数据库迁移失败: %w", err)
 	}
 
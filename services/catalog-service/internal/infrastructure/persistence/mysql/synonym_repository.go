@@ -0,0 +1,66 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/xiebiao/bookstore/services/catalog-service/internal/domain/book"
+)
+
+// synonymRepository 同义词仓储实现
+type synonymRepository struct {
+	db *gorm.DB
+}
+
+// NewSynonymRepository 创建同义词仓储实例
+func NewSynonymRepository(db *gorm.DB) book.SynonymRepository {
+	return &synonymRepository{db: db}
+}
+
+// List 查询全部同义词组
+//
+// 教学要点：词典规模很小（通常几百组），每次全量读取，不需要分页
+func (r *synonymRepository) List(ctx context.Context) ([]*book.SynonymGroup, error) {
+	var groups []*book.SynonymGroup
+	if err := r.db.WithContext(ctx).Order("id ASC").Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("查询同义词失败: %w", err)
+	}
+	return groups, nil
+}
+
+// Save 新建或更新同义词组
+func (r *synonymRepository) Save(ctx context.Context, g *book.SynonymGroup) error {
+	if g.ID == 0 {
+		if err := r.db.WithContext(ctx).Create(g).Error; err != nil {
+			return fmt.Errorf("创建同义词组失败: %w", err)
+		}
+		return nil
+	}
+
+	g.UpdatedAt = time.Now()
+	result := r.db.WithContext(ctx).Model(&book.SynonymGroup{}).
+		Where("id = ?", g.ID).
+		Updates(map[string]interface{}{"words": g.Words, "updated_at": g.UpdatedAt})
+	if result.Error != nil {
+		return fmt.Errorf("更新同义词组失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return book.ErrSynonymGroupNotFound
+	}
+	return nil
+}
+
+// Delete 删除同义词组
+func (r *synonymRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&book.SynonymGroup{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除同义词组失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return book.ErrSynonymGroupNotFound
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package redis
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -31,16 +33,14 @@ type CacheStore struct {
 	client    *redis.Client
 	listTTL   time.Duration
 	detailTTL time.Duration
-	searchTTL time.Duration
 }
 
 // NewCacheStore 创建缓存存储实例
-func NewCacheStore(client *redis.Client, listTTL, detailTTL, searchTTL time.Duration) *CacheStore {
+func NewCacheStore(client *redis.Client, listTTL, detailTTL time.Duration) *CacheStore {
 	return &CacheStore{
 		client:    client,
 		listTTL:   listTTL,
 		detailTTL: detailTTL,
-		searchTTL: searchTTL,
 	}
 }
 
@@ -105,44 +105,53 @@ func (c *CacheStore) DeleteBookDetail(ctx context.Context, bookID uint) error {
 	return nil
 }
 
-// GetBookList 获取图书列表缓存
-func (c *CacheStore) GetBookList(ctx context.Context, page, pageSize int, sortBy, order string) ([]*book.Book, int64, error) {
-	key := c.bookListKey(page, pageSize, sortBy, order)
+// BookListQuery 列表查询参数（全部参与缓存key）
+//
+// Cursor非空时为游标分页，Page不参与key
+type BookListQuery struct {
+	Filter   book.ListFilter
+	Page     int
+	PageSize int
+	SortBy   string
+	Order    string
+	Cursor   string
+}
+
+// BookListPage 列表缓存的内容（图书、总数、分面、下一页游标一起缓存）
+type BookListPage struct {
+	Books      []*book.Book `json:"books"`
+	Total      int64        `json:"total"`
+	Facets     *book.Facets `json:"facets"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// GetBookList 获取图书列表缓存（未命中返回nil）
+func (c *CacheStore) GetBookList(ctx context.Context, q BookListQuery) (*BookListPage, error) {
+	key := c.bookListKey(q)
 
 	// 从Redis获取JSON字符串
 	val, err := c.client.Get(ctx, key).Result()
 	if err != nil {
 		if err == redis.Nil {
-			return nil, 0, nil // 缓存未命中
+			return nil, nil // 缓存未命中
 		}
-		return nil, 0, fmt.Errorf("获取缓存失败: %w", err)
+		return nil, fmt.Errorf("获取缓存失败: %w", err)
 	}
 
 	// 反序列化
-	var result struct {
-		Books []*book.Book `json:"books"`
-		Total int64        `json:"total"`
-	}
+	var result BookListPage
 	if err := json.Unmarshal([]byte(val), &result); err != nil {
-		return nil, 0, fmt.Errorf("反序列化失败: %w", err)
+		return nil, fmt.Errorf("反序列化失败: %w", err)
 	}
 
-	return result.Books, result.Total, nil
+	return &result, nil
 }
 
 // SetBookList 设置图书列表缓存
-func (c *CacheStore) SetBookList(ctx context.Context, books []*book.Book, total int64, page, pageSize int, sortBy, order string) error {
-	key := c.bookListKey(page, pageSize, sortBy, order)
+func (c *CacheStore) SetBookList(ctx context.Context, q BookListQuery, result *BookListPage) error {
+	key := c.bookListKey(q)
 
 	// 序列化
-	result := struct {
-		Books []*book.Book `json:"books"`
-		Total int64        `json:"total"`
-	}{
-		Books: books,
-		Total: total,
-	}
-
 	val, err := json.Marshal(result)
 	if err != nil {
 		return fmt.Errorf("序列化失败: %w", err)
@@ -186,51 +195,18 @@ func (c *CacheStore) DeleteBookListCache(ctx context.Context) error {
 	return nil
 }
 
-// GetSearchResult 获取搜索结果缓存
-func (c *CacheStore) GetSearchResult(ctx context.Context, keyword string, page, pageSize int) ([]*book.Book, int64, error) {
-	key := c.searchResultKey(keyword, page, pageSize)
-
-	val, err := c.client.Get(ctx, key).Result()
-	if err != nil {
-		if err == redis.Nil {
-			return nil, 0, nil
-		}
-		return nil, 0, fmt.Errorf("获取缓存失败: %w", err)
-	}
-
-	var result struct {
-		Books []*book.Book `json:"books"`
-		Total int64        `json:"total"`
-	}
-	if err := json.Unmarshal([]byte(val), &result); err != nil {
-		return nil, 0, fmt.Errorf("反序列化失败: %w", err)
-	}
-
-	return result.Books, result.Total, nil
-}
-
-// SetSearchResult 设置搜索结果缓存
-func (c *CacheStore) SetSearchResult(ctx context.Context, books []*book.Book, total int64, keyword string, page, pageSize int) error {
-	key := c.searchResultKey(keyword, page, pageSize)
-
-	result := struct {
-		Books []*book.Book `json:"books"`
-		Total int64        `json:"total"`
-	}{
-		Books: books,
-		Total: total,
-	}
-
-	val, err := json.Marshal(result)
-	if err != nil {
-		return fmt.Errorf("序列化失败: %w", err)
-	}
-
-	if err := c.client.Set(ctx, key, val, c.searchTTL).Err(); err != nil {
-		return fmt.Errorf("设置缓存失败: %w", err)
+// InvalidateBook 图书变更后删除相关缓存（详情 + 全部列表）
+//
+// 教学要点：
+// 1. 修改、改价、上下架、删除、恢复都会影响列表内容（排序、筛选、分面），列表缓存要全部删除
+// 2. 两者都尝试删除，返回第一个错误
+func (c *CacheStore) InvalidateBook(ctx context.Context, bookID uint) error {
+	detailErr := c.DeleteBookDetail(ctx, bookID)
+	listErr := c.DeleteBookListCache(ctx)
+	if detailErr != nil {
+		return detailErr
 	}
-
-	return nil
+	return listErr
 }
 
 // bookDetailKey 生成图书详情缓存key
@@ -240,19 +216,46 @@ func (c *CacheStore) bookDetailKey(bookID uint) string {
 }
 
 // bookListKey 生成图书列表缓存key
-// 格式：catalog:list:{page}:{pageSize}:{sortBy}:{order}
+// 格式：
+//   - 页码分页：catalog:list:{page}:{pageSize}:{sortBy}:{order}[:f:{filterHash}]
+//   - 游标分页：catalog:list:c:{cursorHash}:{pageSize}:{sortBy}:{order}[:f:{filterHash}]
 //
 // 教学要点：
 // 1. Key设计原则
 //   - 包含所有查询参数（避免脏数据）
 //   - 使用冒号分隔（Redis规范）
 //   - 有业务前缀（catalog:）便于管理
-func (c *CacheStore) bookListKey(page, pageSize int, sortBy, order string) string {
-	return fmt.Sprintf("catalog:list:%d:%d:%s:%s", page, pageSize, sortBy, order)
+//
+// 2. 筛选条件含作者、出版社等任意文本（可能很长、含冒号），取哈希放入key
+//   - 无筛选条件时key与原格式相同，最常见的列表请求仍共用一份缓存
+//   - 仍以catalog:list:为前缀，DeleteBookListCache可以一并删除
+//
+// 3. 游标同样取哈希放入key：图书变更时列表缓存全部删除，缓存的下一页不会过时
+func (c *CacheStore) bookListKey(q BookListQuery) string {
+	var key string
+	if q.Cursor != "" {
+		sum := sha1.Sum([]byte(q.Cursor))
+		key = fmt.Sprintf("catalog:list:c:%s:%d:%s:%s", hex.EncodeToString(sum[:]), q.PageSize, q.SortBy, q.Order)
+	} else {
+		key = fmt.Sprintf("catalog:list:%d:%d:%s:%s", q.Page, q.PageSize, q.SortBy, q.Order)
+	}
+	if q.Filter.IsEmpty() {
+		return key
+	}
+	return key + ":f:" + filterHash(q.Filter)
 }
 
-// searchResultKey 生成搜索结果缓存key
-// 格式：catalog:search:{keyword}:{page}:{pageSize}
-func (c *CacheStore) searchResultKey(keyword string, page, pageSize int) string {
-	return fmt.Sprintf("catalog:search:%s:%d:%d", keyword, page, pageSize)
+// filterHash 筛选条件的哈希（字段按固定顺序编码，相同条件得到相同哈希）
+func filterHash(f book.ListFilter) string {
+	var from, to int64
+	if !f.PublishedFrom.IsZero() {
+		from = f.PublishedFrom.Unix()
+	}
+	if !f.PublishedTo.IsZero() {
+		to = f.PublishedTo.Unix()
+	}
+	// 作者、出版社用%q编码，避免字段内容中的分隔符造成歧义
+	canonical := fmt.Sprintf("%d|%d|%q|%q|%d|%d|%t", f.MinPrice, f.MaxPrice, f.Author, f.Publisher, from, to, f.InStockOnly)
+	sum := sha1.Sum([]byte(canonical))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,150 @@
+package search
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/xiebiao/bookstore/services/catalog-service/internal/domain/book"
+)
+
+//go:embed dict.txt
+var builtinDict string
+
+// Dictionary 查询词典（分词词表 + 同义词）
+//
+// 教学要点：
+// 1. 词典只用于切分和扩展查询，索引仍按单字/二字切分
+//   - 管理员修改词典后立即生效，不需要重建索引
+//   - 分词错误也不会让已建好的索引"漏词"
+//
+// 2. 分词：正向最大匹配（从左到右每次取词典中最长的词），词典外的字保持连续，按二字切分
+// 3. 同义词：同一组里的词互为同义词，组里的词自动加入分词词表
+//
+// Dictionary创建后只读，更新时整体替换，可以无锁并发使用
+type Dictionary struct {
+	words    map[string]bool     // 分词词表（小写）
+	maxLen   int                 // 最长词的字符数
+	synonyms map[string][]string // 词 → 同组的其他词
+}
+
+// NewDictionary 创建词典：内置词表 + 同义词组
+func NewDictionary(synonymGroups [][]string) *Dictionary {
+	d := &Dictionary{
+		words:    make(map[string]bool),
+		synonyms: make(map[string][]string),
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(builtinDict))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		d.addWord(line)
+	}
+
+	for _, group := range synonymGroups {
+		words := make([]string, 0, len(group))
+		for _, w := range group {
+			if w = normalizeWord(w); w != "" && !containsString(words, w) {
+				words = append(words, w)
+			}
+		}
+		for _, w := range words {
+			d.addWord(w)
+			for _, other := range words {
+				if other != w && !containsString(d.synonyms[w], other) {
+					d.synonyms[w] = append(d.synonyms[w], other)
+				}
+			}
+		}
+	}
+	return d
+}
+
+// LoadDictionary 从数据库读取同义词组并创建词典
+func LoadDictionary(ctx context.Context, repo book.SynonymRepository) (*Dictionary, error) {
+	groups, err := repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("加载搜索词典失败: %w", err)
+	}
+	words := make([][]string, 0, len(groups))
+	for _, g := range groups {
+		words = append(words, g.WordList())
+	}
+	return NewDictionary(words), nil
+}
+
+// Synonyms 词的同义词
+func (d *Dictionary) Synonyms(word string) []string {
+	return d.synonyms[normalizeWord(word)]
+}
+
+func (d *Dictionary) addWord(w string) {
+	w = normalizeWord(w)
+	if w == "" {
+		return
+	}
+	d.words[w] = true
+	if n := utf8.RuneCountInString(w); n > d.maxLen {
+		d.maxLen = n
+	}
+}
+
+// segment 正向最大匹配分词
+//
+// 返回切分结果，isWord标记该片段是否为词典中的词；
+// 英文/数字只能整词匹配（"go"不能匹配"golang"的前半截）
+func (d *Dictionary) segment(text string) (segments []string, isWord []bool) {
+	runes := []rune(normalizeWord(text))
+
+	leftover := 0 // 未匹配片段的起点
+	flush := func(end int) {
+		if end > leftover {
+			segments = append(segments, string(runes[leftover:end]))
+			isWord = append(isWord, false)
+		}
+	}
+
+	for i := 0; i < len(runes); {
+		matched := 0
+		if i == 0 || !(isWordRune(runes[i-1]) && isWordRune(runes[i])) {
+			for l := min(d.maxLen, len(runes)-i); l > 0; l-- {
+				end := i + l
+				if end < len(runes) && isWordRune(runes[end-1]) && isWordRune(runes[end]) {
+					continue // 截断了英文单词
+				}
+				if d.words[string(runes[i:end])] {
+					matched = l
+					break
+				}
+			}
+		}
+		if matched == 0 {
+			i++
+			continue
+		}
+		flush(i)
+		segments = append(segments, string(runes[i:i+matched]))
+		isWord = append(isWord, true)
+		i += matched
+		leftover = i
+	}
+	flush(len(runes))
+	return segments, isWord
+}
+
+// normalizeWord 统一为小写并去掉首尾空白
+func normalizeWord(w string) string {
+	return strings.ToLower(strings.TrimSpace(w))
+}
+
+// isWordRune 英文字母或数字（非汉字），这类字符组成的词不能从中间切开
+func isWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !unicode.Is(unicode.Han, r)
+}
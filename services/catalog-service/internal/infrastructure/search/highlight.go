@@ -0,0 +1,142 @@
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/xiebiao/bookstore/services/catalog-service/internal/domain/book"
+)
+
+// 高亮标签
+const (
+	highlightPreTag  = "<em>"
+	highlightPostTag = "</em>"
+)
+
+// snippetRunes 描述摘要的最大长度（字符数）
+const snippetRunes = 80
+
+// Highlight 命中字段的高亮文本（未命中的字段为空）
+//
+// 教学要点：
+// 1. 命中的词用<em></em>包裹，前端直接渲染即可；拼音命中时高亮对应的汉字
+// 2. 其余文本做HTML转义：图书信息由用户发布，不能原样拼进HTML（防XSS）
+// 3. 描述可能很长，只截取第一个命中位置附近的摘要
+type Highlight struct {
+	Title       string
+	Author      string
+	Publisher   string
+	Description string
+}
+
+// highlight 为图书生成高亮
+func highlight(b *book.Book, terms []string) Highlight {
+	set := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		set[t] = true
+	}
+
+	h := Highlight{
+		Title:     highlightField(fieldTitle, b.Title, set),
+		Author:    highlightField(fieldAuthor, b.Author, set),
+		Publisher: highlightField(fieldPublisher, b.Publisher, set),
+	}
+	if spans := matchSpans(fieldDescription, b.Description, set); len(spans) > 0 {
+		start, end := snippetRange(b.Description, spans[0].start)
+		h.Description = markSpans(b.Description, spans, start, end)
+	}
+	return h
+}
+
+// span 原文中的命中区间（字节偏移，左闭右开）
+type span struct {
+	start int
+	end   int
+}
+
+func highlightField(f field, text string, set map[string]bool) string {
+	spans := matchSpans(f, text, set)
+	if len(spans) == 0 {
+		return ""
+	}
+	return markSpans(text, spans, 0, len(text))
+}
+
+// matchSpans 找出命中查询词的区间，重叠或相邻的合并（"三体"+"体问" → "三体问"）
+func matchSpans(f field, text string, set map[string]bool) []span {
+	var spans []span
+	for _, t := range fieldTokens(f, text) {
+		if set[t.term] {
+			spans = append(spans, span{start: t.start, end: t.end})
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	merged := spans[:1]
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s.start <= last.end {
+			if s.end > last.end {
+				last.end = s.end
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// markSpans 截取[from, to)并为其中的命中区间加上高亮标签
+func markSpans(text string, spans []span, from, to int) string {
+	var sb strings.Builder
+	if from > 0 {
+		sb.WriteString("…")
+	}
+
+	pos := from
+	for _, s := range spans {
+		if s.end <= from || s.start >= to {
+			continue
+		}
+		start, end := max(s.start, pos), min(s.end, to)
+		sb.WriteString(html.EscapeString(text[pos:start]))
+		sb.WriteString(highlightPreTag)
+		sb.WriteString(html.EscapeString(text[start:end]))
+		sb.WriteString(highlightPostTag)
+		pos = end
+	}
+	sb.WriteString(html.EscapeString(text[pos:to]))
+
+	if to < len(text) {
+		sb.WriteString("…")
+	}
+	return sb.String()
+}
+
+// snippetRange 以命中位置为中心截取约snippetRunes个字符（按字符边界，不截断汉字）
+func snippetRange(text string, hit int) (int, int) {
+	runes := []rune(text)
+	if len(runes) <= snippetRunes {
+		return 0, len(text)
+	}
+
+	// 字节偏移 → 字符下标
+	hitRune := len([]rune(text[:hit]))
+	startRune := hitRune - snippetRunes/4 // 命中位置之前保留少量上下文
+	if startRune < 0 {
+		startRune = 0
+	}
+	endRune := startRune + snippetRunes
+	if endRune > len(runes) {
+		endRune = len(runes)
+		startRune = endRune - snippetRunes
+	}
+
+	start := len(string(runes[:startRune]))
+	end := start + len(string(runes[startRune:endRune]))
+	return start, end
+}
@@ -0,0 +1,387 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/xiebiao/bookstore/services/catalog-service/internal/domain/book"
+)
+
+// field 参与搜索的图书字段
+type field int
+
+const (
+	fieldTitle field = iota
+	fieldAuthor
+	fieldPublisher
+	fieldDescription
+	numFields
+)
+
+// fieldWeights 字段权重：书名命中比描述命中更相关
+var fieldWeights = [numFields]float64{
+	fieldTitle:       3.0,
+	fieldAuthor:      2.0,
+	fieldPublisher:   1.0,
+	fieldDescription: 0.5,
+}
+
+// BM25参数
+const (
+	bm25K1 = 1.2  // 词频饱和度：同一个词出现很多次，得分增长趋缓
+	bm25B  = 0.75 // 长度归一化：长文本中的命中权重低于短文本
+)
+
+// Hit 搜索命中
+type Hit struct {
+	Book      *book.Book
+	Score     float64
+	Highlight Highlight
+}
+
+// posting 倒排表中的一项：某个词在某本书各字段中的出现次数
+type posting [numFields]int
+
+// document 已索引的图书
+type document struct {
+	book   *book.Book
+	length [numFields]int // 各字段的词数
+	terms  []string       // 出现过的词（删除时用来清理倒排表）
+}
+
+// indexData 索引数据（全量重建时整体替换）
+type indexData struct {
+	docs     map[uint]*document
+	postings map[string]map[uint]*posting // 词 → 图书ID → 出现次数
+	totalLen [numFields]int               // 各字段总词数（计算平均长度）
+}
+
+func newIndexData() *indexData {
+	return &indexData{
+		docs:     make(map[uint]*document),
+		postings: make(map[string]map[uint]*posting),
+	}
+}
+
+// Index 图书全文索引（内存倒排索引）
+//
+// 教学要点：
+// 1. 倒排索引：词 → 包含该词的图书列表，查询时只看命中的图书，不用像LIKE那样逐行扫描
+// 2. 相关性排序：BM25F（按字段加权的BM25），综合词的稀有程度、词频和字段长度打分
+// 3. 索引在进程内存中，每个副本各有一份
+//   - 本副本的写操作直接增量更新（Add/Remove）
+//   - 其他副本的写操作由定时增量同步（Refresh）追上
+//   - 定时全量重建（Rebuild）兜底，清理已被物理删除的图书
+//
+// 4. 图书数量在十万级以内时内存索引足够；更大规模应换成Elasticsearch等独立搜索服务
+type Index struct {
+	mu   sync.RWMutex
+	data *indexData
+
+	dict atomic.Pointer[Dictionary] // 查询词典（整体替换，无需加锁）
+
+	// 全量重建期间的增量操作，重建完成后重放，避免被旧快照覆盖
+	rebuilding bool
+	pending    []pendingOp
+}
+
+// pendingOp 重建期间的增量操作（book为nil表示删除）
+type pendingOp struct {
+	id   uint
+	book *book.Book
+}
+
+// NewIndex 创建空索引（使用只含内置词表的词典）
+func NewIndex() *Index {
+	idx := &Index{data: newIndexData()}
+	idx.dict.Store(NewDictionary(nil))
+	return idx
+}
+
+// Add 索引图书（已存在时覆盖）
+//
+// 只索引在售图书：草稿、已下架、已删除的图书调用Add等同于Remove，
+// 调用方在图书任何变更后都调用Add即可，不用关心状态
+func (idx *Index) Add(b *book.Book) {
+	if b == nil || b.ID == 0 {
+		return
+	}
+	if !b.IsOnSale() {
+		idx.Remove(b.ID)
+		return
+	}
+	cp := *b // 拷贝一份，避免调用方后续修改影响索引
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.rebuilding {
+		idx.pending = append(idx.pending, pendingOp{id: cp.ID, book: &cp})
+	}
+	idx.data.add(&cp)
+}
+
+// Remove 从索引中删除图书
+func (idx *Index) Remove(id uint) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.rebuilding {
+		idx.pending = append(idx.pending, pendingOp{id: id})
+	}
+	idx.data.remove(id)
+}
+
+// Len 已索引的图书数
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.data.docs)
+}
+
+// SetDictionary 替换查询词典（管理员修改同义词后调用）
+func (idx *Index) SetDictionary(d *Dictionary) {
+	idx.dict.Store(d)
+}
+
+// Search 搜索图书，按相关性降序分页返回满足筛选条件的图书及分面统计
+//
+// 教学要点：
+// 1. 查询词之间是AND关系：所有词都命中的图书才返回（与LIKE包含关键词的语义一致）
+// 2. 每个词可以按原词、同义词、全拼、首字母命中，取得分最高的一种
+// 3. 筛选和分面在命中结果上进行：分面统计的是"搜到的书"里各出版社/价格区间有多少本
+// 4. 只为当前页生成高亮，避免为全部命中结果做无用功
+// 5. 相关性相同的按ID降序（新书在前），保证分页结果稳定
+func (idx *Index) Search(keyword string, filter book.ListFilter, page, pageSize int) ([]Hit, int64, *book.Facets) {
+	clauses := parseQuery(keyword, idx.dict.Load())
+	if len(clauses) == 0 {
+		return []Hit{}, 0, book.NewFacets(nil, nil)
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	scores := idx.data.score(clauses)
+	hits := make([]Hit, 0, len(scores))
+	publisherFilter, priceFilter := filter.WithoutPublisher(), filter.WithoutPrice()
+	publisherCounts := make(map[string]int64)
+	bandCounts := make([]int64, len(book.PriceBands))
+	for id, score := range scores {
+		b := idx.data.docs[id].book
+		// 分面忽略自身的筛选条件（见book.Facets）
+		if publisherFilter.Matches(b) {
+			publisherCounts[b.Publisher]++
+		}
+		if priceFilter.Matches(b) {
+			bandCounts[book.PriceBandOf(b.Price)]++
+		}
+		if filter.Matches(b) {
+			hits = append(hits, Hit{Book: b, Score: score})
+		}
+	}
+	facets := book.NewFacets(publisherCounts, bandCounts)
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Book.ID > hits[j].Book.ID
+	})
+
+	total := int64(len(hits))
+	offset := (page - 1) * pageSize
+	if offset >= len(hits) {
+		return []Hit{}, total, facets
+	}
+	end := offset + pageSize
+	if end > len(hits) {
+		end = len(hits)
+	}
+
+	terms := allTerms(clauses)
+	result := hits[offset:end]
+	for i := range result {
+		cp := *result[i].Book
+		result[i].Book = &cp
+		result[i].Highlight = highlight(&cp, terms)
+	}
+	return result, total, facets
+}
+
+// beginRebuild 标记开始全量重建
+func (idx *Index) beginRebuild() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.rebuilding = true
+	idx.pending = nil
+}
+
+// finishRebuild 用新索引替换旧索引，并重放重建期间的增量操作
+//
+// data为nil表示重建失败，保留旧索引
+func (idx *Index) finishRebuild(data *indexData) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if data != nil {
+		for _, op := range idx.pending {
+			if op.book != nil {
+				data.add(op.book)
+			} else {
+				data.remove(op.id)
+			}
+		}
+		idx.data = data
+	}
+	idx.rebuilding = false
+	idx.pending = nil
+}
+
+// add 索引图书（调用方持有写锁）
+func (d *indexData) add(b *book.Book) {
+	d.remove(b.ID)
+
+	doc := &document{book: b}
+	texts := [numFields]string{
+		fieldTitle:       b.Title,
+		fieldAuthor:      b.Author,
+		fieldPublisher:   b.Publisher,
+		fieldDescription: b.Description,
+	}
+	for f, text := range texts {
+		tokens := fieldTokens(field(f), text)
+		doc.length[f] = len(tokens)
+		d.totalLen[f] += len(tokens)
+
+		for _, t := range tokens {
+			list, ok := d.postings[t.term]
+			if !ok {
+				list = make(map[uint]*posting)
+				d.postings[t.term] = list
+			}
+			p, ok := list[b.ID]
+			if !ok {
+				p = &posting{}
+				list[b.ID] = p
+				doc.terms = append(doc.terms, t.term)
+			}
+			p[f]++
+		}
+	}
+	d.docs[b.ID] = doc
+}
+
+// remove 删除图书（调用方持有写锁）
+func (d *indexData) remove(id uint) {
+	doc, ok := d.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range doc.terms {
+		list := d.postings[term]
+		delete(list, id)
+		if len(list) == 0 {
+			delete(d.postings, term)
+		}
+	}
+	for f := range doc.length {
+		d.totalLen[f] -= doc.length[f]
+	}
+	delete(d.docs, id)
+}
+
+// score 计算命中全部查询子句的图书得分（调用方持有读锁）
+//
+// 子句得分取命中的各匹配方式中最高的一种，图书得分为各子句得分之和
+func (d *indexData) score(clauses []clause) map[uint]float64 {
+	var scores map[uint]float64
+	for _, c := range clauses {
+		clauseScores := make(map[uint]float64)
+		for _, alt := range c.alternatives {
+			for id, s := range d.scoreAll(alt.terms) {
+				if s *= alt.weight; s > clauseScores[id] {
+					clauseScores[id] = s
+				}
+			}
+		}
+
+		// AND：与之前子句的结果求交集
+		if scores == nil {
+			scores = clauseScores
+			continue
+		}
+		for id, s := range scores {
+			if cs, ok := clauseScores[id]; ok {
+				scores[id] = s + cs
+			} else {
+				delete(scores, id)
+			}
+		}
+		if len(scores) == 0 {
+			return nil
+		}
+	}
+	return scores
+}
+
+// scoreAll 计算同时命中全部词项的图书得分
+//
+// BM25F：先按字段权重和字段长度合并词频，再套用BM25公式
+//
+//	tf  = Σ weight(f) * freq(f) / (1 - b + b * len(f) / avgLen(f))
+//	idf = ln(1 + (N - df + 0.5) / (df + 0.5))
+//	score = Σ idf * tf * (k1 + 1) / (tf + k1)
+func (d *indexData) scoreAll(terms []string) map[uint]float64 {
+	lists := make([]map[uint]*posting, len(terms))
+	for i, term := range terms {
+		list := d.postings[term]
+		if len(list) == 0 {
+			return nil // 有一个词没有命中任何图书，结果为空
+		}
+		lists[i] = list
+	}
+	// 从最短的倒排表开始求交集，减少比较次数
+	sort.Slice(lists, func(i, j int) bool { return len(lists[i]) < len(lists[j]) })
+
+	n := float64(len(d.docs))
+	var avgLen [numFields]float64
+	for f := range avgLen {
+		avgLen[f] = math.Max(float64(d.totalLen[f])/n, 1)
+	}
+
+	scores := make(map[uint]float64, len(lists[0]))
+	for id := range lists[0] {
+		doc := d.docs[id]
+		total := 0.0
+		for _, list := range lists {
+			p, ok := list[id]
+			if !ok {
+				total = -1
+				break
+			}
+			tf := 0.0
+			for f := field(0); f < numFields; f++ {
+				if p[f] == 0 {
+					continue
+				}
+				norm := 1 - bm25B + bm25B*float64(doc.length[f])/avgLen[f]
+				tf += fieldWeights[f] * float64(p[f]) / norm
+			}
+			df := float64(len(list))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			total += idf * tf * (bm25K1 + 1) / (tf + bm25K1)
+		}
+		if total >= 0 {
+			scores[id] = total
+		}
+	}
+	return scores
+}
+
+// fieldTokens 字段分词：原文词项 + 拼音词项（描述较长且很少按拼音搜索，不生成拼音词项）
+func fieldTokens(f field, text string) []token {
+	tokens := tokenize(text, false)
+	if f != fieldDescription {
+		tokens = append(tokens, pinyinTokens(text)...)
+	}
+	return tokens
+}
@@ -0,0 +1,115 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/xiebiao/bookstore/services/catalog-service/internal/domain/book"
+)
+
+func newTestIndex(books ...*book.Book) *Index {
+	idx := NewIndex()
+	for _, b := range books {
+		idx.Add(b)
+	}
+	return idx
+}
+
+func onSale(id uint, title, author, description string) *book.Book {
+	return &book.Book{ID: id, Title: title, Author: author, Description: description, Status: book.BookStatusOnSale, Price: 5000}
+}
+
+// TestIndex_Search 测试查询词AND语义、字段权重排序和大小写
+func TestIndex_Search(t *testing.T) {
+	idx := newTestIndex(
+		onSale(1, "Go语言编程", "许式伟", "Go入门"),
+		onSale(2, "Redis设计与实现", "黄健宏", "深入Redis内部"),
+		onSale(3, "数据库系统", "王珊", "讲解Go和Redis的使用"),
+		&book.Book{ID: 4, Title: "Go并发编程", Status: book.BookStatusDraft},
+	)
+
+	tests := []struct {
+		name    string
+		keyword string
+		wantIDs []uint // 按相关性排序
+	}{
+		{name: "书名命中排在描述命中之前", keyword: "redis", wantIDs: []uint{2, 3}},
+		{name: "大小写不敏感", keyword: "GO", wantIDs: []uint{1, 3}},
+		{name: "多个词全部命中才返回", keyword: "go redis", wantIDs: []uint{3}},
+		{name: "中文片段", keyword: "设计与实现", wantIDs: []uint{2}},
+		{name: "作者", keyword: "王珊", wantIDs: []uint{3}},
+		{name: "未命中", keyword: "kafka", wantIDs: nil},
+		{name: "草稿不被索引", keyword: "并发", wantIDs: nil},
+		{name: "只有标点", keyword: "，", wantIDs: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, total, _ := idx.Search(tt.keyword, book.ListFilter{}, 1, 10)
+			if total != int64(len(tt.wantIDs)) || len(hits) != len(tt.wantIDs) {
+				t.Fatalf("期望命中%v，实际total=%d hits=%d", tt.wantIDs, total, len(hits))
+			}
+			for i, id := range tt.wantIDs {
+				if hits[i].Book.ID != id {
+					t.Errorf("第%d条期望图书%d，实际%d", i+1, id, hits[i].Book.ID)
+				}
+			}
+		})
+	}
+}
+
+// TestIndex_AddRemove 测试覆盖更新、下架和删除
+func TestIndex_AddRemove(t *testing.T) {
+	idx := newTestIndex(onSale(1, "Go语言编程", "", ""))
+
+	// 改名后旧词不再命中
+	idx.Add(onSale(1, "Rust编程", "", ""))
+	if _, total, _ := idx.Search("go", book.ListFilter{}, 1, 10); total != 0 {
+		t.Errorf("改名后旧书名不应命中，实际%d条", total)
+	}
+	if _, total, _ := idx.Search("rust", book.ListFilter{}, 1, 10); total != 1 {
+		t.Errorf("改名后新书名应命中，实际%d条", total)
+	}
+
+	// 下架等同于删除
+	off := onSale(1, "Rust编程", "", "")
+	off.Status = book.BookStatusOffShelf
+	idx.Add(off)
+	if idx.Len() != 0 {
+		t.Errorf("下架后期望索引为空，实际%d本", idx.Len())
+	}
+}
+
+// TestIndex_SearchFacets 测试分面统计忽略自身的筛选条件
+func TestIndex_SearchFacets(t *testing.T) {
+	withFacet := func(id uint, publisher string, price int64) *book.Book {
+		b := onSale(id, "Go编程", "", "")
+		b.Publisher, b.Price = publisher, price
+		return b
+	}
+	idx := newTestIndex(
+		withFacet(1, "A社", 1999),  // 20元以下
+		withFacet(2, "A社", 2000),  // 20-50元
+		withFacet(3, "B社", 20000), // 200元以上
+	)
+
+	hits, total, facets := idx.Search("go", book.ListFilter{Publisher: "A社", MinPrice: 2000}, 1, 10)
+	if total != 1 || hits[0].Book.ID != 2 {
+		t.Fatalf("期望只命中图书2，实际total=%d", total)
+	}
+
+	// 出版社分面只应用价格条件：A社的2号、B社的3号
+	wantPublishers := map[string]int64{"A社": 1, "B社": 1}
+	if len(facets.Publishers) != len(wantPublishers) {
+		t.Fatalf("期望出版社分面%v，实际%v", wantPublishers, facets.Publishers)
+	}
+	for _, f := range facets.Publishers {
+		if wantPublishers[f.Value] != f.Count {
+			t.Errorf("出版社%s期望%d本，实际%d本", f.Value, wantPublishers[f.Value], f.Count)
+		}
+	}
+
+	// 价格分面只应用出版社条件：A社的1号和2号分属相邻两档
+	if len(facets.PriceBands) != 2 || facets.PriceBands[0].Max != 2000 || facets.PriceBands[1].Min != 2000 {
+		t.Errorf("期望价格分面为20元以下和20-50元各1本，实际%+v", facets.PriceBands)
+	}
+}
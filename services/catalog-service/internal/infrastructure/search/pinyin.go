@@ -0,0 +1,183 @@
+package search
+
+import (
+	"bufio"
+	_ "embed"
+	"strings"
+	"unicode/utf8"
+)
+
+//go:embed pinyin.txt
+var pinyinData string
+
+// 拼音词项前缀（与普通词项区分，避免"an"这类拼音与英文单词混在一起）
+const (
+	pinyinPrefix   = "py:" // 全拼：py:san、py:san_ti
+	initialsPrefix = "in:" // 首字母：in:st
+)
+
+// polyphones 常见多音字的其他读音（pinyin.txt中每个汉字只有一个读音）
+//
+// 教学要点：书名里的多音字很常见（"长安"读chang、"重庆"读chong），
+// 只收一个读音会导致用户按正确读音搜不到，这里把常见读音都建入索引
+var polyphones = map[rune][]string{
+	'长': {"chang"}, '重': {"chong"}, '行': {"hang"}, '地': {"di"}, '率': {"shuai"},
+	'调': {"tiao"}, '还': {"huan"}, '乐': {"yue"}, '得': {"dei"}, '都': {"du"},
+	'和': {"huo"}, '传': {"zhuan"}, '藏': {"zang"}, '朝': {"zhao"}, '差': {"chai", "ci"},
+	'单': {"shan", "chan"}, '曾': {"zeng"}, '降': {"xiang"}, '大': {"dai"}, '便': {"pian"},
+	'觉': {"jiao"}, '校': {"jiao"}, '省': {"xing"}, '角': {"jue"}, '解': {"xie"},
+	'薄': {"bo"}, '弹': {"tan"}, '的': {"di"}, '呵': {"he"}, '沈': {"shen"},
+	'区': {"ou"}, '仇': {"qiu"}, '朴': {"piao"}, '查': {"zha"}, '翟': {"zhai"},
+	'盛': {"cheng"}, '参': {"shen", "cen"}, '乘': {"sheng"}, '会': {"kuai"}, '给': {"ji"},
+	'了': {"liao"}, '着': {"zhao", "zhuo"}, '系': {"ji"}, '血': {"xie"}, '露': {"lou"},
+	'似': {"si"}, '曲': {"qu"}, '数': {"shuo"}, '种': {"chong"}, '相': {"xiang"},
+}
+
+// pinyinTable 汉字 → 读音；pinyinSyllables 合法音节（拆分全拼查询用）
+var (
+	pinyinTable     map[rune][]string
+	pinyinSyllables map[string]bool
+	maxSyllableLen  int
+)
+
+func init() {
+	pinyinTable = make(map[rune][]string, 21000)
+	pinyinSyllables = make(map[string]bool, 410)
+
+	scanner := bufio.NewScanner(strings.NewReader(pinyinData))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		syllable, chars, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		pinyinSyllables[syllable] = true
+		if len(syllable) > maxSyllableLen {
+			maxSyllableLen = len(syllable)
+		}
+		for _, r := range chars {
+			pinyinTable[r] = []string{syllable}
+		}
+	}
+
+	for r, readings := range polyphones {
+		for _, p := range readings {
+			if !containsString(pinyinTable[r], p) {
+				pinyinTable[r] = append(pinyinTable[r], p)
+			}
+		}
+	}
+}
+
+// pinyinTokens 为汉字生成拼音词项（连续汉字 → 全拼单音节/双音节 + 首字母二元组）
+//
+// 教学要点：
+// 1. 与汉字二元切分同样的思路，只是把字换成了读音
+//   - "三体" → py:san、py:ti、py:san_ti、in:st
+//
+// 2. 多音字的每个读音都生成词项（"长安" → py:chang_an、py:zhang_an）
+// 3. 首字母只建二元组：单个字母匹配面太广，没有检索价值
+// 4. 词项的偏移指向对应的汉字，拼音命中时高亮的是汉字本身
+func pinyinTokens(text string) []token {
+	var tokens []token
+	var run []token // 当前连续汉字
+
+	flush := func() {
+		for i, c := range run {
+			readings := pinyinOf(c.term)
+			for _, p := range readings {
+				tokens = append(tokens, token{term: pinyinPrefix + p, start: c.start, end: c.end})
+			}
+			if i == 0 {
+				continue
+			}
+			prev := run[i-1]
+			seenInitials := make(map[string]bool, 2)
+			for _, p1 := range pinyinOf(prev.term) {
+				for _, p2 := range readings {
+					tokens = append(tokens, token{term: pinyinPrefix + p1 + "_" + p2, start: prev.start, end: c.end})
+					initials := p1[:1] + p2[:1]
+					if !seenInitials[initials] {
+						seenInitials[initials] = true
+						tokens = append(tokens, token{term: initialsPrefix + initials, start: prev.start, end: c.end})
+					}
+				}
+			}
+		}
+		run = run[:0]
+	}
+
+	for i, r := range text {
+		if _, ok := pinyinTable[r]; ok {
+			run = append(run, token{term: string(r), start: i, end: i + utf8.RuneLen(r)})
+			continue
+		}
+		flush()
+	}
+	flush()
+
+	return tokens
+}
+
+// pinyinOf 单个汉字的读音
+func pinyinOf(char string) []string {
+	r, _ := utf8.DecodeRuneInString(char)
+	return pinyinTable[r]
+}
+
+// splitPinyin 把全拼拆成音节，无法完整拆分时返回nil
+//
+// 教学要点：动态规划求音节数最少的拆法（"xian"拆成xian而不是xi+an），
+// 需要区分时用户可以输入分隔符（"xi'an"）
+func splitPinyin(s string) []string {
+	n := len(s)
+	// best[i]：s[:i]的最少音节数，prev[i]：最后一个音节的起点
+	best := make([]int, n+1)
+	prev := make([]int, n+1)
+	for i := 1; i <= n; i++ {
+		best[i] = -1
+		for l := 1; l <= maxSyllableLen && l <= i; l++ {
+			j := i - l
+			if best[j] < 0 || !pinyinSyllables[normalizeSyllable(s[j:i])] {
+				continue
+			}
+			if best[i] < 0 || best[j]+1 < best[i] {
+				best[i] = best[j] + 1
+				prev[i] = j
+			}
+		}
+	}
+	if best[n] < 0 {
+		return nil
+	}
+
+	syllables := make([]string, best[n])
+	for i, k := n, best[n]-1; i > 0; i, k = prev[i], k-1 {
+		syllables[k] = normalizeSyllable(s[prev[i]:i])
+	}
+	return syllables
+}
+
+// normalizeSyllable 兼容ü的常见输入方式（lue/nue → lve/nve）
+func normalizeSyllable(s string) string {
+	switch s {
+	case "lue":
+		return "lve"
+	case "nue":
+		return "nve"
+	}
+	return s
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package search
+
+// 各类匹配方式的得分权重（原词命中为1）
+const (
+	synonymWeight  = 0.8 // 同义词
+	pinyinWeight   = 0.6 // 全拼
+	initialsWeight = 0.4 // 首字母（最容易误命中）
+)
+
+// maxInitialsLen 超过该长度的字母串不再按首字母匹配（书名首字母一般不会这么长）
+const maxInitialsLen = 12
+
+// clause 查询子句：一个词的多种匹配方式，命中任意一种即可
+type clause struct {
+	alternatives []alternative
+}
+
+// alternative 一种匹配方式：terms全部命中才算命中，得分乘以weight
+type alternative struct {
+	terms  []string
+	weight float64
+}
+
+// parseQuery 关键词 → 查询子句（子句之间是AND关系）
+//
+// 教学要点：
+// 1. 先用词典分词，词典中的词作为整体，并扩展出同义词
+//   - "电脑编程"（词典：电脑=计算机）→ [电脑|计算机] AND [编程]
+//
+// 2. 词典外的片段按单字/二字切分，每个词项一个子句
+// 3. 英文字母串同时尝试原词、全拼、首字母三种匹配
+//   - "santi" → 原词santi | 全拼san_ti
+//   - "slzt"  → 原词slzt | 首字母sl、lz、zt
+func parseQuery(keyword string, dict *Dictionary) []clause {
+	var clauses []clause
+
+	segments, isWord := dict.segment(keyword)
+	for i, seg := range segments {
+		if isWord[i] {
+			c := clause{}
+			if terms := queryTerms(seg); len(terms) > 0 {
+				c.alternatives = append(c.alternatives, alternative{terms: terms, weight: 1})
+			}
+			for _, syn := range dict.Synonyms(seg) {
+				if terms := queryTerms(syn); len(terms) > 0 {
+					c.alternatives = append(c.alternatives, alternative{terms: terms, weight: synonymWeight})
+				}
+			}
+			if len(c.alternatives) > 0 {
+				clauses = append(clauses, c)
+			}
+			continue
+		}
+
+		for _, term := range queryTerms(seg) {
+			clauses = append(clauses, termClause(term))
+		}
+	}
+	return clauses
+}
+
+// termClause 单个查询词项的子句（英文字母串增加拼音匹配方式）
+func termClause(term string) clause {
+	c := clause{alternatives: []alternative{{terms: []string{term}, weight: 1}}}
+	if !isLetters(term) {
+		return c
+	}
+
+	if syllables := splitPinyin(term); len(syllables) > 0 {
+		c.alternatives = append(c.alternatives, alternative{terms: syllableTerms(syllables), weight: pinyinWeight})
+	}
+	if len(term) >= 2 && len(term) <= maxInitialsLen {
+		terms := make([]string, 0, len(term)-1)
+		for i := 0; i+1 < len(term); i++ {
+			t := initialsPrefix + term[i:i+2]
+			if !containsString(terms, t) {
+				terms = append(terms, t)
+			}
+		}
+		c.alternatives = append(c.alternatives, alternative{terms: terms, weight: initialsWeight})
+	}
+	return c
+}
+
+// syllableTerms 音节 → 全拼词项（单音节取单音节，多音节取相邻二元组）
+func syllableTerms(syllables []string) []string {
+	if len(syllables) == 1 {
+		return []string{pinyinPrefix + syllables[0]}
+	}
+	terms := make([]string, 0, len(syllables)-1)
+	for i := 0; i+1 < len(syllables); i++ {
+		t := pinyinPrefix + syllables[i] + "_" + syllables[i+1]
+		if !containsString(terms, t) {
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// allTerms 查询涉及的全部词项（用于高亮）
+func allTerms(clauses []clause) []string {
+	var terms []string
+	for _, c := range clauses {
+		for _, alt := range c.alternatives {
+			terms = append(terms, alt.terms...)
+		}
+	}
+	return terms
+}
+
+// isLetters 是否全部为ASCII小写字母（拼音只可能由字母组成）
+func isLetters(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'a' || s[i] > 'z' {
+			return false
+		}
+	}
+	return s != ""
+}
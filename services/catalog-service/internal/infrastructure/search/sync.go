@@ -0,0 +1,126 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/xiebiao/bookstore/services/catalog-service/internal/domain/book"
+)
+
+// refreshOverlap 增量同步的回看时间
+//
+// 教学要点：updated_at由写入方在提交前生成，提交较慢的事务可能比已同步的记录"更早"，
+// 每次同步往前多看一段时间，重复索引同一本书是幂等的
+const refreshOverlap = 5 * time.Second
+
+// Syncer 索引与MySQL的同步
+//
+// 教学要点：
+// 1. 按(updated_at, id)游标分批读取，不用OFFSET（深分页慢，且读取期间有写入会跳行）
+// 2. Rebuild：启动时和定时全量重建，建好后整体替换，期间搜索仍使用旧索引
+// 3. Refresh：只读取上次同步之后更新过的图书，追上其他副本的写入
+// 4. 同义词词典很小，每次Refresh全量重新加载，其他副本的修改同样能追上
+type Syncer struct {
+	repo      book.Repository
+	synonyms  book.SynonymRepository
+	index     *Index
+	batchSize int
+
+	watermark time.Time // 已同步的最大updated_at（只在同步任务中访问）
+}
+
+// NewSyncer 创建同步器
+func NewSyncer(repo book.Repository, synonyms book.SynonymRepository, index *Index, batchSize int) *Syncer {
+	return &Syncer{
+		repo:      repo,
+		synonyms:  synonyms,
+		index:     index,
+		batchSize: batchSize,
+	}
+}
+
+// Rebuild 全量重建索引，返回索引的图书数
+func (s *Syncer) Rebuild(ctx context.Context) (int, error) {
+	if err := s.ReloadDictionary(ctx); err != nil {
+		return 0, err
+	}
+
+	s.index.beginRebuild()
+
+	data := newIndexData()
+	watermark, err := s.scan(ctx, time.Time{}, func(b *book.Book) {
+		if b.IsOnSale() {
+			data.add(b)
+		}
+	})
+	if err != nil {
+		s.index.finishRebuild(nil)
+		return 0, fmt.Errorf("重建搜索索引失败: %w", err)
+	}
+
+	s.index.finishRebuild(data)
+	if watermark.After(s.watermark) {
+		s.watermark = watermark
+	}
+	return s.index.Len(), nil
+}
+
+// Refresh 增量同步上次同步之后更新过的图书，返回同步的图书数
+func (s *Syncer) Refresh(ctx context.Context) (int, error) {
+	if err := s.ReloadDictionary(ctx); err != nil {
+		return 0, err
+	}
+
+	since := time.Time{}
+	if !s.watermark.IsZero() {
+		since = s.watermark.Add(-refreshOverlap)
+	}
+
+	count := 0
+	watermark, err := s.scan(ctx, since, func(b *book.Book) {
+		s.index.Add(b)
+		count++
+	})
+	if err != nil {
+		return count, fmt.Errorf("同步搜索索引失败: %w", err)
+	}
+
+	if watermark.After(s.watermark) {
+		s.watermark = watermark
+	}
+	return count, nil
+}
+
+// ReloadDictionary 重新加载同义词词典
+func (s *Syncer) ReloadDictionary(ctx context.Context) error {
+	dict, err := LoadDictionary(ctx, s.synonyms)
+	if err != nil {
+		return err
+	}
+	s.index.SetDictionary(dict)
+	return nil
+}
+
+// scan 分批读取updated_at >= since的图书，返回读到的最大updated_at
+func (s *Syncer) scan(ctx context.Context, since time.Time, fn func(*book.Book)) (time.Time, error) {
+	watermark := since
+	cursorTime, cursorID := since, uint(0)
+	for {
+		books, err := s.repo.ListUpdatedSince(ctx, cursorTime, cursorID, s.batchSize)
+		if err != nil {
+			return watermark, err
+		}
+		for _, b := range books {
+			fn(b)
+			if b.UpdatedAt.After(watermark) {
+				watermark = b.UpdatedAt
+			}
+		}
+		if len(books) < s.batchSize {
+			return watermark, nil
+		}
+		last := books[len(books)-1]
+		cursorTime, cursorID = last.UpdatedAt, last.ID
+	}
+}
@@ -0,0 +1,103 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// token 分词结果
+//
+// start/end为词在原文中的字节偏移（左闭右开），用于高亮
+type token struct {
+	term  string
+	start int
+	end   int
+}
+
+// tokenize 分词
+//
+// 教学要点：
+// 1. 英文/数字：连续的字母数字为一个词，统一转小写（"Golang" 与 "golang" 相同）
+// 2. 中文：没有空格分隔，这里用N-gram切分（与MySQL FULLTEXT的ngram parser思路相同）
+//   - 建索引时同时产出单字和相邻二字（"三体问题" → 三/体/问/题/三体/体问/问题）
+//   - 查询时连续两个以上汉字只取二字（"体问题" → 体问/问题），单个汉字取单字
+//   - 查询的二字全部命中，基本等价于包含该连续片段，又不需要词典
+//
+// 3. 标点、空白等其他字符作为分隔符丢弃
+//
+// query为true时按查询方式切分中文
+func tokenize(text string, query bool) []token {
+	var tokens []token
+
+	wordStart := -1 // 当前英文/数字词的起点
+	var han []token // 当前连续汉字（单字）
+
+	flushWord := func(end int) {
+		if wordStart >= 0 {
+			tokens = append(tokens, token{term: strings.ToLower(text[wordStart:end]), start: wordStart, end: end})
+			wordStart = -1
+		}
+	}
+	flushHan := func() {
+		tokens = append(tokens, hanGrams(han, query)...)
+		han = han[:0]
+	}
+
+	for i, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord(i)
+			han = append(han, token{term: string(r), start: i, end: i + utf8.RuneLen(r)})
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			if wordStart < 0 {
+				wordStart = i
+			}
+		default:
+			flushWord(i)
+			flushHan()
+		}
+	}
+	flushWord(len(text))
+	flushHan()
+
+	return tokens
+}
+
+// hanGrams 连续汉字 → 单字 + 二字
+func hanGrams(chars []token, query bool) []token {
+	if len(chars) == 0 {
+		return nil
+	}
+	if query && len(chars) == 1 {
+		return []token{chars[0]}
+	}
+
+	grams := make([]token, 0, 2*len(chars))
+	if !query {
+		grams = append(grams, chars...)
+	}
+	for i := 0; i+1 < len(chars); i++ {
+		grams = append(grams, token{
+			term:  chars[i].term + chars[i+1].term,
+			start: chars[i].start,
+			end:   chars[i+1].end,
+		})
+	}
+	return grams
+}
+
+// queryTerms 查询关键词 → 去重后的查询词
+func queryTerms(keyword string) []string {
+	tokens := tokenize(keyword, true)
+	seen := make(map[string]bool, len(tokens))
+	terms := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		if !seen[t.term] {
+			seen[t.term] = true
+			terms = append(terms, t.term)
+		}
+	}
+	return terms
+}
@@ -0,0 +1,92 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestTokenize 测试英文、数字、中文N-gram分词及字节偏移
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		query bool
+		want  []token
+	}{
+		{
+			name: "英文转小写，标点分隔",
+			text: "Go, Golang!",
+			want: []token{{term: "go", start: 0, end: 2}, {term: "golang", start: 4, end: 10}},
+		},
+		{
+			name: "字母数字连续为一个词",
+			text: "MySQL8 v2",
+			want: []token{{term: "mysql8", start: 0, end: 6}, {term: "v2", start: 7, end: 9}},
+		},
+		{
+			name: "建索引：单字加二字",
+			text: "三体",
+			want: []token{
+				{term: "三", start: 0, end: 3},
+				{term: "体", start: 3, end: 6},
+				{term: "三体", start: 0, end: 6},
+			},
+		},
+		{
+			name:  "查询：连续汉字只取二字",
+			text:  "三体问",
+			query: true,
+			want:  []token{{term: "三体", start: 0, end: 6}, {term: "体问", start: 3, end: 9}},
+		},
+		{
+			name:  "查询：单个汉字取单字",
+			text:  "书",
+			query: true,
+			want:  []token{{term: "书", start: 0, end: 3}},
+		},
+		{
+			name:  "中英混排：汉字与字母互为分隔",
+			text:  "Go语言",
+			query: true,
+			want:  []token{{term: "go", start: 0, end: 2}, {term: "语言", start: 2, end: 8}},
+		},
+		{
+			name:  "空白和标点之间的汉字各自成段",
+			text:  "人、书",
+			query: true,
+			want:  []token{{term: "人", start: 0, end: 3}, {term: "书", start: 6, end: 9}},
+		},
+		{
+			name: "只有标点",
+			text: " ，。!",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenize(tt.text, tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize(%q, %v) = %+v，期望%+v", tt.text, tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestQueryTerms 测试查询词去重并保持顺序
+func TestQueryTerms(t *testing.T) {
+	tests := []struct {
+		keyword string
+		want    []string
+	}{
+		{keyword: "Go go GO", want: []string{"go"}},
+		{keyword: "三体 三体", want: []string{"三体"}},
+		{keyword: "Redis设计与实现", want: []string{"redis", "设计", "计与", "与实", "实现"}},
+		{keyword: "  ", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := queryTerms(tt.keyword); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("queryTerms(%q) = %v，期望%v", tt.keyword, got, tt.want)
+		}
+	}
+}
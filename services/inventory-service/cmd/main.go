@@ -14,9 +14,13 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
+	"github.com/xiebiao/bookstore/pkg/events"
+	"github.com/xiebiao/bookstore/pkg/mq"
 	inventoryv1 "github.com/xiebiao/bookstore/proto/inventoryv1"
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
 	"github.com/xiebiao/bookstore/services/inventory-service/internal/grpc/handler"
 	"github.com/xiebiao/bookstore/services/inventory-service/internal/infrastructure/config"
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/infrastructure/notifier"
 	"github.com/xiebiao/bookstore/services/inventory-service/internal/infrastructure/persistence/mysql"
 	redisStore "github.com/xiebiao/bookstore/services/inventory-service/internal/infrastructure/persistence/redis"
 )
@@ -55,13 +59,24 @@ func main() {
 	log.Println("✅ 数据库连接成功")
 
 	// 步骤3：初始化Redis连接
-	redisClient := redis.NewClient(&redis.Options{
-		Addr:         cfg.Redis.Addr,
-		Password:     cfg.Redis.Password,
-		DB:           cfg.Redis.DB,
-		PoolSize:     cfg.Redis.PoolSize,
-		MinIdleConns: cfg.Redis.MinIdleConns,
-	})
+	// 配置了集群节点时使用Redis Cluster（库存脚本的key按库存单元共用hash tag，见InventoryStore）
+	var redisClient redis.UniversalClient
+	if len(cfg.Redis.Addrs) > 0 {
+		redisClient = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        cfg.Redis.Addrs,
+			Password:     cfg.Redis.Password,
+			PoolSize:     cfg.Redis.PoolSize,
+			MinIdleConns: cfg.Redis.MinIdleConns,
+		})
+	} else {
+		redisClient = redis.NewClient(&redis.Options{
+			Addr:         cfg.Redis.Addr,
+			Password:     cfg.Redis.Password,
+			DB:           cfg.Redis.DB,
+			PoolSize:     cfg.Redis.PoolSize,
+			MinIdleConns: cfg.Redis.MinIdleConns,
+		})
+	}
 
 	defer redisClient.Close()
 
@@ -78,6 +93,9 @@ func main() {
 	// 步骤4：创建Redis库存存储并预加载Lua脚本
 	inventoryStore := redisStore.NewInventoryStore(redisClient)
 
+	// 仓库兜底顺序（优先仓库库存不足时按配置顺序使用其他仓库）
+	inventoryStore.SetWarehousePriority(cfg.Inventory.WarehouseIDs())
+
 	// 教学要点：预加载Lua脚本到Redis
 	// 好处：后续使用EVALSHA调用，减少网络传输
 	if err := inventoryStore.LoadScripts(ctx); err != nil {
@@ -89,9 +107,24 @@ func main() {
 	// 步骤5：创建仓储实例
 	inventoryRepo := mysql.NewInventoryRepository(db)
 	logRepo := mysql.NewLogRepository(db)
+	subRepo := mysql.NewSubscriptionRepository(db)
+
+	// 步骤6：创建事件发布者和gRPC Handler
+	//
+	// 教学要点：库存跨过低库存阈值/缺货时发布告警事件（inventory.low_stock / inventory.out_of_stock）
+	publisher, err := mq.NewPublisher(cfg.MQ.URL, events.Exchange, events.ExchangeType)
+	if err != nil {
+		log.Fatalf("创建事件发布者失败: %v", err)
+	}
+	defer publisher.Close()
 
-	// 步骤6：创建gRPC Handler
-	inventoryHandler := handler.NewInventoryServiceServer(inventoryRepo, logRepo, inventoryStore)
+	// 到货提醒发送渠道（本地开发用log/file）
+	backInStockNotifier, err := notifier.New(cfg.Notify.Driver, cfg.Notify.FilePath)
+	if err != nil {
+		log.Fatalf("创建到货提醒通知器失败: %v", err)
+	}
+
+	inventoryHandler := handler.NewInventoryServiceServer(inventoryRepo, logRepo, subRepo, inventoryStore, &cfg.Inventory, publisher, backInStockNotifier)
 
 	// 步骤7：创建gRPC服务器
 	grpcServer := grpc.NewServer(
@@ -121,15 +154,165 @@ func main() {
 		}
 	}()
 
-	// 步骤9：优雅关闭
+	// 步骤9：启动过期预占扫描任务
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	go startReservationSweeper(bgCtx, inventoryHandler, cfg.Inventory.GetReservationSweepInterval())
+
+	// 步骤10：启动定时对账任务
+	if cfg.Inventory.ReconcileInterval > 0 {
+		policy, _ := inventory.ParseRepairPolicy(cfg.Inventory.ReconcilePolicy) // 配置加载时已校验
+		go startReconcileJob(bgCtx, inventoryHandler, time.Duration(cfg.Inventory.ReconcileInterval)*time.Second, policy)
+	}
+
+	// 步骤11：订阅库存变更广播（WatchStock推送）
+	go startStockWatch(bgCtx, inventoryHandler)
+
+	// 步骤12：热点图书库存分片与定时再平衡
+	if len(cfg.Inventory.HotBooks) > 0 {
+		go startShardRebalancer(bgCtx, inventoryHandler, cfg.Inventory.GetShardRebalanceInterval())
+	}
+
+	// 步骤13：消费到货事件，分批通知订阅了到货提醒的用户
+	//
+	// 教学要点：订阅按状态幂等（通知后即标记），不需要按消息ID去重
+	backInStockConsumer, err := mq.NewConsumer(
+		cfg.MQ.URL,
+		events.Exchange,
+		events.ExchangeType,
+		cfg.MQ.GetBackInStockQueue(),
+		[]string{events.InventoryBackInStock},
+	)
+	if err != nil {
+		log.Fatalf("创建到货事件消费者失败: %v", err)
+	}
+	defer backInStockConsumer.Close()
+	go startBackInStockConsumer(bgCtx, backInStockConsumer, inventoryHandler)
+
+	// 步骤14：优雅关闭
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Println("📴 收到关闭信号，开始优雅关闭...")
 
+	// 停止后台任务
+	bgCancel()
+
 	// 停止gRPC服务器
 	grpcServer.GracefulStop()
 
 	log.Println("✅ inventory-service 已安全关闭")
 }
+
+// startReservationSweeper 定时取消过期预占
+//
+// 教学要点：
+// 1. 预占的TTL不依赖Redis key过期：key过期只会丢掉记录，锁定库存无法退回
+// 2. 由扫描任务调用取消脚本，库存退回与状态变更在同一个Lua脚本中原子完成
+// 3. 每批最多处理100条，积压时下一轮继续
+func startReservationSweeper(ctx context.Context, h *handler.InventoryServiceServer, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("⏰ 过期预占扫描任务已启动（间隔:%s）", interval)
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("过期预占扫描任务已停止")
+			return
+		case <-ticker.C:
+			n, err := h.ExpireReservations(ctx, 100)
+			if err != nil {
+				log.Printf("⚠️ 取消过期预占失败: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("✅ 本轮取消过期预占%d条", n)
+			}
+		}
+	}
+}
+
+// startStockWatch 订阅库存变更广播，分发给本副本的WatchStock连接
+//
+// 教学要点：每个副本只订阅一次Redis频道，再按图书ID分发给本地连接，连接数再多也只占一个Redis连接
+func startStockWatch(ctx context.Context, h *handler.InventoryServiceServer) {
+	log.Println("📡 库存变更订阅已启动")
+	if err := h.RunStockWatch(ctx); err != nil {
+		log.Printf("⚠️ 库存变更订阅退出: %v", err)
+		return
+	}
+	log.Println("库存变更订阅已停止")
+}
+
+// startShardRebalancer 热点图书库存分片与定时再平衡
+//
+// 教学要点：启动时立即按配置分片一次，之后定时重新均分（每次扣减、释放只落在一个分片上，分片间会逐渐不均衡）；
+// 同时让本实例记住各图书的分片数，扣减据此选择分片
+func startShardRebalancer(ctx context.Context, h *handler.InventoryServiceServer, interval time.Duration) {
+	rebalanceOnce := func() {
+		if _, err := h.RebalanceHotBooks(ctx); err != nil {
+			log.Printf("⚠️ 热点图书库存分片失败: %v", err)
+		}
+	}
+
+	log.Printf("🔀 热点图书库存分片任务已启动（间隔:%s）", interval)
+	rebalanceOnce()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("热点图书库存分片任务已停止")
+			return
+		case <-ticker.C:
+			rebalanceOnce()
+		}
+	}
+}
+
+// startBackInStockConsumer 启动到货事件消费（到货提醒）
+//
+// 教学要点：处理函数返回error时消息进入重试队列延迟重投，已通知的用户不会被重复通知
+func startBackInStockConsumer(ctx context.Context, consumer *mq.Consumer, h *handler.InventoryServiceServer) {
+	err := consumer.Consume(ctx, func(body []byte) error {
+		return h.HandleBackInStock(ctx, body)
+	})
+	if err != nil {
+		log.Printf("❌ 到货事件消费已停止: %v", err)
+	}
+}
+
+// startReconcileJob 定时对账Redis与MySQL库存
+//
+// 教学要点：
+// 1. 启动时先对账一次：Redis重启后库存key丢失，auto策略会立即从MySQL恢复
+// 2. 之后按间隔全量对账，不一致的图书记录日志（生产环境应接入告警）
+func startReconcileJob(ctx context.Context, h *handler.InventoryServiceServer, interval time.Duration, policy inventory.RepairPolicy) {
+	reconcileOnce := func() {
+		result, err := h.Reconcile(ctx, nil, policy)
+		if err != nil {
+			log.Printf("⚠️ 库存对账失败: %v", err)
+			return
+		}
+		if len(result.Drifts) > 0 {
+			log.Printf("⚖️ 库存对账完成：检查%d本，不一致%d本，修复%d本", result.Checked, len(result.Drifts), result.Repaired)
+		}
+	}
+
+	log.Printf("⚖️ 库存对账任务已启动（间隔:%s 策略:%s）", interval, policy)
+	reconcileOnce()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("库存对账任务已停止")
+			return
+		case <-ticker.C:
+			reconcileOnce()
+		}
+	}
+}
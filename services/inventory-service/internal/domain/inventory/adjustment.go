@@ -0,0 +1,38 @@
+package inventory
+
+// adjustDirections 库存调整原因及允许的调整方向
+//
+// 教学要点：
+// 1. 原因码直接作为日志的ChangeType记录，审计时按类型统计报损、丢失金额
+// 2. 方向由原因决定：报损/丢失只能减少，退货入库只能增加，盘点差异可增可减
+// 3. 补货（RESTOCK）走RestockInventory，不属于调整：调整是对账面的修正，补货是正常入库
+var adjustDirections = map[ChangeType]int{
+	ChangeTypeDamage:    -1,
+	ChangeTypeLoss:      -1,
+	ChangeTypeStocktake: 0,
+	ChangeTypeReturn:    1,
+}
+
+// ParseAdjustReason 解析库存调整原因（DAMAGE/LOSS/STOCKTAKE/RETURN）
+func ParseAdjustReason(reason string) (ChangeType, error) {
+	changeType := ChangeType(reason)
+	if _, ok := adjustDirections[changeType]; !ok {
+		return "", ErrInvalidAdjustReason
+	}
+	return changeType, nil
+}
+
+// ValidateAdjustment 校验调整数量（有符号数）与调整原因的方向是否一致
+func ValidateAdjustment(reason ChangeType, quantity int) error {
+	direction, ok := adjustDirections[reason]
+	if !ok {
+		return ErrInvalidAdjustReason
+	}
+	if quantity == 0 {
+		return ErrInvalidQuantity
+	}
+	if direction < 0 && quantity > 0 || direction > 0 && quantity < 0 {
+		return ErrAdjustDirection
+	}
+	return nil
+}
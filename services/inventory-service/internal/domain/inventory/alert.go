@@ -0,0 +1,45 @@
+package inventory
+
+// StockAlert 库存告警类型
+type StockAlert string
+
+const (
+	StockAlertNone       StockAlert = ""
+	StockAlertLowStock   StockAlert = "low_stock"
+	StockAlertOutOfStock StockAlert = "out_of_stock"
+)
+
+// DetectStockAlert 根据变更前后的可用库存判断是否触发告警
+//
+// 教学要点：
+// 1. 边沿触发：只有"跨过"告警线的那次变更才告警
+//   - 库存从20扣到8（阈值10）→ 低库存告警
+//   - 库存从8扣到5 → 已经在低库存状态，不再告警
+//
+// 2. 缺货优先：一次扣减从高于阈值直接扣到0，只发缺货告警
+// 3. 补货后再次跨过阈值会重新告警（状态无需持久化）
+func DetectStockAlert(beforeStock, afterStock, threshold int) StockAlert {
+	before := &Inventory{Stock: beforeStock}
+	after := &Inventory{Stock: afterStock}
+
+	if after.IsOutOfStock() {
+		if before.IsOutOfStock() {
+			return StockAlertNone
+		}
+		return StockAlertOutOfStock
+	}
+
+	if after.IsLowStock(threshold) && !before.IsLowStock(threshold) && !before.IsOutOfStock() {
+		return StockAlertLowStock
+	}
+	return StockAlertNone
+}
+
+// IsBackInStock 根据变更前后的可用库存判断是否到货（从缺货变为有货）
+//
+// 与告警一样边沿触发：库存从0补到10才算到货，从5补到10不会再次通知订阅用户
+func IsBackInStock(beforeStock, afterStock int) bool {
+	before := &Inventory{Stock: beforeStock}
+	after := &Inventory{Stock: afterStock}
+	return before.IsOutOfStock() && !after.IsOutOfStock()
+}
@@ -34,6 +34,10 @@ type Inventory struct {
 	// 教学要点：TotalStock = Stock + LockedStock，用于库存盘点
 	TotalStock int `gorm:"not null;default:0" json:"total_stock"`
 
+	// 低库存阈值（NULL表示使用默认阈值inventory.warning_threshold）
+	// 教学要点：畅销书和长尾书的补货节奏不同，阈值按图书单独设置
+	LowStockThreshold *int `gorm:"column:low_stock_threshold" json:"low_stock_threshold"`
+
 	// 创建时间
 	CreatedAt time.Time `json:"created_at"`
 
@@ -79,6 +83,46 @@ func (i *Inventory) CanLock(quantity int) bool {
 	return i.Stock >= quantity && quantity > 0
 }
 
+// Lock 锁定库存（预占）：可用库存 → 锁定库存，总库存不变
+func (i *Inventory) Lock(quantity int) error {
+	if !i.CanLock(quantity) {
+		return ErrInsufficientStock
+	}
+	i.Stock -= quantity
+	i.LockedStock += quantity
+	i.TotalStock = i.Stock + i.LockedStock
+	return nil
+}
+
+// ConfirmLocked 确认锁定库存（售出）：锁定库存减少，总库存随之减少
+func (i *Inventory) ConfirmLocked(quantity int) error {
+	if quantity <= 0 || i.LockedStock < quantity {
+		return ErrInsufficientLockedStock
+	}
+	i.LockedStock -= quantity
+	i.TotalStock = i.Stock + i.LockedStock
+	return nil
+}
+
+// Unlock 解锁库存（取消预占）：锁定库存 → 可用库存，总库存不变
+func (i *Inventory) Unlock(quantity int) error {
+	if quantity <= 0 || i.LockedStock < quantity {
+		return ErrInsufficientLockedStock
+	}
+	i.LockedStock -= quantity
+	i.Stock += quantity
+	i.TotalStock = i.Stock + i.LockedStock
+	return nil
+}
+
+// EffectiveThreshold 生效的低库存阈值（未单独设置时使用默认阈值）
+func (i *Inventory) EffectiveThreshold(defaultThreshold int) int {
+	if i.LowStockThreshold != nil {
+		return *i.LowStockThreshold
+	}
+	return defaultThreshold
+}
+
 // IsLowStock 判断是否低库存（需要告警）
 func (i *Inventory) IsLowStock(threshold int) bool {
 	return i.Stock <= threshold && i.Stock > 0
@@ -31,4 +31,24 @@ var (
 	// 幂等性错误
 	ErrDuplicateDeduction = errors.New("重复扣减（订单已处理）")
 	ErrDuplicateRelease   = errors.New("重复释放（订单已处理）")
+
+	// 预占错误
+	ErrReservationNotFound  = errors.New("预占记录不存在")
+	ErrDuplicateReservation = errors.New("重复预占（订单已处理）")
+	ErrReservationConfirmed = errors.New("预占已确认")
+	ErrReservationCancelled = errors.New("预占已取消")
+
+	// 仓库错误
+	ErrInvalidWarehouse           = errors.New("无效的仓库")
+	ErrInsufficientWarehouseStock = errors.New("仓库库存不足")
+
+	// 对账错误
+	ErrStockChanged = errors.New("库存已变化")
+
+	// 库存调整错误
+	ErrInvalidAdjustReason = errors.New("无效的调整原因")
+	ErrAdjustDirection     = errors.New("调整数量与调整原因不符")
+
+	// 到货提醒错误
+	ErrSubscriptionNotFound = errors.New("未订阅到货提醒")
 )
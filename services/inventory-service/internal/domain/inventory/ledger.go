@@ -0,0 +1,169 @@
+package inventory
+
+import "time"
+
+// 日志链断点类型
+const (
+	LedgerGapChainBreak       = "CHAIN_BREAK"       // BeforeStock不等于上一条日志的AfterStock（中间缺日志或有人直接改表）
+	LedgerGapQuantityMismatch = "QUANTITY_MISMATCH" // AfterStock - BeforeStock与变更数量不符
+)
+
+// maxLedgerGaps 单本图书最多记录的断点数（断点过多时只保留前面的，GapCount为总数）
+const maxLedgerGaps = 100
+
+// LedgerGap 日志链断点
+type LedgerGap struct {
+	LogID       uint
+	PrevLogID   uint // 同一条链上的上一条日志（数量不符时为0）
+	WarehouseID uint
+	Kind        string
+	Expected    int // 断链：上一条的AfterStock；数量不符：Before + 变更数量
+	Actual      int // 断链：本条的BeforeStock；数量不符：本条的AfterStock
+}
+
+// ledgerChain 一条日志链（可售库存或某个仓库）的重放状态
+type ledgerChain struct {
+	started  bool
+	stock    int // 按变更数量累加的库存
+	recorded int // 最后一条日志的AfterStock
+	lastID   uint
+}
+
+// LedgerReplay 按库存日志重放历史库存
+//
+// 教学要点：
+// 1. 日志即账本（Ledger）
+//   - 每条日志记录变更前后的库存，按ID顺序首尾相接：本条BeforeStock == 上一条AfterStock
+//   - 可售库存（WarehouseID=0）和每个仓库各是一条独立的链
+//
+// 2. 重放：期初库存 + 逐条变更数量 = 任意时刻的库存
+//   - 期初库存取链首日志的BeforeStock（建账、初始化库存不写日志）
+//   - 链条完整时，重放结果与最后一条日志的AfterStock一致；不一致说明账本有缺口
+//
+// 3. 锁定库存按LOCK/UNLOCK/确认预占推算
+//   - 对账修复锁定库存时不写日志，修复过的图书推算值可能不准
+//
+// 4. MySQL按行锁串行写入日志，同一图书的日志ID顺序就是变更顺序
+type LedgerReplay struct {
+	BookID uint
+	AsOf   time.Time
+
+	OpeningStock  int // 期初可用库存
+	Stock         int // 重放得到的可用库存
+	RecordedStock int // 最后一条日志记录的可用库存
+	LockedStock   int // 推算的锁定库存
+
+	LogCount  int
+	LastLogID uint
+	LastLogAt time.Time
+
+	Gaps     []LedgerGap
+	GapCount int
+
+	chains map[uint]*ledgerChain
+}
+
+// NewLedgerReplay 创建重放器（asOf为截止时间）
+func NewLedgerReplay(bookID uint, asOf time.Time) *LedgerReplay {
+	return &LedgerReplay{
+		BookID: bookID,
+		AsOf:   asOf,
+		chains: make(map[uint]*ledgerChain),
+	}
+}
+
+// Apply 重放一条日志（须按ID升序调用，且CreatedAt不晚于AsOf）
+func (r *LedgerReplay) Apply(l *InventoryLog) {
+	chain, ok := r.chains[l.WarehouseID]
+	if !ok {
+		chain = &ledgerChain{}
+		r.chains[l.WarehouseID] = chain
+	}
+
+	if !chain.started {
+		chain.started = true
+		chain.stock = l.BeforeStock
+		if l.WarehouseID == UnassignedWarehouseID {
+			r.OpeningStock = l.BeforeStock
+		}
+	} else if l.BeforeStock != chain.recorded {
+		r.addGap(LedgerGap{
+			LogID:       l.ID,
+			PrevLogID:   chain.lastID,
+			WarehouseID: l.WarehouseID,
+			Kind:        LedgerGapChainBreak,
+			Expected:    chain.recorded,
+			Actual:      l.BeforeStock,
+		})
+	}
+
+	delta := l.stockDelta()
+	if l.AfterStock-l.BeforeStock != delta {
+		r.addGap(LedgerGap{
+			LogID:       l.ID,
+			WarehouseID: l.WarehouseID,
+			Kind:        LedgerGapQuantityMismatch,
+			Expected:    l.BeforeStock + delta,
+			Actual:      l.AfterStock,
+		})
+	}
+
+	chain.stock += delta
+	chain.recorded = l.AfterStock
+	chain.lastID = l.ID
+
+	if l.WarehouseID == UnassignedWarehouseID {
+		r.Stock = chain.stock
+		r.RecordedStock = chain.recorded
+		r.LockedStock += l.lockedDelta()
+	}
+
+	r.LogCount++
+	r.LastLogID = l.ID
+	r.LastLogAt = l.CreatedAt
+}
+
+// Warehouses 各仓库重放得到的库存（只含有日志的仓库）
+func (r *LedgerReplay) Warehouses() map[uint]int {
+	stocks := make(map[uint]int, len(r.chains))
+	for id, chain := range r.chains {
+		if id != UnassignedWarehouseID {
+			stocks[id] = chain.stock
+		}
+	}
+	return stocks
+}
+
+// Consistent 日志链是否完整（无断点且重放结果与记录一致）
+func (r *LedgerReplay) Consistent() bool {
+	return r.GapCount == 0 && r.Stock == r.RecordedStock
+}
+
+func (r *LedgerReplay) addGap(gap LedgerGap) {
+	r.GapCount++
+	if len(r.Gaps) < maxLedgerGaps {
+		r.Gaps = append(r.Gaps, gap)
+	}
+}
+
+// stockDelta 日志对本条链库存的影响（确认预占售出的是锁定库存，可用库存不变）
+func (l *InventoryLog) stockDelta() int {
+	if l.WarehouseID == UnassignedWarehouseID && l.IsConfirm() {
+		return 0
+	}
+	return l.Quantity
+}
+
+// lockedDelta 日志对锁定库存的影响（只看可售库存层的日志）
+func (l *InventoryLog) lockedDelta() int {
+	switch {
+	case l.ChangeType == ChangeTypeLock:
+		return -l.Quantity // 可用库存减少多少，锁定库存增加多少
+	case l.ChangeType == ChangeTypeUnlock:
+		return -l.Quantity
+	case l.IsConfirm():
+		return l.Quantity // 售出数量为负数
+	default:
+		return 0
+	}
+}
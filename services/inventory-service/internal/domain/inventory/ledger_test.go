@@ -0,0 +1,189 @@
+package inventory
+
+import (
+	"testing"
+	"time"
+)
+
+// ledgerLog 构造一条日志（ID自增由调用方保证）
+func ledgerLog(id, warehouseID uint, changeType ChangeType, quantity, before, after int) *InventoryLog {
+	return &InventoryLog{
+		ID:          id,
+		BookID:      1,
+		WarehouseID: warehouseID,
+		ChangeType:  changeType,
+		Quantity:    quantity,
+		BeforeStock: before,
+		AfterStock:  after,
+	}
+}
+
+// confirmLog 确认预占日志：售出锁定库存，可用库存不变
+func confirmLog(id uint, quantity, stock int) *InventoryLog {
+	l := ledgerLog(id, UnassignedWarehouseID, ChangeTypeDeduct, quantity, stock, stock)
+	l.Remark = ConfirmRemark
+	return l
+}
+
+// TestLedgerReplay 测试重放结果和断点检测
+func TestLedgerReplay(t *testing.T) {
+	tests := []struct {
+		name           string
+		logs           []*InventoryLog
+		wantOpening    int
+		wantStock      int
+		wantRecorded   int
+		wantLocked     int
+		wantWarehouses map[uint]int
+		wantGaps       []LedgerGap
+	}{
+		{
+			name: "完整链条：补货、锁定、确认、解锁",
+			logs: []*InventoryLog{
+				ledgerLog(1, 0, ChangeTypeRestock, 10, 5, 15),
+				ledgerLog(2, 0, ChangeTypeLock, -3, 15, 12),
+				confirmLog(3, -2, 12),
+				ledgerLog(4, 0, ChangeTypeUnlock, 1, 12, 13),
+			},
+			wantOpening:  5,
+			wantStock:    13,
+			wantRecorded: 13,
+			wantLocked:   0, // 锁定3，售出2，解锁1
+		},
+		{
+			name: "仓库链与可售库存链互不影响",
+			logs: []*InventoryLog{
+				ledgerLog(1, 0, ChangeTypeRestock, 10, 0, 10),
+				ledgerLog(2, 1, ChangeTypeRestock, 10, 0, 10),
+				ledgerLog(3, 0, ChangeTypeLock, -4, 10, 6),
+				ledgerLog(4, 1, ChangeTypeTransfer, -3, 10, 7),
+				ledgerLog(5, 2, ChangeTypeTransfer, 3, 0, 3),
+			},
+			wantOpening:    0,
+			wantStock:      6,
+			wantRecorded:   6,
+			wantLocked:     4,
+			wantWarehouses: map[uint]int{1: 7, 2: 3},
+		},
+		{
+			name: "断链：中间缺一条日志",
+			logs: []*InventoryLog{
+				ledgerLog(1, 0, ChangeTypeRestock, 10, 0, 10),
+				ledgerLog(3, 0, ChangeTypeDeduct, -2, 8, 6),
+			},
+			wantOpening:  0,
+			wantStock:    8, // 按变更数量累加：0 + 10 - 2
+			wantRecorded: 6,
+			wantGaps: []LedgerGap{
+				{LogID: 3, PrevLogID: 1, Kind: LedgerGapChainBreak, Expected: 10, Actual: 8},
+			},
+		},
+		{
+			name: "仓库链断链带上仓库ID",
+			logs: []*InventoryLog{
+				ledgerLog(1, 2, ChangeTypeRestock, 5, 0, 5),
+				ledgerLog(2, 2, ChangeTypeDamage, -1, 6, 5),
+			},
+			wantWarehouses: map[uint]int{2: 4},
+			wantGaps: []LedgerGap{
+				{LogID: 2, PrevLogID: 1, WarehouseID: 2, Kind: LedgerGapChainBreak, Expected: 5, Actual: 6},
+			},
+		},
+		{
+			name: "数量不符：前后差与变更数量不一致",
+			logs: []*InventoryLog{
+				ledgerLog(1, 0, ChangeTypeRestock, 10, 0, 10),
+				ledgerLog(2, 0, ChangeTypeDeduct, -2, 10, 7),
+			},
+			wantOpening:  0,
+			wantStock:    8,
+			wantRecorded: 7,
+			wantGaps: []LedgerGap{
+				{LogID: 2, Kind: LedgerGapQuantityMismatch, Expected: 8, Actual: 7},
+			},
+		},
+		{
+			name: "确认预占的前后库存必须相同",
+			logs: []*InventoryLog{
+				ledgerLog(1, 0, ChangeTypeLock, -2, 10, 8),
+				func() *InventoryLog { l := confirmLog(2, -2, 8); l.AfterStock = 6; return l }(),
+			},
+			wantOpening:  10,
+			wantStock:    8,
+			wantRecorded: 6,
+			wantLocked:   0,
+			wantGaps: []LedgerGap{
+				{LogID: 2, Kind: LedgerGapQuantityMismatch, Expected: 8, Actual: 6},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewLedgerReplay(1, time.Now())
+			for _, l := range tt.logs {
+				r.Apply(l)
+			}
+
+			if r.OpeningStock != tt.wantOpening || r.Stock != tt.wantStock || r.RecordedStock != tt.wantRecorded || r.LockedStock != tt.wantLocked {
+				t.Errorf("期望期初/重放/记录/锁定=%d/%d/%d/%d，实际%d/%d/%d/%d",
+					tt.wantOpening, tt.wantStock, tt.wantRecorded, tt.wantLocked,
+					r.OpeningStock, r.Stock, r.RecordedStock, r.LockedStock)
+			}
+			if r.LogCount != len(tt.logs) || r.LastLogID != tt.logs[len(tt.logs)-1].ID {
+				t.Errorf("期望日志数%d、最后日志ID%d，实际%d/%d", len(tt.logs), tt.logs[len(tt.logs)-1].ID, r.LogCount, r.LastLogID)
+			}
+
+			warehouses := r.Warehouses()
+			if len(warehouses) != len(tt.wantWarehouses) {
+				t.Errorf("期望仓库库存%v，实际%v", tt.wantWarehouses, warehouses)
+			}
+			for id, stock := range tt.wantWarehouses {
+				if warehouses[id] != stock {
+					t.Errorf("仓库%d期望库存%d，实际%d", id, stock, warehouses[id])
+				}
+			}
+
+			if r.GapCount != len(tt.wantGaps) || len(r.Gaps) != len(tt.wantGaps) {
+				t.Fatalf("期望%d个断点，实际%d个: %+v", len(tt.wantGaps), r.GapCount, r.Gaps)
+			}
+			for i, want := range tt.wantGaps {
+				if r.Gaps[i] != want {
+					t.Errorf("断点%d期望%+v，实际%+v", i, want, r.Gaps[i])
+				}
+			}
+			if wantConsistent := len(tt.wantGaps) == 0; r.Consistent() != wantConsistent {
+				t.Errorf("期望Consistent=%v", wantConsistent)
+			}
+		})
+	}
+}
+
+// TestLedgerReplay_GapCap 测试断点超过上限时只保留前maxLedgerGaps个，GapCount为总数
+func TestLedgerReplay_GapCap(t *testing.T) {
+	tests := []struct {
+		breaks   int
+		wantKept int
+	}{
+		{breaks: maxLedgerGaps - 1, wantKept: maxLedgerGaps - 1},
+		{breaks: maxLedgerGaps, wantKept: maxLedgerGaps},
+		{breaks: maxLedgerGaps + 50, wantKept: maxLedgerGaps},
+	}
+
+	for _, tt := range tests {
+		r := NewLedgerReplay(1, time.Now())
+		r.Apply(ledgerLog(1, 0, ChangeTypeRestock, 1, 0, 1))
+		// 每条日志的BeforeStock都比上一条AfterStock多1：每条都是一个断链
+		for i := 0; i < tt.breaks; i++ {
+			before := 2 * (i + 1)
+			r.Apply(ledgerLog(uint(i+2), 0, ChangeTypeRestock, 1, before, before+1))
+		}
+
+		if r.GapCount != tt.breaks || len(r.Gaps) != tt.wantKept {
+			t.Errorf("断链%d次: 期望GapCount=%d、保留%d个，实际%d/%d", tt.breaks, tt.breaks, tt.wantKept, r.GapCount, len(r.Gaps))
+		}
+		if len(r.Gaps) > 0 && r.Gaps[0].LogID != 2 {
+			t.Errorf("应保留最早的断点，实际第一个为日志%d", r.Gaps[0].LogID)
+		}
+	}
+}
@@ -21,12 +21,20 @@ type InventoryLog struct {
 	// 图书ID
 	BookID uint `gorm:"index:idx_book_id;not null" json:"book_id"`
 
+	// 仓库ID
+	// 0: 可售库存（全部仓库合计）的变更，对账、幂等判断只看这一层
+	// >0: 该仓库库存的变更（扣减分配、补货入库、调拨），Before/After为该仓库的库存
+	WarehouseID uint `gorm:"not null;default:0;index:idx_warehouse_id" json:"warehouse_id"`
+
 	// 变更类型
 	// DEDUCT: 扣减库存（支付成功）
 	// RELEASE: 释放库存（订单取消、支付失败）
 	// RESTOCK: 补充库存（补货）
 	// LOCK: 锁定库存（下单）
 	// UNLOCK: 解锁库存（订单取消）
+	// RECONCILE: 对账修复（Redis与MySQL不一致时以Redis为准修复）
+	// TRANSFER: 仓库间调拨（调出、调入各一条，可售库存不变）
+	// DAMAGE/LOSS/STOCKTAKE/RETURN: 库存调整（报损、丢失、盘点差异、退货入库）
 	ChangeType ChangeType `gorm:"type:varchar(20);not null" json:"change_type"`
 
 	// 变更数量（正数=增加，负数=减少）
@@ -57,13 +65,27 @@ func (InventoryLog) TableName() string {
 type ChangeType string
 
 const (
-	ChangeTypeDeduct  ChangeType = "DEDUCT"  // 扣减
-	ChangeTypeRelease ChangeType = "RELEASE" // 释放
-	ChangeTypeRestock ChangeType = "RESTOCK" // 补充
-	ChangeTypeLock    ChangeType = "LOCK"    // 锁定
-	ChangeTypeUnlock  ChangeType = "UNLOCK"  // 解锁
+	ChangeTypeDeduct    ChangeType = "DEDUCT"    // 扣减
+	ChangeTypeRelease   ChangeType = "RELEASE"   // 释放
+	ChangeTypeRestock   ChangeType = "RESTOCK"   // 补充
+	ChangeTypeLock      ChangeType = "LOCK"      // 锁定
+	ChangeTypeUnlock    ChangeType = "UNLOCK"    // 解锁
+	ChangeTypeReconcile ChangeType = "RECONCILE" // 对账修复
+	ChangeTypeTransfer  ChangeType = "TRANSFER"  // 仓库调拨
+	ChangeTypeDamage    ChangeType = "DAMAGE"    // 报损
+	ChangeTypeLoss      ChangeType = "LOSS"      // 丢失
+	ChangeTypeStocktake ChangeType = "STOCKTAKE" // 盘点差异
+	ChangeTypeReturn    ChangeType = "RETURN"    // 退货入库
 )
 
+// ConfirmRemark 确认预占日志的备注（区分DEDUCT日志是直接扣减还是锁定库存售出）
+const ConfirmRemark = "reservation_confirmed"
+
+// IsConfirm 是否为确认预占日志（可用库存不变，售出的是锁定库存）
+func (l *InventoryLog) IsConfirm() bool {
+	return l.ChangeType == ChangeTypeDeduct && l.Remark == ConfirmRemark
+}
+
 // NewDeductLog 创建扣减日志
 func NewDeductLog(bookID uint, quantity int, before, after int, orderID uint) *InventoryLog {
 	return &InventoryLog{
@@ -89,6 +111,60 @@ func NewReleaseLog(bookID uint, quantity int, before, after int, orderID uint, r
 	}
 }
 
+// NewLockLog 创建锁定日志（预占）
+//
+// Before/After记录的是可用库存，与其他日志一致，便于按日志串联核对
+func NewLockLog(bookID uint, quantity int, before, after int, orderID uint) *InventoryLog {
+	return &InventoryLog{
+		BookID:      bookID,
+		ChangeType:  ChangeTypeLock,
+		Quantity:    -quantity, // 可用库存减少
+		BeforeStock: before,
+		AfterStock:  after,
+		OrderID:     orderID,
+	}
+}
+
+// NewUnlockLog 创建解锁日志（取消预占）
+func NewUnlockLog(bookID uint, quantity int, before, after int, orderID uint, reason string) *InventoryLog {
+	return &InventoryLog{
+		BookID:      bookID,
+		ChangeType:  ChangeTypeUnlock,
+		Quantity:    quantity, // 可用库存增加
+		BeforeStock: before,
+		AfterStock:  after,
+		OrderID:     orderID,
+		Remark:      reason,
+	}
+}
+
+// NewConfirmLog 创建确认预占日志（支付成功，锁定库存售出）
+//
+// 可用库存不变（Before == After），Quantity为售出数量（负数），扣减发生在锁定库存上
+func NewConfirmLog(bookID uint, quantity int, stock int, orderID uint) *InventoryLog {
+	return &InventoryLog{
+		BookID:      bookID,
+		ChangeType:  ChangeTypeDeduct,
+		Quantity:    -quantity,
+		BeforeStock: stock,
+		AfterStock:  stock,
+		OrderID:     orderID,
+		Remark:      ConfirmRemark,
+	}
+}
+
+// NewReconcileLog 创建对账修复日志（按Redis修复MySQL时写入，保证日志链连续）
+func NewReconcileLog(bookID uint, before, after int, remark string) *InventoryLog {
+	return &InventoryLog{
+		BookID:      bookID,
+		ChangeType:  ChangeTypeReconcile,
+		Quantity:    after - before,
+		BeforeStock: before,
+		AfterStock:  after,
+		Remark:      remark,
+	}
+}
+
 // NewRestockLog 创建补货日志
 func NewRestockLog(bookID uint, quantity int, before, after int) *InventoryLog {
 	return &InventoryLog{
@@ -99,3 +175,31 @@ func NewRestockLog(bookID uint, quantity int, before, after int) *InventoryLog {
 		AfterStock:  after,
 	}
 }
+
+// NewAdjustLog 创建库存调整日志（quantity为有符号数，changeType为调整原因）
+func NewAdjustLog(bookID uint, changeType ChangeType, quantity int, before, after int, remark string) *InventoryLog {
+	return &InventoryLog{
+		BookID:      bookID,
+		ChangeType:  changeType,
+		Quantity:    quantity,
+		BeforeStock: before,
+		AfterStock:  after,
+		Remark:      remark,
+	}
+}
+
+// NewWarehouseLog 创建仓库库存变更日志
+//
+// quantity为有符号数（负数减少），Before/After为该仓库的库存
+func NewWarehouseLog(bookID, warehouseID uint, changeType ChangeType, quantity int, before, after int, orderID uint, remark string) *InventoryLog {
+	return &InventoryLog{
+		BookID:      bookID,
+		WarehouseID: warehouseID,
+		ChangeType:  changeType,
+		Quantity:    quantity,
+		BeforeStock: before,
+		AfterStock:  after,
+		OrderID:     orderID,
+		Remark:      remark,
+	}
+}
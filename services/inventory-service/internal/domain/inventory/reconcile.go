@@ -0,0 +1,100 @@
+package inventory
+
+import "fmt"
+
+// RepairPolicy 库存对账修复策略
+//
+// 教学要点：
+// 1. Redis和MySQL各有可能"丢数据"：
+//   - Redis重启/淘汰：库存key丢失或回退到旧快照 → 应以MySQL为准
+//   - MySQL异步同步失败：Redis已扣减，MySQL未落库 → 应以Redis为准
+//
+// 2. 程序无法在所有情况下判断哪一边正确，因此修复方向由策略决定：
+//   - report_only：只报告，不修复（默认，先人工确认）
+//   - auto：只修复能确定的情况（Redis key缺失时从MySQL恢复），其余只报告
+//   - mysql_to_redis / redis_to_mysql：明确指定以哪一边为准
+type RepairPolicy string
+
+const (
+	RepairReportOnly   RepairPolicy = "report_only"
+	RepairAuto         RepairPolicy = "auto"
+	RepairMySQLToRedis RepairPolicy = "mysql_to_redis"
+	RepairRedisToMySQL RepairPolicy = "redis_to_mysql"
+)
+
+// ParseRepairPolicy 解析修复策略（空字符串为report_only）
+func ParseRepairPolicy(s string) (RepairPolicy, error) {
+	switch p := RepairPolicy(s); p {
+	case "":
+		return RepairReportOnly, nil
+	case RepairReportOnly, RepairAuto, RepairMySQLToRedis, RepairRedisToMySQL:
+		return p, nil
+	default:
+		return "", fmt.Errorf("未知的修复策略: %s", s)
+	}
+}
+
+// 对账修复动作
+const (
+	ReconcileActionNone          = ""               // 未修复
+	ReconcileActionRedisRepaired = "redis_repaired" // 已按MySQL修复Redis
+	ReconcileActionMySQLRepaired = "mysql_repaired" // 已按Redis修复MySQL
+	ReconcileActionChanged       = "changed"        // 修复前库存又发生变化，跳过（下一轮再对账）
+	ReconcileActionMySQLMissing  = "mysql_missing"  // MySQL无库存记录，需人工处理
+)
+
+// StockDrift 单本图书的库存对账结果
+//
+// Ledger为inventory_logs中最后一条日志的AfterStock（日志链的"账面库存"）：
+// MySQL库存与日志在同一事务写入，二者不一致说明有人绕过仓储直接改了表
+type StockDrift struct {
+	BookID uint
+
+	RedisStock   int
+	RedisLocked  int
+	RedisMissing bool // Redis中没有库存key（重启或被清理）
+
+	MySQLStock   int
+	MySQLLocked  int
+	MySQLMissing bool // MySQL中没有库存记录
+
+	LedgerStock int
+	HasLedger   bool // 是否有库存日志
+
+	Action string // 修复动作（ReconcileAction*）
+}
+
+// HasDrift Redis与MySQL是否不一致
+func (d *StockDrift) HasDrift() bool {
+	return d.RedisMissing || d.MySQLMissing ||
+		d.RedisStock != d.MySQLStock || d.RedisLocked != d.MySQLLocked
+}
+
+// LedgerMismatch MySQL库存与日志账面库存是否不一致
+func (d *StockDrift) LedgerMismatch() bool {
+	return d.HasLedger && !d.MySQLMissing && d.LedgerStock != d.MySQLStock
+}
+
+// RepairDirection 按策略决定修复方向，返回要执行的修复动作（ReconcileActionNone表示不修复）
+func (d *StockDrift) RepairDirection(policy RepairPolicy) string {
+	if !d.HasDrift() {
+		return ReconcileActionNone
+	}
+	if d.MySQLMissing {
+		return ReconcileActionMySQLMissing
+	}
+
+	switch policy {
+	case RepairAuto:
+		if d.RedisMissing {
+			return ReconcileActionRedisRepaired
+		}
+	case RepairMySQLToRedis:
+		return ReconcileActionRedisRepaired
+	case RepairRedisToMySQL:
+		if !d.RedisMissing {
+			return ReconcileActionMySQLRepaired
+		}
+	}
+	return ReconcileActionNone
+}
@@ -1,6 +1,35 @@
 package inventory
 
-import "context"
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// StockItem 批量库存操作的单项
+type StockItem struct {
+	BookID   uint
+	Quantity int
+}
+
+// MergeStockItems 合并同一图书的数量，并按图书ID升序返回
+//
+// 教学要点：
+// - 同一图书的扣减记录按(图书, 订单)去重，必须先合并，否则第二项会被当成重复扣减
+// - 固定顺序加锁（MySQL按book_id升序SELECT FOR UPDATE），避免两个批量事务互相等待造成死锁
+func MergeStockItems(items []StockItem) []StockItem {
+	merged := make(map[uint]int, len(items))
+	for _, item := range items {
+		merged[item.BookID] += item.Quantity
+	}
+
+	result := make([]StockItem, 0, len(merged))
+	for bookID, quantity := range merged {
+		result = append(result, StockItem{BookID: bookID, Quantity: quantity})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].BookID < result[j].BookID })
+	return result
+}
 
 // Repository 库存仓储接口（领域层定义）
 //
@@ -30,8 +59,47 @@ type Repository interface {
 	// ReleaseStock 释放库存
 	ReleaseStock(ctx context.Context, bookID uint, quantity int, orderID uint, reason string) error
 
+	// BatchDeductStock 批量扣减库存（同一事务，任一图书库存不足则全部回滚）
+	BatchDeductStock(ctx context.Context, items []StockItem, orderID uint) error
+
+	// BatchReleaseStock 批量释放库存（同一事务，未扣减或已释放的图书跳过）
+	BatchReleaseStock(ctx context.Context, items []StockItem, orderID uint, reason string) error
+
 	// RestockInventory 补充库存
 	RestockInventory(ctx context.Context, bookID uint, quantity int) error
+
+	// AdjustStock 调整可用库存（quantity为有符号数，changeType为调整原因）
+	AdjustStock(ctx context.Context, bookID uint, quantity int, changeType ChangeType, remark string) error
+
+	// ReserveStock 预占库存（可用库存 → 锁定库存，并创建预占记录）
+	ReserveStock(ctx context.Context, bookID uint, quantity int, orderID uint, expiresAt time.Time) error
+
+	// BatchReserveStock 批量预占库存（同一事务，任一图书库存不足则全部回滚，已预占的图书跳过）
+	BatchReserveStock(ctx context.Context, items []StockItem, orderID uint, expiresAt time.Time) error
+
+	// ConfirmReservation 确认预占（锁定库存售出）
+	ConfirmReservation(ctx context.Context, bookID uint, orderID uint) error
+
+	// CancelReservation 取消预占（锁定库存退回可用库存）
+	CancelReservation(ctx context.Context, bookID uint, orderID uint, reason string) error
+
+	// SetLowStockThreshold 设置图书的低库存阈值（nil表示使用默认阈值）
+	SetLowStockThreshold(ctx context.Context, bookID uint, threshold *int) error
+
+	// ListLowStock 分页查询低库存图书（可用库存不高于生效阈值，按库存升序）
+	ListLowStock(ctx context.Context, defaultThreshold int, includeOutOfStock bool, page, pageSize int) ([]*Inventory, int64, error)
+
+	// ListWarehouseStocks 查询图书在各仓库的库存（按仓库ID升序）
+	ListWarehouseStocks(ctx context.Context, bookID uint) ([]*WarehouseStock, error)
+
+	// ChangeWarehouseStock 变更仓库库存（同一事务，每个仓库一条日志；Quantity为有符号数，未分配仓库跳过）
+	ChangeWarehouseStock(ctx context.Context, bookID uint, changes []WarehouseAllocation, changeType ChangeType, orderID uint, remark string) error
+
+	// ListAfter 按图书ID升序分页查询库存（游标为上一页最后的图书ID，用于全量对账）
+	ListAfter(ctx context.Context, afterBookID uint, limit int) ([]*Inventory, error)
+
+	// ApplyReconcile 对账修复：库存仍为期望值时更新为目标值并写入对账日志，否则返回ErrStockChanged
+	ApplyReconcile(ctx context.Context, bookID uint, expectStock, expectLocked, stock, locked int, remark string) error
 }
 
 // LogRepository 库存日志仓储接口
@@ -44,4 +112,10 @@ type LogRepository interface {
 
 	// ListByOrderID 查询指定订单的库存日志
 	ListByOrderID(ctx context.Context, orderID uint) ([]*InventoryLog, error)
+
+	// LatestByBookIDs 查询每本图书最新的一条库存日志（对账时取账面库存）
+	LatestByBookIDs(ctx context.Context, bookIDs []uint) (map[uint]*InventoryLog, error)
+
+	// ListForReplay 按ID升序查询图书在截止时间之前的日志（游标为上一页最后的日志ID，用于重放）
+	ListForReplay(ctx context.Context, bookID uint, afterLogID uint, until time.Time, limit int) ([]*InventoryLog, error)
 }
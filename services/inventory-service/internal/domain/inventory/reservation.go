@@ -0,0 +1,104 @@
+package inventory
+
+import "time"
+
+// Reservation 库存预占记录（两阶段扣减）
+//
+// 教学要点：
+// 1. 为什么需要预占？
+//   - 直接扣减：用户下单不支付，库存被"卖掉"却没有收入
+//   - 预占：下单时把库存从Stock移到LockedStock，支付成功才真正售出，超时自动退回
+//
+// 2. 状态流转
+//   - RESERVED → CONFIRMED（支付成功）
+//   - RESERVED → CANCELLED（订单取消、预占过期）
+//   - CONFIRMED/CANCELLED为终态，不可再变更
+//
+// 3. 幂等键：(order_id, book_id) 唯一，同一订单同一图书只预占一次
+type Reservation struct {
+	// 主键ID
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// 订单ID
+	OrderID uint `gorm:"uniqueIndex:uk_order_book;not null" json:"order_id"`
+
+	// 图书ID
+	BookID uint `gorm:"uniqueIndex:uk_order_book;not null" json:"book_id"`
+
+	// 预占数量
+	Quantity int `gorm:"not null" json:"quantity"`
+
+	// 预占状态
+	Status ReservationStatus `gorm:"type:varchar(20);not null;index:idx_status_expires" json:"status"`
+
+	// 过期时间（超过后由过期任务取消）
+	ExpiresAt time.Time `gorm:"not null;index:idx_status_expires" json:"expires_at"`
+
+	// 取消原因
+	Reason string `gorm:"type:varchar(64)" json:"reason,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Reservation) TableName() string {
+	return "inventory_reservations"
+}
+
+// ReservationStatus 预占状态
+type ReservationStatus string
+
+const (
+	ReservationStatusReserved  ReservationStatus = "RESERVED"  // 已预占
+	ReservationStatusConfirmed ReservationStatus = "CONFIRMED" // 已确认（售出）
+	ReservationStatusCancelled ReservationStatus = "CANCELLED" // 已取消（库存已退回）
+)
+
+// 预占取消原因
+const (
+	ReservationReasonExpired = "reservation_expired" // 预占过期
+)
+
+// NewReservation 创建预占记录
+func NewReservation(bookID uint, quantity int, orderID uint, expiresAt time.Time) *Reservation {
+	return &Reservation{
+		OrderID:   orderID,
+		BookID:    bookID,
+		Quantity:  quantity,
+		Status:    ReservationStatusReserved,
+		ExpiresAt: expiresAt,
+	}
+}
+
+// Confirm 确认预占
+func (r *Reservation) Confirm() error {
+	switch r.Status {
+	case ReservationStatusReserved:
+		r.Status = ReservationStatusConfirmed
+		return nil
+	case ReservationStatusConfirmed:
+		return ErrReservationConfirmed
+	default:
+		return ErrReservationCancelled
+	}
+}
+
+// Cancel 取消预占
+func (r *Reservation) Cancel(reason string) error {
+	switch r.Status {
+	case ReservationStatusReserved:
+		r.Status = ReservationStatusCancelled
+		r.Reason = reason
+		return nil
+	case ReservationStatusCancelled:
+		return ErrReservationCancelled
+	default:
+		return ErrReservationConfirmed
+	}
+}
+
+// IsExpired 判断预占是否已过期
+func (r *Reservation) IsExpired(now time.Time) bool {
+	return r.Status == ReservationStatusReserved && !now.Before(r.ExpiresAt)
+}
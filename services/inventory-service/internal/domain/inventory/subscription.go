@@ -0,0 +1,71 @@
+package inventory
+
+import (
+	"context"
+	"time"
+)
+
+// SubscriptionStatus 到货提醒订阅状态
+type SubscriptionStatus string
+
+const (
+	SubscriptionStatusPending  SubscriptionStatus = "PENDING"  // 等待到货
+	SubscriptionStatusNotified SubscriptionStatus = "NOTIFIED" // 已通知（再次缺货后需重新订阅）
+)
+
+// StockSubscription 到货提醒订阅
+//
+// 教学要点：
+// 1. (user_id, book_id)唯一：重复订阅只是把状态重置为等待到货，不会重复通知
+// 2. 通知一次即结束：通知后状态改为NOTIFIED，再次缺货时用户自行决定是否重新订阅
+// 3. 按(book_id, status)索引：到货时只扫描该图书等待中的订阅，按ID分批读取
+type StockSubscription struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	UserID uint `gorm:"uniqueIndex:uk_user_book;not null" json:"user_id"`
+
+	BookID uint `gorm:"uniqueIndex:uk_user_book;index:idx_book_status;not null" json:"book_id"`
+
+	Status SubscriptionStatus `gorm:"type:varchar(20);not null;index:idx_book_status" json:"status"`
+
+	// 最近一次通知时间
+	NotifiedAt *time.Time `json:"notified_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (StockSubscription) TableName() string {
+	return "stock_subscriptions"
+}
+
+// BackInStockNotice 一批到货提醒（同一本书的多个订阅用户）
+type BackInStockNotice struct {
+	BookID  uint
+	Stock   int // 到货后的可用库存
+	UserIDs []uint
+}
+
+// Notifier 到货提醒发送接口
+//
+// 教学要点：领域层只定义"通知一批用户"，具体渠道（日志、文件、短信、站内信）由基础设施层实现，
+// 本地开发用日志/文件实现即可看到通知内容
+type Notifier interface {
+	NotifyBackInStock(ctx context.Context, notice *BackInStockNotice) error
+}
+
+// SubscriptionRepository 到货提醒订阅仓储接口
+type SubscriptionRepository interface {
+	// Subscribe 订阅（已存在时重置为等待到货）
+	Subscribe(ctx context.Context, userID, bookID uint) error
+
+	// Unsubscribe 取消订阅（不存在时返回ErrSubscriptionNotFound）
+	Unsubscribe(ctx context.Context, userID, bookID uint) error
+
+	// ListPending 按ID升序查询图书等待到货的订阅（游标为上一批最后的订阅ID）
+	ListPending(ctx context.Context, bookID uint, afterID uint, limit int) ([]*StockSubscription, error)
+
+	// MarkNotified 标记为已通知（只更新仍在等待到货的订阅）
+	MarkNotified(ctx context.Context, ids []uint, notifiedAt time.Time) error
+}
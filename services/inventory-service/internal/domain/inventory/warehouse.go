@@ -0,0 +1,83 @@
+package inventory
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UnassignedWarehouseID 未分配仓库（分仓上线前的存量库存，可调拨到具体仓库）
+//
+// 同时用作库存日志的仓库ID：WarehouseID为0的日志记录可售库存（全部仓库合计）的变更
+const UnassignedWarehouseID uint = 0
+
+// WarehouseStock 仓库库存（领域模型）
+//
+// 教学要点：
+// 1. 两层库存
+//   - inventory.stock：可售库存（全部仓库合计），下单检查、对账都以它为准
+//   - warehouse_stocks.stock：各仓库的可用库存，决定从哪个仓库发货
+//
+// 2. 可售库存 - 各仓库合计 = 未分配库存
+//   - 分仓上线前的库存没有仓库归属，扣减时最后兜底使用
+//   - 通过调拨（从仓库0调出）分配到具体仓库
+//
+// 3. 锁定库存不按仓库拆分：预占时已从仓库可用库存中扣出，确认后不再变化
+type WarehouseStock struct {
+	BookID      uint      `gorm:"primaryKey;column:book_id" json:"book_id"`
+	WarehouseID uint      `gorm:"primaryKey;column:warehouse_id" json:"warehouse_id"`
+	Stock       int       `gorm:"not null;default:0" json:"stock"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (WarehouseStock) TableName() string {
+	return "warehouse_stocks"
+}
+
+// WarehouseAllocation 仓库分配明细（一次扣减/预占从某个仓库扣了多少）
+//
+// Quantity在仓库库存变更时为有符号数：负数为减少，正数为增加
+type WarehouseAllocation struct {
+	WarehouseID uint
+	Quantity    int
+}
+
+// ParseAllocation 解析Lua脚本记录的分配串（"仓库ID:数量,仓库ID:数量"）
+//
+// 空串表示全部来自未分配库存；旧版扣减记录的值为"1"，同样视为无仓库分配
+func ParseAllocation(s string) ([]WarehouseAllocation, error) {
+	if s == "" || s == "1" {
+		return nil, nil
+	}
+
+	parts := strings.Split(s, ",")
+	allocs := make([]WarehouseAllocation, 0, len(parts))
+	for _, part := range parts {
+		wh, qty, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("无效的仓库分配: %q", s)
+		}
+		warehouseID, err := strconv.ParseUint(wh, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("无效的仓库分配: %q", s)
+		}
+		quantity, err := strconv.Atoi(qty)
+		if err != nil {
+			return nil, fmt.Errorf("无效的仓库分配: %q", s)
+		}
+		allocs = append(allocs, WarehouseAllocation{WarehouseID: uint(warehouseID), Quantity: quantity})
+	}
+	return allocs, nil
+}
+
+// NegateAllocations 分配明细取反（扣减/预占时仓库库存减少）
+func NegateAllocations(allocs []WarehouseAllocation) []WarehouseAllocation {
+	negated := make([]WarehouseAllocation, len(allocs))
+	for i, a := range allocs {
+		negated[i] = WarehouseAllocation{WarehouseID: a.WarehouseID, Quantity: -a.Quantity}
+	}
+	return negated
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	inventoryv1 "github.com/xiebiao/bookstore/proto/inventoryv1"
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/infrastructure/persistence/redis"
+)
+
+// AdjustStock 库存调整（报损、丢失、盘点差异、退货入库）
+//
+// 教学要点：
+// 1. 调整数量为有符号数，原因码记为日志的ChangeType，审计时可按原因统计
+// 2. 调整只作用于可用库存：锁定库存属于待支付订单，报损不能扣到它
+// 3. 与补货一致：Redis脚本校验并调整，异步落库（可售库存一条日志 + 各仓库一条日志）
+//
+// 返回码：
+// 0: 成功
+// 40001: 参数错误
+// 40100: 可用库存不足
+// 40103: 指定仓库库存不足
+func (s *InventoryServiceServer) AdjustStock(ctx context.Context, req *inventoryv1.AdjustStockRequest) (*inventoryv1.AdjustStockResponse, error) {
+	bookID := uint(req.BookId)
+	quantity := int(req.Quantity)
+
+	if bookID == 0 {
+		return &inventoryv1.AdjustStockResponse{Code: 40001, Message: "图书ID不能为空"}, nil
+	}
+	reason, err := inventory.ParseAdjustReason(req.Reason)
+	if err != nil {
+		return &inventoryv1.AdjustStockResponse{Code: 40001, Message: err.Error()}, nil
+	}
+	if err := inventory.ValidateAdjustment(reason, quantity); err != nil {
+		return &inventoryv1.AdjustStockResponse{Code: 40001, Message: err.Error()}, nil
+	}
+	warehouseID, message := s.adjustWarehouse(uint(req.WarehouseId), quantity > 0)
+	if message != "" {
+		return &inventoryv1.AdjustStockResponse{Code: 40001, Message: message}, nil
+	}
+
+	result, err := s.redisStore.AdjustStock(ctx, bookID, quantity, warehouseID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "调整库存失败: %v", err)
+	}
+
+	switch result.Code {
+	case redis.AdjustInsufficient:
+		return &inventoryv1.AdjustStockResponse{Code: 40100, Message: "可用库存不足", BeforeStock: int32(result.Before), CurrentStock: int32(result.Before)}, nil
+
+	case redis.AdjustInsufficientWarehouse:
+		return &inventoryv1.AdjustStockResponse{Code: 40103, Message: "仓库库存不足", BeforeStock: int32(result.Before), CurrentStock: int32(result.Before)}, nil
+
+	case redis.AdjustApplied:
+		s.syncAdjustment(bookID, result, reason, req.Remark)
+		return &inventoryv1.AdjustStockResponse{
+			Code:         0,
+			Message:      "调整成功",
+			BeforeStock:  int32(result.Before),
+			CurrentStock: int32(result.After),
+		}, nil
+
+	default:
+		return nil, status.Errorf(codes.Internal, "未知的调整结果: %d", result.Code)
+	}
+}
+
+// ImportStocktake 盘点导入
+//
+// 教学要点：
+// 1. 盘点数的是仓库里的实物：已锁定未发货的库存也在架上，账面数量 = 可用 + 锁定
+// 2. 差异在Lua脚本内计算并调整：导入期间仍在下单，读取账面与调整之间不能插入扣减
+// 3. 逐本独立调整，一本失败（如差异大于可用库存）不影响其他图书，失败明细返回给调用方人工处理
+//
+// 返回码：
+// 0: 成功（逐本结果见results）
+// 40001: 参数错误
+func (s *InventoryServiceServer) ImportStocktake(ctx context.Context, req *inventoryv1.ImportStocktakeRequest) (*inventoryv1.ImportStocktakeResponse, error) {
+	if message := validateStocktakeItems(req.Items); message != "" {
+		return &inventoryv1.ImportStocktakeResponse{Code: 40001, Message: message}, nil
+	}
+
+	// 差异方向在脚本内才确定：未指定仓库时盘亏按兜底顺序扣减，盘盈计入默认仓库
+	warehouseID, message := s.adjustWarehouse(uint(req.WarehouseId), false)
+	if message != "" {
+		return &inventoryv1.ImportStocktakeResponse{Code: 40001, Message: message}, nil
+	}
+	inbound, _ := s.adjustWarehouse(uint(req.WarehouseId), true)
+
+	resp := &inventoryv1.ImportStocktakeResponse{
+		Code:    0,
+		Message: "盘点导入完成",
+		Results: make([]*inventoryv1.StocktakeResult, 0, len(req.Items)),
+	}
+	for _, item := range req.Items {
+		bookID := uint(item.BookId)
+		counted := int(item.CountedQuantity)
+
+		result, err := s.redisStore.ApplyStocktake(ctx, bookID, counted, warehouseID, inbound)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "盘点调整失败 (book_id=%d): %v", bookID, err)
+		}
+
+		row := &inventoryv1.StocktakeResult{
+			BookId:          item.BookId,
+			CountedQuantity: item.CountedQuantity,
+			Difference:      int32(result.Delta),
+			BeforeStock:     int32(result.Before),
+			CurrentStock:    int32(result.After),
+		}
+		switch result.Code {
+		case redis.AdjustNoDifference:
+			row.Message = "无差异"
+
+		case redis.AdjustApplied:
+			row.Message = "已调整"
+			resp.Adjusted++
+			s.syncAdjustment(bookID, result, inventory.ChangeTypeStocktake, req.Remark)
+
+		case redis.AdjustInsufficient:
+			row.Code, row.Message = 40100, "盘亏数量大于可用库存（存在锁定库存），需人工处理"
+			row.CurrentStock = int32(result.Before)
+			resp.Failed++
+
+		case redis.AdjustInsufficientWarehouse:
+			row.Code, row.Message = 40103, "仓库库存不足"
+			row.CurrentStock = int32(result.Before)
+			resp.Failed++
+
+		default:
+			return nil, status.Errorf(codes.Internal, "未知的盘点调整结果: %d", result.Code)
+		}
+		resp.Results = append(resp.Results, row)
+	}
+
+	return resp, nil
+}
+
+// adjustWarehouse 确定调整计入的仓库
+//
+// 增加时未指定仓库计入默认仓库（与补货一致）；减少时未指定仓库返回0，由脚本按兜底顺序扣减
+func (s *InventoryServiceServer) adjustWarehouse(warehouseID uint, increase bool) (uint, string) {
+	if warehouseID == 0 {
+		if increase {
+			return s.cfg.DefaultWarehouseID(), ""
+		}
+		return 0, ""
+	}
+	if _, ok := s.cfg.WarehouseName(warehouseID); !ok {
+		return 0, "仓库不存在"
+	}
+	return warehouseID, ""
+}
+
+// syncAdjustment 调整成功后异步落库并发布库存变更，库存减少时检查低库存告警
+func (s *InventoryServiceServer) syncAdjustment(bookID uint, result *redis.AdjustResult, reason inventory.ChangeType, remark string) {
+	go func() {
+		if err := s.repo.AdjustStock(context.Background(), bookID, result.Delta, reason, remark); err != nil {
+			if !errors.Is(err, inventory.ErrInventoryNotFound) {
+				log.Printf("⚠️ 同步库存调整到MySQL失败 (book_id=%d, type=%s): %v", bookID, reason, err)
+			}
+		}
+		s.syncWarehouseChange(bookID, result.Changes, reason, 0, remark)
+	}()
+
+	if result.Delta < 0 {
+		go s.checkStockAlert(bookID, result.Before, result.After, 0)
+	}
+	s.notifyStockChange(bookID)
+}
+
+// validateStocktakeItems 校验盘点明细（数量上限、图书ID、实盘数量、重复图书）
+func validateStocktakeItems(items []*inventoryv1.StocktakeItem) string {
+	if len(items) == 0 {
+		return "盘点明细不能为空"
+	}
+	if len(items) > maxBatchItems {
+		return "盘点明细过多"
+	}
+	seen := make(map[uint64]bool, len(items))
+	for _, item := range items {
+		if item.BookId == 0 {
+			return "图书ID不能为空"
+		}
+		if item.CountedQuantity < 0 {
+			return "实盘数量不能为负数"
+		}
+		if seen[item.BookId] {
+			return "盘点明细中图书重复"
+		}
+		seen[item.BookId] = true
+	}
+	return ""
+}
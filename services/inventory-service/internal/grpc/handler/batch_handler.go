@@ -0,0 +1,306 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	inventoryv1 "github.com/xiebiao/bookstore/proto/inventoryv1"
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+)
+
+// maxBatchItems 单次批量操作的图书数量上限（Lua脚本执行期间Redis不处理其他命令）
+const maxBatchItems = 100
+
+// BatchDeductStock 批量扣减库存（一个订单的多本图书）
+//
+// 教学要点：
+// 1. 逐本调用DeductStock无法保证原子性：第3本不足时前2本已扣减，需要调用方补偿
+// 2. 单个Lua脚本先检查全部再扣减：要么全部成功，要么一本都不扣
+// 3. 与DeductStock共用扣减记录：重试时已扣减的图书跳过，单本ReleaseStock也能释放
+// 4. 异步同步MySQL时只同步本次新扣减的图书，避免重复记账
+//
+// 返回码：
+// 0: 成功（含重复扣减）
+// 40001: 参数错误
+// 40100: 库存不足（insufficient_book_id为不足的图书）
+func (s *InventoryServiceServer) BatchDeductStock(ctx context.Context, req *inventoryv1.BatchDeductStockRequest) (*inventoryv1.BatchDeductStockResponse, error) {
+	orderID := uint(req.OrderId)
+
+	items, message := toStockItems(req.Items)
+	if message == "" && orderID == 0 {
+		message = "订单ID不能为空"
+	}
+	if message != "" {
+		return &inventoryv1.BatchDeductStockResponse{Code: 40001, Message: message}, nil
+	}
+
+	result, err := s.redisStore.BatchDeductStock(ctx, items, orderID, uint(req.PreferredWarehouseId))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "批量扣减库存失败: %v", err)
+	}
+
+	if result.Insufficient >= 0 {
+		if result.Insufficient >= len(items) {
+			return nil, status.Errorf(codes.Internal, "未知的库存不足下标: %d", result.Insufficient)
+		}
+		return &inventoryv1.BatchDeductStockResponse{
+			Code:               40100,
+			Message:            "库存不足",
+			InsufficientBookId: uint64(items[result.Insufficient].BookID),
+		}, nil
+	}
+
+	deducted := make([]inventory.StockItem, 0, len(items))
+	deductedIDs := make([]uint, 0, len(items))
+	for i, item := range items {
+		if result.Deducted[i] {
+			deducted = append(deducted, item)
+			deductedIDs = append(deductedIDs, item.BookID)
+		}
+	}
+
+	message = "扣减成功"
+	if len(deducted) == 0 {
+		message = "订单已处理（幂等性）"
+	} else {
+		allocs, err := s.redisStore.DeductAllocations(ctx, orderID, deductedIDs...)
+		if err != nil {
+			log.Printf("⚠️ 查询仓库分配失败 (order_id=%d): %v", orderID, err)
+		}
+		go func() {
+			if err := s.repo.BatchDeductStock(context.Background(), deducted, orderID); err != nil {
+				log.Printf("⚠️ 同步批量扣减到MySQL失败 (order_id=%d): %v", orderID, err)
+			}
+			for _, item := range deducted {
+				s.syncWarehouseChange(item.BookID, inventory.NegateAllocations(allocs[item.BookID]), inventory.ChangeTypeDeduct, orderID, "")
+			}
+		}()
+		s.notifyStockChange(deductedIDs...)
+	}
+
+	stocks, err := s.stockInfos(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+
+	// 本次新扣减的图书检查低库存/缺货告警（stocks与items顺序一致）
+	for i, item := range items {
+		if result.Deducted[i] {
+			after := int(stocks[i].Stock)
+			go s.checkStockAlert(item.BookID, after+item.Quantity, after, orderID)
+		}
+	}
+
+	return &inventoryv1.BatchDeductStockResponse{
+		Code:    0,
+		Message: message,
+		Stocks:  stocks,
+	}, nil
+}
+
+// BatchReleaseStock 批量释放库存（BatchDeductStock的补偿操作）
+//
+// 未扣减或已释放的图书跳过，因此补偿时可以对订单全部明细调用，无需关心扣减进行到哪一步
+//
+// 返回码：
+// 0: 成功
+// 40001: 参数错误
+func (s *InventoryServiceServer) BatchReleaseStock(ctx context.Context, req *inventoryv1.BatchReleaseStockRequest) (*inventoryv1.BatchReleaseStockResponse, error) {
+	orderID := uint(req.OrderId)
+
+	items, message := toStockItems(req.Items)
+	if message == "" && orderID == 0 {
+		message = "订单ID不能为空"
+	}
+	if message != "" {
+		return &inventoryv1.BatchReleaseStockResponse{Code: 40001, Message: message}, nil
+	}
+
+	results, err := s.redisStore.BatchReleaseStock(ctx, items, orderID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "批量释放库存失败: %v", err)
+	}
+
+	released := make([]inventory.StockItem, 0, len(items))
+	releasedIDs := make([]uint, 0, len(items))
+	for i, item := range items {
+		if results[i] == 1 {
+			released = append(released, item)
+			releasedIDs = append(releasedIDs, item.BookID)
+		}
+	}
+
+	if len(released) > 0 {
+		reason := req.Reason
+		allocs, err := s.redisStore.ReleaseAllocations(ctx, orderID, releasedIDs...)
+		if err != nil {
+			log.Printf("⚠️ 查询仓库分配失败 (order_id=%d): %v", orderID, err)
+		}
+		go func() {
+			if err := s.repo.BatchReleaseStock(context.Background(), released, orderID, reason); err != nil {
+				log.Printf("⚠️ 同步批量释放到MySQL失败 (order_id=%d): %v", orderID, err)
+			}
+			for _, item := range released {
+				s.syncWarehouseChange(item.BookID, allocs[item.BookID], inventory.ChangeTypeRelease, orderID, reason)
+			}
+		}()
+		s.notifyStockChange(releasedIDs...)
+	}
+
+	stocks, err := s.stockInfos(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	return &inventoryv1.BatchReleaseStockResponse{
+		Code:    0,
+		Message: "释放成功",
+		Stocks:  stocks,
+	}, nil
+}
+
+// BatchReserveStock 批量预占库存（一个订单的多本图书）
+//
+// 教学要点：
+// 1. 与BatchDeductStock相同：单个Lua脚本先检查全部再预占，要么全部预占，要么一本都不占
+// 2. 与ReserveStock共用预占记录：重试时已预占的图书跳过，确认/取消/过期仍按单本处理
+// 3. 异步同步MySQL时只同步本次新预占的图书（同一事务）
+//
+// 返回码：
+// 0: 成功（含重复预占）
+// 40001: 参数错误
+// 40100: 库存不足（insufficient_book_id为不足的图书）
+func (s *InventoryServiceServer) BatchReserveStock(ctx context.Context, req *inventoryv1.BatchReserveStockRequest) (*inventoryv1.BatchReserveStockResponse, error) {
+	orderID := uint(req.OrderId)
+
+	items, message := toStockItems(req.Items)
+	if message == "" && orderID == 0 {
+		message = "订单ID不能为空"
+	}
+	if message == "" && req.TtlSeconds < 0 {
+		message = "预占有效期不能为负数"
+	}
+	if message != "" {
+		return &inventoryv1.BatchReserveStockResponse{Code: 40001, Message: message}, nil
+	}
+
+	ttl := s.cfg.GetReservationTTL()
+	if req.TtlSeconds > 0 {
+		ttl = time.Duration(req.TtlSeconds) * time.Second
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	result, err := s.redisStore.BatchReserveStock(ctx, items, orderID, expiresAt, uint(req.PreferredWarehouseId))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "批量预占库存失败: %v", err)
+	}
+
+	if result.Insufficient >= 0 {
+		if result.Insufficient >= len(items) {
+			return nil, status.Errorf(codes.Internal, "未知的库存不足下标: %d", result.Insufficient)
+		}
+		return &inventoryv1.BatchReserveStockResponse{
+			Code:               40100,
+			Message:            "库存不足",
+			InsufficientBookId: uint64(items[result.Insufficient].BookID),
+		}, nil
+	}
+
+	reserved := make([]inventory.StockItem, 0, len(items))
+	reservedIDs := make([]uint, 0, len(items))
+	for i, item := range items {
+		if result.Reserved[i] {
+			reserved = append(reserved, item)
+			reservedIDs = append(reservedIDs, item.BookID)
+		}
+	}
+
+	message = "预占成功"
+	var respExpiresAt int64
+	if len(reserved) == 0 {
+		message = "订单已预占（幂等性）"
+	} else {
+		respExpiresAt = expiresAt.Unix()
+		allocs := make(map[uint][]inventory.WarehouseAllocation, len(reserved))
+		for _, bookID := range reservedIDs {
+			a, err := s.redisStore.ReservationAllocation(ctx, bookID, orderID)
+			if err != nil {
+				log.Printf("⚠️ 查询仓库分配失败 (book_id=%d, order_id=%d): %v", bookID, orderID, err)
+			}
+			allocs[bookID] = a
+		}
+		go func() {
+			if err := s.repo.BatchReserveStock(context.Background(), reserved, orderID, expiresAt); err != nil {
+				log.Printf("⚠️ 同步批量预占到MySQL失败 (order_id=%d): %v", orderID, err)
+			}
+			for _, item := range reserved {
+				s.syncWarehouseChange(item.BookID, inventory.NegateAllocations(allocs[item.BookID]), inventory.ChangeTypeLock, orderID, "")
+			}
+		}()
+		s.notifyStockChange(reservedIDs...)
+	}
+
+	stocks, err := s.stockInfos(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+
+	// 预占同样减少可用库存，本次新预占的图书检查告警（stocks与items顺序一致）
+	for i, item := range items {
+		if result.Reserved[i] {
+			after := int(stocks[i].Stock)
+			go s.checkStockAlert(item.BookID, after+item.Quantity, after, orderID)
+		}
+	}
+
+	return &inventoryv1.BatchReserveStockResponse{
+		Code:      0,
+		Message:   message,
+		Stocks:    stocks,
+		ExpiresAt: respExpiresAt,
+	}, nil
+}
+
+// toStockItems 校验并转换批量请求的明细，参数错误时返回错误信息
+func toStockItems(pbItems []*inventoryv1.StockItem) ([]inventory.StockItem, string) {
+	if len(pbItems) == 0 {
+		return nil, "图书明细不能为空"
+	}
+	if len(pbItems) > maxBatchItems {
+		return nil, "图书明细数量超过上限"
+	}
+
+	items := make([]inventory.StockItem, 0, len(pbItems))
+	for _, item := range pbItems {
+		if item.BookId == 0 {
+			return nil, "图书ID不能为空"
+		}
+		if item.Quantity <= 0 {
+			return nil, "数量必须大于0"
+		}
+		items = append(items, inventory.StockItem{BookID: uint(item.BookId), Quantity: int(item.Quantity)})
+	}
+	return inventory.MergeStockItems(items), ""
+}
+
+// stockInfos 查询明细中各图书的当前库存（按明细顺序返回）
+func (s *InventoryServiceServer) stockInfos(ctx context.Context, items []inventory.StockItem) ([]*inventoryv1.StockInfo, error) {
+	bookIDs := make([]uint, len(items))
+	for i, item := range items {
+		bookIDs[i] = item.BookID
+	}
+
+	stockMap, err := s.redisStore.BatchGetStock(ctx, bookIDs)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "查询库存失败: %v", err)
+	}
+
+	stocks := make([]*inventoryv1.StockInfo, len(bookIDs))
+	for i, bookID := range bookIDs {
+		stocks[i] = &inventoryv1.StockInfo{BookId: uint64(bookID), Stock: int32(stockMap[bookID])}
+	}
+	return stocks, nil
+}
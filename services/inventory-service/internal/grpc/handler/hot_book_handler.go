@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"context"
+)
+
+// RebalanceHotBooks 按配置对热点图书的库存分片并重新均分，返回处理的图书数
+//
+// 教学要点：
+// 1. 分片只改变Redis中的存放方式，可售库存合计不变，MySQL和库存日志不受影响
+// 2. ShardStock既应用配置（拆分/改分片数/合并）又做再平衡，定时执行即可
+// 3. 尚未预热到Redis的图书跳过，预热后（GetStock从MySQL回填）下一轮再分片
+func (s *InventoryServiceServer) RebalanceHotBooks(ctx context.Context) (int, error) {
+	rebalanced := 0
+	for _, hb := range s.cfg.HotBooks {
+		_, exists, err := s.redisStore.ShardStock(ctx, hb.BookID, hb.Shards)
+		if err != nil {
+			return rebalanced, err
+		}
+		if exists {
+			rebalanced++
+		}
+	}
+	return rebalanced, nil
+}
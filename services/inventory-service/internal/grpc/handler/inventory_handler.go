@@ -3,12 +3,14 @@ package handler
 import (
 	"context"
 	"errors"
+	"log"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	inventoryv1 "github.com/xiebiao/bookstore/proto/inventoryv1"
 	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/infrastructure/config"
 	"github.com/xiebiao/bookstore/services/inventory-service/internal/infrastructure/persistence/redis"
 )
 
@@ -28,21 +30,35 @@ import (
 //   - 对账机制（MySQL为准）
 type InventoryServiceServer struct {
 	inventoryv1.UnimplementedInventoryServiceServer
-	repo       inventory.Repository    // MySQL仓储
-	logRepo    inventory.LogRepository // 日志仓储
-	redisStore *redis.InventoryStore   // Redis存储
+	repo       inventory.Repository             // MySQL仓储
+	logRepo    inventory.LogRepository          // 日志仓储
+	subRepo    inventory.SubscriptionRepository // 到货提醒订阅仓储
+	redisStore *redis.InventoryStore            // Redis存储
+	cfg        *config.InventoryConfig          // 库存业务配置
+	publisher  EventPublisher                   // 库存告警事件发布
+	notifier   inventory.Notifier               // 到货提醒发送
+	watchHub   *stockWatchHub                   // 本副本的WatchStock连接
 }
 
 // NewInventoryServiceServer 创建gRPC服务实例
 func NewInventoryServiceServer(
 	repo inventory.Repository,
 	logRepo inventory.LogRepository,
+	subRepo inventory.SubscriptionRepository,
 	redisStore *redis.InventoryStore,
+	cfg *config.InventoryConfig,
+	publisher EventPublisher,
+	notifier inventory.Notifier,
 ) *InventoryServiceServer {
 	return &InventoryServiceServer{
 		repo:       repo,
 		logRepo:    logRepo,
+		subRepo:    subRepo,
 		redisStore: redisStore,
+		cfg:        cfg,
+		publisher:  publisher,
+		notifier:   notifier,
+		watchHub:   newStockWatchHub(),
 	}
 }
 
@@ -52,17 +68,24 @@ func NewInventoryServiceServer(
 // 1. 先查Redis（快速）
 // 2. Redis未命中，查MySQL
 // 3. 将MySQL数据写入Redis（预热）
+//
+// Stock为全部仓库合计的可售库存，Warehouses为各仓库明细
 func (s *InventoryServiceServer) GetStock(ctx context.Context, req *inventoryv1.GetStockRequest) (*inventoryv1.GetStockResponse, error) {
 	bookID := uint(req.BookId)
 
 	// 步骤1：先查Redis
 	stock, err := s.redisStore.GetStock(ctx, bookID)
 	if err == nil {
+		warehouses, err := s.warehouseStocks(ctx, bookID, stock)
+		if err != nil {
+			return nil, err
+		}
 		return &inventoryv1.GetStockResponse{
-			Code:    0,
-			Message: "success",
-			BookId:  req.BookId,
-			Stock:   int32(stock),
+			Code:       0,
+			Message:    "success",
+			BookId:     req.BookId,
+			Stock:      int32(stock),
+			Warehouses: warehouses,
 		}, nil
 	}
 
@@ -80,20 +103,31 @@ func (s *InventoryServiceServer) GetStock(ctx context.Context, req *inventoryv1.
 		return nil, status.Errorf(codes.Internal, "查询库存失败: %v", err)
 	}
 
+	rows, err := s.repo.ListWarehouseStocks(ctx, bookID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "查询仓库库存失败: %v", err)
+	}
+	warehouseStocks := make(map[uint]int, len(rows))
+	for _, ws := range rows {
+		warehouseStocks[ws.WarehouseID] = ws.Stock
+	}
+
 	// 步骤3：将MySQL数据写入Redis（预热）
 	go func() {
 		_ = s.redisStore.SetStock(context.Background(), bookID, inv.Stock)
+		_ = s.redisStore.SetWarehouseStocks(context.Background(), bookID, warehouseStocks)
 	}()
 
 	return &inventoryv1.GetStockResponse{
-		Code:    0,
-		Message: "success",
-		BookId:  req.BookId,
-		Stock:   int32(inv.Stock),
+		Code:       0,
+		Message:    "success",
+		BookId:     req.BookId,
+		Stock:      int32(inv.Stock),
+		Warehouses: s.toWarehouseStocks(warehouseStocks, inv.Stock),
 	}, nil
 }
 
-// BatchGetStock 批量查询库存
+// BatchGetStock 批量查询库存（全部仓库合计，不返回仓库明细）
 func (s *InventoryServiceServer) BatchGetStock(ctx context.Context, req *inventoryv1.BatchGetStockRequest) (*inventoryv1.BatchGetStockResponse, error) {
 	if len(req.BookIds) == 0 {
 		return &inventoryv1.BatchGetStockResponse{
@@ -137,6 +171,7 @@ func (s *InventoryServiceServer) BatchGetStock(ctx context.Context, req *invento
 // 1. 写操作优先Redis（高性能）
 // 2. Lua脚本保证原子性和幂等性
 // 3. 异步同步到MySQL（持久化）
+// 4. 仓库分配：优先仓库 → 按仓库优先级兜底 → 未分配库存（Lua脚本内完成）
 //
 // 返回码：
 // 0: 成功
@@ -156,7 +191,7 @@ func (s *InventoryServiceServer) DeductStock(ctx context.Context, req *inventory
 	}
 
 	// 步骤1：Redis扣减（Lua脚本）
-	code, err := s.redisStore.DeductStock(ctx, bookID, quantity, orderID)
+	code, err := s.redisStore.DeductStock(ctx, bookID, quantity, orderID, uint(req.PreferredWarehouseId))
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "扣减库存失败: %v", err)
 	}
@@ -172,16 +207,23 @@ func (s *InventoryServiceServer) DeductStock(ctx context.Context, req *inventory
 
 	case 1:
 		// 扣减成功
-		// 步骤3：异步同步到MySQL
+		// 步骤3：异步同步到MySQL（可售库存 + 仓库分配）
+		allocs, err := s.redisStore.DeductAllocations(ctx, orderID, bookID)
+		if err != nil {
+			log.Printf("⚠️ 查询仓库分配失败 (book_id=%d, order_id=%d): %v", bookID, orderID, err)
+		}
 		go func() {
 			if err := s.repo.DeductStock(context.Background(), bookID, quantity, orderID); err != nil {
 				// 同步失败记录日志（生产环境应接入告警）
 				// logger.Error("sync to mysql failed", zap.Error(err))
 			}
+			s.syncWarehouseChange(bookID, inventory.NegateAllocations(allocs[bookID]), inventory.ChangeTypeDeduct, orderID, "")
 		}()
 
-		// 查询剩余库存
+		// 查询剩余库存，检查是否需要低库存/缺货告警
 		remainingStock, _ := s.redisStore.GetStock(ctx, bookID)
+		go s.checkStockAlert(bookID, remainingStock+quantity, remainingStock, orderID)
+		s.notifyStockChange(bookID)
 
 		return &inventoryv1.DeductStockResponse{
 			Code:           0,
@@ -227,12 +269,18 @@ func (s *InventoryServiceServer) ReleaseStock(ctx context.Context, req *inventor
 
 	case 1:
 		// 释放成功
-		// 步骤3：异步同步到MySQL
+		// 步骤3：异步同步到MySQL（可售库存 + 按扣减时的分配退回各仓库）
+		allocs, err := s.redisStore.ReleaseAllocations(ctx, orderID, bookID)
+		if err != nil {
+			log.Printf("⚠️ 查询仓库分配失败 (book_id=%d, order_id=%d): %v", bookID, orderID, err)
+		}
 		go func() {
 			if err := s.repo.ReleaseStock(context.Background(), bookID, quantity, orderID, reason); err != nil {
 				// logger.Error("sync release to mysql failed", zap.Error(err))
 			}
+			s.syncWarehouseChange(bookID, allocs[bookID], inventory.ChangeTypeRelease, orderID, reason)
 		}()
+		s.notifyStockChange(bookID)
 
 		currentStock, _ := s.redisStore.GetStock(ctx, bookID)
 
@@ -257,6 +305,9 @@ func (s *InventoryServiceServer) ReleaseStock(ctx context.Context, req *inventor
 }
 
 // RestockInventory 补充库存
+//
+// 补货入库到指定仓库（未指定时为默认仓库，即配置中的第一个仓库）
+// 库存从缺货变为有货时发布到货事件，由消费者通知订阅了到货提醒的用户
 func (s *InventoryServiceServer) RestockInventory(ctx context.Context, req *inventoryv1.RestockInventoryRequest) (*inventoryv1.RestockInventoryResponse, error) {
 	bookID := uint(req.BookId)
 	quantity := int(req.Quantity)
@@ -268,8 +319,18 @@ func (s *InventoryServiceServer) RestockInventory(ctx context.Context, req *inve
 		}, nil
 	}
 
+	warehouseID := uint(req.WarehouseId)
+	if warehouseID == 0 {
+		warehouseID = s.cfg.DefaultWarehouseID()
+	} else if _, ok := s.cfg.WarehouseName(warehouseID); !ok {
+		return &inventoryv1.RestockInventoryResponse{
+			Code:    40001,
+			Message: "仓库不存在",
+		}, nil
+	}
+
 	// Redis补货
-	newStock, err := s.redisStore.RestockInventory(ctx, bookID, quantity)
+	newStock, err := s.redisStore.RestockInventory(ctx, bookID, quantity, warehouseID)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "补充库存失败: %v", err)
 	}
@@ -279,7 +340,10 @@ func (s *InventoryServiceServer) RestockInventory(ctx context.Context, req *inve
 		if err := s.repo.RestockInventory(context.Background(), bookID, quantity); err != nil {
 			// logger.Error("sync restock to mysql failed", zap.Error(err))
 		}
+		s.syncWarehouseChange(bookID, []inventory.WarehouseAllocation{{WarehouseID: warehouseID, Quantity: quantity}}, inventory.ChangeTypeRestock, 0, "")
 	}()
+	s.notifyStockChange(bookID)
+	s.checkBackInStock(bookID, newStock-quantity, newStock)
 
 	return &inventoryv1.RestockInventoryResponse{
 		Code:         0,
@@ -289,13 +353,37 @@ func (s *InventoryServiceServer) RestockInventory(ctx context.Context, req *inve
 }
 
 // GetInventoryLogs 获取库存变更日志
+//
+// 两种查询方式：
+// 1. 按图书分页查询（book_id + page/page_size）
+// 2. 按订单查询（order_id）：对账时核对订单的扣减/释放流水，同时指定book_id时只返回该图书
 func (s *InventoryServiceServer) GetInventoryLogs(ctx context.Context, req *inventoryv1.GetInventoryLogsRequest) (*inventoryv1.GetInventoryLogsResponse, error) {
 	bookID := uint(req.BookId)
 	page := int(req.Page)
 	pageSize := int(req.PageSize)
 
+	if bookID == 0 && req.OrderId == 0 {
+		return &inventoryv1.GetInventoryLogsResponse{
+			Code:    40001,
+			Message: "图书ID和订单ID不能同时为空",
+		}, nil
+	}
+
 	// 查询日志
-	logs, total, err := s.logRepo.ListByBookID(ctx, bookID, page, pageSize)
+	var (
+		logs  []*inventory.InventoryLog
+		total int64
+		err   error
+	)
+	if req.OrderId != 0 {
+		logs, err = s.logRepo.ListByOrderID(ctx, uint(req.OrderId))
+		if err == nil && bookID != 0 {
+			logs = filterLogsByBookID(logs, bookID)
+		}
+		total = int64(len(logs))
+	} else {
+		logs, total, err = s.logRepo.ListByBookID(ctx, bookID, page, pageSize)
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "查询库存日志失败: %v", err)
 	}
@@ -312,6 +400,7 @@ func (s *InventoryServiceServer) GetInventoryLogs(ctx context.Context, req *inve
 			AfterStock:  int32(log.AfterStock),
 			OrderId:     uint64(log.OrderID),
 			CreatedAt:   log.CreatedAt.Unix(),
+			WarehouseId: uint64(log.WarehouseID),
 		}
 	}
 
@@ -322,3 +411,14 @@ func (s *InventoryServiceServer) GetInventoryLogs(ctx context.Context, req *inve
 		Total:   uint32(total),
 	}, nil
 }
+
+// filterLogsByBookID 过滤出指定图书的日志
+func filterLogsByBookID(logs []*inventory.InventoryLog, bookID uint) []*inventory.InventoryLog {
+	filtered := make([]*inventory.InventoryLog, 0, len(logs))
+	for _, l := range logs {
+		if l.BookID == bookID {
+			filtered = append(filtered, l)
+		}
+	}
+	return filtered
+}
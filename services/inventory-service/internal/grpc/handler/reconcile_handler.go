@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	inventoryv1 "github.com/xiebiao/bookstore/proto/inventoryv1"
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/infrastructure/persistence/redis"
+)
+
+// reconcileBatchSize 对账每批处理的图书数
+const reconcileBatchSize = 200
+
+// ReconcileResult 一次对账的结果
+type ReconcileResult struct {
+	Checked  int                     // 检查的图书数
+	Repaired int                     // 修复的图书数
+	Drifts   []*inventory.StockDrift // 复核后仍不一致的图书（含账面不一致）
+}
+
+// ReconcileStock 库存对账（管理接口）
+//
+// 返回码：
+// 0: 成功（drifts为空表示Redis与MySQL一致）
+// 40001: 修复策略无效
+func (s *InventoryServiceServer) ReconcileStock(ctx context.Context, req *inventoryv1.ReconcileStockRequest) (*inventoryv1.ReconcileStockResponse, error) {
+	policy, err := inventory.ParseRepairPolicy(req.Policy)
+	if err != nil {
+		return &inventoryv1.ReconcileStockResponse{Code: 40001, Message: err.Error()}, nil
+	}
+
+	bookIDs := make([]uint, len(req.BookIds))
+	for i, id := range req.BookIds {
+		bookIDs[i] = uint(id)
+	}
+
+	result, err := s.Reconcile(ctx, bookIDs, policy)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "库存对账失败: %v", err)
+	}
+
+	drifts := make([]*inventoryv1.StockDrift, 0, len(result.Drifts))
+	for _, d := range result.Drifts {
+		drifts = append(drifts, &inventoryv1.StockDrift{
+			BookId:       uint64(d.BookID),
+			RedisStock:   int32(d.RedisStock),
+			RedisLocked:  int32(d.RedisLocked),
+			RedisMissing: d.RedisMissing,
+			MysqlStock:   int32(d.MySQLStock),
+			MysqlLocked:  int32(d.MySQLLocked),
+			MysqlMissing: d.MySQLMissing,
+			LedgerStock:  int32(d.LedgerStock),
+			HasLedger:    d.HasLedger,
+			Action:       d.Action,
+		})
+	}
+
+	return &inventoryv1.ReconcileStockResponse{
+		Code:     0,
+		Message:  "success",
+		Checked:  uint32(result.Checked),
+		Repaired: uint32(result.Repaired),
+		Drifts:   drifts,
+	}, nil
+}
+
+// Reconcile 对账Redis与MySQL库存，并按策略修复
+//
+// 教学要点：
+// 1. 三方比对：Redis（实时库存）、MySQL（持久化库存）、inventory_logs（账面库存）
+// 2. 两次读取：发现不一致后等待复核，排除"Redis已扣减、MySQL异步落库中"的短暂不一致
+// 3. 比较并修复：修复前确认库存仍是复核时的值，期间有新交易则放弃（下一轮再对账）
+// 4. bookIDs为空时按图书ID游标遍历全表
+func (s *InventoryServiceServer) Reconcile(ctx context.Context, bookIDs []uint, policy inventory.RepairPolicy) (*ReconcileResult, error) {
+	result := &ReconcileResult{}
+
+	if len(bookIDs) > 0 {
+		for start := 0; start < len(bookIDs); start += reconcileBatchSize {
+			end := min(start+reconcileBatchSize, len(bookIDs))
+			if err := s.reconcileBatch(ctx, bookIDs[start:end], policy, result); err != nil {
+				return result, err
+			}
+		}
+		return result, nil
+	}
+
+	var after uint
+	for {
+		invs, err := s.repo.ListAfter(ctx, after, reconcileBatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(invs) == 0 {
+			return result, nil
+		}
+
+		ids := make([]uint, len(invs))
+		for i, inv := range invs {
+			ids[i] = inv.BookID
+		}
+		if err := s.reconcileBatch(ctx, ids, policy, result); err != nil {
+			return result, err
+		}
+		after = ids[len(ids)-1]
+	}
+}
+
+// reconcileBatch 对账一批图书
+func (s *InventoryServiceServer) reconcileBatch(ctx context.Context, bookIDs []uint, policy inventory.RepairPolicy, result *ReconcileResult) error {
+	drifts, err := s.compareStock(ctx, bookIDs)
+	if err != nil {
+		return err
+	}
+	result.Checked += len(bookIDs)
+	if len(drifts) == 0 {
+		return nil
+	}
+
+	// 等待异步落库完成后复核
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(s.cfg.GetReconcileSettle()):
+	}
+
+	suspects := make([]uint, len(drifts))
+	for i, d := range drifts {
+		suspects[i] = d.BookID
+	}
+	if drifts, err = s.compareStock(ctx, suspects); err != nil {
+		return err
+	}
+
+	for _, d := range drifts {
+		if err := s.repairDrift(ctx, d, policy); err != nil {
+			return err
+		}
+		if d.Action == inventory.ReconcileActionRedisRepaired || d.Action == inventory.ReconcileActionMySQLRepaired {
+			result.Repaired++
+		}
+		log.Printf("⚖️ 库存不一致 (book_id=%d): redis=%d/%d(missing=%v) mysql=%d/%d(missing=%v) ledger=%d(has=%v) action=%q",
+			d.BookID, d.RedisStock, d.RedisLocked, d.RedisMissing, d.MySQLStock, d.MySQLLocked, d.MySQLMissing,
+			d.LedgerStock, d.HasLedger, d.Action)
+		result.Drifts = append(result.Drifts, d)
+	}
+	return nil
+}
+
+// compareStock 读取三方库存，返回不一致（含账面不一致）的图书
+func (s *InventoryServiceServer) compareStock(ctx context.Context, bookIDs []uint) ([]*inventory.StockDrift, error) {
+	snapshots, err := s.redisStore.BatchGetSnapshot(ctx, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+	invs, err := s.repo.BatchGetByBookIDs(ctx, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+	ledger, err := s.logRepo.LatestByBookIDs(ctx, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []*inventory.StockDrift
+	for _, bookID := range bookIDs {
+		snap := snapshots[bookID]
+		d := &inventory.StockDrift{
+			BookID:       bookID,
+			RedisStock:   snap.Stock,
+			RedisLocked:  snap.Locked,
+			RedisMissing: !snap.Exists,
+		}
+		if inv, ok := invs[bookID]; ok {
+			d.MySQLStock, d.MySQLLocked = inv.Stock, inv.LockedStock
+		} else {
+			d.MySQLMissing = true
+		}
+		if l, ok := ledger[bookID]; ok {
+			d.LedgerStock, d.HasLedger = l.AfterStock, true
+		}
+
+		if d.HasDrift() || d.LedgerMismatch() {
+			drifts = append(drifts, d)
+		}
+	}
+	return drifts, nil
+}
+
+// repairDrift 按策略修复一本图书，结果写入d.Action
+func (s *InventoryServiceServer) repairDrift(ctx context.Context, d *inventory.StockDrift, policy inventory.RepairPolicy) error {
+	d.Action = d.RepairDirection(policy)
+
+	switch d.Action {
+	case inventory.ReconcileActionRedisRepaired:
+		expect := redis.StockSnapshot{Stock: d.RedisStock, Locked: d.RedisLocked, Exists: !d.RedisMissing}
+		ok, err := s.redisStore.CompareAndSetStock(ctx, d.BookID, expect, d.MySQLStock, d.MySQLLocked)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			d.Action = inventory.ReconcileActionChanged
+			break
+		}
+		s.notifyStockChange(d.BookID)
+		// Redis数据丢失时仓库库存一并丢失，同样从MySQL恢复
+		if d.RedisMissing {
+			if err := s.restoreWarehouseStocks(ctx, d.BookID); err != nil {
+				return err
+			}
+		}
+
+	case inventory.ReconcileActionMySQLRepaired:
+		err := s.repo.ApplyReconcile(ctx, d.BookID, d.MySQLStock, d.MySQLLocked, d.RedisStock, d.RedisLocked, string(policy))
+		switch {
+		case errors.Is(err, inventory.ErrStockChanged):
+			d.Action = inventory.ReconcileActionChanged
+		case err != nil:
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	inventoryv1 "github.com/xiebiao/bookstore/proto/inventoryv1"
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+)
+
+// ReserveStock 预占库存（两阶段扣减：下单锁定）
+//
+// 教学要点：
+// 1. 与DeductStock相同的"Redis先行 + 异步MySQL"策略
+// 2. 预占成功后库存处于锁定状态：不可再卖，但也未售出
+// 3. 支付成功 → ConfirmReservation；取消/超时 → CancelReservation 或过期任务自动取消
+//
+// 返回码：
+// 0: 成功（含重复预占）
+// 40001: 参数错误
+// 40100: 库存不足
+func (s *InventoryServiceServer) ReserveStock(ctx context.Context, req *inventoryv1.ReserveStockRequest) (*inventoryv1.ReserveStockResponse, error) {
+	bookID := uint(req.BookId)
+	quantity := int(req.Quantity)
+	orderID := uint(req.OrderId)
+
+	if quantity <= 0 {
+		return &inventoryv1.ReserveStockResponse{Code: 40001, Message: "预占数量必须大于0"}, nil
+	}
+	if orderID == 0 {
+		return &inventoryv1.ReserveStockResponse{Code: 40001, Message: "订单ID不能为空"}, nil
+	}
+	if req.TtlSeconds < 0 {
+		return &inventoryv1.ReserveStockResponse{Code: 40001, Message: "预占有效期不能为负数"}, nil
+	}
+
+	ttl := s.cfg.GetReservationTTL()
+	if req.TtlSeconds > 0 {
+		ttl = time.Duration(req.TtlSeconds) * time.Second
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	code, err := s.redisStore.ReserveStock(ctx, bookID, quantity, orderID, expiresAt, uint(req.PreferredWarehouseId))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "预占库存失败: %v", err)
+	}
+
+	switch code {
+	case 0:
+		return &inventoryv1.ReserveStockResponse{Code: 40100, Message: "库存不足"}, nil
+
+	case 1:
+		allocs, err := s.redisStore.ReservationAllocation(ctx, bookID, orderID)
+		if err != nil {
+			log.Printf("⚠️ 查询仓库分配失败 (book_id=%d, order_id=%d): %v", bookID, orderID, err)
+		}
+		go func() {
+			if err := s.repo.ReserveStock(context.Background(), bookID, quantity, orderID, expiresAt); err != nil {
+				log.Printf("⚠️ 同步预占到MySQL失败 (book_id=%d, order_id=%d): %v", bookID, orderID, err)
+			}
+			s.syncWarehouseChange(bookID, inventory.NegateAllocations(allocs), inventory.ChangeTypeLock, orderID, "")
+		}()
+
+		// 预占同样减少可用库存，需要检查告警
+		remainingStock, _ := s.redisStore.GetStock(ctx, bookID)
+		go s.checkStockAlert(bookID, remainingStock+quantity, remainingStock, orderID)
+		s.notifyStockChange(bookID)
+
+		return &inventoryv1.ReserveStockResponse{
+			Code:           0,
+			Message:        "预占成功",
+			RemainingStock: int32(remainingStock),
+			ExpiresAt:      expiresAt.Unix(),
+		}, nil
+
+	case 2:
+		// 重复预占：返回当前库存，过期时间以首次预占为准（此处不返回）
+		remainingStock, _ := s.redisStore.GetStock(ctx, bookID)
+		return &inventoryv1.ReserveStockResponse{
+			Code:           0,
+			Message:        "订单已预占（幂等性）",
+			RemainingStock: int32(remainingStock),
+		}, nil
+
+	default:
+		return nil, status.Errorf(codes.Internal, "未知的预占结果: %d", code)
+	}
+}
+
+// ConfirmReservation 确认预占（支付成功，锁定库存售出）
+//
+// 返回码：
+// 0: 成功（含重复确认）
+// 40401: 预占不存在
+// 40101: 预占已取消（超时或订单取消，库存已退回）
+func (s *InventoryServiceServer) ConfirmReservation(ctx context.Context, req *inventoryv1.ConfirmReservationRequest) (*inventoryv1.ConfirmReservationResponse, error) {
+	bookID := uint(req.BookId)
+	orderID := uint(req.OrderId)
+
+	code, err := s.redisStore.ConfirmReservation(ctx, bookID, orderID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "确认预占失败: %v", err)
+	}
+
+	switch code {
+	case 0:
+		return &inventoryv1.ConfirmReservationResponse{Code: 40401, Message: "预占记录不存在"}, nil
+
+	case 1:
+		go func() {
+			if err := s.repo.ConfirmReservation(context.Background(), bookID, orderID); err != nil {
+				log.Printf("⚠️ 同步确认预占到MySQL失败 (book_id=%d, order_id=%d): %v", bookID, orderID, err)
+			}
+		}()
+		return &inventoryv1.ConfirmReservationResponse{Code: 0, Message: "确认成功"}, nil
+
+	case 2:
+		return &inventoryv1.ConfirmReservationResponse{Code: 0, Message: "预占已确认（幂等性）"}, nil
+
+	case 3:
+		return &inventoryv1.ConfirmReservationResponse{Code: 40101, Message: "预占已取消"}, nil
+
+	default:
+		return nil, status.Errorf(codes.Internal, "未知的确认结果: %d", code)
+	}
+}
+
+// CancelReservation 取消预占（锁定库存退回可用库存）
+//
+// 返回码：
+// 0: 成功（含重复取消）
+// 40401: 预占不存在
+// 40102: 预占已确认（已售出，不能取消）
+func (s *InventoryServiceServer) CancelReservation(ctx context.Context, req *inventoryv1.CancelReservationRequest) (*inventoryv1.CancelReservationResponse, error) {
+	bookID := uint(req.BookId)
+	orderID := uint(req.OrderId)
+
+	code, err := s.cancelReservation(ctx, bookID, orderID, req.Reason)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "取消预占失败: %v", err)
+	}
+
+	switch code {
+	case 0:
+		return &inventoryv1.CancelReservationResponse{Code: 40401, Message: "预占记录不存在"}, nil
+
+	case 1, 2:
+		message := "取消成功"
+		if code == 2 {
+			message = "预占已取消（幂等性）"
+		}
+		currentStock, _ := s.redisStore.GetStock(ctx, bookID)
+		return &inventoryv1.CancelReservationResponse{
+			Code:         0,
+			Message:      message,
+			CurrentStock: int32(currentStock),
+		}, nil
+
+	case 3:
+		return &inventoryv1.CancelReservationResponse{Code: 40102, Message: "预占已确认，不能取消"}, nil
+
+	default:
+		return nil, status.Errorf(codes.Internal, "未知的取消结果: %d", code)
+	}
+}
+
+// cancelReservation 取消预占（Redis脚本 + 异步同步MySQL），供RPC和过期任务共用
+func (s *InventoryServiceServer) cancelReservation(ctx context.Context, bookID, orderID uint, reason string) (int, error) {
+	code, err := s.redisStore.CancelReservation(ctx, bookID, orderID)
+	if err != nil {
+		return 0, err
+	}
+
+	if code == 1 {
+		// 预占记录保留在Redis中（终态保留期内），可读取预占时的仓库分配
+		allocs, err := s.redisStore.ReservationAllocation(ctx, bookID, orderID)
+		if err != nil {
+			log.Printf("⚠️ 查询仓库分配失败 (book_id=%d, order_id=%d): %v", bookID, orderID, err)
+		}
+		go func() {
+			if err := s.repo.CancelReservation(context.Background(), bookID, orderID, reason); err != nil {
+				log.Printf("⚠️ 同步取消预占到MySQL失败 (book_id=%d, order_id=%d): %v", bookID, orderID, err)
+			}
+			s.syncWarehouseChange(bookID, allocs, inventory.ChangeTypeUnlock, orderID, reason)
+		}()
+		s.notifyStockChange(bookID)
+	}
+	return code, nil
+}
+
+// ExpireReservations 取消已过期的预占，返回取消数量
+//
+// 教学要点：
+// 1. 过期队列在Redis ZSET中（与预占原子写入），不依赖异步写入的MySQL记录
+// 2. 取消脚本会把记录移出ZSET：已确认/已取消的预占不会被重复处理
+// 3. 多实例同时扫描时，同一预占只有一个实例返回"取消成功"，其余为幂等
+func (s *InventoryServiceServer) ExpireReservations(ctx context.Context, limit int) (int, error) {
+	refs, err := s.redisStore.ListExpiredReservations(ctx, time.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, ref := range refs {
+		code, err := s.cancelReservation(ctx, ref.BookID, ref.OrderID, inventory.ReservationReasonExpired)
+		if err != nil {
+			return expired, err
+		}
+		switch code {
+		case 0:
+			// 预占记录已丢失（如Redis数据被清理），移出队列避免每轮重复扫描
+			if err := s.redisStore.RemoveReservationExpiry(ctx, ref); err != nil {
+				return expired, err
+			}
+		case 1:
+			expired++
+			log.Printf("⏰ 预占已过期，库存已退回 (book_id=%d, order_id=%d)", ref.BookID, ref.OrderID)
+		}
+	}
+	return expired, nil
+}
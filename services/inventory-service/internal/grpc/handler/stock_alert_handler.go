@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xiebiao/bookstore/pkg/events"
+	inventoryv1 "github.com/xiebiao/bookstore/proto/inventoryv1"
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+)
+
+// EventPublisher 库存事件发布接口（*mq.Publisher实现）
+type EventPublisher interface {
+	Publish(routingKey string, message interface{}) error
+}
+
+// stockAlertTimeout 检查告警时查询阈值的超时时间（在扣减请求之外异步执行）
+const stockAlertTimeout = 3 * time.Second
+
+// checkStockAlert 库存减少后检查是否跨过告警线，跨过则发布告警事件
+//
+// 教学要点：
+// 1. 在扣减/预占成功后异步调用：告警不影响下单链路的延迟，发布失败只记录日志
+// 2. 阈值存在MySQL（按图书设置，未设置用默认值），按主键查询代价很小
+// 3. 变更前库存按"变更后库存 + 本次数量"推算，并发扣减时可能漏报或重复告警
+// 4. 下游按告警补货需要容忍重复；ListLowStock可随时拉取完整的低库存清单
+func (s *InventoryServiceServer) checkStockAlert(bookID uint, beforeStock, afterStock int, orderID uint) {
+	ctx, cancel := context.WithTimeout(context.Background(), stockAlertTimeout)
+	defer cancel()
+
+	threshold := s.cfg.WarningThreshold
+	inv, err := s.repo.GetByBookID(ctx, bookID)
+	switch {
+	case err == nil:
+		threshold = inv.EffectiveThreshold(threshold)
+	case errors.Is(err, inventory.ErrInventoryNotFound):
+		// MySQL记录尚未落库，按默认阈值判断
+	default:
+		log.Printf("⚠️ 查询低库存阈值失败 (book_id=%d): %v", bookID, err)
+	}
+
+	now := time.Now().Unix()
+	switch inventory.DetectStockAlert(beforeStock, afterStock, threshold) {
+	case inventory.StockAlertLowStock:
+		s.publishStockEvent(events.InventoryLowStock, &events.InventoryLowStockEvent{
+			Type:        events.InventoryLowStock,
+			BookID:      uint64(bookID),
+			Stock:       afterStock,
+			BeforeStock: beforeStock,
+			Threshold:   threshold,
+			OrderID:     uint64(orderID),
+			OccurredAt:  now,
+		})
+	case inventory.StockAlertOutOfStock:
+		s.publishStockEvent(events.InventoryOutOfStock, &events.InventoryOutOfStockEvent{
+			Type:        events.InventoryOutOfStock,
+			BookID:      uint64(bookID),
+			BeforeStock: beforeStock,
+			OrderID:     uint64(orderID),
+			OccurredAt:  now,
+		})
+	}
+}
+
+// publishStockEvent 发布库存事件（失败只记录日志，不影响库存操作）
+func (s *InventoryServiceServer) publishStockEvent(routingKey string, event interface{}) {
+	if s.publisher == nil {
+		return
+	}
+	if err := s.publisher.Publish(routingKey, event); err != nil {
+		log.Printf("⚠️ 发布库存事件失败 (%s): %v", routingKey, err)
+		return
+	}
+	log.Printf("📣 已发布库存事件: %s", routingKey)
+}
+
+// SetLowStockThreshold 设置图书的低库存阈值
+//
+// 返回码：
+// 0: 成功
+// 40001: 参数错误
+// 40401: 库存记录不存在
+func (s *InventoryServiceServer) SetLowStockThreshold(ctx context.Context, req *inventoryv1.SetLowStockThresholdRequest) (*inventoryv1.SetLowStockThresholdResponse, error) {
+	if req.BookId == 0 {
+		return &inventoryv1.SetLowStockThresholdResponse{Code: 40001, Message: "图书ID不能为空"}, nil
+	}
+
+	var threshold *int
+	if !req.UseDefault {
+		if req.Threshold < 0 {
+			return &inventoryv1.SetLowStockThresholdResponse{Code: 40001, Message: "低库存阈值不能为负数"}, nil
+		}
+		t := int(req.Threshold)
+		threshold = &t
+	}
+
+	if err := s.repo.SetLowStockThreshold(ctx, uint(req.BookId), threshold); err != nil {
+		if errors.Is(err, inventory.ErrInventoryNotFound) {
+			return &inventoryv1.SetLowStockThresholdResponse{Code: 40401, Message: "库存记录不存在"}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "设置低库存阈值失败: %v", err)
+	}
+
+	return &inventoryv1.SetLowStockThresholdResponse{Code: 0, Message: "设置成功"}, nil
+}
+
+// ListLowStock 查询低库存图书（按可用库存升序）
+func (s *InventoryServiceServer) ListLowStock(ctx context.Context, req *inventoryv1.ListLowStockRequest) (*inventoryv1.ListLowStockResponse, error) {
+	defaultThreshold := s.cfg.WarningThreshold
+
+	invs, total, err := s.repo.ListLowStock(ctx, defaultThreshold, req.IncludeOutOfStock, int(req.Page), int(req.PageSize))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "查询低库存图书失败: %v", err)
+	}
+
+	items := make([]*inventoryv1.LowStockItem, len(invs))
+	for i, inv := range invs {
+		items[i] = &inventoryv1.LowStockItem{
+			BookId:           uint64(inv.BookID),
+			Stock:            int32(inv.Stock),
+			LockedStock:      int32(inv.LockedStock),
+			Threshold:        int32(inv.EffectiveThreshold(defaultThreshold)),
+			DefaultThreshold: inv.LowStockThreshold == nil,
+		}
+	}
+
+	return &inventoryv1.ListLowStockResponse{
+		Code:    0,
+		Message: "success",
+		Items:   items,
+		Total:   uint32(total),
+	}, nil
+}
@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	inventoryv1 "github.com/xiebiao/bookstore/proto/inventoryv1"
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/infrastructure/persistence/redis"
+)
+
+// stockNotifyTimeout 发布库存变更通知的超时时间（在库存操作之外异步执行）
+const stockNotifyTimeout = 3 * time.Second
+
+// WatchStock 订阅库存变更（服务端流）
+//
+// 教学要点：
+// 1. 推送代替轮询：库存变化时才推送，客户端不必定时调用GetStock/BatchGetStock
+// 2. 多副本扇出：变更通过Redis Pub/Sub广播，每个副本只向自己持有的连接推送
+// 3. 断线续传：客户端重连时带上收到的最大序号，只补发之后有变化的图书
+// 4. 慢消费者合并推送：同一本书积压的多次变更只推送最新一次，不会因为堆积拖垮服务端
+//
+// from_seq为0时先推送全部图书的当前库存（从未变更过的图书序号为0）
+func (s *InventoryServiceServer) WatchStock(req *inventoryv1.WatchStockRequest, stream inventoryv1.InventoryService_WatchStockServer) error {
+	bookIDs := uniqueBookIDs(req.BookIds)
+	if len(bookIDs) == 0 {
+		return status.Error(codes.InvalidArgument, "图书ID不能为空")
+	}
+	if len(bookIDs) > maxBatchItems {
+		return status.Error(codes.InvalidArgument, "图书数量过多")
+	}
+
+	ctx := stream.Context()
+
+	// 先登记再读取初始值：登记之后的变更都会进入待推送队列，不会漏掉
+	w := s.watchHub.watch(bookIDs)
+	defer s.watchHub.unwatch(w)
+
+	initial, err := s.initialStockChanges(ctx, bookIDs, req.FromSeq)
+	if err != nil {
+		return err
+	}
+	for _, c := range initial {
+		if err := stream.Send(toStockChange(c)); err != nil {
+			return err
+		}
+		w.markSent(c)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.watchHub.done:
+			// 服务关闭：结束流让GracefulStop返回，客户端带上序号重连到其他副本
+			return status.Error(codes.Unavailable, "服务正在关闭")
+		case <-w.ready:
+			for _, c := range w.drain() {
+				if err := stream.Send(toStockChange(c)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// RunStockWatch 订阅库存变更广播并分发给本副本的WatchStock连接（阻塞直到ctx取消）
+//
+// 返回时结束本副本的全部WatchStock连接
+func (s *InventoryServiceServer) RunStockWatch(ctx context.Context) error {
+	defer s.watchHub.close()
+
+	return s.redisStore.SubscribeStockChanges(ctx, s.watchHub.dispatch, func() {
+		// （重新）订阅成功：按最新值表补发断线期间可能丢失的变更，重复的由序号去重
+		bookIDs := s.watchHub.bookIDs()
+		if len(bookIDs) == 0 {
+			return
+		}
+		latest, err := s.redisStore.LatestStockChanges(ctx, bookIDs)
+		if err != nil {
+			log.Printf("⚠️ 补发库存变更失败: %v", err)
+			return
+		}
+		for _, c := range latest {
+			s.watchHub.dispatch(c)
+		}
+	})
+}
+
+// initialStockChanges 计算连接建立时需要推送的库存
+//
+// fromSeq > 0：只推送序号更大的变更（断线续传）
+// fromSeq = 0：推送全部图书的当前库存
+func (s *InventoryServiceServer) initialStockChanges(ctx context.Context, bookIDs []uint, fromSeq uint64) ([]redis.StockChange, error) {
+	latest, err := s.redisStore.LatestStockChanges(ctx, bookIDs)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "查询库存变更失败: %v", err)
+	}
+
+	changes := make([]redis.StockChange, 0, len(bookIDs))
+	if fromSeq > 0 {
+		for _, c := range latest {
+			if c.Seq > fromSeq {
+				changes = append(changes, c)
+			}
+		}
+	} else {
+		stocks, err := s.redisStore.BatchGetStock(ctx, bookIDs)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "查询库存失败: %v", err)
+		}
+		for _, bookID := range bookIDs {
+			c, ok := latest[bookID]
+			if !ok {
+				c = redis.StockChange{BookID: bookID, Stock: stocks[bookID]}
+			}
+			changes = append(changes, c)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Seq < changes[j].Seq })
+	return changes, nil
+}
+
+// notifyStockChange 库存变化后异步发布变更通知（失败只记录日志，不影响库存操作）
+func (s *InventoryServiceServer) notifyStockChange(bookIDs ...uint) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), stockNotifyTimeout)
+		defer cancel()
+
+		for _, bookID := range bookIDs {
+			if _, err := s.redisStore.NotifyStockChange(ctx, bookID); err != nil {
+				log.Printf("⚠️ 发布库存变更通知失败 (book_id=%d): %v", bookID, err)
+			}
+		}
+	}()
+}
+
+func toStockChange(c redis.StockChange) *inventoryv1.StockChange {
+	return &inventoryv1.StockChange{
+		Seq:    c.Seq,
+		BookId: uint64(c.BookID),
+		Stock:  int32(c.Stock),
+	}
+}
+
+// uniqueBookIDs 去掉0和重复的图书ID
+func uniqueBookIDs(ids []uint64) []uint {
+	seen := make(map[uint64]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, uint(id))
+	}
+	return result
+}
+
+// stockWatchHub 本副本的WatchStock连接登记表（图书ID → 订阅者）
+type stockWatchHub struct {
+	mu       sync.RWMutex
+	watchers map[uint]map[*stockWatcher]struct{}
+
+	done      chan struct{} // 关闭后全部连接退出
+	closeOnce sync.Once
+}
+
+func newStockWatchHub() *stockWatchHub {
+	return &stockWatchHub{
+		watchers: make(map[uint]map[*stockWatcher]struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (h *stockWatchHub) close() {
+	h.closeOnce.Do(func() { close(h.done) })
+}
+
+func (h *stockWatchHub) watch(bookIDs []uint) *stockWatcher {
+	w := &stockWatcher{
+		bookIDs: bookIDs,
+		pending: make(map[uint]redis.StockChange),
+		sent:    make(map[uint]uint64),
+		ready:   make(chan struct{}, 1),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, id := range bookIDs {
+		if h.watchers[id] == nil {
+			h.watchers[id] = make(map[*stockWatcher]struct{})
+		}
+		h.watchers[id][w] = struct{}{}
+	}
+	return w
+}
+
+func (h *stockWatchHub) unwatch(w *stockWatcher) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, id := range w.bookIDs {
+		delete(h.watchers[id], w)
+		if len(h.watchers[id]) == 0 {
+			delete(h.watchers, id)
+		}
+	}
+}
+
+// dispatch 把一条变更交给订阅了该图书的全部连接
+func (h *stockWatchHub) dispatch(c redis.StockChange) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for w := range h.watchers[c.BookID] {
+		w.offer(c)
+	}
+}
+
+// bookIDs 当前有连接订阅的图书
+func (h *stockWatchHub) bookIDs() []uint {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	ids := make([]uint, 0, len(h.watchers))
+	for id := range h.watchers {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// stockWatcher 一个WatchStock连接的待推送队列
+//
+// 每本书只保留序号最大的一条待推送变更；ready容量为1，只用来唤醒推送循环
+type stockWatcher struct {
+	bookIDs []uint
+
+	mu      sync.Mutex
+	pending map[uint]redis.StockChange
+	sent    map[uint]uint64 // 每本书已推送的最大序号
+	ready   chan struct{}
+}
+
+func (w *stockWatcher) offer(c redis.StockChange) {
+	w.mu.Lock()
+	if c.Seq <= w.sent[c.BookID] || c.Seq <= w.pending[c.BookID].Seq {
+		w.mu.Unlock()
+		return
+	}
+	w.pending[c.BookID] = c
+	w.mu.Unlock()
+
+	select {
+	case w.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (w *stockWatcher) markSent(c redis.StockChange) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if c.Seq > w.sent[c.BookID] {
+		w.sent[c.BookID] = c.Seq
+	}
+}
+
+// drain 取出待推送的变更（按序号升序，跳过已推送过的）
+func (w *stockWatcher) drain() []redis.StockChange {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	changes := make([]redis.StockChange, 0, len(w.pending))
+	for bookID, c := range w.pending {
+		if c.Seq > w.sent[bookID] {
+			changes = append(changes, c)
+			w.sent[bookID] = c.Seq
+		}
+		delete(w.pending, bookID)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Seq < changes[j].Seq })
+	return changes
+}
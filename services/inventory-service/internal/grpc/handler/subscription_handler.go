@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xiebiao/bookstore/pkg/events"
+	inventoryv1 "github.com/xiebiao/bookstore/proto/inventoryv1"
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+)
+
+// SubscribeBackInStock 订阅到货提醒
+//
+// 教学要点：
+// 1. 有货时也允许订阅：到货提醒只在"缺货 → 有货"时触发，订阅后等下一次到货
+// 2. 重复订阅幂等，已通知过的订阅重新进入等待状态
+//
+// 返回码：
+// 0: 成功
+// 40001: 参数错误
+// 40401: 库存记录不存在
+func (s *InventoryServiceServer) SubscribeBackInStock(ctx context.Context, req *inventoryv1.SubscribeBackInStockRequest) (*inventoryv1.SubscribeBackInStockResponse, error) {
+	userID, bookID := uint(req.UserId), uint(req.BookId)
+	if userID == 0 || bookID == 0 {
+		return &inventoryv1.SubscribeBackInStockResponse{Code: 40001, Message: "用户ID和图书ID不能为空"}, nil
+	}
+
+	inv, err := s.repo.GetByBookID(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, inventory.ErrInventoryNotFound) {
+			return &inventoryv1.SubscribeBackInStockResponse{Code: 40401, Message: "库存记录不存在"}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "查询库存失败: %v", err)
+	}
+
+	if err := s.subRepo.Subscribe(ctx, userID, bookID); err != nil {
+		return nil, status.Errorf(codes.Internal, "订阅到货提醒失败: %v", err)
+	}
+
+	// 当前库存以Redis为准，读取失败时退回MySQL中的值
+	stock, err := s.redisStore.GetStock(ctx, bookID)
+	if err != nil {
+		stock = inv.Stock
+	}
+
+	return &inventoryv1.SubscribeBackInStockResponse{
+		Code:         0,
+		Message:      "订阅成功",
+		CurrentStock: int32(stock),
+	}, nil
+}
+
+// UnsubscribeBackInStock 取消到货提醒
+//
+// 返回码：
+// 0: 成功
+// 40001: 参数错误
+// 40402: 未订阅
+func (s *InventoryServiceServer) UnsubscribeBackInStock(ctx context.Context, req *inventoryv1.UnsubscribeBackInStockRequest) (*inventoryv1.UnsubscribeBackInStockResponse, error) {
+	userID, bookID := uint(req.UserId), uint(req.BookId)
+	if userID == 0 || bookID == 0 {
+		return &inventoryv1.UnsubscribeBackInStockResponse{Code: 40001, Message: "用户ID和图书ID不能为空"}, nil
+	}
+
+	if err := s.subRepo.Unsubscribe(ctx, userID, bookID); err != nil {
+		if errors.Is(err, inventory.ErrSubscriptionNotFound) {
+			return &inventoryv1.UnsubscribeBackInStockResponse{Code: 40402, Message: "未订阅到货提醒"}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "取消到货提醒失败: %v", err)
+	}
+
+	return &inventoryv1.UnsubscribeBackInStockResponse{Code: 0, Message: "已取消"}, nil
+}
+
+// checkBackInStock 补货后检查是否从缺货变为有货，是则发布到货事件
+func (s *InventoryServiceServer) checkBackInStock(bookID uint, beforeStock, afterStock int) {
+	if !inventory.IsBackInStock(beforeStock, afterStock) {
+		return
+	}
+	s.publishStockEvent(events.InventoryBackInStock, &events.InventoryBackInStockEvent{
+		Type:        events.InventoryBackInStock,
+		BookID:      uint64(bookID),
+		Stock:       afterStock,
+		BeforeStock: beforeStock,
+		OccurredAt:  time.Now().Unix(),
+	})
+}
+
+// HandleBackInStock 消费到货事件，分批通知订阅用户
+//
+// 教学要点：
+// 1. 按订阅ID游标分批读取，每批通知后立即标记为已通知，订阅者再多内存也只占一批
+// 2. 幂等不依赖消息去重：重复投递或失败重试时，已通知的批次不再是等待状态，只会通知剩下的用户
+// 3. 通知发送成功、标记失败时这一批会在重试时重复通知（至少一次），通知渠道需能容忍
+func (s *InventoryServiceServer) HandleBackInStock(ctx context.Context, body []byte) error {
+	var event events.InventoryBackInStockEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Printf("⚠️ 到货事件格式错误，丢弃: %v", err)
+		return nil
+	}
+	if event.Type != events.InventoryBackInStock || event.BookID == 0 {
+		return nil
+	}
+
+	bookID := uint(event.BookID)
+	batchSize := s.cfg.GetBackInStockBatchSize()
+
+	var after uint
+	notified := 0
+	for {
+		subs, err := s.subRepo.ListPending(ctx, bookID, after, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(subs) == 0 {
+			break
+		}
+
+		ids := make([]uint, len(subs))
+		userIDs := make([]uint, len(subs))
+		for i, sub := range subs {
+			ids[i] = sub.ID
+			userIDs[i] = sub.UserID
+		}
+
+		notice := &inventory.BackInStockNotice{BookID: bookID, Stock: event.Stock, UserIDs: userIDs}
+		if err := s.notifier.NotifyBackInStock(ctx, notice); err != nil {
+			return fmt.Errorf("发送到货提醒失败 (book_id=%d): %w", bookID, err)
+		}
+		if err := s.subRepo.MarkNotified(ctx, ids, time.Now()); err != nil {
+			return err
+		}
+		notified += len(subs)
+
+		if len(subs) < batchSize {
+			break
+		}
+		after = ids[len(ids)-1]
+	}
+
+	if notified > 0 {
+		log.Printf("🔔 到货提醒已发送 (book_id=%d, 用户数=%d)", bookID, notified)
+	}
+	return nil
+}
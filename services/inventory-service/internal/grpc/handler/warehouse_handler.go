@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	inventoryv1 "github.com/xiebiao/bookstore/proto/inventoryv1"
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+)
+
+// TransferStock 仓库间调拨
+//
+// 教学要点：
+// 1. 调拨只在仓库之间移动库存，可售库存（全部仓库合计）不变，不影响下单
+// 2. 调出仓库为0表示从未分配库存调出：分仓上线后，把存量库存分配到具体仓库
+// 3. 与其他写操作一致：Redis脚本校验并调拨，异步落库（调出、调入各一条TRANSFER日志）
+//
+// 返回码：
+// 0: 成功
+// 40001: 参数错误
+// 40103: 调出仓库库存不足
+func (s *InventoryServiceServer) TransferStock(ctx context.Context, req *inventoryv1.TransferStockRequest) (*inventoryv1.TransferStockResponse, error) {
+	bookID := uint(req.BookId)
+	from := uint(req.FromWarehouseId)
+	to := uint(req.ToWarehouseId)
+	quantity := int(req.Quantity)
+
+	if bookID == 0 {
+		return &inventoryv1.TransferStockResponse{Code: 40001, Message: "图书ID不能为空"}, nil
+	}
+	if quantity <= 0 {
+		return &inventoryv1.TransferStockResponse{Code: 40001, Message: "调拨数量必须大于0"}, nil
+	}
+	if from == to {
+		return &inventoryv1.TransferStockResponse{Code: 40001, Message: "调出仓库与调入仓库相同"}, nil
+	}
+	if _, ok := s.cfg.WarehouseName(to); !ok {
+		return &inventoryv1.TransferStockResponse{Code: 40001, Message: "调入仓库不存在"}, nil
+	}
+	if _, ok := s.cfg.WarehouseName(from); !ok && from != inventory.UnassignedWarehouseID {
+		return &inventoryv1.TransferStockResponse{Code: 40001, Message: "调出仓库不存在"}, nil
+	}
+
+	code, err := s.redisStore.TransferStock(ctx, bookID, from, to, quantity)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "调拨库存失败: %v", err)
+	}
+
+	switch code {
+	case 0:
+		return &inventoryv1.TransferStockResponse{Code: 40103, Message: "调出仓库库存不足"}, nil
+
+	case 1:
+		changes := []inventory.WarehouseAllocation{
+			{WarehouseID: from, Quantity: -quantity},
+			{WarehouseID: to, Quantity: quantity},
+		}
+		remark := req.Remark
+		go s.syncWarehouseChange(bookID, changes, inventory.ChangeTypeTransfer, 0, remark)
+
+		total, _ := s.redisStore.GetStock(ctx, bookID)
+		warehouses, err := s.warehouseStocks(ctx, bookID, total)
+		if err != nil {
+			return nil, err
+		}
+		return &inventoryv1.TransferStockResponse{
+			Code:       0,
+			Message:    "调拨成功",
+			Warehouses: warehouses,
+		}, nil
+
+	default:
+		return nil, status.Errorf(codes.Internal, "未知的调拨结果: %d", code)
+	}
+}
+
+// warehouseStocks 查询图书的各仓库库存（按配置顺序，未配置的仓库按ID排在后面）
+//
+// 可售库存 - 各仓库合计 > 0 时，追加warehouse_id=0的未分配库存
+func (s *InventoryServiceServer) warehouseStocks(ctx context.Context, bookID uint, total int) ([]*inventoryv1.WarehouseStock, error) {
+	stocks, err := s.redisStore.GetWarehouseStocks(ctx, bookID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "查询仓库库存失败: %v", err)
+	}
+	return s.toWarehouseStocks(stocks, total), nil
+}
+
+func (s *InventoryServiceServer) toWarehouseStocks(stocks map[uint]int, total int) []*inventoryv1.WarehouseStock {
+	result := make([]*inventoryv1.WarehouseStock, 0, len(stocks)+1)
+	seen := make(map[uint]bool, len(stocks))
+	assigned := 0
+
+	for _, wh := range s.cfg.Warehouses {
+		seen[wh.ID] = true
+		assigned += stocks[wh.ID]
+		result = append(result, &inventoryv1.WarehouseStock{
+			WarehouseId: uint64(wh.ID),
+			Name:        wh.Name,
+			Stock:       int32(stocks[wh.ID]),
+		})
+	}
+
+	// 已下线（配置中移除）但仍有库存的仓库
+	var others []uint
+	for id := range stocks {
+		if !seen[id] {
+			others = append(others, id)
+		}
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i] < others[j] })
+	for _, id := range others {
+		assigned += stocks[id]
+		result = append(result, &inventoryv1.WarehouseStock{WarehouseId: uint64(id), Stock: int32(stocks[id])})
+	}
+
+	if unassigned := total - assigned; unassigned > 0 {
+		result = append(result, &inventoryv1.WarehouseStock{
+			WarehouseId: uint64(inventory.UnassignedWarehouseID),
+			Name:        "未分配",
+			Stock:       int32(unassigned),
+		})
+	}
+	return result
+}
+
+// syncWarehouseChange 异步同步仓库库存变更到MySQL（没有仓库分配时跳过）
+func (s *InventoryServiceServer) syncWarehouseChange(bookID uint, changes []inventory.WarehouseAllocation, changeType inventory.ChangeType, orderID uint, remark string) {
+	if len(changes) == 0 {
+		return
+	}
+	if err := s.repo.ChangeWarehouseStock(context.Background(), bookID, changes, changeType, orderID, remark); err != nil {
+		log.Printf("⚠️ 同步仓库库存到MySQL失败 (book_id=%d, order_id=%d, type=%s): %v", bookID, orderID, changeType, err)
+	}
+}
+
+// restoreWarehouseStocks 从MySQL恢复Redis中的仓库库存（Redis数据丢失后由对账调用）
+func (s *InventoryServiceServer) restoreWarehouseStocks(ctx context.Context, bookID uint) error {
+	rows, err := s.repo.ListWarehouseStocks(ctx, bookID)
+	if err != nil {
+		return err
+	}
+	stocks := make(map[uint]int, len(rows))
+	for _, ws := range rows {
+		stocks[ws.WarehouseID] = ws.Stock
+	}
+	return s.redisStore.SetWarehouseStocks(ctx, bookID, stocks)
+}
@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/spf13/viper"
+
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
 )
 
 // Config 应用配置
@@ -13,6 +15,8 @@ type Config struct {
 	Database  DatabaseConfig  `mapstructure:"database"`
 	Redis     RedisConfig     `mapstructure:"redis"`
 	Inventory InventoryConfig `mapstructure:"inventory"`
+	MQ        MQConfig        `mapstructure:"mq"`
+	Notify    NotifyConfig    `mapstructure:"notify"`
 	Log       LogConfig       `mapstructure:"log"`
 }
 
@@ -32,19 +36,77 @@ type DatabaseConfig struct {
 }
 
 type RedisConfig struct {
-	Addr         string `mapstructure:"addr"`
-	Password     string `mapstructure:"password"`
-	DB           int    `mapstructure:"db"`
-	PoolSize     int    `mapstructure:"pool_size"`
-	MinIdleConns int    `mapstructure:"min_idle_conns"`
+	Addr         string   `mapstructure:"addr"`
+	Addrs        []string `mapstructure:"addrs"` // Redis Cluster节点地址（配置后使用集群模式，忽略addr和db）
+	Password     string   `mapstructure:"password"`
+	DB           int      `mapstructure:"db"`
+	PoolSize     int      `mapstructure:"pool_size"`
+	MinIdleConns int      `mapstructure:"min_idle_conns"`
 }
 
 // InventoryConfig 库存配置
 // 教学要点：业务配置与技术配置分离
 type InventoryConfig struct {
 	EnableCache      bool `mapstructure:"enable_cache"`
-	WarningThreshold int  `mapstructure:"warning_threshold"`
+	WarningThreshold int  `mapstructure:"warning_threshold"` // 默认低库存阈值（图书未单独设置时使用）
 	SyncInterval     int  `mapstructure:"sync_interval"`
+
+	// 库存预占
+	ReservationTTL           int `mapstructure:"reservation_ttl"`            // 默认预占有效期（秒）
+	ReservationSweepInterval int `mapstructure:"reservation_sweep_interval"` // 过期预占扫描间隔（秒）
+
+	// 库存对账
+	ReconcileInterval int    `mapstructure:"reconcile_interval"`  // 定时对账间隔（秒），0为不启用
+	ReconcilePolicy   string `mapstructure:"reconcile_policy"`    // 定时对账的修复策略
+	ReconcileSettleMs int    `mapstructure:"reconcile_settle_ms"` // 发现不一致后等待复核的时间（毫秒）
+
+	// 多仓库（顺序即扣减时的兜底优先级，第一个为默认入库仓库）
+	Warehouses []WarehouseConfig `mapstructure:"warehouses"`
+
+	// 热点图书库存分片（未列出的图书不分片）
+	HotBooks               []HotBookConfig `mapstructure:"hot_books"`
+	ShardRebalanceInterval int             `mapstructure:"shard_rebalance_interval"` // 分片再平衡间隔（秒）
+
+	// 到货提醒
+	BackInStockBatchSize int `mapstructure:"back_in_stock_batch_size"` // 每批通知的用户数
+}
+
+// WarehouseConfig 仓库配置
+type WarehouseConfig struct {
+	ID   uint   `mapstructure:"id"`   // 仓库ID（>0，0保留给未分配库存）
+	Name string `mapstructure:"name"` // 仓库名称
+}
+
+// MaxHotBookShards 单本图书的最大分片数（查询合计和再平衡逐个分片读取，临近售罄时扣减也要逐个分片尝试）
+const MaxHotBookShards = 64
+
+// HotBookConfig 热点图书分片配置
+type HotBookConfig struct {
+	BookID uint `mapstructure:"book_id"`
+	Shards int  `mapstructure:"shards"` // 分片数（1表示合并回主库存，用于撤销分片）
+}
+
+// MQConfig 消息队列配置
+//
+// 教学要点：库存告警（inventory.low_stock / inventory.out_of_stock）发布到业务事件交换机，
+// 由采购、通知等下游服务按路由键订阅，inventory-service不关心谁在消费
+type MQConfig struct {
+	URL              string `mapstructure:"url"`                 // RabbitMQ连接URL
+	BackInStockQueue string `mapstructure:"back_in_stock_queue"` // 到货事件队列（到货提醒）
+}
+
+// GetBackInStockQueue 到货事件队列名（未配置时inventory.back-in-stock）
+func (c *MQConfig) GetBackInStockQueue() string {
+	if c.BackInStockQueue == "" {
+		return "inventory.back-in-stock"
+	}
+	return c.BackInStockQueue
+}
+
+// NotifyConfig 到货提醒发送渠道配置
+type NotifyConfig struct {
+	Driver   string `mapstructure:"driver"`    // 发送渠道：log（默认）、file
+	FilePath string `mapstructure:"file_path"` // file渠道的输出文件
 }
 
 type LogConfig struct {
@@ -85,10 +147,47 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("数据库DSN不能为空")
 	}
 
-	if c.Redis.Addr == "" {
+	if c.Redis.Addr == "" && len(c.Redis.Addrs) == 0 {
 		return fmt.Errorf("Redis地址不能为空")
 	}
 
+	if c.Inventory.WarningThreshold < 0 {
+		return fmt.Errorf("低库存阈值不能为负数: %d", c.Inventory.WarningThreshold)
+	}
+
+	seen := make(map[uint]bool, len(c.Inventory.Warehouses))
+	for _, wh := range c.Inventory.Warehouses {
+		if wh.ID == 0 {
+			return fmt.Errorf("仓库ID不能为0（0保留给未分配库存）")
+		}
+		if seen[wh.ID] {
+			return fmt.Errorf("仓库ID重复: %d", wh.ID)
+		}
+		seen[wh.ID] = true
+	}
+
+	seenBooks := make(map[uint]bool, len(c.Inventory.HotBooks))
+	for _, hb := range c.Inventory.HotBooks {
+		if hb.BookID == 0 {
+			return fmt.Errorf("热点图书ID不能为0")
+		}
+		if hb.Shards < 1 || hb.Shards > MaxHotBookShards {
+			return fmt.Errorf("热点图书分片数须在1~%d之间: book_id=%d shards=%d", MaxHotBookShards, hb.BookID, hb.Shards)
+		}
+		if seenBooks[hb.BookID] {
+			return fmt.Errorf("热点图书重复: %d", hb.BookID)
+		}
+		seenBooks[hb.BookID] = true
+	}
+
+	if c.MQ.URL == "" {
+		return fmt.Errorf("mq.url 不能为空")
+	}
+
+	if _, err := inventory.ParseRepairPolicy(c.Inventory.ReconcilePolicy); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -99,3 +198,72 @@ func (c *DatabaseConfig) GetConnMaxLifetime() time.Duration {
 func (c *InventoryConfig) GetSyncInterval() time.Duration {
 	return time.Duration(c.SyncInterval) * time.Second
 }
+
+// GetReservationTTL 默认预占有效期（未配置时20分钟，需大于订单支付超时）
+func (c *InventoryConfig) GetReservationTTL() time.Duration {
+	if c.ReservationTTL <= 0 {
+		return 20 * time.Minute
+	}
+	return time.Duration(c.ReservationTTL) * time.Second
+}
+
+// GetReservationSweepInterval 过期预占扫描间隔（未配置时10秒）
+func (c *InventoryConfig) GetReservationSweepInterval() time.Duration {
+	if c.ReservationSweepInterval <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.ReservationSweepInterval) * time.Second
+}
+
+// GetReconcileSettle 复核等待时间（未配置时2秒）
+//
+// Redis先写、MySQL异步落库，刚发生的扣减会短暂"不一致"，等待后复核仍不一致才报告
+func (c *InventoryConfig) GetReconcileSettle() time.Duration {
+	if c.ReconcileSettleMs <= 0 {
+		return 2 * time.Second
+	}
+	return time.Duration(c.ReconcileSettleMs) * time.Millisecond
+}
+
+// GetShardRebalanceInterval 分片再平衡间隔（未配置时1分钟）
+func (c *InventoryConfig) GetShardRebalanceInterval() time.Duration {
+	if c.ShardRebalanceInterval <= 0 {
+		return time.Minute
+	}
+	return time.Duration(c.ShardRebalanceInterval) * time.Second
+}
+
+// GetBackInStockBatchSize 到货提醒每批通知的用户数（未配置时100）
+func (c *InventoryConfig) GetBackInStockBatchSize() int {
+	if c.BackInStockBatchSize <= 0 {
+		return 100
+	}
+	return c.BackInStockBatchSize
+}
+
+// WarehouseIDs 按优先级排列的仓库ID
+func (c *InventoryConfig) WarehouseIDs() []uint {
+	ids := make([]uint, len(c.Warehouses))
+	for i, wh := range c.Warehouses {
+		ids[i] = wh.ID
+	}
+	return ids
+}
+
+// DefaultWarehouseID 默认入库仓库（未配置仓库时为0，即不区分仓库）
+func (c *InventoryConfig) DefaultWarehouseID() uint {
+	if len(c.Warehouses) == 0 {
+		return 0
+	}
+	return c.Warehouses[0].ID
+}
+
+// WarehouseName 仓库名称（未配置的仓库返回空串）
+func (c *InventoryConfig) WarehouseName(id uint) (string, bool) {
+	for _, wh := range c.Warehouses {
+		if wh.ID == id {
+			return wh.Name, true
+		}
+	}
+	return "", false
+}
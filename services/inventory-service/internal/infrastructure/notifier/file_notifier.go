@@ -0,0 +1,74 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+)
+
+// fileNotice 文件中的一行（一个用户一条通知）
+type fileNotice struct {
+	Type       string `json:"type"`
+	UserID     uint   `json:"user_id"`
+	BookID     uint   `json:"book_id"`
+	Stock      int    `json:"stock"`
+	NotifiedAt int64  `json:"notified_at"`
+}
+
+// fileNotifier 文件通知器（JSON Lines，便于本地查看和脚本断言）
+type fileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileNotifier 创建文件通知器（启动时检查文件可写）
+func NewFileNotifier(path string) (inventory.Notifier, error) {
+	if path == "" {
+		return nil, fmt.Errorf("文件通知器需要配置文件路径")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("打开通知文件失败: %w", err)
+	}
+	f.Close()
+	return &fileNotifier{path: path}, nil
+}
+
+// NotifyBackInStock 把一批到货提醒追加到文件（一批一次写入）
+func (n *fileNotifier) NotifyBackInStock(ctx context.Context, notice *inventory.BackInStockNotice) error {
+	now := time.Now().Unix()
+	var buf []byte
+	for _, userID := range notice.UserIDs {
+		line, err := json.Marshal(fileNotice{
+			Type:       "back_in_stock",
+			UserID:     userID,
+			BookID:     notice.BookID,
+			Stock:      notice.Stock,
+			NotifiedAt: now,
+		})
+		if err != nil {
+			return fmt.Errorf("序列化到货提醒失败: %w", err)
+		}
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("打开通知文件失败: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(buf); err != nil {
+		return fmt.Errorf("写入通知文件失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package notifier
+
+import (
+	"context"
+	"log"
+
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+)
+
+// logNotifier 日志通知器（每个用户打印一行）
+type logNotifier struct{}
+
+// NewLogNotifier 创建日志通知器
+func NewLogNotifier() inventory.Notifier {
+	return &logNotifier{}
+}
+
+// NotifyBackInStock 打印到货提醒
+func (n *logNotifier) NotifyBackInStock(ctx context.Context, notice *inventory.BackInStockNotice) error {
+	for _, userID := range notice.UserIDs {
+		log.Printf("🔔 到货提醒 user_id=%d book_id=%d stock=%d", userID, notice.BookID, notice.Stock)
+	}
+	return nil
+}
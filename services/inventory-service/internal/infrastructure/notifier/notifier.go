@@ -0,0 +1,30 @@
+// Package notifier 到货提醒的发送渠道实现
+//
+// 教学要点：
+// 1. 领域层只依赖inventory.Notifier接口，渠道按配置选择，业务代码不变
+// 2. log/file用于本地开发和演示；接入短信、站内信时新增一个实现即可
+package notifier
+
+import (
+	"fmt"
+
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+)
+
+// 发送渠道
+const (
+	DriverLog  = "log"  // 打印到服务日志
+	DriverFile = "file" // 追加写入JSON Lines文件
+)
+
+// New 按渠道创建通知器（driver为空时使用log）
+func New(driver, filePath string) (inventory.Notifier, error) {
+	switch driver {
+	case "", DriverLog:
+		return NewLogNotifier(), nil
+	case DriverFile:
+		return NewFileNotifier(filePath)
+	default:
+		return nil, fmt.Errorf("未知的通知渠道: %s", driver)
+	}
+}
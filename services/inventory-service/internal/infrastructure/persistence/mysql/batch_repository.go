@@ -0,0 +1,163 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+)
+
+// BatchDeductStock 批量扣减库存（同一事务）
+//
+// 教学要点：
+// 1. 先按book_id升序逐行加锁并检查，全部充足才开始扣减：任一不足整个事务回滚
+// 2. 每本图书一条DEDUCT日志，与单本扣减的日志格式一致
+// 3. 已扣减（未释放）的图书跳过：与Redis脚本的幂等语义一致
+func (r *inventoryRepository) BatchDeductStock(ctx context.Context, items []inventory.StockItem, orderID uint) error {
+	items = inventory.MergeStockItems(items)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		type pending struct {
+			inv      *inventory.Inventory
+			quantity int
+		}
+		toDeduct := make([]pending, 0, len(items))
+
+		for _, item := range items {
+			inv, err := lockInventory(tx, item.BookID)
+			if err != nil {
+				return err
+			}
+
+			if orderID != 0 {
+				deducted, err := isOrderDeducted(tx, item.BookID, orderID)
+				if err != nil {
+					return err
+				}
+				if deducted {
+					continue
+				}
+			}
+
+			if !inv.CanDeduct(item.Quantity) {
+				return fmt.Errorf("图书[%d]: %w", item.BookID, inventory.ErrInsufficientStock)
+			}
+			toDeduct = append(toDeduct, pending{inv: inv, quantity: item.Quantity})
+		}
+
+		for _, p := range toDeduct {
+			beforeStock := p.inv.Stock
+			p.inv.Stock -= p.quantity
+			p.inv.TotalStock = p.inv.Stock + p.inv.LockedStock
+			if err := tx.Save(p.inv).Error; err != nil {
+				return fmt.Errorf("扣减库存失败: %w", err)
+			}
+
+			log := inventory.NewDeductLog(p.inv.BookID, p.quantity, beforeStock, p.inv.Stock, orderID)
+			if err := tx.Create(log).Error; err != nil {
+				return fmt.Errorf("创建库存日志失败: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// BatchReleaseStock 批量释放库存（同一事务）
+func (r *inventoryRepository) BatchReleaseStock(ctx context.Context, items []inventory.StockItem, orderID uint, reason string) error {
+	items = inventory.MergeStockItems(items)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			inv, err := lockInventory(tx, item.BookID)
+			if err != nil {
+				return err
+			}
+
+			if orderID != 0 {
+				deducted, err := isOrderDeducted(tx, item.BookID, orderID)
+				if err != nil {
+					return err
+				}
+				if !deducted {
+					continue
+				}
+			}
+
+			beforeStock := inv.Stock
+			inv.Stock += item.Quantity
+			inv.TotalStock = inv.Stock + inv.LockedStock
+			if err := tx.Save(inv).Error; err != nil {
+				return fmt.Errorf("释放库存失败: %w", err)
+			}
+
+			log := inventory.NewReleaseLog(item.BookID, item.Quantity, beforeStock, inv.Stock, orderID, reason)
+			if err := tx.Create(log).Error; err != nil {
+				return fmt.Errorf("创建库存日志失败: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// BatchReserveStock 批量预占库存（同一事务）
+//
+// 教学要点：
+// 1. 与BatchDeductStock相同：先按book_id升序逐行加锁并检查，全部可锁定才开始预占
+// 2. 每本图书一条预占记录和一条LOCK日志，确认/取消时仍按单本处理
+// 3. 已有预占记录的图书跳过：与Redis脚本的幂等语义一致
+func (r *inventoryRepository) BatchReserveStock(ctx context.Context, items []inventory.StockItem, orderID uint, expiresAt time.Time) error {
+	items = inventory.MergeStockItems(items)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		type pending struct {
+			inv      *inventory.Inventory
+			quantity int
+		}
+		toReserve := make([]pending, 0, len(items))
+
+		for _, item := range items {
+			inv, err := lockInventory(tx, item.BookID)
+			if err != nil {
+				return err
+			}
+
+			var existing int64
+			if err := tx.Model(&inventory.Reservation{}).
+				Where("order_id = ? AND book_id = ?", orderID, item.BookID).
+				Count(&existing).Error; err != nil {
+				return fmt.Errorf("查询预占记录失败: %w", err)
+			}
+			if existing > 0 {
+				continue
+			}
+
+			if !inv.CanLock(item.Quantity) {
+				return fmt.Errorf("图书[%d]: %w", item.BookID, inventory.ErrInsufficientStock)
+			}
+			toReserve = append(toReserve, pending{inv: inv, quantity: item.Quantity})
+		}
+
+		for _, p := range toReserve {
+			beforeStock := p.inv.Stock
+			if err := p.inv.Lock(p.quantity); err != nil {
+				return err
+			}
+			if err := tx.Save(p.inv).Error; err != nil {
+				return fmt.Errorf("锁定库存失败: %w", err)
+			}
+
+			if err := tx.Create(inventory.NewReservation(p.inv.BookID, p.quantity, orderID, expiresAt)).Error; err != nil {
+				return fmt.Errorf("创建预占记录失败: %w", err)
+			}
+
+			log := inventory.NewLockLog(p.inv.BookID, p.quantity, beforeStock, p.inv.Stock, orderID)
+			if err := tx.Create(log).Error; err != nil {
+				return fmt.Errorf("创建库存日志失败: %w", err)
+			}
+		}
+		return nil
+	})
+}
@@ -45,7 +45,7 @@ func NewDB(cfg *config.DatabaseConfig) (*gorm.DB, error) {
 	}
 
 	// 自动迁移
-	if err := db.AutoMigrate(&inventory.Inventory{}, &inventory.InventoryLog{}); err != nil {
+	if err := db.AutoMigrate(&inventory.Inventory{}, &inventory.InventoryLog{}, &inventory.Reservation{}, &inventory.WarehouseStock{}, &inventory.StockSubscription{}); err != nil {
 		return nil, fmt.Errorf("数据库迁移失败: %w", err)
 	}
 
@@ -137,6 +137,18 @@ func (r *inventoryRepository) DeductStock(ctx context.Context, bookID uint, quan
 			return fmt.Errorf("锁定库存失败: %w", err)
 		}
 
+		// 幂等性检查：同一订单已扣减（且未释放）时不再重复扣减
+		// 行锁已持有，同一图书的并发扣减在此串行，检查与写日志之间不会被插入
+		if orderID != 0 {
+			deducted, err := isOrderDeducted(tx, bookID, orderID)
+			if err != nil {
+				return err
+			}
+			if deducted {
+				return inventory.ErrDuplicateDeduction
+			}
+		}
+
 		// 步骤2：检查库存是否充足
 		if !inv.CanDeduct(quantity) {
 			return inventory.ErrInsufficientStock
@@ -176,6 +188,17 @@ func (r *inventoryRepository) ReleaseStock(ctx context.Context, bookID uint, qua
 			return fmt.Errorf("锁定库存失败: %w", err)
 		}
 
+		// 幂等性检查：订单没有未释放的扣减时不再释放，防止重复释放导致库存虚增
+		if orderID != 0 {
+			deducted, err := isOrderDeducted(tx, bookID, orderID)
+			if err != nil {
+				return err
+			}
+			if !deducted {
+				return inventory.ErrDuplicateRelease
+			}
+		}
+
 		// 释放库存
 		beforeStock := inv.Stock
 		inv.Stock += quantity
@@ -195,6 +218,37 @@ func (r *inventoryRepository) ReleaseStock(ctx context.Context, bookID uint, qua
 	})
 }
 
+// isOrderDeducted 订单在该图书上是否有未释放的扣减
+//
+// 按库存日志判断：DEDUCT次数多于RELEASE次数即为已扣减未释放
+// （订单释放后允许再次扣减，与Redis脚本删除扣减记录的语义一致）
+// 只统计可售库存层（warehouse_id = 0）的日志，仓库分配日志不计入
+func isOrderDeducted(tx *gorm.DB, bookID, orderID uint) (bool, error) {
+	var counts []struct {
+		ChangeType inventory.ChangeType
+		Cnt        int64
+	}
+	if err := tx.Model(&inventory.InventoryLog{}).
+		Select("change_type, COUNT(*) AS cnt").
+		Where("book_id = ? AND order_id = ? AND warehouse_id = 0 AND change_type IN ?", bookID, orderID,
+			[]inventory.ChangeType{inventory.ChangeTypeDeduct, inventory.ChangeTypeRelease}).
+		Group("change_type").
+		Scan(&counts).Error; err != nil {
+		return false, fmt.Errorf("查询订单库存日志失败: %w", err)
+	}
+
+	var deducts, releases int64
+	for _, c := range counts {
+		switch c.ChangeType {
+		case inventory.ChangeTypeDeduct:
+			deducts = c.Cnt
+		case inventory.ChangeTypeRelease:
+			releases = c.Cnt
+		}
+	}
+	return deducts > releases, nil
+}
+
 // RestockInventory 补充库存
 func (r *inventoryRepository) RestockInventory(ctx context.Context, bookID uint, quantity int) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -228,3 +282,39 @@ func (r *inventoryRepository) RestockInventory(ctx context.Context, bookID uint,
 		return nil
 	})
 }
+
+// AdjustStock 调整可用库存（报损、丢失、盘点差异、退货入库）
+//
+// Redis已校验可用库存足够；MySQL与Redis不一致导致库存为负时拒绝，留给对账修复
+func (r *inventoryRepository) AdjustStock(ctx context.Context, bookID uint, quantity int, changeType inventory.ChangeType, remark string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var inv inventory.Inventory
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("book_id = ?", bookID).
+			First(&inv).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return inventory.ErrInventoryNotFound
+			}
+			return fmt.Errorf("锁定库存失败: %w", err)
+		}
+
+		beforeStock := inv.Stock
+		if beforeStock+quantity < 0 {
+			return inventory.ErrInsufficientStock
+		}
+		inv.Stock += quantity
+		inv.TotalStock = inv.Stock + inv.LockedStock
+
+		if err := tx.Save(&inv).Error; err != nil {
+			return fmt.Errorf("调整库存失败: %w", err)
+		}
+
+		log := inventory.NewAdjustLog(bookID, changeType, quantity, beforeStock, inv.Stock, remark)
+		if err := tx.Create(log).Error; err != nil {
+			return fmt.Errorf("创建库存日志失败: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -3,6 +3,7 @@ package mysql
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -77,3 +78,45 @@ func (r *logRepository) ListByOrderID(ctx context.Context, orderID uint) ([]*inv
 
 	return logs, nil
 }
+
+// LatestByBookIDs 查询每本图书最新的一条库存日志
+//
+// 只取可售库存层（warehouse_id = 0）的日志，仓库日志的Before/After是单个仓库的库存
+func (r *logRepository) LatestByBookIDs(ctx context.Context, bookIDs []uint) (map[uint]*inventory.InventoryLog, error) {
+	result := make(map[uint]*inventory.InventoryLog, len(bookIDs))
+	if len(bookIDs) == 0 {
+		return result, nil
+	}
+
+	latestIDs := r.db.WithContext(ctx).Model(&inventory.InventoryLog{}).
+		Select("MAX(id)").
+		Where("book_id IN ? AND warehouse_id = 0", bookIDs).
+		Group("book_id")
+
+	var logs []*inventory.InventoryLog
+	if err := r.db.WithContext(ctx).
+		Where("id IN (?)", latestIDs).
+		Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("查询最新库存日志失败: %w", err)
+	}
+
+	for _, l := range logs {
+		result[l.BookID] = l
+	}
+	return result, nil
+}
+
+// ListForReplay 按ID升序查询图书在截止时间之前的日志
+//
+// 与ListAfter一样使用游标分页：一本书的日志可能有几十万条，重放时逐页读取
+func (r *logRepository) ListForReplay(ctx context.Context, bookID uint, afterLogID uint, until time.Time, limit int) ([]*inventory.InventoryLog, error) {
+	var logs []*inventory.InventoryLog
+	if err := r.db.WithContext(ctx).
+		Where("book_id = ? AND id > ? AND created_at <= ?", bookID, afterLogID, until).
+		Order("id ASC").
+		Limit(limit).
+		Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("查询库存日志失败: %w", err)
+	}
+	return logs, nil
+}
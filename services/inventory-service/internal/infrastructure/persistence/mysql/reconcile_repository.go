@@ -0,0 +1,60 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+)
+
+// ListAfter 按图书ID升序分页查询库存
+//
+// 教学要点：游标分页（WHERE book_id > ? LIMIT n）
+// - 全量对账需要遍历整张表，OFFSET越往后越慢
+// - 以主键为游标每页都走索引范围扫描
+func (r *inventoryRepository) ListAfter(ctx context.Context, afterBookID uint, limit int) ([]*inventory.Inventory, error) {
+	var invs []*inventory.Inventory
+	if err := r.db.WithContext(ctx).
+		Where("book_id > ?", afterBookID).
+		Order("book_id ASC").
+		Limit(limit).
+		Find(&invs).Error; err != nil {
+		return nil, fmt.Errorf("查询库存列表失败: %w", err)
+	}
+	return invs, nil
+}
+
+// ApplyReconcile 对账修复MySQL库存
+//
+// 比较并更新：锁定行后确认库存仍是对账时读到的值，期间有新的扣减/释放落库则放弃，
+// 避免用过期的Redis快照覆盖刚同步的数据
+func (r *inventoryRepository) ApplyReconcile(ctx context.Context, bookID uint, expectStock, expectLocked, stock, locked int, remark string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		inv, err := lockInventory(tx, bookID)
+		if err != nil {
+			return err
+		}
+		if inv.Stock != expectStock || inv.LockedStock != expectLocked {
+			return inventory.ErrStockChanged
+		}
+
+		beforeStock := inv.Stock
+		inv.Stock = stock
+		inv.LockedStock = locked
+		inv.TotalStock = inv.Stock + inv.LockedStock
+		if err := inv.Validate(); err != nil {
+			return err
+		}
+		if err := tx.Save(inv).Error; err != nil {
+			return fmt.Errorf("修复库存失败: %w", err)
+		}
+
+		log := inventory.NewReconcileLog(bookID, beforeStock, inv.Stock, remark)
+		if err := tx.Create(log).Error; err != nil {
+			return fmt.Errorf("创建库存日志失败: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,152 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+)
+
+// ReserveStock 预占库存（使用悲观锁）
+//
+// 教学要点：
+// 1. 同一事务内：锁定库存行 → Stock转入LockedStock → 写预占记录 → 写LOCK日志
+// 2. (order_id, book_id)唯一索引兜底幂等：重复预占返回ErrDuplicateReservation
+func (r *inventoryRepository) ReserveStock(ctx context.Context, bookID uint, quantity int, orderID uint, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		inv, err := lockInventory(tx, bookID)
+		if err != nil {
+			return err
+		}
+
+		var existing int64
+		if err := tx.Model(&inventory.Reservation{}).
+			Where("order_id = ? AND book_id = ?", orderID, bookID).
+			Count(&existing).Error; err != nil {
+			return fmt.Errorf("查询预占记录失败: %w", err)
+		}
+		if existing > 0 {
+			return inventory.ErrDuplicateReservation
+		}
+
+		beforeStock := inv.Stock
+		if err := inv.Lock(quantity); err != nil {
+			return err
+		}
+		if err := tx.Save(inv).Error; err != nil {
+			return fmt.Errorf("锁定库存失败: %w", err)
+		}
+
+		if err := tx.Create(inventory.NewReservation(bookID, quantity, orderID, expiresAt)).Error; err != nil {
+			return fmt.Errorf("创建预占记录失败: %w", err)
+		}
+
+		log := inventory.NewLockLog(bookID, quantity, beforeStock, inv.Stock, orderID)
+		if err := tx.Create(log).Error; err != nil {
+			return fmt.Errorf("创建库存日志失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// ConfirmReservation 确认预占（锁定库存售出）
+func (r *inventoryRepository) ConfirmReservation(ctx context.Context, bookID uint, orderID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		inv, err := lockInventory(tx, bookID)
+		if err != nil {
+			return err
+		}
+
+		res, err := lockReservation(tx, bookID, orderID)
+		if err != nil {
+			return err
+		}
+		if err := res.Confirm(); err != nil {
+			return err
+		}
+
+		if err := inv.ConfirmLocked(res.Quantity); err != nil {
+			return err
+		}
+		if err := tx.Save(inv).Error; err != nil {
+			return fmt.Errorf("扣减锁定库存失败: %w", err)
+		}
+		if err := tx.Save(res).Error; err != nil {
+			return fmt.Errorf("更新预占记录失败: %w", err)
+		}
+
+		log := inventory.NewConfirmLog(bookID, res.Quantity, inv.Stock, orderID)
+		if err := tx.Create(log).Error; err != nil {
+			return fmt.Errorf("创建库存日志失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// CancelReservation 取消预占（锁定库存退回可用库存）
+func (r *inventoryRepository) CancelReservation(ctx context.Context, bookID uint, orderID uint, reason string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		inv, err := lockInventory(tx, bookID)
+		if err != nil {
+			return err
+		}
+
+		res, err := lockReservation(tx, bookID, orderID)
+		if err != nil {
+			return err
+		}
+		if err := res.Cancel(reason); err != nil {
+			return err
+		}
+
+		beforeStock := inv.Stock
+		if err := inv.Unlock(res.Quantity); err != nil {
+			return err
+		}
+		if err := tx.Save(inv).Error; err != nil {
+			return fmt.Errorf("解锁库存失败: %w", err)
+		}
+		if err := tx.Save(res).Error; err != nil {
+			return fmt.Errorf("更新预占记录失败: %w", err)
+		}
+
+		log := inventory.NewUnlockLog(bookID, res.Quantity, beforeStock, inv.Stock, orderID, reason)
+		if err := tx.Create(log).Error; err != nil {
+			return fmt.Errorf("创建库存日志失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// lockInventory 锁定库存行（SELECT FOR UPDATE）
+func lockInventory(tx *gorm.DB, bookID uint) (*inventory.Inventory, error) {
+	var inv inventory.Inventory
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("book_id = ?", bookID).
+		First(&inv).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, inventory.ErrInventoryNotFound
+		}
+		return nil, fmt.Errorf("锁定库存失败: %w", err)
+	}
+	return &inv, nil
+}
+
+// lockReservation 锁定预占记录（先锁库存行再锁预占记录，加锁顺序固定避免死锁）
+func lockReservation(tx *gorm.DB, bookID, orderID uint) (*inventory.Reservation, error) {
+	var res inventory.Reservation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND book_id = ?", orderID, bookID).
+		First(&res).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, inventory.ErrReservationNotFound
+		}
+		return nil, fmt.Errorf("查询预占记录失败: %w", err)
+	}
+	return &res, nil
+}
@@ -0,0 +1,86 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+)
+
+// subscriptionRepository 到货提醒订阅仓储实现
+type subscriptionRepository struct {
+	db *gorm.DB
+}
+
+// NewSubscriptionRepository 创建订阅仓储实例
+func NewSubscriptionRepository(db *gorm.DB) inventory.SubscriptionRepository {
+	return &subscriptionRepository{db: db}
+}
+
+// Subscribe 订阅
+//
+// 教学要点：INSERT ... ON DUPLICATE KEY UPDATE，一条语句完成"新建或重置"，
+// 并发重复订阅由(user_id, book_id)唯一索引兜底
+func (r *subscriptionRepository) Subscribe(ctx context.Context, userID, bookID uint) error {
+	sub := &inventory.StockSubscription{
+		UserID: userID,
+		BookID: bookID,
+		Status: inventory.SubscriptionStatusPending,
+	}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "book_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"status": inventory.SubscriptionStatusPending, "updated_at": time.Now()}),
+	}).Create(sub).Error
+	if err != nil {
+		return fmt.Errorf("订阅到货提醒失败: %w", err)
+	}
+	return nil
+}
+
+// Unsubscribe 取消订阅
+func (r *subscriptionRepository) Unsubscribe(ctx context.Context, userID, bookID uint) error {
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND book_id = ?", userID, bookID).
+		Delete(&inventory.StockSubscription{})
+	if result.Error != nil {
+		return fmt.Errorf("取消到货提醒失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return inventory.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// ListPending 按ID升序查询图书等待到货的订阅
+func (r *subscriptionRepository) ListPending(ctx context.Context, bookID uint, afterID uint, limit int) ([]*inventory.StockSubscription, error) {
+	var subs []*inventory.StockSubscription
+	if err := r.db.WithContext(ctx).
+		Where("book_id = ? AND status = ? AND id > ?", bookID, inventory.SubscriptionStatusPending, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&subs).Error; err != nil {
+		return nil, fmt.Errorf("查询到货提醒订阅失败: %w", err)
+	}
+	return subs, nil
+}
+
+// MarkNotified 标记为已通知
+func (r *subscriptionRepository) MarkNotified(ctx context.Context, ids []uint, notifiedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&inventory.StockSubscription{}).
+		Where("id IN ? AND status = ?", ids, inventory.SubscriptionStatusPending).
+		Updates(map[string]interface{}{
+			"status":      inventory.SubscriptionStatusNotified,
+			"notified_at": notifiedAt,
+		}).Error; err != nil {
+		return fmt.Errorf("更新到货提醒状态失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+)
+
+// SetLowStockThreshold 设置图书的低库存阈值（threshold为nil时恢复默认阈值）
+func (r *inventoryRepository) SetLowStockThreshold(ctx context.Context, bookID uint, threshold *int) error {
+	result := r.db.WithContext(ctx).
+		Model(&inventory.Inventory{}).
+		Where("book_id = ?", bookID).
+		Update("low_stock_threshold", threshold)
+	if result.Error != nil {
+		return fmt.Errorf("设置低库存阈值失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// 值未变化时RowsAffected也为0，需区分"记录不存在"
+		if _, err := r.GetByBookID(ctx, bookID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListLowStock 分页查询低库存图书（可用库存不高于生效阈值）
+//
+// 教学要点：
+// 1. COALESCE(low_stock_threshold, 默认阈值)：单独设置的阈值优先
+// 2. 按可用库存升序：最紧缺的图书排在最前，方便采购优先处理
+// 3. 读的是MySQL（异步落库），与Redis实时库存可能有秒级延迟，对采购场景足够
+func (r *inventoryRepository) ListLowStock(ctx context.Context, defaultThreshold int, includeOutOfStock bool, page, pageSize int) ([]*inventory.Inventory, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	query := r.db.WithContext(ctx).
+		Model(&inventory.Inventory{}).
+		Where("stock <= COALESCE(low_stock_threshold, ?)", defaultThreshold)
+	if !includeOutOfStock {
+		query = query.Where("stock > 0")
+	}
+	// Session：Count和Find各自基于同一条件构建语句，互不影响
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询低库存总数失败: %w", err)
+	}
+
+	var invs []*inventory.Inventory
+	if err := query.
+		Order("stock ASC, book_id ASC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&invs).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询低库存列表失败: %w", err)
+	}
+	return invs, total, nil
+}
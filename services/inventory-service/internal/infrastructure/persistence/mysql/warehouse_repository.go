@@ -0,0 +1,81 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+)
+
+// ListWarehouseStocks 查询图书在各仓库的库存
+func (r *inventoryRepository) ListWarehouseStocks(ctx context.Context, bookID uint) ([]*inventory.WarehouseStock, error) {
+	var stocks []*inventory.WarehouseStock
+	if err := r.db.WithContext(ctx).
+		Where("book_id = ?", bookID).
+		Order("warehouse_id ASC").
+		Find(&stocks).Error; err != nil {
+		return nil, fmt.Errorf("查询仓库库存失败: %w", err)
+	}
+	return stocks, nil
+}
+
+// ChangeWarehouseStock 变更仓库库存
+//
+// 教学要点：
+// 1. 仓库库存由Redis脚本分配、校验，这里按分配结果异步落库（与可售库存的同步方式一致）
+// 2. 仓库行不存在时创建：首次补货/调入即建立该仓库的库存记录
+// 3. 按仓库ID升序加锁，与批量扣减按图书ID升序加锁同理，避免死锁
+func (r *inventoryRepository) ChangeWarehouseStock(ctx context.Context, bookID uint, changes []inventory.WarehouseAllocation, changeType inventory.ChangeType, orderID uint, remark string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, c := range sortAllocations(changes) {
+			if c.WarehouseID == inventory.UnassignedWarehouseID || c.Quantity == 0 {
+				continue
+			}
+
+			ws, err := lockWarehouseStock(tx, bookID, c.WarehouseID)
+			if err != nil {
+				return err
+			}
+
+			before := ws.Stock
+			ws.Stock += c.Quantity
+			if err := tx.Save(ws).Error; err != nil {
+				return fmt.Errorf("更新仓库库存失败: %w", err)
+			}
+
+			log := inventory.NewWarehouseLog(bookID, c.WarehouseID, changeType, c.Quantity, before, ws.Stock, orderID, remark)
+			if err := tx.Create(log).Error; err != nil {
+				return fmt.Errorf("创建库存日志失败: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// lockWarehouseStock 锁定仓库库存行（不存在时返回库存为0的新记录，Save时插入）
+func lockWarehouseStock(tx *gorm.DB, bookID, warehouseID uint) (*inventory.WarehouseStock, error) {
+	var ws inventory.WarehouseStock
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("book_id = ? AND warehouse_id = ?", bookID, warehouseID).
+		First(&ws).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &inventory.WarehouseStock{BookID: bookID, WarehouseID: warehouseID}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("锁定仓库库存失败: %w", err)
+	}
+	return &ws, nil
+}
+
+// sortAllocations 按仓库ID升序返回副本
+func sortAllocations(changes []inventory.WarehouseAllocation) []inventory.WarehouseAllocation {
+	sorted := make([]inventory.WarehouseAllocation, len(changes))
+	copy(sorted, changes)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].WarehouseID < sorted[j].WarehouseID })
+	return sorted
+}
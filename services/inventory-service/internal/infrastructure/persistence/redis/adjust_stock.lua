@@ -0,0 +1,86 @@
+-- adjust_stock.lua
+-- 库存调整Lua脚本（原子操作）
+--
+-- 教学要点：
+-- 1. 两种模式
+--    - delta：按有符号数量调整（报损、丢失、退货入库）
+--    - count：按实盘数量调整（盘点），差异 = 实盘数量 - 账面数量（可用 + 锁定）
+--    - 盘点模式在脚本内读取账面数量并计算差异：读取与调整之间不会插入下单
+--
+-- 2. 调整只作用于可用库存
+--    - 已锁定的库存属于待支付订单，不能被报损扣掉
+--    - 减少数量超过可用库存时拒绝调整，需先处理相关订单
+--
+-- 3. 仓库库存同步变化
+--    - 减少：指定仓库时该仓库库存必须足够；未指定（0）时按兜底顺序从各仓库扣减，不足部分来自未分配库存
+--    - 增加：计入入库仓库（0计入未分配库存）
+--    - 盘点导入前不知道差异方向，两个仓库参数分开传入
+--
+-- 4. 只作用于主库存：热点图书由调用方先收回各分片的库存，
+--    各分片剩余的可用/锁定库存由调用方传入，计入账面数量和返回的调整前后库存
+--
+-- KEYS[1]: 库存键（stock:{book_id}）
+-- KEYS[2]: 锁定库存键（locked:{stock:{book_id}}）
+-- KEYS[3]: 仓库库存键（warehouse:{stock:{book_id}}）
+-- ARGV[1]: 模式（delta/count）
+-- ARGV[2]: 调整数量（delta模式，有符号）或实盘数量（count模式）
+-- ARGV[3]: 减少时的扣减仓库ID（0为按兜底顺序扣减）
+-- ARGV[4]: 增加时的入库仓库ID（0为计入未分配库存）
+-- ARGV[5]: 仓库兜底顺序（逗号分隔的仓库ID）
+-- ARGV[6]: 各分片的可用库存合计（未分片为0）
+-- ARGV[7]: 各分片的锁定库存合计（未分片为0）
+--
+-- 返回值：{结果码, 调整前可用库存, 调整后可用库存, 调整数量, 仓库分配串}
+--  1: 调整成功
+--  2: 无差异（count模式，未调整）
+--  0: 可用库存不足
+-- -1: 指定仓库库存不足
+
+local stock_key = KEYS[1]
+local locked_key = KEYS[2]
+local wh_key = KEYS[3]
+local mode = ARGV[1]
+local value = tonumber(ARGV[2])
+local warehouse_id = ARGV[3]
+local inbound_id = ARGV[4]
+local priority = ARGV[5]
+local other_stock = tonumber(ARGV[6])
+local other_locked = tonumber(ARGV[7])
+
+local stock = tonumber(redis.call('GET', stock_key) or 0)
+local total = stock + other_stock
+
+local delta = value
+if mode == "count" then
+    local locked = tonumber(redis.call('GET', locked_key) or 0) + other_locked
+    delta = value - (total + locked)
+    if delta == 0 then
+        return {2, total, total, 0, ""}
+    end
+end
+
+local allocation = ""
+if delta < 0 then
+    local quantity = -delta
+    if stock < quantity then
+        return {0, total, total, delta, ""}
+    end
+
+    if warehouse_id ~= "0" then
+        local available = tonumber(redis.call('HGET', wh_key, warehouse_id) or 0)
+        if available < quantity then
+            return {-1, total, total, delta, ""}
+        end
+        redis.call('HINCRBY', wh_key, warehouse_id, -quantity)
+        allocation = warehouse_id .. ":" .. quantity
+    else
+        allocation = wh_allocate(wh_key, quantity, "0", priority)
+    end
+elseif inbound_id ~= "0" then
+    redis.call('HINCRBY', wh_key, inbound_id, delta)
+    allocation = inbound_id .. ":" .. delta
+end
+
+redis.call('INCRBY', stock_key, delta)
+
+return {1, total, total + delta, delta, allocation}
@@ -0,0 +1,88 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+)
+
+// 库存调整结果码（与adjust_stock.lua一致）
+const (
+	AdjustInsufficient          = 0  // 可用库存不足
+	AdjustApplied               = 1  // 调整成功
+	AdjustNoDifference          = 2  // 盘点无差异，未调整
+	AdjustInsufficientWarehouse = -1 // 指定仓库库存不足
+)
+
+// AdjustResult 库存调整结果
+type AdjustResult struct {
+	Code int
+	// Before/After 调整前后的可用库存
+	Before int
+	After  int
+	// Delta 调整数量（有符号，盘点模式下为实盘与账面的差异）
+	Delta int
+	// Changes 各仓库的库存变更（有符号，未分配库存不记录）
+	Changes []inventory.WarehouseAllocation
+}
+
+// AdjustStock 按有符号数量调整库存（使用Lua脚本）
+//
+// warehouseID为0时：增加计入未分配库存，减少按兜底顺序从各仓库扣减
+func (s *InventoryStore) AdjustStock(ctx context.Context, bookID uint, delta int, warehouseID uint) (*AdjustResult, error) {
+	return s.adjust(ctx, bookID, "delta", delta, warehouseID, warehouseID)
+}
+
+// ApplyStocktake 按实盘数量调整库存（使用Lua脚本）
+//
+// 实盘数量与账面数量（可用 + 锁定）的差异在脚本内计算并调整到可用库存，无差异时返回AdjustNoDifference
+//
+// 差异方向在脚本内才确定：盘亏从warehouseID扣减（0为按兜底顺序），盘盈计入inboundWarehouse（0为未分配库存）
+func (s *InventoryStore) ApplyStocktake(ctx context.Context, bookID uint, counted int, warehouseID, inboundWarehouse uint) (*AdjustResult, error) {
+	return s.adjust(ctx, bookID, "count", counted, warehouseID, inboundWarehouse)
+}
+
+// adjust 执行库存调整脚本：热点图书先收回各分片的库存，各分片剩余的部分计入账面数量（见adjust_stock.lua）
+func (s *InventoryStore) adjust(ctx context.Context, bookID uint, mode string, value int, warehouseID, inboundWarehouse uint) (*AdjustResult, error) {
+	otherStock, otherLocked, err := s.recallForAdmin(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	u := stockUnit{bookID: bookID}
+	keys := []string{u.stockKey(), u.lockedKey(), u.warehouseKey()}
+	result, err := s.client.EvalSha(ctx, s.adjustSHA, keys, mode, value, warehouseID, inboundWarehouse, s.warehousePriority, otherStock, otherLocked).Result()
+	if err != nil {
+		return nil, fmt.Errorf("执行库存调整脚本失败: %w", err)
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 5 {
+		return nil, fmt.Errorf("库存调整脚本返回值错误: %v", result)
+	}
+	nums, err := toInt64Slice(values[:4])
+	if err != nil {
+		return nil, err
+	}
+	allocation, ok := values[4].(string)
+	if !ok {
+		return nil, fmt.Errorf("脚本返回值类型错误: %T", values[4])
+	}
+
+	changes, err := inventory.ParseAllocation(allocation)
+	if err != nil {
+		return nil, err
+	}
+	if nums[3] < 0 {
+		changes = inventory.NegateAllocations(changes)
+	}
+
+	return &AdjustResult{
+		Code:    int(nums[0]),
+		Before:  int(nums[1]),
+		After:   int(nums[2]),
+		Delta:   int(nums[3]),
+		Changes: changes,
+	}, nil
+}
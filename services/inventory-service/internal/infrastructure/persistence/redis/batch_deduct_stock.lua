@@ -0,0 +1,67 @@
+-- batch_deduct_stock.lua
+-- 批量扣减库存Lua脚本（原子操作，全部成功或全部不扣）
+--
+-- 教学要点：
+-- 1. 两阶段：先检查全部图书，再统一扣减
+--    - 检查阶段发现任一库存不足直接返回，不扣减任何图书
+--    - Redis单线程执行脚本，检查与扣减之间不会插入其他命令
+--
+-- 2. 幂等性：与deduct_stock.lua共用扣减记录 deduct:{单元}:{order_id}
+--    - 已扣减的图书跳过（重试时不会重复扣减）
+--    - 单本释放脚本可以释放批量扣减的图书
+--
+-- 3. 每本图书各用一个库存单元，各单元的key跨slot：只在单机Redis上使用
+--    （Redis Cluster下由调用方逐本扣减并撤销，见InventoryStore.BatchDeductStock）
+--
+-- KEYS[4i-3..4i]: 第i本图书本次使用的库存单元：可用库存键、分片布局键、扣减记录键、仓库库存键
+-- ARGV[1]: 优先仓库ID（0为不指定）
+-- ARGV[2]: 仓库兜底顺序（逗号分隔）
+-- ARGV[3i..3i+2]: 第i本图书的扣减数量、调用方看到的分片数、不足时是否写入跳过标记
+--
+-- 返回值（数组）：
+-- {0, i}: 第i本图书在本次使用的单元上不足，未扣减任何图书
+-- {-1, i}: 第i本图书的布局不符，未扣减任何图书
+-- {1, s1, s2, ...}: 扣减成功，si为第i本图书的状态（1=本次扣减，2=此前已扣减）
+
+local n = #KEYS / 4
+local preferred_warehouse = ARGV[1]
+local warehouse_priority = ARGV[2]
+
+-- 阶段1：检查
+local status = {}
+for i = 1, n do
+    local stock_key = KEYS[4 * i - 3]
+    local layout_key = KEYS[4 * i - 2]
+    local deduct_record_key = KEYS[4 * i - 1]
+    local quantity = tonumber(ARGV[3 * i])
+
+    local record = redis.call('GET', deduct_record_key)
+    if record == UNIT_SKIP then
+        return {0, i}
+    elseif record then
+        status[i] = 2
+    elseif not unit_layout_ok(layout_key, ARGV[3 * i + 1]) then
+        return {-1, i}
+    elseif tonumber(redis.call('GET', stock_key) or 0) < quantity then
+        if ARGV[3 * i + 2] == "1" then
+            redis.call('SETEX', deduct_record_key, UNIT_SKIP_TTL, UNIT_SKIP)
+        end
+        return {0, i}
+    else
+        status[i] = 1
+    end
+end
+
+-- 阶段2：扣减
+local result = {1}
+for i = 1, n do
+    if status[i] == 1 then
+        local quantity = tonumber(ARGV[3 * i])
+        redis.call('DECRBY', KEYS[4 * i - 3], quantity)
+        local allocation = wh_allocate(KEYS[4 * i], quantity, preferred_warehouse, warehouse_priority)
+        redis.call('SETEX', KEYS[4 * i - 1], 3600, allocation)
+    end
+    result[i + 1] = status[i]
+end
+
+return result
@@ -0,0 +1,40 @@
+-- batch_release_stock.lua
+-- 批量释放库存Lua脚本（原子操作）
+--
+-- 教学要点：
+-- 1. 与release_stock.lua逐本语义相同：未扣减的跳过，已释放的跳过
+-- 2. 一个脚本处理整个订单，补偿只需一次往返
+-- 3. 各单元的key跨slot：只在单机Redis上使用，Redis Cluster下逐本释放
+--
+-- KEYS[4i-3..4i]: 第i本图书的库存单元：可用库存键、仓库库存键、扣减记录键、释放记录键
+-- ARGV[i]: 第i本图书的释放数量
+--
+-- 返回值（数组）：{s1, s2, ...}
+-- si = 0: 未在这个单元扣减，无需释放
+-- si = 1: 释放成功
+-- si = 2: 重复释放（幂等性）
+
+local result = {}
+
+for i = 1, #KEYS / 4 do
+    local stock_key = KEYS[4 * i - 3]
+    local wh_key = KEYS[4 * i - 2]
+    local deduct_record_key = KEYS[4 * i - 1]
+    local release_record_key = KEYS[4 * i]
+    local quantity = tonumber(ARGV[i])
+    local allocation = redis.call('GET', deduct_record_key)
+
+    if redis.call('EXISTS', release_record_key) == 1 then
+        result[i] = 2
+    elseif not allocation or allocation == UNIT_SKIP then
+        result[i] = 0
+    else
+        redis.call('INCRBY', stock_key, quantity)
+        wh_restore(wh_key, allocation)
+        redis.call('DEL', deduct_record_key)
+        redis.call('SETEX', release_record_key, 3600, allocation)
+        result[i] = 1
+    end
+end
+
+return result
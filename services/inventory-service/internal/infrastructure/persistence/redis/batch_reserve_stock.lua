@@ -0,0 +1,70 @@
+-- batch_reserve_stock.lua
+-- 批量预占库存Lua脚本（原子操作，全部预占或一本都不占）
+--
+-- 教学要点：
+-- 1. 与batch_deduct_stock.lua相同的两阶段：先检查全部图书，再统一预占
+--    - 检查阶段发现任一库存不足直接返回，不预占任何图书
+--    - 逐本调用ReserveStock时，第3本不足前2本已被锁定，需要调用方补偿
+--
+-- 2. 与reserve_stock.lua共用预占记录 reserve:{单元}:{order_id}
+--    - 已预占的图书跳过（重试时不会重复预占）
+--    - 确认、取消、过期仍按单本处理，与单本预占完全相同
+--    - 过期队列由调用方写入（见reserve_stock.lua）
+--
+-- 3. 各单元的key跨slot：只在单机Redis上使用（Redis Cluster下由调用方逐本预占并撤销）
+--
+-- KEYS[5i-4..5i]: 第i本图书本次使用的库存单元：可用库存键、分片布局键、锁定库存键、预占记录键、仓库库存键
+-- ARGV[1]: 优先仓库ID（0为不指定）
+-- ARGV[2]: 仓库兜底顺序（逗号分隔）
+-- ARGV[3i..3i+2]: 第i本图书的预占数量、调用方看到的分片数、不足时是否写入跳过标记
+--
+-- 返回值（数组）：
+-- {0, i}: 第i本图书在本次使用的单元上不足，未预占任何图书
+-- {-1, i}: 第i本图书的布局不符，未预占任何图书
+-- {1, s1, s2, ...}: 预占成功，si为第i本图书的状态（1=本次预占，2=此前已预占）
+
+local n = #KEYS / 5
+local preferred_warehouse = ARGV[1]
+local warehouse_priority = ARGV[2]
+
+-- 阶段1：检查
+local status = {}
+for i = 1, n do
+    local stock_key = KEYS[5 * i - 4]
+    local layout_key = KEYS[5 * i - 3]
+    local reserve_key = KEYS[5 * i - 1]
+    local quantity = tonumber(ARGV[3 * i])
+
+    local current = redis.call('HGET', reserve_key, 'status')
+    if current == UNIT_SKIPPED then
+        return {0, i}
+    elseif current then
+        status[i] = 2
+    elseif not unit_layout_ok(layout_key, ARGV[3 * i + 1]) then
+        return {-1, i}
+    elseif tonumber(redis.call('GET', stock_key) or 0) < quantity then
+        if ARGV[3 * i + 2] == "1" then
+            redis.call('HSET', reserve_key, 'status', UNIT_SKIPPED)
+            redis.call('EXPIRE', reserve_key, UNIT_SKIP_TTL)
+        end
+        return {0, i}
+    else
+        status[i] = 1
+    end
+end
+
+-- 阶段2：预占
+local result = {1}
+for i = 1, n do
+    if status[i] == 1 then
+        local quantity = tonumber(ARGV[3 * i])
+        redis.call('DECRBY', KEYS[5 * i - 4], quantity)
+        redis.call('INCRBY', KEYS[5 * i - 2], quantity)
+
+        local allocation = wh_allocate(KEYS[5 * i], quantity, preferred_warehouse, warehouse_priority)
+        redis.call('HSET', KEYS[5 * i - 1], 'quantity', quantity, 'status', 'RESERVED', 'alloc', allocation)
+    end
+    result[i + 1] = status[i]
+end
+
+return result
@@ -0,0 +1,57 @@
+-- cancel_reservation.lua
+-- 取消预占Lua脚本（订单取消、预占过期，锁定库存退回可用库存）
+--
+-- 教学要点：
+-- 1. 锁定库存 → 可用库存（按预占时的仓库分配退回各仓库）
+-- 2. 已确认的预占不能取消（已售出，需走退款/释放流程）
+-- 3. 终态记录保留一段时间，用于重复取消的幂等判断
+-- 4. 撤销模式：批量预占逐本执行时撤销本次已预占的图书，删除记录（相当于没预占过，重试时可以重新预占）
+-- 5. 过期队列由调用方在取消后移出（全局key不在库存单元的slot中）
+--
+-- KEYS[1]: 可用库存键
+-- KEYS[2]: 锁定库存键（locked:{单元}）
+-- KEYS[3]: 预占记录键（reserve:{单元}:{order_id}）
+-- KEYS[4]: 仓库库存键（warehouse:{单元}）
+-- ARGV[1]: 终态记录保留时间（秒）
+-- ARGV[2]: 是否为撤销（1为撤销）
+--
+-- 返回值：
+-- 0: 预占不存在
+-- 1: 取消成功
+-- 2: 重复取消（幂等性）
+-- 3: 预占已确认
+
+local stock_key = KEYS[1]
+local locked_key = KEYS[2]
+local reserve_key = KEYS[3]
+local wh_key = KEYS[4]
+local retention = tonumber(ARGV[1])
+local undo = ARGV[2] == "1"
+
+local status = redis.call('HGET', reserve_key, 'status')
+if not status or status == UNIT_SKIPPED then
+    return 0
+end
+if status == 'CANCELLED' then
+    return 2
+end
+if status == 'CONFIRMED' then
+    return 3
+end
+
+local quantity = tonumber(redis.call('HGET', reserve_key, 'quantity'))
+
+-- 锁定库存退回可用库存
+redis.call('DECRBY', locked_key, quantity)
+redis.call('INCRBY', stock_key, quantity)
+wh_restore(wh_key, redis.call('HGET', reserve_key, 'alloc'))
+
+-- 更新状态
+if undo then
+    redis.call('DEL', reserve_key)
+else
+    redis.call('HSET', reserve_key, 'status', 'CANCELLED')
+    redis.call('EXPIRE', reserve_key, retention)
+end
+
+return 1
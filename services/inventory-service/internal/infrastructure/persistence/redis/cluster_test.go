@@ -0,0 +1,395 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+)
+
+// clusterSlotRanges 测试集群各节点负责的slot范围（与三主节点的Redis Cluster默认分配相同）
+var clusterSlotRanges = [][2]int{{0, 5460}, {5461, 10922}, {10923, 16383}}
+
+// crc16 Redis Cluster使用的CRC16（XMODEM）
+func crc16(data string) uint16 {
+	var crc uint16
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// keySlot 计算key所在的slot：有非空hash tag（第一个{到其后第一个}之间）时只对tag计算
+func keySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key)) % 16384
+}
+
+// slotNode slot所在的节点下标
+func slotNode(slot int) int {
+	for i, r := range clusterSlotRanges {
+		if slot >= r[0] && slot <= r[1] {
+			return i
+		}
+	}
+	return -1
+}
+
+// clusterKeyGuard 加在每个脚本前面的检查代码：脚本读写未通过KEYS声明的key时报错
+//
+// Redis Cluster按KEYS路由脚本，未声明的key可能不在执行脚本的节点上（miniredis不会检查）
+const clusterKeyGuard = `
+local __declared = {}
+for _, k in ipairs(KEYS) do __declared[k] = true end
+local __call = redis.call
+redis.call = function(cmd, key, ...)
+    local c = string.lower(cmd)
+    if c ~= 'publish' and not __declared[key] then
+        error('undeclared key in script: ' .. tostring(key))
+    end
+    return __call(cmd, key, ...)
+end
+`
+
+// clusterGuardHook 节点客户端的Hook：模拟Redis Cluster对跨slot的检查
+//
+//   - SCRIPT LOAD：在脚本前加上clusterKeyGuard
+//   - EVALSHA/DEL/EXISTS：全部key须在同一个slot，且slot属于这个节点（否则为CROSSSLOT/MOVED错误）
+type clusterGuardHook struct {
+	node int
+}
+
+func (h clusterGuardHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return ctx, h.check(cmd)
+}
+
+func (h clusterGuardHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	return nil
+}
+
+func (h clusterGuardHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	for _, cmd := range cmds {
+		if err := h.check(cmd); err != nil {
+			return ctx, err
+		}
+	}
+	return ctx, nil
+}
+
+func (h clusterGuardHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	return nil
+}
+
+// check 检查一条命令，SCRIPT LOAD时改写脚本
+func (h clusterGuardHook) check(cmd redis.Cmder) error {
+	args := cmd.Args()
+	var keys []interface{}
+	switch cmd.Name() {
+	case "script":
+		if len(args) == 3 && strings.EqualFold(fmt.Sprint(args[1]), "load") {
+			args[2] = clusterKeyGuard + fmt.Sprint(args[2])
+		}
+		return nil
+	case "evalsha", "eval":
+		n, err := strconv.Atoi(fmt.Sprint(args[2]))
+		if err != nil {
+			return err
+		}
+		keys = args[3 : 3+n]
+	case "del", "exists", "mget":
+		keys = args[1:]
+	case "get", "set", "setex", "incrby", "hget", "hgetall", "hset", "hdel", "hmget", "zadd", "zrem", "zrangebyscore":
+		keys = args[1:2]
+	default:
+		return nil
+	}
+
+	for i, key := range keys {
+		slot := keySlot(fmt.Sprint(key))
+		if slot != keySlot(fmt.Sprint(keys[0])) {
+			return fmt.Errorf("CROSSSLOT Keys in request don't hash to the same slot: %v", keys)
+		}
+		if i == 0 && slotNode(slot) != h.node {
+			return fmt.Errorf("MOVED %d: key %v is not on node %d", slot, key, h.node)
+		}
+	}
+	return nil
+}
+
+// newClusterTestStore 创建连接三个内存Redis节点（按slot分片，模拟Redis Cluster）的库存存储
+func newClusterTestStore(t *testing.T) (*InventoryStore, []*miniredis.Miniredis) {
+	t.Helper()
+
+	nodes := make([]*miniredis.Miniredis, len(clusterSlotRanges))
+	addrNode := make(map[string]int, len(nodes))
+	slots := make([]redis.ClusterSlot, len(nodes))
+	for i, r := range clusterSlotRanges {
+		nodes[i] = miniredis.RunT(t)
+		addrNode[nodes[i].Addr()] = i
+		slots[i] = redis.ClusterSlot{Start: r[0], End: r[1], Nodes: []redis.ClusterNode{{Addr: nodes[i].Addr()}}}
+	}
+
+	client := redis.NewClusterClient(&redis.ClusterOptions{
+		ClusterSlots: func(ctx context.Context) ([]redis.ClusterSlot, error) {
+			return slots, nil
+		},
+		NewClient: func(opt *redis.Options) *redis.Client {
+			c := redis.NewClient(opt)
+			c.AddHook(clusterGuardHook{node: addrNode[opt.Addr]})
+			return c
+		},
+	})
+	t.Cleanup(func() { client.Close() })
+
+	s := NewInventoryStore(client)
+	if !s.cluster {
+		t.Fatal("ClusterClient应按Redis Cluster处理")
+	}
+	if err := s.LoadScripts(context.Background()); err != nil {
+		t.Fatalf("加载脚本失败: %v", err)
+	}
+	return s, nodes
+}
+
+// clusterGet 从key所在的节点直接读取
+func clusterGet(t *testing.T, nodes []*miniredis.Miniredis, key string) string {
+	t.Helper()
+
+	v, err := nodes[slotNode(keySlot(key))].Get(key)
+	if err != nil && err != miniredis.ErrKeyNotFound {
+		t.Fatalf("读取%s失败: %v", key, err)
+	}
+	return v
+}
+
+// booksOnDistinctNodes 找出主库存落在不同节点上的两本图书（保证批量操作确实跨节点）
+func booksOnDistinctNodes(t *testing.T) (uint, uint) {
+	t.Helper()
+
+	first := uint(1)
+	node := slotNode(keySlot(stockUnit{bookID: first}.stockKey()))
+	for id := uint(2); id < 100; id++ {
+		if slotNode(keySlot(stockUnit{bookID: id}.stockKey())) != node {
+			return first, id
+		}
+	}
+	t.Fatal("找不到落在不同节点上的图书")
+	return 0, 0
+}
+
+// TestCluster_NormalBook 测试Redis Cluster下普通图书的扣减、预占、批量操作和管理操作
+func TestCluster_NormalBook(t *testing.T) {
+	s, _ := newClusterTestStore(t)
+	ctx := context.Background()
+	a, b := booksOnDistinctNodes(t)
+	expiresAt := time.Now().Add(15 * time.Minute)
+
+	if err := s.SetStock(ctx, a, 10); err != nil {
+		t.Fatalf("设置库存失败: %v", err)
+	}
+	if err := s.SetStock(ctx, b, 2); err != nil {
+		t.Fatalf("设置库存失败: %v", err)
+	}
+
+	// 单本扣减、重复扣减、释放
+	if code, err := s.DeductStock(ctx, a, 3, 1, 0); err != nil || code != 1 {
+		t.Fatalf("扣减失败: code=%d err=%v", code, err)
+	}
+	if code, err := s.DeductStock(ctx, a, 3, 1, 0); err != nil || code != 2 {
+		t.Fatalf("重复扣减应返回2: code=%d err=%v", code, err)
+	}
+	if code, err := s.ReleaseStock(ctx, a, 3, 1); err != nil || code != 1 {
+		t.Fatalf("释放失败: code=%d err=%v", code, err)
+	}
+
+	// 预占、确认、取消
+	if code, err := s.ReserveStock(ctx, a, 2, 2, expiresAt, 0); err != nil || code != 1 {
+		t.Fatalf("预占失败: code=%d err=%v", code, err)
+	}
+	if code, err := s.ConfirmReservation(ctx, a, 2); err != nil || code != 1 {
+		t.Fatalf("确认失败: code=%d err=%v", code, err)
+	}
+	if code, err := s.ReserveStock(ctx, a, 1, 3, expiresAt, 0); err != nil || code != 1 {
+		t.Fatalf("预占失败: code=%d err=%v", code, err)
+	}
+	if code, err := s.CancelReservation(ctx, a, 3); err != nil || code != 1 {
+		t.Fatalf("取消失败: code=%d err=%v", code, err)
+	}
+	if got := mustStock(t, s, a); got != 8 {
+		t.Errorf("图书%d库存应为8，实际%d", a, got)
+	}
+	if locked, err := s.GetLockedStock(ctx, a); err != nil || locked != 0 {
+		t.Errorf("锁定库存应为0，实际%d（%v）", locked, err)
+	}
+
+	// 批量扣减：图书b不足，已扣减的图书a要撤销
+	items := []inventory.StockItem{{BookID: a, Quantity: 2}, {BookID: b, Quantity: 3}}
+	res, err := s.BatchDeductStock(ctx, items, 4, 0)
+	if err != nil {
+		t.Fatalf("批量扣减失败: %v", err)
+	}
+	if res.Insufficient != 1 {
+		t.Fatalf("图书%d应库存不足: %+v", b, res)
+	}
+	if got := mustStock(t, s, a); got != 8 {
+		t.Errorf("整单不足时图书%d库存应仍为8，实际%d", a, got)
+	}
+
+	// 同一订单减少数量后重试：撤销没有留下释放记录，可以重新扣减
+	items[1].Quantity = 2
+	res, err = s.BatchDeductStock(ctx, items, 4, 0)
+	if err != nil || res.Insufficient != -1 || !res.Deducted[0] || !res.Deducted[1] {
+		t.Fatalf("批量扣减应全部成功: %+v err=%v", res, err)
+	}
+	codes, err := s.BatchReleaseStock(ctx, items, 4)
+	if err != nil || codes[0] != 1 || codes[1] != 1 {
+		t.Fatalf("批量释放失败: %v err=%v", codes, err)
+	}
+
+	// 批量预占：图书b不足，已预占的图书a要撤销
+	items[1].Quantity = 5
+	reserved, err := s.BatchReserveStock(ctx, items, 5, expiresAt, 0)
+	if err != nil || reserved.Insufficient != 1 {
+		t.Fatalf("图书%d应库存不足: %+v err=%v", b, reserved, err)
+	}
+	if locked, _ := s.GetLockedStock(ctx, a); locked != 0 {
+		t.Errorf("整单不足时图书%d不应被锁定，实际%d", a, locked)
+	}
+	if refs, err := s.ListExpiredReservations(ctx, expiresAt.Add(time.Minute), 10); err != nil || len(refs) != 0 {
+		t.Errorf("撤销后不应留下过期队列成员: %v err=%v", refs, err)
+	}
+
+	// 管理操作
+	if _, err := s.RestockInventory(ctx, a, 5, 1); err != nil {
+		t.Fatalf("补货失败: %v", err)
+	}
+	if r, err := s.AdjustStock(ctx, a, -2, 1); err != nil || r.After != 11 {
+		t.Fatalf("调整失败: %+v err=%v", r, err)
+	}
+	if _, err := s.TransferStock(ctx, a, 1, 2, 1); err != nil {
+		t.Fatalf("调拨失败: %v", err)
+	}
+	stocks, err := s.BatchGetStock(ctx, []uint{a, b})
+	if err != nil || stocks[a] != 11 || stocks[b] != 2 {
+		t.Errorf("批量查询库存错误: %v err=%v", stocks, err)
+	}
+	if seq, err := s.NotifyStockChange(ctx, a); err != nil || seq == 0 {
+		t.Fatalf("发布库存变更失败: seq=%d err=%v", seq, err)
+	}
+	if err := s.DeleteStock(ctx, b); err != nil {
+		t.Fatalf("删除库存失败: %v", err)
+	}
+}
+
+// TestCluster_HotBook 测试Redis Cluster下热点图书的分片、扣减、收回和合并
+func TestCluster_HotBook(t *testing.T) {
+	s, nodes := newClusterTestStore(t)
+	ctx := context.Background()
+	other := uint(2)
+	expiresAt := time.Now().Add(15 * time.Minute)
+
+	if err := s.SetStock(ctx, 1, 40); err != nil {
+		t.Fatalf("设置库存失败: %v", err)
+	}
+	if err := s.SetStock(ctx, other, 1); err != nil {
+		t.Fatalf("设置库存失败: %v", err)
+	}
+	if _, err := s.RestockInventory(ctx, 1, 8, 1); err != nil {
+		t.Fatalf("补货失败: %v", err)
+	}
+
+	stock, exists, err := s.ShardStock(ctx, 1, 4)
+	if err != nil || !exists || stock != 48 {
+		t.Fatalf("拆分失败: stock=%d exists=%v err=%v", stock, exists, err)
+	}
+	used := map[int]bool{}
+	for i := 1; i <= 4; i++ {
+		key := stockUnit{bookID: 1, shard: i}.stockKey()
+		used[slotNode(keySlot(key))] = true
+		if v := clusterGet(t, nodes, key); v != "12" {
+			t.Errorf("分片%d应为12，实际%s", i, v)
+		}
+	}
+	if len(used) < 2 {
+		t.Errorf("各分片应分散到多个节点，实际%v", used)
+	}
+	if warehouses, err := s.GetWarehouseStocks(ctx, 1); err != nil || warehouses[1] != 8 {
+		t.Errorf("仓库库存应随分片迁移保持合计: %v err=%v", warehouses, err)
+	}
+
+	// 订单分散到各分片扣减，再全部释放
+	for orderID := uint(10); orderID < 20; orderID++ {
+		if code, err := s.DeductStock(ctx, 1, 2, orderID, 0); err != nil || code != 1 {
+			t.Fatalf("订单%d扣减失败: code=%d err=%v", orderID, code, err)
+		}
+	}
+	if got := mustStock(t, s, 1); got != 28 {
+		t.Errorf("扣减后库存应为28，实际%d", got)
+	}
+	for orderID := uint(10); orderID < 20; orderID++ {
+		if code, err := s.ReleaseStock(ctx, 1, 2, orderID); err != nil || code != 1 {
+			t.Fatalf("订单%d释放失败: code=%d err=%v", orderID, code, err)
+		}
+	}
+
+	// 单个分片不够：收回到主库存后扣减（指定仓库1，分片上的仓库库存也一并收回）
+	if code, err := s.DeductStock(ctx, 1, 30, 20, 1); err != nil || code != 1 {
+		t.Fatalf("收回分片后扣减失败: code=%d err=%v", code, err)
+	}
+	if got := mustStock(t, s, 1); got != 18 {
+		t.Errorf("扣减后库存应为18，实际%d", got)
+	}
+	if allocs, err := s.DeductAllocations(ctx, 20, 1); err != nil || len(allocs[1]) == 0 {
+		t.Errorf("应能查到扣减的仓库分配: %v err=%v", allocs, err)
+	}
+
+	// 预占和取消
+	if code, err := s.ReserveStock(ctx, 1, 3, 21, expiresAt, 0); err != nil || code != 1 {
+		t.Fatalf("预占失败: code=%d err=%v", code, err)
+	}
+	if code, err := s.CancelReservation(ctx, 1, 21); err != nil || code != 1 {
+		t.Fatalf("取消失败: code=%d err=%v", code, err)
+	}
+
+	// 批量预占：另一本书不足，热点图书的预占要撤销
+	items := []inventory.StockItem{{BookID: 1, Quantity: 2}, {BookID: other, Quantity: 2}}
+	res, err := s.BatchReserveStock(ctx, items, 22, expiresAt, 0)
+	if err != nil || res.Insufficient != 1 {
+		t.Fatalf("图书%d应库存不足: %+v err=%v", other, res, err)
+	}
+	if locked, _ := s.GetLockedStock(ctx, 1); locked != 0 {
+		t.Errorf("整单不足时热点图书不应被锁定，实际%d", locked)
+	}
+
+	// 合并：库存全部回到主库存
+	if stock, _, err := s.ShardStock(ctx, 1, 1); err != nil || stock != 18 {
+		t.Fatalf("合并失败: stock=%d err=%v", stock, err)
+	}
+	if v := clusterGet(t, nodes, "stock:1"); v != "18" {
+		t.Errorf("合并后stock:1应为18，实际%s", v)
+	}
+	if code, err := s.ReleaseStock(ctx, 1, 30, 20); err != nil || code != 1 {
+		t.Fatalf("合并后释放旧订单失败: code=%d err=%v", code, err)
+	}
+	if got := mustStock(t, s, 1); got != 48 {
+		t.Errorf("释放后库存应为48，实际%d", got)
+	}
+}
@@ -0,0 +1,44 @@
+-- confirm_reservation.lua
+-- 确认预占Lua脚本（支付成功，锁定库存售出）
+--
+-- 教学要点：
+-- 1. 只扣减锁定库存，可用库存不变
+-- 2. 预占已过期但过期任务尚未处理时仍允许确认（库存仍被锁定，用户已付款）
+-- 3. 终态记录保留一段时间，用于重复确认的幂等判断
+-- 4. 过期队列由调用方在确认后移出（全局key不在库存单元的slot中）
+--
+-- KEYS[1]: 锁定库存键（locked:{单元}）
+-- KEYS[2]: 预占记录键（reserve:{单元}:{order_id}）
+-- ARGV[1]: 终态记录保留时间（秒）
+--
+-- 返回值：
+-- 0: 预占不存在
+-- 1: 确认成功
+-- 2: 重复确认（幂等性）
+-- 3: 预占已取消
+
+local locked_key = KEYS[1]
+local reserve_key = KEYS[2]
+local retention = tonumber(ARGV[1])
+
+local status = redis.call('HGET', reserve_key, 'status')
+if not status or status == UNIT_SKIPPED then
+    return 0
+end
+if status == 'CONFIRMED' then
+    return 2
+end
+if status == 'CANCELLED' then
+    return 3
+end
+
+local quantity = tonumber(redis.call('HGET', reserve_key, 'quantity'))
+
+-- 锁定库存售出
+redis.call('DECRBY', locked_key, quantity)
+
+-- 更新状态
+redis.call('HSET', reserve_key, 'status', 'CONFIRMED')
+redis.call('EXPIRE', reserve_key, retention)
+
+return 1
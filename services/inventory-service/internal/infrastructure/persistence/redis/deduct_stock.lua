@@ -16,34 +16,57 @@
 --    - 使用订单ID作为去重键
 --    - 防止同一订单重复扣减
 --
--- KEYS[1]: 库存键（stock:book_id）
+-- 4. 只读写一个库存单元（见unit_lib.lua），用到的key全部在KEYS中声明且共用一个hash tag
+--
+-- KEYS[1]: 可用库存键（主库存stock:{book_id}，分片{stock:{book_id}:shard:{i}}）
+-- KEYS[2]: 分片布局键（{单元}:shards）
+-- KEYS[3]: 扣减记录键（deduct:{单元}:{order_id}）
+-- KEYS[4]: 仓库库存键（warehouse:{单元}）
 -- ARGV[1]: 扣减数量
--- ARGV[2]: 订单ID（用于幂等性控制）
+-- ARGV[2]: 调用方看到的分片数（与单元上的布局不符时不扣减）
+-- ARGV[3]: 优先仓库ID（0为不指定）
+-- ARGV[4]: 仓库兜底顺序（逗号分隔的仓库ID）
+-- ARGV[5]: 不足时是否写入跳过标记（分片为1，主库存为0）
 --
 -- 返回值：
+-- -1: 布局不符（调用方刷新分片数后重试）
 -- 0: 库存不足
 -- 1: 扣减成功
 -- 2: 重复扣减（幂等性）
 
--- 获取当前库存
 local stock_key = KEYS[1]
+local layout_key = KEYS[2]
+local deduct_record_key = KEYS[3]
+local wh_key = KEYS[4]
 local quantity = tonumber(ARGV[1])
-local order_id = ARGV[2]
+local shards = ARGV[2]
+local preferred_warehouse = ARGV[3]
+local warehouse_priority = ARGV[4]
+local mark_skip = ARGV[5] == "1"
 
 -- 幂等性检查（订单是否已处理）
-local deduct_record_key = "deduct:" .. stock_key .. ":" .. order_id
-local is_deducted = redis.call('EXISTS', deduct_record_key)
-
-if is_deducted == 1 then
+local record = redis.call('GET', deduct_record_key)
+if record == UNIT_SKIP then
+    -- 此前在这个分片上不足，本订单不再使用这个分片
+    return 0
+end
+if record then
     -- 订单已扣减，返回2（幂等性）
     return 2
 end
 
+if not unit_layout_ok(layout_key, shards) then
+    return -1
+end
+
 -- 获取当前库存
 local current_stock = tonumber(redis.call('GET', stock_key) or 0)
 
 -- 检查库存是否充足
 if current_stock < quantity then
+    if mark_skip then
+        redis.call('SETEX', deduct_record_key, UNIT_SKIP_TTL, UNIT_SKIP)
+    end
     -- 库存不足，返回0
     return 0
 end
@@ -51,8 +74,10 @@ end
 -- 扣减库存
 redis.call('DECRBY', stock_key, quantity)
 
--- 记录已扣减（有效期1小时，防止内存泄漏）
-redis.call('SETEX', deduct_record_key, 3600, '1')
+-- 按策略从各仓库扣减（优先仓库 → 兜底仓库 → 未分配库存）
+-- 记录已扣减（有效期1小时，防止内存泄漏），值为仓库分配串，释放时按原分配退回
+local allocation = wh_allocate(wh_key, quantity, preferred_warehouse, warehouse_priority)
+redis.call('SETEX', deduct_record_key, 3600, allocation)
 
 -- 扣减成功，返回1
 return 1
@@ -5,8 +5,13 @@ import (
 	_ "embed"
 	"fmt"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-redis/redis/v8"
+
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
 )
 
 // Lua脚本嵌入
@@ -17,6 +22,12 @@ import (
 // - 只能引用当前包及子目录的文件
 // - 实践中，将脚本与使用它的代码放在一起更符合内聚性原则
 //
+//go:embed warehouse_lib.lua
+var warehouseLibLua string
+
+//go:embed unit_lib.lua
+var unitLibLua string
+
 //go:embed deduct_stock.lua
 var deductStockLua string
 
@@ -26,6 +37,48 @@ var releaseStockLua string
 //go:embed restock_inventory.lua
 var restockInventoryLua string
 
+//go:embed batch_deduct_stock.lua
+var batchDeductStockLua string
+
+//go:embed batch_release_stock.lua
+var batchReleaseStockLua string
+
+//go:embed reserve_stock.lua
+var reserveStockLua string
+
+//go:embed batch_reserve_stock.lua
+var batchReserveStockLua string
+
+//go:embed confirm_reservation.lua
+var confirmReservationLua string
+
+//go:embed cancel_reservation.lua
+var cancelReservationLua string
+
+//go:embed reconcile_stock.lua
+var reconcileStockLua string
+
+//go:embed transfer_stock.lua
+var transferStockLua string
+
+//go:embed adjust_stock.lua
+var adjustStockLua string
+
+//go:embed stock_watch_notify.lua
+var stockWatchNotifyLua string
+
+//go:embed move_out_stock.lua
+var moveOutStockLua string
+
+//go:embed move_in_stock.lua
+var moveInStockLua string
+
+// reservationExpiryKey 预占过期队列（ZSET，score为过期时间）
+const reservationExpiryKey = "reservation:expiry"
+
+// reservationRetention 预占进入终态后记录的保留时间（用于幂等判断）
+const reservationRetention = 7 * 24 * time.Hour
+
 // InventoryStore Redis库存存储
 //
 // 教学要点：
@@ -39,42 +92,85 @@ var restockInventoryLua string
 //   - MySQL为辅（持久化、对账）
 //   - 定时同步Redis → MySQL
 //
-// 3. Key设计规范
+// 3. Key设计规范（同一库存单元的key共用hash tag，见stockUnit）
 //   - stock:{book_id}：库存数量
-//   - deduct:stock:{book_id}:{order_id}：扣减记录（幂等性）
-//   - release:stock:{book_id}:{order_id}：释放记录（幂等性）
+//   - deduct:{stock:{book_id}}:{order_id}：扣减记录（幂等性）
+//   - release:{stock:{book_id}}:{order_id}：释放记录（幂等性）
+//   - locked:{stock:{book_id}}：锁定库存数量
+//   - reserve:{stock:{book_id}}:{order_id}：预占记录（Hash：quantity/status）
+//   - reservation:expiry：预占过期队列（ZSET）
+//   - warehouse:{stock:{book_id}}：各仓库可用库存（Hash，field为仓库ID）
+//   - {stock:{book_id}:shard:{i}}及同tag的key：热点图书的各分片（见unit_lib.lua、ShardStock）
+//
+// 4. 同时支持单机Redis和Redis Cluster：脚本访问的key全部通过KEYS声明，且同一脚本的key在同一slot
 type InventoryStore struct {
-	client *redis.Client
+	client redis.UniversalClient
+
+	// 是否为Redis Cluster：批量脚本的各图书在不同slot，Cluster下改为逐本执行
+	cluster bool
+
+	// 热点图书的分片数（由ShardStock维护，布局不符时从Redis刷新），扣减时据此决定尝试顺序
+	shardMu sync.RWMutex
+	shards  map[uint]int
+
+	// 仓库兜底顺序（逗号分隔的仓库ID，按优先级），优先仓库库存不足时依次使用
+	warehousePriority string
 
 	// Lua脚本SHA1（预加载优化）
 	deductScriptSHA  string
 	releaseScriptSHA string
 	restockScriptSHA string
+	batchDeductSHA   string
+	batchReleaseSHA  string
+	reserveScriptSHA string
+	batchReserveSHA  string
+	confirmScriptSHA string
+	cancelScriptSHA  string
+	reconcileSHA     string
+	transferSHA      string
+	adjustSHA        string
+	stockWatchSHA    string
+	moveOutSHA       string
+	moveInSHA        string
 }
 
-// NewInventoryStore 创建Redis库存存储实例
-func NewInventoryStore(client *redis.Client) *InventoryStore {
+// NewInventoryStore 创建Redis库存存储实例（client为*redis.Client或*redis.ClusterClient）
+func NewInventoryStore(client redis.UniversalClient) *InventoryStore {
+	_, cluster := client.(*redis.ClusterClient)
 	return &InventoryStore{
-		client: client,
+		client:  client,
+		cluster: cluster,
+		shards:  make(map[uint]int),
 	}
 }
 
+// SetWarehousePriority 设置仓库兜底顺序（按优先级排列的仓库ID）
+func (s *InventoryStore) SetWarehousePriority(warehouseIDs []uint) {
+	ids := make([]string, len(warehouseIDs))
+	for i, id := range warehouseIDs {
+		ids[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	s.warehousePriority = strings.Join(ids, ",")
+}
+
 // LoadScripts 预加载Lua脚本
 //
 // 教学要点：
-// 1. SCRIPT LOAD预加载脚本到Redis
+// 1. SCRIPT LOAD预加载脚本到Redis（Cluster下加载到每个主节点）
 // 2. 后续使用EVALSHA调用（减少网络传输）
 // 3. 性能优化：EVAL传输整个脚本，EVALSHA只传输SHA1
+// 4. 改变可用库存的脚本拼接仓库分配函数库（warehouse_lib.lua），按仓库扣减/退回
+// 5. 扣减、预占及其释放类脚本拼接库存单元函数库（unit_lib.lua），处理布局校验和跳过标记
 func (s *InventoryStore) LoadScripts(ctx context.Context) error {
 	// 加载扣减脚本
-	deductSHA, err := s.client.ScriptLoad(ctx, deductStockLua).Result()
+	deductSHA, err := s.client.ScriptLoad(ctx, unitLibLua+warehouseLibLua+deductStockLua).Result()
 	if err != nil {
 		return fmt.Errorf("加载扣减脚本失败: %w", err)
 	}
 	s.deductScriptSHA = deductSHA
 
 	// 加载释放脚本
-	releaseSHA, err := s.client.ScriptLoad(ctx, releaseStockLua).Result()
+	releaseSHA, err := s.client.ScriptLoad(ctx, unitLibLua+warehouseLibLua+releaseStockLua).Result()
 	if err != nil {
 		return fmt.Errorf("加载释放脚本失败: %w", err)
 	}
@@ -87,36 +183,104 @@ func (s *InventoryStore) LoadScripts(ctx context.Context) error {
 	}
 	s.restockScriptSHA = restockSHA
 
-	return nil
-}
+	// 加载批量扣减/释放脚本
+	batchDeductSHA, err := s.client.ScriptLoad(ctx, unitLibLua+warehouseLibLua+batchDeductStockLua).Result()
+	if err != nil {
+		return fmt.Errorf("加载批量扣减脚本失败: %w", err)
+	}
+	s.batchDeductSHA = batchDeductSHA
 
-// GetStock 获取库存
-func (s *InventoryStore) GetStock(ctx context.Context, bookID uint) (int, error) {
-	key := s.stockKey(bookID)
+	batchReleaseSHA, err := s.client.ScriptLoad(ctx, unitLibLua+warehouseLibLua+batchReleaseStockLua).Result()
+	if err != nil {
+		return fmt.Errorf("加载批量释放脚本失败: %w", err)
+	}
+	s.batchReleaseSHA = batchReleaseSHA
 
-	val, err := s.client.Get(ctx, key).Result()
+	// 加载预占脚本
+	reserveSHA, err := s.client.ScriptLoad(ctx, unitLibLua+warehouseLibLua+reserveStockLua).Result()
 	if err != nil {
-		if err == redis.Nil {
-			// 库存不存在，返回0
-			return 0, nil
-		}
-		return 0, fmt.Errorf("获取库存失败: %w", err)
+		return fmt.Errorf("加载预占脚本失败: %w", err)
+	}
+	s.reserveScriptSHA = reserveSHA
+
+	batchReserveSHA, err := s.client.ScriptLoad(ctx, unitLibLua+warehouseLibLua+batchReserveStockLua).Result()
+	if err != nil {
+		return fmt.Errorf("加载批量预占脚本失败: %w", err)
+	}
+	s.batchReserveSHA = batchReserveSHA
+
+	confirmSHA, err := s.client.ScriptLoad(ctx, unitLibLua+confirmReservationLua).Result()
+	if err != nil {
+		return fmt.Errorf("加载确认预占脚本失败: %w", err)
+	}
+	s.confirmScriptSHA = confirmSHA
+
+	cancelSHA, err := s.client.ScriptLoad(ctx, unitLibLua+warehouseLibLua+cancelReservationLua).Result()
+	if err != nil {
+		return fmt.Errorf("加载取消预占脚本失败: %w", err)
+	}
+	s.cancelScriptSHA = cancelSHA
+
+	// 加载对账修复脚本
+	reconcileSHA, err := s.client.ScriptLoad(ctx, reconcileStockLua).Result()
+	if err != nil {
+		return fmt.Errorf("加载对账修复脚本失败: %w", err)
 	}
+	s.reconcileSHA = reconcileSHA
 
-	stock, err := strconv.Atoi(val)
+	// 加载仓库调拨脚本
+	transferSHA, err := s.client.ScriptLoad(ctx, warehouseLibLua+transferStockLua).Result()
 	if err != nil {
-		return 0, fmt.Errorf("解析库存失败: %w", err)
+		return fmt.Errorf("加载调拨脚本失败: %w", err)
 	}
+	s.transferSHA = transferSHA
 
-	return stock, nil
+	// 加载库存调整脚本
+	adjustSHA, err := s.client.ScriptLoad(ctx, warehouseLibLua+adjustStockLua).Result()
+	if err != nil {
+		return fmt.Errorf("加载库存调整脚本失败: %w", err)
+	}
+	s.adjustSHA = adjustSHA
+
+	// 加载库存变更通知脚本
+	stockWatchSHA, err := s.client.ScriptLoad(ctx, stockWatchNotifyLua).Result()
+	if err != nil {
+		return fmt.Errorf("加载库存变更通知脚本失败: %w", err)
+	}
+	s.stockWatchSHA = stockWatchSHA
+
+	// 加载库存单元迁移脚本（分片再平衡）
+	moveOutSHA, err := s.client.ScriptLoad(ctx, warehouseLibLua+moveOutStockLua).Result()
+	if err != nil {
+		return fmt.Errorf("加载库存迁出脚本失败: %w", err)
+	}
+	s.moveOutSHA = moveOutSHA
+
+	moveInSHA, err := s.client.ScriptLoad(ctx, warehouseLibLua+moveInStockLua).Result()
+	if err != nil {
+		return fmt.Errorf("加载库存迁入脚本失败: %w", err)
+	}
+	s.moveInSHA = moveInSHA
+
+	return nil
+}
+
+// GetStock 获取库存（库存不存在返回0，热点图书为主库存与各分片之和）
+func (s *InventoryStore) GetStock(ctx context.Context, bookID uint) (int, error) {
+	snaps, err := s.readStocks(ctx, []uint{bookID})
+	if err != nil {
+		return 0, fmt.Errorf("获取库存失败: %w", err)
+	}
+	return snaps[bookID].Stock, nil
 }
 
 // SetStock 设置库存
-// 教学要点：初始化库存或同步MySQL库存到Redis
+// 教学要点：初始化库存或同步MySQL库存到Redis（热点图书先收回各分片的库存，再设置主库存）
 func (s *InventoryStore) SetStock(ctx context.Context, bookID uint, stock int) error {
-	key := s.stockKey(bookID)
-
-	if err := s.client.Set(ctx, key, stock, 0).Err(); err != nil {
+	if _, err := s.recallShards(ctx, bookID); err != nil {
+		return fmt.Errorf("设置库存失败: %w", err)
+	}
+	if err := s.client.Set(ctx, stockUnit{bookID: bookID}.stockKey(), stock, 0).Err(); err != nil {
 		return fmt.Errorf("设置库存失败: %w", err)
 	}
 
@@ -132,116 +296,726 @@ func (s *InventoryStore) SetStock(ctx context.Context, bookID uint, stock int) e
 //     0: 库存不足
 //     1: 扣减成功
 //     2: 重复扣减
-func (s *InventoryStore) DeductStock(ctx context.Context, bookID uint, quantity int, orderID uint) (int, error) {
-	key := s.stockKey(bookID)
-
-	// 执行Lua脚本
-	result, err := s.client.EvalSha(ctx, s.deductScriptSHA, []string{key}, quantity, orderID).Result()
+//  4. preferredWarehouse为优先发货仓库（0为不指定），不足部分按兜底顺序从其他仓库扣减
+//  5. 热点图书每次脚本调用只扣一个分片，分片不足时换下一个单元（见walkUnits）
+func (s *InventoryStore) DeductStock(ctx context.Context, bookID uint, quantity int, orderID uint, preferredWarehouse uint) (int, error) {
+	code, err := s.walkBook(ctx, bookID, orderID, func(w *unitWalk, u stockUnit) (int, error) {
+		keys := []string{u.stockKey(), u.layoutKey(), u.recordKey("deduct", orderID), u.warehouseKey()}
+		return s.evalCode(ctx, s.deductScriptSHA, keys, quantity, w.shards, preferredWarehouse, s.warehousePriority, u.skipFlag())
+	})
 	if err != nil {
 		return 0, fmt.Errorf("执行扣减脚本失败: %w", err)
 	}
+	return code, nil
+}
 
-	// 转换结果
-	code, ok := result.(int64)
-	if !ok {
-		return 0, fmt.Errorf("脚本返回值类型错误: %T", result)
+// walkBook 单本图书的walkUnits：try返回脚本结果码，大于0即成功，返回最终结果码（库存不足为0）
+func (s *InventoryStore) walkBook(ctx context.Context, bookID, orderID uint, try func(w *unitWalk, u stockUnit) (int, error)) (int, error) {
+	result := unitInsufficient
+	_, err := s.walkUnits(ctx, []uint{bookID}, orderID, func(walks []*unitWalk) (int, int, error) {
+		code, err := try(walks[0], walks[0].current())
+		if err != nil || code <= 0 {
+			return code, 0, err
+		}
+		result = code
+		return code, -1, nil
+	})
+	if err != nil {
+		return 0, err
 	}
-
-	return int(code), nil
+	return result, nil
 }
 
 // ReleaseStock 释放库存（使用Lua脚本）
 func (s *InventoryStore) ReleaseStock(ctx context.Context, bookID uint, quantity int, orderID uint) (int, error) {
-	key := s.stockKey(bookID)
-
-	result, err := s.client.EvalSha(ctx, s.releaseScriptSHA, []string{key}, quantity, orderID).Result()
+	code, err := s.releaseOnUnits(ctx, bookID, quantity, orderID, false)
 	if err != nil {
 		return 0, fmt.Errorf("执行释放脚本失败: %w", err)
 	}
+	return code, nil
+}
 
-	code, ok := result.(int64)
-	if !ok {
-		return 0, fmt.Errorf("脚本返回值类型错误: %T", result)
+// releaseOnUnits 查找扣减记录所在的单元并释放（undo为撤销：不写释放记录，见release_stock.lua）
+func (s *InventoryStore) releaseOnUnits(ctx context.Context, bookID uint, quantity int, orderID uint, undo bool) (int, error) {
+	return s.probeUnits(ctx, bookID, orderID, func(u stockUnit) (int, error) {
+		keys := []string{u.stockKey(), u.warehouseKey(), u.recordKey("deduct", orderID), u.recordKey("release", orderID)}
+		return s.evalCode(ctx, s.releaseScriptSHA, keys, quantity, boolArg(undo))
+	})
+}
+
+// BatchDeductResult 批量扣减结果
+type BatchDeductResult struct {
+	// Insufficient 库存不足的图书下标（-1表示全部扣减成功）
+	Insufficient int
+	// Deducted 各图书是否为本次扣减（false表示此前已扣减，幂等跳过）
+	Deducted []bool
+}
+
+// BatchDeductStock 批量扣减库存（使用Lua脚本，全部成功或全部不扣）
+//
+// items须已合并重复图书（inventory.MergeStockItems）：同一key出现两次会让检查阶段重复计算
+//
+// Redis Cluster下各图书的单元在不同slot，改为逐本扣减，某本不足时撤销已扣减的图书（见batchDeductEach）
+func (s *InventoryStore) BatchDeductStock(ctx context.Context, items []inventory.StockItem, orderID uint, preferredWarehouse uint) (*BatchDeductResult, error) {
+	if s.cluster {
+		return s.batchDeductEach(ctx, items, orderID, preferredWarehouse)
 	}
 
-	return int(code), nil
+	var deducted []bool
+	insufficient, err := s.walkUnits(ctx, itemBookIDs(items), orderID, func(walks []*unitWalk) (int, int, error) {
+		keys := make([]string, 0, len(items)*4)
+		args := []interface{}{preferredWarehouse, s.warehousePriority}
+		for i, item := range items {
+			w := walks[i]
+			u := w.current()
+			keys = append(keys, u.stockKey(), u.layoutKey(), u.recordKey("deduct", orderID), u.warehouseKey())
+			args = append(args, item.Quantity, w.shards, u.skipFlag())
+		}
+
+		result, err := s.client.EvalSha(ctx, s.batchDeductSHA, keys, args...).Result()
+		if err != nil {
+			return 0, 0, err
+		}
+		code, idx, applied, err := parseBatchResult(result, len(items))
+		deducted = applied
+		return code, idx, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("执行批量扣减脚本失败: %w", err)
+	}
+	if insufficient >= 0 {
+		return &BatchDeductResult{Insufficient: insufficient}, nil
+	}
+	return &BatchDeductResult{Insufficient: -1, Deducted: deducted}, nil
 }
 
-// RestockInventory 补充库存（使用Lua脚本）
-func (s *InventoryStore) RestockInventory(ctx context.Context, bookID uint, quantity int) (int, error) {
-	key := s.stockKey(bookID)
+// batchDeductEach 逐本扣减，某本不足（或出错）时撤销本次已扣减的图书，结果与批量脚本相同
+func (s *InventoryStore) batchDeductEach(ctx context.Context, items []inventory.StockItem, orderID uint, preferredWarehouse uint) (*BatchDeductResult, error) {
+	deducted := make([]bool, len(items))
+	for i, item := range items {
+		code, err := s.DeductStock(ctx, item.BookID, item.Quantity, orderID, preferredWarehouse)
+		if err == nil && code != 0 {
+			deducted[i] = code == 1
+			continue
+		}
 
-	result, err := s.client.EvalSha(ctx, s.restockScriptSHA, []string{key}, quantity).Result()
+		for j := 0; j < i; j++ {
+			if !deducted[j] {
+				continue
+			}
+			if _, undoErr := s.releaseOnUnits(ctx, items[j].BookID, items[j].Quantity, orderID, true); undoErr != nil {
+				return nil, fmt.Errorf("撤销图书%d的扣减失败: %w", items[j].BookID, undoErr)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &BatchDeductResult{Insufficient: i}, nil
+	}
+	return &BatchDeductResult{Insufficient: -1, Deducted: deducted}, nil
+}
+
+// parseBatchResult 解析批量扣减/预占脚本的返回值
+//
+// 返回结果码、出问题的图书下标（成功时为-1）和各图书是否为本次处理（仅成功时有值，false表示此前已处理，幂等跳过）
+func parseBatchResult(result interface{}, n int) (int, int, []bool, error) {
+	codes, err := toInt64Slice(result)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	if len(codes) == 0 {
+		return 0, 0, nil, fmt.Errorf("批量脚本返回值为空")
+	}
+	if codes[0] <= 0 {
+		if len(codes) != 2 {
+			return 0, 0, nil, fmt.Errorf("批量脚本返回值长度错误: %d", len(codes))
+		}
+		// Lua数组下标从1开始
+		return int(codes[0]), int(codes[1]) - 1, nil, nil
+	}
+	if len(codes) != n+1 {
+		return 0, 0, nil, fmt.Errorf("批量脚本返回值长度错误: %d", len(codes))
+	}
+
+	applied := make([]bool, n)
+	for i, c := range codes[1:] {
+		applied[i] = c == 1
+	}
+	return int(codes[0]), -1, applied, nil
+}
+
+// BatchReleaseStock 批量释放库存（使用Lua脚本）
+//
+// 返回各图书的释放结果，含义与ReleaseStock相同：0未扣减，1释放成功，2重复释放
+//
+// 先在各图书的第一个单元上批量释放（普通图书只有主库存，一次往返完成），没找到记录的图书再逐本查找；
+// Redis Cluster下直接逐本释放
+func (s *InventoryStore) BatchReleaseStock(ctx context.Context, items []inventory.StockItem, orderID uint) ([]int, error) {
+	out := make([]int, len(items))
+
+	if !s.cluster {
+		keys := make([]string, 0, len(items)*4)
+		args := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			u := unitOrder(item.BookID, orderID, s.shardHint(item.BookID))[0]
+			keys = append(keys, u.stockKey(), u.warehouseKey(), u.recordKey("deduct", orderID), u.recordKey("release", orderID))
+			args = append(args, item.Quantity)
+		}
+
+		result, err := s.client.EvalSha(ctx, s.batchReleaseSHA, keys, args...).Result()
+		if err != nil {
+			return nil, fmt.Errorf("执行批量释放脚本失败: %w", err)
+		}
+		codes, err := toInt64Slice(result)
+		if err != nil {
+			return nil, err
+		}
+		if len(codes) != len(items) {
+			return nil, fmt.Errorf("批量释放脚本返回值长度错误: %d", len(codes))
+		}
+		for i, c := range codes {
+			out[i] = int(c)
+		}
+	}
+
+	for i, item := range items {
+		if out[i] != 0 {
+			continue
+		}
+		code, err := s.ReleaseStock(ctx, item.BookID, item.Quantity, orderID)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = code
+	}
+	return out, nil
+}
+
+// itemBookIDs 各图书的ID
+func itemBookIDs(items []inventory.StockItem) []uint {
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.BookID
+	}
+	return ids
+}
+
+// boolArg 脚本的布尔参数（1/0）
+func boolArg(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// toInt64Slice 转换Lua脚本返回的整数数组
+func toInt64Slice(result interface{}) ([]int64, error) {
+	values, ok := result.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("脚本返回值类型错误: %T", result)
+	}
+	out := make([]int64, len(values))
+	for i, v := range values {
+		n, ok := v.(int64)
+		if !ok {
+			return nil, fmt.Errorf("脚本返回值类型错误: %T", v)
+		}
+		out[i] = n
+	}
+	return out, nil
+}
+
+// RestockInventory 补充库存（使用Lua脚本，warehouseID为入库仓库，0计入未分配库存）
+//
+// 补货加到主库存，返回补充后的库存合计（热点图书加上各分片）
+func (s *InventoryStore) RestockInventory(ctx context.Context, bookID uint, quantity int, warehouseID uint) (int, error) {
+	u := stockUnit{bookID: bookID}
+	keys := []string{u.stockKey(), u.warehouseKey(), maxShardsKey(bookID)}
+
+	result, err := s.client.EvalSha(ctx, s.restockScriptSHA, keys, quantity, warehouseID).Result()
 	if err != nil {
 		return 0, fmt.Errorf("执行补货脚本失败: %w", err)
 	}
 
-	newStock, ok := result.(int64)
+	values, err := toInt64Slice(result)
+	if err != nil {
+		return 0, err
+	}
+	if len(values) != 2 {
+		return 0, fmt.Errorf("补货脚本返回值长度错误: %d", len(values))
+	}
+	if values[1] > 0 {
+		return s.GetStock(ctx, bookID)
+	}
+
+	return int(values[0]), nil
+}
+
+// ReserveStock 预占库存（使用Lua脚本）
+//
+// 返回值含义：
+//
+//	0: 库存不足
+//	1: 预占成功
+//	2: 重复预占
+//
+// 热点图书按分片扣减，与DeductStock相同；预占前先加入过期队列，库存不足时移出
+func (s *InventoryStore) ReserveStock(ctx context.Context, bookID uint, quantity int, orderID uint, expiresAt time.Time, preferredWarehouse uint) (int, error) {
+	ref := ReservationRef{BookID: bookID, OrderID: orderID}
+	if err := s.addReservationExpiry(ctx, expiresAt, ref); err != nil {
+		return 0, err
+	}
+
+	code, err := s.walkBook(ctx, bookID, orderID, func(w *unitWalk, u stockUnit) (int, error) {
+		return s.reserveOnUnit(ctx, w, u, quantity, orderID, preferredWarehouse)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if code == 0 {
+		if err := s.RemoveReservationExpiry(ctx, ref); err != nil {
+			return 0, err
+		}
+	}
+	return code, nil
+}
+
+// reserveOnUnit 在一个单元上执行预占脚本
+func (s *InventoryStore) reserveOnUnit(ctx context.Context, w *unitWalk, u stockUnit, quantity int, orderID uint, preferredWarehouse uint) (int, error) {
+	keys := []string{u.stockKey(), u.layoutKey(), u.lockedKey(), u.recordKey("reserve", orderID), u.warehouseKey()}
+	return s.evalCode(ctx, s.reserveScriptSHA, keys, quantity, w.shards, preferredWarehouse, s.warehousePriority, u.skipFlag())
+}
+
+// BatchReserveResult 批量预占结果
+type BatchReserveResult struct {
+	// Insufficient 库存不足的图书下标（-1表示全部预占成功）
+	Insufficient int
+	// Reserved 各图书是否为本次预占（false表示此前已预占，幂等跳过）
+	Reserved []bool
+}
+
+// BatchReserveStock 批量预占库存（使用Lua脚本，全部预占或一本都不占）
+//
+// items须已合并重复图书（inventory.MergeStockItems），与BatchDeductStock相同
+//
+// Redis Cluster下逐本预占，某本不足时撤销已预占的图书（见batchReserveEach）
+func (s *InventoryStore) BatchReserveStock(ctx context.Context, items []inventory.StockItem, orderID uint, expiresAt time.Time, preferredWarehouse uint) (*BatchReserveResult, error) {
+	if s.cluster {
+		return s.batchReserveEach(ctx, items, orderID, expiresAt, preferredWarehouse)
+	}
+
+	refs := make([]ReservationRef, len(items))
+	for i, item := range items {
+		refs[i] = ReservationRef{BookID: item.BookID, OrderID: orderID}
+	}
+	if err := s.addReservationExpiry(ctx, expiresAt, refs...); err != nil {
+		return nil, err
+	}
+
+	var reserved []bool
+	insufficient, err := s.walkUnits(ctx, itemBookIDs(items), orderID, func(walks []*unitWalk) (int, int, error) {
+		keys := make([]string, 0, len(items)*5)
+		args := []interface{}{preferredWarehouse, s.warehousePriority}
+		for i, item := range items {
+			w := walks[i]
+			u := w.current()
+			keys = append(keys, u.stockKey(), u.layoutKey(), u.lockedKey(), u.recordKey("reserve", orderID), u.warehouseKey())
+			args = append(args, item.Quantity, w.shards, u.skipFlag())
+		}
+
+		result, err := s.client.EvalSha(ctx, s.batchReserveSHA, keys, args...).Result()
+		if err != nil {
+			return 0, 0, err
+		}
+		code, idx, applied, err := parseBatchResult(result, len(items))
+		reserved = applied
+		return code, idx, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("执行批量预占脚本失败: %w", err)
+	}
+	if insufficient >= 0 {
+		// 本次一本都没有预占
+		if err := s.RemoveReservationExpiry(ctx, refs...); err != nil {
+			return nil, err
+		}
+		return &BatchReserveResult{Insufficient: insufficient}, nil
+	}
+	return &BatchReserveResult{Insufficient: -1, Reserved: reserved}, nil
+}
+
+// batchReserveEach 逐本预占，某本不足（或出错）时撤销本次已预占的图书，结果与批量脚本相同
+func (s *InventoryStore) batchReserveEach(ctx context.Context, items []inventory.StockItem, orderID uint, expiresAt time.Time, preferredWarehouse uint) (*BatchReserveResult, error) {
+	reserved := make([]bool, len(items))
+	for i, item := range items {
+		code, err := s.ReserveStock(ctx, item.BookID, item.Quantity, orderID, expiresAt, preferredWarehouse)
+		if err == nil && code != 0 {
+			reserved[i] = code == 1
+			continue
+		}
+
+		for j := 0; j < i; j++ {
+			if !reserved[j] {
+				continue
+			}
+			if _, undoErr := s.cancelOnUnits(ctx, items[j].BookID, orderID, true); undoErr != nil {
+				return nil, fmt.Errorf("撤销图书%d的预占失败: %w", items[j].BookID, undoErr)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &BatchReserveResult{Insufficient: i}, nil
+	}
+	return &BatchReserveResult{Insufficient: -1, Reserved: reserved}, nil
+}
+
+// ConfirmReservation 确认预占（使用Lua脚本）
+//
+// 返回值含义：
+//
+//	0: 预占不存在
+//	1: 确认成功
+//	2: 重复确认
+//	3: 预占已取消
+func (s *InventoryStore) ConfirmReservation(ctx context.Context, bookID uint, orderID uint) (int, error) {
+	code, err := s.probeUnits(ctx, bookID, orderID, func(u stockUnit) (int, error) {
+		keys := []string{u.lockedKey(), u.recordKey("reserve", orderID)}
+		return s.evalCode(ctx, s.confirmScriptSHA, keys, int(reservationRetention.Seconds()))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return code, s.removeSettledExpiry(ctx, code, bookID, orderID)
+}
+
+// CancelReservation 取消预占（使用Lua脚本）
+//
+// 返回值含义：
+//
+//	0: 预占不存在
+//	1: 取消成功
+//	2: 重复取消
+//	3: 预占已确认
+func (s *InventoryStore) CancelReservation(ctx context.Context, bookID uint, orderID uint) (int, error) {
+	return s.cancelOnUnits(ctx, bookID, orderID, false)
+}
+
+// cancelOnUnits 查找预占记录所在的单元并取消（undo为撤销：删除记录，见cancel_reservation.lua）
+func (s *InventoryStore) cancelOnUnits(ctx context.Context, bookID, orderID uint, undo bool) (int, error) {
+	code, err := s.probeUnits(ctx, bookID, orderID, func(u stockUnit) (int, error) {
+		keys := []string{u.stockKey(), u.lockedKey(), u.recordKey("reserve", orderID), u.warehouseKey()}
+		return s.evalCode(ctx, s.cancelScriptSHA, keys, int(reservationRetention.Seconds()), boolArg(undo))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return code, s.removeSettledExpiry(ctx, code, bookID, orderID)
+}
+
+// removeSettledExpiry 预占已进入终态（确认/取消的结果码不为0）时移出过期队列
+//
+// 重复确认/取消也移出：上次脚本成功后进程可能在移出前退出
+func (s *InventoryStore) removeSettledExpiry(ctx context.Context, code int, bookID, orderID uint) error {
+	if code == 0 {
+		return nil
+	}
+	return s.RemoveReservationExpiry(ctx, ReservationRef{BookID: bookID, OrderID: orderID})
+}
+
+// ReservationRef 预占标识（图书ID + 订单ID）
+type ReservationRef struct {
+	BookID  uint
+	OrderID uint
+}
+
+// member 过期队列中的成员：{book_id}:{order_id}
+func (r ReservationRef) member() string {
+	return fmt.Sprintf("%d:%d", r.BookID, r.OrderID)
+}
+
+// addReservationExpiry 加入过期队列（已在队列中的保留原过期时间）
+func (s *InventoryStore) addReservationExpiry(ctx context.Context, expiresAt time.Time, refs ...ReservationRef) error {
+	members := make([]*redis.Z, len(refs))
+	for i, ref := range refs {
+		members[i] = &redis.Z{Score: float64(expiresAt.Unix()), Member: ref.member()}
+	}
+	if err := s.client.ZAddNX(ctx, reservationExpiryKey, members...).Err(); err != nil {
+		return fmt.Errorf("加入过期队列失败: %w", err)
+	}
+	return nil
+}
+
+// ListExpiredReservations 查询已过期的预占（按过期时间升序）
+func (s *InventoryStore) ListExpiredReservations(ctx context.Context, now time.Time, limit int) ([]ReservationRef, error) {
+	members, err := s.client.ZRangeByScore(ctx, reservationExpiryKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.Unix(), 10),
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("查询过期预占失败: %w", err)
+	}
+
+	refs := make([]ReservationRef, 0, len(members))
+	for _, m := range members {
+		var bookID, orderID uint
+		if _, err := fmt.Sscanf(m, "%d:%d", &bookID, &orderID); err != nil {
+			return nil, fmt.Errorf("解析过期预占失败[%s]: %w", m, err)
+		}
+		refs = append(refs, ReservationRef{BookID: bookID, OrderID: orderID})
+	}
+	return refs, nil
+}
+
+// RemoveReservationExpiry 从过期队列移除预占
+func (s *InventoryStore) RemoveReservationExpiry(ctx context.Context, refs ...ReservationRef) error {
+	members := make([]interface{}, len(refs))
+	for i, ref := range refs {
+		members[i] = ref.member()
+	}
+	if err := s.client.ZRem(ctx, reservationExpiryKey, members...).Err(); err != nil {
+		return fmt.Errorf("移除过期预占失败: %w", err)
+	}
+	return nil
+}
+
+// GetLockedStock 获取锁定库存（热点图书为主库存与各分片之和）
+func (s *InventoryStore) GetLockedStock(ctx context.Context, bookID uint) (int, error) {
+	snaps, err := s.readStocks(ctx, []uint{bookID})
+	if err != nil {
+		return 0, fmt.Errorf("获取锁定库存失败: %w", err)
+	}
+	return snaps[bookID].Locked, nil
+}
+
+// evalCode 执行返回状态码的Lua脚本
+func (s *InventoryStore) evalCode(ctx context.Context, sha string, keys []string, args ...interface{}) (int, error) {
+	result, err := s.client.EvalSha(ctx, sha, keys, args...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("执行Lua脚本失败: %w", err)
+	}
+
+	code, ok := result.(int64)
 	if !ok {
 		return 0, fmt.Errorf("脚本返回值类型错误: %T", result)
 	}
-
-	return int(newStock), nil
+	return int(code), nil
 }
 
 // BatchGetStock 批量获取库存
 //
 // 教学要点：
-// 1. 使用Pipeline批量查询（减少网络往返）
-// 2. 一次性查询多个库存
+// 1. 使用Pipeline读取全部图书（减少网络往返），热点图书再读一轮各分片求和
+// 2. 库存不存在的图书返回0
 func (s *InventoryStore) BatchGetStock(ctx context.Context, bookIDs []uint) (map[uint]int, error) {
+	snaps, err := s.readStocks(ctx, bookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("批量查询库存失败: %w", err)
+	}
+
+	result := make(map[uint]int, len(snaps))
+	for bookID, snap := range snaps {
+		result[bookID] = snap.Stock
+	}
+	return result, nil
+}
+
+// StockSnapshot Redis库存快照（对账用）
+type StockSnapshot struct {
+	Stock  int
+	Locked int
+	Exists bool // 库存key是否存在（不存在时Stock为0）
+}
+
+// BatchGetSnapshot 批量获取库存快照（可用库存 + 锁定库存）
+//
+// 与BatchGetStock不同：key不存在不会被当作0库存，对账需要区分"库存为0"和"数据丢失"
+func (s *InventoryStore) BatchGetSnapshot(ctx context.Context, bookIDs []uint) (map[uint]StockSnapshot, error) {
+	snaps, err := s.readStocks(ctx, bookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("批量查询库存快照失败: %w", err)
+	}
+	return snaps, nil
+}
+
+// readStocks 读取库存快照（使用Pipeline，兼容分片与未分片两种布局）
+//
+// 第一轮读取各图书的主库存、锁定库存和分片数上限，普通图书到此为止；
+// 热点图书第二轮读取各分片（含已下线但可能还有库存的分片）再求和。
+// 各单元在不同slot，读到的不是同一时刻的合计，对账和展示可以接受
+func (s *InventoryStore) readStocks(ctx context.Context, bookIDs []uint) (map[uint]StockSnapshot, error) {
+	result := make(map[uint]StockSnapshot, len(bookIDs))
 	if len(bookIDs) == 0 {
-		return make(map[uint]int), nil
+		return result, nil
 	}
 
-	// 使用Pipeline批量查询
+	type bookCmds struct {
+		stock, locked, maxShards *redis.StringCmd
+	}
 	pipe := s.client.Pipeline()
-	cmds := make(map[uint]*redis.StringCmd, len(bookIDs))
-
+	cmds := make(map[uint]bookCmds, len(bookIDs))
 	for _, bookID := range bookIDs {
-		key := s.stockKey(bookID)
-		cmds[bookID] = pipe.Get(ctx, key)
+		u := stockUnit{bookID: bookID}
+		cmds[bookID] = bookCmds{
+			stock:     pipe.Get(ctx, u.stockKey()),
+			locked:    pipe.Get(ctx, u.lockedKey()),
+			maxShards: pipe.Get(ctx, maxShardsKey(bookID)),
+		}
 	}
-
-	// 执行Pipeline
 	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
-		return nil, fmt.Errorf("批量查询库存失败: %w", err)
+		return nil, fmt.Errorf("读取库存失败: %w", err)
 	}
 
-	// 解析结果
-	result := make(map[uint]int, len(bookIDs))
-	for bookID, cmd := range cmds {
-		val, err := cmd.Result()
+	var shardUnits []stockUnit
+	for bookID, c := range cmds {
+		stock, exists, err := intResult(c.stock)
 		if err != nil {
-			if err == redis.Nil {
-				result[bookID] = 0 // 库存不存在，返回0
-				continue
-			}
-			// 单个查询失败不影响整体
-			result[bookID] = 0
-			continue
+			return nil, err
 		}
-
-		stock, err := strconv.Atoi(val)
+		locked, _, err := intResult(c.locked)
 		if err != nil {
-			result[bookID] = 0
-			continue
+			return nil, err
+		}
+		maxShards, _, err := intResult(c.maxShards)
+		if err != nil {
+			return nil, err
 		}
 
-		result[bookID] = stock
+		result[bookID] = StockSnapshot{Stock: stock, Locked: locked, Exists: exists}
+		shardUnits = append(shardUnits, bookUnits(bookID, maxShards)[1:]...)
+	}
+	if len(shardUnits) == 0 {
+		return result, nil
+	}
+
+	pipe = s.client.Pipeline()
+	stockCmds := make([]*redis.StringCmd, len(shardUnits))
+	lockedCmds := make([]*redis.StringCmd, len(shardUnits))
+	for i, u := range shardUnits {
+		stockCmds[i] = pipe.Get(ctx, u.stockKey())
+		lockedCmds[i] = pipe.Get(ctx, u.lockedKey())
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("读取分片库存失败: %w", err)
 	}
 
+	for i, u := range shardUnits {
+		stock, _, err := intResult(stockCmds[i])
+		if err != nil {
+			return nil, err
+		}
+		locked, _, err := intResult(lockedCmds[i])
+		if err != nil {
+			return nil, err
+		}
+		snap := result[u.bookID]
+		snap.Stock += stock
+		snap.Locked += locked
+		result[u.bookID] = snap
+	}
 	return result, nil
 }
 
-// stockKey 生成库存键
-// 格式：stock:{book_id}
-func (s *InventoryStore) stockKey(bookID uint) string {
-	return fmt.Sprintf("stock:%d", bookID)
+// intResult 读取整数结果，key不存在时返回0和false
+func intResult(cmd *redis.StringCmd) (int, bool, error) {
+	v, err := cmd.Int()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("读取库存失败: %w", err)
+	}
+	return v, true, nil
+}
+
+// CompareAndSetStock 库存仍等于快照时设置为目标值（对账修复），返回是否修复
+//
+// 热点图书先收回各分片的库存，比较和设置只作用于主库存：
+// 期望值和目标值都减去各分片剩余的部分（收回后通常只剩锁定库存），目标值不够分时放弃修复
+func (s *InventoryStore) CompareAndSetStock(ctx context.Context, bookID uint, expect StockSnapshot, stock, locked int) (bool, error) {
+	otherStock, otherLocked, err := s.recallForAdmin(ctx, bookID)
+	if err != nil {
+		return false, err
+	}
+	stock -= otherStock
+	locked -= otherLocked
+	if stock < 0 || locked < 0 {
+		return false, nil
+	}
+
+	expectStock := ""
+	if expect.Exists {
+		expectStock = strconv.Itoa(expect.Stock - otherStock)
+	}
+
+	u := stockUnit{bookID: bookID}
+	keys := []string{u.stockKey(), u.lockedKey()}
+	code, err := s.evalCode(ctx, s.reconcileSHA, keys, expectStock, expect.Locked-otherLocked, stock, locked)
+	if err != nil {
+		return false, err
+	}
+	return code == 1, nil
+}
+
+// recallForAdmin 管理操作前收回热点图书各分片的可用库存，返回各分片剩余的可用库存和锁定库存合计
+//
+// 设置、调整、盘点、调拨、对账修复都只作用于主库存；收回与之后的操作不是原子的，
+// 期间分片上的释放会让分片重新有少量库存，由下一次再平衡收回
+func (s *InventoryStore) recallForAdmin(ctx context.Context, bookID uint) (int, int, error) {
+	maxShards, err := s.maxShards(ctx, bookID)
+	if err != nil || maxShards == 0 {
+		return 0, 0, err
+	}
+	if _, err := s.recallShards(ctx, bookID); err != nil {
+		return 0, 0, err
+	}
+
+	pipe := s.client.Pipeline()
+	units := bookUnits(bookID, maxShards)[1:]
+	stockCmds := make([]*redis.StringCmd, len(units))
+	lockedCmds := make([]*redis.StringCmd, len(units))
+	for i, u := range units {
+		stockCmds[i] = pipe.Get(ctx, u.stockKey())
+		lockedCmds[i] = pipe.Get(ctx, u.lockedKey())
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, 0, fmt.Errorf("读取分片库存失败: %w", err)
+	}
+
+	otherStock, otherLocked := 0, 0
+	for i := range units {
+		stock, _, err := intResult(stockCmds[i])
+		if err != nil {
+			return 0, 0, err
+		}
+		locked, _, err := intResult(lockedCmds[i])
+		if err != nil {
+			return 0, 0, err
+		}
+		otherStock += stock
+		otherLocked += locked
+	}
+	return otherStock, otherLocked, nil
 }
 
-// DeleteStock 删除库存（用于测试）
+// DeleteStock 删除库存（用于测试，热点图书连同分片和布局一起删除）
 func (s *InventoryStore) DeleteStock(ctx context.Context, bookID uint) error {
-	key := s.stockKey(bookID)
-	return s.client.Del(ctx, key).Err()
+	maxShards, err := s.maxShards(ctx, bookID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, u := range bookUnits(bookID, maxShards) {
+			pipe.Del(ctx, u.stockKey(), u.layoutKey())
+		}
+		pipe.Del(ctx, maxShardsKey(bookID))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("删除库存失败: %w", err)
+	}
+	s.setShardHint(bookID, 0)
+	return nil
 }
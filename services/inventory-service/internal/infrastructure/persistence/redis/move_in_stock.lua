@@ -0,0 +1,29 @@
+-- move_in_stock.lua
+-- 库存单元迁入Lua脚本（见move_out_stock.lua）
+--
+-- 教学要点：迁入标记保证同一笔迁移只加一次（崩溃恢复时可能重复迁入）
+--
+-- KEYS[1]: 可用库存键
+-- KEYS[2]: 仓库库存键（warehouse:{单元}）
+-- KEYS[3]: 迁入标记键（moved:{单元}:{迁移ID}）
+-- ARGV[1]: 迁入数量
+-- ARGV[2]: 仓库分配串
+-- ARGV[3]: 迁入标记保留时间（秒）
+--
+-- 返回值：
+-- 0: 已迁入过（幂等性）
+-- 1: 迁入成功
+
+local stock_key = KEYS[1]
+local wh_key = KEYS[2]
+local marker_key = KEYS[3]
+
+if redis.call('EXISTS', marker_key) == 1 then
+    return 0
+end
+
+redis.call('INCRBY', stock_key, tonumber(ARGV[1]))
+wh_restore(wh_key, ARGV[2])
+redis.call('SETEX', marker_key, tonumber(ARGV[3]), 1)
+
+return 1
@@ -0,0 +1,47 @@
+-- move_out_stock.lua
+-- 库存单元迁出Lua脚本（分片再平衡、收回分片库存的第一步）
+--
+-- 教学要点：
+-- 1. 两个库存单元在不同slot，一次迁移拆成迁出、迁入两个脚本
+--    - 迁出：从本单元取出库存（连同仓库归属），在本单元的迁移表中记下这笔迁移
+--    - 迁入：目标单元按迁移ID幂等地加上库存（move_in_stock.lua）
+--    - 迁入完成后调用方删除迁移表中的记录；进程在中间崩溃时，下次再平衡按迁移表补做迁入
+--
+-- 2. 取出数量不超过当前可用库存：与并发扣减交错执行也不会扣成负数
+-- 3. 先取未分配库存，不够再从各仓库取，保证剩余的可用库存不少于仓库合计
+--
+-- KEYS[1]: 可用库存键
+-- KEYS[2]: 仓库库存键（warehouse:{单元}）
+-- KEYS[3]: 迁移表键（moves:{单元}，Hash，field为迁移ID）
+-- ARGV[1]: 迁移ID
+-- ARGV[2]: 迁出数量（-1为全部）
+-- ARGV[3]: 目标单元的分片号（0为主库存）
+--
+-- 返回值：{迁出数量, 仓库分配串}
+
+local stock_key = KEYS[1]
+local wh_key = KEYS[2]
+local moves_key = KEYS[3]
+local move_id = ARGV[1]
+local quantity = tonumber(ARGV[2])
+local target = ARGV[3]
+
+local available = tonumber(redis.call('GET', stock_key) or 0)
+local take = available
+if quantity >= 0 and quantity < available then
+    take = quantity
+end
+if take <= 0 then
+    return {0, ""}
+end
+
+local unallocated = math.max(available - wh_total(wh_key), 0)
+local allocation = ""
+if take > unallocated then
+    allocation = wh_take(wh_key, take - unallocated)
+end
+
+redis.call('DECRBY', stock_key, take)
+redis.call('HSET', moves_key, move_id, target .. "|" .. take .. "|" .. allocation)
+
+return {take, allocation}
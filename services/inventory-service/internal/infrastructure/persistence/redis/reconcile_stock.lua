@@ -0,0 +1,43 @@
+-- reconcile_stock.lua
+-- 对账修复Lua脚本（比较并设置）
+--
+-- 教学要点：
+-- 1. 对账读取快照与修复之间可能有新的扣减/释放
+-- 2. 只有库存仍等于对账时读到的值才覆盖，否则放弃（下一轮再对账）
+-- 3. 可用库存和锁定库存在同一个脚本中设置，保证二者一致
+-- 4. 只作用于主库存：热点图书由调用方先收回分片库存，期望值和目标值都已减去各分片的部分
+--
+-- KEYS[1]: 库存键（stock:{book_id}）
+-- KEYS[2]: 锁定库存键（locked:{stock:{book_id}}）
+-- ARGV[1]: 期望的可用库存（空字符串表示期望key不存在）
+-- ARGV[2]: 期望的锁定库存
+-- ARGV[3]: 修复后的可用库存
+-- ARGV[4]: 修复后的锁定库存
+--
+-- 返回值：
+-- 0: 库存已变化，未修复
+-- 1: 修复成功
+
+local stock_key = KEYS[1]
+local locked_key = KEYS[2]
+local expect_stock = ARGV[1]
+local expect_locked = tonumber(ARGV[2])
+
+local current = redis.call('GET', stock_key)
+if expect_stock == "" then
+    if current then
+        return 0
+    end
+elseif not current or tonumber(current) ~= tonumber(expect_stock) then
+    return 0
+end
+
+local current_locked = tonumber(redis.call('GET', locked_key) or 0)
+if current_locked ~= expect_locked then
+    return 0
+end
+
+redis.call('SET', stock_key, ARGV[3])
+redis.call('SET', locked_key, ARGV[4])
+
+return 1
@@ -11,9 +11,17 @@
 --    - 检查订单是否已释放
 --    - 防止重复释放导致库存虚增
 --
--- KEYS[1]: 库存键（stock:book_id）
+-- 3. 库存退回扣减记录所在的库存单元（调用方逐个单元查找扣减记录）
+--
+-- 4. 撤销模式：批量扣减逐本执行时，后面的图书不足需要撤销前面已扣减的图书
+--    - 撤销相当于没扣过：不写释放记录，同一订单重试时可以重新扣减
+--
+-- KEYS[1]: 可用库存键
+-- KEYS[2]: 仓库库存键（warehouse:{单元}）
+-- KEYS[3]: 扣减记录键（deduct:{单元}:{order_id}）
+-- KEYS[4]: 释放记录键（release:{单元}:{order_id}）
 -- ARGV[1]: 释放数量
--- ARGV[2]: 订单ID
+-- ARGV[2]: 是否为撤销（1为撤销）
 --
 -- 返回值：
 -- 0: 失败
@@ -21,11 +29,13 @@
 -- 2: 重复释放（幂等性）
 
 local stock_key = KEYS[1]
+local wh_key = KEYS[2]
+local deduct_record_key = KEYS[3]
+local release_record_key = KEYS[4]
 local quantity = tonumber(ARGV[1])
-local order_id = ARGV[2]
+local undo = ARGV[2] == "1"
 
 -- 幂等性检查（订单是否已释放）
-local release_record_key = "release:" .. stock_key .. ":" .. order_id
 local is_released = redis.call('EXISTS', release_record_key)
 
 if is_released == 1 then
@@ -33,23 +43,25 @@ if is_released == 1 then
     return 2
 end
 
--- 检查订单是否已扣减
-local deduct_record_key = "deduct:" .. stock_key .. ":" .. order_id
-local is_deducted = redis.call('EXISTS', deduct_record_key)
+-- 检查订单是否已扣减（跳过标记表示没有在这个单元扣减）
+local allocation = redis.call('GET', deduct_record_key)
 
-if is_deducted == 0 then
+if not allocation or allocation == UNIT_SKIP then
     -- 订单未扣减，无需释放
     return 0
 end
 
--- 增加库存（释放）
+-- 增加库存（释放），并按扣减时的仓库分配退回各仓库
 redis.call('INCRBY', stock_key, quantity)
+wh_restore(wh_key, allocation)
 
 -- 删除扣减记录
 redis.call('DEL', deduct_record_key)
 
--- 记录已释放（有效期1小时）
-redis.call('SETEX', release_record_key, 3600, '1')
+-- 记录已释放（有效期1小时），保留分配串供MySQL同步仓库明细
+if not undo then
+    redis.call('SETEX', release_record_key, 3600, allocation)
+end
 
 -- 释放成功，返回1
 return 1
@@ -0,0 +1,76 @@
+-- reserve_stock.lua
+-- 库存预占Lua脚本（原子操作，两阶段扣减的第一阶段）
+--
+-- 教学要点：
+-- 1. 可用库存 → 锁定库存
+--    - 扣减 stock:{book_id}（热点图书扣减一个分片，见unit_lib.lua）
+--    - INCRBY locked:{stock:{book_id}}
+--
+-- 2. 预占记录（Hash）
+--    - reserve:{stock:{book_id}}:{order_id} → quantity/status/alloc（alloc为仓库分配串）
+--    - 未确认前不设置过期时间：过期由ZSET + 过期任务处理，保证锁定库存一定能退回
+--
+-- 3. 过期队列（ZSET）不在脚本中写入
+--    - 过期队列是全局key，与各库存单元不在同一个slot，Redis Cluster下不能在同一脚本中访问
+--    - 调用方预占前先加入过期队列，库存不足时再移出：预占成功的记录一定在队列中
+--
+-- KEYS[1]: 可用库存键
+-- KEYS[2]: 分片布局键（{单元}:shards）
+-- KEYS[3]: 锁定库存键（locked:{单元}）
+-- KEYS[4]: 预占记录键（reserve:{单元}:{order_id}）
+-- KEYS[5]: 仓库库存键（warehouse:{单元}）
+-- ARGV[1]: 预占数量
+-- ARGV[2]: 调用方看到的分片数（与单元上的布局不符时不预占）
+-- ARGV[3]: 优先仓库ID（0为不指定）
+-- ARGV[4]: 仓库兜底顺序（逗号分隔）
+-- ARGV[5]: 不足时是否写入跳过标记（分片为1，主库存为0）
+--
+-- 返回值：
+-- -1: 布局不符（调用方刷新分片数后重试）
+-- 0: 库存不足
+-- 1: 预占成功
+-- 2: 重复预占（幂等性）
+
+local stock_key = KEYS[1]
+local layout_key = KEYS[2]
+local locked_key = KEYS[3]
+local reserve_key = KEYS[4]
+local wh_key = KEYS[5]
+local quantity = tonumber(ARGV[1])
+local shards = ARGV[2]
+local preferred_warehouse = ARGV[3]
+local warehouse_priority = ARGV[4]
+local mark_skip = ARGV[5] == "1"
+
+-- 幂等性检查（订单是否已预占，无论当前状态）
+local status = redis.call('HGET', reserve_key, 'status')
+if status == UNIT_SKIPPED then
+    return 0
+end
+if status then
+    return 2
+end
+
+if not unit_layout_ok(layout_key, shards) then
+    return -1
+end
+
+-- 检查可用库存
+local current_stock = tonumber(redis.call('GET', stock_key) or 0)
+if current_stock < quantity then
+    if mark_skip then
+        redis.call('HSET', reserve_key, 'status', UNIT_SKIPPED)
+        redis.call('EXPIRE', reserve_key, UNIT_SKIP_TTL)
+    end
+    return 0
+end
+
+-- 可用库存 → 锁定库存
+redis.call('DECRBY', stock_key, quantity)
+redis.call('INCRBY', locked_key, quantity)
+
+-- 记录预占
+local allocation = wh_allocate(wh_key, quantity, preferred_warehouse, warehouse_priority)
+redis.call('HSET', reserve_key, 'quantity', quantity, 'status', 'RESERVED', 'alloc', allocation)
+
+return 1
@@ -6,16 +6,30 @@
 --    - 管理员补货
 --    - 退货入库
 --
--- KEYS[1]: 库存键（stock:book_id）
+-- 2. 补货必须落到具体仓库：可售库存和仓库库存同时增加
+-- 3. 补货只加到主库存：热点图书由分片再平衡下发到各分片，分片不足时扣减也会回退到主库存
+--
+-- KEYS[1]: 库存键（stock:{book_id}）
+-- KEYS[2]: 仓库库存键（warehouse:{stock:{book_id}}）
+-- KEYS[3]: 分片数上限键（{stock:{book_id}}:max_shards，调用方据此判断是否需要加上各分片）
 -- ARGV[1]: 补充数量
+-- ARGV[2]: 入库仓库ID（0表示不区分仓库，计入未分配库存）
 --
--- 返回值：补充后的库存数量
+-- 返回值：{补充后的主库存数量, 分片数上限}
 
 local stock_key = KEYS[1]
+local wh_key = KEYS[2]
+local max_shards_key = KEYS[3]
 local quantity = tonumber(ARGV[1])
+local warehouse_id = ARGV[2]
 
 -- 增加库存
 local new_stock = redis.call('INCRBY', stock_key, quantity)
 
+-- 增加仓库库存
+if warehouse_id ~= nil and warehouse_id ~= "0" then
+    redis.call('HINCRBY', wh_key, warehouse_id, quantity)
+end
+
 -- 返回新库存
-return new_stock
+return {new_stock, tonumber(redis.call('GET', max_shards_key) or 0)}
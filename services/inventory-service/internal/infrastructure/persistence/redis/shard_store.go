@@ -0,0 +1,267 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// movedMarkerTTL 迁入标记的保留时间：覆盖崩溃后到下一次再平衡补做迁入的间隔
+const movedMarkerTTL = 24 * time.Hour
+
+// ShardStock 设置热点图书的库存分片数并重新均分
+//
+// 教学要点：
+// 1. shards > 1：主库存的库存下发到shards个分片（已是该分片数时只做再平衡）
+// 2. shards <= 1：各分片的库存收回到主库存（合并）
+// 3. 库存不存在（尚未预热）时不处理，exists返回false
+// 4. 各单元在不同slot，不能在一个脚本中完成：
+//   - 先更新布局（主库存上的分片数和各分片的副本），再逐个单元迁移库存（见move_out_stock.lua）
+//   - 迁移期间扣减照常进行：迁出数量不超过当前可用库存，合计始终不变
+//   - 布局不符的扣减由调用方刷新分片数后重试（见unit_lib.lua）
+//
+// 注意：调整分片数会改变订单经过各单元的顺序，调整前已在旧分片扣减、调整后才重试的同一订单，
+// 新顺序可能先遇到另一个有库存的单元；分片数只随配置变更，应在低峰期调整，各实例的配置须一致
+//
+// 返回处理后的可用库存（合计）
+func (s *InventoryStore) ShardStock(ctx context.Context, bookID uint, shards int) (stock int, exists bool, err error) {
+	reservoir := stockUnit{bookID: bookID}
+
+	n, err := s.client.Exists(ctx, reservoir.stockKey()).Result()
+	if err != nil {
+		return 0, false, fmt.Errorf("库存分片失败: %w", err)
+	}
+	if n == 0 {
+		s.setShardHint(bookID, 0)
+		return 0, false, nil
+	}
+
+	if shards <= 1 {
+		shards = 0
+	}
+
+	maxShards, err := s.maxShards(ctx, bookID)
+	if err != nil {
+		return 0, false, err
+	}
+
+	// 补做上次中断的迁移
+	if err := s.recoverMoves(ctx, bookID, maxShards); err != nil {
+		return 0, false, err
+	}
+
+	if shards > maxShards {
+		maxShards = shards
+	}
+	if err := s.writeLayout(ctx, bookID, shards, maxShards); err != nil {
+		return 0, false, err
+	}
+	s.setShardHint(bookID, shards)
+
+	if err := s.rebalance(ctx, bookID, shards, maxShards); err != nil {
+		return 0, false, err
+	}
+
+	stock, err = s.GetStock(ctx, bookID)
+	if err != nil {
+		return 0, false, err
+	}
+	return stock, true, nil
+}
+
+// writeLayout 写入分片布局：分片数上限、各分片的分片数副本（下线的分片删除副本）、主库存上的分片数
+func (s *InventoryStore) writeLayout(ctx context.Context, bookID uint, shards, maxShards int) error {
+	reservoir := stockUnit{bookID: bookID}
+
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, maxShardsKey(bookID), maxShards, 0)
+		for i := 1; i <= maxShards; i++ {
+			u := stockUnit{bookID: bookID, shard: i}
+			if i <= shards {
+				pipe.Set(ctx, u.layoutKey(), shards, 0)
+			} else {
+				pipe.Del(ctx, u.layoutKey())
+			}
+		}
+		if shards > 0 {
+			pipe.Set(ctx, reservoir.layoutKey(), shards, 0)
+		} else {
+			pipe.Del(ctx, reservoir.layoutKey())
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("更新库存分片布局失败: %w", err)
+	}
+	return nil
+}
+
+// rebalance 按合计重新均分：主库存和已下线的分片目标为0，在线分片均分（余数放在前面的分片）
+//
+// 多出的库存先迁回主库存，再从主库存下发给不足的分片
+func (s *InventoryStore) rebalance(ctx context.Context, bookID uint, shards, maxShards int) error {
+	units := bookUnits(bookID, maxShards)
+	available, err := s.unitStocks(ctx, units)
+	if err != nil {
+		return err
+	}
+
+	total := 0
+	for _, v := range available {
+		if v > 0 {
+			total += v
+		}
+	}
+
+	target := make([]int, len(units))
+	if shards > 0 {
+		for i := 1; i <= shards; i++ {
+			target[i] = total / shards
+			if i <= total%shards {
+				target[i]++
+			}
+		}
+	} else {
+		target[0] = total
+	}
+
+	reservoir := units[0]
+	for i := 1; i < len(units); i++ {
+		if available[i] > target[i] {
+			if _, err := s.moveStock(ctx, units[i], reservoir, available[i]-target[i]); err != nil {
+				return err
+			}
+		}
+	}
+	for i := 1; i < len(units); i++ {
+		if available[i] < target[i] {
+			if _, err := s.moveStock(ctx, reservoir, units[i], target[i]-available[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// recallShards 把各分片的可用库存全部收回到主库存，返回收回的数量
+//
+// 用于临近售罄（各分片都不足）和管理操作（设置、调整、盘点、调拨、对账修复只作用于主库存）
+func (s *InventoryStore) recallShards(ctx context.Context, bookID uint) (int, error) {
+	maxShards, err := s.maxShards(ctx, bookID)
+	if err != nil || maxShards == 0 {
+		return 0, err
+	}
+
+	units := bookUnits(bookID, maxShards)
+	available, err := s.unitStocks(ctx, units)
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for i := 1; i < len(units); i++ {
+		if available[i] <= 0 {
+			continue
+		}
+		n, err := s.moveStock(ctx, units[i], units[0], -1)
+		if err != nil {
+			return moved, err
+		}
+		moved += n
+	}
+	return moved, nil
+}
+
+// moveStock 从一个库存单元迁移quantity件（-1为全部）到同一本书的另一个单元，返回实际迁移的数量
+func (s *InventoryStore) moveStock(ctx context.Context, from, to stockUnit, quantity int) (int, error) {
+	moveID := fmt.Sprintf("%d-%d", time.Now().UnixNano(), rand.Int63())
+
+	keys := []string{from.stockKey(), from.warehouseKey(), from.movesKey()}
+	result, err := s.client.EvalSha(ctx, s.moveOutSHA, keys, moveID, quantity, to.shard).Result()
+	if err != nil {
+		return 0, fmt.Errorf("迁出库存失败: %w", err)
+	}
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 2 {
+		return 0, fmt.Errorf("迁出脚本返回值错误: %v", result)
+	}
+	moved, ok1 := values[0].(int64)
+	allocation, ok2 := values[1].(string)
+	if !ok1 || !ok2 {
+		return 0, fmt.Errorf("迁出脚本返回值错误: %v", result)
+	}
+	if moved == 0 {
+		return 0, nil
+	}
+
+	if err := s.finishMove(ctx, from, to, moveID, int(moved), allocation); err != nil {
+		return 0, err
+	}
+	return int(moved), nil
+}
+
+// finishMove 迁入目标单元并删除迁出单元上的迁移记录
+func (s *InventoryStore) finishMove(ctx context.Context, from, to stockUnit, moveID string, quantity int, allocation string) error {
+	keys := []string{to.stockKey(), to.warehouseKey(), to.movedKey(moveID)}
+	if _, err := s.evalCode(ctx, s.moveInSHA, keys, quantity, allocation, int(movedMarkerTTL.Seconds())); err != nil {
+		return fmt.Errorf("迁入库存失败: %w", err)
+	}
+	if err := s.client.HDel(ctx, from.movesKey(), moveID).Err(); err != nil {
+		return fmt.Errorf("删除迁移记录失败: %w", err)
+	}
+	return nil
+}
+
+// recoverMoves 补做各单元迁移表中未完成的迁入（进程在迁出与迁入之间崩溃）
+func (s *InventoryStore) recoverMoves(ctx context.Context, bookID uint, maxShards int) error {
+	for _, from := range bookUnits(bookID, maxShards) {
+		moves, err := s.client.HGetAll(ctx, from.movesKey()).Result()
+		if err != nil {
+			return fmt.Errorf("查询迁移记录失败: %w", err)
+		}
+		for moveID, record := range moves {
+			// 记录格式："目标分片号|数量|仓库分配串"
+			parts := strings.SplitN(record, "|", 3)
+			if len(parts) != 3 {
+				return fmt.Errorf("无效的迁移记录: %q", record)
+			}
+			shard, err1 := strconv.Atoi(parts[0])
+			quantity, err2 := strconv.Atoi(parts[1])
+			if err1 != nil || err2 != nil {
+				return fmt.Errorf("无效的迁移记录: %q", record)
+			}
+			to := stockUnit{bookID: bookID, shard: shard}
+			if err := s.finishMove(ctx, from, to, moveID, quantity, parts[2]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// unitStocks 使用Pipeline读取各单元的可用库存（key不存在为0）
+func (s *InventoryStore) unitStocks(ctx context.Context, units []stockUnit) ([]int, error) {
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(units))
+	for i, u := range units {
+		cmds[i] = pipe.Get(ctx, u.stockKey())
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("读取库存失败: %w", err)
+	}
+
+	values := make([]int, len(units))
+	for i, cmd := range cmds {
+		v, err := cmd.Int()
+		if err != nil && err != redis.Nil {
+			return nil, fmt.Errorf("读取库存失败: %w", err)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
@@ -0,0 +1,355 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+)
+
+// newTestStore 创建连接内存Redis（miniredis，支持Lua脚本）的库存存储
+func newTestStore(t *testing.T, mr *miniredis.Miniredis) *InventoryStore {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	s := NewInventoryStore(client)
+	if err := s.LoadScripts(context.Background()); err != nil {
+		t.Fatalf("加载脚本失败: %v", err)
+	}
+	return s
+}
+
+// shardValues 读取各分片的库存
+func shardValues(t *testing.T, mr *miniredis.Miniredis, bookID uint, n int) []int {
+	t.Helper()
+
+	values := make([]int, n)
+	for i := 1; i <= n; i++ {
+		v, err := mr.Get(stockUnit{bookID: bookID, shard: i}.stockKey())
+		if err != nil {
+			t.Fatalf("读取分片%d失败: %v", i, err)
+		}
+		values[i-1], _ = strconv.Atoi(v)
+	}
+	return values
+}
+
+// setShards 直接设置各分片的库存（构造分片不均衡的场景）
+func setShards(t *testing.T, mr *miniredis.Miniredis, bookID uint, values ...int) {
+	t.Helper()
+
+	for i, v := range values {
+		if err := mr.Set(stockUnit{bookID: bookID, shard: i + 1}.stockKey(), strconv.Itoa(v)); err != nil {
+			t.Fatalf("设置分片%d失败: %v", i+1, err)
+		}
+	}
+}
+
+// mustStock 查询库存合计
+func mustStock(t *testing.T, s *InventoryStore, bookID uint) int {
+	t.Helper()
+
+	stock, err := s.GetStock(context.Background(), bookID)
+	if err != nil {
+		t.Fatalf("查询库存失败: %v", err)
+	}
+	return stock
+}
+
+// TestStockUnit_Keys 测试同一库存单元的key共用hash tag，各分片使用各自的hash tag
+func TestStockUnit_Keys(t *testing.T) {
+	reservoir := stockUnit{bookID: 123}
+	shard := stockUnit{bookID: 123, shard: 2}
+
+	if got := reservoir.stockKey(); got != "stock:123" {
+		t.Errorf("主库存key应与分片之前相同，实际%s", got)
+	}
+	if got := shard.stockKey(); got != "{stock:123:shard:2}" {
+		t.Errorf("分片key错误: %s", got)
+	}
+	if got := reservoir.recordKey("deduct", 9); got != "deduct:{stock:123}:9" {
+		t.Errorf("扣减记录key错误: %s", got)
+	}
+
+	for _, u := range []stockUnit{reservoir, shard} {
+		slot := keySlot(u.stockKey())
+		for _, key := range []string{u.lockedKey(), u.warehouseKey(), u.layoutKey(), u.movesKey(), u.recordKey("reserve", 9), u.movedKey("m1")} {
+			if keySlot(key) != slot {
+				t.Errorf("%s与%s应在同一slot", key, u.stockKey())
+			}
+		}
+	}
+	if keySlot(maxShardsKey(123)) != keySlot(reservoir.stockKey()) {
+		t.Error("分片数上限key应与主库存在同一slot")
+	}
+	if shard.stockKey() == (stockUnit{bookID: 123, shard: 1}).stockKey() {
+		t.Error("不同分片的key不应相同")
+	}
+}
+
+// TestShardStock_SplitRebalanceMerge 测试拆分、再平衡、合并时库存合计不变
+func TestShardStock_SplitRebalanceMerge(t *testing.T) {
+	mr := miniredis.RunT(t)
+	s := newTestStore(t, mr)
+	ctx := context.Background()
+
+	// 尚未预热的图书不分片
+	if _, exists, err := s.ShardStock(ctx, 1, 4); err != nil || exists {
+		t.Fatalf("未预热的图书不应分片: exists=%v err=%v", exists, err)
+	}
+
+	if err := s.SetStock(ctx, 1, 10); err != nil {
+		t.Fatalf("设置库存失败: %v", err)
+	}
+
+	// 拆分：10 → 3,3,2,2，主库存全部下发
+	stock, exists, err := s.ShardStock(ctx, 1, 4)
+	if err != nil || !exists || stock != 10 {
+		t.Fatalf("拆分失败: stock=%d exists=%v err=%v", stock, exists, err)
+	}
+	if v, _ := mr.Get("stock:1"); v != "0" {
+		t.Errorf("拆分后主库存应为0，实际%s", v)
+	}
+	if got := shardValues(t, mr, 1, 4); got[0] != 3 || got[1] != 3 || got[2] != 2 || got[3] != 2 {
+		t.Errorf("拆分后各分片应为[3 3 2 2]，实际%v", got)
+	}
+
+	// 再平衡：不均衡的分片按合计重新均分
+	setShards(t, mr, 1, 0, 0, 1, 7)
+	if stock, _, err := s.ShardStock(ctx, 1, 4); err != nil || stock != 8 {
+		t.Fatalf("再平衡失败: stock=%d err=%v", stock, err)
+	}
+	if got := shardValues(t, mr, 1, 4); got[0] != 2 || got[1] != 2 || got[2] != 2 || got[3] != 2 {
+		t.Errorf("再平衡后各分片应为[2 2 2 2]，实际%v", got)
+	}
+
+	// 补货加到主库存上，合计正确
+	if newStock, err := s.RestockInventory(ctx, 1, 5, 0); err != nil || newStock != 13 {
+		t.Fatalf("补货失败: stock=%d err=%v", newStock, err)
+	}
+	if v, _ := mr.Get("stock:1"); v != "5" {
+		t.Errorf("补货后主库存应为5，实际%s", v)
+	}
+
+	// 合并：各分片的库存回到stock:1，分片布局删除
+	if stock, _, err := s.ShardStock(ctx, 1, 1); err != nil || stock != 13 {
+		t.Fatalf("合并失败: stock=%d err=%v", stock, err)
+	}
+	if got := shardValues(t, mr, 1, 4); got[0] != 0 || got[1] != 0 || got[2] != 0 || got[3] != 0 {
+		t.Errorf("合并后各分片应为0，实际%v", got)
+	}
+	for i := 0; i <= 4; i++ {
+		if mr.Exists(stockUnit{bookID: 1, shard: i}.layoutKey()) {
+			t.Errorf("合并后不应保留单元%d的分片数", i)
+		}
+	}
+	if v, _ := mr.Get("stock:1"); v != "13" {
+		t.Errorf("合并后stock:1应为13，实际%s", v)
+	}
+}
+
+// TestDeductStock_SingleShard 测试热点图书每次扣减只改动一个分片
+func TestDeductStock_SingleShard(t *testing.T) {
+	mr := miniredis.RunT(t)
+	s := newTestStore(t, mr)
+	ctx := context.Background()
+
+	if err := s.SetStock(ctx, 1, 40); err != nil {
+		t.Fatalf("设置库存失败: %v", err)
+	}
+	if _, _, err := s.ShardStock(ctx, 1, 4); err != nil {
+		t.Fatalf("拆分失败: %v", err)
+	}
+
+	code, err := s.DeductStock(ctx, 1, 3, 100, 0)
+	if err != nil || code != 1 {
+		t.Fatalf("扣减失败: code=%d err=%v", code, err)
+	}
+
+	changed := 0
+	for _, v := range shardValues(t, mr, 1, 4) {
+		switch v {
+		case 10:
+		case 7:
+			changed++
+		default:
+			t.Errorf("分片库存应为10或7，实际%d", v)
+		}
+	}
+	if changed != 1 {
+		t.Errorf("应只有一个分片被扣减，实际%d个", changed)
+	}
+	if got := mustStock(t, s, 1); got != 37 {
+		t.Errorf("扣减后库存应为37，实际%d", got)
+	}
+
+	// 同一订单重复扣减
+	if code, err := s.DeductStock(ctx, 1, 3, 100, 0); err != nil || code != 2 {
+		t.Errorf("重复扣减应返回2: code=%d err=%v", code, err)
+	}
+	if got := mustStock(t, s, 1); got != 37 {
+		t.Errorf("重复扣减后库存应仍为37，实际%d", got)
+	}
+}
+
+// TestDeductStock_ShardFallback 测试分片不足时换其他分片，各分片都不足时收回到主库存再扣减
+func TestDeductStock_ShardFallback(t *testing.T) {
+	mr := miniredis.RunT(t)
+	s := newTestStore(t, mr)
+	ctx := context.Background()
+
+	if err := s.SetStock(ctx, 1, 0); err != nil {
+		t.Fatalf("设置库存失败: %v", err)
+	}
+	if _, _, err := s.ShardStock(ctx, 1, 3); err != nil {
+		t.Fatalf("拆分失败: %v", err)
+	}
+
+	// 只有一个分片够：无论随机从哪个分片开始，都要扣到第3个分片上
+	setShards(t, mr, 1, 0, 0, 5)
+	if code, err := s.DeductStock(ctx, 1, 4, 101, 0); err != nil || code != 1 {
+		t.Fatalf("扣减失败: code=%d err=%v", code, err)
+	}
+	if got := shardValues(t, mr, 1, 3); got[0] != 0 || got[1] != 0 || got[2] != 1 {
+		t.Errorf("扣减后各分片应为[0 0 1]，实际%v", got)
+	}
+
+	// 每个分片都不够，但合计够：收回到主库存后扣减
+	setShards(t, mr, 1, 2, 2, 2)
+	if code, err := s.DeductStock(ctx, 1, 5, 102, 0); err != nil || code != 1 {
+		t.Fatalf("收回分片后扣减失败: code=%d err=%v", code, err)
+	}
+	if got := mustStock(t, s, 1); got != 1 {
+		t.Errorf("收回分片后扣减，库存应为1，实际%d", got)
+	}
+	if v, _ := mr.Get("stock:1"); v != "1" {
+		t.Errorf("收回后主库存应为1，实际%s", v)
+	}
+
+	// 分片补足后同一订单重试：跳过标记让重试经过这些分片，在主库存上发现已扣减
+	setShards(t, mr, 1, 5, 5, 5)
+	if code, err := s.DeductStock(ctx, 1, 5, 102, 0); err != nil || code != 2 {
+		t.Fatalf("重试应返回重复扣减: code=%d err=%v", code, err)
+	}
+	if got := mustStock(t, s, 1); got != 16 {
+		t.Errorf("重试不应再扣减，库存应为16，实际%d", got)
+	}
+
+	// 释放：在主库存上找到扣减记录
+	if code, err := s.ReleaseStock(ctx, 1, 5, 102); err != nil || code != 1 {
+		t.Fatalf("释放失败: code=%d err=%v", code, err)
+	}
+	if v, _ := mr.Get("stock:1"); v != "6" {
+		t.Errorf("释放后主库存应为6，实际%s", v)
+	}
+	if err := s.SetStock(ctx, 1, 1); err != nil {
+		t.Fatalf("设置库存失败: %v", err)
+	}
+
+	// 合计也不够：库存不足，不做任何修改
+	if code, err := s.DeductStock(ctx, 1, 2, 103, 0); err != nil || code != 0 {
+		t.Fatalf("库存不足应返回0: code=%d err=%v", code, err)
+	}
+	if got := mustStock(t, s, 1); got != 1 {
+		t.Errorf("库存不足时库存应仍为1，实际%d", got)
+	}
+	if mr.Exists(stockUnit{bookID: 1}.recordKey("deduct", 103)) {
+		t.Error("库存不足时主库存上不应写入扣减记录")
+	}
+}
+
+// TestDeductStock_StaleShardHint 测试其他实例调整了分片数后，本实例按旧分片数扣减仍然正确
+func TestDeductStock_StaleShardHint(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a := newTestStore(t, mr)
+	b := newTestStore(t, mr)
+	ctx := context.Background()
+
+	if err := a.SetStock(ctx, 1, 20); err != nil {
+		t.Fatalf("设置库存失败: %v", err)
+	}
+	if _, _, err := a.ShardStock(ctx, 1, 4); err != nil {
+		t.Fatalf("拆分失败: %v", err)
+	}
+
+	// 实例B改为2个分片：实例A仍会尝试分片3、4（已不存在）
+	if _, _, err := b.ShardStock(ctx, 1, 2); err != nil {
+		t.Fatalf("调整分片数失败: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if code, err := a.DeductStock(ctx, 1, 2, uint(200+i), 0); err != nil || code != 1 {
+			t.Fatalf("第%d次扣减失败: code=%d err=%v", i+1, code, err)
+		}
+	}
+	if got := mustStock(t, a, 1); got != 10 {
+		t.Errorf("扣减后库存应为10，实际%d", got)
+	}
+	if got := shardValues(t, mr, 1, 4); got[2] != 0 || got[3] != 0 {
+		t.Errorf("已下线的分片3、4不应再被扣减，实际%v", got)
+	}
+
+	// 实例B合并回单个key：实例A的扣减直接落在stock:1上
+	if _, _, err := b.ShardStock(ctx, 1, 1); err != nil {
+		t.Fatalf("合并失败: %v", err)
+	}
+	if code, err := a.DeductStock(ctx, 1, 4, 300, 0); err != nil || code != 1 {
+		t.Fatalf("合并后扣减失败: code=%d err=%v", code, err)
+	}
+	if v, _ := mr.Get("stock:1"); v != "6" {
+		t.Errorf("合并后stock:1应为6，实际%s", v)
+	}
+}
+
+// TestBatchReserveStock_ShardFallback 测试批量预占时只为不足的图书换分片，结果仍是全部预占或一本都不占
+func TestBatchReserveStock_ShardFallback(t *testing.T) {
+	mr := miniredis.RunT(t)
+	s := newTestStore(t, mr)
+	ctx := context.Background()
+	expiresAt := time.Now().Add(15 * time.Minute)
+
+	if err := s.SetStock(ctx, 1, 0); err != nil {
+		t.Fatalf("设置库存失败: %v", err)
+	}
+	if _, _, err := s.ShardStock(ctx, 1, 3); err != nil {
+		t.Fatalf("拆分失败: %v", err)
+	}
+	setShards(t, mr, 1, 0, 3, 0)
+	if err := s.SetStock(ctx, 2, 5); err != nil {
+		t.Fatalf("设置库存失败: %v", err)
+	}
+
+	items := []inventory.StockItem{{BookID: 1, Quantity: 3}, {BookID: 2, Quantity: 1}}
+	res, err := s.BatchReserveStock(ctx, items, 400, expiresAt, 0)
+	if err != nil {
+		t.Fatalf("批量预占失败: %v", err)
+	}
+	if res.Insufficient != -1 || !res.Reserved[0] || !res.Reserved[1] {
+		t.Fatalf("应全部预占成功: %+v", res)
+	}
+	if got := mustStock(t, s, 1); got != 0 {
+		t.Errorf("图书1预占后库存应为0，实际%d", got)
+	}
+	if locked, _ := s.GetLockedStock(ctx, 1); locked != 3 {
+		t.Errorf("图书1锁定库存应为3，实际%d", locked)
+	}
+
+	// 图书1已无库存：整单不足，图书2不应被预占
+	items = []inventory.StockItem{{BookID: 1, Quantity: 1}, {BookID: 2, Quantity: 1}}
+	res, err = s.BatchReserveStock(ctx, items, 401, expiresAt, 0)
+	if err != nil {
+		t.Fatalf("批量预占失败: %v", err)
+	}
+	if res.Insufficient != 0 {
+		t.Fatalf("图书1应库存不足: %+v", res)
+	}
+	if got := mustStock(t, s, 2); got != 4 {
+		t.Errorf("整单不足时图书2库存应仍为4，实际%d", got)
+	}
+}
@@ -0,0 +1,285 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// stockUnit 库存单元：图书的主库存（shard为0）或热点图书的一个分片（shard从1开始）
+//
+// 教学要点：
+// 1. 一个单元的全部key共用一个hash tag，Redis Cluster下落在同一个slot
+//   - 主库存：stock:{book_id}、locked:{stock:{book_id}}、deduct:{stock:{book_id}}:{order_id}……
+//   - 分片：{stock:{book_id}:shard:{i}}、locked:{stock:{book_id}:shard:{i}}……
+//   - stock:{book_id}本身不带花括号，整个key参与hash，与{stock:{book_id}}落在同一个slot
+//
+// 2. 每个Lua脚本只读写一个单元（批量脚本除外，只在单机Redis上使用），key全部通过KEYS传入
+// 3. 普通图书只有主库存，key与分片之前相同，只是辅助key加上了hash tag
+type stockUnit struct {
+	bookID uint
+	shard  int
+}
+
+// tag 单元的hash tag内容
+func (u stockUnit) tag() string {
+	if u.shard == 0 {
+		return "stock:" + strconv.FormatUint(uint64(u.bookID), 10)
+	}
+	return fmt.Sprintf("stock:%d:shard:%d", u.bookID, u.shard)
+}
+
+// stockKey 可用库存键
+// 格式：stock:{book_id}（主库存）、{stock:{book_id}:shard:{i}}（分片）
+func (u stockUnit) stockKey() string {
+	if u.shard == 0 {
+		return u.tag()
+	}
+	return "{" + u.tag() + "}"
+}
+
+// prefixedKey 单元的辅助键，格式：{prefix}:{tag}
+func (u stockUnit) prefixedKey(prefix string) string {
+	return prefix + ":{" + u.tag() + "}"
+}
+
+// lockedKey 锁定库存键
+func (u stockUnit) lockedKey() string { return u.prefixedKey("locked") }
+
+// warehouseKey 仓库库存键（Hash，field为仓库ID）
+func (u stockUnit) warehouseKey() string { return u.prefixedKey("warehouse") }
+
+// movesKey 迁移表键（见move_out_stock.lua）
+func (u stockUnit) movesKey() string { return u.prefixedKey("moves") }
+
+// layoutKey 分片布局键：主库存上为当前分片数，分片上为分片数的副本（已下线的分片不存在）
+func (u stockUnit) layoutKey() string { return "{" + u.tag() + "}:shards" }
+
+// recordKey 订单记录键（kind为deduct/release/reserve）
+// 格式：{kind}:{tag}:{order_id}
+func (u stockUnit) recordKey(kind string, orderID uint) string {
+	return u.prefixedKey(kind) + ":" + strconv.FormatUint(uint64(orderID), 10)
+}
+
+// movedKey 迁入标记键
+func (u stockUnit) movedKey(moveID string) string {
+	return u.prefixedKey("moved") + ":" + moveID
+}
+
+// skipFlag 不足时是否写入跳过标记：只有分片需要（主库存是最后一个单元，收回分片后还会再试一次）
+func (u stockUnit) skipFlag() int {
+	if u.shard > 0 {
+		return 1
+	}
+	return 0
+}
+
+// maxShardsKey 分片数上限键：图书曾经使用过的最大分片数，释放类操作据此查找已下线的分片
+// 格式：{stock:{book_id}}:max_shards
+func maxShardsKey(bookID uint) string {
+	return "{" + stockUnit{bookID: bookID}.tag() + "}:max_shards"
+}
+
+// unitOrder 订单在图书各库存单元上的尝试顺序：从订单对应的分片开始依次轮转，最后为主库存
+//
+// 教学要点：
+// 1. 起点按订单ID计算：同一本书的并发订单分散到各分片，每次脚本调用只读写一个分片
+// 2. 同一订单每次得到相同的顺序：重试时先经过上次不足（已写跳过标记）的分片，再到上次成功的单元
+// 3. 未分片的图书只有主库存，与分片之前一样只调用一次脚本
+func unitOrder(bookID, orderID uint, shards int) []stockUnit {
+	units := make([]stockUnit, 0, shards+1)
+	if shards > 0 {
+		start := int((uint64(orderID)*2654435761 + uint64(bookID)) % uint64(shards))
+		for k := 0; k < shards; k++ {
+			units = append(units, stockUnit{bookID: bookID, shard: (start+k)%shards + 1})
+		}
+	}
+	return append(units, stockUnit{bookID: bookID})
+}
+
+// 扣减类脚本在单个单元上的结果码（成功的结果码见各方法）
+const (
+	unitInsufficient   = 0  // 单元上库存不足（或有跳过标记）
+	unitLayoutMismatch = -1 // 单元上的布局与调用方看到的分片数不符
+)
+
+// maxLayoutRefreshes 一次操作中刷新分片数的次数上限：超过说明布局正在调整，返回错误由调用方重试
+const maxLayoutRefreshes = 3
+
+// unitWalk 一本图书在一次扣减/预占中的尝试进度
+type unitWalk struct {
+	bookID    uint
+	shards    int         // 本次使用的分片数
+	units     []stockUnit // 依次尝试的单元
+	next      int         // 当前单元下标
+	refreshes int
+	recalled  bool
+}
+
+// current 当前尝试的单元
+func (w *unitWalk) current() stockUnit {
+	return w.units[w.next]
+}
+
+// walkUnits 在各图书的库存单元上执行扣减类操作，直到成功或确定库存不足
+//
+// try在每本图书的当前单元上尝试一次（单本操作只有一本，批量脚本一次尝试全部图书），
+// 成功时返回idx为-1，否则返回出问题的图书下标和结果码：
+//   - unitLayoutMismatch：刷新这本书的分片数，从头重新尝试（跳过标记保证不会重复扣减）
+//   - unitInsufficient：换这本书的下一个单元；全部不足时收回各分片的库存到主库存，再试一次主库存
+//
+// 返回库存不足的图书下标（-1表示成功）
+func (s *InventoryStore) walkUnits(ctx context.Context, bookIDs []uint, orderID uint, try func(walks []*unitWalk) (code, idx int, err error)) (int, error) {
+	walks := make([]*unitWalk, len(bookIDs))
+	for i, bookID := range bookIDs {
+		walks[i] = s.newUnitWalk(bookID, orderID, s.shardHint(bookID))
+	}
+
+	for {
+		code, idx, err := try(walks)
+		if err != nil {
+			return 0, err
+		}
+		if idx < 0 {
+			return -1, nil
+		}
+		if idx >= len(walks) {
+			return 0, fmt.Errorf("脚本返回的图书下标越界: %d", idx)
+		}
+
+		w := walks[idx]
+		if code == unitLayoutMismatch {
+			if w.refreshes >= maxLayoutRefreshes {
+				return 0, fmt.Errorf("图书%d的库存分片布局正在调整，请稍后重试", w.bookID)
+			}
+			shards, err := s.refreshShardHint(ctx, w.bookID)
+			if err != nil {
+				return 0, err
+			}
+			refreshed := s.newUnitWalk(w.bookID, orderID, shards)
+			refreshed.refreshes = w.refreshes + 1
+			refreshed.recalled = w.recalled
+			walks[idx] = refreshed
+			continue
+		}
+
+		w.next++
+		if w.next < len(w.units) {
+			continue
+		}
+
+		// 各单元都不足：临近售罄时库存可能零散在各分片上，收回到主库存后再试一次
+		if w.shards > 0 && !w.recalled {
+			w.recalled = true
+			moved, err := s.recallShards(ctx, w.bookID)
+			if err != nil {
+				return 0, err
+			}
+			if moved > 0 {
+				w.units = append(w.units, stockUnit{bookID: w.bookID})
+				continue
+			}
+		}
+		return idx, nil
+	}
+}
+
+// newUnitWalk 按分片数创建尝试进度
+func (s *InventoryStore) newUnitWalk(bookID, orderID uint, shards int) *unitWalk {
+	return &unitWalk{bookID: bookID, shards: shards, units: unitOrder(bookID, orderID, shards)}
+}
+
+// probeUnits 逐个单元查找订单的记录并执行释放类操作（释放、取消、确认），直到结果不是0（记录不存在）
+//
+// 先按扣减时的顺序查找；都没有时再查找已下线的分片（分片数调小后，旧分片上仍可能有未释放的记录）
+func (s *InventoryStore) probeUnits(ctx context.Context, bookID, orderID uint, try func(u stockUnit) (int, error)) (int, error) {
+	shards := s.shardHint(bookID)
+	for _, u := range unitOrder(bookID, orderID, shards) {
+		code, err := try(u)
+		if err != nil || code != 0 {
+			return code, err
+		}
+	}
+
+	maxShards, err := s.maxShards(ctx, bookID)
+	if err != nil {
+		return 0, err
+	}
+	for i := shards + 1; i <= maxShards; i++ {
+		code, err := try(stockUnit{bookID: bookID, shard: i})
+		if err != nil || code != 0 {
+			return code, err
+		}
+	}
+	return 0, nil
+}
+
+// bookUnits 图书的全部库存单元：主库存 + 分片1..maxShards
+func bookUnits(bookID uint, maxShards int) []stockUnit {
+	units := make([]stockUnit, 0, maxShards+1)
+	for i := 0; i <= maxShards; i++ {
+		units = append(units, stockUnit{bookID: bookID, shard: i})
+	}
+	return units
+}
+
+// shardHint 本实例记录的分片数（只是提示，脚本会按Redis中的布局校验）
+func (s *InventoryStore) shardHint(bookID uint) int {
+	s.shardMu.RLock()
+	defer s.shardMu.RUnlock()
+	return s.shards[bookID]
+}
+
+// setShardHint 更新本实例记录的分片数
+func (s *InventoryStore) setShardHint(bookID uint, shards int) {
+	s.shardMu.Lock()
+	defer s.shardMu.Unlock()
+	if shards > 0 {
+		s.shards[bookID] = shards
+	} else {
+		delete(s.shards, bookID)
+	}
+}
+
+// refreshShardHint 从Redis读取图书当前的分片数并更新本实例的记录
+func (s *InventoryStore) refreshShardHint(ctx context.Context, bookID uint) (int, error) {
+	shards, err := s.client.Get(ctx, stockUnit{bookID: bookID}.layoutKey()).Int()
+	if err != nil && err != redis.Nil {
+		return 0, fmt.Errorf("查询库存分片数失败: %w", err)
+	}
+	s.setShardHint(bookID, shards)
+	return shards, nil
+}
+
+// maxShards 查询图书曾经使用过的最大分片数（从未分片为0）
+func (s *InventoryStore) maxShards(ctx context.Context, bookID uint) (int, error) {
+	n, err := s.client.Get(ctx, maxShardsKey(bookID)).Int()
+	if err != nil && err != redis.Nil {
+		return 0, fmt.Errorf("查询库存分片数失败: %w", err)
+	}
+	return n, nil
+}
+
+// batchMaxShards 使用Pipeline批量查询各图书的最大分片数
+func (s *InventoryStore) batchMaxShards(ctx context.Context, bookIDs []uint) (map[uint]int, error) {
+	pipe := s.client.Pipeline()
+	cmds := make(map[uint]*redis.StringCmd, len(bookIDs))
+	for _, bookID := range bookIDs {
+		cmds[bookID] = pipe.Get(ctx, maxShardsKey(bookID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("查询库存分片数失败: %w", err)
+	}
+
+	result := make(map[uint]int, len(bookIDs))
+	for bookID, cmd := range cmds {
+		n, err := cmd.Int()
+		if err != nil && err != redis.Nil {
+			return nil, fmt.Errorf("查询库存分片数失败: %w", err)
+		}
+		result[bookID] = n
+	}
+	return result, nil
+}
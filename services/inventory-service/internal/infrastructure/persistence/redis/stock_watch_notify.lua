@@ -0,0 +1,36 @@
+-- stock_watch_notify.lua
+-- 库存变更通知Lua脚本（原子操作）
+--
+-- 教学要点：
+-- 1. 库存不在脚本内读取
+--    - 热点图书的库存分布在多个slot，Redis Cluster下脚本只能访问同一slot的key
+--    - 调用方先读库存再调用脚本，序号全局递增（INCR），客户端按序号取最大的那条作为最新库存
+--    - 读库存与递增序号不是原子的：并发变更时序号最大的那条可能是旧值，
+--      调用方发布后再读一次库存，变了就再发布一次，最终序号最大的一定是最新库存
+--
+-- 2. 最新值表（HASH，book_id → "序号:库存"）
+--    - 客户端断线重连时带上收到的最大序号，只补发之后有变化的图书
+--    - 只保留每本书的最新值：库存角标只关心现在有多少，不需要中间过程
+--
+-- 3. PUBLISH广播给所有副本，各副本再推送给自己的WatchStock连接
+--
+-- KEYS[1]: 序号键（{stock_watch}:seq）
+-- KEYS[2]: 最新值表（{stock_watch}:latest）
+-- ARGV[1]: 图书ID
+-- ARGV[2]: 库存
+-- ARGV[3]: 广播频道
+--
+-- 返回值：本次变更的序号
+
+local seq_key = KEYS[1]
+local latest_key = KEYS[2]
+local book_id = ARGV[1]
+local stock = ARGV[2]
+local channel = ARGV[3]
+
+local seq = redis.call('INCR', seq_key)
+
+redis.call('HSET', latest_key, book_id, seq .. ":" .. stock)
+redis.call('PUBLISH', channel, book_id .. ":" .. seq .. ":" .. stock)
+
+return seq
@@ -0,0 +1,144 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 库存变更通知使用的Key和频道（两个key共用hash tag，Redis Cluster下在同一slot）
+const (
+	stockWatchSeqKey    = "{stock_watch}:seq"
+	stockWatchLatestKey = "{stock_watch}:latest"
+	stockWatchChannel   = "stock_watch"
+)
+
+// stockWatchRetryDelay 订阅出错后的重试间隔（go-redis在下一次Receive时自动重连）
+const stockWatchRetryDelay = time.Second
+
+// StockChange 库存变更通知
+type StockChange struct {
+	Seq    uint64 // 全局递增序号（断线重连时的续传位置）
+	BookID uint
+	Stock  int // 变更后的可售库存
+}
+
+// stockWatchRecheck 发布后复查库存的次数上限（见stock_watch_notify.lua）
+const stockWatchRecheck = 3
+
+// NotifyStockChange 发布库存变更通知（使用Lua脚本递增序号并发布），返回最后一次发布的序号
+//
+// 先读库存再发布，发布后再读一次：库存又变了（并发变更）就按新值再发布，保证序号最大的通知是最新库存
+func (s *InventoryStore) NotifyStockChange(ctx context.Context, bookID uint) (uint64, error) {
+	stock, err := s.GetStock(ctx, bookID)
+	if err != nil {
+		return 0, fmt.Errorf("发布库存变更通知失败: %w", err)
+	}
+
+	keys := []string{stockWatchSeqKey, stockWatchLatestKey}
+	var seq int64
+	for i := 0; i < stockWatchRecheck; i++ {
+		seq, err = s.client.EvalSha(ctx, s.stockWatchSHA, keys, bookID, stock, stockWatchChannel).Int64()
+		if err != nil {
+			return 0, fmt.Errorf("发布库存变更通知失败: %w", err)
+		}
+
+		latest, err := s.GetStock(ctx, bookID)
+		if err != nil {
+			return 0, fmt.Errorf("发布库存变更通知失败: %w", err)
+		}
+		if latest == stock {
+			break
+		}
+		stock = latest
+	}
+	return uint64(seq), nil
+}
+
+// LatestStockChanges 查询图书最近一次变更（从未通知过的图书不在结果中）
+func (s *InventoryStore) LatestStockChanges(ctx context.Context, bookIDs []uint) (map[uint]StockChange, error) {
+	result := make(map[uint]StockChange, len(bookIDs))
+	if len(bookIDs) == 0 {
+		return result, nil
+	}
+
+	fields := make([]string, len(bookIDs))
+	for i, id := range bookIDs {
+		fields[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	values, err := s.client.HMGet(ctx, stockWatchLatestKey, fields...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("查询库存变更失败: %w", err)
+	}
+
+	for i, v := range values {
+		str, ok := v.(string)
+		if !ok {
+			continue
+		}
+		change, err := parseStockChange(fields[i] + ":" + str)
+		if err != nil {
+			return nil, err
+		}
+		result[change.BookID] = change
+	}
+	return result, nil
+}
+
+// SubscribeStockChanges 订阅库存变更广播（阻塞直到ctx取消）
+//
+// 教学要点：
+// 1. Redis Pub/Sub不保存消息：断线期间的广播会丢失
+// 2. go-redis断线后自动重连并重新订阅，每次订阅成功都会回调onSubscribed
+// 3. 调用方在onSubscribed中按最新值表补发，弥补断线期间丢失的变更
+func (s *InventoryStore) SubscribeStockChanges(ctx context.Context, onChange func(StockChange), onSubscribed func()) error {
+	pubsub := s.client.Subscribe(ctx, stockWatchChannel)
+	defer pubsub.Close()
+
+	for {
+		msg, err := pubsub.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(stockWatchRetryDelay):
+			}
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			if m.Kind == "subscribe" {
+				onSubscribed()
+			}
+		case *redis.Message:
+			change, err := parseStockChange(m.Payload)
+			if err != nil {
+				continue
+			}
+			onChange(change)
+		}
+	}
+}
+
+// parseStockChange 解析"图书ID:序号:库存"
+func parseStockChange(s string) (StockChange, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return StockChange{}, fmt.Errorf("无效的库存变更: %q", s)
+	}
+	bookID, err1 := strconv.ParseUint(parts[0], 10, 64)
+	seq, err2 := strconv.ParseUint(parts[1], 10, 64)
+	stock, err3 := strconv.Atoi(parts[2])
+	if err1 != nil || err2 != nil || err3 != nil {
+		return StockChange{}, fmt.Errorf("无效的库存变更: %q", s)
+	}
+	return StockChange{Seq: seq, BookID: uint(bookID), Stock: stock}, nil
+}
@@ -0,0 +1,42 @@
+-- transfer_stock.lua
+-- 仓库间调拨Lua脚本（原子操作）
+--
+-- 教学要点：
+-- 1. 调拨只在仓库之间移动，可售库存（stock:{book_id}）不变
+-- 2. 调出仓库为0表示从"未分配库存"调出：分仓上线前的存量库存由此分配到具体仓库
+-- 3. 检查与移动在同一脚本中完成，调出仓库不会被扣成负数
+-- 4. 只作用于主库存：热点图书由调用方先收回各分片的库存
+--
+-- KEYS[1]: 库存键（stock:{book_id}）
+-- KEYS[2]: 仓库库存键（warehouse:{stock:{book_id}}）
+-- ARGV[1]: 调出仓库ID（0为未分配库存）
+-- ARGV[2]: 调入仓库ID
+-- ARGV[3]: 调拨数量
+--
+-- 返回值：
+-- 0: 调出仓库库存不足
+-- 1: 调拨成功
+
+local stock_key = KEYS[1]
+local wh_key = KEYS[2]
+local from_warehouse = ARGV[1]
+local to_warehouse = ARGV[2]
+local quantity = tonumber(ARGV[3])
+
+local available
+if from_warehouse == "0" then
+    available = tonumber(redis.call('GET', stock_key) or 0) - wh_total(wh_key)
+else
+    available = tonumber(redis.call('HGET', wh_key, from_warehouse) or 0)
+end
+
+if available < quantity then
+    return 0
+end
+
+if from_warehouse ~= "0" then
+    redis.call('HINCRBY', wh_key, from_warehouse, -quantity)
+end
+redis.call('HINCRBY', wh_key, to_warehouse, quantity)
+
+return 1
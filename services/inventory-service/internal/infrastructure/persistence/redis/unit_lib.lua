@@ -0,0 +1,28 @@
+-- unit_lib.lua
+-- 库存单元函数库（LoadScripts时拼接在扣减/预占脚本前面，不单独执行）
+--
+-- 教学要点：
+-- 1. 库存单元：普通图书只有主库存一个单元；热点图书另有N个分片，每个分片是一个独立单元
+--    - 主库存：stock:{book_id}，其余key用{stock:{book_id}}作hash tag（如locked:{stock:123}）
+--    - 分片：{stock:{book_id}:shard:{i}}，其余key同样带这个hash tag
+--    - 一个单元的可用库存、锁定库存、仓库库存、扣减/预占记录都在同一个slot，脚本只读写一个单元
+--
+-- 2. 布局校验：调用方按自己看到的分片数决定尝试顺序，脚本核对单元上记录的分片数
+--    - 主库存的{stock:{book_id}}:shards为当前分片数（不存在为0），各分片的{单元}:shards是它的副本
+--    - 不符说明布局已调整（或分片已下线），返回-1让调用方刷新分片数后重新尝试
+--    - 保证同一订单的每次尝试都按同一个顺序经过各单元，重试一定先遇到上次成功的单元
+--
+-- 3. 跳过标记：分片不足时在该分片写入跳过标记（扣减记录为SKIP，预占记录状态为SKIPPED）
+--    - 调用方会换下一个单元，重试时若该分片恰好补足，没有标记就会在两个单元各扣一次
+--    - 释放、取消、确认把跳过标记当作"记录不存在"
+
+local UNIT_SKIP = "SKIP"
+local UNIT_SKIPPED = "SKIPPED"
+
+-- 跳过标记的有效期（秒）：覆盖调用方重试的时间窗口即可
+local UNIT_SKIP_TTL = 3600
+
+-- 单元记录的分片数是否与调用方看到的一致
+local function unit_layout_ok(layout_key, shards)
+    return (redis.call('GET', layout_key) or "0") == shards
+end
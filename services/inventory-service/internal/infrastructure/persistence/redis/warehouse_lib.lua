@@ -0,0 +1,88 @@
+-- warehouse_lib.lua
+-- 仓库分配函数库（LoadScripts时拼接在需要分仓的脚本前面，不单独执行）
+--
+-- 教学要点：
+-- 1. 两层库存
+--    - stock:{book_id}：可售库存（全部仓库合计），下单检查以它为准
+--    - warehouse:{stock:{book_id}}：各仓库可用库存（Hash，field为仓库ID）
+--    - 可售库存 - 各仓库合计 = 未分配仓库的库存（分仓之前的存量数据）
+--    - 热点图书的每个分片有自己的仓库库存（warehouse:{stock:{book_id}:shard:{i}}），与分片库存同一slot
+--
+-- 2. 分配策略：优先仓库 → 按配置的优先级依次兜底 → 未分配库存
+--    - 分配结果记录为 "仓库ID:数量,仓库ID:数量"（未分配部分不记录）
+--    - 写入扣减/预占记录，释放/取消时按原分配退回各仓库
+--
+-- 3. Redis脚本内不能调用其他脚本，公共逻辑通过拼接复用
+-- 4. 仓库库存键由调用方通过KEYS传入：Redis Cluster要求脚本访问的key都在KEYS中声明
+
+-- 按策略从各仓库扣减，返回分配串
+local function wh_allocate(wh_key, quantity, preferred, priority)
+    local remaining = quantity
+    local parts = {}
+    local tried = {}
+
+    local candidates = {}
+    if preferred ~= nil and preferred ~= "" and preferred ~= "0" then
+        table.insert(candidates, preferred)
+    end
+    for wh in string.gmatch(priority or "", "[^,]+") do
+        table.insert(candidates, wh)
+    end
+
+    for _, wh in ipairs(candidates) do
+        if remaining <= 0 then
+            break
+        end
+        if not tried[wh] then
+            tried[wh] = true
+            local available = tonumber(redis.call('HGET', wh_key, wh) or 0)
+            if available > 0 then
+                local take = math.min(available, remaining)
+                redis.call('HINCRBY', wh_key, wh, -take)
+                remaining = remaining - take
+                table.insert(parts, wh .. ":" .. take)
+            end
+        end
+    end
+
+    return table.concat(parts, ",")
+end
+
+-- 按分配串退回各仓库（旧版扣减记录的值为'1'，不含分配信息，退回到未分配库存）
+local function wh_restore(wh_key, allocation)
+    if not allocation then
+        return
+    end
+    for wh, qty in string.gmatch(allocation, "(%d+):(%d+)") do
+        redis.call('HINCRBY', wh_key, wh, tonumber(qty))
+    end
+end
+
+-- 各仓库库存合计
+local function wh_total(wh_key)
+    local total = 0
+    for _, v in ipairs(redis.call('HVALS', wh_key)) do
+        total = total + tonumber(v)
+    end
+    return total
+end
+
+-- 不分优先级从各仓库取出quantity件，返回分配串（库存单元之间迁移用：仓库归属随库存一起迁移）
+local function wh_take(wh_key, quantity)
+    local remaining = quantity
+    local parts = {}
+    local fields = redis.call('HGETALL', wh_key)
+    for i = 1, #fields, 2 do
+        if remaining <= 0 then
+            break
+        end
+        local available = tonumber(fields[i + 1])
+        if available > 0 then
+            local take = math.min(available, remaining)
+            redis.call('HINCRBY', wh_key, fields[i], -take)
+            remaining = remaining - take
+            table.insert(parts, fields[i] .. ":" .. take)
+        end
+    end
+    return table.concat(parts, ",")
+end
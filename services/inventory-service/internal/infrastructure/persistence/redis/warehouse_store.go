@@ -0,0 +1,163 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/xiebiao/bookstore/services/inventory-service/internal/domain/inventory"
+)
+
+// TransferStock 仓库间调拨（使用Lua脚本，可售库存不变）
+//
+// 返回值含义：
+//
+//	0: 调出仓库库存不足
+//	1: 调拨成功
+//
+// 调拨只作用于主库存：热点图书先收回各分片的库存（见recallForAdmin）
+func (s *InventoryStore) TransferStock(ctx context.Context, bookID uint, fromWarehouse, toWarehouse uint, quantity int) (int, error) {
+	if _, _, err := s.recallForAdmin(ctx, bookID); err != nil {
+		return 0, err
+	}
+	u := stockUnit{bookID: bookID}
+	return s.evalCode(ctx, s.transferSHA, []string{u.stockKey(), u.warehouseKey()}, fromWarehouse, toWarehouse, quantity)
+}
+
+// GetWarehouseStocks 获取图书在各仓库的可用库存（热点图书为主库存与各分片之和）
+func (s *InventoryStore) GetWarehouseStocks(ctx context.Context, bookID uint) (map[uint]int, error) {
+	maxShards, err := s.maxShards(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := s.client.Pipeline()
+	units := bookUnits(bookID, maxShards)
+	cmds := make([]*redis.StringStringMapCmd, len(units))
+	for i, u := range units {
+		cmds[i] = pipe.HGetAll(ctx, u.warehouseKey())
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("获取仓库库存失败: %w", err)
+	}
+
+	stocks := make(map[uint]int)
+	for _, cmd := range cmds {
+		for field, val := range cmd.Val() {
+			warehouseID, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				continue
+			}
+			stock, err := strconv.Atoi(val)
+			if err != nil {
+				continue
+			}
+			stocks[uint(warehouseID)] += stock
+		}
+	}
+	return stocks, nil
+}
+
+// SetWarehouseStocks 设置图书在各仓库的库存（从MySQL恢复，覆盖原有数据）
+//
+// 热点图书先收回各分片的库存（连同仓库归属），再覆盖主库存的仓库库存
+func (s *InventoryStore) SetWarehouseStocks(ctx context.Context, bookID uint, stocks map[uint]int) error {
+	if _, _, err := s.recallForAdmin(ctx, bookID); err != nil {
+		return fmt.Errorf("设置仓库库存失败: %w", err)
+	}
+	key := stockUnit{bookID: bookID}.warehouseKey()
+
+	values := make(map[string]interface{}, len(stocks))
+	for warehouseID, stock := range stocks {
+		values[strconv.FormatUint(uint64(warehouseID), 10)] = stock
+	}
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		if len(values) > 0 {
+			pipe.HSet(ctx, key, values)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("设置仓库库存失败: %w", err)
+	}
+	return nil
+}
+
+// DeductAllocations 批量查询订单扣减时的仓库分配（扣减记录不存在的图书不在结果中）
+func (s *InventoryStore) DeductAllocations(ctx context.Context, orderID uint, bookIDs ...uint) (map[uint][]inventory.WarehouseAllocation, error) {
+	return s.getAllocations(ctx, "deduct", orderID, bookIDs)
+}
+
+// ReleaseAllocations 批量查询订单释放时退回的仓库分配（释放记录不存在的图书不在结果中）
+func (s *InventoryStore) ReleaseAllocations(ctx context.Context, orderID uint, bookIDs ...uint) (map[uint][]inventory.WarehouseAllocation, error) {
+	return s.getAllocations(ctx, "release", orderID, bookIDs)
+}
+
+// ReservationAllocation 查询预占时的仓库分配（预占记录不存在时返回nil）
+func (s *InventoryStore) ReservationAllocation(ctx context.Context, bookID, orderID uint) ([]inventory.WarehouseAllocation, error) {
+	maxShards, err := s.maxShards(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := s.client.Pipeline()
+	units := bookUnits(bookID, maxShards)
+	cmds := make([]*redis.SliceCmd, len(units))
+	for i, u := range units {
+		cmds[i] = pipe.HMGet(ctx, u.recordKey("reserve", orderID), "status", "alloc")
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("获取仓库分配失败: %w", err)
+	}
+
+	for _, cmd := range cmds {
+		values := cmd.Val()
+		status, _ := values[0].(string)
+		alloc, ok := values[1].(string)
+		if !ok || status == "SKIPPED" {
+			continue
+		}
+		return inventory.ParseAllocation(alloc)
+	}
+	return nil, nil
+}
+
+// getAllocations 使用Pipeline读取扣减/释放记录中的分配串（热点图书逐个单元查找，跳过标记不算记录）
+func (s *InventoryStore) getAllocations(ctx context.Context, kind string, orderID uint, bookIDs []uint) (map[uint][]inventory.WarehouseAllocation, error) {
+	maxShards, err := s.batchMaxShards(ctx, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := s.client.Pipeline()
+	cmds := make(map[uint][]*redis.StringCmd, len(bookIDs))
+	for _, bookID := range bookIDs {
+		for _, u := range bookUnits(bookID, maxShards[bookID]) {
+			cmds[bookID] = append(cmds[bookID], pipe.Get(ctx, u.recordKey(kind, orderID)))
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("获取仓库分配失败: %w", err)
+	}
+
+	result := make(map[uint][]inventory.WarehouseAllocation, len(bookIDs))
+	for bookID, bookCmds := range cmds {
+		for _, cmd := range bookCmds {
+			val, err := cmd.Result()
+			if err != nil || val == "SKIP" {
+				continue // 记录不存在（已过期或已被释放），或只是跳过标记
+			}
+			allocs, err := inventory.ParseAllocation(val)
+			if err != nil {
+				return nil, err
+			}
+			result[bookID] = allocs
+			break
+		}
+	}
+	return result, nil
+}
@@ -10,8 +10,11 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/xiebiao/bookstore/pkg/events"
+	"github.com/xiebiao/bookstore/pkg/mq"
+	"github.com/xiebiao/bookstore/pkg/outbox"
+	"github.com/xiebiao/bookstore/pkg/saga"
 	orderv1 "github.com/xiebiao/bookstore/proto/orderv1"
-	"github.com/xiebiao/bookstore/services/order-service/internal/domain/order"
 	"github.com/xiebiao/bookstore/services/order-service/internal/grpc/handler"
 	"github.com/xiebiao/bookstore/services/order-service/internal/infrastructure/config"
 	"github.com/xiebiao/bookstore/services/order-service/internal/infrastructure/grpc_client"
@@ -52,14 +55,28 @@ func main() {
 	// 5. 创建仓储和缓存
 	orderRepo := mysql.NewOrderRepository(db)
 	orderCache := redisStore.NewOrderCache(redisClient)
+	sagaStore := mysql.NewSagaStore(db)
+	flashSaleRepo := mysql.NewFlashSaleRepository(db)
+	flashSaleStore := redisStore.NewFlashSaleStore(redisClient)
 
-	// 6. 创建gRPC服务
+	// 6. 创建事件发布者（发件箱中继和秒杀排队共用）
+	publisher, err := mq.NewPublisher(cfg.MQ.URL, events.Exchange, events.ExchangeType)
+	if err != nil {
+		log.Fatalf("创建事件发布者失败: %v", err)
+	}
+	defer publisher.Close()
+
+	// 7. 创建gRPC服务
 	grpcServer := grpc.NewServer()
 	orderService := handler.NewOrderServiceServer(
 		orderRepo,
 		orderCache,
 		inventoryClient,
 		catalogClient,
+		sagaStore,
+		flashSaleRepo,
+		flashSaleStore,
+		publisher,
 		cfg,
 	)
 	orderv1.RegisterOrderServiceServer(grpcServer, orderService)
@@ -67,12 +84,70 @@ func main() {
 	// 启用反射（便于grpcurl调试）
 	reflection.Register(grpcServer)
 
-	// 7. 启动定时任务（订单超时取消）
+	// 8. 启动定时任务（订单超时取消）
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	go startOrderTimeoutTask(ctx, orderRepo, orderCache, inventoryClient, cfg)
+	go startOrderTimeoutTask(ctx, orderCache, orderService)
+
+	// 9. 启动Saga恢复任务（补偿上次崩溃遗留的未完成Saga）
+	sagaRecoverer := saga.NewRecoverer(sagaStore)
+	orderService.RegisterSagaCompensators(sagaRecoverer)
+	go startSagaRecoveryTask(ctx, sagaRecoverer, cfg)
+
+	// 10. 订阅支付事件（支付成功 → 订单已支付，全额退款 → 订单关闭）
+	paymentConsumer, err := mq.NewConsumer(
+		cfg.MQ.URL,
+		events.Exchange,
+		events.ExchangeType,
+		cfg.MQ.PaymentQueue,
+		[]string{events.PaymentSucceeded, events.PaymentFailed, events.RefundSucceeded},
+		mq.WithMaxRetries(cfg.MQ.MaxRetries),
+		mq.WithRetryBackoff(time.Duration(cfg.MQ.RetryBackoffMs)*time.Millisecond, time.Minute),
+		mq.WithProcessedStore(
+			redisStore.NewProcessedStore(redisClient),
+			time.Minute,
+			time.Duration(cfg.MQ.DedupRetentionHours)*time.Hour,
+		),
+	)
+	if err != nil {
+		log.Fatalf("创建支付事件消费者失败: %v", err)
+	}
+	defer paymentConsumer.Close()
+	go startPaymentEventConsumer(ctx, paymentConsumer, orderService)
+
+	// 11. 启动发件箱中继（订单事件与订单在同一事务写入outbox表，这里异步发布）
+	relay := outbox.NewRelay(
+		"order-service",
+		outbox.NewGormStore(db),
+		publisher,
+		outbox.WithInterval(time.Duration(cfg.MQ.RelayIntervalMs)*time.Millisecond),
+		outbox.WithMaxAttempts(cfg.MQ.RelayMaxAttempts),
+	)
+	go relay.Run(ctx)
+
+	// 12. 秒杀：预热任务 + 排队消费（抢到名额的请求在队列中排队，异步建单）
+	go startFlashSaleWarmupTask(ctx, orderService, cfg)
+	flashSaleConsumer, err := mq.NewConsumer(
+		cfg.MQ.URL,
+		events.Exchange,
+		events.ExchangeType,
+		cfg.FlashSale.Queue,
+		[]string{events.FlashSaleAdmitted},
+		mq.WithMaxRetries(cfg.MQ.MaxRetries),
+		mq.WithRetryBackoff(time.Duration(cfg.MQ.RetryBackoffMs)*time.Millisecond, time.Minute),
+		mq.WithProcessedStore(
+			redisStore.NewProcessedStore(redisClient),
+			time.Duration(cfg.Saga.Timeout)*time.Second*2,
+			time.Duration(cfg.MQ.DedupRetentionHours)*time.Hour,
+		),
+	)
+	if err != nil {
+		log.Fatalf("创建秒杀排队消费者失败: %v", err)
+	}
+	defer flashSaleConsumer.Close()
+	go startFlashSaleConsumer(ctx, flashSaleConsumer, orderService)
 
-	// 8. 启动gRPC服务器
+	// 13. 启动gRPC服务器
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.Port))
 	if err != nil {
 		log.Fatalf("监听端口失败: %v", err)
@@ -81,7 +156,7 @@ func main() {
 	log.Printf("🚀 order-service 启动成功，监听端口: :%d", cfg.Server.Port)
 	log.Printf("📋 订单超时时间: %d分钟", cfg.Order.PaymentTimeout)
 
-	// 9. 优雅关闭
+	// 14. 优雅关闭
 	go func() {
 		if err := grpcServer.Serve(lis); err != nil {
 			log.Fatalf("gRPC服务启动失败: %v", err)
@@ -114,13 +189,7 @@ func main() {
 // 3. 容错处理：
 //   - 单个订单取消失败不影响其他订单
 //   - 失败的订单下次继续处理
-func startOrderTimeoutTask(
-	ctx context.Context,
-	repo order.Repository,
-	cache redisStore.OrderCache,
-	inventoryClient *grpc_client.InventoryClient,
-	cfg *config.Config,
-) {
+func startOrderTimeoutTask(ctx context.Context, cache redisStore.OrderCache, orderService *handler.OrderServiceServer) {
 	ticker := time.NewTicker(1 * time.Minute)
 	defer ticker.Stop()
 
@@ -146,7 +215,7 @@ func startOrderTimeoutTask(
 			log.Printf("发现%d个超时订单，开始自动取消", len(expiredOrders))
 
 			for _, orderID := range expiredOrders {
-				if err := cancelExpiredOrder(ctx, orderID, repo, cache, inventoryClient, cfg); err != nil {
+				if err := orderService.CancelExpiredOrder(ctx, orderID); err != nil {
 					log.Printf("取消订单失败 (order_id=%d): %v", orderID, err)
 				} else {
 					log.Printf("✅ 订单已自动取消 (order_id=%d)", orderID)
@@ -156,56 +225,98 @@ func startOrderTimeoutTask(
 	}
 }
 
-// cancelExpiredOrder 取消超时订单
-func cancelExpiredOrder(
-	ctx context.Context,
-	orderID uint,
-	repo order.Repository,
-	cache redisStore.OrderCache,
-	inventoryClient *grpc_client.InventoryClient,
-	cfg *config.Config,
-) error {
-	// 1. 查询订单
-	o, err := repo.FindByID(ctx, orderID)
-	if err != nil {
-		return err
-	}
+// startSagaRecoveryTask 启动Saga恢复任务
+//
+// 教学要点：
+// 1. 启动时立即执行一次：进程崩溃重启后尽快补偿遗留Saga（释放被占用的库存）
+// 2. 之后定期执行：崩溃前最后一刻的Saga在启动时可能还未"过期"，由后续轮次处理
+// 3. 多实例部署时各实例都会扫描，补偿函数必须幂等
+func startSagaRecoveryTask(ctx context.Context, r *saga.Recoverer, cfg *config.Config) {
+	staleAfter := time.Duration(cfg.Saga.RecoveryStaleAfter) * time.Second
 
-	// 2. 检查状态（只取消待支付订单）
-	if o.Status != order.OrderStatusPending {
-		// 已支付或已取消，从待支付队列移除
-		cache.RemovePendingOrder(ctx, orderID)
-		return nil
+	recoverOnce := func() {
+		n, err := r.Recover(ctx, staleAfter)
+		if err != nil {
+			log.Printf("⚠️ Saga恢复存在失败: %v", err)
+		}
+		if n > 0 {
+			log.Printf("🔁 本轮处理%d个未完成的Saga", n)
+		}
 	}
 
-	// 3. 更新订单状态为已取消
-	if err := o.UpdateStatus(order.OrderStatusCancelled); err != nil {
-		return err
+	log.Println("📅 Saga恢复任务已启动")
+	recoverOnce()
+
+	ticker := time.NewTicker(time.Duration(cfg.Saga.RecoveryInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Saga恢复任务已停止")
+			return
+		case <-ticker.C:
+			recoverOnce()
+		}
 	}
+}
 
-	if err := repo.Update(ctx, o); err != nil {
-		return err
+// startPaymentEventConsumer 启动支付事件消费
+//
+// 教学要点：
+// 1. 消费在独立goroutine中进行，ctx取消（服务关闭）时退出
+// 2. 处理函数返回error时消息进入重试队列延迟重投，超过重试次数进入死信队列
+func startPaymentEventConsumer(ctx context.Context, consumer *mq.Consumer, orderService *handler.OrderServiceServer) {
+	err := consumer.Consume(ctx, func(body []byte) error {
+		return orderService.HandlePaymentEvent(ctx, body)
+	})
+	if err != nil {
+		log.Printf("❌ 支付事件消费已停止: %v", err)
 	}
+}
 
-	// 4. 释放库存
-	for _, item := range o.Items {
-		_, err := inventoryClient.ReleaseStock(
-			ctx,
-			item.BookID,
-			item.Quantity,
-			o.ID,
-			cfg.GetServiceTimeout("inventory"),
-		)
+// startFlashSaleWarmupTask 启动秒杀预热任务
+//
+// 教学要点：
+// 1. 启动时立即执行一次：服务重启后尽快补齐即将开始的活动
+// 2. 多实例同时预热是安全的：活动库存用SETNX写入，不会重置已售出的库存
+func startFlashSaleWarmupTask(ctx context.Context, orderService *handler.OrderServiceServer, cfg *config.Config) {
+	warmUpOnce := func() {
+		n, err := orderService.WarmUpFlashSales(ctx)
 		if err != nil {
-			log.Printf("释放库存失败 (book_id=%d): %v", item.BookID, err)
+			log.Printf("⚠️ 秒杀活动预热存在失败: %v", err)
+		}
+		if n > 0 {
+			log.Printf("🔥 本轮预热%d个秒杀活动", n)
 		}
 	}
 
-	// 5. 从待支付队列移除
-	cache.RemovePendingOrder(ctx, orderID)
+	log.Println("📅 秒杀预热任务已启动")
+	warmUpOnce()
+
+	ticker := time.NewTicker(time.Duration(cfg.FlashSale.WarmupIntervalSeconds) * time.Second)
+	defer ticker.Stop()
 
-	// 6. 删除缓存
-	cache.DeleteOrder(ctx, orderID)
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("秒杀预热任务已停止")
+			return
+		case <-ticker.C:
+			warmUpOnce()
+		}
+	}
+}
 
-	return nil
+// startFlashSaleConsumer 启动秒杀排队消费（异步建单）
+//
+// 教学要点：
+// 消费者每次只取1条消息（Qos=1），建单速度由消费者数量决定，瞬时流量在队列中堆积
+func startFlashSaleConsumer(ctx context.Context, consumer *mq.Consumer, orderService *handler.OrderServiceServer) {
+	err := consumer.Consume(ctx, func(body []byte) error {
+		return orderService.HandleFlashSaleAdmitted(ctx, body)
+	})
+	if err != nil {
+		log.Printf("❌ 秒杀排队消费已停止: %v", err)
+	}
 }
@@ -0,0 +1,115 @@
+package flashsale
+
+import (
+	"time"
+)
+
+// FlashSale 秒杀活动
+//
+// 教学要点：
+// 1. 活动库存独立于图书库存：活动开始前预热到Redis，秒杀期间只操作Redis中的活动库存池
+// 2. 价格快照：创建活动时记录原价和图书标题，活动期间不再查询catalog-service
+// 3. 活动阶段（未开始/进行中/已结束）由时间推导，不落库：
+//   - 避免定时任务逐个修改状态
+//   - 数据库只记录"是否已预热/已取消"这类无法由时间推导的状态
+type FlashSale struct {
+	ID            uint       `gorm:"primaryKey;comment:活动ID"`
+	BookID        uint       `gorm:"index;not null;comment:图书ID"`
+	BookTitle     string     `gorm:"size:200;comment:图书标题（快照）"`
+	Price         int64      `gorm:"not null;comment:秒杀价（分）"`
+	OriginalPrice int64      `gorm:"not null;comment:原价（分）"`
+	Stock         int        `gorm:"not null;comment:活动库存"`
+	PerUserLimit  int        `gorm:"not null;default:1;comment:每人限购数量"`
+	StartAt       time.Time  `gorm:"index;not null;comment:开始时间"`
+	EndAt         time.Time  `gorm:"not null;comment:结束时间"`
+	Status        SaleStatus `gorm:"type:tinyint;not null;default:1;index;comment:活动状态"`
+	WarmedAt      *time.Time `gorm:"comment:预热时间"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// TableName 指定表名
+func (FlashSale) TableName() string {
+	return "flash_sales"
+}
+
+// SaleStatus 活动状态
+type SaleStatus int
+
+const (
+	SaleStatusScheduled SaleStatus = 1 // 已创建，等待预热
+	SaleStatusWarmed    SaleStatus = 2 // 活动库存已预热到Redis
+	SaleStatusCancelled SaleStatus = 3 // 已取消
+)
+
+// Phase 活动阶段（由时间推导）
+type Phase string
+
+const (
+	PhaseUpcoming  Phase = "UPCOMING"  // 未开始
+	PhaseOngoing   Phase = "ONGOING"   // 进行中
+	PhaseEnded     Phase = "ENDED"     // 已结束
+	PhaseCancelled Phase = "CANCELLED" // 已取消
+)
+
+// PhaseAt 计算活动在某一时刻所处的阶段
+func (s *FlashSale) PhaseAt(now time.Time) Phase {
+	switch {
+	case s.Status == SaleStatusCancelled:
+		return PhaseCancelled
+	case now.Before(s.StartAt):
+		return PhaseUpcoming
+	case now.Before(s.EndAt):
+		return PhaseOngoing
+	default:
+		return PhaseEnded
+	}
+}
+
+// Validate 校验活动参数
+func (s *FlashSale) Validate(now time.Time) error {
+	if s.BookID == 0 {
+		return ErrInvalidFlashSale
+	}
+	if s.Price <= 0 || s.Stock <= 0 || s.PerUserLimit <= 0 {
+		return ErrInvalidFlashSale
+	}
+	if !s.EndAt.After(s.StartAt) || !s.EndAt.After(now) {
+		return ErrInvalidSaleTime
+	}
+	return nil
+}
+
+// TicketStatus 秒杀排队凭证状态
+//
+// 抢到名额后立即返回凭证，订单异步创建，前端凭凭证轮询结果
+type TicketStatus string
+
+const (
+	TicketQueued  TicketStatus = "QUEUED"  // 排队中（已占用活动库存，等待建单）
+	TicketSuccess TicketStatus = "SUCCESS" // 建单成功
+	TicketFailed  TicketStatus = "FAILED"  // 建单失败（活动库存和限购额度已退回）
+)
+
+// Ticket 秒杀排队凭证
+type Ticket struct {
+	ID       string
+	SaleID   uint
+	UserID   uint
+	Quantity int
+	Status   TicketStatus
+	OrderID  uint   // 建单成功后的订单ID
+	Reason   string // 失败原因
+}
+
+// AdmitResult 抢购结果（Redis原子判定）
+type AdmitResult int
+
+const (
+	AdmitOK            AdmitResult = 1  // 抢到名额
+	AdmitSoldOut       AdmitResult = 0  // 活动库存不足
+	AdmitNotWarmed     AdmitResult = -1 // 活动未预热（不存在或预热前）
+	AdmitNotStarted    AdmitResult = -2 // 活动未开始
+	AdmitEnded         AdmitResult = -3 // 活动已结束
+	AdmitLimitExceeded AdmitResult = -4 // 超过每人限购
+)
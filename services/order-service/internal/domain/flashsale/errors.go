@@ -0,0 +1,18 @@
+package flashsale
+
+import "errors"
+
+// 秒杀领域错误定义
+var (
+	// ErrFlashSaleNotFound 活动不存在
+	ErrFlashSaleNotFound = errors.New("秒杀活动不存在")
+
+	// ErrInvalidFlashSale 活动参数错误（价格、库存、限购必须大于0）
+	ErrInvalidFlashSale = errors.New("秒杀活动参数错误")
+
+	// ErrInvalidSaleTime 活动时间错误（结束时间必须晚于开始时间和当前时间）
+	ErrInvalidSaleTime = errors.New("秒杀活动时间错误")
+
+	// ErrTicketNotFound 排队凭证不存在或已过期
+	ErrTicketNotFound = errors.New("秒杀凭证不存在")
+)
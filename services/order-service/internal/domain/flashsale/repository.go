@@ -0,0 +1,28 @@
+package flashsale
+
+import (
+	"context"
+	"time"
+)
+
+// Repository 秒杀活动仓储接口
+//
+// 教学要点：
+// 秒杀期间的读写都走Redis（见redis.FlashSaleStore），MySQL只保存活动定义：
+// 创建活动、预热扫描、活动列表都是低频操作
+type Repository interface {
+	// Create 创建活动
+	Create(ctx context.Context, sale *FlashSale) error
+
+	// FindByID 根据ID查询活动
+	FindByID(ctx context.Context, id uint) (*FlashSale, error)
+
+	// ListVisible 查询未结束且未取消的活动（按开始时间升序，用于活动列表）
+	ListVisible(ctx context.Context, now time.Time, page, pageSize int) ([]*FlashSale, int64, error)
+
+	// ListToWarm 查询需要预热的活动：未预热、未结束、且在before之前开始
+	ListToWarm(ctx context.Context, now, before time.Time, limit int) ([]*FlashSale, error)
+
+	// MarkWarmed 标记活动已预热（仅从待预热状态更新，重复调用无副作用）
+	MarkWarmed(ctx context.Context, id uint, warmedAt time.Time) error
+}
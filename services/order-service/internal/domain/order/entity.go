@@ -22,10 +22,10 @@ import (
 type Order struct {
 	ID        uint        `gorm:"primaryKey;comment:订单ID"`
 	OrderNo   string      `gorm:"uniqueIndex;size:32;not null;comment:订单号"`
-	UserID    uint        `gorm:"index;not null;comment:用户ID"`
+	UserID    uint        `gorm:"index;index:idx_user_created,priority:1;not null;comment:用户ID"`
 	Total     int64       `gorm:"not null;comment:总金额（分）"`
 	Status    OrderStatus `gorm:"type:tinyint;not null;default:1;index;comment:订单状态"`
-	CreatedAt time.Time   `gorm:"comment:创建时间"`
+	CreatedAt time.Time   `gorm:"index:idx_user_created,priority:2;comment:创建时间"`
 	UpdatedAt time.Time   `gorm:"comment:更新时间"`
 
 	// Items 订单明细（聚合内的实体集合）
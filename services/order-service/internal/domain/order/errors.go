@@ -63,6 +63,10 @@ var (
 	// 场景：调用payment-service支付时失败
 	// Phase 2会细化为：余额不足、支付超时、渠道异常等
 	ErrPaymentFailed = errors.New("支付失败")
+
+	// ErrStatusConflict 订单状态已被并发修改
+	// 场景：超时取消与支付成功同时发生，条件更新时状态已不是预期值
+	ErrStatusConflict = errors.New("订单状态已变更")
 )
 
 // IsNotFoundError 判断是否为"未找到"类错误
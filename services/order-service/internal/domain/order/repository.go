@@ -1,6 +1,9 @@
 package order
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Repository 订单仓储接口
 //
@@ -65,8 +68,21 @@ type Repository interface {
 	// 2. status=0表示查询所有状态
 	//    - 筛选特定状态：status=1（待支付）
 	//    - 查询全部：status=0
+	// 3. 深翻页时OFFSET越来越慢，翻页期间下了新订单会让下一页重复上一页的最后几条，
+	//    App滚动加载应使用FindByUserIDAfter
 	FindByUserID(ctx context.Context, userID uint, page, pageSize int, status OrderStatus) ([]*Order, int64, error)
 
+	// FindByUserIDAfter 游标分页查询用户的订单列表
+	//
+	// 教学要点：
+	// 1. 排序与FindByUserID相同（创建时间倒序，同一时刻按ID倒序）
+	// 2. after为上一页最后一个订单的位置，nil表示第一页
+	// 3. 用 WHERE (created_at, id) < 游标 定位起点，借助(user_id, created_at)索引直接跳过已翻过的订单
+	FindByUserIDAfter(ctx context.Context, userID uint, status OrderStatus, after *ListCursor, limit int) ([]*Order, error)
+
+	// CountByUserID 用户的订单总数（status=0表示全部状态）
+	CountByUserID(ctx context.Context, userID uint, status OrderStatus) (int64, error)
+
 	// Update 更新订单
 	//
 	// 教学要点：
@@ -88,6 +104,14 @@ type Repository interface {
 	// 2. 避免并发更新冲突
 	UpdateStatus(ctx context.Context, id uint, status OrderStatus) error
 
+	// UpdateStatusFrom 条件更新状态（仅当当前状态为from时更新为to）
+	//
+	// 教学要点：
+	// SQL: UPDATE orders SET status=? WHERE id=? AND status=?
+	// 支付成功事件与超时取消任务可能同时处理同一订单，
+	// 条件更新保证只有一方生效，另一方返回ErrStatusConflict
+	UpdateStatusFrom(ctx context.Context, id uint, from, to OrderStatus) error
+
 	// Delete 删除订单（软删除）
 	//
 	// 教学要点：
@@ -126,3 +150,11 @@ type ItemRepository interface {
 	// FindByBookID 查询某本书的所有订单明细（用于统计销量）
 	FindByBookID(ctx context.Context, bookID uint, limit int) ([]*OrderItem, error)
 }
+
+// ListCursor 订单列表游标：上一页最后一个订单的创建时间和ID
+//
+// 同一时刻可能创建多个订单，ID作为第二排序键保证位置唯一
+type ListCursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
@@ -0,0 +1,470 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/xiebiao/bookstore/pkg/events"
+	"github.com/xiebiao/bookstore/pkg/mq"
+	"github.com/xiebiao/bookstore/pkg/saga"
+	orderv1 "github.com/xiebiao/bookstore/proto/orderv1"
+	"github.com/xiebiao/bookstore/services/order-service/internal/domain/flashsale"
+	"github.com/xiebiao/bookstore/services/order-service/internal/domain/order"
+	"github.com/xiebiao/bookstore/services/order-service/internal/infrastructure/grpc_client"
+)
+
+// EventPublisher 事件发布接口（秒杀排队消息）
+//
+// 由pkg/mq.Publisher实现；使用凭证ID作为消息ID，消费端据此去重
+type EventPublisher interface {
+	PublishWithID(messageID, routingKey string, message interface{}) error
+}
+
+// CreateFlashSale 创建秒杀活动
+//
+// 教学要点：
+// 1. 创建时记录图书标题和原价快照，秒杀期间不再查询catalog-service
+//   - 只有图书的发布者可以创建（与修改图书、补货相同的归属规则），operator_id由网关取自JWT
+//
+// 2. 活动库存不超过图书当前可用库存：抢到名额后仍需预占库存，超出部分必然建单失败
+// 3. 开始时间已进入预热窗口的活动立即预热，其余由预热任务在开始前写入Redis
+func (s *OrderServiceServer) CreateFlashSale(ctx context.Context, req *orderv1.CreateFlashSaleRequest) (*orderv1.CreateFlashSaleResponse, error) {
+	now := time.Now()
+	sale := &flashsale.FlashSale{
+		BookID:       uint(req.BookId),
+		Price:        req.Price,
+		Stock:        int(req.Stock),
+		PerUserLimit: int(req.PerUserLimit),
+		StartAt:      time.Unix(req.StartAt, 0),
+		EndAt:        time.Unix(req.EndAt, 0),
+		Status:       flashsale.SaleStatusScheduled,
+	}
+	if err := sale.Validate(now); err != nil {
+		return &orderv1.CreateFlashSaleResponse{Code: 40000, Message: err.Error()}, nil
+	}
+	if sale.PerUserLimit > s.cfg.Order.MaxQuantityPerItem {
+		return &orderv1.CreateFlashSaleResponse{
+			Code:    40000,
+			Message: fmt.Sprintf("每人限购不能超过%d件", s.cfg.Order.MaxQuantityPerItem),
+		}, nil
+	}
+
+	// 1. 图书信息快照
+	bookResp, err := s.catalogClient.GetBook(ctx, sale.BookID, s.cfg.GetServiceTimeout("catalog"))
+	if err != nil {
+		log.Printf("❌ 查询图书失败 (book_id=%d): %v", sale.BookID, err)
+		return &orderv1.CreateFlashSaleResponse{Code: 50000, Message: "查询图书失败"}, nil
+	}
+	if bookResp.Code != 0 || bookResp.Book == nil {
+		return &orderv1.CreateFlashSaleResponse{Code: 40400, Message: "图书不存在"}, nil
+	}
+	if bookResp.Book.PublisherId != req.OperatorId {
+		return &orderv1.CreateFlashSaleResponse{Code: 40300, Message: "只有图书发布者可以创建秒杀活动"}, nil
+	}
+	if !grpc_client.IsBookOnSale(bookResp.Book) {
+		return &orderv1.CreateFlashSaleResponse{Code: 40000, Message: "图书未在售"}, nil
+	}
+	if sale.Price > bookResp.Book.Price {
+		return &orderv1.CreateFlashSaleResponse{Code: 40000, Message: "秒杀价不能高于原价"}, nil
+	}
+	sale.BookTitle = bookResp.Book.Title
+	sale.OriginalPrice = bookResp.Book.Price
+
+	// 2. 活动库存不超过图书可用库存
+	stockResp, err := s.inventoryClient.BatchGetStock(ctx, []uint{sale.BookID}, s.cfg.GetServiceTimeout("inventory"))
+	if err != nil {
+		log.Printf("❌ 查询库存失败 (book_id=%d): %v", sale.BookID, err)
+		return &orderv1.CreateFlashSaleResponse{Code: 50000, Message: "查询库存失败"}, nil
+	}
+	available := 0
+	for _, st := range stockResp.Stocks {
+		if st.BookId == uint64(sale.BookID) {
+			available = int(st.Stock)
+		}
+	}
+	if sale.Stock > available {
+		return &orderv1.CreateFlashSaleResponse{
+			Code:    40000,
+			Message: fmt.Sprintf("活动库存超过图书可用库存（%d）", available),
+		}, nil
+	}
+
+	// 3. 保存活动
+	if err := s.flashSaleRepo.Create(ctx, sale); err != nil {
+		log.Printf("❌ %v", err)
+		return &orderv1.CreateFlashSaleResponse{Code: 50000, Message: "创建秒杀活动失败"}, nil
+	}
+
+	// 4. 已进入预热窗口的活动立即预热（失败时由预热任务重试）
+	if !sale.StartAt.After(now.Add(s.flashSaleWarmupAhead())) {
+		if err := s.warmUpFlashSale(ctx, sale); err != nil {
+			log.Printf("⚠️ 秒杀活动预热失败，等待预热任务重试 (sale_id=%d): %v", sale.ID, err)
+		}
+	}
+
+	log.Printf("⚡ 秒杀活动已创建 (sale_id=%d, book_id=%d, stock=%d)", sale.ID, sale.BookID, sale.Stock)
+	return &orderv1.CreateFlashSaleResponse{
+		Code:    0,
+		Message: "秒杀活动创建成功",
+		Sale:    toFlashSaleProto(sale, sale.Stock, now),
+	}, nil
+}
+
+// GetFlashSale 查询秒杀活动
+func (s *OrderServiceServer) GetFlashSale(ctx context.Context, req *orderv1.GetFlashSaleRequest) (*orderv1.GetFlashSaleResponse, error) {
+	if req.SaleId == 0 {
+		return &orderv1.GetFlashSaleResponse{Code: 40000, Message: "活动ID不能为空"}, nil
+	}
+
+	sale, err := s.flashSaleRepo.FindByID(ctx, uint(req.SaleId))
+	if err != nil {
+		if errors.Is(err, flashsale.ErrFlashSaleNotFound) {
+			return &orderv1.GetFlashSaleResponse{Code: 40400, Message: err.Error()}, nil
+		}
+		log.Printf("❌ %v", err)
+		return &orderv1.GetFlashSaleResponse{Code: 50000, Message: "查询秒杀活动失败"}, nil
+	}
+
+	protos := s.toFlashSaleProtos(ctx, []*flashsale.FlashSale{sale})
+	return &orderv1.GetFlashSaleResponse{Code: 0, Sale: protos[0]}, nil
+}
+
+// ListFlashSales 查询未结束的秒杀活动列表（按开始时间升序）
+func (s *OrderServiceServer) ListFlashSales(ctx context.Context, req *orderv1.ListFlashSalesRequest) (*orderv1.ListFlashSalesResponse, error) {
+	page, pageSize := int(req.Page), int(req.PageSize)
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	sales, total, err := s.flashSaleRepo.ListVisible(ctx, time.Now(), page, pageSize)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return &orderv1.ListFlashSalesResponse{Code: 50000, Message: "查询秒杀活动失败"}, nil
+	}
+
+	return &orderv1.ListFlashSalesResponse{
+		Code:  0,
+		Sales: s.toFlashSaleProtos(ctx, sales),
+		Total: uint32(total),
+	}, nil
+}
+
+// PurchaseFlashSale 秒杀抢购
+//
+// 教学要点：
+// 1. 层层过滤，越靠前的判定越便宜：
+//   - 进程内售罄标记：已抢完时直接返回，不访问Redis
+//   - 进程内活动定义：未开始/已结束/超过限购直接返回
+//   - Redis Lua：原子判定限购和活动库存，抢到名额时写入排队凭证
+//
+// 2. 抢到名额后发布排队消息并立即返回凭证，订单由消费者异步创建（见HandleFlashSaleAdmitted）
+// 3. 排队消息发布失败时退回名额：否则活动库存被扣减却没有订单，造成少卖
+func (s *OrderServiceServer) PurchaseFlashSale(ctx context.Context, req *orderv1.PurchaseFlashSaleRequest) (*orderv1.PurchaseFlashSaleResponse, error) {
+	if req.SaleId == 0 || req.UserId == 0 {
+		return &orderv1.PurchaseFlashSaleResponse{Code: 40000, Message: "活动ID和用户ID不能为空"}, nil
+	}
+	if req.Quantity <= 0 {
+		return &orderv1.PurchaseFlashSaleResponse{Code: 40000, Message: "数量必须大于0"}, nil
+	}
+	saleID := uint(req.SaleId)
+
+	// 1. 进程内售罄标记
+	if s.isFlashSaleSoldOut(saleID) {
+		return &orderv1.PurchaseFlashSaleResponse{Code: 40901, Message: "已抢完"}, nil
+	}
+
+	// 2. 进程内活动定义
+	sale, err := s.loadFlashSale(ctx, saleID)
+	if err != nil {
+		if errors.Is(err, flashsale.ErrFlashSaleNotFound) {
+			return &orderv1.PurchaseFlashSaleResponse{Code: 40400, Message: err.Error()}, nil
+		}
+		log.Printf("❌ %v", err)
+		return &orderv1.PurchaseFlashSaleResponse{Code: 50000, Message: "系统繁忙，请稍后重试"}, nil
+	}
+	now := time.Now()
+	switch sale.PhaseAt(now) {
+	case flashsale.PhaseUpcoming:
+		return &orderv1.PurchaseFlashSaleResponse{Code: 40903, Message: "秒杀活动未开始"}, nil
+	case flashsale.PhaseEnded, flashsale.PhaseCancelled:
+		return &orderv1.PurchaseFlashSaleResponse{Code: 40904, Message: "秒杀活动已结束"}, nil
+	}
+	if int(req.Quantity) > sale.PerUserLimit {
+		return &orderv1.PurchaseFlashSaleResponse{
+			Code:    40902,
+			Message: fmt.Sprintf("每人限购%d件", sale.PerUserLimit),
+		}, nil
+	}
+
+	// 3. Redis原子判定
+	ticketID := mq.NewMessageID()
+	result, err := s.flashSaleStore.Admit(ctx, saleID, uint(req.UserId), int(req.Quantity), ticketID, now, s.flashSaleTicketTTL(sale, now))
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return &orderv1.PurchaseFlashSaleResponse{Code: 50000, Message: "系统繁忙，请稍后重试"}, nil
+	}
+	switch result {
+	case flashsale.AdmitOK:
+	case flashsale.AdmitSoldOut:
+		s.markFlashSaleSoldOutIfEmpty(ctx, saleID)
+		return &orderv1.PurchaseFlashSaleResponse{Code: 40901, Message: "已抢完"}, nil
+	case flashsale.AdmitLimitExceeded:
+		return &orderv1.PurchaseFlashSaleResponse{
+			Code:    40902,
+			Message: fmt.Sprintf("每人限购%d件", sale.PerUserLimit),
+		}, nil
+	case flashsale.AdmitNotStarted:
+		return &orderv1.PurchaseFlashSaleResponse{Code: 40903, Message: "秒杀活动未开始"}, nil
+	case flashsale.AdmitEnded:
+		return &orderv1.PurchaseFlashSaleResponse{Code: 40904, Message: "秒杀活动已结束"}, nil
+	default:
+		// 活动已开始但预热任务尚未执行（或Redis数据丢失）
+		log.Printf("⚠️ 秒杀活动未预热 (sale_id=%d)", saleID)
+		return &orderv1.PurchaseFlashSaleResponse{Code: 40903, Message: "秒杀活动准备中，请稍后重试"}, nil
+	}
+
+	// 4. 发布排队消息（失败则退回名额）
+	event := events.FlashSaleAdmittedEvent{
+		Type:       events.FlashSaleAdmitted,
+		TicketID:   ticketID,
+		SaleID:     uint64(saleID),
+		UserID:     req.UserId,
+		BookID:     uint64(sale.BookID),
+		Quantity:   int(req.Quantity),
+		Price:      sale.Price,
+		AdmittedAt: now.Unix(),
+	}
+	if err := s.publisher.PublishWithID(ticketID, events.FlashSaleAdmitted, event); err != nil {
+		log.Printf("❌ 秒杀排队消息发布失败，退回名额 (sale_id=%d, ticket=%s): %v", saleID, ticketID, err)
+		if _, err := s.flashSaleStore.Refund(ctx, saleID, ticketID, "排队失败"); err != nil {
+			log.Printf("❌ 退回秒杀名额失败 (sale_id=%d, ticket=%s): %v", saleID, ticketID, err)
+		}
+		return &orderv1.PurchaseFlashSaleResponse{Code: 50000, Message: "系统繁忙，请稍后重试"}, nil
+	}
+
+	return &orderv1.PurchaseFlashSaleResponse{
+		Code:    0,
+		Message: "抢购成功，订单创建中",
+		Ticket:  ticketID,
+	}, nil
+}
+
+// GetFlashSaleResult 查询秒杀结果
+//
+// 凭证不属于当前用户时按不存在处理，避免泄露他人的抢购结果
+func (s *OrderServiceServer) GetFlashSaleResult(ctx context.Context, req *orderv1.GetFlashSaleResultRequest) (*orderv1.GetFlashSaleResultResponse, error) {
+	if req.SaleId == 0 || req.UserId == 0 || req.Ticket == "" {
+		return &orderv1.GetFlashSaleResultResponse{Code: 40000, Message: "活动ID、用户ID和凭证不能为空"}, nil
+	}
+
+	ticket, err := s.flashSaleStore.GetTicket(ctx, uint(req.SaleId), req.Ticket)
+	if err != nil {
+		if errors.Is(err, flashsale.ErrTicketNotFound) {
+			return &orderv1.GetFlashSaleResultResponse{Code: 40400, Message: err.Error()}, nil
+		}
+		log.Printf("❌ %v", err)
+		return &orderv1.GetFlashSaleResultResponse{Code: 50000, Message: "查询秒杀结果失败"}, nil
+	}
+	if ticket.UserID != uint(req.UserId) {
+		return &orderv1.GetFlashSaleResultResponse{Code: 40400, Message: flashsale.ErrTicketNotFound.Error()}, nil
+	}
+
+	return &orderv1.GetFlashSaleResultResponse{
+		Code:    0,
+		Status:  string(ticket.Status),
+		OrderId: uint64(ticket.OrderID),
+		Reason:  ticket.Reason,
+	}, nil
+}
+
+// HandleFlashSaleAdmitted 消费秒杀排队消息：异步创建订单
+//
+// 教学要点：
+// 1. 削峰：消费者按固定速度取消息（Qos=1），瞬时流量在队列中堆积，MySQL只承受成功者的写入
+// 2. 复用下单Saga（按秒杀价）：预占库存、待支付队列、崩溃恢复与普通订单完全一致
+// 3. 幂等：Consumer按消息ID（凭证ID）去重；凭证不是排队状态时直接确认，不会重复建单
+// 4. 建单失败（库存预占失败等）：Saga已自动补偿，这里退回活动库存和限购额度
+// 5. 建单成功后超时未支付的订单按普通订单取消并释放库存，名额不退回活动库存（视为放弃资格）
+func (s *OrderServiceServer) HandleFlashSaleAdmitted(ctx context.Context, body []byte) error {
+	var e events.FlashSaleAdmittedEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		log.Printf("⚠️ 秒杀排队消息格式错误，丢弃: %v", err)
+		return nil
+	}
+	saleID := uint(e.SaleID)
+
+	ticket, err := s.flashSaleStore.GetTicket(ctx, saleID, e.TicketID)
+	if err != nil {
+		if errors.Is(err, flashsale.ErrTicketNotFound) {
+			log.Printf("⚠️ 秒杀凭证不存在或已过期，忽略 (sale_id=%d, ticket=%s)", e.SaleID, e.TicketID)
+			return nil
+		}
+		return err
+	}
+	if ticket.Status != flashsale.TicketQueued {
+		log.Printf("🔁 秒杀凭证已处理 (sale_id=%d, ticket=%s, status=%s)", e.SaleID, e.TicketID, ticket.Status)
+		return nil
+	}
+
+	sagaCtx := &CreateOrderSagaContext{
+		userID: uint(e.UserID),
+		items: []*orderv1.OrderItem{
+			{BookId: e.BookID, Quantity: int32(e.Quantity)},
+		},
+		prices:     map[uint]int64{uint(e.BookID): e.Price},
+		orderItems: make([]order.OrderItem, 0),
+	}
+	orderSaga := s.buildCreateOrderSaga(sagaCtx)
+	if err := orderSaga.Execute(ctx); errors.Is(err, saga.ErrCompletionNotLogged) {
+		log.Printf("⚠️ 秒杀订单已创建，saga状态待恢复任务补记 (saga_id=%s): %v", orderSaga.ID(), err)
+	} else if err != nil {
+		log.Printf("❌ 秒杀建单失败，退回名额 (sale_id=%d, ticket=%s): %v", e.SaleID, e.TicketID, err)
+		if _, err := s.flashSaleStore.Refund(ctx, saleID, e.TicketID, "下单失败，请重新抢购"); err != nil {
+			return err
+		}
+		s.soldOut.Delete(saleID)
+		return nil
+	}
+
+	// 订单已创建：标记失败只影响结果查询，不能返回错误重试（会重复建单）
+	if err := s.flashSaleStore.MarkTicketSuccess(ctx, saleID, e.TicketID, sagaCtx.orderEntity.ID); err != nil {
+		log.Printf("⚠️ %v (sale_id=%d, ticket=%s, order_id=%d)", err, e.SaleID, e.TicketID, sagaCtx.orderEntity.ID)
+	}
+	log.Printf("⚡ 秒杀订单创建成功 (sale_id=%d, order_no=%s)", e.SaleID, sagaCtx.orderEntity.OrderNo)
+	return nil
+}
+
+// WarmUpFlashSales 预热即将开始的秒杀活动（由定时任务调用）
+//
+// 返回本轮预热成功的活动数，单个活动失败不影响其他活动
+func (s *OrderServiceServer) WarmUpFlashSales(ctx context.Context) (int, error) {
+	now := time.Now()
+	sales, err := s.flashSaleRepo.ListToWarm(ctx, now, now.Add(s.flashSaleWarmupAhead()), 100)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	warmed := 0
+	for _, sale := range sales {
+		if err := s.warmUpFlashSale(ctx, sale); err != nil {
+			errs = append(errs, fmt.Errorf("活动[%d]: %w", sale.ID, err))
+			continue
+		}
+		warmed++
+	}
+	return warmed, errors.Join(errs...)
+}
+
+// warmUpFlashSale 预热单个活动：写入Redis后再标记已预热（崩溃时下一轮重新预热，SETNX保证不重置库存）
+func (s *OrderServiceServer) warmUpFlashSale(ctx context.Context, sale *flashsale.FlashSale) error {
+	if err := s.flashSaleStore.WarmUp(ctx, sale, s.flashSaleRetention()); err != nil {
+		return err
+	}
+	if err := s.flashSaleRepo.MarkWarmed(ctx, sale.ID, time.Now()); err != nil {
+		return err
+	}
+	log.Printf("🔥 秒杀活动已预热 (sale_id=%d, stock=%d, start_at=%s)", sale.ID, sale.Stock, sale.StartAt.Format(time.DateTime))
+	return nil
+}
+
+// loadFlashSale 从进程内缓存加载活动定义（活动创建后不可修改，缓存无需失效）
+func (s *OrderServiceServer) loadFlashSale(ctx context.Context, saleID uint) (*flashsale.FlashSale, error) {
+	if v, ok := s.flashSales.Load(saleID); ok {
+		return v.(*flashsale.FlashSale), nil
+	}
+	sale, err := s.flashSaleRepo.FindByID(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+	s.flashSales.Store(saleID, sale)
+	return sale, nil
+}
+
+// isFlashSaleSoldOut 检查进程内售罄标记（过期的标记视为未售罄）
+func (s *OrderServiceServer) isFlashSaleSoldOut(saleID uint) bool {
+	v, ok := s.soldOut.Load(saleID)
+	if !ok {
+		return false
+	}
+	if time.Now().After(v.(time.Time)) {
+		s.soldOut.Delete(saleID)
+		return false
+	}
+	return true
+}
+
+// markFlashSaleSoldOutIfEmpty 活动库存为0时设置售罄标记
+//
+// 标记有效期很短：建单失败会退回名额，标记过期后其他用户仍可抢到
+func (s *OrderServiceServer) markFlashSaleSoldOutIfEmpty(ctx context.Context, saleID uint) {
+	stocks, err := s.flashSaleStore.RemainingStocks(ctx, []uint{saleID})
+	if err != nil {
+		return
+	}
+	if stock, ok := stocks[saleID]; ok && stock <= 0 {
+		s.soldOut.Store(saleID, time.Now().Add(time.Duration(s.cfg.FlashSale.SoldOutMarkMs)*time.Millisecond))
+	}
+}
+
+// flashSaleWarmupAhead 提前预热时间
+func (s *OrderServiceServer) flashSaleWarmupAhead() time.Duration {
+	return time.Duration(s.cfg.FlashSale.WarmupAheadSeconds) * time.Second
+}
+
+// flashSaleRetention 活动结束后Redis数据的保留时长
+func (s *OrderServiceServer) flashSaleRetention() time.Duration {
+	return time.Duration(s.cfg.FlashSale.RetentionHours) * time.Hour
+}
+
+// flashSaleTicketTTL 凭证有效期：与活动Key同时过期（活动结束后保留一段时间）
+func (s *OrderServiceServer) flashSaleTicketTTL(sale *flashsale.FlashSale, now time.Time) time.Duration {
+	return sale.EndAt.Add(s.flashSaleRetention()).Sub(now)
+}
+
+// toFlashSaleProtos 批量转换活动（附带Redis中的剩余活动库存）
+func (s *OrderServiceServer) toFlashSaleProtos(ctx context.Context, sales []*flashsale.FlashSale) []*orderv1.FlashSale {
+	ids := make([]uint, 0, len(sales))
+	for _, sale := range sales {
+		ids = append(ids, sale.ID)
+	}
+	stocks, err := s.flashSaleStore.RemainingStocks(ctx, ids)
+	if err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+
+	now := time.Now()
+	protos := make([]*orderv1.FlashSale, 0, len(sales))
+	for _, sale := range sales {
+		remaining, ok := stocks[sale.ID]
+		if !ok {
+			remaining = sale.Stock
+		}
+		protos = append(protos, toFlashSaleProto(sale, remaining, now))
+	}
+	return protos
+}
+
+// toFlashSaleProto 领域对象 → Protobuf
+func toFlashSaleProto(sale *flashsale.FlashSale, remaining int, now time.Time) *orderv1.FlashSale {
+	return &orderv1.FlashSale{
+		Id:             uint64(sale.ID),
+		BookId:         uint64(sale.BookID),
+		BookTitle:      sale.BookTitle,
+		Price:          sale.Price,
+		OriginalPrice:  sale.OriginalPrice,
+		Stock:          int32(sale.Stock),
+		RemainingStock: int32(max(remaining, 0)),
+		PerUserLimit:   int32(sale.PerUserLimit),
+		StartAt:        sale.StartAt.Unix(),
+		EndAt:          sale.EndAt.Unix(),
+		Phase:          string(sale.PhaseAt(now)),
+	}
+}
@@ -2,12 +2,16 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
+	"github.com/xiebiao/bookstore/pkg/pagination"
 	"github.com/xiebiao/bookstore/pkg/saga"
 	orderv1 "github.com/xiebiao/bookstore/proto/orderv1"
+	"github.com/xiebiao/bookstore/services/order-service/internal/domain/flashsale"
 	"github.com/xiebiao/bookstore/services/order-service/internal/domain/order"
 	"github.com/xiebiao/bookstore/services/order-service/internal/infrastructure/config"
 	"github.com/xiebiao/bookstore/services/order-service/internal/infrastructure/grpc_client"
@@ -21,6 +25,13 @@ type OrderServiceServer struct {
 	cache           redisStore.OrderCache
 	inventoryClient *grpc_client.InventoryClient
 	catalogClient   *grpc_client.CatalogClient
+	sagaStore       saga.Store
+	sagaRecoverer   *saga.Recoverer // RegisterSagaCompensators注入，管理接口重新驱动卡住的补偿
+	flashSaleRepo   flashsale.Repository
+	flashSaleStore  redisStore.FlashSaleStore
+	publisher       EventPublisher // 发布秒杀排队消息
+	flashSales      sync.Map       // 秒杀活动定义的进程内缓存（saleID → *flashsale.FlashSale）
+	soldOut         sync.Map       // 秒杀售罄标记（saleID → 标记过期时间）
 	cfg             *config.Config
 }
 
@@ -29,6 +40,10 @@ func NewOrderServiceServer(
 	cache redisStore.OrderCache,
 	inventoryClient *grpc_client.InventoryClient,
 	catalogClient *grpc_client.CatalogClient,
+	sagaStore saga.Store,
+	flashSaleRepo flashsale.Repository,
+	flashSaleStore redisStore.FlashSaleStore,
+	publisher EventPublisher,
 	cfg *config.Config,
 ) *OrderServiceServer {
 	return &OrderServiceServer{
@@ -36,6 +51,10 @@ func NewOrderServiceServer(
 		cache:           cache,
 		inventoryClient: inventoryClient,
 		catalogClient:   catalogClient,
+		sagaStore:       sagaStore,
+		flashSaleRepo:   flashSaleRepo,
+		flashSaleStore:  flashSaleStore,
+		publisher:       publisher,
 		cfg:             cfg,
 	}
 }
@@ -48,18 +67,21 @@ func NewOrderServiceServer(
 //
 // Saga流程：
 // 1. 查询图书信息（catalog-service）
-// 2. 扣减库存（inventory-service）
-// 3. 创建订单（order-service）
+// 2. 创建订单（order-service，预留订单号和订单ID）
+// 3. 预占库存（inventory-service，以订单ID为幂等键，支付成功后确认）
 // 4. 添加到待支付队列（Redis）
 //
 // 补偿流程（任一步骤失败）：
-// - 如果步骤3失败：释放步骤2扣减的库存
-// - 如果步骤4失败：取消步骤3创建的订单 + 释放步骤2的库存
+// - 如果步骤3失败：取消步骤3的库存预占 + 取消步骤2创建的订单
+// - 如果步骤4失败：取消步骤3的库存预占 + 取消步骤2创建的订单
 //
 // 教学要点：
 // - Saga步骤拆分粒度：每个步骤应该是原子操作
-// - 补偿幂等性：使用订单ID作为幂等键
+// - 补偿幂等性：使用订单ID作为幂等键（预占和取消都按订单ID去重）
+// - 为什么先建单再预占库存？预占前必须拿到订单ID，否则库存流水无法与订单对账
+// - 为什么预占而不是直接扣减？未支付订单只锁定库存，支付成功才售出（见payment_event_handler.go）
 // - 超时控制：整体超时30秒（可配置）
+// - 故障恢复：Saga日志落库，进程崩溃后由恢复任务继续补偿（见order_saga_recovery.go）
 func (s *OrderServiceServer) CreateOrder(ctx context.Context, req *orderv1.CreateOrderRequest) (*orderv1.CreateOrderResponse, error) {
 	// 1. 参数校验
 	if err := s.validateCreateOrderRequest(req); err != nil {
@@ -68,19 +90,20 @@ func (s *OrderServiceServer) CreateOrder(ctx context.Context, req *orderv1.Creat
 
 	// 2. 准备Saga上下文数据
 	sagaCtx := &CreateOrderSagaContext{
-		userID:          uint(req.UserId),
-		items:           req.Items,
-		orderItems:      make([]order.OrderItem, 0),
-		deductedBookIDs: make([]uint, 0),
-		total:           0,
-		orderEntity:     nil,
+		userID:      uint(req.UserId),
+		items:       req.Items,
+		orderItems:  make([]order.OrderItem, 0),
+		total:       0,
+		orderEntity: nil,
 	}
 
 	// 3. 构建Saga流程
 	orderSaga := s.buildCreateOrderSaga(sagaCtx)
 
-	// 4. 执行Saga
-	if err := orderSaga.Execute(ctx); err != nil {
+	// 4. 执行Saga（只差COMPLETED没写进去时订单已创建，按成功返回，由恢复任务补记）
+	if err := orderSaga.Execute(ctx); errors.Is(err, saga.ErrCompletionNotLogged) {
+		log.Printf("⚠️ 订单已创建，saga状态待恢复任务补记 (saga_id=%s): %v", orderSaga.ID(), err)
+	} else if err != nil {
 		log.Printf("❌ 订单Saga执行失败: %v", err)
 		return &orderv1.CreateOrderResponse{
 			Code:    50000,
@@ -102,19 +125,19 @@ func (s *OrderServiceServer) CreateOrder(ctx context.Context, req *orderv1.Creat
 // CreateOrderSagaContext Saga执行上下文（存储中间状态）
 //
 // 为什么需要上下文？
-// - 步骤之间需要传递数据（如订单ID、扣减的库存）
+// - 步骤之间需要传递数据（如订单ID、订单金额）
 // - 补偿操作需要访问正向操作的结果
 //
 // 设计要点：
 // - 使用结构体封装，避免全局变量
 // - 字段可导出，便于测试
 type CreateOrderSagaContext struct {
-	userID          uint
-	items           []*orderv1.OrderItem
-	orderItems      []order.OrderItem // 查询图书后构建的订单明细
-	deductedBookIDs []uint            // 已扣减库存的图书ID（用于补偿）
-	total           int64             // 订单总金额
-	orderEntity     *order.Order      // 创建的订单实体
+	userID      uint
+	items       []*orderv1.OrderItem
+	orderItems  []order.OrderItem // 查询图书后构建的订单明细
+	total       int64             // 订单总金额
+	orderEntity *order.Order      // 创建的订单实体
+	prices      map[uint]int64    // 成交价覆盖（秒杀订单按秒杀价，bookID → 分），为空时按图书售价
 }
 
 // buildCreateOrderSaga 构建创建订单的Saga流程
@@ -123,8 +146,15 @@ type CreateOrderSagaContext struct {
 // - 使用闭包捕获sagaCtx，避免全局变量
 // - 每个步骤独立，便于单元测试
 // - 补偿操作与正向操作对应
+// - 有补偿的步骤先SetStepData再调用下游：崩溃后恢复任务只能依赖日志中的数据
+// - 补偿按配置重试（saga.compensate_retry），重试耗尽写入卡住的补偿记录
 func (s *OrderServiceServer) buildCreateOrderSaga(sagaCtx *CreateOrderSagaContext) *saga.Saga {
-	orderSaga := saga.NewSaga(30 * time.Second)
+	orderSaga := saga.NewPersistentSaga(
+		"",
+		createOrderSagaName,
+		time.Duration(s.cfg.Saga.Timeout)*time.Second,
+		s.sagaStore,
+	)
 
 	// ==================== 步骤1：查询图书信息 ====================
 	orderSaga.AddStep("查询图书信息",
@@ -139,6 +169,9 @@ func (s *OrderServiceServer) buildCreateOrderSaga(sagaCtx *CreateOrderSagaContex
 				if err != nil || bookResp.Code != 0 {
 					return fmt.Errorf("图书[%d]不存在", item.BookId)
 				}
+				if !grpc_client.IsBookOnSale(bookResp.Book) {
+					return fmt.Errorf("图书[%d]未在售", item.BookId)
+				}
 
 				// 构建订单明细（冗余存储图书信息）
 				orderItem := order.OrderItem{
@@ -147,6 +180,9 @@ func (s *OrderServiceServer) buildCreateOrderSaga(sagaCtx *CreateOrderSagaContex
 					Quantity:  int(item.Quantity),
 					Price:     bookResp.Book.Price,
 				}
+				if price, ok := sagaCtx.prices[orderItem.BookID]; ok {
+					orderItem.Price = price
+				}
 				sagaCtx.orderItems = append(sagaCtx.orderItems, orderItem)
 				sagaCtx.total += int64(orderItem.Quantity) * orderItem.Price
 			}
@@ -156,61 +192,8 @@ func (s *OrderServiceServer) buildCreateOrderSaga(sagaCtx *CreateOrderSagaContex
 		nil,
 	)
 
-	// ==================== 步骤2：扣减库存 ====================
-	orderSaga.AddStep("扣减库存",
-		// 正向操作：调用inventory-service扣减库存
-		func(ctx context.Context) error {
-			for _, item := range sagaCtx.items {
-				resp, err := s.inventoryClient.DeductStock(
-					ctx,
-					uint(item.BookId),
-					int(item.Quantity),
-					0, // reference_id暂时为0，后续可改为订单ID
-					s.cfg.GetServiceTimeout("inventory"),
-				)
-				if err != nil || resp.Code != 0 {
-					return fmt.Errorf("库存不足[图书:%d]", item.BookId)
-				}
-
-				// 记录已扣减的图书ID（用于补偿）
-				sagaCtx.deductedBookIDs = append(sagaCtx.deductedBookIDs, uint(item.BookId))
-			}
-			return nil
-		},
-		// 补偿操作：释放已扣减的库存
-		//
-		// 幂等性设计：
-		// - inventory-service的ReleaseStock内部应实现幂等
-		// - 建议使用订单ID作为幂等键
-		func(ctx context.Context) error {
-			for _, bookID := range sagaCtx.deductedBookIDs {
-				// 查找对应的数量
-				var quantity int
-				for _, item := range sagaCtx.items {
-					if uint(item.BookId) == bookID {
-						quantity = int(item.Quantity)
-						break
-					}
-				}
-
-				_, err := s.inventoryClient.ReleaseStock(
-					ctx,
-					bookID,
-					quantity,
-					0, // reference_id
-					s.cfg.GetServiceTimeout("inventory"),
-				)
-				if err != nil {
-					log.Printf("⚠️ 释放库存失败[图书:%d]: %v", bookID, err)
-					// 继续执行后续补偿，不中断
-				}
-			}
-			return nil
-		},
-	)
-
-	// ==================== 步骤3：创建订单 ====================
-	orderSaga.AddStep("创建订单",
+	// ==================== 步骤2：创建订单（预留订单号和订单ID） ====================
+	orderSaga.AddStepWithRetry("创建订单",
 		// 正向操作：创建订单记录
 		func(ctx context.Context) error {
 			sagaCtx.orderEntity = &order.Order{
@@ -221,6 +204,14 @@ func (s *OrderServiceServer) buildCreateOrderSaga(sagaCtx *CreateOrderSagaContex
 				Items:   sagaCtx.orderItems,
 			}
 
+			// 先记录订单号：INSERT成功但未来得及回写ID时崩溃，恢复任务按订单号取消
+			//
+			// 订单先于预占库存创建：预占、确认、取消都以订单ID为幂等键，
+			// inventory_logs.order_id因此可以与订单对账
+			if err := saga.SetStepData(ctx, orderStepData{OrderNo: sagaCtx.orderEntity.OrderNo}); err != nil {
+				return err
+			}
+
 			if err := s.repo.Create(ctx, sagaCtx.orderEntity); err != nil {
 				return fmt.Errorf("创建订单失败: %w", err)
 			}
@@ -232,23 +223,72 @@ func (s *OrderServiceServer) buildCreateOrderSaga(sagaCtx *CreateOrderSagaContex
 		// - 方案1：删除订单记录（简单但丢失审计信息）
 		// - 方案2：更新订单状态为CANCELLED（保留审计信息）✅
 		func(ctx context.Context) error {
-			if sagaCtx.orderEntity != nil && sagaCtx.orderEntity.ID > 0 {
-				// 更新订单状态为已取消
-				if err := sagaCtx.orderEntity.UpdateStatus(order.OrderStatusCancelled); err != nil {
-					return err
-				}
-				if err := s.repo.Update(ctx, sagaCtx.orderEntity); err != nil {
-					log.Printf("⚠️ 取消订单失败[订单:%s]: %v", sagaCtx.orderEntity.OrderNo, err)
+			if sagaCtx.orderEntity == nil {
+				return nil
+			}
+			return s.cancelSagaOrder(ctx, orderStepData{OrderNo: sagaCtx.orderEntity.OrderNo})
+		},
+		s.compensateRetry("创建订单"),
+	)
+
+	// ==================== 步骤3：预占库存（以订单ID为幂等键） ====================
+	orderSaga.AddStepWithRetry("预占库存",
+		// 正向操作：调用inventory-service预占库存（锁定，支付成功后确认）
+		func(ctx context.Context) error {
+			// 先记录待预占明细：崩溃恢复时对全部明细取消预占，未预占的图书取消为空操作
+			orderID := sagaCtx.orderEntity.ID
+			stockData := newStockStepData(sagaCtx.items, orderID)
+			if err := saga.SetStepData(ctx, stockData); err != nil {
+				return err
+			}
+
+			// 一次批量预占：全部预占或一本都不占（同一订单重复预占由inventory-service按订单ID去重）
+			resp, err := s.inventoryClient.BatchReserveStock(
+				ctx,
+				stockData.stockItems(),
+				orderID,
+				s.cfg.Order.ReservationTTL(),
+				s.cfg.GetServiceTimeout("inventory"),
+			)
+			if err != nil {
+				// RPC超时时脚本可能已经执行，而失败的步骤不会被Saga补偿（恢复任务也不补偿FAILED步骤），
+				// 在这里取消全部明细，否则实际已预占的图书要锁到预占过期才释放
+				if cancelErr := s.cancelReservedStock(ctx, stockData); cancelErr != nil {
+					log.Printf("⚠️ 取消预占失败，等待预占过期释放[订单:%d]: %v", orderID, cancelErr)
 				}
+				return fmt.Errorf("预占库存失败: %w", err)
+			}
+			if resp.Code == 40100 {
+				return fmt.Errorf("库存不足[图书:%d]", resp.InsufficientBookId)
+			}
+			if resp.Code != 0 {
+				return fmt.Errorf("预占库存失败: %s", resp.Message)
 			}
 			return nil
 		},
+		// 补偿操作：取消预占
+		//
+		// 幂等性设计：
+		// - inventory-service的CancelReservation内部实现幂等（Lua脚本 + 预占状态）
+		// - 对全部明细取消：RPC超时但实际已预占的图书也能退回，未预占的返回"不存在"视为成功
+		// - 只在本步骤成功、后续步骤失败时调用；本步骤RPC失败时由正向操作取消
+		func(ctx context.Context) error {
+			return s.cancelReservedStock(ctx, newStockStepData(sagaCtx.items, sagaCtx.orderEntity.ID))
+		},
+		s.compensateRetry("预占库存"),
 	)
 
 	// ==================== 步骤4：添加到待支付队列 ====================
-	orderSaga.AddStep("添加到待支付队列",
+	orderSaga.AddStepWithRetry("添加到待支付队列",
 		// 正向操作：将订单加入Redis ZSet（15分钟后过期）
 		func(ctx context.Context) error {
+			if err := saga.SetStepData(ctx, orderStepData{
+				OrderNo: sagaCtx.orderEntity.OrderNo,
+				OrderID: sagaCtx.orderEntity.ID,
+			}); err != nil {
+				return err
+			}
+
 			expireAt := time.Now().Add(time.Duration(s.cfg.Order.PaymentTimeout) * time.Minute)
 			if err := s.cache.SetPendingOrder(ctx, sagaCtx.orderEntity.ID, expireAt); err != nil {
 				return fmt.Errorf("添加到待支付队列失败: %w", err)
@@ -257,13 +297,15 @@ func (s *OrderServiceServer) buildCreateOrderSaga(sagaCtx *CreateOrderSagaContex
 		},
 		// 补偿操作：从待支付队列移除
 		func(ctx context.Context) error {
-			if sagaCtx.orderEntity != nil && sagaCtx.orderEntity.ID > 0 {
-				if err := s.cache.RemovePendingOrder(ctx, sagaCtx.orderEntity.ID); err != nil {
-					log.Printf("⚠️ 从待支付队列移除失败[订单:%s]: %v", sagaCtx.orderEntity.OrderNo, err)
-				}
+			if sagaCtx.orderEntity == nil || sagaCtx.orderEntity.ID == 0 {
+				return nil
 			}
-			return nil
+			return s.removeSagaPendingOrder(ctx, orderStepData{
+				OrderNo: sagaCtx.orderEntity.OrderNo,
+				OrderID: sagaCtx.orderEntity.ID,
+			})
 		},
+		s.compensateRetry("添加到待支付队列"),
 	)
 
 	return orderSaga
@@ -366,8 +408,219 @@ func (s *OrderServiceServer) GetOrder(ctx context.Context, req *orderv1.GetOrder
 		return &orderv1.GetOrderResponse{Code: 40400, Message: "订单不存在"}, nil
 	}
 
-	items := make([]*orderv1.OrderItemDetail, 0, len(orderEntity.Items))
-	for _, item := range orderEntity.Items {
+	return &orderv1.GetOrderResponse{
+		Code:  0,
+		Order: toOrderProto(orderEntity),
+	}, nil
+}
+
+// 订单列表的排序方式（固定为创建时间倒序）
+var userOrdersSortKey = pagination.SortKey("created_at", "desc")
+
+// ListUserOrders 查询用户订单列表
+//
+// 教学要点：
+// 1. 两种分页方式
+//   - 页码分页（page）：兼容旧客户端，深翻页慢，翻页期间下新单会重复
+//   - 游标分页（cursor）：用上一页返回的next_cursor请求下一页，任何深度都一样快
+//
+// 2. 两种方式都返回next_cursor，客户端可以第一页用page=1，之后改用游标
+// 3. 游标分页多查一条，用来判断是否还有下一页
+func (s *OrderServiceServer) ListUserOrders(ctx context.Context, req *orderv1.ListUserOrdersRequest) (*orderv1.ListUserOrdersResponse, error) {
+	if req.UserId == 0 {
+		return &orderv1.ListUserOrdersResponse{Code: 40000, Message: "用户ID不能为空"}, nil
+	}
+	status := order.OrderStatus(req.Status)
+	if status != 0 && !status.IsValid() {
+		return &orderv1.ListUserOrdersResponse{Code: 40000, Message: "订单状态不正确"}, nil
+	}
+
+	page := int(req.Page)
+	if page < 1 {
+		page = 1
+	}
+	pageSize := int(req.PageSize)
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	var (
+		orders  []*order.Order
+		total   int64
+		hasMore bool
+		err     error
+	)
+	if req.Cursor != "" {
+		c, decodeErr := pagination.Decode(req.Cursor, userOrdersSortKey)
+		if decodeErr != nil {
+			return &orderv1.ListUserOrdersResponse{Code: 40000, Message: decodeErr.Error()}, nil
+		}
+		after := &order.ListCursor{CreatedAt: time.Unix(0, c.Value), ID: uint(c.ID)}
+
+		orders, err = s.repo.FindByUserIDAfter(ctx, uint(req.UserId), status, after, pageSize+1)
+		if err == nil {
+			total, err = s.repo.CountByUserID(ctx, uint(req.UserId), status)
+		}
+		if hasMore = len(orders) > pageSize; hasMore {
+			orders = orders[:pageSize]
+		}
+	} else {
+		orders, total, err = s.repo.FindByUserID(ctx, uint(req.UserId), page, pageSize, status)
+		hasMore = int64((page-1)*pageSize+len(orders)) < total
+	}
+	if err != nil {
+		log.Printf("❌ 查询订单列表失败 (user_id=%d): %v", req.UserId, err)
+		return &orderv1.ListUserOrdersResponse{Code: 50000, Message: "查询订单列表失败"}, nil
+	}
+
+	resp := &orderv1.ListUserOrdersResponse{
+		Code:    0,
+		Message: "success",
+		Orders:  make([]*orderv1.Order, 0, len(orders)),
+		Total:   uint32(total),
+	}
+	for _, o := range orders {
+		resp.Orders = append(resp.Orders, toOrderProto(o))
+	}
+	if hasMore && len(orders) > 0 {
+		last := orders[len(orders)-1]
+		resp.NextCursor = pagination.Cursor{
+			Sort:  userOrdersSortKey,
+			Value: last.CreatedAt.UnixNano(),
+			ID:    uint64(last.ID),
+		}.Encode()
+	}
+	return resp, nil
+}
+
+// CancelOrder 用户取消待支付订单
+//
+// 教学要点：
+// 1. 归属校验：订单不属于该用户时同样返回"订单不存在"，不泄露订单ID是否存在
+// 2. 条件更新 待支付 → 已取消：与支付成功事件、超时取消任务并发时只有一方成功
+// 3. 已支付的订单需要走退款流程，这里只取消待支付订单
+// 4. 订单状态更新成功后取消库存预占、移出待支付队列（与超时取消共用cancelPendingOrder）
+//
+// 返回码：
+// 0: 成功（含重复取消）
+// 40000: 参数错误或订单状态不可取消
+// 40400: 订单不存在
+// 50000: 系统错误
+func (s *OrderServiceServer) CancelOrder(ctx context.Context, req *orderv1.CancelOrderRequest) (*orderv1.CancelOrderResponse, error) {
+	if req.OrderId == 0 || req.UserId == 0 {
+		return &orderv1.CancelOrderResponse{Code: 40000, Message: "订单ID和用户ID不能为空"}, nil
+	}
+
+	o, err := s.repo.FindByID(ctx, uint(req.OrderId))
+	if err != nil {
+		if errors.Is(err, order.ErrOrderNotFound) {
+			return &orderv1.CancelOrderResponse{Code: 40400, Message: "订单不存在"}, nil
+		}
+		log.Printf("查询订单失败 (order_id=%d): %v", req.OrderId, err)
+		return &orderv1.CancelOrderResponse{Code: 50000, Message: "系统繁忙，请稍后重试"}, nil
+	}
+	if o.UserID != uint(req.UserId) {
+		return &orderv1.CancelOrderResponse{Code: 40400, Message: "订单不存在"}, nil
+	}
+
+	if o.IsCancelled() {
+		return &orderv1.CancelOrderResponse{Code: 0, Message: "订单已取消（幂等性）"}, nil
+	}
+	if !o.IsPending() {
+		return &orderv1.CancelOrderResponse{Code: 40000, Message: "订单状态不正确，只能取消待支付订单"}, nil
+	}
+
+	// 用户填写的取消原因只记录日志，库存侧使用固定的原因码
+	cancelled, err := s.cancelPendingOrder(ctx, o, "order_cancelled")
+	if err != nil {
+		log.Printf("取消订单失败 (order_id=%d): %v", o.ID, err)
+		return &orderv1.CancelOrderResponse{Code: 50000, Message: "系统繁忙，请稍后重试"}, nil
+	}
+	if !cancelled {
+		// 并发支付成功或超时取消，以数据库中的最新状态为准
+		latest, err := s.repo.FindByID(ctx, o.ID)
+		if err == nil && latest.IsCancelled() {
+			return &orderv1.CancelOrderResponse{Code: 0, Message: "订单已取消（幂等性）"}, nil
+		}
+		return &orderv1.CancelOrderResponse{Code: 40000, Message: "订单状态不正确，只能取消待支付订单"}, nil
+	}
+
+	log.Printf("✅ 订单已取消 (order_id=%d, user_id=%d): %s", o.ID, o.UserID, req.Reason)
+	return &orderv1.CancelOrderResponse{Code: 0, Message: "订单已取消"}, nil
+}
+
+// CancelExpiredOrder 取消支付超时的订单（由订单超时任务调用）
+//
+// 订单已不是待支付（已支付或已被用户取消）时只从待支付队列移除
+func (s *OrderServiceServer) CancelExpiredOrder(ctx context.Context, orderID uint) error {
+	o, err := s.repo.FindByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	cancelled := false
+	if o.IsPending() {
+		if cancelled, err = s.cancelPendingOrder(ctx, o, "payment_timeout"); err != nil {
+			return err
+		}
+	}
+	if !cancelled {
+		if err := s.cache.RemovePendingOrder(ctx, orderID); err != nil {
+			log.Printf("⚠️ 从待支付队列移除失败 (order_id=%d): %v", orderID, err)
+		}
+	}
+	return nil
+}
+
+// cancelPendingOrder 取消待支付订单并退回库存（用户取消和超时取消共用）
+//
+// 教学要点：
+//  1. 条件更新 待支付 → 已取消：扫描到超时/用户点取消的同时，支付成功事件可能先一步把订单改为已支付，
+//     此时不能再取消订单和释放库存，返回cancelled=false
+//  2. 取消库存预占（锁定库存退回可用库存）；预占不存在（40401）的是改为预占之前创建的订单，下单时已直接扣减，改为释放库存
+//  3. 库存、队列、缓存的失败只记录日志，不回滚订单：预占到期后由inventory-service自动回收，超时任务扫描到已取消的订单也会移出队列
+//
+// reason为库存侧的原因码（order_cancelled/payment_timeout）
+func (s *OrderServiceServer) cancelPendingOrder(ctx context.Context, o *order.Order, reason string) (cancelled bool, err error) {
+	err = s.repo.UpdateStatusFrom(ctx, o.ID, order.OrderStatusPending, order.OrderStatusCancelled)
+	if errors.Is(err, order.ErrStatusConflict) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	timeout := s.cfg.GetServiceTimeout("inventory")
+	for _, item := range o.Items {
+		resp, err := s.inventoryClient.CancelReservation(ctx, item.BookID, o.ID, reason, timeout)
+		if err != nil {
+			log.Printf("⚠️ 取消库存预占失败，等待预占过期释放 (order_id=%d, book_id=%d): %v", o.ID, item.BookID, err)
+			continue
+		}
+		if resp.Code != 40401 {
+			continue
+		}
+		if _, err := s.inventoryClient.ReleaseStock(ctx, item.BookID, item.Quantity, o.ID, timeout); err != nil {
+			log.Printf("⚠️ 释放库存失败 (order_id=%d, book_id=%d): %v", o.ID, item.BookID, err)
+		}
+	}
+
+	if err := s.cache.RemovePendingOrder(ctx, o.ID); err != nil {
+		log.Printf("⚠️ 从待支付队列移除失败 (order_id=%d): %v", o.ID, err)
+	}
+	if err := s.cache.DeleteOrder(ctx, o.ID); err != nil {
+		log.Printf("⚠️ 删除订单缓存失败 (order_id=%d): %v", o.ID, err)
+	}
+	return true, nil
+}
+
+// toOrderProto 订单实体 → Protobuf消息
+func toOrderProto(o *order.Order) *orderv1.Order {
+	items := make([]*orderv1.OrderItemDetail, 0, len(o.Items))
+	for _, item := range o.Items {
 		items = append(items, &orderv1.OrderItemDetail{
 			Id:        uint64(item.ID),
 			OrderId:   uint64(item.OrderID),
@@ -378,15 +631,14 @@ func (s *OrderServiceServer) GetOrder(ctx context.Context, req *orderv1.GetOrder
 		})
 	}
 
-	return &orderv1.GetOrderResponse{
-		Code: 0,
-		Order: &orderv1.Order{
-			Id:      uint64(orderEntity.ID),
-			OrderNo: orderEntity.OrderNo,
-			UserId:  uint64(orderEntity.UserID),
-			Total:   orderEntity.Total,
-			Status:  int32(orderEntity.Status),
-			Items:   items,
-		},
-	}, nil
+	return &orderv1.Order{
+		Id:        uint64(o.ID),
+		OrderNo:   o.OrderNo,
+		UserId:    uint64(o.UserID),
+		Total:     o.Total,
+		Status:    int32(o.Status),
+		Items:     items,
+		CreatedAt: o.CreatedAt.Unix(),
+		UpdatedAt: o.UpdatedAt.Unix(),
+	}
 }
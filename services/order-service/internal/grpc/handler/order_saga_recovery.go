@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/xiebiao/bookstore/pkg/saga"
+	inventoryv1 "github.com/xiebiao/bookstore/proto/inventoryv1"
+	orderv1 "github.com/xiebiao/bookstore/proto/orderv1"
+	"github.com/xiebiao/bookstore/services/order-service/internal/domain/order"
+)
+
+// createOrderSagaName 下单Saga类型（写入saga_logs.name，恢复时据此查找补偿函数）
+const createOrderSagaName = "create_order"
+
+// compensateRetryKeys 步骤名 → 补偿重试配置key（saga.step_compensate_retry）
+var compensateRetryKeys = map[string]string{
+	"预占库存":     "release_stock",
+	"扣减库存":     "release_stock",
+	"创建订单":     "cancel_order",
+	"添加到待支付队列": "remove_pending_order",
+}
+
+// compensateRetry 获取步骤的补偿重试策略
+//
+// 在线补偿（AddStepWithRetry）和恢复/重新驱动（RegisterWithRetry）使用同一策略
+func (s *OrderServiceServer) compensateRetry(step string) saga.RetryPolicy {
+	c := s.cfg.Saga.GetCompensateRetry(compensateRetryKeys[step])
+	return saga.RetryPolicy{
+		MaxAttempts:    c.MaxAttempts,
+		InitialBackoff: time.Duration(c.InitialBackoffMs) * time.Millisecond,
+		MaxBackoff:     time.Duration(c.MaxBackoffMs) * time.Millisecond,
+		Multiplier:     2,
+	}
+}
+
+// stockStepData "预占库存"（及旧版"扣减库存"）步骤的补偿数据
+type stockStepData struct {
+	ReferenceID uint        `json:"reference_id"` // 预占/扣减时使用的幂等键（订单ID）
+	Items       []stockItem `json:"items"`
+}
+
+type stockItem struct {
+	BookID   uint `json:"book_id"`
+	Quantity int  `json:"quantity"`
+}
+
+func newStockStepData(items []*orderv1.OrderItem, referenceID uint) stockStepData {
+	data := stockStepData{
+		ReferenceID: referenceID,
+		Items:       make([]stockItem, 0, len(items)),
+	}
+	for _, item := range items {
+		data.Items = append(data.Items, stockItem{
+			BookID:   uint(item.BookId),
+			Quantity: int(item.Quantity),
+		})
+	}
+	return data
+}
+
+// stockItems 转换为inventory-service批量接口的明细
+func (d stockStepData) stockItems() []*inventoryv1.StockItem {
+	items := make([]*inventoryv1.StockItem, 0, len(d.Items))
+	for _, item := range d.Items {
+		items = append(items, &inventoryv1.StockItem{
+			BookId:   uint64(item.BookID),
+			Quantity: int32(item.Quantity),
+		})
+	}
+	return items
+}
+
+// orderStepData "创建订单"/"添加到待支付队列"步骤的补偿数据
+type orderStepData struct {
+	OrderNo string `json:"order_no"`
+	OrderID uint   `json:"order_id,omitempty"`
+}
+
+// RegisterSagaCompensators 注册下单Saga的补偿函数（供启动恢复使用）
+//
+// 教学要点：
+// 1. 步骤名必须与buildCreateOrderSaga中AddStep的名称一致
+// 2. 在线补偿（闭包）和恢复补偿（日志数据）调用同一组方法，避免两套逻辑不一致
+// 3. "查询图书信息"没有补偿操作，无需注册
+// 4. 注册后的Recoverer同时供管理接口重新驱动卡住的补偿（见saga_admin_handler.go）
+// 5. "扣减库存"是改为预占之前的步骤名，保留注册以便补偿升级前未完成的Saga
+func (s *OrderServiceServer) RegisterSagaCompensators(r *saga.Recoverer) {
+	r.RegisterWithRetry(createOrderSagaName, "预占库存", func(ctx context.Context, data []byte) error {
+		var d stockStepData
+		if err := decodeStepData(data, &d); err != nil {
+			return err
+		}
+		return s.cancelReservedStock(ctx, d)
+	}, s.compensateRetry("预占库存"))
+
+	r.RegisterWithRetry(createOrderSagaName, "扣减库存", func(ctx context.Context, data []byte) error {
+		var d stockStepData
+		if err := decodeStepData(data, &d); err != nil {
+			return err
+		}
+		return s.releaseDeductedStock(ctx, d)
+	}, s.compensateRetry("扣减库存"))
+
+	r.RegisterWithRetry(createOrderSagaName, "创建订单", func(ctx context.Context, data []byte) error {
+		var d orderStepData
+		if err := decodeStepData(data, &d); err != nil {
+			return err
+		}
+		return s.cancelSagaOrder(ctx, d)
+	}, s.compensateRetry("创建订单"))
+
+	r.RegisterWithRetry(createOrderSagaName, "添加到待支付队列", func(ctx context.Context, data []byte) error {
+		var d orderStepData
+		if err := decodeStepData(data, &d); err != nil {
+			return err
+		}
+		return s.removeSagaPendingOrder(ctx, d)
+	}, s.compensateRetry("添加到待支付队列"))
+
+	s.sagaRecoverer = r
+}
+
+// decodeStepData 反序列化步骤数据（data为空表示Action在SetStepData之前崩溃，无需补偿）
+func decodeStepData(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("解析saga步骤数据失败: %w", err)
+	}
+	return nil
+}
+
+// cancelReservedStock 取消Saga预占的库存
+//
+// 幂等性：
+// - 已取消的返回成功（inventory-service预占状态）
+// - 未预占的返回40401，同样视为成功（补偿时会对全部明细调用取消）
+// - 已确认的返回40102：订单已支付却在补偿，返回错误交由人工处理（重试耗尽后写入卡住的补偿记录）
+func (s *OrderServiceServer) cancelReservedStock(ctx context.Context, d stockStepData) error {
+	var errs []error
+	for _, item := range d.Items {
+		resp, err := s.inventoryClient.CancelReservation(
+			ctx,
+			item.BookID,
+			d.ReferenceID,
+			"order_cancelled",
+			s.cfg.GetServiceTimeout("inventory"),
+		)
+		if err != nil {
+			log.Printf("⚠️ 取消库存预占失败[图书:%d]: %v", item.BookID, err)
+			errs = append(errs, fmt.Errorf("图书[%d]: %w", item.BookID, err))
+			continue
+		}
+		if resp.Code != 0 && resp.Code != 40401 {
+			errs = append(errs, fmt.Errorf("图书[%d]: %s", item.BookID, resp.Message))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// releaseDeductedStock 释放Saga扣减的库存（旧版"扣减库存"步骤）
+//
+// 幂等性：
+// - 一次BatchReleaseStock释放全部明细，未扣减或已释放的图书由inventory-service跳过
+// - 崩溃恢复时会对全部明细调用释放，无需关心扣减进行到哪一步
+//
+// 返回错误即触发重试，重试耗尽写入卡住的补偿记录（不再静默丢失库存）
+func (s *OrderServiceServer) releaseDeductedStock(ctx context.Context, d stockStepData) error {
+	if len(d.Items) == 0 {
+		return nil
+	}
+
+	resp, err := s.inventoryClient.BatchReleaseStock(
+		ctx,
+		d.stockItems(),
+		d.ReferenceID,
+		"order_cancelled",
+		s.cfg.GetServiceTimeout("inventory"),
+	)
+	if err != nil {
+		log.Printf("⚠️ 释放库存失败[订单:%d]: %v", d.ReferenceID, err)
+		return err
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("释放库存失败[订单:%d]: %s", d.ReferenceID, resp.Message)
+	}
+	return nil
+}
+
+// cancelSagaOrder 取消Saga创建的订单
+//
+// 设计选择：
+// - 方案1：删除订单记录（简单但丢失审计信息）
+// - 方案2：更新订单状态为CANCELLED（保留审计信息）✅
+//
+// 订单不存在（INSERT前崩溃）或已不是待支付状态时，视为补偿成功
+//
+// 使用条件更新（PENDING → CANCELLED）：补偿与支付回调并发时，只有一方能改状态，
+// 不会把刚支付成功的订单覆盖为已取消，取消成功时同一事务写入状态变更事件
+func (s *OrderServiceServer) cancelSagaOrder(ctx context.Context, d orderStepData) error {
+	if d.OrderNo == "" {
+		return nil
+	}
+
+	o, err := s.repo.FindByOrderNo(ctx, d.OrderNo)
+	if err != nil {
+		if errors.Is(err, order.ErrOrderNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	err = s.repo.UpdateStatusFrom(ctx, o.ID, order.OrderStatusPending, order.OrderStatusCancelled)
+	if errors.Is(err, order.ErrOrderNotFound) || errors.Is(err, order.ErrStatusConflict) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("取消订单失败[订单:%s]: %w", o.OrderNo, err)
+	}
+	return nil
+}
+
+// removeSagaPendingOrder 从待支付队列移除订单
+func (s *OrderServiceServer) removeSagaPendingOrder(ctx context.Context, d orderStepData) error {
+	if d.OrderID == 0 {
+		return nil
+	}
+	if err := s.cache.RemovePendingOrder(ctx, d.OrderID); err != nil {
+		return fmt.Errorf("从待支付队列移除失败[订单:%s]: %w", d.OrderNo, err)
+	}
+	return nil
+}
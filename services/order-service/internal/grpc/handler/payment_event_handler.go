@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/xiebiao/bookstore/pkg/events"
+	"github.com/xiebiao/bookstore/services/order-service/internal/domain/order"
+)
+
+// HandlePaymentEvent 处理payment-service发布的支付/退款事件
+//
+// 教学要点：
+// 1. 一个队列绑定多个路由键，按消息体中的type字段分发
+// 2. 返回error会延迟重试，多次失败后进入死信队列：只有"重试可能成功"的错误（数据库/Redis异常）才返回
+// 3. 报文格式错误、订单不存在、状态不匹配等重试也不会成功，记录日志后确认消息
+// 4. Consumer已按消息ID去重，但去重记录过期或写入失败时仍可能重复处理：状态推进本身也必须幂等
+func (s *OrderServiceServer) HandlePaymentEvent(ctx context.Context, body []byte) error {
+	var envelope struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		log.Printf("⚠️ 支付事件格式错误，丢弃: %v", err)
+		return nil
+	}
+
+	switch envelope.Type {
+	case events.PaymentSucceeded:
+		var e events.PaymentSucceededEvent
+		if err := json.Unmarshal(body, &e); err != nil {
+			log.Printf("⚠️ 支付成功事件格式错误，丢弃: %v", err)
+			return nil
+		}
+		return s.onPaymentSucceeded(ctx, &e)
+
+	case events.PaymentFailed:
+		var e events.PaymentFailedEvent
+		if err := json.Unmarshal(body, &e); err != nil {
+			log.Printf("⚠️ 支付失败事件格式错误，丢弃: %v", err)
+			return nil
+		}
+		// 订单保持待支付：用户可在超时前重新支付，超时后由定时任务取消并释放库存
+		log.Printf("💸 订单支付失败 (order_id=%d, payment_no=%s): %s", e.OrderID, e.PaymentNo, e.Reason)
+		return nil
+
+	case events.RefundSucceeded:
+		var e events.RefundSucceededEvent
+		if err := json.Unmarshal(body, &e); err != nil {
+			log.Printf("⚠️ 退款成功事件格式错误，丢弃: %v", err)
+			return nil
+		}
+		return s.onRefundSucceeded(ctx, &e)
+
+	default:
+		log.Printf("⚠️ 未知支付事件类型，忽略: %s", envelope.Type)
+		return nil
+	}
+}
+
+// onPaymentSucceeded 支付成功：待支付 → 已支付，确认库存预占，并从待支付队列移除
+//
+// 与超时取消任务存在竞争：
+// - 先支付后超时：条件更新成功，ZSET中的订单被移除，超时任务不再处理
+// - 先超时后支付：订单已取消（库存已释放），只能记录日志由客服退款
+func (s *OrderServiceServer) onPaymentSucceeded(ctx context.Context, e *events.PaymentSucceededEvent) error {
+	o, err := s.repo.FindByID(ctx, uint(e.OrderID))
+	if err != nil {
+		if errors.Is(err, order.ErrOrderNotFound) {
+			log.Printf("⚠️ 支付成功事件对应的订单不存在，忽略 (order_id=%d)", e.OrderID)
+			return nil
+		}
+		return err
+	}
+
+	if o.IsPending() {
+		if e.Amount != o.Total {
+			log.Printf("❌ 支付金额与订单金额不一致，不推进订单 (order_id=%d): 实付%d 应付%d", o.ID, e.Amount, o.Total)
+			return nil
+		}
+
+		err := s.repo.UpdateStatusFrom(ctx, o.ID, order.OrderStatusPending, order.OrderStatusPaid)
+		switch {
+		case err == nil:
+			o.Status = order.OrderStatusPaid
+			log.Printf("✅ 订单已支付 (order_id=%d, payment_no=%s)", o.ID, e.PaymentNo)
+		case errors.Is(err, order.ErrStatusConflict):
+			// 与超时取消并发：以数据库中的最新状态为准
+			if o, err = s.repo.FindByID(ctx, o.ID); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+	}
+
+	switch o.Status {
+	case order.OrderStatusPaid:
+		// 重复投递时再次确认（幂等），确认失败返回错误重试，订单状态已推进不会重复处理
+		if err := s.confirmOrderStock(ctx, o); err != nil {
+			return err
+		}
+	case order.OrderStatusCancelled:
+		log.Printf("❌ 订单已取消但支付成功，需要退款 (order_id=%d, payment_no=%s, amount=%d)", o.ID, e.PaymentNo, e.Amount)
+	}
+
+	if err := s.cache.RemovePendingOrder(ctx, o.ID); err != nil {
+		return fmt.Errorf("从待支付队列移除失败 (order_id=%d): %w", o.ID, err)
+	}
+	if err := s.cache.DeleteOrder(ctx, o.ID); err != nil {
+		log.Printf("⚠️ 删除订单缓存失败 (order_id=%d): %v", o.ID, err)
+	}
+	return nil
+}
+
+// confirmOrderStock 确认订单的库存预占（锁定库存转为已售）
+//
+// - 预占不存在（40401）：改为预占之前创建的订单，下单时已直接扣减，无需确认
+// - 预占已取消（40101）：预占过期被inventory-service回收，库存已退回，需人工补货或退款
+func (s *OrderServiceServer) confirmOrderStock(ctx context.Context, o *order.Order) error {
+	var errs []error
+	for _, item := range o.Items {
+		resp, err := s.inventoryClient.ConfirmReservation(ctx, item.BookID, o.ID, s.cfg.GetServiceTimeout("inventory"))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("图书[%d]: %w", item.BookID, err))
+			continue
+		}
+		switch resp.Code {
+		case 0, 40401:
+		case 40101:
+			log.Printf("❌ 库存预占已过期回收但订单已支付，需要补货或退款 (order_id=%d, book_id=%d)", o.ID, item.BookID)
+		default:
+			errs = append(errs, fmt.Errorf("图书[%d]: %s", item.BookID, resp.Message))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("确认库存预占失败 (order_id=%d): %w", o.ID, err)
+	}
+	return nil
+}
+
+// onRefundSucceeded 退款成功：全额退款时 已支付 → 已取消，部分退款订单状态不变
+func (s *OrderServiceServer) onRefundSucceeded(ctx context.Context, e *events.RefundSucceededEvent) error {
+	if !e.FullyRefunded {
+		log.Printf("💰 订单部分退款 (order_id=%d, refund_no=%s, amount=%d, refunded=%d)",
+			e.OrderID, e.RefundNo, e.Amount, e.RefundedAmount)
+		return nil
+	}
+
+	err := s.repo.UpdateStatusFrom(ctx, uint(e.OrderID), order.OrderStatusPaid, order.OrderStatusCancelled)
+	switch {
+	case err == nil:
+		log.Printf("✅ 订单全额退款，已关闭 (order_id=%d, refund_no=%s)", e.OrderID, e.RefundNo)
+	case errors.Is(err, order.ErrOrderNotFound):
+		log.Printf("⚠️ 退款事件对应的订单不存在，忽略 (order_id=%d)", e.OrderID)
+		return nil
+	case errors.Is(err, order.ErrStatusConflict):
+		// 重复投递（已取消）或已发货后的退款（售后流程处理），订单状态不变
+		log.Printf("ℹ️ 订单当前状态不随退款关闭 (order_id=%d, refund_no=%s)", e.OrderID, e.RefundNo)
+		return nil
+	default:
+		return err
+	}
+
+	if err := s.cache.DeleteOrder(ctx, uint(e.OrderID)); err != nil {
+		log.Printf("⚠️ 删除订单缓存失败 (order_id=%d): %v", e.OrderID, err)
+	}
+	return nil
+}
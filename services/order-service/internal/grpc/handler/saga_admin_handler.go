@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/xiebiao/bookstore/pkg/saga"
+	orderv1 "github.com/xiebiao/bookstore/proto/orderv1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListStuckCompensations 查询卡住的补偿（管理接口）
+//
+// 教学要点：
+// 1. 补偿重试耗尽后写入saga_stuck_compensations，这里按状态/Saga分页列出
+// 2. 返回补偿数据和最后错误，运维据此判断下游是否已恢复、是否可以重新驱动
+func (s *OrderServiceServer) ListStuckCompensations(ctx context.Context, req *orderv1.ListStuckCompensationsRequest) (*orderv1.ListStuckCompensationsResponse, error) {
+	stuckStatus := saga.StuckStatus(req.Status)
+	if stuckStatus != "" && stuckStatus != saga.StuckPending && stuckStatus != saga.StuckResolved {
+		return &orderv1.ListStuckCompensationsResponse{Code: 40000, Message: "状态只能是PENDING或RESOLVED"}, nil
+	}
+
+	page, pageSize := req.Page, req.PageSize
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	items, total, err := s.sagaStore.ListStuck(ctx, saga.StuckFilter{
+		SagaID: req.SagaId,
+		Status: stuckStatus,
+		Offset: int((page - 1) * pageSize),
+		Limit:  int(pageSize),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "查询卡住的补偿失败: %v", err)
+	}
+
+	resp := &orderv1.ListStuckCompensationsResponse{
+		Code:  0,
+		Items: make([]*orderv1.StuckCompensation, 0, len(items)),
+		Total: uint32(total),
+	}
+	for _, item := range items {
+		resp.Items = append(resp.Items, toStuckCompensationProto(item))
+	}
+	return resp, nil
+}
+
+// RedriveCompensation 重新驱动卡住的补偿（管理接口）
+//
+// 流程：使用启动时注册的补偿函数 + 记录中的补偿数据，按步骤的重试策略再执行一轮
+// 成功后记录转为RESOLVED；该Saga没有其他卡住的步骤时，Saga状态转为COMPENSATED
+func (s *OrderServiceServer) RedriveCompensation(ctx context.Context, req *orderv1.RedriveCompensationRequest) (*orderv1.RedriveCompensationResponse, error) {
+	if req.Id == 0 {
+		return &orderv1.RedriveCompensationResponse{Code: 40000, Message: "记录ID不能为空"}, nil
+	}
+	if s.sagaRecoverer == nil {
+		return nil, status.Error(codes.Unavailable, "saga补偿函数未注册")
+	}
+
+	stuck, err := s.sagaRecoverer.Redrive(ctx, req.Id)
+	if err != nil {
+		if errors.Is(err, saga.ErrStuckNotFound) {
+			return &orderv1.RedriveCompensationResponse{Code: 40400, Message: "补偿记录不存在"}, nil
+		}
+		if stuck == nil {
+			return nil, status.Errorf(codes.Internal, "重新驱动补偿失败: %v", err)
+		}
+
+		// 补偿仍失败：记录已累加尝试次数，返回最新状态
+		log.Printf("⚠️ 重新驱动补偿失败[记录:%d saga:%s]: %v", req.Id, stuck.SagaID, err)
+		return &orderv1.RedriveCompensationResponse{
+			Code:    50000,
+			Message: err.Error(),
+			Item:    toStuckCompensationProto(stuck),
+		}, nil
+	}
+
+	log.Printf("✅ 重新驱动补偿成功[记录:%d saga:%s 步骤:%s]", stuck.ID, stuck.SagaID, stuck.StepName)
+	return &orderv1.RedriveCompensationResponse{
+		Code:    0,
+		Message: "补偿成功",
+		Item:    toStuckCompensationProto(stuck),
+	}, nil
+}
+
+// toStuckCompensationProto 领域对象转换为Protobuf消息
+func toStuckCompensationProto(st *saga.StuckCompensation) *orderv1.StuckCompensation {
+	return &orderv1.StuckCompensation{
+		Id:        st.ID,
+		SagaId:    st.SagaID,
+		SagaName:  st.SagaName,
+		StepIndex: int32(st.StepIndex),
+		StepName:  st.StepName,
+		Data:      string(st.Data),
+		Attempts:  int32(st.Attempts),
+		LastError: st.LastError,
+		Status:    string(st.Status),
+		CreatedAt: st.CreatedAt.Unix(),
+		UpdatedAt: st.UpdatedAt.Unix(),
+	}
+}
@@ -20,12 +20,15 @@ import (
 //   - 清晰的配置边界
 //   - 便于扩展
 type Config struct {
-	Server   ServerConfig             `mapstructure:"server"`
-	Database DatabaseConfig           `mapstructure:"database"`
-	Redis    RedisConfig              `mapstructure:"redis"`
-	Order    OrderConfig              `mapstructure:"order"`
-	Services map[string]ServiceConfig `mapstructure:"services"` // 下游服务配置
-	Log      LogConfig                `mapstructure:"log"`
+	Server    ServerConfig             `mapstructure:"server"`
+	Database  DatabaseConfig           `mapstructure:"database"`
+	Redis     RedisConfig              `mapstructure:"redis"`
+	Order     OrderConfig              `mapstructure:"order"`
+	Saga      SagaConfig               `mapstructure:"saga"`
+	MQ        MQConfig                 `mapstructure:"mq"`
+	FlashSale FlashSaleConfig          `mapstructure:"flash_sale"`
+	Services  map[string]ServiceConfig `mapstructure:"services"` // 下游服务配置
+	Log       LogConfig                `mapstructure:"log"`
 }
 
 // ServerConfig gRPC服务配置
@@ -60,6 +63,90 @@ type OrderConfig struct {
 	OrderNoPrefix      string `mapstructure:"order_no_prefix"`       // 订单号前缀
 	MaxItemsPerOrder   int    `mapstructure:"max_items_per_order"`   // 单个订单最多商品种类
 	MaxQuantityPerItem int    `mapstructure:"max_quantity_per_item"` // 单个商品最大数量
+	ReservationGrace   int    `mapstructure:"reservation_grace"`     // 库存预占比支付超时多保留的时间（分钟）
+}
+
+// ReservationTTL 库存预占有效期 = 支付超时 + 宽限期
+//
+// 正常情况下由订单超时任务先取消预占，inventory-service的过期取消只是兜底
+func (c *OrderConfig) ReservationTTL() time.Duration {
+	return time.Duration(c.PaymentTimeout+c.ReservationGrace) * time.Minute
+}
+
+// SagaConfig Saga事务配置
+//
+// 教学要点：
+// RecoveryStaleAfter必须不小于Timeout：
+// 否则恢复任务可能把"正在执行中"的Saga当成崩溃遗留，与正向流程并发补偿
+type SagaConfig struct {
+	Timeout            int `mapstructure:"timeout"`              // 单个Saga整体超时（秒）
+	RecoveryInterval   int `mapstructure:"recovery_interval"`    // 恢复任务扫描间隔（秒）
+	RecoveryStaleAfter int `mapstructure:"recovery_stale_after"` // 超过该时长未更新才视为崩溃遗留（秒）
+
+	CompensateRetry     CompensateRetryConfig            `mapstructure:"compensate_retry"`      // 默认补偿重试策略
+	StepCompensateRetry map[string]CompensateRetryConfig `mapstructure:"step_compensate_retry"` // 按步骤覆盖（key见handler中的步骤标识）
+}
+
+// CompensateRetryConfig 补偿重试配置
+//
+// 教学要点：
+// 重试耗尽后写入saga_stuck_compensations表，由管理接口列出并重新驱动
+type CompensateRetryConfig struct {
+	MaxAttempts      int `mapstructure:"max_attempts"`       // 最大尝试次数（含第一次）
+	InitialBackoffMs int `mapstructure:"initial_backoff_ms"` // 首次重试等待（毫秒），之后每次翻倍
+	MaxBackoffMs     int `mapstructure:"max_backoff_ms"`     // 单次等待上限（毫秒）
+}
+
+// GetCompensateRetry 获取某个步骤的补偿重试配置（未单独配置的字段使用默认值）
+func (c *SagaConfig) GetCompensateRetry(step string) CompensateRetryConfig {
+	retry := c.CompensateRetry
+	override, ok := c.StepCompensateRetry[step]
+	if !ok {
+		return retry
+	}
+	if override.MaxAttempts > 0 {
+		retry.MaxAttempts = override.MaxAttempts
+	}
+	if override.InitialBackoffMs > 0 {
+		retry.InitialBackoffMs = override.InitialBackoffMs
+	}
+	if override.MaxBackoffMs > 0 {
+		retry.MaxBackoffMs = override.MaxBackoffMs
+	}
+	return retry
+}
+
+// MQConfig 消息队列配置
+//
+// 教学要点：
+// 1. 订单服务订阅payment-service发布的支付/退款事件，推进订单状态
+// 2. Queue持久化且由order-service独占命名，服务重启期间的事件不会丢失
+// 3. 订单事件先写入outbox_messages表，由中继每隔RelayIntervalMs毫秒发布，单条事件失败RelayMaxAttempts次后标记FAILED
+// 4. 消费失败最多重试MaxRetries次（延迟从RetryBackoffMs开始翻倍），之后进入死信队列<payment_queue>.dlq
+// 5. 按消息ID在Redis中去重，记录保留DedupRetentionHours小时
+type MQConfig struct {
+	URL                 string `mapstructure:"url"`                   // RabbitMQ连接URL
+	PaymentQueue        string `mapstructure:"payment_queue"`         // 支付事件队列名称
+	RelayIntervalMs     int    `mapstructure:"relay_interval_ms"`     // 发件箱中继扫描间隔（毫秒）
+	RelayMaxAttempts    int    `mapstructure:"relay_max_attempts"`    // 单条事件最多发布次数
+	MaxRetries          int    `mapstructure:"max_retries"`           // 消费失败最多重试次数
+	RetryBackoffMs      int    `mapstructure:"retry_backoff_ms"`      // 首次重试延迟（毫秒）
+	DedupRetentionHours int    `mapstructure:"dedup_retention_hours"` // 去重记录保留时长（小时）
+}
+
+// FlashSaleConfig 秒杀配置
+//
+// 教学要点：
+// 1. 预热：活动开始前WarmupAheadSeconds秒把活动信息和活动库存写入Redis，秒杀期间不查MySQL
+// 2. 排队：抢到名额的请求发布到Queue，由消费者按固定速度建单（削峰），MySQL只承受成功者的写入
+// 3. 凭证和活动Key在活动结束后保留RetentionHours小时，供用户查询抢购结果
+// 4. 售罄标记：进程内缓存"已售罄"SoldOutMarkMs毫秒，期间的请求不再访问Redis
+type FlashSaleConfig struct {
+	WarmupAheadSeconds    int    `mapstructure:"warmup_ahead_seconds"`    // 提前预热时间（秒）
+	WarmupIntervalSeconds int    `mapstructure:"warmup_interval_seconds"` // 预热任务扫描间隔（秒）
+	Queue                 string `mapstructure:"queue"`                   // 秒杀排队队列名称
+	RetentionHours        int    `mapstructure:"retention_hours"`         // 活动结束后Redis数据保留时长（小时）
+	SoldOutMarkMs         int    `mapstructure:"sold_out_mark_ms"`        // 进程内售罄标记有效期（毫秒）
 }
 
 // ServiceConfig 下游服务配置
@@ -134,6 +221,10 @@ func setDefaults(cfg *Config) {
 		cfg.Order.PaymentTimeout = 15 // 默认15分钟
 	}
 
+	if cfg.Order.ReservationGrace == 0 {
+		cfg.Order.ReservationGrace = 5
+	}
+
 	if cfg.Order.MaxItemsPerOrder == 0 {
 		cfg.Order.MaxItemsPerOrder = 20
 	}
@@ -141,6 +232,74 @@ func setDefaults(cfg *Config) {
 	if cfg.Order.MaxQuantityPerItem == 0 {
 		cfg.Order.MaxQuantityPerItem = 99
 	}
+
+	if cfg.Saga.Timeout == 0 {
+		cfg.Saga.Timeout = 30
+	}
+
+	if cfg.Saga.RecoveryInterval == 0 {
+		cfg.Saga.RecoveryInterval = 60
+	}
+
+	if cfg.Saga.RecoveryStaleAfter < cfg.Saga.Timeout {
+		cfg.Saga.RecoveryStaleAfter = cfg.Saga.Timeout * 2
+	}
+
+	if cfg.Saga.CompensateRetry.MaxAttempts == 0 {
+		cfg.Saga.CompensateRetry.MaxAttempts = 3
+	}
+
+	if cfg.Saga.CompensateRetry.InitialBackoffMs == 0 {
+		cfg.Saga.CompensateRetry.InitialBackoffMs = 100
+	}
+
+	if cfg.Saga.CompensateRetry.MaxBackoffMs == 0 {
+		cfg.Saga.CompensateRetry.MaxBackoffMs = 2000
+	}
+
+	if cfg.MQ.PaymentQueue == "" {
+		cfg.MQ.PaymentQueue = "order.payment-events"
+	}
+
+	if cfg.MQ.RelayIntervalMs == 0 {
+		cfg.MQ.RelayIntervalMs = 1000
+	}
+
+	if cfg.MQ.RelayMaxAttempts == 0 {
+		cfg.MQ.RelayMaxAttempts = 100
+	}
+
+	if cfg.MQ.MaxRetries == 0 {
+		cfg.MQ.MaxRetries = 3
+	}
+
+	if cfg.MQ.RetryBackoffMs == 0 {
+		cfg.MQ.RetryBackoffMs = 1000
+	}
+
+	if cfg.MQ.DedupRetentionHours == 0 {
+		cfg.MQ.DedupRetentionHours = 168 // 7天
+	}
+
+	if cfg.FlashSale.WarmupAheadSeconds == 0 {
+		cfg.FlashSale.WarmupAheadSeconds = 300
+	}
+
+	if cfg.FlashSale.WarmupIntervalSeconds == 0 {
+		cfg.FlashSale.WarmupIntervalSeconds = 10
+	}
+
+	if cfg.FlashSale.Queue == "" {
+		cfg.FlashSale.Queue = "order.flash-sale"
+	}
+
+	if cfg.FlashSale.RetentionHours == 0 {
+		cfg.FlashSale.RetentionHours = 24
+	}
+
+	if cfg.FlashSale.SoldOutMarkMs == 0 {
+		cfg.FlashSale.SoldOutMarkMs = 1000
+	}
 }
 
 // GetServiceAddr 获取下游服务地址
@@ -175,6 +334,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("redis.addr 不能为空")
 	}
 
+	if c.MQ.URL == "" {
+		return fmt.Errorf("mq.url 不能为空")
+	}
+
 	// 验证必需的下游服务
 	requiredServices := []string{"inventory", "catalog"}
 	for _, svc := range requiredServices {
@@ -181,6 +181,103 @@ func (c *InventoryClient) ReleaseStock(
 	return resp, nil
 }
 
+// BatchReleaseStock 批量释放库存（一次RPC释放订单的全部图书，幂等）
+//
+// 未扣减或已释放的图书由inventory-service跳过，补偿时可直接传入订单全部明细
+func (c *InventoryClient) BatchReleaseStock(
+	ctx context.Context,
+	items []*inventoryv1.StockItem,
+	orderID uint,
+	reason string,
+	timeout time.Duration,
+) (*inventoryv1.BatchReleaseStockResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := c.client.BatchReleaseStock(ctx, &inventoryv1.BatchReleaseStockRequest{
+		Items:   items,
+		OrderId: uint64(orderID),
+		Reason:  reason,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("批量释放库存RPC调用失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// BatchReserveStock 批量预占库存（下单时锁定订单的全部图书，支付成功后逐本确认）
+//
+// 教学要点：
+// - 一次RPC、一个Lua脚本：要么全部预占，要么一本都不占，库存不足时无需补偿
+// - 预占后库存处于锁定状态，未支付订单不会把库存"卖掉"
+// - ttl为预占有效期：订单超时取消失败时，inventory-service到期自动退回库存（兜底）
+func (c *InventoryClient) BatchReserveStock(
+	ctx context.Context,
+	items []*inventoryv1.StockItem,
+	orderID uint,
+	ttl time.Duration,
+	timeout time.Duration,
+) (*inventoryv1.BatchReserveStockResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := c.client.BatchReserveStock(ctx, &inventoryv1.BatchReserveStockRequest{
+		Items:      items,
+		OrderId:    uint64(orderID),
+		TtlSeconds: int32(ttl.Seconds()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("批量预占库存RPC调用失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ConfirmReservation 确认预占（支付成功后调用，幂等）
+func (c *InventoryClient) ConfirmReservation(
+	ctx context.Context,
+	bookID uint,
+	orderID uint,
+	timeout time.Duration,
+) (*inventoryv1.ConfirmReservationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := c.client.ConfirmReservation(ctx, &inventoryv1.ConfirmReservationRequest{
+		BookId:  uint64(bookID),
+		OrderId: uint64(orderID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("确认预占RPC调用失败: %w", err)
+	}
+
+	return resp, nil
+}
+
+// CancelReservation 取消预占（补偿操作、订单超时，幂等）
+func (c *InventoryClient) CancelReservation(
+	ctx context.Context,
+	bookID uint,
+	orderID uint,
+	reason string,
+	timeout time.Duration,
+) (*inventoryv1.CancelReservationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := c.client.CancelReservation(ctx, &inventoryv1.CancelReservationRequest{
+		BookId:  uint64(bookID),
+		OrderId: uint64(orderID),
+		Reason:  reason,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("取消预占RPC调用失败: %w", err)
+	}
+
+	return resp, nil
+}
+
 // BatchGetStock 批量查询库存
 //
 // 教学要点：
@@ -289,6 +386,17 @@ func (c *CatalogClient) GetBook(
 	return resp, nil
 }
 
+// bookStatusOnSale catalog.v1.Book.status中"在售"的取值
+const bookStatusOnSale int32 = 2
+
+// IsBookOnSale 图书是否在售
+//
+// 教学要点：草稿、已下架的图书详情仍可查询（GetBook返回成功），
+// 下单和创建秒杀活动前必须单独检查状态
+func IsBookOnSale(b *catalogv1.Book) bool {
+	return b != nil && b.Status == bookStatusOnSale
+}
+
 // BatchGetBooks 批量查询图书
 //
 // 教学要点：
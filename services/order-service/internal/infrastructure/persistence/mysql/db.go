@@ -5,6 +5,8 @@ import (
 	"log"
 	"time"
 
+	"github.com/xiebiao/bookstore/pkg/outbox"
+	"github.com/xiebiao/bookstore/services/order-service/internal/domain/flashsale"
 	"github.com/xiebiao/bookstore/services/order-service/internal/domain/order"
 	"github.com/xiebiao/bookstore/services/order-service/internal/infrastructure/config"
 	"gorm.io/driver/mysql"
@@ -99,6 +101,11 @@ func InitDB(cfg *config.DatabaseConfig) *gorm.DB {
 	if err := db.AutoMigrate(
 		&order.Order{},
 		&order.OrderItem{},
+		&SagaLogModel{},
+		&SagaStepModel{},
+		&SagaStuckCompensationModel{},
+		&outbox.MessageModel{},
+		&flashsale.FlashSale{},
 	); err != nil {
 		log.Fatalf("数据库迁移失败: %v", err)
 	}
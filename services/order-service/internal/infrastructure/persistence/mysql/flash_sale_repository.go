@@ -0,0 +1,100 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/xiebiao/bookstore/services/order-service/internal/domain/flashsale"
+	"gorm.io/gorm"
+)
+
+// flashSaleRepository 秒杀活动仓储MySQL实现
+type flashSaleRepository struct {
+	db *gorm.DB
+}
+
+// NewFlashSaleRepository 创建秒杀活动仓储
+func NewFlashSaleRepository(db *gorm.DB) flashsale.Repository {
+	return &flashSaleRepository{db: db}
+}
+
+// Create 创建活动
+func (r *flashSaleRepository) Create(ctx context.Context, sale *flashsale.FlashSale) error {
+	if err := r.db.WithContext(ctx).Create(sale).Error; err != nil {
+		return fmt.Errorf("创建秒杀活动失败: %w", err)
+	}
+	return nil
+}
+
+// FindByID 根据ID查询活动
+func (r *flashSaleRepository) FindByID(ctx context.Context, id uint) (*flashsale.FlashSale, error) {
+	var sale flashsale.FlashSale
+	if err := r.db.WithContext(ctx).First(&sale, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, flashsale.ErrFlashSaleNotFound
+		}
+		return nil, fmt.Errorf("查询秒杀活动失败: %w", err)
+	}
+	return &sale, nil
+}
+
+// ListVisible 查询未结束且未取消的活动
+//
+// SQL示例：
+// SELECT * FROM flash_sales WHERE end_at > ? AND status <> 3 ORDER BY start_at ASC, id ASC LIMIT 20 OFFSET 0
+func (r *flashSaleRepository) ListVisible(ctx context.Context, now time.Time, page, pageSize int) ([]*flashsale.FlashSale, int64, error) {
+	var sales []*flashsale.FlashSale
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&flashsale.FlashSale{}).
+		Where("end_at > ? AND status <> ?", now, flashsale.SaleStatusCancelled)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询秒杀活动总数失败: %w", err)
+	}
+
+	err := query.
+		Order("start_at ASC, id ASC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&sales).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询秒杀活动列表失败: %w", err)
+	}
+
+	return sales, total, nil
+}
+
+// ListToWarm 查询需要预热的活动
+//
+// 教学要点：
+// 扫描条件用(status, start_at)过滤，已预热的活动不会重复返回；
+// 预热本身也是幂等的（活动库存用SETNX写入），重复预热不会重置已售出的库存
+func (r *flashSaleRepository) ListToWarm(ctx context.Context, now, before time.Time, limit int) ([]*flashsale.FlashSale, error) {
+	var sales []*flashsale.FlashSale
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND start_at <= ? AND end_at > ?", flashsale.SaleStatusScheduled, before, now).
+		Order("start_at ASC").
+		Limit(limit).
+		Find(&sales).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询待预热秒杀活动失败: %w", err)
+	}
+	return sales, nil
+}
+
+// MarkWarmed 标记活动已预热
+func (r *flashSaleRepository) MarkWarmed(ctx context.Context, id uint, warmedAt time.Time) error {
+	err := r.db.WithContext(ctx).Model(&flashsale.FlashSale{}).
+		Where("id = ? AND status = ?", id, flashsale.SaleStatusScheduled).
+		Updates(map[string]interface{}{
+			"status":    flashsale.SaleStatusWarmed,
+			"warmed_at": warmedAt,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("标记秒杀活动已预热失败: %w", err)
+	}
+	return nil
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/xiebiao/bookstore/pkg/events"
+	"github.com/xiebiao/bookstore/pkg/outbox"
 	"github.com/xiebiao/bookstore/services/order-service/internal/domain/order"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -73,7 +76,9 @@ func (r *orderRepository) Create(ctx context.Context, o *order.Order) error {
 		if err := tx.Create(o).Error; err != nil {
 			return fmt.Errorf("创建订单失败: %w", err)
 		}
-		return nil
+
+		// 同一事务写入order.created事件（发件箱模式，由中继异步发布）
+		return outbox.Write(tx, events.OrderCreated, newOrderCreatedEvent(o))
 	})
 }
 
@@ -149,14 +154,14 @@ func (r *orderRepository) FindByOrderNo(ctx context.Context, orderNo string) (*o
 //   - status>0：筛选特定状态
 //
 // 3. 排序：
-//   - Order("created_at DESC")：最新订单在前
+//   - Order("created_at DESC, id DESC")：最新订单在前，同一时刻创建的按ID
 //
 // 4. 查询总数：
 //   - Count(&total)：不受Offset/Limit影响
 //   - 需要单独查询（性能优化：可以缓存）
 //
 // SQL示例：
-// SELECT * FROM orders WHERE user_id=1 AND status=1 ORDER BY created_at DESC LIMIT 10 OFFSET 0
+// SELECT * FROM orders WHERE user_id=1 AND status=1 ORDER BY created_at DESC, id DESC LIMIT 10 OFFSET 0
 // SELECT COUNT(*) FROM orders WHERE user_id=1 AND status=1
 func (r *orderRepository) FindByUserID(
 	ctx context.Context,
@@ -165,23 +170,13 @@ func (r *orderRepository) FindByUserID(
 	status order.OrderStatus,
 ) ([]*order.Order, int64, error) {
 	var orders []*order.Order
-	var total int64
-
-	// 构建查询条件
-	query := r.db.WithContext(ctx).Model(&order.Order{}).
-		Where("user_id = ?", userID)
-
-	// 状态筛选（0表示查询所有）
-	if status > 0 {
-		query = query.Where("status = ?", status)
-	}
 
 	// 查询总数（分页元信息）
 	// 教学要点：
-	// Count必须在Offset/Limit之前执行
-	// 否则会计算分页后的数量
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("查询订单总数失败: %w", err)
+	// 总数单独查询，不受Offset/Limit影响（游标分页共用）
+	total, err := r.CountByUserID(ctx, userID, status)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	// 分页查询
@@ -190,9 +185,9 @@ func (r *orderRepository) FindByUserID(
 	// - page=1, pageSize=10 → Offset=0
 	// - page=2, pageSize=10 → Offset=10
 	offset := (page - 1) * pageSize
-	err := query.
-		Preload("Items").         // 预加载明细
-		Order("created_at DESC"). // 按创建时间降序
+	err = r.userOrders(ctx, userID, status).
+		Preload("Items").                  // 预加载明细
+		Order("created_at DESC, id DESC"). // 按创建时间降序（同一时刻按ID，保证翻页稳定）
 		Offset(offset).
 		Limit(pageSize).
 		Find(&orders).Error
@@ -204,6 +199,54 @@ func (r *orderRepository) FindByUserID(
 	return orders, total, nil
 }
 
+// FindByUserIDAfter 游标分页查询用户的订单列表
+//
+// SQL示例：
+// SELECT * FROM orders WHERE user_id=1 AND (created_at < '...' OR (created_at = '...' AND id < 42))
+// ORDER BY created_at DESC, id DESC LIMIT 10
+func (r *orderRepository) FindByUserIDAfter(
+	ctx context.Context,
+	userID uint,
+	status order.OrderStatus,
+	after *order.ListCursor,
+	limit int,
+) ([]*order.Order, error) {
+	query := r.userOrders(ctx, userID, status)
+	if after != nil {
+		query = query.Where("(created_at < ? OR (created_at = ? AND id < ?))", after.CreatedAt, after.CreatedAt, after.ID)
+	}
+
+	var orders []*order.Order
+	if err := query.
+		Preload("Items").
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&orders).Error; err != nil {
+		return nil, fmt.Errorf("查询订单列表失败: %w", err)
+	}
+
+	return orders, nil
+}
+
+// CountByUserID 用户的订单总数
+func (r *orderRepository) CountByUserID(ctx context.Context, userID uint, status order.OrderStatus) (int64, error) {
+	var total int64
+	if err := r.userOrders(ctx, userID, status).Count(&total).Error; err != nil {
+		return 0, fmt.Errorf("查询订单总数失败: %w", err)
+	}
+	return total, nil
+}
+
+// userOrders 用户订单的查询条件（status=0表示查询所有）
+func (r *orderRepository) userOrders(ctx context.Context, userID uint, status order.OrderStatus) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&order.Order{}).
+		Where("user_id = ?", userID)
+	if status > 0 {
+		query = query.Where("status = ?", status)
+	}
+	return query
+}
+
 // Update 更新订单
 //
 // 教学要点：
@@ -220,21 +263,32 @@ func (r *orderRepository) FindByUserID(
 //   - 添加version字段
 //   - 更新时WHERE version = ?
 //   - 防止并发更新冲突
+//
+// 4. 状态有变化时，在同一事务中写入order.status_changed事件（发件箱）
 func (r *orderRepository) Update(ctx context.Context, o *order.Order) error {
-	// Save会更新所有字段
-	// SQL: UPDATE orders SET order_no=?, user_id=?, total=?, status=?, updated_at=? WHERE id=?
-	result := r.db.WithContext(ctx).Save(o)
-
-	if result.Error != nil {
-		return fmt.Errorf("更新订单失败: %w", result.Error)
-	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁定当前状态，用于判断本次更新是否变更了状态
+		var current order.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "status").
+			First(&current, o.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return order.ErrOrderNotFound
+			}
+			return fmt.Errorf("查询订单失败: %w", err)
+		}
 
-	// 检查是否更新成功（RowsAffected=0表示订单不存在）
-	if result.RowsAffected == 0 {
-		return order.ErrOrderNotFound
-	}
+		// Save会更新所有字段
+		// SQL: UPDATE orders SET order_no=?, user_id=?, total=?, status=?, updated_at=? WHERE id=?
+		if err := tx.Save(o).Error; err != nil {
+			return fmt.Errorf("更新订单失败: %w", err)
+		}
 
-	return nil
+		if current.Status == o.Status {
+			return nil
+		}
+		return outbox.Write(tx, events.OrderStatusChanged, newOrderStatusChangedEvent(o, current.Status))
+	})
 }
 
 // UpdateStatus 仅更新状态
@@ -255,24 +309,68 @@ func (r *orderRepository) Update(ctx context.Context, o *order.Order) error {
 // ✅ DO: 只更新status字段
 //
 //	repo.UpdateStatus(id, newStatus)
+//
+// 状态有变化时，在同一事务中写入order.status_changed事件（发件箱）
 func (r *orderRepository) UpdateStatus(ctx context.Context, id uint, status order.OrderStatus) error {
-	// Model(&order.Order{}).Where("id = ?", id)：指定更新目标
-	// Update("status", status)：只更新status字段
-	// SQL: UPDATE orders SET status=?, updated_at=? WHERE id=?
-	result := r.db.WithContext(ctx).
-		Model(&order.Order{}).
-		Where("id = ?", id).
-		Update("status", status)
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current order.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "order_no", "user_id", "status").
+			First(&current, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return order.ErrOrderNotFound
+			}
+			return fmt.Errorf("查询订单失败: %w", err)
+		}
+		if current.Status == status {
+			return nil
+		}
 
-	if result.Error != nil {
-		return fmt.Errorf("更新订单状态失败: %w", result.Error)
-	}
+		// Model(&order.Order{}).Where("id = ?", id)：指定更新目标
+		// Update("status", status)：只更新status字段
+		// SQL: UPDATE orders SET status=?, updated_at=? WHERE id=?
+		if err := tx.Model(&order.Order{}).
+			Where("id = ?", id).
+			Update("status", status).Error; err != nil {
+			return fmt.Errorf("更新订单状态失败: %w", err)
+		}
 
-	if result.RowsAffected == 0 {
-		return order.ErrOrderNotFound
-	}
+		from := current.Status
+		current.Status = status
+		return outbox.Write(tx, events.OrderStatusChanged, newOrderStatusChangedEvent(&current, from))
+	})
+}
 
-	return nil
+// UpdateStatusFrom 条件更新状态（乐观并发控制）
+//
+// 教学要点：
+// 1. RowsAffected=0时区分两种情况：订单不存在 vs 状态已被其他流程修改
+// 2. 更新成功时，在同一事务中写入order.status_changed事件（发件箱）
+func (r *orderRepository) UpdateStatusFrom(ctx context.Context, id uint, from, to order.OrderStatus) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// SQL: UPDATE orders SET status=?, updated_at=? WHERE id=? AND status=?
+		result := tx.Model(&order.Order{}).
+			Where("id = ? AND status = ?", id, from).
+			Update("status", to)
+
+		if result.Error != nil {
+			return fmt.Errorf("更新订单状态失败: %w", result.Error)
+		}
+
+		var o order.Order
+		err := tx.Select("id", "order_no", "user_id", "status").First(&o, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return order.ErrOrderNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("查询订单失败: %w", err)
+		}
+		if result.RowsAffected == 0 {
+			return order.ErrStatusConflict
+		}
+
+		return outbox.Write(tx, events.OrderStatusChanged, newOrderStatusChangedEvent(&o, from))
+	})
 }
 
 // Delete 删除订单（软删除）
@@ -359,3 +457,37 @@ func (r *orderItemRepository) FindByBookID(ctx context.Context, bookID uint, lim
 
 	return items, nil
 }
+
+// newOrderCreatedEvent 订单 → order.created事件
+func newOrderCreatedEvent(o *order.Order) events.OrderCreatedEvent {
+	items := make([]events.OrderItemEvent, 0, len(o.Items))
+	for _, item := range o.Items {
+		items = append(items, events.OrderItemEvent{
+			BookID:   uint64(item.BookID),
+			Quantity: item.Quantity,
+			Price:    item.Price,
+		})
+	}
+	return events.OrderCreatedEvent{
+		Type:      events.OrderCreated,
+		OrderID:   uint64(o.ID),
+		OrderNo:   o.OrderNo,
+		UserID:    uint64(o.UserID),
+		Total:     o.Total,
+		Items:     items,
+		CreatedAt: o.CreatedAt.Unix(),
+	}
+}
+
+// newOrderStatusChangedEvent 订单（已是新状态）→ order.status_changed事件
+func newOrderStatusChangedEvent(o *order.Order, from order.OrderStatus) events.OrderStatusChangedEvent {
+	return events.OrderStatusChangedEvent{
+		Type:      events.OrderStatusChanged,
+		OrderID:   uint64(o.ID),
+		OrderNo:   o.OrderNo,
+		UserID:    uint64(o.UserID),
+		From:      int(from),
+		To:        int(o.Status),
+		ChangedAt: time.Now().Unix(),
+	}
+}
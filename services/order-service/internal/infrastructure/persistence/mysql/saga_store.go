@@ -0,0 +1,334 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/xiebiao/bookstore/pkg/saga"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SagaLogModel Saga执行日志表
+//
+// 教学要点：
+// 1. 一次下单 = 一条saga_logs + N条saga_steps
+// 2. (status, updated_at)联合索引：恢复任务按"未完成 + 长时间未更新"扫描
+type SagaLogModel struct {
+	ID        string    `gorm:"primaryKey;type:varchar(64)"`
+	Name      string    `gorm:"type:varchar(64);not null;index"`
+	Status    string    `gorm:"type:varchar(20);not null;index:idx_status_updated,priority:1"`
+	StepCount int       `gorm:"not null;default:0"` // 步骤总数（恢复时判断是否全部步骤都已成功）
+	CreatedAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null;index:idx_status_updated,priority:2"`
+}
+
+// TableName 指定表名
+func (SagaLogModel) TableName() string {
+	return "saga_logs"
+}
+
+// SagaStepModel Saga步骤日志表
+//
+// 教学要点：
+// (saga_id, step_index)唯一索引，SaveStep使用INSERT ... ON DUPLICATE KEY UPDATE实现幂等写入
+type SagaStepModel struct {
+	ID          uint      `gorm:"primaryKey"`
+	SagaID      string    `gorm:"type:varchar(64);not null;uniqueIndex:uk_saga_step,priority:1"`
+	StepIndex   int       `gorm:"not null;uniqueIndex:uk_saga_step,priority:2"`
+	Name        string    `gorm:"type:varchar(64);not null"`
+	Status      string    `gorm:"type:varchar(20);not null"`
+	Compensable bool      `gorm:"not null;default:false"`
+	Data        string    `gorm:"type:text"`          // 补偿数据（JSON）
+	Error       string    `gorm:"type:varchar(1000)"` // 最近一次错误
+	UpdatedAt   time.Time `gorm:"not null"`
+}
+
+// TableName 指定表名
+func (SagaStepModel) TableName() string {
+	return "saga_steps"
+}
+
+// SagaStuckCompensationModel 卡住的补偿记录表（补偿重试耗尽后写入）
+//
+// 教学要点：
+// 1. (saga_id, step_index)唯一索引：同一步骤反复失败只保留一条记录，attempts累加
+// 2. (status, created_at)联合索引：管理接口按"待处理 + 最早优先"列出
+type SagaStuckCompensationModel struct {
+	ID        uint64    `gorm:"primaryKey"`
+	SagaID    string    `gorm:"type:varchar(64);not null;uniqueIndex:uk_saga_step,priority:1"`
+	SagaName  string    `gorm:"type:varchar(64);not null"`
+	StepIndex int       `gorm:"not null;uniqueIndex:uk_saga_step,priority:2"`
+	StepName  string    `gorm:"type:varchar(64);not null"`
+	Data      string    `gorm:"type:text"`
+	Attempts  int       `gorm:"not null;default:0"`
+	LastError string    `gorm:"type:varchar(1000)"`
+	Status    string    `gorm:"type:varchar(20);not null;index:idx_status_created,priority:1"`
+	CreatedAt time.Time `gorm:"not null;index:idx_status_created,priority:2"`
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+// TableName 指定表名
+func (SagaStuckCompensationModel) TableName() string {
+	return "saga_stuck_compensations"
+}
+
+// sagaStore saga.Store的MySQL实现
+type sagaStore struct {
+	db *gorm.DB
+}
+
+// NewSagaStore 创建Saga日志存储
+func NewSagaStore(db *gorm.DB) saga.Store {
+	return &sagaStore{db: db}
+}
+
+// CreateLog 创建Saga日志
+func (s *sagaStore) CreateLog(ctx context.Context, l *saga.Log) error {
+	model := &SagaLogModel{
+		ID:        l.ID,
+		Name:      l.Name,
+		Status:    string(l.Status),
+		StepCount: l.StepCount,
+	}
+	if err := s.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("创建saga日志失败: %w", err)
+	}
+	return nil
+}
+
+// UpdateStatus 更新Saga状态（GORM自动刷新updated_at）
+func (s *sagaStore) UpdateStatus(ctx context.Context, sagaID string, status saga.Status) error {
+	err := s.db.WithContext(ctx).
+		Model(&SagaLogModel{}).
+		Where("id = ?", sagaID).
+		Update("status", string(status)).Error
+	if err != nil {
+		return fmt.Errorf("更新saga状态失败: %w", err)
+	}
+	return nil
+}
+
+// SaveStep 写入步骤日志
+//
+// 教学要点：
+// 步骤写入与saga_logs.updated_at刷新放在同一事务，
+// 保证"最后更新时间"真实反映Saga的推进进度（恢复任务依赖它判断是否崩溃）
+func (s *sagaStore) SaveStep(ctx context.Context, sagaID string, step *saga.StepLog) error {
+	now := time.Now()
+	model := &SagaStepModel{
+		SagaID:      sagaID,
+		StepIndex:   step.Index,
+		Name:        step.Name,
+		Status:      string(step.Status),
+		Compensable: step.Compensable,
+		Data:        string(step.Data),
+		Error:       truncate(step.Error, 1000),
+		UpdatedAt:   now,
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// INSERT ... ON DUPLICATE KEY UPDATE
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "saga_id"}, {Name: "step_index"}},
+			DoUpdates: clause.AssignmentColumns([]string{"status", "compensable", "data", "error", "updated_at"}),
+		}).Create(model).Error
+		if err != nil {
+			return fmt.Errorf("写入saga步骤失败: %w", err)
+		}
+
+		if err := tx.Model(&SagaLogModel{}).Where("id = ?", sagaID).Update("updated_at", now).Error; err != nil {
+			return fmt.Errorf("刷新saga更新时间失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListUnfinished 查询未完成的Saga（含步骤）
+//
+// SQL示例：
+// SELECT * FROM saga_logs WHERE status IN ('RUNNING','COMPENSATING') AND updated_at < ? ORDER BY created_at LIMIT 100
+// SELECT * FROM saga_steps WHERE saga_id IN (...) ORDER BY step_index
+func (s *sagaStore) ListUnfinished(ctx context.Context, updatedBefore time.Time, limit int) ([]*saga.Log, error) {
+	var models []SagaLogModel
+	query := s.db.WithContext(ctx).
+		Where("status IN ?", []string{string(saga.StatusRunning), string(saga.StatusCompensating)}).
+		Where("updated_at < ?", updatedBefore).
+		Order("created_at ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("查询未完成saga失败: %w", err)
+	}
+	if len(models) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(models))
+	for _, m := range models {
+		ids = append(ids, m.ID)
+	}
+
+	var steps []SagaStepModel
+	if err := s.db.WithContext(ctx).
+		Where("saga_id IN ?", ids).
+		Order("step_index ASC").
+		Find(&steps).Error; err != nil {
+		return nil, fmt.Errorf("查询saga步骤失败: %w", err)
+	}
+
+	stepsBySaga := make(map[string][]saga.StepLog, len(models))
+	for _, st := range steps {
+		var data []byte
+		if st.Data != "" {
+			data = []byte(st.Data)
+		}
+		stepsBySaga[st.SagaID] = append(stepsBySaga[st.SagaID], saga.StepLog{
+			Index:       st.StepIndex,
+			Name:        st.Name,
+			Status:      saga.StepStatus(st.Status),
+			Compensable: st.Compensable,
+			Data:        data,
+			Error:       st.Error,
+			UpdatedAt:   st.UpdatedAt,
+		})
+	}
+
+	logs := make([]*saga.Log, 0, len(models))
+	for _, m := range models {
+		logs = append(logs, &saga.Log{
+			ID:        m.ID,
+			Name:      m.Name,
+			Status:    saga.Status(m.Status),
+			StepCount: m.StepCount,
+			Steps:     stepsBySaga[m.ID],
+			CreatedAt: m.CreatedAt,
+			UpdatedAt: m.UpdatedAt,
+		})
+	}
+	return logs, nil
+}
+
+// SaveStuck 写入卡住的补偿记录
+//
+// SQL示例：
+// INSERT INTO saga_stuck_compensations (...) VALUES (...)
+// ON DUPLICATE KEY UPDATE attempts = attempts + VALUES(attempts), last_error = VALUES(last_error), status = 'PENDING', ...
+func (s *sagaStore) SaveStuck(ctx context.Context, stuck *saga.StuckCompensation) error {
+	model := &SagaStuckCompensationModel{
+		SagaID:    stuck.SagaID,
+		SagaName:  stuck.SagaName,
+		StepIndex: stuck.StepIndex,
+		StepName:  stuck.StepName,
+		Data:      string(stuck.Data),
+		Attempts:  stuck.Attempts,
+		LastError: truncate(stuck.LastError, 1000),
+		Status:    string(saga.StuckPending),
+	}
+
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "saga_id"}, {Name: "step_index"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + VALUES(attempts)"),
+			"data":       gorm.Expr("VALUES(data)"),
+			"last_error": gorm.Expr("VALUES(last_error)"),
+			"status":     string(saga.StuckPending),
+			"updated_at": gorm.Expr("VALUES(updated_at)"),
+		}),
+	}).Create(model).Error
+	if err != nil {
+		return fmt.Errorf("写入卡住的补偿记录失败: %w", err)
+	}
+	return nil
+}
+
+// GetStuck 按ID查询卡住的补偿记录
+func (s *sagaStore) GetStuck(ctx context.Context, id uint64) (*saga.StuckCompensation, error) {
+	var model SagaStuckCompensationModel
+	if err := s.db.WithContext(ctx).First(&model, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, saga.ErrStuckNotFound
+		}
+		return nil, fmt.Errorf("查询卡住的补偿记录失败: %w", err)
+	}
+	return model.toStuck(), nil
+}
+
+// ListStuck 分页查询卡住的补偿记录
+func (s *sagaStore) ListStuck(ctx context.Context, filter saga.StuckFilter) ([]*saga.StuckCompensation, int64, error) {
+	query := s.db.WithContext(ctx).Model(&SagaStuckCompensationModel{})
+	if filter.SagaID != "" {
+		query = query.Where("saga_id = ?", filter.SagaID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", string(filter.Status))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计卡住的补偿记录失败: %w", err)
+	}
+
+	var models []SagaStuckCompensationModel
+	query = query.Order("created_at ASC").Order("id ASC").Offset(filter.Offset)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if err := query.Find(&models).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询卡住的补偿记录失败: %w", err)
+	}
+
+	result := make([]*saga.StuckCompensation, 0, len(models))
+	for i := range models {
+		result = append(result, models[i].toStuck())
+	}
+	return result, total, nil
+}
+
+// ResolveStuck 标记卡住的补偿已解决
+func (s *sagaStore) ResolveStuck(ctx context.Context, id uint64) error {
+	result := s.db.WithContext(ctx).
+		Model(&SagaStuckCompensationModel{}).
+		Where("id = ?", id).
+		Update("status", string(saga.StuckResolved))
+	if result.Error != nil {
+		return fmt.Errorf("更新卡住的补偿记录失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return saga.ErrStuckNotFound
+	}
+	return nil
+}
+
+// toStuck 模型转换为领域对象
+func (m *SagaStuckCompensationModel) toStuck() *saga.StuckCompensation {
+	var data []byte
+	if m.Data != "" {
+		data = []byte(m.Data)
+	}
+	return &saga.StuckCompensation{
+		ID:        m.ID,
+		SagaID:    m.SagaID,
+		SagaName:  m.SagaName,
+		StepIndex: m.StepIndex,
+		StepName:  m.StepName,
+		Data:      data,
+		Attempts:  m.Attempts,
+		LastError: m.LastError,
+		Status:    saga.StuckStatus(m.Status),
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+// truncate 按字符截断（避免超出varchar长度）
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max])
+}
@@ -0,0 +1,80 @@
+-- flash_sale_admit.lua
+-- 秒杀抢购判定Lua脚本（原子操作）
+--
+-- 教学要点：
+-- 1. 活动时间、每人限购、活动库存在同一个脚本中判定：
+--    - 不会出现"限购检查通过但库存已被别人扣完"的竞态
+--    - 一次网络往返完成全部判定，秒杀期间Redis是唯一的热点
+--
+-- 2. 抢到名额时同时写入排队凭证：
+--    - 凭证记录用户、数量和状态（QUEUED），异步建单后更新为SUCCESS/FAILED
+--    - 建单失败时按凭证退回活动库存和限购额度（见flash_sale_refund.lua）
+--
+-- KEYS[1]: 活动信息（flashsale:{id}:info，HASH：start_at/end_at/per_user_limit）
+-- KEYS[2]: 活动库存（flashsale:{id}:stock）
+-- KEYS[3]: 用户已抢数量（flashsale:{id}:users，HASH：user_id → 数量）
+-- KEYS[4]: 排队凭证（flashsale:{id}:ticket:{ticket_id}，HASH）
+-- ARGV[1]: 用户ID
+-- ARGV[2]: 抢购数量
+-- ARGV[3]: 当前时间（Unix秒）
+-- ARGV[4]: 凭证有效期（秒）
+--
+-- 返回值：
+--  1: 抢到名额
+--  0: 活动库存不足
+-- -1: 活动未预热
+-- -2: 活动未开始
+-- -3: 活动已结束
+-- -4: 超过每人限购
+
+local info_key = KEYS[1]
+local stock_key = KEYS[2]
+local users_key = KEYS[3]
+local ticket_key = KEYS[4]
+local user_id = ARGV[1]
+local quantity = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ticket_ttl = tonumber(ARGV[4])
+
+-- 活动是否已预热
+local info = redis.call('HMGET', info_key, 'start_at', 'end_at', 'per_user_limit')
+if not info[1] then
+    return -1
+end
+
+-- 活动时间窗口
+if now < tonumber(info[1]) then
+    return -2
+end
+if now >= tonumber(info[2]) then
+    return -3
+end
+
+-- 每人限购（累计已抢数量）
+local bought = tonumber(redis.call('HGET', users_key, user_id) or 0)
+if bought + quantity > tonumber(info[3]) then
+    return -4
+end
+
+-- 活动库存
+local stock = tonumber(redis.call('GET', stock_key) or 0)
+if stock < quantity then
+    return 0
+end
+
+-- 扣减活动库存，累计用户已抢数量，写入排队凭证
+redis.call('DECRBY', stock_key, quantity)
+redis.call('HINCRBY', users_key, user_id, quantity)
+
+-- 用户已抢数量与活动信息同时过期（首次写入时设置）
+if redis.call('TTL', users_key) == -1 then
+    local info_ttl = redis.call('TTL', info_key)
+    if info_ttl > 0 then
+        redis.call('EXPIRE', users_key, info_ttl)
+    end
+end
+
+redis.call('HSET', ticket_key, 'user_id', user_id, 'quantity', quantity, 'status', 'QUEUED')
+redis.call('EXPIRE', ticket_key, ticket_ttl)
+
+return 1
@@ -0,0 +1,43 @@
+-- flash_sale_refund.lua
+-- 秒杀名额退回Lua脚本（原子操作）
+--
+-- 教学要点：
+-- 1. 退回场景
+--    - 排队消息发布失败（名额已扣减但没有进入队列）
+--    - 异步建单失败（库存预占失败、图书下架等）
+--
+-- 2. 幂等性控制
+--    - 只有QUEUED状态的凭证才能退回，退回后改为FAILED
+--    - 重复退回不会让活动库存虚增
+--
+-- KEYS[1]: 活动库存（flashsale:{id}:stock）
+-- KEYS[2]: 用户已抢数量（flashsale:{id}:users）
+-- KEYS[3]: 排队凭证（flashsale:{id}:ticket:{ticket_id}）
+-- ARGV[1]: 失败原因
+--
+-- 返回值：
+-- 0: 凭证不存在或不是排队状态（无需退回）
+-- 1: 退回成功
+
+local stock_key = KEYS[1]
+local users_key = KEYS[2]
+local ticket_key = KEYS[3]
+local reason = ARGV[1]
+
+local ticket = redis.call('HMGET', ticket_key, 'status', 'user_id', 'quantity')
+if ticket[1] ~= 'QUEUED' then
+    return 0
+end
+
+local user_id = ticket[2]
+local quantity = tonumber(ticket[3])
+
+-- 退回活动库存和限购额度（用户可以重新抢购）
+redis.call('INCRBY', stock_key, quantity)
+if redis.call('HINCRBY', users_key, user_id, -quantity) <= 0 then
+    redis.call('HDEL', users_key, user_id)
+end
+
+redis.call('HSET', ticket_key, 'status', 'FAILED', 'reason', reason)
+
+return 1
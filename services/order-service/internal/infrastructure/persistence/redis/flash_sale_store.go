@@ -0,0 +1,202 @@
+package redis
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/xiebiao/bookstore/services/order-service/internal/domain/flashsale"
+)
+
+//go:embed flash_sale_admit.lua
+var flashSaleAdmitLua string
+
+//go:embed flash_sale_refund.lua
+var flashSaleRefundLua string
+
+var (
+	flashSaleAdmitScript  = redis.NewScript(flashSaleAdmitLua)
+	flashSaleRefundScript = redis.NewScript(flashSaleRefundLua)
+)
+
+// FlashSaleStore 秒杀活动Redis存储
+//
+// 教学要点：
+// 1. 秒杀期间的全部判定都在Redis中完成（活动时间、限购、活动库存），MySQL只承受抢到名额后的建单
+// 2. Key使用Hash Tag（flashsale:{id}:xxx）：同一活动的Key落在同一个槽位，迁移到Redis Cluster后Lua脚本仍可操作多个Key
+// 3. 所有Key在活动结束后保留一段时间自动过期，无需清理任务
+type FlashSaleStore interface {
+	// WarmUp 预热活动（写入活动信息和活动库存）
+	//
+	// 活动库存使用SETNX写入：重复预热（如多实例同时扫描）不会重置已售出的库存
+	WarmUp(ctx context.Context, sale *flashsale.FlashSale, retention time.Duration) error
+
+	// Admit 抢购判定，抢到名额时扣减活动库存并写入排队凭证
+	Admit(ctx context.Context, saleID, userID uint, quantity int, ticketID string, now time.Time, ticketTTL time.Duration) (flashsale.AdmitResult, error)
+
+	// Refund 退回名额（活动库存 + 限购额度），凭证改为FAILED；非排队状态的凭证返回false
+	Refund(ctx context.Context, saleID uint, ticketID, reason string) (bool, error)
+
+	// MarkTicketSuccess 标记凭证建单成功
+	MarkTicketSuccess(ctx context.Context, saleID uint, ticketID string, orderID uint) error
+
+	// GetTicket 查询排队凭证（不存在或已过期返回flashsale.ErrTicketNotFound）
+	GetTicket(ctx context.Context, saleID uint, ticketID string) (*flashsale.Ticket, error)
+
+	// RemainingStocks 批量查询剩余活动库存（未预热的活动不在结果中）
+	RemainingStocks(ctx context.Context, saleIDs []uint) (map[uint]int, error)
+}
+
+type flashSaleStore struct {
+	client *redis.Client
+}
+
+// NewFlashSaleStore 创建秒杀活动存储
+func NewFlashSaleStore(client *redis.Client) FlashSaleStore {
+	return &flashSaleStore{client: client}
+}
+
+func flashSaleInfoKey(saleID uint) string {
+	return fmt.Sprintf("flashsale:{%d}:info", saleID)
+}
+
+func flashSaleStockKey(saleID uint) string {
+	return fmt.Sprintf("flashsale:{%d}:stock", saleID)
+}
+
+func flashSaleUsersKey(saleID uint) string {
+	return fmt.Sprintf("flashsale:{%d}:users", saleID)
+}
+
+func flashSaleTicketKey(saleID uint, ticketID string) string {
+	return fmt.Sprintf("flashsale:{%d}:ticket:%s", saleID, ticketID)
+}
+
+// WarmUp 预热活动
+func (s *flashSaleStore) WarmUp(ctx context.Context, sale *flashsale.FlashSale, retention time.Duration) error {
+	expireAt := sale.EndAt.Add(retention)
+	infoKey := flashSaleInfoKey(sale.ID)
+	stockKey := flashSaleStockKey(sale.ID)
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, infoKey,
+			"book_id", sale.BookID,
+			"price", sale.Price,
+			"start_at", sale.StartAt.Unix(),
+			"end_at", sale.EndAt.Unix(),
+			"per_user_limit", sale.PerUserLimit,
+		)
+		pipe.SetNX(ctx, stockKey, sale.Stock, 0)
+		pipe.ExpireAt(ctx, infoKey, expireAt)
+		pipe.ExpireAt(ctx, stockKey, expireAt)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("预热秒杀活动失败: %w", err)
+	}
+	return nil
+}
+
+// Admit 抢购判定
+func (s *flashSaleStore) Admit(
+	ctx context.Context,
+	saleID, userID uint,
+	quantity int,
+	ticketID string,
+	now time.Time,
+	ticketTTL time.Duration,
+) (flashsale.AdmitResult, error) {
+	keys := []string{
+		flashSaleInfoKey(saleID),
+		flashSaleStockKey(saleID),
+		flashSaleUsersKey(saleID),
+		flashSaleTicketKey(saleID, ticketID),
+	}
+	result, err := flashSaleAdmitScript.Run(ctx, s.client, keys,
+		userID, quantity, now.Unix(), int64(ticketTTL/time.Second),
+	).Int()
+	if err != nil {
+		return flashsale.AdmitNotWarmed, fmt.Errorf("执行秒杀脚本失败: %w", err)
+	}
+	return flashsale.AdmitResult(result), nil
+}
+
+// Refund 退回名额
+func (s *flashSaleStore) Refund(ctx context.Context, saleID uint, ticketID, reason string) (bool, error) {
+	keys := []string{
+		flashSaleStockKey(saleID),
+		flashSaleUsersKey(saleID),
+		flashSaleTicketKey(saleID, ticketID),
+	}
+	result, err := flashSaleRefundScript.Run(ctx, s.client, keys, reason).Int()
+	if err != nil {
+		return false, fmt.Errorf("执行秒杀退回脚本失败: %w", err)
+	}
+	return result == 1, nil
+}
+
+// MarkTicketSuccess 标记凭证建单成功
+func (s *flashSaleStore) MarkTicketSuccess(ctx context.Context, saleID uint, ticketID string, orderID uint) error {
+	err := s.client.HSet(ctx, flashSaleTicketKey(saleID, ticketID),
+		"status", string(flashsale.TicketSuccess),
+		"order_id", orderID,
+	).Err()
+	if err != nil {
+		return fmt.Errorf("更新秒杀凭证失败: %w", err)
+	}
+	return nil
+}
+
+// GetTicket 查询排队凭证
+func (s *flashSaleStore) GetTicket(ctx context.Context, saleID uint, ticketID string) (*flashsale.Ticket, error) {
+	fields, err := s.client.HGetAll(ctx, flashSaleTicketKey(saleID, ticketID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("查询秒杀凭证失败: %w", err)
+	}
+	if len(fields) == 0 {
+		return nil, flashsale.ErrTicketNotFound
+	}
+
+	userID, _ := strconv.ParseUint(fields["user_id"], 10, 64)
+	quantity, _ := strconv.Atoi(fields["quantity"])
+	orderID, _ := strconv.ParseUint(fields["order_id"], 10, 64)
+	return &flashsale.Ticket{
+		ID:       ticketID,
+		SaleID:   saleID,
+		UserID:   uint(userID),
+		Quantity: quantity,
+		Status:   flashsale.TicketStatus(fields["status"]),
+		OrderID:  uint(orderID),
+		Reason:   fields["reason"],
+	}, nil
+}
+
+// RemainingStocks 批量查询剩余活动库存
+func (s *flashSaleStore) RemainingStocks(ctx context.Context, saleIDs []uint) (map[uint]int, error) {
+	stocks := make(map[uint]int, len(saleIDs))
+	if len(saleIDs) == 0 {
+		return stocks, nil
+	}
+
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(saleIDs))
+	for i, id := range saleIDs {
+		cmds[i] = pipe.Get(ctx, flashSaleStockKey(id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("查询秒杀活动库存失败: %w", err)
+	}
+
+	for i, cmd := range cmds {
+		stock, err := cmd.Int()
+		if err != nil {
+			continue
+		}
+		stocks[saleIDs[i]] = stock
+	}
+	return stocks, nil
+}
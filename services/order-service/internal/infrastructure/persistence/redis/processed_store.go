@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/xiebiao/bookstore/pkg/mq"
+)
+
+const (
+	processedKeyPrefix = "order:mq:processed:"
+	claimValue         = "processing"
+	processedValue     = "done"
+)
+
+// processedStore 已处理消息存储Redis实现（支付事件消费去重）
+//
+// 教学要点：
+// 1. Claim使用SET NX EX：只有一个消费者能占用，占用者崩溃后按TTL自动释放
+// 2. 处理成功后覆盖为done并设置较长的保留时间，期间的重复投递直接确认
+// 3. Key示例：order:mq:processed:order.payment-events:payment-service-42
+type processedStore struct {
+	client *redis.Client
+}
+
+// NewProcessedStore 创建已处理消息存储
+func NewProcessedStore(client *redis.Client) mq.ProcessedStore {
+	return &processedStore{client: client}
+}
+
+// Claim 占用消息处理权
+func (s *processedStore) Claim(ctx context.Context, key string, ttl time.Duration) (mq.ClaimResult, error) {
+	ok, err := s.client.SetNX(ctx, processedKeyPrefix+key, claimValue, ttl).Result()
+	if err != nil {
+		return mq.ClaimInProgress, fmt.Errorf("占用消息处理权失败: %w", err)
+	}
+	if ok {
+		return mq.ClaimAcquired, nil
+	}
+
+	val, err := s.client.Get(ctx, processedKeyPrefix+key).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			// 占用恰好过期：按处理中返回，稍后重投时再抢占
+			return mq.ClaimInProgress, nil
+		}
+		return mq.ClaimInProgress, fmt.Errorf("查询消息处理状态失败: %w", err)
+	}
+	if val == processedValue {
+		return mq.ClaimProcessed, nil
+	}
+	return mq.ClaimInProgress, nil
+}
+
+// MarkProcessed 标记处理完成
+func (s *processedStore) MarkProcessed(ctx context.Context, key string, retention time.Duration) error {
+	if err := s.client.Set(ctx, processedKeyPrefix+key, processedValue, retention).Err(); err != nil {
+		return fmt.Errorf("标记消息已处理失败: %w", err)
+	}
+	return nil
+}
+
+// Release 释放占用（只删除处理中的记录，不删除已完成的记录）
+func (s *processedStore) Release(ctx context.Context, key string) error {
+	val, err := s.client.Get(ctx, processedKeyPrefix+key).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		return fmt.Errorf("查询消息处理状态失败: %w", err)
+	}
+	if val != claimValue {
+		return nil
+	}
+	if err := s.client.Del(ctx, processedKeyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("释放消息处理权失败: %w", err)
+	}
+	return nil
+}
@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net"
+	"time"
 
 	"github.com/spf13/viper"
+	"github.com/xiebiao/bookstore/pkg/events"
+	"github.com/xiebiao/bookstore/pkg/mq"
+	"github.com/xiebiao/bookstore/pkg/outbox"
 	paymentv1 "github.com/xiebiao/bookstore/proto/paymentv1"
 	"github.com/xiebiao/bookstore/services/payment-service/internal/domain/payment"
 	"github.com/xiebiao/bookstore/services/payment-service/internal/grpc/handler"
+	"github.com/xiebiao/bookstore/services/payment-service/internal/infrastructure/gateway"
 	"github.com/xiebiao/bookstore/services/payment-service/internal/infrastructure/persistence/mysql"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
@@ -31,17 +37,51 @@ func main() {
 		log.Fatalf("数据库连接失败: %v", err)
 	}
 
-	db.AutoMigrate(&payment.Payment{})
+	db.AutoMigrate(&payment.Payment{}, &payment.Refund{}, &payment.RefundItem{}, &outbox.MessageModel{})
+	if err := mysql.DropLegacyIndexes(db); err != nil {
+		log.Fatalf("数据库迁移失败: %v", err)
+	}
 	log.Println("✅ payment_db迁移成功")
 
+	// 支付网关（目前只有沙箱实现，接入真实渠道时按gateway.provider选择）
+	if provider := v.GetString("gateway.provider"); provider != "" && provider != gateway.SandboxProvider {
+		log.Fatalf("不支持的支付网关: %s", provider)
+	}
+	paymentGateway, err := gateway.NewSandboxGateway(gateway.SandboxConfig{
+		Secret:        v.GetString("gateway.sandbox.secret"),
+		CallbackURL:   v.GetString("gateway.sandbox.callback_url"),
+		CallbackDelay: time.Duration(v.GetInt("gateway.sandbox.callback_delay_ms")) * time.Millisecond,
+	})
+	if err != nil {
+		log.Fatalf("创建支付网关失败: %v", err)
+	}
+
 	repo := mysql.NewPaymentRepository(db)
+	refundRepo := mysql.NewRefundRepository(db)
 	grpcServer := grpc.NewServer()
-	paymentService := handler.NewPaymentServiceServer(repo)
+	paymentService := handler.NewPaymentServiceServer(repo, refundRepo, paymentGateway)
 	paymentv1.RegisterPaymentServiceServer(grpcServer, paymentService)
 	reflection.Register(grpcServer)
 
+	// 发件箱中继：支付/退款结果事件与状态变更在同一事务写入outbox表，
+	// 这里异步发布到RabbitMQ，由order-service订阅推进订单状态
+	publisher, err := mq.NewPublisher(v.GetString("mq.url"), events.Exchange, events.ExchangeType)
+	if err != nil {
+		log.Fatalf("创建事件发布者失败: %v", err)
+	}
+	defer publisher.Close()
+	relayInterval := time.Duration(v.GetInt("mq.relay_interval_ms")) * time.Millisecond
+	relay := outbox.NewRelay(
+		"payment-service",
+		outbox.NewGormStore(db),
+		publisher,
+		outbox.WithInterval(relayInterval),
+		outbox.WithMaxAttempts(v.GetInt("mq.relay_max_attempts")),
+	)
+	go relay.Run(context.Background())
+
 	lis, _ := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	log.Printf("🚀 payment-service启动，端口:%d", port)
-	log.Printf("💳 Mock支付：70%%成功率")
+	log.Printf("💳 支付网关：%s（回调地址:%s）", paymentGateway.Provider(), v.GetString("gateway.sandbox.callback_url"))
 	grpcServer.Serve(lis)
 }
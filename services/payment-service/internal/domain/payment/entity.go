@@ -11,31 +11,38 @@ import "time"
 //   - OrderID：关联的订单ID（外键）
 //
 // 3. 第三方支付流水号（ThirdPartyNo）：
-//   - Mock模式：为空
+//   - 沙箱：SBX开头的模拟交易号
 //   - 真实支付：支付宝/微信返回的交易号
+//
+// 4. 已退金额（RefundedAmount）：支持多次部分退款，累计不超过Amount（见refund.go）
 type Payment struct {
-	ID            uint          `gorm:"primaryKey;comment:支付ID"`
-	PaymentNo     string        `gorm:"uniqueIndex;size:32;not null;comment:支付流水号"`
-	OrderID       uint          `gorm:"uniqueIndex;not null;comment:订单ID"`
-	Amount        int64         `gorm:"not null;comment:支付金额（分）"`
-	Status        PaymentStatus `gorm:"type:tinyint;not null;default:1;index;comment:支付状态"`
-	PaymentMethod string        `gorm:"size:20;not null;comment:支付方式"`
-	ThirdPartyNo  string        `gorm:"size:64;comment:第三方支付流水号"`
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	ID             uint          `gorm:"primaryKey;comment:支付ID"`
+	PaymentNo      string        `gorm:"uniqueIndex;size:32;not null;comment:支付流水号"`
+	OrderID        uint          `gorm:"uniqueIndex;not null;comment:订单ID"`
+	Amount         int64         `gorm:"not null;comment:支付金额（分）"`
+	Status         PaymentStatus `gorm:"type:tinyint;not null;default:1;index;comment:支付状态"`
+	PaymentMethod  string        `gorm:"size:20;not null;comment:支付方式"`
+	ThirdPartyNo   string        `gorm:"size:64;comment:第三方支付流水号"`
+	Gateway        string        `gorm:"size:20;not null;default:'';comment:支付网关"`
+	FailReason     string        `gorm:"size:255;comment:失败原因"`
+	RefundedAmount int64         `gorm:"not null;default:0;comment:已退金额（分）"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
 
 // PaymentStatus 支付状态枚举
 //
 // 教学要点：
-// 支付状态机比订单简单（只有4种状态）
+// 支付状态机比订单简单：
+// 待支付 → 已支付/失败；已支付 → 部分退款 → 已退款（累计退款达到支付金额）
 type PaymentStatus int
 
 const (
-	PaymentStatusPending  PaymentStatus = 1 // 待支付
-	PaymentStatusPaid     PaymentStatus = 2 // 已支付
-	PaymentStatusRefunded PaymentStatus = 3 // 已退款
-	PaymentStatusFailed   PaymentStatus = 4 // 失败
+	PaymentStatusPending           PaymentStatus = 1 // 待支付
+	PaymentStatusPaid              PaymentStatus = 2 // 已支付
+	PaymentStatusRefunded          PaymentStatus = 3 // 已退款（全额）
+	PaymentStatusFailed            PaymentStatus = 4 // 失败
+	PaymentStatusPartiallyRefunded PaymentStatus = 5 // 部分退款
 )
 
 func (s PaymentStatus) String() string {
@@ -48,6 +55,8 @@ func (s PaymentStatus) String() string {
 		return "已退款"
 	case PaymentStatusFailed:
 		return "失败"
+	case PaymentStatusPartiallyRefunded:
+		return "部分退款"
 	default:
 		return "未知状态"
 	}
@@ -61,9 +70,66 @@ func (Payment) TableName() string {
 // CanRefund 判断是否可以退款
 //
 // 教学要点：
-// 业务规则：只有已支付的才能退款
+// 业务规则：已支付（含部分退款）且还有可退金额
 func (p *Payment) CanRefund() bool {
-	return p.Status == PaymentStatusPaid
+	return (p.Status == PaymentStatusPaid || p.Status == PaymentStatusPartiallyRefunded) &&
+		p.RefundableAmount() > 0
+}
+
+// RefundableAmount 剩余可退金额（不含处理中的退款）
+func (p *Payment) RefundableAmount() int64 {
+	return p.Amount - p.RefundedAmount
+}
+
+// ApplyRefund 退款成功后累加已退金额，并推进支付状态
+func (p *Payment) ApplyRefund(amount int64) error {
+	if amount <= 0 || amount > p.RefundableAmount() {
+		return ErrRefundExceeded
+	}
+	p.RefundedAmount += amount
+	if p.RefundedAmount == p.Amount {
+		p.Status = PaymentStatusRefunded
+	} else {
+		p.Status = PaymentStatusPartiallyRefunded
+	}
+	return nil
+}
+
+// MarkPaid 网关确认支付成功（待支付 → 已支付）
+func (p *Payment) MarkPaid(thirdPartyNo string) error {
+	if p.Status != PaymentStatusPending {
+		return ErrInvalidTransition
+	}
+	p.Status = PaymentStatusPaid
+	if thirdPartyNo != "" {
+		p.ThirdPartyNo = thirdPartyNo
+	}
+	p.FailReason = ""
+	return nil
+}
+
+// MarkFailed 网关确认支付失败（待支付 → 失败）
+func (p *Payment) MarkFailed(reason string) error {
+	if p.Status != PaymentStatusPending {
+		return ErrInvalidTransition
+	}
+	p.Status = PaymentStatusFailed
+	p.FailReason = reason
+	return nil
+}
+
+// ApplyGatewayResult 按网关返回的状态推进支付
+//
+// 网关仍是待支付时不做任何变更，返回false
+func (p *Payment) ApplyGatewayResult(status PaymentStatus, thirdPartyNo, failReason string) (bool, error) {
+	switch status {
+	case PaymentStatusPaid:
+		return true, p.MarkPaid(thirdPartyNo)
+	case PaymentStatusFailed:
+		return true, p.MarkFailed(failReason)
+	default:
+		return false, nil
+	}
 }
 
 // UpdateStatus 更新支付状态
@@ -7,4 +7,12 @@ var (
 	ErrDuplicatePayment     = errors.New("订单已支付")
 	ErrPaymentNotRefundable = errors.New("支付不可退款")
 	ErrInvalidAmount        = errors.New("支付金额异常")
+	ErrInvalidSignature     = errors.New("回调签名无效")
+	ErrChargeNotFound       = errors.New("网关交易不存在")
+	ErrStatusConflict       = errors.New("支付状态已变更")
+	ErrInvalidTransition    = errors.New("支付状态不允许此变更")
+	ErrRefundNotFound       = errors.New("退款记录不存在")
+	ErrRefundExceeded       = errors.New("退款金额超过可退金额")
+	ErrRefundItemExceeded   = errors.New("退款数量超过购买数量")
+	ErrInvalidRefundItem    = errors.New("退款明细无效")
 )
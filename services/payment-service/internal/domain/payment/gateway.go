@@ -0,0 +1,67 @@
+package payment
+
+import "context"
+
+// PaymentGateway 第三方支付网关接口
+//
+// 教学要点：
+// 1. 领域层只定义接口（依赖倒置），支付宝/微信/沙箱等实现放在infrastructure/gateway
+// 2. 真实支付都是异步的：CreateCharge只表示"网关已受理"，结果通过回调通知
+// 3. 回调可能丢失或延迟，QueryCharge用于主动查询兜底
+// 4. 回调可能被伪造，VerifyCallback必须验签后才能信任报文内容
+type PaymentGateway interface {
+	// Provider 网关标识（如sandbox、alipay），回调路由据此选择网关
+	Provider() string
+
+	// CreateCharge 向网关下单，返回网关交易号和受理状态（通常为待支付）
+	CreateCharge(ctx context.Context, req *ChargeRequest) (*ChargeResult, error)
+
+	// QueryCharge 按支付流水号查询网关侧的交易状态
+	QueryCharge(ctx context.Context, paymentNo string) (*ChargeResult, error)
+
+	// Refund 向网关发起退款
+	Refund(ctx context.Context, req *GatewayRefundRequest) (*GatewayRefundResult, error)
+
+	// VerifyCallback 验证回调签名并解析报文，签名不匹配返回ErrInvalidSignature
+	VerifyCallback(ctx context.Context, payload []byte, signature string) (*CallbackEvent, error)
+}
+
+// ChargeRequest 网关下单请求
+type ChargeRequest struct {
+	PaymentNo     string // 支付流水号（网关侧的商户订单号，用于幂等）
+	OrderID       uint
+	Amount        int64 // 金额（分）
+	PaymentMethod string
+}
+
+// ChargeResult 网关交易状态
+//
+// Status只会是PaymentStatusPending/PaymentStatusPaid/PaymentStatusFailed
+type ChargeResult struct {
+	ThirdPartyNo string        // 网关交易号
+	Status       PaymentStatus // 网关侧状态
+	FailReason   string        // 失败原因（Status为失败时）
+}
+
+// GatewayRefundRequest 网关退款请求
+type GatewayRefundRequest struct {
+	PaymentNo    string
+	ThirdPartyNo string
+	RefundNo     string // 退款流水号（网关侧用于退款幂等）
+	Amount       int64  // 退款金额（分）
+	Reason       string
+}
+
+// GatewayRefundResult 网关退款结果
+type GatewayRefundResult struct {
+	ThirdPartyRefundNo string // 网关退款交易号
+}
+
+// CallbackEvent 验签通过的回调内容
+type CallbackEvent struct {
+	PaymentNo    string
+	ThirdPartyNo string
+	Amount       int64         // 网关实收金额（分），需与本地记录核对
+	Status       PaymentStatus // PaymentStatusPaid或PaymentStatusFailed
+	FailReason   string
+}
@@ -17,3 +17,10 @@ func GeneratePaymentNo() string {
 	randomPart := rand.Intn(900000) + 100000
 	return fmt.Sprintf("PAY%s%d", timePart, randomPart)
 }
+
+// GenerateRefundNo 生成退款流水号
+//
+// 格式：REF + YYYYMMDDHHMMSS + 6位随机数（与支付流水号风格一致）
+func GenerateRefundNo() string {
+	return fmt.Sprintf("REF%s%d", time.Now().Format("20060102150405"), rand.Intn(900000)+100000)
+}
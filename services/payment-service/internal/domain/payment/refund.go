@@ -0,0 +1,144 @@
+package payment
+
+import "time"
+
+// Refund 退款聚合根
+//
+// 教学要点：
+// 1. 一笔支付可以有多笔退款（客服常见场景：多本书的订单只退其中一本）
+// 2. 退款有自己的流水号（RefundNo）和状态，不再直接把Payment改成"已退款"
+// 3. Items关联到订单明细（OrderItemID），用于按图书统计已退数量；不带明细表示整单金额退款（如运费补偿）
+// 4. RequestID是调用方幂等键：同一订单同一RequestID重复提交返回同一笔退款，防止重复退钱
+//   - 唯一索引为(order_id, request_id)：幂等键只在订单内唯一，不同订单的调用方各自生成，互不冲突
+type Refund struct {
+	ID                 uint         `gorm:"primaryKey;comment:退款ID"`
+	RefundNo           string       `gorm:"uniqueIndex;size:32;not null;comment:退款流水号"`
+	RequestID          string       `gorm:"uniqueIndex:uk_order_request,priority:2;size:64;not null;comment:调用方幂等键"`
+	PaymentID          uint         `gorm:"index;not null;comment:支付ID"`
+	PaymentNo          string       `gorm:"size:32;not null;comment:支付流水号"`
+	OrderID            uint         `gorm:"uniqueIndex:uk_order_request,priority:1;not null;comment:订单ID"`
+	Amount             int64        `gorm:"not null;comment:退款金额（分）"`
+	Reason             string       `gorm:"size:200;not null;comment:退款原因"`
+	Status             RefundStatus `gorm:"type:tinyint;not null;default:1;comment:退款状态"`
+	ThirdPartyRefundNo string       `gorm:"size:64;comment:网关退款交易号"`
+	FailReason         string       `gorm:"size:255;comment:失败原因"`
+	Items              []RefundItem `gorm:"foreignKey:RefundID"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// RefundItem 退款明细（关联订单明细）
+type RefundItem struct {
+	ID          uint  `gorm:"primaryKey"`
+	RefundID    uint  `gorm:"index;not null;comment:退款ID"`
+	OrderItemID uint  `gorm:"index;not null;comment:订单明细ID"`
+	BookID      uint  `gorm:"not null;comment:图书ID"`
+	Quantity    int   `gorm:"not null;comment:退款数量"`
+	Amount      int64 `gorm:"not null;comment:退款金额（分）"`
+
+	// OrderedQuantity 下单数量（调用方从订单读取，仅用于校验累计退款数量，不落库）
+	OrderedQuantity int `gorm:"-"`
+}
+
+// RefundStatus 退款状态
+//
+// 状态流转：处理中 → 成功/失败
+// 处理中的退款占用可退额度，失败后释放
+type RefundStatus int
+
+const (
+	RefundStatusPending   RefundStatus = 1 // 处理中
+	RefundStatusSucceeded RefundStatus = 2 // 成功
+	RefundStatusFailed    RefundStatus = 3 // 失败
+)
+
+func (s RefundStatus) String() string {
+	switch s {
+	case RefundStatusPending:
+		return "处理中"
+	case RefundStatusSucceeded:
+		return "成功"
+	case RefundStatusFailed:
+		return "失败"
+	default:
+		return "未知状态"
+	}
+}
+
+// TableName 指定表名
+func (Refund) TableName() string {
+	return "refunds"
+}
+
+// TableName 指定表名
+func (RefundItem) TableName() string {
+	return "refund_items"
+}
+
+// NewRefund 创建退款（处理中）
+//
+// 金额规则：
+// - 带明细：退款金额 = 明细金额之和（amount为0或与之相等）
+// - 不带明细：必须指定amount
+func NewRefund(p *Payment, amount int64, reason, requestID string, items []RefundItem) (*Refund, error) {
+	var itemsTotal int64
+	for _, item := range items {
+		if item.OrderItemID == 0 || item.Quantity <= 0 || item.Amount <= 0 {
+			return nil, ErrInvalidRefundItem
+		}
+		if item.OrderedQuantity > 0 && item.Quantity > item.OrderedQuantity {
+			return nil, ErrRefundItemExceeded
+		}
+		itemsTotal += item.Amount
+	}
+
+	if len(items) > 0 {
+		if amount != 0 && amount != itemsTotal {
+			return nil, ErrInvalidAmount
+		}
+		amount = itemsTotal
+	}
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if amount > p.RefundableAmount() {
+		return nil, ErrRefundExceeded
+	}
+
+	refundNo := GenerateRefundNo()
+	if requestID == "" {
+		requestID = refundNo
+	}
+
+	return &Refund{
+		RefundNo:  refundNo,
+		RequestID: requestID,
+		PaymentID: p.ID,
+		PaymentNo: p.PaymentNo,
+		OrderID:   p.OrderID,
+		Amount:    amount,
+		Reason:    reason,
+		Status:    RefundStatusPending,
+		Items:     items,
+	}, nil
+}
+
+// MarkSucceeded 网关退款成功
+func (r *Refund) MarkSucceeded(thirdPartyRefundNo string) error {
+	if r.Status != RefundStatusPending {
+		return ErrInvalidTransition
+	}
+	r.Status = RefundStatusSucceeded
+	r.ThirdPartyRefundNo = thirdPartyRefundNo
+	return nil
+}
+
+// MarkFailed 网关退款失败
+func (r *Refund) MarkFailed(reason string) error {
+	if r.Status != RefundStatusPending {
+		return ErrInvalidTransition
+	}
+	r.Status = RefundStatusFailed
+	r.FailReason = reason
+	return nil
+}
@@ -9,5 +9,31 @@ type Repository interface {
 	Create(ctx context.Context, payment *Payment) error
 	FindByID(ctx context.Context, id uint) (*Payment, error)
 	FindByOrderID(ctx context.Context, orderID uint) (*Payment, error)
+	FindByPaymentNo(ctx context.Context, paymentNo string) (*Payment, error)
 	Update(ctx context.Context, payment *Payment) error
+
+	// UpdateStatusFrom 条件更新：仅当数据库中状态仍为from时写入，否则返回ErrStatusConflict
+	// 用于回调与主动查询并发推进同一笔支付的场景；进入终态时同一事务写入支付结果事件（发件箱）
+	UpdateStatusFrom(ctx context.Context, payment *Payment, from PaymentStatus) error
+}
+
+// RefundRepository 退款仓储接口
+//
+// 教学要点：
+// 可退额度校验必须和创建退款在同一事务中完成（锁定支付记录），
+// 否则两个并发的部分退款可能各自通过校验，累计超过支付金额
+type RefundRepository interface {
+	// Reserve 锁定支付记录，校验可退额度（扣除处理中的退款）和明细累计数量，创建处理中的退款
+	Reserve(ctx context.Context, refund *Refund) error
+
+	// Complete 退款成功：更新退款状态，累加支付记录的已退金额并推进支付状态，写入退款成功事件（同一事务）
+	Complete(ctx context.Context, refund *Refund) (*Payment, error)
+
+	// Fail 退款失败：更新退款状态，释放占用的可退额度
+	Fail(ctx context.Context, refund *Refund) error
+
+	// FindByRequestID 按订单和调用方幂等键查询退款
+	FindByRequestID(ctx context.Context, orderID uint, requestID string) (*Refund, error)
+
+	ListByOrderID(ctx context.Context, orderID uint) ([]*Refund, error)
 }
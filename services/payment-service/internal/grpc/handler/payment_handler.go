@@ -2,47 +2,112 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"log"
-	"math/rand"
 
 	paymentv1 "github.com/xiebiao/bookstore/proto/paymentv1"
 	"github.com/xiebiao/bookstore/services/payment-service/internal/domain/payment"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type PaymentServiceServer struct {
 	paymentv1.UnimplementedPaymentServiceServer
-	repo payment.Repository
+	repo       payment.Repository
+	refundRepo payment.RefundRepository
+	gateway    payment.PaymentGateway
 }
 
-func NewPaymentServiceServer(repo payment.Repository) *PaymentServiceServer {
-	return &PaymentServiceServer{repo: repo}
+func NewPaymentServiceServer(repo payment.Repository, refundRepo payment.RefundRepository, gateway payment.PaymentGateway) *PaymentServiceServer {
+	return &PaymentServiceServer{repo: repo, refundRepo: refundRepo, gateway: gateway}
 }
 
+// Pay 发起支付
+//
+// 流程：
+// 1. 幂等检查：已支付/待支付直接返回原记录，失败的允许换新流水号重新支付
+// 2. 本地先落"待支付"记录，再向网关下单（网关回调时一定能查到记录）
+// 3. 保存网关交易号，返回"待支付"，最终结果由回调推进
 func (s *PaymentServiceServer) Pay(ctx context.Context, req *paymentv1.PayRequest) (*paymentv1.PayResponse, error) {
-	existing, _ := s.repo.FindByOrderID(ctx, uint(req.OrderId))
-	if existing != nil && existing.Status == payment.PaymentStatusPaid {
-		return &paymentv1.PayResponse{Code: 0, Message: "订单已支付", PaymentNo: existing.PaymentNo}, nil
-	}
-
-	isSuccess := rand.Intn(100) < 70
-	p := &payment.Payment{
-		PaymentNo:     payment.GeneratePaymentNo(),
-		OrderID:       uint(req.OrderId),
-		Amount:        req.Amount,
-		PaymentMethod: req.PaymentMethod,
-	}
-
-	if isSuccess {
-		p.Status = payment.PaymentStatusPaid
-		p.ThirdPartyNo = "MOCK" + p.PaymentNo
-		s.repo.Create(ctx, p)
-		log.Printf("✅ 支付成功: %s", p.PaymentNo)
-		return &paymentv1.PayResponse{Code: 0, Message: "支付成功", PaymentNo: p.PaymentNo, ThirdPartyNo: p.ThirdPartyNo}, nil
-	} else {
-		p.Status = payment.PaymentStatusFailed
-		s.repo.Create(ctx, p)
-		return &paymentv1.PayResponse{Code: 1, Message: "支付失败（Mock）"}, nil
+	if req.OrderId == 0 || req.Amount <= 0 {
+		return &paymentv1.PayResponse{Code: 40000, Message: "订单ID和支付金额不能为空"}, nil
 	}
+
+	existing, err := s.repo.FindByOrderID(ctx, uint(req.OrderId))
+	if err != nil && !errors.Is(err, payment.ErrPaymentNotFound) {
+		return nil, status.Errorf(codes.Internal, "查询支付记录失败: %v", err)
+	}
+
+	// 待支付：先向网关确认一次（回调可能丢失；网关交易不存在则重新下单）
+	if existing != nil && existing.Status == payment.PaymentStatusPending && existing.ThirdPartyNo != "" {
+		if errors.Is(s.syncFromGateway(ctx, existing), payment.ErrChargeNotFound) {
+			existing.ThirdPartyNo = ""
+		}
+	}
+
+	var p *payment.Payment
+	switch {
+	case existing == nil:
+		p = &payment.Payment{
+			PaymentNo:     payment.GeneratePaymentNo(),
+			OrderID:       uint(req.OrderId),
+			Amount:        req.Amount,
+			Status:        payment.PaymentStatusPending,
+			PaymentMethod: req.PaymentMethod,
+			Gateway:       s.gateway.Provider(),
+		}
+		if err := s.repo.Create(ctx, p); err != nil {
+			return nil, status.Errorf(codes.Internal, "创建支付记录失败: %v", err)
+		}
+
+	case existing.Status == payment.PaymentStatusPaid:
+		return toPayResponse(existing, "订单已支付"), nil
+
+	case existing.Status == payment.PaymentStatusPending && existing.ThirdPartyNo != "":
+		return toPayResponse(existing, "支付处理中"), nil
+
+	case existing.Status == payment.PaymentStatusPending || existing.Status == payment.PaymentStatusFailed:
+		// 上次失败（或网关交易不存在）：换新流水号重新下单，避免与网关旧交易冲突
+		p = existing
+		p.PaymentNo = payment.GeneratePaymentNo()
+		p.Amount = req.Amount
+		p.Status = payment.PaymentStatusPending
+		p.PaymentMethod = req.PaymentMethod
+		p.Gateway = s.gateway.Provider()
+		p.ThirdPartyNo = ""
+		p.FailReason = ""
+		if err := s.repo.Update(ctx, p); err != nil {
+			return nil, status.Errorf(codes.Internal, "更新支付记录失败: %v", err)
+		}
+
+	default:
+		return &paymentv1.PayResponse{Code: 1, Message: "订单当前状态不可支付: " + existing.Status.String()}, nil
+	}
+
+	charge, err := s.gateway.CreateCharge(ctx, &payment.ChargeRequest{
+		PaymentNo:     p.PaymentNo,
+		OrderID:       p.OrderID,
+		Amount:        p.Amount,
+		PaymentMethod: p.PaymentMethod,
+	})
+	if err != nil {
+		log.Printf("⚠️ 网关下单失败[%s]: %v", p.PaymentNo, err)
+		if err := p.MarkFailed(err.Error()); err == nil {
+			_ = s.repo.UpdateStatusFrom(ctx, p, payment.PaymentStatusPending)
+		}
+		return &paymentv1.PayResponse{Code: 1, Message: "支付下单失败: " + err.Error(), PaymentNo: p.PaymentNo}, nil
+	}
+
+	p.ThirdPartyNo = charge.ThirdPartyNo
+	if _, err := p.ApplyGatewayResult(charge.Status, charge.ThirdPartyNo, charge.FailReason); err != nil {
+		return nil, status.Errorf(codes.Internal, "更新支付状态失败: %v", err)
+	}
+	if err := s.repo.UpdateStatusFrom(ctx, p, payment.PaymentStatusPending); err != nil && !errors.Is(err, payment.ErrStatusConflict) {
+		return nil, status.Errorf(codes.Internal, "保存网关交易号失败: %v", err)
+	}
+
+	log.Printf("💳 支付已受理: %s（网关:%s 交易号:%s）", p.PaymentNo, p.Gateway, p.ThirdPartyNo)
+	return toPayResponse(p, "支付处理中"), nil
 }
 
 func (s *PaymentServiceServer) GetPaymentStatus(ctx context.Context, req *paymentv1.GetPaymentStatusRequest) (*paymentv1.GetPaymentStatusResponse, error) {
@@ -50,21 +115,38 @@ func (s *PaymentServiceServer) GetPaymentStatus(ctx context.Context, req *paymen
 	if err != nil {
 		return &paymentv1.GetPaymentStatusResponse{Code: 40400, Message: "支付记录不存在"}, nil
 	}
+
+	// 待支付时主动查询网关（回调丢失兜底）
+	if p.Status == payment.PaymentStatusPending && p.ThirdPartyNo != "" {
+		_ = s.syncFromGateway(ctx, p)
+	}
+
 	return &paymentv1.GetPaymentStatusResponse{
-		Code: 0,
-		Payment: &paymentv1.Payment{
-			Id:            uint64(p.ID),
-			PaymentNo:     p.PaymentNo,
-			OrderId:       uint64(p.OrderID),
-			Amount:        p.Amount,
-			Status:        int32(p.Status),
-			PaymentMethod: p.PaymentMethod,
-			CreatedAt:     p.CreatedAt.Unix(),
-		},
+		Code:    0,
+		Payment: toPaymentProto(p),
 	}, nil
 }
 
+// Refund 退款
+//
+// 流程：
+// 1. 幂等：同一订单的request_id已存在直接返回原退款（幂等键按订单隔离，不会返回其他订单的退款）
+// 2. 领域校验（金额/明细）→ 事务内锁定支付记录、校验可退额度、创建处理中的退款
+// 3. 调用网关退款：成功则累加已退金额，失败则释放额度
 func (s *PaymentServiceServer) Refund(ctx context.Context, req *paymentv1.RefundRequest) (*paymentv1.RefundResponse, error) {
+	if req.RequestId != "" {
+		existing, err := s.refundRepo.FindByRequestID(ctx, uint(req.OrderId), req.RequestId)
+		if err == nil {
+			if existing.OrderID != uint(req.OrderId) {
+				return &paymentv1.RefundResponse{Code: 40900, Message: "退款请求ID已用于其他订单"}, nil
+			}
+			return toRefundResponse(existing), nil
+		}
+		if !errors.Is(err, payment.ErrRefundNotFound) {
+			return nil, status.Errorf(codes.Internal, "查询退款记录失败: %v", err)
+		}
+	}
+
 	p, err := s.repo.FindByOrderID(ctx, uint(req.OrderId))
 	if err != nil {
 		return &paymentv1.RefundResponse{Code: 40400, Message: "支付记录不存在"}, nil
@@ -72,7 +154,247 @@ func (s *PaymentServiceServer) Refund(ctx context.Context, req *paymentv1.Refund
 	if !p.CanRefund() {
 		return &paymentv1.RefundResponse{Code: 40000, Message: "支付不可退款"}, nil
 	}
-	p.UpdateStatus(payment.PaymentStatusRefunded)
-	s.repo.Update(ctx, p)
-	return &paymentv1.RefundResponse{Code: 0, Message: "退款成功", RefundNo: "REF" + p.PaymentNo}, nil
+
+	items := make([]payment.RefundItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, payment.RefundItem{
+			OrderItemID:     uint(item.OrderItemId),
+			BookID:          uint(item.BookId),
+			Quantity:        int(item.Quantity),
+			Amount:          item.Amount,
+			OrderedQuantity: int(item.OrderedQuantity),
+		})
+	}
+
+	amount := req.Amount
+	if amount == 0 && len(items) == 0 {
+		amount = p.RefundableAmount() // 不指定金额和明细：退还全部剩余金额
+	}
+
+	refund, err := payment.NewRefund(p, amount, req.Reason, req.RequestId, items)
+	if err == nil {
+		err = s.refundRepo.Reserve(ctx, refund)
+	}
+	if err != nil {
+		return refundErrorResponse(err)
+	}
+
+	result, err := s.gateway.Refund(ctx, &payment.GatewayRefundRequest{
+		PaymentNo:    p.PaymentNo,
+		ThirdPartyNo: p.ThirdPartyNo,
+		RefundNo:     refund.RefundNo,
+		Amount:       refund.Amount,
+		Reason:       refund.Reason,
+	})
+	if err != nil {
+		log.Printf("⚠️ 网关退款失败[%s]: %v", refund.RefundNo, err)
+		_ = refund.MarkFailed(err.Error())
+		if err := s.refundRepo.Fail(ctx, refund); err != nil {
+			log.Printf("⚠️ 更新退款失败状态失败[%s]: %v", refund.RefundNo, err)
+		}
+		resp := toRefundResponse(refund)
+		resp.Code = 1
+		resp.Message = "网关退款失败: " + err.Error()
+		return resp, nil
+	}
+
+	_ = refund.MarkSucceeded(result.ThirdPartyRefundNo)
+	if _, err := s.refundRepo.Complete(ctx, refund); err != nil {
+		// 网关已退款但本地未落库：退款保持处理中（继续占用额度），需人工核对
+		log.Printf("❌ 网关退款成功但本地更新失败[%s]: %v", refund.RefundNo, err)
+		return nil, status.Errorf(codes.Internal, "更新退款状态失败: %v", err)
+	}
+
+	log.Printf("✅ 退款成功: %s（订单:%d 金额:%d）", refund.RefundNo, refund.OrderID, refund.Amount)
+	return toRefundResponse(refund), nil
+}
+
+// ListRefunds 查询订单的退款记录
+func (s *PaymentServiceServer) ListRefunds(ctx context.Context, req *paymentv1.ListRefundsRequest) (*paymentv1.ListRefundsResponse, error) {
+	p, err := s.repo.FindByOrderID(ctx, uint(req.OrderId))
+	if err != nil {
+		return &paymentv1.ListRefundsResponse{Code: 40400, Message: "支付记录不存在"}, nil
+	}
+
+	refunds, err := s.refundRepo.ListByOrderID(ctx, p.OrderID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "查询退款记录失败: %v", err)
+	}
+
+	resp := &paymentv1.ListRefundsResponse{
+		Code:           0,
+		Refunds:        make([]*paymentv1.Refund, 0, len(refunds)),
+		PaidAmount:     p.Amount,
+		RefundedAmount: p.RefundedAmount,
+	}
+	for _, refund := range refunds {
+		resp.Refunds = append(resp.Refunds, toRefundProto(refund))
+	}
+	return resp, nil
+}
+
+// PaymentCallback 处理支付网关回调
+//
+// 教学要点：
+// 1. 先验签，再信任报文（签名错误返回40100，网关会重试，攻击者无法伪造）
+// 2. 核对金额：报文金额与本地记录不一致时拒绝（防止篡改或串单）
+// 3. 幂等：已是终态的支付直接返回成功，网关重复通知不会重复推进
+// 4. 条件更新：与GetPaymentStatus的主动查询并发时，只有一方生效
+func (s *PaymentServiceServer) PaymentCallback(ctx context.Context, req *paymentv1.PaymentCallbackRequest) (*paymentv1.PaymentCallbackResponse, error) {
+	if req.Provider != s.gateway.Provider() {
+		return &paymentv1.PaymentCallbackResponse{Code: 40000, Message: "未知支付网关: " + req.Provider}, nil
+	}
+
+	event, err := s.gateway.VerifyCallback(ctx, req.Payload, req.Signature)
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			log.Printf("⚠️ 支付回调验签失败[网关:%s]", req.Provider)
+			return &paymentv1.PaymentCallbackResponse{Code: 40100, Message: "签名无效"}, nil
+		}
+		return &paymentv1.PaymentCallbackResponse{Code: 40000, Message: err.Error()}, nil
+	}
+
+	p, err := s.repo.FindByPaymentNo(ctx, event.PaymentNo)
+	if err != nil {
+		if errors.Is(err, payment.ErrPaymentNotFound) {
+			// 重新支付后旧流水号的回调：确认收到即可，避免网关无限重试
+			log.Printf("⚠️ 支付回调对应的流水号不存在，忽略: %s", event.PaymentNo)
+			return &paymentv1.PaymentCallbackResponse{Code: 0, Message: "忽略"}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "查询支付记录失败: %v", err)
+	}
+
+	if event.Amount != p.Amount {
+		log.Printf("⚠️ 支付回调金额不一致[%s]: 回调%d 本地%d", p.PaymentNo, event.Amount, p.Amount)
+		return &paymentv1.PaymentCallbackResponse{Code: 40000, Message: "金额不一致"}, nil
+	}
+
+	if p.Status != payment.PaymentStatusPending {
+		return &paymentv1.PaymentCallbackResponse{Code: 0, Message: "已处理"}, nil
+	}
+
+	if _, err := p.ApplyGatewayResult(event.Status, event.ThirdPartyNo, event.FailReason); err != nil {
+		return &paymentv1.PaymentCallbackResponse{Code: 40000, Message: err.Error()}, nil
+	}
+	if err := s.repo.UpdateStatusFrom(ctx, p, payment.PaymentStatusPending); err != nil {
+		if errors.Is(err, payment.ErrStatusConflict) {
+			return &paymentv1.PaymentCallbackResponse{Code: 0, Message: "已处理"}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "更新支付状态失败: %v", err)
+	}
+
+	log.Printf("✅ 支付回调处理完成: %s → %s", p.PaymentNo, p.Status)
+	return &paymentv1.PaymentCallbackResponse{Code: 0, Message: "成功"}, nil
+}
+
+// syncFromGateway 主动查询网关并推进支付状态
+//
+// 只返回ErrChargeNotFound（网关交易不存在），其他失败只记录日志，p保持本地状态
+func (s *PaymentServiceServer) syncFromGateway(ctx context.Context, p *payment.Payment) error {
+	charge, err := s.gateway.QueryCharge(ctx, p.PaymentNo)
+	if err != nil {
+		if errors.Is(err, payment.ErrChargeNotFound) {
+			return err
+		}
+		log.Printf("⚠️ 查询网关交易失败[%s]: %v", p.PaymentNo, err)
+		return nil
+	}
+
+	changed, err := p.ApplyGatewayResult(charge.Status, charge.ThirdPartyNo, charge.FailReason)
+	if err != nil || !changed {
+		return nil
+	}
+	if err := s.repo.UpdateStatusFrom(ctx, p, payment.PaymentStatusPending); err != nil {
+		if !errors.Is(err, payment.ErrStatusConflict) {
+			log.Printf("⚠️ 同步网关状态失败[%s]: %v", p.PaymentNo, err)
+		}
+		// 回调已先一步推进（或写入失败），以数据库中的状态为准
+		if latest, err := s.repo.FindByID(ctx, p.ID); err == nil {
+			*p = *latest
+		}
+	}
+	return nil
+}
+
+func toPayResponse(p *payment.Payment, message string) *paymentv1.PayResponse {
+	return &paymentv1.PayResponse{
+		Code:         0,
+		Message:      message,
+		PaymentNo:    p.PaymentNo,
+		ThirdPartyNo: p.ThirdPartyNo,
+		Status:       int32(p.Status),
+	}
+}
+
+func toPaymentProto(p *payment.Payment) *paymentv1.Payment {
+	return &paymentv1.Payment{
+		Id:             uint64(p.ID),
+		PaymentNo:      p.PaymentNo,
+		OrderId:        uint64(p.OrderID),
+		Amount:         p.Amount,
+		Status:         int32(p.Status),
+		PaymentMethod:  p.PaymentMethod,
+		ThirdPartyNo:   p.ThirdPartyNo,
+		CreatedAt:      p.CreatedAt.Unix(),
+		UpdatedAt:      p.UpdatedAt.Unix(),
+		RefundedAmount: p.RefundedAmount,
+	}
+}
+
+// refundErrorResponse 退款领域错误 → 业务错误码
+func refundErrorResponse(err error) (*paymentv1.RefundResponse, error) {
+	switch {
+	case errors.Is(err, payment.ErrRefundExceeded), errors.Is(err, payment.ErrRefundItemExceeded):
+		return &paymentv1.RefundResponse{Code: 40001, Message: err.Error()}, nil
+	case errors.Is(err, payment.ErrInvalidAmount), errors.Is(err, payment.ErrInvalidRefundItem),
+		errors.Is(err, payment.ErrPaymentNotRefundable):
+		return &paymentv1.RefundResponse{Code: 40000, Message: err.Error()}, nil
+	case errors.Is(err, payment.ErrPaymentNotFound):
+		return &paymentv1.RefundResponse{Code: 40400, Message: err.Error()}, nil
+	default:
+		return nil, status.Errorf(codes.Internal, "创建退款失败: %v", err)
+	}
+}
+
+func toRefundResponse(refund *payment.Refund) *paymentv1.RefundResponse {
+	resp := &paymentv1.RefundResponse{
+		Code:     0,
+		Message:  "退款成功",
+		RefundNo: refund.RefundNo,
+		Refund:   toRefundProto(refund),
+	}
+	switch refund.Status {
+	case payment.RefundStatusPending:
+		resp.Message = "退款处理中"
+	case payment.RefundStatusFailed:
+		resp.Code = 1
+		resp.Message = "退款失败: " + refund.FailReason
+	}
+	return resp
+}
+
+func toRefundProto(refund *payment.Refund) *paymentv1.Refund {
+	items := make([]*paymentv1.RefundItem, 0, len(refund.Items))
+	for _, item := range refund.Items {
+		items = append(items, &paymentv1.RefundItem{
+			OrderItemId: uint64(item.OrderItemID),
+			BookId:      uint64(item.BookID),
+			Quantity:    int32(item.Quantity),
+			Amount:      item.Amount,
+		})
+	}
+	return &paymentv1.Refund{
+		Id:                 uint64(refund.ID),
+		RefundNo:           refund.RefundNo,
+		OrderId:            uint64(refund.OrderID),
+		PaymentNo:          refund.PaymentNo,
+		Amount:             refund.Amount,
+		Reason:             refund.Reason,
+		Status:             int32(refund.Status),
+		ThirdPartyRefundNo: refund.ThirdPartyRefundNo,
+		FailReason:         refund.FailReason,
+		Items:              items,
+		CreatedAt:          refund.CreatedAt.Unix(),
+		UpdatedAt:          refund.UpdatedAt.Unix(),
+	}
 }
@@ -0,0 +1,285 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xiebiao/bookstore/services/payment-service/internal/domain/payment"
+)
+
+// SandboxProvider 沙箱网关标识
+const SandboxProvider = "sandbox"
+
+// SignatureHeader 回调签名HTTP头（api-gateway透传给payment-service）
+const SignatureHeader = "X-Payment-Signature"
+
+// 沙箱规则：按金额的"分"位（amount % 100）决定结果，测试可重复
+//
+//	xx.66元 → 支付失败（余额不足），回调通知失败
+//	xx.99元 → 支付成功但不发回调（模拟回调丢失），只能靠QueryCharge兜底
+//	其他    → 支付成功，回调通知成功
+const (
+	sandboxFailCents       = 66
+	sandboxLostNotifyCents = 99
+)
+
+// ErrEmptySecret 回调签名密钥为空
+//
+// 空密钥的HMAC任何人都能算出来，等于不验签，启动时直接拒绝
+var ErrEmptySecret = errors.New("沙箱网关回调签名密钥不能为空")
+
+// SandboxConfig 沙箱网关配置
+type SandboxConfig struct {
+	Secret        string        // 回调签名密钥（HMAC-SHA256）
+	CallbackURL   string        // 回调地址（api-gateway的/api/v1/payments/callback/sandbox）
+	CallbackDelay time.Duration // 下单后多久发回调（模拟用户付款耗时）
+}
+
+// sandboxCallback 沙箱回调报文
+type sandboxCallback struct {
+	PaymentNo    string `json:"payment_no"`
+	ThirdPartyNo string `json:"third_party_no"`
+	Amount       int64  `json:"amount"`
+	TradeStatus  string `json:"trade_status"` // SUCCESS/FAILED
+	FailReason   string `json:"fail_reason,omitempty"`
+	Timestamp    int64  `json:"timestamp"`
+}
+
+// sandboxCharge 沙箱内部交易记录
+type sandboxCharge struct {
+	req      payment.ChargeRequest
+	result   payment.ChargeResult
+	refunded int64
+}
+
+// sandboxGateway 本地沙箱支付网关
+//
+// 教学要点：
+// 1. 行为完全由金额决定（见上方规则），QA可以稳定复现成功/失败/回调丢失
+// 2. 与真实网关一样走"异步回调 + 签名"流程，业务代码不区分沙箱和真实渠道
+// 3. 交易只保存在内存中：重启后QueryCharge返回ErrChargeNotFound，用户再次支付时重新下单
+type sandboxGateway struct {
+	cfg        SandboxConfig
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	charges map[string]*sandboxCharge // paymentNo → 交易
+}
+
+// NewSandboxGateway 创建沙箱支付网关（Secret为空时返回ErrEmptySecret）
+func NewSandboxGateway(cfg SandboxConfig) (payment.PaymentGateway, error) {
+	if cfg.Secret == "" {
+		return nil, ErrEmptySecret
+	}
+	return newSandboxGateway(cfg), nil
+}
+
+func newSandboxGateway(cfg SandboxConfig) *sandboxGateway {
+	return &sandboxGateway{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		charges:    make(map[string]*sandboxCharge),
+	}
+}
+
+// Provider 网关标识
+func (g *sandboxGateway) Provider() string {
+	return SandboxProvider
+}
+
+// CreateCharge 沙箱下单
+//
+// 同一PaymentNo重复下单返回原交易（幂等）
+func (g *sandboxGateway) CreateCharge(ctx context.Context, req *payment.ChargeRequest) (*payment.ChargeResult, error) {
+	if req.Amount <= 0 {
+		return nil, payment.ErrInvalidAmount
+	}
+
+	g.mu.Lock()
+	if c, ok := g.charges[req.PaymentNo]; ok {
+		result := c.result
+		g.mu.Unlock()
+		return &result, nil
+	}
+
+	c := &sandboxCharge{
+		req: *req,
+		result: payment.ChargeResult{
+			ThirdPartyNo: "SBX" + strings.TrimPrefix(req.PaymentNo, "PAY"),
+			Status:       payment.PaymentStatusPending,
+		},
+	}
+	g.charges[req.PaymentNo] = c
+	result := c.result
+	g.mu.Unlock()
+
+	// 模拟用户在收银台付款：延迟后确定结果并回调
+	time.AfterFunc(g.cfg.CallbackDelay, func() { g.settle(req.PaymentNo) })
+
+	return &result, nil
+}
+
+// QueryCharge 查询沙箱交易
+func (g *sandboxGateway) QueryCharge(ctx context.Context, paymentNo string) (*payment.ChargeResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	c, ok := g.charges[paymentNo]
+	if !ok {
+		return nil, payment.ErrChargeNotFound
+	}
+	result := c.result
+	return &result, nil
+}
+
+// Refund 沙箱退款（同步成功，累计退款不能超过实收金额）
+func (g *sandboxGateway) Refund(ctx context.Context, req *payment.GatewayRefundRequest) (*payment.GatewayRefundResult, error) {
+	if req.Amount <= 0 {
+		return nil, payment.ErrInvalidAmount
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// 重启后交易丢失时无法校验，直接受理（沙箱简化）
+	if c, ok := g.charges[req.PaymentNo]; ok {
+		if c.result.Status != payment.PaymentStatusPaid {
+			return nil, payment.ErrPaymentNotRefundable
+		}
+		if c.refunded+req.Amount > c.req.Amount {
+			return nil, fmt.Errorf("退款金额超过实收金额: %w", payment.ErrInvalidAmount)
+		}
+		c.refunded += req.Amount
+	}
+
+	return &payment.GatewayRefundResult{
+		ThirdPartyRefundNo: "SBXR" + strings.TrimPrefix(req.RefundNo, "REF"),
+	}, nil
+}
+
+// VerifyCallback 验签并解析沙箱回调
+func (g *sandboxGateway) VerifyCallback(ctx context.Context, payload []byte, signature string) (*payment.CallbackEvent, error) {
+	if !hmac.Equal([]byte(Sign(g.cfg.Secret, payload)), []byte(signature)) {
+		return nil, payment.ErrInvalidSignature
+	}
+
+	var cb sandboxCallback
+	if err := json.Unmarshal(payload, &cb); err != nil {
+		return nil, fmt.Errorf("解析回调报文失败: %w", err)
+	}
+
+	event := &payment.CallbackEvent{
+		PaymentNo:    cb.PaymentNo,
+		ThirdPartyNo: cb.ThirdPartyNo,
+		Amount:       cb.Amount,
+		FailReason:   cb.FailReason,
+	}
+	switch cb.TradeStatus {
+	case "SUCCESS":
+		event.Status = payment.PaymentStatusPaid
+	case "FAILED":
+		event.Status = payment.PaymentStatusFailed
+	default:
+		return nil, fmt.Errorf("未知交易状态: %s", cb.TradeStatus)
+	}
+	return event, nil
+}
+
+// settle 按沙箱规则确定交易结果并发送回调
+func (g *sandboxGateway) settle(paymentNo string) {
+	g.mu.Lock()
+	c, ok := g.charges[paymentNo]
+	if !ok || c.result.Status != payment.PaymentStatusPending {
+		g.mu.Unlock()
+		return
+	}
+
+	cb := sandboxCallback{
+		PaymentNo:    paymentNo,
+		ThirdPartyNo: c.result.ThirdPartyNo,
+		Amount:       c.req.Amount,
+		Timestamp:    time.Now().Unix(),
+	}
+	cents := c.req.Amount % 100
+	if cents == sandboxFailCents {
+		c.result.Status = payment.PaymentStatusFailed
+		c.result.FailReason = "余额不足（沙箱）"
+		cb.TradeStatus = "FAILED"
+		cb.FailReason = c.result.FailReason
+	} else {
+		c.result.Status = payment.PaymentStatusPaid
+		cb.TradeStatus = "SUCCESS"
+	}
+	g.mu.Unlock()
+
+	if cents == sandboxLostNotifyCents {
+		log.Printf("🧪 沙箱模拟回调丢失: %s", paymentNo)
+		return
+	}
+	g.notify(cb)
+}
+
+// notify 发送签名回调（失败按1s/2s/4s重试，与真实网关的重复通知行为一致）
+func (g *sandboxGateway) notify(cb sandboxCallback) {
+	if g.cfg.CallbackURL == "" {
+		return
+	}
+
+	payload, err := json.Marshal(cb)
+	if err != nil {
+		log.Printf("⚠️ 沙箱回调序列化失败: %v", err)
+		return
+	}
+	signature := Sign(g.cfg.Secret, payload)
+
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		if err = g.post(payload, signature); err == nil {
+			return
+		}
+		log.Printf("⚠️ 沙箱回调失败[%s 第%d次]: %v", cb.PaymentNo, attempt, err)
+		if attempt == 4 {
+			return
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (g *sandboxGateway) post(payload []byte, signature string) error {
+	req, err := http.NewRequest(http.MethodPost, g.cfg.CallbackURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, signature)
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("回调返回HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign 计算回调签名：hex(HMAC-SHA256(secret, payload))
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,166 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xiebiao/bookstore/services/payment-service/internal/domain/payment"
+)
+
+const testSecret = "test-secret"
+
+// callbackRecorder 记录沙箱发出的回调
+type callbackRecorder struct {
+	mu         sync.Mutex
+	payloads   [][]byte
+	signatures []string
+}
+
+func (r *callbackRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	r.payloads = append(r.payloads, body)
+	r.signatures = append(r.signatures, req.Header.Get(SignatureHeader))
+	r.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+// newTestGateway 创建回调发往httptest服务器的沙箱网关
+//
+// CallbackDelay设为1小时，由测试直接调用settle，结果和回调都是同步的
+func newTestGateway(t *testing.T) (*sandboxGateway, *callbackRecorder) {
+	t.Helper()
+	rec := &callbackRecorder{}
+	srv := httptest.NewServer(rec)
+	t.Cleanup(srv.Close)
+
+	g := newSandboxGateway(SandboxConfig{
+		Secret:        testSecret,
+		CallbackURL:   srv.URL,
+		CallbackDelay: time.Hour,
+	})
+	return g, rec
+}
+
+// TestNewSandboxGateway_RequiresSecret 测试密钥为空时拒绝创建
+func TestNewSandboxGateway_RequiresSecret(t *testing.T) {
+	if _, err := NewSandboxGateway(SandboxConfig{}); !errors.Is(err, ErrEmptySecret) {
+		t.Errorf("期望ErrEmptySecret，实际%v", err)
+	}
+	if _, err := NewSandboxGateway(SandboxConfig{Secret: testSecret}); err != nil {
+		t.Errorf("配置密钥后期望创建成功，实际%v", err)
+	}
+}
+
+// TestSandbox_AmountRules 测试按金额分位决定交易结果和是否回调
+func TestSandbox_AmountRules(t *testing.T) {
+	tests := []struct {
+		name         string
+		amount       int64
+		wantStatus   payment.PaymentStatus
+		wantCallback string // 期望回调的trade_status，空表示不回调
+	}{
+		{name: "普通金额成功", amount: 1000, wantStatus: payment.PaymentStatusPaid, wantCallback: "SUCCESS"},
+		{name: "66分失败", amount: 1066, wantStatus: payment.PaymentStatusFailed, wantCallback: "FAILED"},
+		{name: "99分成功但回调丢失", amount: 1099, wantStatus: payment.PaymentStatusPaid},
+		{name: "66元不是66分", amount: 6600, wantStatus: payment.PaymentStatusPaid, wantCallback: "SUCCESS"},
+		{name: "只有66分", amount: 66, wantStatus: payment.PaymentStatusFailed, wantCallback: "FAILED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, rec := newTestGateway(t)
+			ctx := context.Background()
+
+			created, err := g.CreateCharge(ctx, &payment.ChargeRequest{PaymentNo: "PAY001", OrderID: 1, Amount: tt.amount})
+			if err != nil {
+				t.Fatalf("下单失败: %v", err)
+			}
+			if created.Status != payment.PaymentStatusPending {
+				t.Fatalf("下单后期望待支付，实际%v", created.Status)
+			}
+
+			g.settle("PAY001")
+
+			result, err := g.QueryCharge(ctx, "PAY001")
+			if err != nil {
+				t.Fatalf("查询失败: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("期望状态%v，实际%v", tt.wantStatus, result.Status)
+			}
+
+			if tt.wantCallback == "" {
+				if len(rec.payloads) != 0 {
+					t.Errorf("期望不发回调，实际%d次", len(rec.payloads))
+				}
+				return
+			}
+			if len(rec.payloads) != 1 {
+				t.Fatalf("期望回调1次，实际%d次", len(rec.payloads))
+			}
+			var cb sandboxCallback
+			if err := json.Unmarshal(rec.payloads[0], &cb); err != nil {
+				t.Fatalf("回调报文解析失败: %v", err)
+			}
+			if cb.TradeStatus != tt.wantCallback || cb.Amount != tt.amount || cb.PaymentNo != "PAY001" {
+				t.Errorf("回调内容不符: %+v", cb)
+			}
+			if rec.signatures[0] != Sign(testSecret, rec.payloads[0]) {
+				t.Errorf("回调签名不正确: %s", rec.signatures[0])
+			}
+		})
+	}
+}
+
+// TestSandbox_VerifyCallback 测试回调验签
+func TestSandbox_VerifyCallback(t *testing.T) {
+	g, _ := newTestGateway(t)
+	payload := []byte(`{"payment_no":"PAY001","third_party_no":"SBX001","amount":1000,"trade_status":"SUCCESS","timestamp":1}`)
+	unknown := []byte(`{"payment_no":"PAY001","trade_status":"CLOSED"}`)
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		signature string
+		wantErr   error // nil表示验签通过
+		wantParse bool  // 验签通过但解析失败
+	}{
+		{name: "签名正确", payload: payload, signature: Sign(testSecret, payload)},
+		{name: "报文被篡改", payload: []byte(`{"payment_no":"PAY001","amount":1}`), signature: Sign(testSecret, payload), wantErr: payment.ErrInvalidSignature},
+		{name: "密钥不同", payload: payload, signature: Sign("other-secret", payload), wantErr: payment.ErrInvalidSignature},
+		{name: "缺少签名", payload: payload, signature: "", wantErr: payment.ErrInvalidSignature},
+		{name: "空密钥签名", payload: payload, signature: Sign("", payload), wantErr: payment.ErrInvalidSignature},
+		{name: "未知交易状态", payload: unknown, signature: Sign(testSecret, unknown), wantParse: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := g.VerifyCallback(context.Background(), tt.payload, tt.signature)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("期望%v，实际%v", tt.wantErr, err)
+				}
+			case tt.wantParse:
+				if err == nil || errors.Is(err, payment.ErrInvalidSignature) {
+					t.Errorf("期望解析错误，实际%v", err)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("期望验签通过，实际%v", err)
+				}
+				if event.PaymentNo != "PAY001" || event.ThirdPartyNo != "SBX001" || event.Amount != 1000 || event.Status != payment.PaymentStatusPaid {
+					t.Errorf("回调内容不符: %+v", event)
+				}
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/xiebiao/bookstore/pkg/outbox"
 	"github.com/xiebiao/bookstore/services/payment-service/internal/domain/payment"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -33,7 +34,10 @@ func InitDB(dsn string) *gorm.DB {
 		log.Fatalf("数据库Ping失败: %v", err)
 	}
 
-	if err := db.AutoMigrate(&payment.Payment{}); err != nil {
+	if err := db.AutoMigrate(&payment.Payment{}, &payment.Refund{}, &payment.RefundItem{}, &outbox.MessageModel{}); err != nil {
+		log.Fatalf("数据库迁移失败: %v", err)
+	}
+	if err := DropLegacyIndexes(db); err != nil {
 		log.Fatalf("数据库迁移失败: %v", err)
 	}
 
@@ -41,6 +45,20 @@ func InitDB(dsn string) *gorm.DB {
 	return db
 }
 
+// DropLegacyIndexes 删除已被替换的旧索引（AutoMigrate只创建索引，不删除）
+//
+// refunds.request_id原为单列唯一索引，现改为(order_id, request_id)联合唯一索引：
+// 旧索引不删除的话，不同订单仍不能使用相同的幂等键
+func DropLegacyIndexes(db *gorm.DB) error {
+	m := db.Migrator()
+	if m.HasIndex(&payment.Refund{}, "idx_refunds_request_id") {
+		if err := m.DropIndex(&payment.Refund{}, "idx_refunds_request_id"); err != nil {
+			return fmt.Errorf("删除旧索引idx_refunds_request_id失败: %w", err)
+		}
+	}
+	return nil
+}
+
 func Close(db *gorm.DB) error {
 	sqlDB, err := db.DB()
 	if err != nil {
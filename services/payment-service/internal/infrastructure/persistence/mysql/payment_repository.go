@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/xiebiao/bookstore/pkg/events"
+	"github.com/xiebiao/bookstore/pkg/outbox"
 	"github.com/xiebiao/bookstore/services/payment-service/internal/domain/payment"
 	"gorm.io/gorm"
 )
@@ -48,6 +51,18 @@ func (r *paymentRepository) FindByOrderID(ctx context.Context, orderID uint) (*p
 	return &p, nil
 }
 
+func (r *paymentRepository) FindByPaymentNo(ctx context.Context, paymentNo string) (*payment.Payment, error) {
+	var p payment.Payment
+	err := r.db.WithContext(ctx).Where("payment_no = ?", paymentNo).First(&p).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payment.ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
 func (r *paymentRepository) Update(ctx context.Context, p *payment.Payment) error {
 	result := r.db.WithContext(ctx).Save(p)
 	if result.Error != nil {
@@ -58,3 +73,51 @@ func (r *paymentRepository) Update(ctx context.Context, p *payment.Payment) erro
 	}
 	return nil
 }
+
+// UpdateStatusFrom 条件更新支付状态
+//
+// SQL：UPDATE payments SET status=?, third_party_no=?, fail_reason=?, updated_at=? WHERE id=? AND status=?
+//
+// 进入终态（已支付/支付失败）时，在同一事务中写入payment.succeeded/payment.failed事件（发件箱）
+func (r *paymentRepository) UpdateStatusFrom(ctx context.Context, p *payment.Payment, from payment.PaymentStatus) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&payment.Payment{}).
+			Where("id = ? AND status = ?", p.ID, from).
+			Updates(map[string]interface{}{
+				"status":         p.Status,
+				"third_party_no": p.ThirdPartyNo,
+				"fail_reason":    p.FailReason,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("更新支付状态失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return payment.ErrStatusConflict
+		}
+
+		switch {
+		case from == p.Status:
+			return nil
+		case p.Status == payment.PaymentStatusPaid:
+			return outbox.Write(tx, events.PaymentSucceeded, events.PaymentSucceededEvent{
+				Type:         events.PaymentSucceeded,
+				OrderID:      uint64(p.OrderID),
+				PaymentNo:    p.PaymentNo,
+				ThirdPartyNo: p.ThirdPartyNo,
+				Amount:       p.Amount,
+				PaidAt:       time.Now().Unix(),
+			})
+		case p.Status == payment.PaymentStatusFailed:
+			return outbox.Write(tx, events.PaymentFailed, events.PaymentFailedEvent{
+				Type:      events.PaymentFailed,
+				OrderID:   uint64(p.OrderID),
+				PaymentNo: p.PaymentNo,
+				Amount:    p.Amount,
+				Reason:    p.FailReason,
+				FailedAt:  time.Now().Unix(),
+			})
+		default:
+			return nil
+		}
+	})
+}
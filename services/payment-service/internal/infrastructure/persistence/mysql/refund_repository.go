@@ -0,0 +1,173 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/xiebiao/bookstore/pkg/events"
+	"github.com/xiebiao/bookstore/pkg/outbox"
+	"github.com/xiebiao/bookstore/services/payment-service/internal/domain/payment"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type refundRepository struct {
+	db *gorm.DB
+}
+
+func NewRefundRepository(db *gorm.DB) payment.RefundRepository {
+	return &refundRepository{db: db}
+}
+
+// Reserve 校验可退额度并创建处理中的退款
+//
+// 教学要点：
+// 1. SELECT ... FOR UPDATE锁定支付记录，同一笔支付的退款串行校验
+// 2. 可退额度 = 支付金额 - 已退金额 - 处理中的退款金额
+// 3. 明细按订单明细累计：成功 + 处理中的退款数量 + 本次数量 <= 下单数量
+func (r *refundRepository) Reserve(ctx context.Context, refund *payment.Refund) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var p payment.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&p, refund.PaymentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return payment.ErrPaymentNotFound
+			}
+			return fmt.Errorf("锁定支付记录失败: %w", err)
+		}
+		if !p.CanRefund() {
+			return payment.ErrPaymentNotRefundable
+		}
+
+		var pending int64
+		if err := tx.Model(&payment.Refund{}).
+			Where("payment_id = ? AND status = ?", p.ID, payment.RefundStatusPending).
+			Select("COALESCE(SUM(amount), 0)").
+			Scan(&pending).Error; err != nil {
+			return fmt.Errorf("统计处理中退款失败: %w", err)
+		}
+		if refund.Amount > p.RefundableAmount()-pending {
+			return payment.ErrRefundExceeded
+		}
+
+		for _, item := range refund.Items {
+			if item.OrderedQuantity <= 0 {
+				continue
+			}
+			var refunded int64
+			if err := tx.Table("refund_items").
+				Joins("JOIN refunds ON refunds.id = refund_items.refund_id").
+				Where("refunds.payment_id = ? AND refund_items.order_item_id = ?", p.ID, item.OrderItemID).
+				Where("refunds.status IN ?", []payment.RefundStatus{payment.RefundStatusPending, payment.RefundStatusSucceeded}).
+				Select("COALESCE(SUM(refund_items.quantity), 0)").
+				Scan(&refunded).Error; err != nil {
+				return fmt.Errorf("统计明细已退数量失败: %w", err)
+			}
+			if int(refunded)+item.Quantity > item.OrderedQuantity {
+				return fmt.Errorf("订单明细[%d]: %w", item.OrderItemID, payment.ErrRefundItemExceeded)
+			}
+		}
+
+		if err := tx.Create(refund).Error; err != nil {
+			return fmt.Errorf("创建退款记录失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// Complete 退款成功：更新退款状态并累加支付记录的已退金额
+//
+// refund.succeeded事件在同一事务中写入发件箱，累计已退金额以锁定后的支付记录为准
+func (r *refundRepository) Complete(ctx context.Context, refund *payment.Refund) (*payment.Payment, error) {
+	var p payment.Payment
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&p, refund.PaymentID).Error; err != nil {
+			return fmt.Errorf("锁定支付记录失败: %w", err)
+		}
+		if err := p.ApplyRefund(refund.Amount); err != nil {
+			return err
+		}
+
+		result := tx.Model(&payment.Refund{}).
+			Where("id = ? AND status = ?", refund.ID, payment.RefundStatusPending).
+			Updates(map[string]interface{}{
+				"status":                refund.Status,
+				"third_party_refund_no": refund.ThirdPartyRefundNo,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("更新退款状态失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return payment.ErrStatusConflict
+		}
+
+		if err := tx.Model(&p).Updates(map[string]interface{}{
+			"refunded_amount": p.RefundedAmount,
+			"status":          p.Status,
+		}).Error; err != nil {
+			return fmt.Errorf("更新已退金额失败: %w", err)
+		}
+
+		return outbox.Write(tx, events.RefundSucceeded, events.RefundSucceededEvent{
+			Type:           events.RefundSucceeded,
+			OrderID:        uint64(refund.OrderID),
+			PaymentNo:      refund.PaymentNo,
+			RefundNo:       refund.RefundNo,
+			Amount:         refund.Amount,
+			RefundedAmount: p.RefundedAmount,
+			FullyRefunded:  p.RefundableAmount() == 0,
+			RefundedAt:     time.Now().Unix(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Fail 退款失败：更新退款状态（处理中额度随之释放）
+func (r *refundRepository) Fail(ctx context.Context, refund *payment.Refund) error {
+	result := r.db.WithContext(ctx).
+		Model(&payment.Refund{}).
+		Where("id = ? AND status = ?", refund.ID, payment.RefundStatusPending).
+		Updates(map[string]interface{}{
+			"status":      refund.Status,
+			"fail_reason": refund.FailReason,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("更新退款状态失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return payment.ErrStatusConflict
+	}
+	return nil
+}
+
+// FindByRequestID 按订单和幂等键查询退款（命中uk_order_request唯一索引）
+func (r *refundRepository) FindByRequestID(ctx context.Context, orderID uint, requestID string) (*payment.Refund, error) {
+	var refund payment.Refund
+	err := r.db.WithContext(ctx).Preload("Items").
+		Where("order_id = ? AND request_id = ?", orderID, requestID).
+		First(&refund).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payment.ErrRefundNotFound
+		}
+		return nil, err
+	}
+	return &refund, nil
+}
+
+func (r *refundRepository) ListByOrderID(ctx context.Context, orderID uint) ([]*payment.Refund, error) {
+	var refunds []*payment.Refund
+	err := r.db.WithContext(ctx).
+		Preload("Items").
+		Where("order_id = ?", orderID).
+		Order("id ASC").
+		Find(&refunds).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询退款记录失败: %w", err)
+	}
+	return refunds, nil
+}